@@ -0,0 +1,11 @@
+// Package claudeonslack holds files from the repository root that are built
+// into the binary.
+package claudeonslack
+
+import _ "embed"
+
+// Changelog is CHANGELOG.md as of the build, for release notes in
+// deployment notifications
+//
+//go:embed CHANGELOG.md
+var Changelog string
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// client calls the bot's admin API
+type client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func newClient(baseURL, token string) *client {
+	return &client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// apiError is a failed admin API call, with the request ID the bot logged
+// it under
+type apiError struct {
+	Status    int
+	Message   string
+	RequestID string
+}
+
+func (e *apiError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s (HTTP %d, request %s)", e.Message, e.Status, e.RequestID)
+	}
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.Status)
+}
+
+// do sends a request to path with query and an optional JSON body, and
+// decodes the JSON answer into out
+func (c *client) do(method, path string, query url.Values, body, out interface{}) error {
+	target := c.baseURL + "/admin/" + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Error     string `json:"error"`
+			RequestID string `json:"request_id"`
+		}
+		if json.Unmarshal(data, &failure) != nil || failure.Error == "" {
+			failure.Error = strings.TrimSpace(string(data))
+			if failure.Error == "" {
+				failure.Error = http.StatusText(resp.StatusCode)
+			}
+		}
+		if failure.RequestID == "" {
+			failure.RequestID = resp.Header.Get("X-Request-ID")
+		}
+		return &apiError{Status: resp.StatusCode, Message: failure.Error, RequestID: failure.RequestID}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	return nil
+}
+
+// session, sessionDetail, exchange, deleteResult, channel, clearResult, and
+// logEntry mirror the admin API's answers
+type session struct {
+	ID           string    `json:"id"`
+	ChannelID    string    `json:"channel_id"`
+	WorkDir      string    `json:"work_dir"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastActivity time.Time `json:"last_activity"`
+	Active       bool      `json:"active"`
+	Processing   bool      `json:"processing"`
+}
+
+type sessionDetail struct {
+	ID         string    `json:"id"`
+	WorkDir    string    `json:"work_dir"`
+	SystemUser string    `json:"system_user"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Exchanges  int       `json:"exchanges"`
+	Processing bool      `json:"processing"`
+}
+
+type exchange struct {
+	SessionID         string    `json:"session_id"`
+	PreviousSessionID string    `json:"previous_session_id"`
+	Prompt            string    `json:"prompt"`
+	Response          string    `json:"response"`
+	Summary           string    `json:"summary"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type deleteResult struct {
+	SessionID string `json:"session_id"`
+	Trashed   bool   `json:"trashed"`
+}
+
+type channel struct {
+	ChannelID           string     `json:"channel_id"`
+	LifecycleState      string     `json:"lifecycle_state"`
+	SessionMode         string     `json:"session_mode"`
+	Permission          string     `json:"permission"`
+	PermissionExpiresAt *time.Time `json:"permission_expires_at"`
+	RunPriority         string     `json:"run_priority"`
+	ActiveSessionID     string     `json:"active_session_id"`
+	Processing          bool       `json:"processing"`
+	Runs                int        `json:"runs"`
+	PendingPrompts      int        `json:"pending_prompts"`
+}
+
+type clearResult struct {
+	ChannelID       string `json:"channel_id"`
+	CanceledRuns    int    `json:"canceled_runs"`
+	CanceledPrompts int    `json:"canceled_prompts"`
+}
+
+type logEntry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields"`
+}
@@ -0,0 +1,537 @@
+// Command claudectl manages a running claude-on-slack bot from a terminal
+// through its admin API: listing, inspecting, and deleting sessions,
+// inspecting channels, switching their sessions and permission modes,
+// clearing queues, compacting sessions, and tailing recent errors.
+//
+// The bot serves the admin API when ADMIN_API_TOKEN is set. Point claudectl
+// at it with CLAUDECTL_URL and CLAUDECTL_TOKEN, or the -url and -token flags.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const defaultURL = "http://localhost:8080"
+
+const usage = `Usage: claudectl [-url URL] [-token TOKEN] [-json] <command> [arguments]
+
+Commands:
+  sessions [-channel C123] [-limit 50]   List recent sessions
+  session SESSION                        Show a session
+  tree SESSION                           Show a session's conversation
+  delete SESSION                         Delete a session (to the trash when kept)
+  channel C123                           Show a channel's state and queue
+  switch -session SESSION C123           Point a channel at another session
+  permission -mode plan [-ttl 30m] C123  Set a channel's permission mode
+  clear-queue C123                       Cancel a channel's runs and scheduled prompts
+  compact -user U123 C123                Compact the channel's session as a user
+  errors [-limit 50] [-level error] [-f] Show recent errors, or follow them with -f
+
+Environment:
+  CLAUDECTL_URL    Bot address (default ` + defaultURL + `)
+  CLAUDECTL_TOKEN  The bot's ADMIN_API_TOKEN
+`
+
+// errUsage is returned for bad arguments, after the usage is printed
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	if err := run(os.Args[1:], os.Stdout, os.Stderr, interrupt); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "claudectl: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run executes one claudectl command. Following errors stops when stop
+// receives.
+func run(args []string, stdout, stderr io.Writer, stop <-chan os.Signal) error {
+	global := flag.NewFlagSet("claudectl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() { fmt.Fprint(stderr, usage) }
+	baseURL := global.String("url", envOr("CLAUDECTL_URL", defaultURL), "bot address")
+	token := global.String("token", os.Getenv("CLAUDECTL_TOKEN"), "admin API token")
+	raw := global.Bool("json", false, "print the API's JSON instead of a table")
+	if err := global.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if global.NArg() == 0 {
+		global.Usage()
+		return errUsage
+	}
+	if *token == "" {
+		return errors.New("no token; set CLAUDECTL_TOKEN or pass -token")
+	}
+
+	c := newClient(*baseURL, *token)
+	out := &printer{w: stdout, raw: *raw}
+	command, rest := global.Arg(0), global.Args()[1:]
+
+	switch command {
+	case "sessions":
+		return runSessions(c, out, rest, stderr)
+	case "session":
+		return runSession(c, out, rest, stderr)
+	case "tree":
+		return runTree(c, out, rest, stderr)
+	case "delete":
+		return runDelete(c, out, rest, stderr)
+	case "channel":
+		return runChannel(c, out, rest, stderr)
+	case "switch":
+		return runSwitch(c, out, rest, stderr)
+	case "permission":
+		return runPermission(c, out, rest, stderr)
+	case "clear-queue":
+		return runClearQueue(c, out, rest, stderr)
+	case "compact":
+		return runCompact(c, out, rest, stderr)
+	case "errors":
+		return runErrors(c, out, rest, stderr, stop)
+	case "help":
+		global.Usage()
+		return nil
+	default:
+		fmt.Fprintf(stderr, "claudectl: unknown command %q\n\n", command)
+		global.Usage()
+		return errUsage
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// subcommand parses a command's flags, requiring exactly positional
+// arguments after them
+func subcommand(name string, args []string, stderr io.Writer, positional string, define func(*flag.FlagSet)) ([]string, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	define(flags)
+	if err := flags.Parse(args); err != nil {
+		return nil, errUsage
+	}
+
+	want := 0
+	if positional != "" {
+		want = 1
+	}
+	if flags.NArg() != want {
+		fmt.Fprintf(stderr, "Usage: claudectl %s", name)
+		flags.VisitAll(func(f *flag.Flag) { fmt.Fprintf(stderr, " [-%s %s]", f.Name, f.DefValue) })
+		if positional != "" {
+			fmt.Fprintf(stderr, " %s", positional)
+		}
+		fmt.Fprintln(stderr)
+		return nil, errUsage
+	}
+	return flags.Args(), nil
+}
+
+func runSessions(c *client, out *printer, args []string, stderr io.Writer) error {
+	var channelID string
+	var limit int
+	if _, err := subcommand("sessions", args, stderr, "", func(f *flag.FlagSet) {
+		f.StringVar(&channelID, "channel", "", "only sessions in this channel")
+		f.IntVar(&limit, "limit", 50, "most sessions to list")
+	}); err != nil {
+		return err
+	}
+
+	query := url.Values{"limit": {strconv.Itoa(limit)}}
+	if channelID != "" {
+		query.Set("channel", channelID)
+	}
+	var sessions []session
+	if err := c.do(http.MethodGet, "sessions", query, nil, &sessions); err != nil {
+		return err
+	}
+	if out.raw {
+		return out.json(sessions)
+	}
+
+	if len(sessions) == 0 {
+		fmt.Fprintln(out.w, "No sessions.")
+		return nil
+	}
+	table := out.table("SESSION", "CHANNEL", "STATE", "LAST ACTIVITY", "WORK DIR")
+	for _, s := range sessions {
+		state := "closed"
+		switch {
+		case s.Processing:
+			state = "busy"
+		case s.Active:
+			state = "active"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", s.ID, s.ChannelID, state, ago(s.LastActivity), s.WorkDir)
+	}
+	return table.Flush()
+}
+
+func runSession(c *client, out *printer, args []string, stderr io.Writer) error {
+	positional, err := subcommand("session", args, stderr, "SESSION", func(*flag.FlagSet) {})
+	if err != nil {
+		return err
+	}
+
+	var detail sessionDetail
+	if err := c.do(http.MethodGet, "sessions/"+url.PathEscape(positional[0]), nil, nil, &detail); err != nil {
+		return err
+	}
+	if out.raw {
+		return out.json(detail)
+	}
+
+	state := "idle"
+	if detail.Processing {
+		state = "busy"
+	}
+	table := out.table()
+	fmt.Fprintf(table, "Session:\t%s\n", detail.ID)
+	fmt.Fprintf(table, "State:\t%s\n", state)
+	fmt.Fprintf(table, "Work dir:\t%s\n", detail.WorkDir)
+	if detail.SystemUser != "" {
+		fmt.Fprintf(table, "System user:\t%s\n", detail.SystemUser)
+	}
+	fmt.Fprintf(table, "Exchanges:\t%d\n", detail.Exchanges)
+	fmt.Fprintf(table, "Created:\t%s\n", detail.CreatedAt.Local().Format(time.RFC3339))
+	fmt.Fprintf(table, "Updated:\t%s (%s)\n", detail.UpdatedAt.Local().Format(time.RFC3339), ago(detail.UpdatedAt))
+	return table.Flush()
+}
+
+// treePreview caps how much of each prompt and reply tree prints
+const treePreview = 200
+
+func runTree(c *client, out *printer, args []string, stderr io.Writer) error {
+	positional, err := subcommand("tree", args, stderr, "SESSION", func(*flag.FlagSet) {})
+	if err != nil {
+		return err
+	}
+
+	var exchanges []exchange
+	if err := c.do(http.MethodGet, "sessions/"+url.PathEscape(positional[0])+"/tree", nil, nil, &exchanges); err != nil {
+		return err
+	}
+	if out.raw {
+		return out.json(exchanges)
+	}
+
+	if len(exchanges) == 0 {
+		fmt.Fprintln(out.w, "No exchanges yet.")
+		return nil
+	}
+	for i, e := range exchanges {
+		fmt.Fprintf(out.w, "%d. %s  %s\n", i+1, e.SessionID, e.CreatedAt.Local().Format(time.RFC3339))
+		if e.Prompt != "" {
+			fmt.Fprintf(out.w, "   > %s\n", preview(e.Prompt))
+		}
+		reply := e.Summary
+		if reply == "" {
+			reply = e.Response
+		}
+		if reply != "" {
+			fmt.Fprintf(out.w, "   %s\n", preview(reply))
+		}
+	}
+	return nil
+}
+
+func runDelete(c *client, out *printer, args []string, stderr io.Writer) error {
+	positional, err := subcommand("delete", args, stderr, "SESSION", func(*flag.FlagSet) {})
+	if err != nil {
+		return err
+	}
+
+	var result deleteResult
+	if err := c.do(http.MethodDelete, "sessions/"+url.PathEscape(positional[0]), nil, nil, &result); err != nil {
+		return err
+	}
+	if out.raw {
+		return out.json(result)
+	}
+	if result.Trashed {
+		fmt.Fprintf(out.w, "Moved %s to the trash; restore it with /session restore in Slack.\n", result.SessionID)
+	} else {
+		fmt.Fprintf(out.w, "Deleted %s.\n", result.SessionID)
+	}
+	return nil
+}
+
+func runChannel(c *client, out *printer, args []string, stderr io.Writer) error {
+	positional, err := subcommand("channel", args, stderr, "CHANNEL", func(*flag.FlagSet) {})
+	if err != nil {
+		return err
+	}
+
+	var ch channel
+	if err := c.do(http.MethodGet, "channels/"+url.PathEscape(positional[0]), nil, nil, &ch); err != nil {
+		return err
+	}
+	return out.channel(ch)
+}
+
+func runSwitch(c *client, out *printer, args []string, stderr io.Writer) error {
+	var sessionID string
+	positional, err := subcommand("switch", args, stderr, "CHANNEL", func(f *flag.FlagSet) {
+		f.StringVar(&sessionID, "session", "", "session to switch the channel to")
+	})
+	if err != nil {
+		return err
+	}
+	if sessionID == "" {
+		fmt.Fprintln(stderr, "claudectl switch: -session is required")
+		return errUsage
+	}
+
+	var ch channel
+	body := map[string]string{"session_id": sessionID}
+	if err := c.do(http.MethodPut, "channels/"+url.PathEscape(positional[0])+"/session", nil, body, &ch); err != nil {
+		return err
+	}
+	return out.channel(ch)
+}
+
+func runPermission(c *client, out *printer, args []string, stderr io.Writer) error {
+	var mode, ttl string
+	positional, err := subcommand("permission", args, stderr, "CHANNEL", func(f *flag.FlagSet) {
+		f.StringVar(&mode, "mode", "", "default, acceptEdits, bypassPermissions, or plan")
+		f.StringVar(&ttl, "ttl", "", "revert to the channel default after this long, e.g. 30m")
+	})
+	if err != nil {
+		return err
+	}
+	if mode == "" {
+		fmt.Fprintln(stderr, "claudectl permission: -mode is required")
+		return errUsage
+	}
+
+	var ch channel
+	body := map[string]string{"mode": mode}
+	if ttl != "" {
+		body["ttl"] = ttl
+	}
+	if err := c.do(http.MethodPut, "channels/"+url.PathEscape(positional[0])+"/permission", nil, body, &ch); err != nil {
+		return err
+	}
+	return out.channel(ch)
+}
+
+func runClearQueue(c *client, out *printer, args []string, stderr io.Writer) error {
+	positional, err := subcommand("clear-queue", args, stderr, "CHANNEL", func(*flag.FlagSet) {})
+	if err != nil {
+		return err
+	}
+
+	var result clearResult
+	if err := c.do(http.MethodPost, "channels/"+url.PathEscape(positional[0])+"/clear-queue", nil, nil, &result); err != nil {
+		return err
+	}
+	if out.raw {
+		return out.json(result)
+	}
+
+	fmt.Fprintf(out.w, "Cleared %s: canceled %d run(s) and %d scheduled prompt(s)\n", result.ChannelID, result.CanceledRuns, result.CanceledPrompts)
+	return nil
+}
+
+func runCompact(c *client, out *printer, args []string, stderr io.Writer) error {
+	var user string
+	positional, err := subcommand("compact", args, stderr, "CHANNEL", func(f *flag.FlagSet) {
+		f.StringVar(&user, "user", "", "Slack user ID to run the compaction as")
+	})
+	if err != nil {
+		return err
+	}
+	if user == "" {
+		fmt.Fprintln(stderr, "claudectl compact: -user is required; compaction runs as a Slack user allowed in the channel")
+		return errUsage
+	}
+
+	var result map[string]string
+	body := map[string]string{"user": user}
+	if err := c.do(http.MethodPost, "channels/"+url.PathEscape(positional[0])+"/compact", nil, body, &result); err != nil {
+		return err
+	}
+	if out.raw {
+		return out.json(result)
+	}
+	fmt.Fprintf(out.w, "Compacting session %s in %s; the result is posted in the channel.\n", result["session_id"], result["channel_id"])
+	return nil
+}
+
+// followInterval is how often errors -f polls
+const followInterval = 5 * time.Second
+
+func runErrors(c *client, out *printer, args []string, stderr io.Writer, stop <-chan os.Signal) error {
+	var limit int
+	var level string
+	var follow bool
+	if _, err := subcommand("errors", args, stderr, "", func(f *flag.FlagSet) {
+		f.IntVar(&limit, "limit", 50, "most entries to show")
+		f.StringVar(&level, "level", "error", "lowest level to show, e.g. warn")
+		f.BoolVar(&follow, "f", false, "keep polling for new entries")
+	}); err != nil {
+		return err
+	}
+
+	var since time.Time
+	for {
+		query := url.Values{"limit": {strconv.Itoa(limit)}, "level": {level}}
+		if !since.IsZero() {
+			query.Set("since", since.Format(time.RFC3339Nano))
+		}
+		var entries []logEntry
+		if err := c.do(http.MethodGet, "errors", query, nil, &entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := out.logEntry(entry); err != nil {
+				return err
+			}
+			if entry.Time.After(since) {
+				since = entry.Time
+			}
+		}
+
+		if !follow {
+			if len(entries) == 0 && !out.raw {
+				fmt.Fprintln(out.w, "No recent errors.")
+			}
+			return nil
+		}
+		// Entries logged before the first poll that didn't fit are skipped
+		if since.IsZero() {
+			since = time.Now()
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(followInterval):
+		}
+	}
+}
+
+// printer writes command output as tables or, with -json, as JSON
+type printer struct {
+	w   io.Writer
+	raw bool
+}
+
+func (p *printer) json(v interface{}) error {
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func (p *printer) table(headers ...string) *tabwriter.Writer {
+	table := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	if len(headers) > 0 {
+		fmt.Fprintln(table, strings.Join(headers, "\t"))
+	}
+	return table
+}
+
+// channel prints a channel's state
+func (p *printer) channel(ch channel) error {
+	if p.raw {
+		return p.json(ch)
+	}
+
+	table := p.table()
+	row := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(table, "%s:\t%s\n", name, value)
+		}
+	}
+	row("Channel", ch.ChannelID)
+	row("Lifecycle", ch.LifecycleState)
+	row("Session mode", ch.SessionMode)
+	permission := ch.Permission
+	if ch.PermissionExpiresAt != nil {
+		permission += fmt.Sprintf(" (until %s)", ch.PermissionExpiresAt.Local().Format(time.RFC3339))
+	}
+	row("Permission", permission)
+	row("Run priority", ch.RunPriority)
+	active := ch.ActiveSessionID
+	if active != "" && ch.Processing {
+		active += " (busy)"
+	}
+	row("Active session", active)
+	row("Runs", strconv.Itoa(ch.Runs))
+	row("Scheduled prompts", strconv.Itoa(ch.PendingPrompts))
+	return table.Flush()
+}
+
+// logEntry prints one log entry a line at a time, so errors -f can be piped
+func (p *printer) logEntry(entry logEntry) error {
+	if p.raw {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.w, string(data))
+		return err
+	}
+
+	keys := make([]string, 0, len(entry.Fields))
+	for key := range entry.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fields := make([]string, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, fmt.Sprintf("%s=%v", key, entry.Fields[key]))
+	}
+
+	_, err := fmt.Fprintf(p.w, "%s %-5s %s %s\n", entry.Time.Local().Format(time.RFC3339), strings.ToUpper(entry.Level), entry.Message, strings.Join(fields, " "))
+	return err
+}
+
+// preview flattens text to one line and shortens it for tree
+func preview(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > treePreview {
+		return string(runes[:treePreview]) + "…"
+	}
+	return text
+}
+
+// ago renders how long ago t was, coarsely
+func ago(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
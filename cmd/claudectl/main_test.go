@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// fakeAdminAPI answers like the bot's admin API and records what it was
+// asked
+type fakeAdminAPI struct {
+	requests []*http.Request
+	bodies   []string
+}
+
+func (f *fakeAdminAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body bytes.Buffer
+	body.ReadFrom(r.Body)
+	f.requests = append(f.requests, r)
+	f.bodies = append(f.bodies, body.String())
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.Header().Set("X-Request-ID", "req-1")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "missing or invalid bearer token", "request_id": "req-1"})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/admin/sessions":
+		w.Write([]byte(`[{"id":"s-1","channel_id":"C123","work_dir":"/srv/app","active":true,"processing":true}]`))
+	case "/admin/sessions/s-1":
+		if r.Method == http.MethodDelete {
+			w.Write([]byte(`{"session_id":"s-1","trashed":true}`))
+			return
+		}
+		w.Write([]byte(`{"id":"s-1","work_dir":"/srv/app","exchanges":3,"processing":false}`))
+	case "/admin/sessions/s-1/tree":
+		w.Write([]byte(`[{"session_id":"s-1a","prompt":"fix the build","response":"Fixed the\nimport."}]`))
+	case "/admin/channels/C123", "/admin/channels/C123/session", "/admin/channels/C123/permission":
+		w.Write([]byte(`{"channel_id":"C123","permission":"plan","active_session_id":"s-1","processing":true,"runs":2,"pending_prompts":1}`))
+	case "/admin/channels/C123/clear-queue":
+		w.Write([]byte(`{"channel_id":"C123","canceled_runs":2,"canceled_prompts":1}`))
+	case "/admin/channels/C123/compact":
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"channel_id":"C123","session_id":"s-1","status":"compacting"}`))
+	case "/admin/errors":
+		w.Write([]byte(`[{"time":"2026-01-02T03:04:05Z","level":"error","message":"Claude Code processing failed","fields":{"channel_id":"C123"}}]`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"no such admin endpoint"}`))
+	}
+}
+
+func runAgainst(t *testing.T, api *fakeAdminAPI, args ...string) (string, string, error) {
+	t.Helper()
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	err := run(append([]string{"-url", server.URL, "-token", "secret"}, args...), &stdout, &stderr, make(chan os.Signal))
+	return stdout.String(), stderr.String(), err
+}
+
+func TestRun_Commands(t *testing.T) {
+	tests := []struct {
+		args   []string
+		method string
+		path   string
+		want   []string
+	}{
+		{[]string{"sessions", "-channel", "C123"}, http.MethodGet, "/admin/sessions", []string{"SESSION", "s-1", "busy", "/srv/app"}},
+		{[]string{"channel", "C123"}, http.MethodGet, "/admin/channels/C123", []string{"Permission:", "plan", "s-1 (busy)", "Scheduled prompts:"}},
+		{[]string{"session", "s-1"}, http.MethodGet, "/admin/sessions/s-1", []string{"Session:", "idle", "/srv/app", "Exchanges:"}},
+		{[]string{"tree", "s-1"}, http.MethodGet, "/admin/sessions/s-1/tree", []string{"1. s-1a", "> fix the build", "Fixed the import."}},
+		{[]string{"delete", "s-1"}, http.MethodDelete, "/admin/sessions/s-1", []string{"Moved s-1 to the trash"}},
+		{[]string{"switch", "-session", "s-1", "C123"}, http.MethodPut, "/admin/channels/C123/session", []string{"Active session:", "s-1 (busy)"}},
+		{[]string{"permission", "-mode", "plan", "C123"}, http.MethodPut, "/admin/channels/C123/permission", []string{"Permission:", "plan"}},
+		{[]string{"clear-queue", "C123"}, http.MethodPost, "/admin/channels/C123/clear-queue", []string{"canceled 2 run(s) and 1 scheduled prompt(s)"}},
+		{[]string{"compact", "-user", "U123", "C123"}, http.MethodPost, "/admin/channels/C123/compact", []string{"Compacting session s-1 in C123"}},
+		{[]string{"errors"}, http.MethodGet, "/admin/errors", []string{"ERROR", "Claude Code processing failed", "channel_id=C123"}},
+	}
+
+	for _, tt := range tests {
+		api := &fakeAdminAPI{}
+		stdout, stderr, err := runAgainst(t, api, tt.args...)
+		if err != nil {
+			t.Errorf("%v: %v (stderr %q)", tt.args, err, stderr)
+			continue
+		}
+		if len(api.requests) != 1 || api.requests[0].Method != tt.method || api.requests[0].URL.Path != tt.path {
+			t.Errorf("%v: expected one %s %s, got %d request(s)", tt.args, tt.method, tt.path, len(api.requests))
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("%v: expected output to contain %q, got:\n%s", tt.args, want, stdout)
+			}
+		}
+	}
+}
+
+func TestRun_SendsQueryAndBody(t *testing.T) {
+	api := &fakeAdminAPI{}
+	if _, _, err := runAgainst(t, api, "sessions", "-channel", "C123", "-limit", "5"); err != nil {
+		t.Fatal(err)
+	}
+	if query := api.requests[0].URL.Query(); query.Get("channel") != "C123" || query.Get("limit") != "5" {
+		t.Errorf("Unexpected query %v", query)
+	}
+
+	api = &fakeAdminAPI{}
+	if _, _, err := runAgainst(t, api, "compact", "-user", "U123", "C123"); err != nil {
+		t.Fatal(err)
+	}
+	if api.bodies[0] != `{"user":"U123"}` {
+		t.Errorf("Unexpected compact body %q", api.bodies[0])
+	}
+
+	api = &fakeAdminAPI{}
+	if _, _, err := runAgainst(t, api, "permission", "-mode", "plan", "-ttl", "30m", "C123"); err != nil {
+		t.Fatal(err)
+	}
+	if api.bodies[0] != `{"mode":"plan","ttl":"30m"}` {
+		t.Errorf("Unexpected permission body %q", api.bodies[0])
+	}
+}
+
+func TestRun_JSONOutput(t *testing.T) {
+	stdout, _, err := runAgainst(t, &fakeAdminAPI{}, "-json", "channel", "C123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ch channel
+	if err := json.Unmarshal([]byte(stdout), &ch); err != nil || ch.Runs != 2 {
+		t.Errorf("Expected the channel as JSON, got %q (%v)", stdout, err)
+	}
+}
+
+func TestRun_ReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(&fakeAdminAPI{})
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"-url", server.URL, "-token", "wrong", "sessions"}, &stdout, &stderr, make(chan os.Signal))
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized || apiErr.RequestID != "req-1" {
+		t.Fatalf("Expected a 401 with its request ID, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "request req-1") {
+		t.Errorf("Expected the error to quote the request ID, got %q", err)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"frobnicate"},
+		{"channel"},
+		{"channel", "C1", "C2"},
+		{"compact", "C123"},
+		{"switch", "C123"},
+		{"permission", "C123"},
+		{"tree"},
+	} {
+		api := &fakeAdminAPI{}
+		_, stderr, err := runAgainst(t, api, args...)
+		if !errors.Is(err, errUsage) {
+			t.Errorf("%v: expected a usage error, got %v", args, err)
+		}
+		if !strings.Contains(stderr, "claudectl") {
+			t.Errorf("%v: expected usage on stderr, got %q", args, stderr)
+		}
+		if len(api.requests) != 0 {
+			t.Errorf("%v: expected no request for bad arguments", args)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	t.Setenv("CLAUDECTL_TOKEN", "")
+	if err := run([]string{"sessions"}, &stdout, &stderr, nil); err == nil || !strings.Contains(err.Error(), "CLAUDECTL_TOKEN") {
+		t.Errorf("Expected a missing token to be reported, got %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,6 +15,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/ratelimit"
 )
 
 // Permission represents a permission level
@@ -39,8 +41,39 @@ type UserInfo struct {
 	Metadata    map[string]string `json:"metadata"`
 	CreatedAt   time.Time         `json:"created_at"`
 	LastSeen    time.Time         `json:"last_seen"`
+
+	// Slack profile, populated by the ProfileFetcher
+	DisplayName      string    `json:"display_name"`
+	TimeZone         string    `json:"time_zone"`
+	TZOffset         int       `json:"tz_offset"`
+	ProfileUpdatedAt time.Time `json:"profile_updated_at"`
+
+	// IsExternal is set for users from another organization (Slack Connect)
+	IsExternal bool `json:"is_external"`
+
+	profileCheckedAt time.Time
+}
+
+// UserProfile is the Slack profile data used to enrich UserInfo
+type UserProfile struct {
+	Name        string
+	DisplayName string
+	Email       string
+	TimeZone    string
+	TZOffset    int // Seconds east of UTC
+	IsBot       bool
+
+	TeamID       string
+	EnterpriseID string // Enterprise Grid organization, if any
+	IsStranger   bool   // Slack flags users the bot's workspace doesn't share an org with
 }
 
+// ProfileFetcher looks up a user's Slack profile (users.info)
+type ProfileFetcher func(userID string) (*UserProfile, error)
+
+// profileRetryInterval limits how often a failed profile lookup is retried
+const profileRetryInterval = 5 * time.Minute
+
 // ChannelInfo represents channel information
 type ChannelInfo struct {
 	ID          string            `json:"id"`
@@ -71,15 +104,25 @@ type Service struct {
 	users          map[string]*UserInfo
 	channels       map[string]*ChannelInfo
 	bannedUsers    map[string]time.Time
-	rateLimitMap   map[string]*RateLimitEntry
+	rateLimiter    *ratelimit.Limiter
+	profileFetcher ProfileFetcher
+	homeTeamID     string
+	homeEnterprise string
+	identities     map[string]string // Slack user ID to system identity
 	mu             sync.RWMutex
 }
 
-// RateLimitEntry tracks rate limiting per user
-type RateLimitEntry struct {
-	Count     int       `json:"count"`
-	Window    time.Time `json:"window"`
-	LastReset time.Time `json:"last_reset"`
+// RateLimitError reports that a user or channel has used up its request
+// capacity, and when it returns
+type RateLimitError struct {
+	Scope      string // ratelimit.ScopeUser or ratelimit.ScopeChannel
+	RetryAfter time.Duration
+	RetryAt    time.Time
+	Limit      int // Requests allowed in a burst
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s rate limit exceeded, try again in %v", e.Scope, e.RetryAfter)
 }
 
 // NewService creates a new authentication service
@@ -90,7 +133,11 @@ func NewService(cfg *config.Config, logger *zap.Logger) *Service {
 		users:        make(map[string]*UserInfo),
 		channels:     make(map[string]*ChannelInfo),
 		bannedUsers:  make(map[string]time.Time),
-		rateLimitMap: make(map[string]*RateLimitEntry),
+		identities:   make(map[string]string),
+		rateLimiter: ratelimit.New(
+			ratelimit.Limit{PerMinute: cfg.RateLimitPerMinute, Burst: cfg.RateLimitBurst},
+			ratelimit.Limit{PerMinute: cfg.ChannelRateLimitPerMinute, Burst: cfg.ChannelRateLimitBurst},
+		),
 	}
 }
 
@@ -116,33 +163,174 @@ func (s *Service) AuthenticateUser(ctx *AuthContext) (*UserInfo, error) {
 		s.users[ctx.UserID] = user
 		s.mu.Unlock()
 
+		s.refreshProfile(user)
+
 		s.logger.Info("Created new user",
 			zap.String("user_id", ctx.UserID),
+			zap.String("user_name", s.DisplayName(ctx.UserID)),
+			zap.String("identity", s.Identity(ctx.UserID)),
+			zap.String("email", user.Email),
 			zap.Bool("is_admin", user.IsAdmin))
 	} else {
 		// Update last seen
 		s.mu.Lock()
 		user.LastSeen = time.Now()
 		s.mu.Unlock()
+
+		s.refreshProfile(user)
 	}
 
 	return user, nil
 }
 
+// SetProfileFetcher sets how Slack profiles are looked up for new users
+func (s *Service) SetProfileFetcher(fetcher ProfileFetcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profileFetcher = fetcher
+}
+
+// SetHomeTeam records the bot's own workspace and Enterprise Grid organization.
+// Users whose profile places them on any other team are treated as external.
+func (s *Service) SetHomeTeam(teamID, enterpriseID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.homeTeamID = teamID
+	s.homeEnterprise = enterpriseID
+}
+
+// isExternalUser reports whether a profile belongs to another organization.
+// Users on other workspaces of the same Enterprise Grid org are not external.
+func isExternalUser(profile *UserProfile, homeTeamID, homeEnterprise string) bool {
+	if profile.IsStranger {
+		return true
+	}
+	if homeEnterprise != "" && profile.EnterpriseID == homeEnterprise {
+		return false
+	}
+	return homeTeamID != "" && profile.TeamID != "" && profile.TeamID != homeTeamID
+}
+
+// refreshProfile fetches the user's Slack profile if it was never loaded or
+// is older than USER_PROFILE_CACHE_TTL. Failures are retried after a short
+// delay; authentication never depends on the profile.
+func (s *Service) refreshProfile(user *UserInfo) {
+	s.mu.RLock()
+	fetcher := s.profileFetcher
+	fresh := time.Since(user.ProfileUpdatedAt) < s.config.UserProfileCacheTTL ||
+		time.Since(user.profileCheckedAt) < profileRetryInterval
+	s.mu.RUnlock()
+
+	if fetcher == nil || fresh {
+		return
+	}
+
+	profile, err := fetcher(user.ID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user.profileCheckedAt = time.Now()
+	if err != nil {
+		s.logger.Warn("Failed to fetch user profile", zap.String("user_id", user.ID), zap.Error(err))
+		return
+	}
+
+	user.Name = profile.Name
+	user.DisplayName = profile.DisplayName
+	user.Email = profile.Email
+	user.TimeZone = profile.TimeZone
+	user.TZOffset = profile.TZOffset
+	user.IsBot = profile.IsBot
+	if profile.TeamID != "" {
+		user.TeamID = profile.TeamID
+	}
+	user.IsExternal = isExternalUser(profile, s.homeTeamID, s.homeEnterprise)
+	user.ProfileUpdatedAt = time.Now()
+}
+
+// DisplayName returns the best known name for a user, falling back to the ID
+func (s *Service) DisplayName(userID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.displayNameLocked(userID)
+}
+
+// displayNameLocked is DisplayName for callers already holding s.mu
+func (s *Service) displayNameLocked(userID string) string {
+	user, exists := s.users[userID]
+	switch {
+	case !exists:
+		return userID
+	case user.DisplayName != "":
+		return user.DisplayName
+	case user.Name != "":
+		return user.Name
+	default:
+		return userID
+	}
+}
+
+// SetIdentities replaces every Slack user to system identity mapping
+func (s *Service) SetIdentities(identities map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.identities = make(map[string]string, len(identities))
+	for userID, identity := range identities {
+		s.identities[userID] = identity
+	}
+}
+
+// SetIdentity maps a Slack user to the system identity (LDAP, Unix, or email)
+// their actions are attributed to; an empty identity removes the mapping
+func (s *Service) SetIdentity(userID, identity string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if identity == "" {
+		delete(s.identities, userID)
+		return
+	}
+	s.identities[userID] = identity
+}
+
+// Identity returns the system identity mapped to a Slack user, or "" if
+// there is none
+func (s *Service) Identity(userID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.identities[userID]
+}
+
+// Location returns the user's Slack timezone, or the server's local time
+// zone if the profile is unknown
+func (s *Service) Location(userID string) *time.Location {
+	s.mu.RLock()
+	user, exists := s.users[userID]
+	var timeZone string
+	var offset int
+	if exists {
+		timeZone = user.TimeZone
+		offset = user.TZOffset
+	}
+	s.mu.RUnlock()
+
+	if timeZone == "" {
+		return time.Local
+	}
+	if location, err := time.LoadLocation(timeZone); err == nil {
+		return location
+	}
+	return time.FixedZone(timeZone, offset)
+}
+
 // AuthorizeUser checks if a user is authorized for a specific action
 func (s *Service) AuthorizeUser(ctx *AuthContext, requiredPermission Permission) error {
 	// Check if user is banned
 	if s.isUserBanned(ctx.UserID) {
-		s.logger.Warn("Blocked banned user", zap.String("user_id", ctx.UserID))
-		return fmt.Errorf("user %s is banned", ctx.UserID)
-	}
-
-	// Check rate limiting
-	if limited, until := s.checkRateLimit(ctx.UserID); limited {
-		s.logger.Warn("Rate limited user",
+		s.logger.Warn("Blocked banned user",
 			zap.String("user_id", ctx.UserID),
-			zap.Time("until", until))
-		return fmt.Errorf("rate limit exceeded, try again in %v", time.Until(until))
+			zap.String("user_name", s.DisplayName(ctx.UserID)),
+			zap.String("identity", s.Identity(ctx.UserID)))
+		return fmt.Errorf("user %s is banned", ctx.UserID)
 	}
 
 	// Authenticate user
@@ -151,9 +339,19 @@ func (s *Service) AuthorizeUser(ctx *AuthContext, requiredPermission Permission)
 		return fmt.Errorf("authentication failed: %w", err)
 	}
 
+	// Slack Connect users are subject to the external user policy
+	if user.IsExternal {
+		if err := s.authorizeExternalUser(ctx, user, requiredPermission); err != nil {
+			return err
+		}
+	}
+
 	// Check if user is allowed
 	if !s.config.IsUserAllowed(ctx.UserID) {
-		s.logger.Warn("Blocked unauthorized user", zap.String("user_id", ctx.UserID))
+		s.logger.Warn("Blocked unauthorized user",
+			zap.String("user_id", ctx.UserID),
+			zap.String("user_name", s.DisplayName(ctx.UserID)),
+			zap.String("identity", s.Identity(ctx.UserID)))
 		return fmt.Errorf("user %s is not authorized to use this bot", ctx.UserID)
 	}
 
@@ -161,7 +359,9 @@ func (s *Service) AuthorizeUser(ctx *AuthContext, requiredPermission Permission)
 	if !s.config.IsChannelAllowed(ctx.ChannelID) {
 		s.logger.Warn("Blocked unauthorized channel",
 			zap.String("channel_id", ctx.ChannelID),
-			zap.String("user_id", ctx.UserID))
+			zap.String("user_id", ctx.UserID),
+			zap.String("user_name", s.DisplayName(ctx.UserID)),
+			zap.String("identity", s.Identity(ctx.UserID)))
 		return fmt.Errorf("bot is not authorized in this channel")
 	}
 
@@ -169,6 +369,8 @@ func (s *Service) AuthorizeUser(ctx *AuthContext, requiredPermission Permission)
 	if !s.hasPermission(user, requiredPermission) {
 		s.logger.Warn("User lacks required permission",
 			zap.String("user_id", ctx.UserID),
+			zap.String("user_name", s.DisplayName(ctx.UserID)),
+			zap.String("identity", s.Identity(ctx.UserID)),
 			zap.String("required", s.permissionToString(requiredPermission)))
 		return fmt.Errorf("insufficient permissions")
 	}
@@ -177,18 +379,54 @@ func (s *Service) AuthorizeUser(ctx *AuthContext, requiredPermission Permission)
 	if ctx.Command != "" && !s.config.IsCommandAllowed(ctx.Command) {
 		s.logger.Warn("Blocked unauthorized command",
 			zap.String("command", ctx.Command),
-			zap.String("user_id", ctx.UserID))
+			zap.String("user_id", ctx.UserID),
+			zap.String("user_name", s.DisplayName(ctx.UserID)),
+			zap.String("identity", s.Identity(ctx.UserID)))
 		return fmt.Errorf("command not allowed: %s", ctx.Command)
 	}
 
 	s.logger.Debug("Authorization successful",
 		zap.String("user_id", ctx.UserID),
+		zap.String("user_name", s.DisplayName(ctx.UserID)),
+		zap.String("identity", s.Identity(ctx.UserID)),
 		zap.String("channel_id", ctx.ChannelID),
 		zap.String("permission", s.permissionToString(requiredPermission)))
 
 	return nil
 }
 
+// authorizeExternalUser applies EXTERNAL_USER_POLICY and audit logs every
+// interaction from a user outside the bot's organization
+func (s *Service) authorizeExternalUser(ctx *AuthContext, user *UserInfo, requiredPermission Permission) error {
+	s.mu.RLock()
+	teamID := user.TeamID
+	s.mu.RUnlock()
+
+	policy := s.config.ExternalUserPolicy
+	var err error
+	switch {
+	case policy == config.ExternalUserAllow:
+	case policy == config.ExternalUserReadOnly && requiredPermission <= PermissionRead:
+	case policy == config.ExternalUserReadOnly:
+		err = fmt.Errorf("external users have read-only access")
+	default:
+		err = fmt.Errorf("external users are not allowed to use this bot")
+	}
+
+	s.logger.Info("External user interaction",
+		zap.String("user_id", ctx.UserID),
+		zap.String("user_name", s.DisplayName(ctx.UserID)),
+		zap.String("identity", s.Identity(ctx.UserID)),
+		zap.String("team_id", teamID),
+		zap.String("channel_id", ctx.ChannelID),
+		zap.String("command", ctx.Command),
+		zap.String("required", requiredPermission.String()),
+		zap.String("policy", string(policy)),
+		zap.Bool("allowed", err == nil))
+
+	return err
+}
+
 // IsUserAdmin checks if a user is an admin
 func (s *Service) IsUserAdmin(userID string) bool {
 	s.mu.RLock()
@@ -202,6 +440,79 @@ func (s *Service) IsUserAdmin(userID string) bool {
 	return s.config.IsUserAdmin(userID)
 }
 
+// Access is what a user may do in a channel, as AuthorizeUser would decide
+// it, for showing to the user
+type Access struct {
+	BannedUntil    time.Time // Zero unless banned
+	Admin          bool
+	UserAllowed    bool // On ALLOWED_USERS, or no list is set
+	ChannelAllowed bool // On ALLOWED_CHANNELS, or no list is set
+	External       bool
+	ExternalPolicy config.ExternalUserPolicy
+	Permission     Permission // Highest level AuthorizeUser grants in the channel
+	RateLimits     []ratelimit.Capacity
+}
+
+// Role names the rule that decides the user's access, e.g. "admin" or
+// "external (read-only)"
+func (a *Access) Role() string {
+	switch {
+	case !a.BannedUntil.IsZero():
+		return "banned"
+	case !a.UserAllowed:
+		return "not allowed"
+	case a.External:
+		return fmt.Sprintf("external (%s)", a.ExternalPolicy)
+	case a.Admin:
+		return "admin"
+	default:
+		return "user"
+	}
+}
+
+// Access explains what a user may do in a channel. Unlike AuthorizeUser it
+// changes nothing: users aren't created, bans don't expire, and no rate
+// limit capacity is taken.
+func (s *Service) Access(userID, channelID string) *Access {
+	now := time.Now()
+	access := &Access{
+		Admin:          s.config.IsUserAdmin(userID),
+		UserAllowed:    s.config.IsUserAllowed(userID),
+		ChannelAllowed: s.config.IsChannelAllowed(channelID),
+		ExternalPolicy: s.config.ExternalUserPolicy,
+		RateLimits:     s.rateLimiter.Peek(userID, channelID, now),
+	}
+
+	permissions := s.getDefaultPermissions(userID)
+	s.mu.RLock()
+	if bannedUntil, ok := s.bannedUsers[userID]; ok && now.Before(bannedUntil) {
+		access.BannedUntil = bannedUntil
+	}
+	if user, ok := s.users[userID]; ok {
+		access.Admin = user.IsAdmin
+		access.External = user.IsExternal
+		permissions = user.Permissions
+	}
+	s.mu.RUnlock()
+
+	if !access.BannedUntil.IsZero() || !access.UserAllowed || !access.ChannelAllowed {
+		return access
+	}
+	for _, permission := range permissions {
+		access.Permission = max(access.Permission, permission)
+	}
+	if access.External {
+		switch access.ExternalPolicy {
+		case config.ExternalUserAllow:
+		case config.ExternalUserReadOnly:
+			access.Permission = min(access.Permission, PermissionRead)
+		default:
+			access.Permission = PermissionNone
+		}
+	}
+	return access
+}
+
 // BanUser bans a user for a specific duration
 func (s *Service) BanUser(userID string, duration time.Duration) error {
 	s.mu.Lock()
@@ -212,6 +523,7 @@ func (s *Service) BanUser(userID string, duration time.Duration) error {
 
 	s.logger.Info("Banned user",
 		zap.String("user_id", userID),
+		zap.String("user_name", s.displayNameLocked(userID)),
 		zap.Duration("duration", duration),
 		zap.Time("until", until))
 
@@ -300,6 +612,12 @@ func (s *Service) RegisterChannel(channelID, name, channelType string) error {
 	return nil
 }
 
+// IsBanned reports whether a user is currently banned. Commands that need no
+// permission still refuse banned users.
+func (s *Service) IsBanned(userID string) bool {
+	return s.isUserBanned(userID)
+}
+
 // isUserBanned checks if a user is currently banned
 func (s *Service) isUserBanned(userID string) bool {
 	s.mu.RLock()
@@ -321,41 +639,28 @@ func (s *Service) isUserBanned(userID string) bool {
 	return true
 }
 
-// checkRateLimit checks and updates rate limiting for a user
-func (s *Service) checkRateLimit(userID string) (bool, time.Time) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
+// CheckRateLimit takes one request from the user's and the channel's
+// buckets. It returns a *RateLimitError if either is out of capacity. Call
+// it once per incoming request, not per authorization check.
+func (s *Service) CheckRateLimit(userID, channelID string) error {
 	now := time.Now()
-	entry, exists := s.rateLimitMap[userID]
-
-	if !exists {
-		// Create new entry
-		s.rateLimitMap[userID] = &RateLimitEntry{
-			Count:     1,
-			Window:    now,
-			LastReset: now,
-		}
-		return false, time.Time{}
-	}
-
-	// Reset counter if window has passed
-	if now.Sub(entry.Window) > time.Minute {
-		entry.Count = 1
-		entry.Window = now
-		entry.LastReset = now
-		return false, time.Time{}
+	decision := s.rateLimiter.Allow(userID, channelID, now)
+	if decision.Allowed {
+		return nil
 	}
 
-	// Check if rate limit exceeded
-	if entry.Count >= s.config.RateLimitPerMinute {
-		nextWindow := entry.Window.Add(time.Minute)
-		return true, nextWindow
+	s.logger.Warn("Rate limited request",
+		zap.String("user_id", userID),
+		zap.String("user_name", s.DisplayName(userID)),
+		zap.String("channel_id", channelID),
+		zap.String("scope", decision.Scope),
+		zap.Time("retry_at", decision.RetryAt))
+	return &RateLimitError{
+		Scope:      decision.Scope,
+		RetryAfter: decision.RetryAfter(now),
+		RetryAt:    decision.RetryAt,
+		Limit:      decision.Limit,
 	}
-
-	// Increment counter
-	entry.Count++
-	return false, time.Time{}
 }
 
 // getDefaultPermissions returns default permissions for a user
@@ -392,7 +697,12 @@ func (s *Service) hasPermission(user *UserInfo, required Permission) bool {
 
 // permissionToString converts permission to string
 func (s *Service) permissionToString(permission Permission) string {
-	switch permission {
+	return permission.String()
+}
+
+// String returns the permission level name
+func (p Permission) String() string {
+	switch p {
 	case PermissionNone:
 		return "none"
 	case PermissionRead:
@@ -433,7 +743,22 @@ func (s *Service) GetStats() map[string]interface{} {
 		}
 	}
 
+	// Most recently active users by name
+	recent := make([]*UserInfo, 0, len(s.users))
+	for _, user := range s.users {
+		recent = append(recent, user)
+	}
+	sort.Slice(recent, func(i, j int) bool { return recent[i].LastSeen.After(recent[j].LastSeen) })
+	var recentUsers []string
+	for i, user := range recent {
+		if i >= 5 {
+			break
+		}
+		recentUsers = append(recentUsers, s.displayNameLocked(user.ID))
+	}
+
 	return map[string]interface{}{
+		"recent_users":   recentUsers,
 		"total_users":    totalUsers,
 		"admin_users":    adminUsers,
 		"banned_users":   bannedUsers,
@@ -457,23 +782,19 @@ func (s *Service) CleanupExpiredEntries() {
 		}
 	}
 
-	// Clean up old rate limit entries
-	for userID, entry := range s.rateLimitMap {
-		if now.Sub(entry.LastReset) > time.Hour {
-			delete(s.rateLimitMap, userID)
-		}
-	}
+	// Drop rate limit buckets that have refilled
+	s.rateLimiter.Prune(now)
 }
 
 // ValidateSlackSignature validates Slack request signature using HMAC SHA256
 func (s *Service) ValidateSlackSignature(timestamp, signature, body string) bool {
 	// Early validation
-	if timestamp == "" || signature == "" || body == "" || s.config.SlackSigningSecret == "" {
+	if timestamp == "" || signature == "" || body == "" || s.config.SlackCredentials().SigningSecret == "" {
 		s.logger.Warn("Invalid parameters for signature validation",
 			zap.Bool("has_timestamp", timestamp != ""),
 			zap.Bool("has_signature", signature != ""),
 			zap.Bool("has_body", body != ""),
-			zap.Bool("has_secret", s.config.SlackSigningSecret != ""))
+			zap.Bool("has_secret", s.config.SlackCredentials().SigningSecret != ""))
 		return false
 	}
 
@@ -496,7 +817,7 @@ func (s *Service) ValidateSlackSignature(timestamp, signature, body string) bool
 	baseString := fmt.Sprintf("v0:%s:%s", timestamp, body)
 
 	// Create HMAC SHA256 hash using signing secret
-	hash := hmac.New(sha256.New, []byte(s.config.SlackSigningSecret))
+	hash := hmac.New(sha256.New, []byte(s.config.SlackCredentials().SigningSecret))
 	hash.Write([]byte(baseString))
 
 	// Compare signatures using constant-time comparison
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/ratelimit"
+)
+
+func TestIsExternalUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile UserProfile
+		want    bool
+	}{
+		{"same team", UserProfile{TeamID: "T1"}, false},
+		{"other team", UserProfile{TeamID: "T2"}, true},
+		{"stranger", UserProfile{TeamID: "T1", IsStranger: true}, true},
+		{"same grid org", UserProfile{TeamID: "T2", EnterpriseID: "E1"}, false},
+		{"team unknown", UserProfile{}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isExternalUser(&tt.profile, "T1", "E1"); got != tt.want {
+			t.Errorf("%s: isExternalUser = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizeUser_ExternalPolicy(t *testing.T) {
+	tests := []struct {
+		policy    config.ExternalUserPolicy
+		readOK    bool
+		executeOK bool
+	}{
+		{config.ExternalUserDeny, false, false},
+		{config.ExternalUserReadOnly, true, false},
+		{config.ExternalUserAllow, true, true},
+	}
+
+	for _, tt := range tests {
+		cfg := &config.Config{
+			RateLimitPerMinute:  100,
+			UserProfileCacheTTL: time.Hour,
+			ExternalUserPolicy:  tt.policy,
+		}
+		service := NewService(cfg, zap.NewNop())
+		service.SetHomeTeam("T1", "")
+		service.SetProfileFetcher(func(userID string) (*UserProfile, error) {
+			if userID == "U_EXT" {
+				return &UserProfile{Name: "guest", TeamID: "T2"}, nil
+			}
+			return &UserProfile{Name: "member", TeamID: "T1"}, nil
+		})
+
+		check := func(userID string, permission Permission) bool {
+			return service.AuthorizeUser(&AuthContext{UserID: userID, ChannelID: "C1"}, permission) == nil
+		}
+
+		if got := check("U_EXT", PermissionRead); got != tt.readOK {
+			t.Errorf("%s: external read allowed = %v, want %v", tt.policy, got, tt.readOK)
+		}
+		if got := check("U_EXT", PermissionExecute); got != tt.executeOK {
+			t.Errorf("%s: external execute allowed = %v, want %v", tt.policy, got, tt.executeOK)
+		}
+		if !check("U_INT", PermissionExecute) {
+			t.Errorf("%s: internal user was denied", tt.policy)
+		}
+	}
+}
+
+func TestCheckRateLimit(t *testing.T) {
+	cfg := &config.Config{RateLimitPerMinute: 1, RateLimitBurst: 2}
+	service := NewService(cfg, zap.NewNop())
+
+	for i := 0; i < 2; i++ {
+		if err := service.CheckRateLimit("U1", "C1"); err != nil {
+			t.Fatalf("Request %d within the burst was refused: %v", i+1, err)
+		}
+	}
+
+	// Authorization checks don't count against the limit
+	if err := service.AuthorizeUser(&AuthContext{UserID: "U1", ChannelID: "C1"}, PermissionRead); err != nil {
+		t.Fatalf("AuthorizeUser failed: %v", err)
+	}
+
+	err := service.CheckRateLimit("U1", "C1")
+	var limited *RateLimitError
+	if !errors.As(err, &limited) {
+		t.Fatalf("Expected a *RateLimitError, got %v", err)
+	}
+	if limited.Scope != ratelimit.ScopeUser || limited.Limit != 2 || limited.RetryAfter <= 0 || limited.RetryAfter > time.Minute {
+		t.Errorf("Unexpected rate limit error: %+v", limited)
+	}
+}
+
+func TestIdentities(t *testing.T) {
+	s := NewService(&config.Config{}, zap.NewNop())
+	s.SetIdentities(map[string]string{"U1": "jdoe", "U2": "sam@example.com"})
+	s.SetIdentity("U2", "")
+	s.SetIdentity("U3", "alice")
+
+	for userID, want := range map[string]string{"U1": "jdoe", "U2": "", "U3": "alice", "U4": ""} {
+		if got := s.Identity(userID); got != want {
+			t.Errorf("Identity(%q) = %q, want %q", userID, got, want)
+		}
+	}
+}
+
+func TestAccess(t *testing.T) {
+	cfg := &config.Config{
+		AdminUsers:          []string{"U_ADMIN"},
+		AllowedUsers:        []string{"U_ADMIN", "U1", "U_EXT"},
+		AllowedChannels:     []string{"C1"},
+		ExternalUserPolicy:  config.ExternalUserReadOnly,
+		RateLimitPerMinute:  1,
+		RateLimitBurst:      2,
+		UserProfileCacheTTL: time.Hour,
+	}
+	service := NewService(cfg, zap.NewNop())
+	service.SetHomeTeam("T1", "")
+	service.SetProfileFetcher(func(userID string) (*UserProfile, error) {
+		if userID == "U_EXT" {
+			return &UserProfile{TeamID: "T2"}, nil
+		}
+		return &UserProfile{TeamID: "T1"}, nil
+	})
+	if err := service.AuthorizeUser(&AuthContext{UserID: "U_EXT", ChannelID: "C1"}, PermissionRead); err != nil {
+		t.Fatalf("AuthorizeUser failed: %v", err)
+	}
+	if err := service.BanUser("U1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		user, channel string
+		role          string
+		permission    Permission
+	}{
+		{"U_ADMIN", "C1", "admin", PermissionAdmin},
+		{"U_ADMIN", "C2", "admin", PermissionNone},
+		{"U1", "C1", "banned", PermissionNone},
+		{"U2", "C1", "not allowed", PermissionNone},
+		{"U_EXT", "C1", "external (read-only)", PermissionRead},
+	}
+	for _, tt := range tests {
+		access := service.Access(tt.user, tt.channel)
+		if access.Role() != tt.role || access.Permission != tt.permission {
+			t.Errorf("Access(%s, %s) = %s with %s, want %s with %s",
+				tt.user, tt.channel, access.Role(), access.Permission, tt.role, tt.permission)
+		}
+	}
+
+	// Explaining access takes no rate limit capacity
+	for i := 0; i < 3; i++ {
+		service.Access("U_ADMIN", "C1")
+	}
+	if err := service.CheckRateLimit("U_ADMIN", "C1"); err != nil {
+		t.Errorf("Expected Access to leave the rate limit alone, got %v", err)
+	}
+	if limits := service.Access("U_ADMIN", "C1").RateLimits; len(limits) != 1 || limits[0].Remaining != 1 {
+		t.Errorf("Expected one user bucket with 1 request left, got %+v", limits)
+	}
+}
@@ -0,0 +1,426 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/httpauth"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+// adminAPIPrefix is where the admin API used by claudectl is served
+const adminAPIPrefix = "/admin/"
+
+const (
+	defaultAdminListLimit = 50
+	maxAdminListLimit     = 500
+)
+
+// maxAdminRequestBody caps admin request bodies, which are tiny JSON objects
+const maxAdminRequestBody = 4 << 10
+
+// adminSession is a session as the admin API lists it
+type adminSession struct {
+	ID           string    `json:"id"`
+	ChannelID    string    `json:"channel_id"`
+	WorkDir      string    `json:"work_dir"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastActivity time.Time `json:"last_activity"`
+	Active       bool      `json:"active"`
+	Processing   bool      `json:"processing"`
+}
+
+// adminChannel is a channel's state as the admin API reports it. Fields the
+// session manager doesn't store are left out.
+type adminChannel struct {
+	ChannelID           string     `json:"channel_id"`
+	LifecycleState      string     `json:"lifecycle_state,omitempty"`
+	SessionMode         string     `json:"session_mode,omitempty"`
+	Permission          string     `json:"permission,omitempty"`
+	PermissionExpiresAt *time.Time `json:"permission_expires_at,omitempty"`
+	RunPriority         string     `json:"run_priority,omitempty"`
+	ActiveSessionID     string     `json:"active_session_id,omitempty"`
+	Processing          bool       `json:"processing"`
+	Runs                int        `json:"runs"`            // Queued or running
+	PendingPrompts      int        `json:"pending_prompts"` // Scheduled with /later
+}
+
+// adminClearResult is what clearing a channel's queue canceled
+type adminClearResult struct {
+	ChannelID       string `json:"channel_id"`
+	CanceledRuns    int    `json:"canceled_runs"`
+	CanceledPrompts int    `json:"canceled_prompts"`
+}
+
+// adminCompactRequest asks for a channel's session to be compacted as user,
+// who must be allowed to run Claude there
+type adminCompactRequest struct {
+	User string `json:"user"`
+}
+
+// adminError is the body of every failed admin request
+type adminError struct {
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+type adminIdentityKey struct{}
+
+// adminActorFallback stands in for the operator when the auth policy can't
+// tell who made a request, e.g. with a shared bearer token
+const adminActorFallback = "admin-api"
+
+// adminActor is who to record as making an admin API change: the subject the
+// auth policy authenticated, or adminActorFallback
+func adminActor(r *http.Request) string {
+	if identity, ok := r.Context().Value(adminIdentityKey{}).(*httpauth.Identity); ok && identity.Subject != "" {
+		return identity.Subject
+	}
+	return adminActorFallback
+}
+
+// adminAPI serves the admin API: session listings and conversation trees,
+// channel state, switching sessions, permission modes, deleting sessions,
+// clearing queues, compaction, and recent errors, for operators using
+// claudectl and internal tooling
+func (s *Service) adminAPI() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity := s.httpAuthenticate(w, r, config.HTTPGroupAdmin, s.writeAdminError)
+		if identity == nil {
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), adminIdentityKey{}, identity))
+
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, adminAPIPrefix), "/"), "/")
+		switch {
+		case len(parts) == 1 && parts[0] == "sessions":
+			s.adminOnly(w, r, http.MethodGet, s.handleAdminSessions)
+		case len(parts) == 2 && parts[0] == "sessions" && adminSessionIDPattern.MatchString(parts[1]):
+			s.adminMethods(w, r, map[string]http.HandlerFunc{
+				http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+					s.handleAdminSession(w, r, parts[1])
+				},
+				http.MethodDelete: func(w http.ResponseWriter, r *http.Request) {
+					s.handleAdminDeleteSession(w, r, parts[1])
+				},
+			})
+		case len(parts) == 3 && parts[0] == "sessions" && adminSessionIDPattern.MatchString(parts[1]) && parts[2] == "tree":
+			s.adminOnly(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+				s.handleAdminSessionTree(w, r, parts[1])
+			})
+		case len(parts) == 1 && parts[0] == "errors":
+			s.adminOnly(w, r, http.MethodGet, s.handleAdminErrors)
+		case len(parts) == 2 && parts[0] == "channels" && slackChannelPattern.MatchString(parts[1]):
+			s.adminOnly(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+				s.handleAdminChannel(w, r, parts[1])
+			})
+		case len(parts) == 3 && parts[0] == "channels" && slackChannelPattern.MatchString(parts[1]) && parts[2] == "clear-queue":
+			s.adminOnly(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+				s.handleAdminClearQueue(w, r, parts[1])
+			})
+		case len(parts) == 3 && parts[0] == "channels" && slackChannelPattern.MatchString(parts[1]) && parts[2] == "session":
+			s.adminOnly(w, r, http.MethodPut, func(w http.ResponseWriter, r *http.Request) {
+				s.handleAdminSwitchSession(w, r, parts[1])
+			})
+		case len(parts) == 3 && parts[0] == "channels" && slackChannelPattern.MatchString(parts[1]) && parts[2] == "permission":
+			s.adminOnly(w, r, http.MethodPut, func(w http.ResponseWriter, r *http.Request) {
+				s.handleAdminSetPermission(w, r, parts[1])
+			})
+		case len(parts) == 3 && parts[0] == "channels" && slackChannelPattern.MatchString(parts[1]) && parts[2] == "compact":
+			s.adminOnly(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+				s.handleAdminCompact(w, r, parts[1])
+			})
+		default:
+			s.writeAdminError(w, r, http.StatusNotFound, "no such admin endpoint")
+		}
+	})
+}
+
+// adminOnly calls handler if the request uses method
+func (s *Service) adminOnly(w http.ResponseWriter, r *http.Request, method string, handler http.HandlerFunc) {
+	s.adminMethods(w, r, map[string]http.HandlerFunc{method: handler})
+}
+
+// adminMethods calls the handler for the request's method
+func (s *Service) adminMethods(w http.ResponseWriter, r *http.Request, handlers map[string]http.HandlerFunc) {
+	handler, ok := handlers[r.Method]
+	if !ok {
+		allowed := make([]string, 0, len(handlers))
+		for method := range handlers {
+			allowed = append(allowed, method)
+		}
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		s.writeAdminError(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("use %s", strings.Join(allowed, " or ")))
+		return
+	}
+	handler(w, r)
+}
+
+// readAdminBody decodes a request's small JSON body into v
+func readAdminBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAdminRequestBody))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// writeAdminJSON answers with v as JSON
+func (s *Service) writeAdminJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.requestLogger(r).Warn("Failed to write admin API response", zap.Error(err))
+	}
+}
+
+// writeAdminError answers with message and the request ID to quote
+func (s *Service) writeAdminError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	s.writeAdminJSON(w, r, status, adminError{Error: message, RequestID: requestID(r)})
+}
+
+// adminLimit reads the limit query parameter, defaulting and capping it
+func adminLimit(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultAdminListLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("limit must be a positive number, got %q", value)
+	}
+	if limit > maxAdminListLimit {
+		limit = maxAdminListLimit
+	}
+	return limit, nil
+}
+
+// handleAdminSessions lists recent sessions, optionally in one channel
+func (s *Service) handleAdminSessions(w http.ResponseWriter, r *http.Request) {
+	limit, err := adminLimit(r)
+	if err != nil {
+		s.writeAdminError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	channelID := r.URL.Query().Get("channel")
+	if channelID != "" && !slackChannelPattern.MatchString(channelID) {
+		s.writeAdminError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid channel %q", channelID))
+		return
+	}
+
+	var sessions []session.SessionInfo
+	if lister, ok := s.sessionManager.(session.ChannelTypeManager); ok && channelID != "" {
+		sessions, err = lister.ListSessionsForChannel(channelID, "", limit)
+	} else {
+		sessions, err = s.sessionManager.ListAllSessions(limit)
+	}
+	if err != nil {
+		s.requestLogger(r).Error("Failed to list sessions for admin API", zap.Error(err))
+		s.writeAdminError(w, r, http.StatusInternalServerError, "failed to list sessions")
+		return
+	}
+
+	result := []adminSession{}
+	for _, info := range sessions {
+		if channelID != "" && info.GetChannelID() != channelID {
+			continue
+		}
+		result = append(result, adminSession{
+			ID:           info.GetID(),
+			ChannelID:    info.GetChannelID(),
+			WorkDir:      info.GetCurrentWorkDir(),
+			CreatedAt:    info.GetCreatedAt(),
+			LastActivity: info.GetLastActivity(),
+			Active:       info.IsActive(),
+			Processing:   s.sessionManager.IsProcessing(info.GetID()),
+		})
+	}
+	s.writeAdminJSON(w, r, http.StatusOK, result)
+}
+
+// handleAdminChannel reports a channel's stored state and what is queued in it
+func (s *Service) handleAdminChannel(w http.ResponseWriter, r *http.Request, channelID string) {
+	result := adminChannel{ChannelID: channelID, Runs: s.channelRuns.count(channelID)}
+
+	if reader, ok := s.sessionManager.(session.ChannelStateReader); ok {
+		state, err := reader.GetChannelState(channelID)
+		if err != nil {
+			s.requestLogger(r).Error("Failed to load channel state for admin API", zap.String("channel_id", channelID), zap.Error(err))
+			s.writeAdminError(w, r, http.StatusInternalServerError, "failed to load channel state")
+			return
+		}
+		if state == nil {
+			s.writeAdminError(w, r, http.StatusNotFound, fmt.Sprintf("the bot has no state for %s", channelID))
+			return
+		}
+		result.SessionMode = state.SessionMode
+		result.Permission = state.Permission
+		result.PermissionExpiresAt = state.PermissionExpiresAt
+		if state.RunPriority != nil {
+			result.RunPriority = *state.RunPriority
+		}
+		if branches, ok := s.sessionManager.(session.SessionBranchManager); ok && state.ActiveSessionID != nil {
+			if active, err := branches.LoadSessionByID(*state.ActiveSessionID); err == nil && active != nil {
+				result.ActiveSessionID = active.SessionID
+				result.Processing = s.sessionManager.IsProcessing(active.SessionID)
+			}
+		}
+	}
+
+	if lifecycle, ok := s.sessionManager.(session.ChannelLifecycleManager); ok {
+		if state, err := lifecycle.GetChannelLifecycleState(channelID); err == nil {
+			result.LifecycleState = state
+		}
+	}
+	if s.delayedPrompts != nil {
+		if prompts, err := s.delayedPrompts.ListPendingDelayedPrompts(channelID); err == nil {
+			result.PendingPrompts = len(prompts)
+		}
+	}
+	s.writeAdminJSON(w, r, http.StatusOK, result)
+}
+
+// handleAdminClearQueue cancels a channel's queued and running runs and its
+// pending /later prompts. Session processing flags are left to the canceled
+// runs, which clear their own as they wind down.
+func (s *Service) handleAdminClearQueue(w http.ResponseWriter, r *http.Request, channelID string) {
+	logger := s.requestLogger(r).With(zap.String("channel_id", channelID))
+	result := adminClearResult{ChannelID: channelID, CanceledRuns: s.channelRuns.cancel(channelID, errRunsCleared)}
+
+	if s.delayedPrompts != nil {
+		canceled, err := s.delayedPrompts.CancelChannelDelayedPrompts(channelID)
+		if err != nil {
+			logger.Error("Failed to cancel delayed prompts for admin API", zap.Error(err))
+			s.writeAdminError(w, r, http.StatusInternalServerError, "canceled runs, but failed to cancel scheduled prompts")
+			return
+		}
+		result.CanceledPrompts = canceled
+	}
+
+	logger.Info("Cleared channel queue from admin API",
+		zap.Int("canceled_runs", result.CanceledRuns),
+		zap.Int("canceled_prompts", result.CanceledPrompts))
+	s.writeAdminJSON(w, r, http.StatusOK, result)
+}
+
+// handleAdminCompact starts compacting the channel's current session as the
+// given user and answers right away; the result is posted in the channel
+func (s *Service) handleAdminCompact(w http.ResponseWriter, r *http.Request, channelID string) {
+	var req adminCompactRequest
+	if err := readAdminBody(w, r, &req); err != nil {
+		s.writeAdminError(w, r, http.StatusBadRequest, "body must be a JSON object like {\"user\": \"U123\"}")
+		return
+	}
+	if !slackUserPattern.MatchString(req.User) {
+		s.writeAdminError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid user %q", req.User))
+		return
+	}
+	if s.channelInactive(channelID) {
+		s.writeAdminError(w, r, http.StatusConflict, fmt.Sprintf("%s is archived", channelID))
+		return
+	}
+
+	authCtx := &auth.AuthContext{UserID: req.User, ChannelID: channelID, Timestamp: time.Now()}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionExecute); err != nil {
+		s.writeAdminError(w, r, http.StatusForbidden, fmt.Sprintf("%s may not run Claude in %s: %v", req.User, channelID, err))
+		return
+	}
+
+	userSession, err := s.sessionManager.GetOrCreateSession(req.User, channelID)
+	if err != nil {
+		s.requestLogger(r).Error("Failed to get session for admin compaction", zap.String("channel_id", channelID), zap.Error(err))
+		s.writeAdminError(w, r, http.StatusInternalServerError, "failed to get the channel's session")
+		return
+	}
+	if s.sessionManager.IsProcessing(userSession.GetID()) {
+		s.writeAdminError(w, r, http.StatusConflict, fmt.Sprintf("session %s is busy; try again when it's idle", userSession.GetID()))
+		return
+	}
+
+	s.requestLogger(r).Info("Compacting session from admin API",
+		zap.String("channel_id", channelID),
+		zap.String("user_id", req.User),
+		zap.String("session_id", userSession.GetID()))
+	go s.runAdminCompaction(channelID, req.User)
+
+	s.writeAdminJSON(w, r, http.StatusAccepted, map[string]string{
+		"channel_id": channelID,
+		"session_id": userSession.GetID(),
+		"status":     "compacting",
+	})
+}
+
+// runAdminCompaction runs /compact in the channel's session under a marker
+// message, so the channel sees why its context changed
+func (s *Service) runAdminCompaction(channelID, userID string) {
+	threadTS := s.sendThreadResponse(channelID, "",
+		fmt.Sprintf("🗜️ **Compacting this session** at an operator's request, as <@%s>", userID))
+
+	event := &slackevents.MessageEvent{
+		Type:            "message",
+		User:            userID,
+		Text:            "/compact",
+		Channel:         channelID,
+		ThreadTimeStamp: threadTS,
+	}
+	if response := s.processClaudeMessage(context.Background(), event, "/compact", runOverrides{}); response != "" {
+		s.sendThreadResponseOrHold(channelID, threadTS, response)
+	}
+}
+
+// handleAdminErrors returns recent error-level log entries, oldest first.
+// since limits them to entries after a time, which claudectl errors -f polls
+// with; level lowers the threshold, e.g. to warn.
+func (s *Service) handleAdminErrors(w http.ResponseWriter, r *http.Request) {
+	limit, err := adminLimit(r)
+	if err != nil {
+		s.writeAdminError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	minLevel := zapcore.ErrorLevel
+	if value := r.URL.Query().Get("level"); value != "" {
+		if minLevel, err = zapcore.ParseLevel(value); err != nil {
+			s.writeAdminError(w, r, http.StatusBadRequest, fmt.Sprintf("unknown level %q", value))
+			return
+		}
+	}
+
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		if since, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			s.writeAdminError(w, r, http.StatusBadRequest, fmt.Sprintf("since must be an RFC 3339 time, got %q", value))
+			return
+		}
+	}
+
+	entries := []logging.LogEntry{}
+	if s.logBuffer != nil {
+		for _, entry := range s.logBuffer.Entries() {
+			level, err := zapcore.ParseLevel(entry.Level)
+			if err != nil || level < minLevel || !entry.Time.After(since) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	s.writeAdminJSON(w, r, http.StatusOK, entries)
+}
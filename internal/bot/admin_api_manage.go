@@ -0,0 +1,231 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+// adminSessionIDPattern matches the session IDs Claude hands out
+var adminSessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// adminSessionDetail is one session as the admin API describes it
+type adminSessionDetail struct {
+	ID         string    `json:"id"`
+	WorkDir    string    `json:"work_dir"`
+	SystemUser string    `json:"system_user,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Exchanges  int       `json:"exchanges"`
+	Processing bool      `json:"processing"`
+}
+
+// adminExchange is one prompt and reply in a session's conversation tree
+type adminExchange struct {
+	SessionID         string    `json:"session_id"`
+	PreviousSessionID string    `json:"previous_session_id,omitempty"`
+	Prompt            string    `json:"prompt,omitempty"`
+	Response          string    `json:"response,omitempty"`
+	Summary           string    `json:"summary,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// adminSwitchRequest points a channel at another session
+type adminSwitchRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+// adminPermissionRequest sets a channel's permission mode, reverting to the
+// channel default after TTL when it's given
+type adminPermissionRequest struct {
+	Mode string `json:"mode"`
+	TTL  string `json:"ttl,omitempty"`
+}
+
+// handleAdminSession describes a session, like /session info
+func (s *Service) handleAdminSession(w http.ResponseWriter, r *http.Request, sessionID string) {
+	stored, err := s.sessionManager.GetSessionBySessionID(sessionID)
+	if err != nil {
+		s.requestLogger(r).Error("Failed to load session for admin API", zap.String("session_id", sessionID), zap.Error(err))
+		s.writeAdminError(w, r, http.StatusInternalServerError, "failed to load session")
+		return
+	}
+	if stored == nil {
+		s.writeAdminError(w, r, http.StatusNotFound, fmt.Sprintf("session %s not found", sessionID))
+		return
+	}
+
+	result := adminSessionDetail{
+		ID:         stored.SessionID,
+		WorkDir:    stored.WorkingDirectory,
+		SystemUser: stored.SystemUser,
+		CreatedAt:  stored.CreatedAt,
+		UpdatedAt:  stored.UpdatedAt,
+		Processing: s.sessionManager.IsProcessing(stored.SessionID),
+	}
+	if children, err := s.sessionManager.GetConversationTree(sessionID); err == nil {
+		result.Exchanges = len(children)
+	}
+	s.writeAdminJSON(w, r, http.StatusOK, result)
+}
+
+// handleAdminSessionTree returns a session's exchanges, oldest first
+func (s *Service) handleAdminSessionTree(w http.ResponseWriter, r *http.Request, sessionID string) {
+	children, err := s.sessionManager.GetConversationTree(sessionID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			s.writeAdminError(w, r, http.StatusNotFound, fmt.Sprintf("session %s not found", sessionID))
+			return
+		}
+		s.requestLogger(r).Error("Failed to load conversation tree for admin API", zap.String("session_id", sessionID), zap.Error(err))
+		s.writeAdminError(w, r, http.StatusInternalServerError, "failed to load conversation tree")
+		return
+	}
+
+	result := make([]adminExchange, 0, len(children))
+	for _, child := range children {
+		result = append(result, adminExchange{
+			SessionID:         child.SessionID,
+			PreviousSessionID: derefString(child.PreviousSessionID),
+			Prompt:            derefString(child.UserPrompt),
+			Response:          derefString(child.AIResponse),
+			Summary:           derefString(child.Summary),
+			CreatedAt:         child.CreatedAt,
+		})
+	}
+	s.writeAdminJSON(w, r, http.StatusOK, result)
+}
+
+// handleAdminDeleteSession deletes a session like /delete, moving it to the
+// trash when the session manager keeps one, recorded as deleted by the
+// request's operator
+func (s *Service) handleAdminDeleteSession(w http.ResponseWriter, r *http.Request, sessionID string) {
+	var err error
+	trashMgr, canTrash := s.sessionManager.(session.SessionTrashManager)
+	if canTrash {
+		err = trashMgr.TrashSession(sessionID, adminActor(r))
+	} else {
+		err = s.sessionManager.DeleteSession(sessionID)
+	}
+	switch {
+	case isSessionBusy(err):
+		s.writeAdminError(w, r, http.StatusConflict, fmt.Sprintf("session %s has a run in progress", sessionID))
+		return
+	case errors.Is(err, session.ErrSessionNotFound), err != nil && strings.Contains(err.Error(), "not found"):
+		s.writeAdminError(w, r, http.StatusNotFound, fmt.Sprintf("session %s not found", sessionID))
+		return
+	case err != nil:
+		s.requestLogger(r).Error("Failed to delete session from admin API", zap.String("session_id", sessionID), zap.Error(err))
+		s.writeAdminError(w, r, http.StatusInternalServerError, "failed to delete session")
+		return
+	}
+
+	s.requestLogger(r).Info("Deleted session from admin API",
+		zap.String("session_id", sessionID),
+		zap.String("deleted_by", adminActor(r)),
+		zap.Bool("trashed", canTrash))
+	s.writeAdminJSON(w, r, http.StatusOK, map[string]interface{}{
+		"session_id": sessionID,
+		"trashed":    canTrash,
+	})
+}
+
+// handleAdminSwitchSession points a channel's shared session at another
+// session, like /session <id>, and answers with the channel's new state
+func (s *Service) handleAdminSwitchSession(w http.ResponseWriter, r *http.Request, channelID string) {
+	var req adminSwitchRequest
+	if err := readAdminBody(w, r, &req); err != nil {
+		s.writeAdminError(w, r, http.StatusBadRequest, "body must be a JSON object like {\"session_id\": \"...\"}")
+		return
+	}
+	if !adminSessionIDPattern.MatchString(req.SessionID) {
+		s.writeAdminError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid session %q", req.SessionID))
+		return
+	}
+	if s.channelInactive(channelID) {
+		s.writeAdminError(w, r, http.StatusConflict, fmt.Sprintf("%s is archived", channelID))
+		return
+	}
+
+	if err := s.sessionManager.SwitchToSessionInChannel(channelID, req.SessionID); err != nil {
+		switch {
+		case isSessionBusy(err):
+			s.writeAdminError(w, r, http.StatusConflict, "a run is in progress in the channel or the session")
+		case strings.Contains(err.Error(), "not found"):
+			s.writeAdminError(w, r, http.StatusNotFound, fmt.Sprintf("session %s not found", req.SessionID))
+		default:
+			s.requestLogger(r).Error("Failed to switch session from admin API", zap.String("channel_id", channelID), zap.Error(err))
+			s.writeAdminError(w, r, http.StatusInternalServerError, "failed to switch session")
+		}
+		return
+	}
+
+	s.requestLogger(r).Info("Switched channel session from admin API",
+		zap.String("channel_id", channelID),
+		zap.String("session_id", req.SessionID))
+	s.handleAdminChannel(w, r, channelID)
+}
+
+// handleAdminSetPermission sets a channel's permission mode, like
+// /permission <mode> [duration], and answers with the channel's new state
+func (s *Service) handleAdminSetPermission(w http.ResponseWriter, r *http.Request, channelID string) {
+	var req adminPermissionRequest
+	if err := readAdminBody(w, r, &req); err != nil {
+		s.writeAdminError(w, r, http.StatusBadRequest, "body must be a JSON object like {\"mode\": \"plan\", \"ttl\": \"30m\"}")
+		return
+	}
+	mode := config.PermissionMode(req.Mode)
+	if !mode.IsValid() {
+		s.writeAdminError(w, r, http.StatusBadRequest, fmt.Sprintf("unknown permission mode %q", req.Mode))
+		return
+	}
+
+	var err error
+	if req.TTL != "" {
+		ttl, parseErr := time.ParseDuration(req.TTL)
+		if parseErr != nil || ttl <= 0 {
+			s.writeAdminError(w, r, http.StatusBadRequest, fmt.Sprintf("ttl must be a positive duration such as 30m, got %q", req.TTL))
+			return
+		}
+		expiryMgr, ok := s.sessionManager.(session.ChannelPermissionExpiryManager)
+		if !ok {
+			s.writeAdminError(w, r, http.StatusNotImplemented, "permission expiry requires database persistence")
+			return
+		}
+		_, err = expiryMgr.SetPermissionModeForChannelWithExpiry(channelID, mode, ttl)
+	} else {
+		channelPermMgr, ok := s.sessionManager.(session.ChannelPermissionManager)
+		if !ok {
+			s.writeAdminError(w, r, http.StatusNotImplemented, "channel permission modes require database persistence")
+			return
+		}
+		err = channelPermMgr.SetPermissionModeForChannel(channelID, mode)
+	}
+	if err != nil {
+		s.requestLogger(r).Error("Failed to set permission mode from admin API", zap.String("channel_id", channelID), zap.Error(err))
+		s.writeAdminError(w, r, http.StatusInternalServerError, "failed to set permission mode")
+		return
+	}
+
+	s.requestLogger(r).Info("Set channel permission mode from admin API",
+		zap.String("channel_id", channelID),
+		zap.String("mode", string(mode)),
+		zap.String("ttl", req.TTL))
+	s.handleAdminChannel(w, r, channelID)
+}
+
+// derefString returns what s points at, or "" for nil
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,429 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/httpauth"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+const testAdminToken = "0123456789abcdef-admin"
+
+// adminTestManager serves the few session manager calls the admin API makes
+type adminTestManager struct {
+	session.SessionManager
+	sessions   []session.SessionInfo
+	processing map[string]bool
+	channels   map[string]*repository.SlackChannel
+	deleted    []string
+	activeID   int
+}
+
+func (m *adminTestManager) ListAllSessions(limit int) ([]session.SessionInfo, error) {
+	return m.sessions, nil
+}
+
+func (m *adminTestManager) IsProcessing(sessionID string) bool {
+	return m.processing[sessionID]
+}
+
+func (m *adminTestManager) GetChannelState(channelID string) (*repository.SlackChannel, error) {
+	return m.channels[channelID], nil
+}
+
+func (m *adminTestManager) GetSessionBySessionID(sessionID string) (*repository.Session, error) {
+	if sessionID != "s-1" {
+		return nil, nil
+	}
+	return &repository.Session{SessionID: "s-1", WorkingDirectory: "/srv/app"}, nil
+}
+
+func (m *adminTestManager) GetConversationTree(sessionID string) ([]*repository.ChildSession, error) {
+	if sessionID != "s-1" {
+		return nil, fmt.Errorf("session not found")
+	}
+	prompt, reply := "fix the build", "Fixed."
+	return []*repository.ChildSession{{SessionID: "s-1a", UserPrompt: &prompt, AIResponse: &reply}}, nil
+}
+
+func (m *adminTestManager) DeleteSession(sessionID string) error {
+	switch {
+	case m.processing[sessionID]:
+		return session.ErrSessionBusy
+	case sessionID != "s-1":
+		return session.ErrSessionNotFound
+	}
+	m.deleted = append(m.deleted, sessionID)
+	return nil
+}
+
+func (m *adminTestManager) SwitchToSessionInChannel(channelID, sessionID string) error {
+	if sessionID != "s-1" {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+	m.channels[channelID].ActiveSessionID = &m.activeID
+	return nil
+}
+
+func (m *adminTestManager) LoadSessionByID(id int) (*repository.Session, error) {
+	return &repository.Session{ID: id, SessionID: "s-1"}, nil
+}
+
+func (m *adminTestManager) GetChildSessionBySessionID(sessionID string) (*repository.ChildSession, error) {
+	return nil, nil
+}
+
+func (m *adminTestManager) LoadConversationTree(rootParentID int) ([]*repository.ChildSession, error) {
+	return nil, nil
+}
+
+func (m *adminTestManager) GetPermissionModeForChannel(channelID string) (config.PermissionMode, error) {
+	return config.PermissionMode(m.channels[channelID].Permission), nil
+}
+
+func (m *adminTestManager) SetPermissionModeForChannel(channelID string, mode config.PermissionMode) error {
+	m.channels[channelID].Permission = string(mode)
+	return nil
+}
+
+func newAdminTestService(manager *adminTestManager) *Service {
+	return &Service{
+		config:         &config.Config{AdminAPIToken: testAdminToken},
+		logger:         zap.NewNop(),
+		sessionManager: manager,
+		channelRuns:    newChannelRuns(),
+	}
+}
+
+func adminRequest(t *testing.T, s *Service, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	s.adminAPI().ServeHTTP(w, r)
+	return w
+}
+
+func TestAdminAPI_RequiresToken(t *testing.T) {
+	s := newAdminTestService(&adminTestManager{})
+
+	for name, header := range map[string]string{
+		"missing":    "",
+		"wrong":      "Bearer not-the-token",
+		"not bearer": "Basic " + testAdminToken,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/admin/sessions", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		s.adminAPI().ServeHTTP(w, r)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s token: status = %d, want 401", name, w.Code)
+		}
+	}
+
+	s.config.AdminAPIToken = ""
+	r := httptest.NewRequest(http.MethodGet, "/admin/sessions", nil)
+	r.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+	s.adminAPI().ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected an unset token to authorize nothing, got %d", w.Code)
+	}
+}
+
+func TestAdminAPI_Routing(t *testing.T) {
+	s := newAdminTestService(&adminTestManager{})
+
+	tests := []struct {
+		method, target string
+		want           int
+	}{
+		{http.MethodPost, "/admin/sessions", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/admin/channels/C123/clear-queue", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/admin/channels/not-a-channel", http.StatusNotFound},
+		{http.MethodGet, "/admin/nothing", http.StatusNotFound},
+		{http.MethodPost, "/admin/sessions/s-1", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/admin/sessions/not%20an%20id", http.StatusNotFound},
+		{http.MethodPost, "/admin/channels/C123/permission", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/admin/sessions?limit=0", http.StatusBadRequest},
+		{http.MethodGet, "/admin/sessions?channel=general", http.StatusBadRequest},
+		{http.MethodGet, "/admin/errors?level=loud", http.StatusBadRequest},
+		{http.MethodGet, "/admin/errors?since=yesterday", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := adminRequest(t, s, tt.method, tt.target, "")
+		if w.Code != tt.want {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.target, w.Code, tt.want)
+		}
+		var body adminError
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error == "" {
+			t.Errorf("%s %s: expected a JSON error, got %q", tt.method, tt.target, w.Body.String())
+		}
+	}
+}
+
+func TestAdminAPI_Sessions(t *testing.T) {
+	channel := "C123"
+	manager := &adminTestManager{
+		sessions: []session.SessionInfo{
+			&session.DbSessionInfo{Session: &repository.Session{SessionID: "s-1", ChannelID: &channel, WorkingDirectory: "/srv/app"}},
+			&session.DbSessionInfo{Session: &repository.Session{SessionID: "s-2", WorkingDirectory: "/tmp"}},
+		},
+		processing: map[string]bool{"s-1": true},
+	}
+	s := newAdminTestService(manager)
+
+	w := adminRequest(t, s, http.MethodGet, "/admin/sessions?channel=C123", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var sessions []adminSession
+	if err := json.Unmarshal(w.Body.Bytes(), &sessions); err != nil {
+		t.Fatalf("Failed to decode sessions: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "s-1" || !sessions[0].Processing || sessions[0].WorkDir != "/srv/app" {
+		t.Errorf("Unexpected sessions: %+v", sessions)
+	}
+
+	w = adminRequest(t, s, http.MethodGet, "/admin/sessions?channel=C999", "")
+	if strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("Expected an empty list as [], got %s", w.Body.String())
+	}
+}
+
+func TestAdminAPI_Channel(t *testing.T) {
+	priority := "urgent"
+	manager := &adminTestManager{channels: map[string]*repository.SlackChannel{
+		"C123": {ChannelID: "C123", Permission: "plan", SessionMode: "shared", RunPriority: &priority},
+	}}
+	s := newAdminTestService(manager)
+	_, untrack := s.channelRuns.track(context.Background(), "C123")
+	defer untrack()
+
+	w := adminRequest(t, s, http.MethodGet, "/admin/channels/C123", "")
+	var ch adminChannel
+	if err := json.Unmarshal(w.Body.Bytes(), &ch); err != nil {
+		t.Fatalf("Failed to decode channel: %v (%s)", err, w.Body.String())
+	}
+	if ch.Permission != "plan" || ch.SessionMode != "shared" || ch.RunPriority != "urgent" || ch.Runs != 1 {
+		t.Errorf("Unexpected channel state: %+v", ch)
+	}
+
+	if w := adminRequest(t, s, http.MethodGet, "/admin/channels/C999", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown channel to be 404, got %d", w.Code)
+	}
+}
+
+func TestAdminAPI_ClearQueueCancelsRuns(t *testing.T) {
+	s := newAdminTestService(&adminTestManager{})
+	run, untrack := s.channelRuns.track(context.Background(), "C123")
+	defer untrack()
+
+	w := adminRequest(t, s, http.MethodPost, "/admin/channels/C123/clear-queue", "")
+	var result adminClearResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to decode result: %v (%s)", err, w.Body.String())
+	}
+	if result.CanceledRuns != 1 {
+		t.Errorf("CanceledRuns = %d, want 1", result.CanceledRuns)
+	}
+	if !errors.Is(context.Cause(run), errRunsCleared) {
+		t.Errorf("Expected the run to be canceled by the operator, got %v", context.Cause(run))
+	}
+}
+
+func TestAdminAPI_CompactValidatesRequest(t *testing.T) {
+	s := newAdminTestService(&adminTestManager{})
+
+	for _, body := range []string{"", "not json", `{"user": "someone"}`} {
+		if w := adminRequest(t, s, http.MethodPost, "/admin/channels/C123/compact", body); w.Code != http.StatusBadRequest {
+			t.Errorf("body %q: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestAdminAPI_Errors(t *testing.T) {
+	buffer := logging.NewRingBuffer(10)
+	logger := zap.New(buffer.Core(zap.DebugLevel))
+	logger.Info("routine")
+	logger.Warn("odd")
+	logger.Error("first failure", zap.String("channel_id", "C123"))
+	cutoff := time.Now()
+	time.Sleep(time.Millisecond)
+	logger.Error("second failure")
+
+	s := newAdminTestService(&adminTestManager{})
+	s.logBuffer = buffer
+
+	decode := func(target string) []logging.LogEntry {
+		t.Helper()
+		w := adminRequest(t, s, http.MethodGet, target, "")
+		var entries []logging.LogEntry
+		if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+			t.Fatalf("%s: failed to decode entries: %v (%s)", target, err, w.Body.String())
+		}
+		return entries
+	}
+
+	if entries := decode("/admin/errors"); len(entries) != 2 || entries[0].Message != "first failure" {
+		t.Errorf("Expected the two errors, oldest first, got %+v", entries)
+	}
+	if entries := decode("/admin/errors?level=warn"); len(entries) != 3 {
+		t.Errorf("Expected level=warn to include warnings, got %d entries", len(entries))
+	}
+	if entries := decode("/admin/errors?limit=1"); len(entries) != 1 || entries[0].Message != "second failure" {
+		t.Errorf("Expected limit to keep the newest, got %+v", entries)
+	}
+	if entries := decode("/admin/errors?since=" + cutoff.Format(time.RFC3339Nano)); len(entries) != 1 || entries[0].Message != "second failure" {
+		t.Errorf("Expected since to skip older entries, got %+v", entries)
+	}
+}
+
+func TestAdminAPI_SessionAndTree(t *testing.T) {
+	s := newAdminTestService(&adminTestManager{processing: map[string]bool{}})
+
+	w := adminRequest(t, s, http.MethodGet, "/admin/sessions/s-1", "")
+	var detail adminSessionDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("Failed to decode session: %v (%s)", err, w.Body.String())
+	}
+	if detail.ID != "s-1" || detail.WorkDir != "/srv/app" || detail.Exchanges != 1 {
+		t.Errorf("Unexpected session: %+v", detail)
+	}
+
+	w = adminRequest(t, s, http.MethodGet, "/admin/sessions/s-1/tree", "")
+	var tree []adminExchange
+	if err := json.Unmarshal(w.Body.Bytes(), &tree); err != nil {
+		t.Fatalf("Failed to decode tree: %v (%s)", err, w.Body.String())
+	}
+	if len(tree) != 1 || tree[0].Prompt != "fix the build" || tree[0].Response != "Fixed." {
+		t.Errorf("Unexpected tree: %+v", tree)
+	}
+
+	for _, target := range []string{"/admin/sessions/s-9", "/admin/sessions/s-9/tree"} {
+		if w := adminRequest(t, s, http.MethodGet, target, ""); w.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", target, w.Code)
+		}
+	}
+}
+
+func TestAdminAPI_DeleteSession(t *testing.T) {
+	manager := &adminTestManager{processing: map[string]bool{"s-busy": true}}
+	s := newAdminTestService(manager)
+
+	if w := adminRequest(t, s, http.MethodDelete, "/admin/sessions/s-1", ""); w.Code != http.StatusOK {
+		t.Errorf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	if len(manager.deleted) != 1 || manager.deleted[0] != "s-1" {
+		t.Errorf("Expected s-1 to be deleted, got %v", manager.deleted)
+	}
+	if w := adminRequest(t, s, http.MethodDelete, "/admin/sessions/s-busy", ""); w.Code != http.StatusConflict {
+		t.Errorf("Expected a busy session to be 409, got %d", w.Code)
+	}
+	if w := adminRequest(t, s, http.MethodDelete, "/admin/sessions/s-9", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown session to be 404, got %d", w.Code)
+	}
+}
+
+// trashingAdminManager keeps deleted sessions in a trash, recording who
+// deleted them
+type trashingAdminManager struct {
+	*adminTestManager
+	session.SessionTrashManager
+	deletedBy []string
+}
+
+func (m *trashingAdminManager) TrashSession(sessionID, userID string) error {
+	if err := m.DeleteSession(sessionID); err != nil {
+		return err
+	}
+	m.deletedBy = append(m.deletedBy, userID)
+	return nil
+}
+
+func TestAdminAPI_DeleteSessionRecordsOperator(t *testing.T) {
+	manager := &trashingAdminManager{adminTestManager: &adminTestManager{}}
+	s := newAdminTestService(manager.adminTestManager)
+	s.sessionManager = manager
+
+	// A shared token doesn't say who the operator is
+	if w := adminRequest(t, s, http.MethodDelete, "/admin/sessions/s-1", ""); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	if len(manager.deletedBy) != 1 || manager.deletedBy[0] != adminActorFallback {
+		t.Errorf("Expected the deletion to be recorded as %s, got %v", adminActorFallback, manager.deletedBy)
+	}
+
+	r := httptest.NewRequest(http.MethodDelete, "/admin/sessions/s-1", nil)
+	r = r.WithContext(context.WithValue(r.Context(), adminIdentityKey{}, &httpauth.Identity{Provider: "header", Subject: "alice@example.com"}))
+	if actor := adminActor(r); actor != "alice@example.com" {
+		t.Errorf("Expected the authenticated subject, got %q", actor)
+	}
+}
+
+func TestAdminAPI_SwitchSession(t *testing.T) {
+	manager := &adminTestManager{
+		activeID: 7,
+		channels: map[string]*repository.SlackChannel{"C123": {ChannelID: "C123", Permission: "default"}},
+	}
+	s := newAdminTestService(manager)
+
+	w := adminRequest(t, s, http.MethodPut, "/admin/channels/C123/session", `{"session_id": "s-1"}`)
+	var ch adminChannel
+	if err := json.Unmarshal(w.Body.Bytes(), &ch); err != nil {
+		t.Fatalf("Failed to decode channel: %v (%s)", err, w.Body.String())
+	}
+	if ch.ActiveSessionID != "s-1" {
+		t.Errorf("Expected the channel's new state, got %+v", ch)
+	}
+
+	for body, want := range map[string]int{
+		`{"session_id": "s-9"}`:    http.StatusNotFound,
+		`{"session_id": "../etc"}`: http.StatusBadRequest,
+		`not json`:                 http.StatusBadRequest,
+	} {
+		if w := adminRequest(t, s, http.MethodPut, "/admin/channels/C123/session", body); w.Code != want {
+			t.Errorf("body %s: status = %d, want %d", body, w.Code, want)
+		}
+	}
+}
+
+func TestAdminAPI_SetPermission(t *testing.T) {
+	manager := &adminTestManager{channels: map[string]*repository.SlackChannel{"C123": {ChannelID: "C123", Permission: "default"}}}
+	s := newAdminTestService(manager)
+
+	w := adminRequest(t, s, http.MethodPut, "/admin/channels/C123/permission", `{"mode": "plan"}`)
+	var ch adminChannel
+	if err := json.Unmarshal(w.Body.Bytes(), &ch); err != nil {
+		t.Fatalf("Failed to decode channel: %v (%s)", err, w.Body.String())
+	}
+	if ch.Permission != "plan" {
+		t.Errorf("Expected the channel to be in plan mode, got %+v", ch)
+	}
+
+	for body, want := range map[string]int{
+		`{"mode": "yolo"}`:                http.StatusBadRequest,
+		`{"mode": "plan", "ttl": "soon"}`: http.StatusBadRequest,
+		`{"mode": "plan", "ttl": "-5m"}`:  http.StatusBadRequest,
+		`{"mode": "plan", "ttl": "30m"}`:  http.StatusNotImplemented,
+	} {
+		if w := adminRequest(t, s, http.MethodPut, "/admin/channels/C123/permission", body); w.Code != want {
+			t.Errorf("body %s: status = %d, want %d", body, w.Code, want)
+		}
+	}
+}
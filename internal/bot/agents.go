@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const agentsUsage = "**Usage:** `/agents list` | `/agents use <name> [name...]` | `/agents all` | `/agents none`"
+
+// channelAgentNames returns the sub-agents enabled in a channel by its agents
+// setting, or nil when the channel uses every configured agent
+func (s *Service) channelAgentNames(channelID string) []string {
+	return s.channelSettings(channelID).Agents
+}
+
+// channelAgents returns the agent definitions passed to Claude Code in a channel
+func (s *Service) channelAgents(channelID string) claude.Agents {
+	if len(s.agents) == 0 {
+		return nil
+	}
+	return s.agents.Select(s.channelAgentNames(channelID))
+}
+
+// handleAgentsSlashCommand lists configured sub-agents and selects which are enabled per channel
+func (s *Service) handleAgentsSlashCommand(ctx context.Context, req *commands.Request) (string, error) {
+	if len(req.Args) == 0 || req.Args[0] == "list" {
+		return s.formatAgentsList(req.ChannelID), nil
+	}
+
+	if s.settings == nil {
+		return "❌ **Channel agent settings require database persistence**", nil
+	}
+
+	var names []string
+	switch req.Args[0] {
+	case "all":
+		names = nil
+	case "none":
+		names = []string{}
+	case "use":
+		if len(req.Args) < 2 {
+			return "❌ **No agents given**\n\n" + agentsUsage, nil
+		}
+		for _, arg := range req.Args[1:] {
+			for _, name := range strings.Split(arg, ",") {
+				if name = strings.TrimSpace(name); name == "" {
+					continue
+				}
+				if _, exists := s.agents[name]; !exists {
+					return fmt.Sprintf("❌ **Unknown agent:** `%s`\n\nConfigured agents: %s", name, formatAgentNames(s.agents.Names())), nil
+				}
+				names = append(names, name)
+			}
+		}
+	default:
+		return "❌ **Invalid arguments**\n\n" + agentsUsage, nil
+	}
+
+	var stored interface{}
+	if names != nil {
+		stored = names
+	}
+	if err := s.storeChannelSetting(req.ChannelID, repository.ChannelSettingAgents, stored, req.UserID); err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "agents_slash_command", "set_channel_agents")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to update channel agents"), nil
+	}
+
+	s.logger.Info("Channel agents updated",
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID),
+		zap.Strings("agents", names))
+
+	switch {
+	case names == nil:
+		return "✅ **All configured agents enabled in this channel**", nil
+	case len(names) == 0:
+		return "✅ **Agents disabled in this channel**", nil
+	default:
+		return fmt.Sprintf("✅ **Channel agents set:** %s", formatAgentNames(names)), nil
+	}
+}
+
+// formatAgentsList renders configured agents and whether each is enabled in the channel
+func (s *Service) formatAgentsList(channelID string) string {
+	if len(s.agents) == 0 {
+		return "🤖 **No agents configured**\n\nDefine sub-agents in a JSON file and set `CLAUDE_AGENTS_FILE` to enable them."
+	}
+
+	enabled := s.channelAgents(channelID)
+
+	var list strings.Builder
+	list.WriteString("🤖 **Claude Agents**\n\n")
+	for _, name := range s.agents.Names() {
+		status := "⚪"
+		if _, ok := enabled[name]; ok {
+			status = "🟢"
+		}
+		agent := s.agents[name]
+		list.WriteString(fmt.Sprintf("%s `%s` - %s", status, name, agent.Description))
+		if agent.Model != "" {
+			list.WriteString(fmt.Sprintf(" _(model: %s)_", agent.Model))
+		}
+		list.WriteString("\n")
+	}
+
+	if s.channelAgentNames(channelID) == nil {
+		list.WriteString("\nThis channel uses all configured agents.")
+	}
+	list.WriteString("\n" + agentsUsage)
+
+	return list.String()
+}
+
+// formatAgentNames renders agent names as inline code
+func formatAgentNames(names []string) string {
+	if len(names) == 0 {
+		return "_none_"
+	}
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "`" + name + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,176 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/budget"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/notifications"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const (
+	// alertCooldown is how long a condition stays quiet after it was reported
+	alertCooldown = time.Hour
+
+	// alertSendTimeout bounds how long delivering one alert may take
+	alertSendTimeout = 30 * time.Second
+)
+
+// newNotificationRouter builds a router for the sinks enabled in NOTIFY_SINKS.
+// Slack notices go only to SLACK_NOTIFICATION_CHANNELS; without any, alerts
+// are logged rather than posted where users work.
+func newNotificationRouter(cfg *config.Config, poster notifications.MessagePoster, logger *zap.Logger) *notifications.Router {
+	var routes []notifications.Route
+	for name, level := range cfg.NotificationSinks {
+		severity, err := notifications.ParseSeverity(level)
+		if err != nil {
+			logger.Warn("Ignoring notification sink", zap.String("sink", name), zap.Error(err))
+			continue
+		}
+
+		var sink notifications.Sink
+		switch name {
+		case "slack":
+			if len(cfg.NotificationChannels) == 0 {
+				logger.Info("No SLACK_NOTIFICATION_CHANNELS configured; Slack alerts are only logged")
+				continue
+			}
+			sink = notifications.NewSlackSink(poster, cfg.NotificationChannels)
+		case "webhook":
+			sink = notifications.NewWebhookSink(cfg.NotifyWebhookURL)
+		case "email":
+			sink = notifications.NewEmailSink(cfg.NotifySMTPAddr, cfg.NotifySMTPUsername, cfg.NotifySMTPPassword,
+				cfg.NotifyEmailFrom, cfg.NotifyEmailTo)
+		case "pagerduty":
+			sink = notifications.NewPagerDutySink(cfg.NotifyPagerDutyRoutingKey)
+		default:
+			logger.Warn("Ignoring unknown notification sink", zap.String("sink", name))
+			continue
+		}
+		routes = append(routes, notifications.Route{Sink: sink, MinSeverity: severity})
+	}
+	return notifications.NewRouter(logger, routes...)
+}
+
+// alertCooldowns remembers when each condition was last reported
+type alertCooldowns struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func newAlertCooldowns() *alertCooldowns {
+	return &alertCooldowns{last: make(map[string]time.Time)}
+}
+
+// allow reports whether a condition may be reported now, and if so starts
+// its cooldown
+func (c *alertCooldowns) allow(key string, now time.Time, cooldown time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if last, ok := c.last[key]; ok && now.Sub(last) < cooldown {
+		return false
+	}
+	c.last[key] = now
+	return true
+}
+
+// alert sends an operational event to the configured sinks in the
+// background. The same kind and key is reported at most once per cooldown.
+func (s *Service) alert(event notifications.Event) {
+	if !s.alertCooldowns.allow(event.Kind+"/"+event.Key, time.Now(), alertCooldown) {
+		return
+	}
+
+	s.logger.Info("Sending operational alert",
+		zap.String("kind", event.Kind),
+		zap.String("severity", event.Severity.String()),
+		zap.String("key", event.Key))
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), alertSendTimeout)
+		defer cancel()
+		if err := s.notifier.Notify(ctx, event); err != nil {
+			s.logger.Error("Failed to send operational alert", zap.Error(err))
+		}
+	}()
+}
+
+// alertBudgetBreaches reports every budget in usages that is used up
+func (s *Service) alertBudgetBreaches(channelID, sessionID string, usages []budget.Usage) {
+	for _, usage := range usages {
+		if usage.Budget <= 0 || usage.Spent < usage.Budget {
+			continue
+		}
+
+		key := channelID
+		if usage.Scope == "session" {
+			key = sessionID
+		}
+		s.alert(notifications.Event{
+			Kind:     notifications.EventBudgetBreach,
+			Severity: notifications.SeverityWarning,
+			Title:    fmt.Sprintf("Budget exceeded (%s)", usage.Scope),
+			Text:     fmt.Sprintf("Spent $%.2f of a $%.2f %s budget.", usage.Spent, usage.Budget, usage.Scope),
+			Fields: map[string]string{
+				"channel": channelID,
+				"session": sessionID,
+			},
+			Key: usage.Scope + "/" + key,
+		})
+	}
+}
+
+// alertRepeatedFailures reports a channel whose failed runs within
+// FAILURE_ALERT_WINDOW reached FAILURE_ALERT_THRESHOLD
+func (s *Service) alertRepeatedFailures(channelID, errorKind string) {
+	if s.config.FailureAlertThreshold <= 0 {
+		return
+	}
+
+	count, err := s.failedRuns.CountFailedRunsSince(channelID, time.Now().Add(-s.config.FailureAlertWindow))
+	if err != nil {
+		s.logger.Warn("Failed to count recent failed runs", zap.String("channel_id", channelID), zap.Error(err))
+		return
+	}
+	if count < s.config.FailureAlertThreshold {
+		return
+	}
+
+	s.alert(notifications.Event{
+		Kind:     notifications.EventRepeatedFailures,
+		Severity: notifications.SeverityCritical,
+		Title:    "Repeated Claude run failures",
+		Text:     fmt.Sprintf("%d runs failed in the last %s. Inspect them with /failed list.", count, s.config.FailureAlertWindow),
+		Fields: map[string]string{
+			"channel":      channelID,
+			"latest_error": errorKind,
+		},
+		Key: channelID,
+	})
+}
+
+// alertStuckRuns reports channels whose runs outlived THINKING_TIMEOUT and
+// were marked interrupted
+func (s *Service) alertStuckRuns(messages []*repository.ThinkingMessage) {
+	stuck := make(map[string]int)
+	for _, message := range messages {
+		stuck[message.ChannelID]++
+	}
+
+	for channelID, count := range stuck {
+		s.alert(notifications.Event{
+			Kind:     notifications.EventStuckQueue,
+			Severity: notifications.SeverityWarning,
+			Title:    "Claude runs stuck",
+			Text:     fmt.Sprintf("%d run(s) were still going after %s and were marked interrupted.", count, s.config.ThinkingTimeout),
+			Fields:   map[string]string{"channel": channelID},
+			Key:      channelID,
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlertCooldowns(t *testing.T) {
+	cooldowns := newAlertCooldowns()
+	now := time.Now()
+
+	if !cooldowns.allow("stuck_queue/C1", now, time.Hour) {
+		t.Fatal("Expected the first alert to be allowed")
+	}
+	if cooldowns.allow("stuck_queue/C1", now.Add(30*time.Minute), time.Hour) {
+		t.Error("Expected a repeat within the cooldown to be suppressed")
+	}
+	if !cooldowns.allow("stuck_queue/C2", now.Add(30*time.Minute), time.Hour) {
+		t.Error("Expected a different key to be allowed")
+	}
+	if !cooldowns.allow("stuck_queue/C1", now.Add(61*time.Minute), time.Hour) {
+		t.Error("Expected the alert to be allowed again after the cooldown")
+	}
+}
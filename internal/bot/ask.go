@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+// askPermissionMode is the mode a /ask run uses: the channel's mode if it
+// can't change anything without asking, else plan, so a side question never
+// edits the work tree a longer task is using
+func askPermissionMode(channelMode config.PermissionMode, forcePlan bool) config.PermissionMode {
+	if !forcePlan && channelMode == config.PermissionModeDefault {
+		return config.PermissionModeDefault
+	}
+	return config.PermissionModePlan
+}
+
+// handleAskCommand handles /ask <prompt>: a one-off run in a fresh Claude
+// session whose answer only the asker sees
+func (s *Service) handleAskCommand(ctx context.Context, req *commands.Request) (string, error) {
+	prompt := strings.TrimSpace(req.Text)
+	if !s.policyAccepted(req.UserID) {
+		if err := s.postPolicyPrompt(ctx, req.ChannelID, req.UserID); err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "ask", "post_usage_policy")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to show the usage policy"), nil
+		}
+		return "ℹ️ Agree to the usage policy above, then run `/ask` again.", nil
+	}
+
+	if tooLarge := promptTooLarge(prompt, s.config.PromptMaxTokens); tooLarge != "" {
+		return tooLarge, nil
+	}
+
+	// The channel's session only lends its working directory and budget; its
+	// conversation isn't resumed or extended
+	userSession, err := s.sessionManager.GetOrCreateSession(req.UserID, req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "ask", "get_session")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to get session"), nil
+	}
+
+	go s.runAsk(req.UserID, req.ChannelID, userSession.GetID(), userSession.GetCurrentWorkDir(), prompt)
+
+	return fmt.Sprintf("🤔 _Asking..._ The answer will appear here, visible only to you.\n> %s", truncatePrompt(prompt)), nil
+}
+
+// runAsk runs a /ask prompt and posts the answer ephemerally
+func (s *Service) runAsk(userID, channelID, sessionID, workDir, prompt string) {
+	ctx, untrack := s.channelRuns.track(context.Background(), channelID)
+	defer untrack()
+
+	settings := s.channelSettings(channelID)
+	observer := observerMode(settings)
+	if observer {
+		dir, err := observerWorkDir()
+		if err != nil {
+			errCtx := logging.CreateErrorContext(channelID, userID, "ask", "observer_workdir")
+			s.postEphemeral(channelID, userID, s.logErrorWithTrace(ctx, errCtx, err, "Failed to prepare the observer run; Claude was not run"))
+			return
+		}
+		workDir = dir
+	}
+	if failed := s.preflight(workDir); failed != nil {
+		s.postEphemeral(channelID, userID, failed.message())
+		return
+	}
+
+	defer s.beginRun()()
+
+	channelMode, err := s.getPermissionModeForChannel(channelID, sessionID)
+	if err != nil {
+		channelMode = config.PermissionModeDefault
+	}
+	budgetDecision := s.applyBudgetPolicy(sessionID, channelID, s.channelModel(settings))
+	permMode := askPermissionMode(channelMode, observer || budgetDecision.PlanMode)
+
+	guardrails, err := s.channelGuardrails(channelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "ask", "load_guardrails")
+		s.postEphemeral(channelID, userID, s.logErrorWithTrace(ctx, errCtx, err, "Failed to load channel guardrails; Claude was not run"))
+		return
+	}
+	runOpts := claude.RunOptions{
+		ExtraSystemPrompt: joinPromptSections(s.channelContextPrompt(channelID), s.channelFactsPrompt(channelID), s.responsePreferencesPrompt(userID, channelID)),
+		Guardrails:        guardrails,
+		Model:             budgetDecision.Model,
+		Agents:            s.channelAgents(channelID),
+		Env:               s.channelEnvironment(channelID),
+		AddDirs:           s.runAddDirs(sessionID),
+		DisallowedTools:   settings.DisallowedTools,
+	}
+	if observer {
+		restrictToObserver(&runOpts)
+	}
+
+	priority, _ := s.channelRunPriority(channelID)
+	start := time.Now()
+	var response *claude.ClaudeCodeResponse
+	err = s.runQueued(ctx, priority, nil, func(runCtx context.Context) error {
+		var runErr error
+		response, runErr = s.claudeExecutor.ProcessClaudeCodeRequest(runCtx, prompt, uuid.New().String(), userID, workDir, s.allowedTools(settings), true, permMode, runOpts)
+		return runErr
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		if errors.Is(context.Cause(ctx), errChannelArchived) || errors.Is(context.Cause(ctx), errRunsCleared) {
+			s.postEphemeral(channelID, userID, "🛑 **Your `/ask` was canceled**")
+			return
+		}
+		errCtx := logging.CreateErrorContext(channelID, userID, "ask", "claude_processing")
+		message := s.logErrorWithTrace(ctx, errCtx, err, "Claude Code processing failed")
+		var partialErr *claude.PartialResultError
+		if errors.As(err, &partialErr) && partialErr.Partial != "" {
+			message += fmt.Sprintf("\n\n⚠️ *Partial result before failure:*\n\n%s", s.postProcessResponse(ctx, channelID, userID, sessionID, partialErr.Partial))
+		}
+		s.postEphemeral(channelID, userID, message)
+		return
+	}
+
+	s.recordUsage(sessionID, channelID, userID, budgetDecision.Model, response, duration)
+	s.logger.Info("Answered /ask",
+		zap.String("channel_id", channelID),
+		zap.String("user_id", userID),
+		zap.String("claude_session_id", response.SessionID),
+		zap.Duration("duration", duration))
+
+	// Redaction and other hooks apply to ephemeral answers too
+	result := s.postProcessResponse(ctx, channelID, userID, sessionID, response.Result)
+	answer := fmt.Sprintf("💬 *You asked:*\n> %s\n\n%s\n\n_• Mode: %s · $%.4f · %s · not added to the channel's session_",
+		truncatePrompt(prompt), result, permMode, response.TotalCostUSD, formatElapsed(duration))
+	for _, part := range splitMessage(answer, s.config.MaxMessageLength) {
+		s.postEphemeral(channelID, userID, part)
+	}
+}
@@ -0,0 +1,25 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+func TestAskPermissionMode(t *testing.T) {
+	for _, tc := range []struct {
+		channel   config.PermissionMode
+		forcePlan bool
+		want      config.PermissionMode
+	}{
+		{config.PermissionModeDefault, false, config.PermissionModeDefault},
+		{config.PermissionModePlan, false, config.PermissionModePlan},
+		{config.PermissionModeAcceptEdits, false, config.PermissionModePlan},
+		{config.PermissionModeBypassPerms, false, config.PermissionModePlan},
+		{config.PermissionModeDefault, true, config.PermissionModePlan},
+	} {
+		if got := askPermissionMode(tc.channel, tc.forcePlan); got != tc.want {
+			t.Errorf("askPermissionMode(%s, %v) = %s, want %s", tc.channel, tc.forcePlan, got, tc.want)
+		}
+	}
+}
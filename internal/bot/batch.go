@@ -0,0 +1,447 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/runqueue"
+)
+
+const (
+	batchUsage = "`/batch <prompt> --paths /srv/api,/srv/web`"
+
+	// maxBatchExcerptLength caps the per-path excerpt in the batch summary
+	maxBatchExcerptLength = 150
+)
+
+// batchPathsFlag matches "--paths a,b" and "--paths=a,b"
+var batchPathsFlag = regexp.MustCompile(`(?:^|\s)--paths(?:=|\s+)(\S+)`)
+
+// batchStatus is the state of one path in a batch
+type batchStatus int
+
+const (
+	batchQueued batchStatus = iota
+	batchRunning
+	batchSucceeded
+	batchFailed
+	batchSkipped
+)
+
+func (b batchStatus) icon() string {
+	switch b {
+	case batchRunning:
+		return "🔄"
+	case batchSucceeded:
+		return "✅"
+	case batchFailed:
+		return "❌"
+	case batchSkipped:
+		return "⏭️"
+	default:
+		return "⏳"
+	}
+}
+
+// batchRun tracks the Claude run for one path in a batch
+type batchRun struct {
+	Path     string
+	Status   batchStatus
+	Result   string
+	Err      error
+	CostUSD  float64
+	Duration time.Duration
+}
+
+// batch is a prompt fanned out to several working directories
+type batch struct {
+	Prompt    string
+	UserID    string
+	ChannelID string
+	SessionID string // Bot session that usage is recorded against
+	TrackerTS string
+	Started   time.Time
+
+	mu   sync.Mutex
+	Runs []*batchRun
+}
+
+// parseBatchArgs splits "/batch" text into the prompt and the --paths list
+func parseBatchArgs(text string) (string, []string, error) {
+	matches := batchPathsFlag.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return "", nil, fmt.Errorf("--paths is required")
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	var prompt strings.Builder
+	last := 0
+	for _, match := range matches {
+		prompt.WriteString(text[last:match[0]])
+		prompt.WriteString(" ")
+		last = match[1]
+
+		for _, path := range strings.Split(text[match[2]:match[3]], ",") {
+			if path = strings.TrimSpace(path); path == "" {
+				continue
+			}
+			path = filepath.Clean(path)
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	prompt.WriteString(text[last:])
+
+	if len(paths) == 0 {
+		return "", nil, fmt.Errorf("--paths needs at least one path")
+	}
+	trimmed := strings.TrimSpace(prompt.String())
+	if trimmed == "" {
+		return "", nil, fmt.Errorf("a prompt is required")
+	}
+	return trimmed, paths, nil
+}
+
+// handleBatchCommand validates a batch, posts its tracker, and starts the runs
+func (s *Service) handleBatchCommand(ctx context.Context, req *commands.Request) (string, error) {
+	prompt, paths, err := parseBatchArgs(req.Text)
+	if err != nil {
+		return fmt.Sprintf("❌ **Invalid batch:** %v\n\n**Usage:** %s", err, batchUsage), nil
+	}
+	if observerMode(s.channelSettings(req.ChannelID)) {
+		return "👁️ **Observer channel:** batch runs work in the host's directories, which this channel can't reach.", nil
+	}
+	if !s.policyAccepted(req.UserID) {
+		if err := s.postPolicyPrompt(ctx, req.ChannelID, req.UserID); err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "batch", "post_usage_policy")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to show the usage policy"), nil
+		}
+		return "ℹ️ Agree to the usage policy above, then run `/batch` again.", nil
+	}
+	if len(paths) > s.config.BatchMaxPaths {
+		return fmt.Sprintf("❌ **Too many paths:** %d given, at most %d are allowed per batch", len(paths), s.config.BatchMaxPaths), nil
+	}
+
+	var problems []string
+	for _, path := range paths {
+		switch {
+		case !filepath.IsAbs(path):
+			problems = append(problems, fmt.Sprintf("• `%s` is not an absolute path", path))
+		case !s.isAllowedWorkdir(path):
+			problems = append(problems, fmt.Sprintf("• `%s` is outside the allowed working directories", path))
+		default:
+			if err := validateWorkingDir(path); err != nil {
+				problems = append(problems, "• "+err.Error())
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return "❌ **Invalid paths**\n\n" + strings.Join(problems, "\n"), nil
+	}
+
+	// Usage is charged to the channel's session so budgets still apply
+	userSession, err := s.sessionManager.GetOrCreateSession(req.UserID, req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "batch", "get_session")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to get session"), nil
+	}
+
+	b := &batch{
+		Prompt:    prompt,
+		UserID:    req.UserID,
+		ChannelID: req.ChannelID,
+		SessionID: userSession.GetID(),
+		Started:   time.Now(),
+	}
+	for _, path := range paths {
+		b.Runs = append(b.Runs, &batchRun{Path: path})
+	}
+
+	trackerTS, err := s.sender.PostMessage(ctx, req.ChannelID, slack.MsgOptionText(b.tracker(), false))
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "batch", "post_tracker")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to start batch"), nil
+	}
+	b.TrackerTS = trackerTS
+
+	s.logger.Info("Starting batch",
+		zap.String("user_id", req.UserID),
+		zap.String("channel_id", req.ChannelID),
+		zap.Strings("paths", paths))
+
+	go s.runBatch(b)
+
+	return "", nil
+}
+
+// runBatch runs every path with at most BatchConcurrency runs in flight, then
+// posts the summary in the tracker's thread
+func (s *Service) runBatch(b *batch) {
+	ctx, untrack := s.channelRuns.track(context.Background(), b.ChannelID)
+	defer untrack()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	slots := make(chan struct{}, s.config.BatchConcurrency)
+	var wg sync.WaitGroup
+	for _, run := range b.Runs {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			b.update(run, func() { run.Status = batchSkipped })
+			continue
+		}
+
+		wg.Add(1)
+		go func(run *batchRun) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			s.runBatchPath(ctx, b, run)
+			s.updateBatchTracker(b)
+		}(run)
+	}
+	wg.Wait()
+
+	s.updateBatchTracker(b)
+	s.sendThreadResponse(b.ChannelID, b.TrackerTS, b.summary())
+}
+
+// runBatchPath runs the prompt in one path as a fresh Claude conversation
+func (s *Service) runBatchPath(ctx context.Context, b *batch, run *batchRun) {
+	b.update(run, func() { run.Status = batchRunning })
+	s.updateBatchTracker(b)
+
+	if failed := s.preflight(run.Path); failed != nil {
+		s.logger.Warn("Batch run pre-flight check failed",
+			zap.String("channel_id", b.ChannelID),
+			zap.String("path", run.Path),
+			zap.Error(failed))
+		b.update(run, func() {
+			run.Status = batchFailed
+			run.Err = failed
+		})
+		s.sendThreadResponse(b.ChannelID, b.TrackerTS, fmt.Sprintf("❌ *`%s`* not started\n\n%s", run.Path, failed.message()))
+		return
+	}
+
+	defer s.beginRun()()
+
+	permMode, err := s.getPermissionModeForChannel(b.ChannelID, b.SessionID)
+	if err != nil {
+		permMode = config.PermissionModeDefault
+	}
+	channelSettings := s.channelSettings(b.ChannelID)
+	budgetDecision := s.applyBudgetPolicy(b.SessionID, b.ChannelID, s.channelModel(channelSettings))
+	if budgetDecision.PlanMode {
+		permMode = config.PermissionModePlan
+	}
+	guardrails, err := s.channelGuardrails(b.ChannelID)
+	if err != nil {
+		s.logger.Error("Failed to load channel guardrails for batch run",
+			zap.String("channel_id", b.ChannelID),
+			zap.String("path", run.Path),
+			zap.Error(err))
+		b.update(run, func() {
+			run.Status = batchFailed
+			run.Err = err
+		})
+		s.sendThreadResponse(b.ChannelID, b.TrackerTS, fmt.Sprintf("❌ *`%s`* not started\n\nThe channel's guardrails couldn't be loaded, so Claude wasn't run.", run.Path))
+		return
+	}
+	runOpts := claude.RunOptions{
+		ExtraSystemPrompt: joinPromptSections(s.channelContextPrompt(b.ChannelID), s.channelFactsPrompt(b.ChannelID), s.responsePreferencesPrompt(b.UserID, b.ChannelID)),
+		Guardrails:        guardrails,
+		Model:             budgetDecision.Model,
+		Agents:            s.channelAgents(b.ChannelID),
+		Env:               s.channelEnvironment(b.ChannelID),
+		DisallowedTools:   channelSettings.DisallowedTools,
+	}
+
+	// Batch runs go behind interactive ones and start over in a fresh
+	// conversation if an urgent run preempts them
+	var response *claude.ClaudeCodeResponse
+	start := time.Now()
+	err = s.runQueued(ctx, runqueue.PriorityBatch, func() {
+		b.update(run, func() { run.Status = batchQueued })
+		s.updateBatchTracker(b)
+		s.sendThreadResponse(b.ChannelID, b.TrackerTS, fmt.Sprintf("⏸️ *`%s`* paused for an urgent run; it will start over when a slot frees up", run.Path))
+	}, func(slotCtx context.Context) error {
+		b.update(run, func() { run.Status = batchRunning })
+		s.updateBatchTracker(b)
+
+		runCtx, cancel := context.WithTimeout(slotCtx, s.config.ClaudeTimeout)
+		defer cancel()
+
+		start = time.Now()
+		var runErr error
+		response, runErr = s.claudeExecutor.ProcessClaudeCodeRequest(runCtx, b.Prompt, uuid.New().String(), b.UserID, run.Path, s.allowedTools(channelSettings), true, permMode, runOpts)
+		return runErr
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		s.logger.Error("Batch run failed",
+			zap.String("channel_id", b.ChannelID),
+			zap.String("path", run.Path),
+			zap.Error(err))
+
+		result := ""
+		var partialErr *claude.PartialResultError
+		if errors.As(err, &partialErr) {
+			result = s.postProcessResponse(ctx, b.ChannelID, b.UserID, b.SessionID, partialErr.Partial)
+		}
+		b.update(run, func() {
+			run.Status = batchFailed
+			run.Err = err
+			run.Result = result
+			run.Duration = duration
+		})
+
+		message := fmt.Sprintf("❌ *`%s`* failed after %s\n\n%v", run.Path, formatElapsed(duration), err)
+		if result != "" {
+			message += fmt.Sprintf("\n\n⚠️ *Partial result before failure:*\n\n%s", result)
+		}
+		s.sendThreadResponseOrHold(b.ChannelID, b.TrackerTS, message)
+		return
+	}
+
+	s.recordUsage(b.SessionID, b.ChannelID, b.UserID, budgetDecision.Model, response, duration)
+
+	// Redaction and other hooks apply to what's posted, the tracker's
+	// excerpts included
+	result := s.postProcessResponse(ctx, b.ChannelID, b.UserID, b.SessionID, response.Result)
+	b.update(run, func() {
+		run.Status = batchSucceeded
+		run.Result = result
+		run.CostUSD = response.TotalCostUSD
+		run.Duration = duration
+	})
+
+	s.sendThreadResponseOrHold(b.ChannelID, b.TrackerTS, fmt.Sprintf("✅ *`%s`* · $%.4f · %s\n\n%s",
+		run.Path, response.TotalCostUSD, formatElapsed(duration), result))
+}
+
+// updateBatchTracker rewrites the tracker message with the current progress
+func (s *Service) updateBatchTracker(b *batch) {
+	if err := s.sender.UpdateMessage(context.Background(), b.ChannelID, b.TrackerTS, slack.MsgOptionText(b.tracker(), false)); err != nil {
+		s.logger.Warn("Failed to update batch tracker", zap.Error(err))
+	}
+}
+
+// update changes a run under the batch lock
+func (b *batch) update(run *batchRun, change func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	change()
+}
+
+// counts returns how many runs finished and how many ended in each state
+func (b *batch) counts() (done, succeeded, failed, skipped int) {
+	for _, run := range b.Runs {
+		switch run.Status {
+		case batchSucceeded:
+			succeeded++
+		case batchFailed:
+			failed++
+		case batchSkipped:
+			skipped++
+		}
+	}
+	return succeeded + failed + skipped, succeeded, failed, skipped
+}
+
+// tracker formats the progress message posted when the batch starts
+func (b *batch) tracker() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	done, _, _, _ := b.counts()
+	var text strings.Builder
+	fmt.Fprintf(&text, "📦 *Batch run* by <@%s> · %d/%d done\n", b.UserID, done, len(b.Runs))
+	fmt.Fprintf(&text, ">%s\n", batchExcerpt(b.Prompt))
+	for _, run := range b.Runs {
+		fmt.Fprintf(&text, "\n%s `%s`", run.Status.icon(), run.Path)
+		if run.Status == batchSucceeded || run.Status == batchFailed {
+			fmt.Fprintf(&text, " · %s", formatElapsed(run.Duration))
+		}
+		if run.CostUSD > 0 {
+			fmt.Fprintf(&text, " · $%.4f", run.CostUSD)
+		}
+	}
+	if done < len(b.Runs) {
+		text.WriteString("\n\n_Results are posted in this thread as each path finishes._")
+	}
+	return text.String()
+}
+
+// summary formats the aggregated results posted when the batch finishes
+func (b *batch) summary() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, succeeded, failed, skipped := b.counts()
+	var totalCost float64
+	for _, run := range b.Runs {
+		totalCost += run.CostUSD
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "📦 *Batch finished* in %s: %d succeeded, %d failed", formatElapsed(time.Since(b.Started)), succeeded, failed)
+	if skipped > 0 {
+		fmt.Fprintf(&text, ", %d skipped", skipped)
+	}
+	fmt.Fprintf(&text, " · Total cost: $%.4f\n", totalCost)
+
+	for _, run := range b.Runs {
+		fmt.Fprintf(&text, "\n%s `%s`", run.Status.icon(), run.Path)
+		switch run.Status {
+		case batchSucceeded:
+			fmt.Fprintf(&text, " — %s", batchExcerpt(run.Result))
+		case batchFailed:
+			fmt.Fprintf(&text, " — %s", batchExcerpt(run.Err.Error()))
+		case batchSkipped:
+			text.WriteString(" — stopped before it started")
+		}
+	}
+	return text.String()
+}
+
+// batchExcerpt returns the first non-empty line of text, shortened
+func batchExcerpt(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if runes := []rune(line); len(runes) > maxBatchExcerptLength {
+			line = string(runes[:maxBatchExcerptLength]) + "…"
+		}
+		return line
+	}
+	return "_no output_"
+}
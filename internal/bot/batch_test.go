@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBatchArgs(t *testing.T) {
+	tests := []struct {
+		input   string
+		prompt  string
+		paths   []string
+		wantErr bool
+	}{
+		{"bump lodash --paths /srv/api,/srv/web", "bump lodash", []string{"/srv/api", "/srv/web"}, false},
+		{"--paths=/srv/api/ run the tests", "run the tests", []string{"/srv/api"}, false},
+		{"fix lint --paths /a, --paths /b,/a", "fix lint", []string{"/a", "/b"}, false},
+		{"bump lodash", "", nil, true},
+		{"--paths /srv/api", "", nil, true},
+		{"bump lodash --paths ,", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		prompt, paths, err := parseBatchArgs(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseBatchArgs(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if prompt != tt.prompt || !reflect.DeepEqual(paths, tt.paths) {
+			t.Errorf("parseBatchArgs(%q) = %q, %v; want %q, %v", tt.input, prompt, paths, tt.prompt, tt.paths)
+		}
+	}
+}
+
+func TestBatchSummary(t *testing.T) {
+	b := &batch{
+		Prompt: "bump lodash",
+		UserID: "U1",
+		Runs: []*batchRun{
+			{Path: "/srv/api", Status: batchSucceeded, Result: "\nBumped lodash to 4.17.21\nTests pass", CostUSD: 0.25},
+			{Path: "/srv/web", Status: batchSkipped},
+		},
+	}
+
+	want := "\n✅ `/srv/api` — Bumped lodash to 4.17.21\n⏭️ `/srv/web` — stopped before it started"
+	if summary := b.summary(); !strings.HasSuffix(summary, want) {
+		t.Errorf("Unexpected summary:\n%s", b.summary())
+	}
+}
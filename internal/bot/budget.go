@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/budget"
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+// applyBudgetPolicy decides which model and mode to use for the next run in a
+// session based on session and daily channel spend, starting from the
+// requested model
+func (s *Service) applyBudgetPolicy(sessionID, channelID, model string) budget.Decision {
+	if s.config.SessionBudgetUSD <= 0 && s.config.ChannelDailyBudgetUSD <= 0 {
+		return budget.Decision{Model: model}
+	}
+
+	var usages []budget.Usage
+
+	if s.config.SessionBudgetUSD > 0 {
+		spent, err := s.usage.GetSessionCost(sessionID)
+		if err != nil {
+			s.logger.Warn("Failed to get session cost for budget policy",
+				zap.String("session_id", sessionID),
+				zap.Error(err))
+		} else {
+			usages = append(usages, budget.Usage{Scope: "session", Spent: spent, Budget: s.config.SessionBudgetUSD})
+		}
+	}
+
+	if s.config.ChannelDailyBudgetUSD > 0 {
+		now := time.Now()
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		spent, err := s.usage.GetChannelCostSince(channelID, startOfDay)
+		if err != nil {
+			s.logger.Warn("Failed to get channel cost for budget policy",
+				zap.String("channel_id", channelID),
+				zap.Error(err))
+		} else {
+			usages = append(usages, budget.Usage{Scope: "daily channel", Spent: spent, Budget: s.config.ChannelDailyBudgetUSD})
+		}
+	}
+
+	s.alertBudgetBreaches(channelID, sessionID, usages)
+
+	decision := s.budgetPolicy.Decide(model, usages...)
+	if decision.Downgraded {
+		s.logger.Info("Budget policy downgraded run",
+			zap.String("session_id", sessionID),
+			zap.String("channel_id", channelID),
+			zap.String("requested_model", model),
+			zap.String("model", decision.Model),
+			zap.Bool("plan_mode", decision.PlanMode),
+			zap.String("scope", decision.Scope),
+			zap.Float64("ratio", decision.Ratio))
+	}
+	return decision
+}
+
+// recordUsage stores the cost, tokens, duration, and edited files of a
+// completed run, attributed to the user who started it; failures are only
+// logged
+func (s *Service) recordUsage(sessionID, channelID, userID, model string, response *claude.ClaudeCodeResponse, duration time.Duration) {
+	err := s.usage.RecordUsage(&repository.RunUsage{
+		SessionID:       sessionID,
+		ChannelID:       channelID,
+		UserID:          userID,
+		Identity:        s.authService.Identity(userID),
+		ClaudeSessionID: response.SessionID,
+		Model:           model,
+		CostUSD:         response.TotalCostUSD,
+		ContextTokens:   response.ContextTokens(),
+		TotalTokens:     response.Usage.Total(),
+		Duration:        duration,
+		EditedFiles:     response.EditedFiles(),
+	})
+	if err != nil {
+		s.logger.Error("Failed to record usage",
+			zap.String("session_id", sessionID),
+			zap.String("channel_id", channelID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,192 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+// channelInfo is the cached subset of conversations.info used for prompt context
+type channelInfo struct {
+	Name      string
+	Topic     string
+	Purpose   string
+	IsPrivate bool
+	fetchedAt time.Time
+}
+
+// channelInfoCache caches channel metadata to avoid a conversations.info call per message
+type channelInfoCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*channelInfo
+}
+
+// newChannelInfoCache creates a cache whose entries expire after ttl
+func newChannelInfoCache(ttl time.Duration) *channelInfoCache {
+	return &channelInfoCache{
+		ttl:     ttl,
+		entries: make(map[string]*channelInfo),
+	}
+}
+
+// get returns a cached entry if it has not expired
+func (c *channelInfoCache) get(channelID string) (*channelInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, exists := c.entries[channelID]
+	if !exists || time.Since(info.fetchedAt) > c.ttl {
+		return nil, false
+	}
+	return info, true
+}
+
+// set stores an entry
+func (c *channelInfoCache) set(channelID string, info *channelInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info.fetchedAt = time.Now()
+	c.entries[channelID] = info
+}
+
+// invalidate drops a cached entry, e.g. after a topic change
+func (c *channelInfoCache) invalidate(channelID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, channelID)
+}
+
+// getChannelInfo returns channel metadata, using the cache when possible
+func (s *Service) getChannelInfo(channelID string) (*channelInfo, error) {
+	if info, ok := s.channelInfo.get(channelID); ok {
+		return info, nil
+	}
+
+	channel, err := s.api().GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: channelID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel info: %w", err)
+	}
+
+	info := &channelInfo{
+		Name:      channel.Name,
+		Topic:     channel.Topic.Value,
+		Purpose:   channel.Purpose.Value,
+		IsPrivate: channel.IsPrivate,
+	}
+	s.channelInfo.set(channelID, info)
+	return info, nil
+}
+
+// channelContextEnabled resolves the per-channel toggle, falling back to config
+func (s *Service) channelContextEnabled(channelID string) bool {
+	if manager, ok := s.sessionManager.(session.ChannelContextManager); ok {
+		enabled, err := manager.GetChannelContextEnabled(channelID)
+		if err != nil {
+			s.logger.Warn("Failed to get channel context setting",
+				zap.String("channel_id", channelID),
+				zap.Error(err))
+		} else if enabled != nil {
+			return *enabled
+		}
+	}
+	return s.config.ChannelContextEnabled
+}
+
+// channelContextPrompt builds the system prompt addition describing the channel,
+// or returns an empty string when disabled or nothing useful is set
+func (s *Service) channelContextPrompt(channelID string) string {
+	if !s.channelContextEnabled(channelID) {
+		return ""
+	}
+
+	info, err := s.getChannelInfo(channelID)
+	if err != nil {
+		// Channel context is best effort; never block a prompt on it
+		s.logger.Warn("Failed to fetch channel context",
+			zap.String("channel_id", channelID),
+			zap.Error(err))
+		return ""
+	}
+
+	if info.Topic == "" && info.Purpose == "" {
+		return ""
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("SLACK CHANNEL CONTEXT - The user is talking to you from this channel:\n")
+	if info.Name != "" {
+		prompt.WriteString(fmt.Sprintf("- Channel: #%s\n", info.Name))
+	}
+	if info.Topic != "" {
+		prompt.WriteString(fmt.Sprintf("- Topic: %s\n", info.Topic))
+	}
+	if info.Purpose != "" {
+		prompt.WriteString(fmt.Sprintf("- Purpose: %s\n", info.Purpose))
+	}
+	prompt.WriteString("Let this shape your tone and caution (for example, be conservative with changes in production or incident channels).")
+
+	return prompt.String()
+}
+
+// handleContextSlashCommand handles /context channel on|off
+func (s *Service) handleContextSlashCommand(ctx context.Context, req *commands.Request) (string, error) {
+	manager, ok := s.sessionManager.(session.ChannelContextManager)
+	if !ok {
+		return "❌ **Channel context settings require database persistence**", nil
+	}
+
+	if len(req.Args) == 0 {
+		status := "off"
+		if s.channelContextEnabled(req.ChannelID) {
+			status = "on"
+		}
+		return fmt.Sprintf("🧭 **Channel Context:** `%s`\n\nWhen on, the channel topic and purpose are included in Claude's system prompt.\n\n**Usage:** `/context channel on|off`", status), nil
+	}
+
+	if req.Args[0] != "channel" || len(req.Args) < 2 || (req.Args[1] != "on" && req.Args[1] != "off") {
+		return "❌ **Invalid arguments**\n\n**Usage:** `/context channel on|off`", nil
+	}
+
+	enabled := req.Args[1] == "on"
+	if err := manager.SetChannelContextEnabled(req.ChannelID, enabled); err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "context_slash_command", "set_channel_context")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to update channel context setting"), nil
+	}
+
+	// Pick up topic edits made since the last fetch
+	s.channelInfo.invalidate(req.ChannelID)
+
+	s.logger.Info("Channel context setting updated",
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID),
+		zap.Bool("enabled", enabled))
+
+	if enabled {
+		return "✅ **Channel context enabled**\n\nThe channel topic and purpose will be included in Claude's system prompt.", nil
+	}
+	return "✅ **Channel context disabled**", nil
+}
+
+// joinPromptSections joins system prompt sections with blank lines,
+// skipping empty ones
+func joinPromptSections(sections ...string) string {
+	var kept []string
+	for _, section := range sections {
+		if section = strings.TrimSpace(section); section != "" {
+			kept = append(kept, section)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
@@ -0,0 +1,206 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const memoryUsage = "**Usage:**\n" +
+	"• `/remember <fact>` - Remember a fact for this channel, e.g. `/remember \"deploys run via make deploy\"`\n" +
+	"• `/memory list` - Show what this channel has asked Claude to remember\n" +
+	"• `/memory edit <id> <fact>` - Reword a fact\n" +
+	"• `/memory forget <id>` - Forget a fact\n\n" +
+	"Facts are added to every run in the channel, across sessions. Changing them requires write permission."
+
+const (
+	// maxChannelFactLength keeps a fact to a sentence or two
+	maxChannelFactLength = 500
+	// maxChannelFacts caps how much a channel adds to every system prompt
+	maxChannelFacts = 50
+)
+
+// parseChannelFact cleans up the text of a fact, which may be quoted
+func parseChannelFact(text string) (string, error) {
+	fact := strings.Join(strings.Fields(unquote(strings.TrimSpace(text))), " ")
+	if fact == "" {
+		return "", fmt.Errorf("no fact given")
+	}
+	if len([]rune(fact)) > maxChannelFactLength {
+		return "", fmt.Errorf("fact is %d characters; keep it under %d", len([]rune(fact)), maxChannelFactLength)
+	}
+	return fact, nil
+}
+
+// parseChannelFactID parses a fact ID as listed, with or without its #
+func parseChannelFactID(arg string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("`%s` isn't a fact ID; see `/memory list`", arg)
+	}
+	return id, nil
+}
+
+// buildChannelFactsPrompt renders a channel's facts for the system prompt
+func buildChannelFactsPrompt(facts []*repository.ChannelFact) string {
+	if len(facts) == 0 {
+		return ""
+	}
+	var prompt strings.Builder
+	prompt.WriteString("CHANNEL FACTS - People in this channel asked you to remember these. Rely on them unless the user says otherwise:\n")
+	for _, f := range facts {
+		fmt.Fprintf(&prompt, "- %s\n", f.Fact)
+	}
+	return strings.TrimSpace(prompt.String())
+}
+
+// channelFactsPrompt returns the channel's facts for a run's system prompt,
+// or "" if it has none
+func (s *Service) channelFactsPrompt(channelID string) string {
+	facts, err := s.channelFacts.ListChannelFacts(channelID)
+	if err != nil {
+		// Facts are best effort; never block a prompt on them
+		s.logger.Warn("Failed to get channel facts",
+			zap.String("channel_id", channelID),
+			zap.Error(err))
+		return ""
+	}
+	return buildChannelFactsPrompt(facts)
+}
+
+// authorizeMemoryChange checks that a user may change the channel's facts
+func (s *Service) authorizeMemoryChange(req *commands.Request, command string) error {
+	authCtx := &auth.AuthContext{UserID: req.UserID, ChannelID: req.ChannelID, Command: command, Timestamp: time.Now()}
+	return s.authService.AuthorizeUser(authCtx, auth.PermissionWrite)
+}
+
+// handleRememberCommand saves a fact for the channel
+func (s *Service) handleRememberCommand(ctx context.Context, req *commands.Request) (string, error) {
+	fact, err := parseChannelFact(req.Text)
+	if err != nil {
+		return fmt.Sprintf("❌ **Invalid fact:** %v\n\n%s", err, memoryUsage), nil
+	}
+
+	facts, err := s.channelFacts.ListChannelFacts(req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "memory_command", "list_facts")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to load channel facts"), nil
+	}
+	if len(facts) >= maxChannelFacts {
+		return fmt.Sprintf("❌ **This channel already has %d facts**\n\nForget or merge some with `/memory forget <id>` or `/memory edit <id> <fact>` first.", len(facts)), nil
+	}
+
+	id, err := s.channelFacts.AddChannelFact(req.ChannelID, fact, req.UserID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "memory_command", "add_fact")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to save channel fact"), nil
+	}
+
+	s.logger.Info("Channel fact saved",
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID),
+		zap.Int("fact_id", id))
+	return fmt.Sprintf("🧠 **Remembered** `#%d`\n> %s\n\nClaude will know this in every run in this channel. See everything with `/memory list`.", id, fact), nil
+}
+
+// handleMemoryCommand handles /memory list, edit, and forget
+func (s *Service) handleMemoryCommand(ctx context.Context, req *commands.Request) (string, error) {
+	args := req.Args
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	switch args[0] {
+	case "list":
+		if len(args) != 1 {
+			return "❌ **Invalid arguments**\n\n" + memoryUsage, nil
+		}
+		return s.handleMemoryListCommand(ctx, req), nil
+	case "edit":
+		if len(args) < 3 {
+			return "❌ **Invalid arguments**\n\n" + memoryUsage, nil
+		}
+		id, err := parseChannelFactID(args[1])
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid ID:** %v", err), nil
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(req.Text, args[0])), args[1]))
+		fact, err := parseChannelFact(rest)
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid fact:** %v\n\n%s", err, memoryUsage), nil
+		}
+		if err := s.authorizeMemoryChange(req, "/memory"); err != nil {
+			return fmt.Sprintf("❌ Authorization failed: %v", err), nil
+		}
+		return s.handleMemoryEditCommand(ctx, req, id, fact), nil
+	case "forget":
+		if len(args) != 2 {
+			return "❌ **Invalid arguments**\n\n" + memoryUsage, nil
+		}
+		id, err := parseChannelFactID(args[1])
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid ID:** %v", err), nil
+		}
+		if err := s.authorizeMemoryChange(req, "/memory"); err != nil {
+			return fmt.Sprintf("❌ Authorization failed: %v", err), nil
+		}
+		return s.handleMemoryForgetCommand(ctx, req, id), nil
+	}
+	return memoryUsage, nil
+}
+
+func (s *Service) handleMemoryListCommand(ctx context.Context, req *commands.Request) string {
+	facts, err := s.channelFacts.ListChannelFacts(req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "memory_command", "list_facts")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list channel facts")
+	}
+	if len(facts) == 0 {
+		return "ℹ️ **Nothing remembered in this channel yet**\n\nAdd a fact with `/remember <fact>`, e.g. `/remember \"deploys run via make deploy\"`."
+	}
+
+	var response strings.Builder
+	fmt.Fprintf(&response, "🧠 **Channel Facts** (%d of %d)\n", len(facts), maxChannelFacts)
+	for _, f := range facts {
+		fmt.Fprintf(&response, "\n• `#%d` %s _(<@%s>, %s)_", f.ID, f.Fact, f.UpdatedBy, f.UpdatedAt.Format("2006-01-02"))
+	}
+	response.WriteString("\n\nEvery run in this channel sees these. Change them with `/memory edit <id> <fact>` or `/memory forget <id>`.")
+	return response.String()
+}
+
+func (s *Service) handleMemoryEditCommand(ctx context.Context, req *commands.Request, id int, fact string) string {
+	updated, err := s.channelFacts.UpdateChannelFact(req.ChannelID, id, fact, req.UserID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "memory_command", "edit_fact")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to edit channel fact")
+	}
+	if !updated {
+		return fmt.Sprintf("ℹ️ This channel has no fact `#%d`", id)
+	}
+	return fmt.Sprintf("✅ **Fact `#%d` updated**\n> %s", id, fact)
+}
+
+func (s *Service) handleMemoryForgetCommand(ctx context.Context, req *commands.Request, id int) string {
+	deleted, err := s.channelFacts.DeleteChannelFact(req.ChannelID, id)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "memory_command", "forget_fact")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to forget channel fact")
+	}
+	if !deleted {
+		return fmt.Sprintf("ℹ️ This channel has no fact `#%d`", id)
+	}
+	s.logger.Info("Channel fact forgotten",
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID),
+		zap.Int("fact_id", id))
+	return fmt.Sprintf("✅ **Fact `#%d` forgotten**", id)
+}
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func TestParseChannelFact(t *testing.T) {
+	for input, want := range map[string]string{
+		`"deploys run via make deploy"`:  "deploys run via make deploy",
+		"“staging is   at staging.test”": "staging is at staging.test",
+		"  plain fact ":                  "plain fact",
+	} {
+		got, err := parseChannelFact(input)
+		if err != nil || got != want {
+			t.Errorf("parseChannelFact(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", `""`, strings.Repeat("x", maxChannelFactLength+1)} {
+		if _, err := parseChannelFact(input); err == nil {
+			t.Errorf("Expected parseChannelFact(%.20q) to fail", input)
+		}
+	}
+}
+
+func TestParseChannelFactID(t *testing.T) {
+	for input, want := range map[string]int{"3": 3, "#12": 12} {
+		if got, err := parseChannelFactID(input); err != nil || got != want {
+			t.Errorf("parseChannelFactID(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"x", "0", "-1", "#"} {
+		if _, err := parseChannelFactID(input); err == nil {
+			t.Errorf("Expected parseChannelFactID(%q) to fail", input)
+		}
+	}
+}
+
+func TestBuildChannelFactsPrompt(t *testing.T) {
+	if prompt := buildChannelFactsPrompt(nil); prompt != "" {
+		t.Errorf("Expected no prompt without facts, got %q", prompt)
+	}
+
+	prompt := buildChannelFactsPrompt([]*repository.ChannelFact{
+		{ID: 1, Fact: "deploys run via make deploy"},
+		{ID: 2, Fact: "the on-call rotation is in #ops"},
+	})
+	if !strings.HasPrefix(prompt, "CHANNEL FACTS") {
+		t.Errorf("Expected the prompt to be labeled as channel facts, got %q", prompt)
+	}
+	if !strings.HasSuffix(prompt, "- deploys run via make deploy\n- the on-call rotation is in #ops") {
+		t.Errorf("Expected one line per fact in order, got %q", prompt)
+	}
+}
+
+func TestJoinPromptSections(t *testing.T) {
+	if got := joinPromptSections("context", "", "  ", "prefs\n"); got != "context\n\nprefs" {
+		t.Errorf("joinPromptSections() = %q", got)
+	}
+	if got := joinPromptSections("", ""); got != "" {
+		t.Errorf("Expected empty sections to join to nothing, got %q", got)
+	}
+}
@@ -0,0 +1,252 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+// errChannelArchived cancels runs in a channel that was archived or deleted
+var errChannelArchived = errors.New("channel was archived")
+
+// errRunsCleared cancels runs an operator cleared through the admin API
+var errRunsCleared = errors.New("runs cleared by an operator")
+
+// archivedRunsGrace is how long an archived channel's canceled runs get to
+// wind down before its sessions are closed or trashed
+const archivedRunsGrace = 30 * time.Second
+
+// channelRuns tracks the runs in flight per channel, queued or running, so
+// they can be canceled when the channel goes away
+type channelRuns struct {
+	mu     sync.Mutex
+	nextID int
+	runs   map[string]map[int]context.CancelCauseFunc
+	live   map[string]int // runs not yet over, canceled or not
+	ended  chan struct{}  // closed and replaced whenever a run ends
+}
+
+func newChannelRuns() *channelRuns {
+	return &channelRuns{
+		runs:  make(map[string]map[int]context.CancelCauseFunc),
+		live:  make(map[string]int),
+		ended: make(chan struct{}),
+	}
+}
+
+// track returns a context that is canceled if the channel is archived, and
+// a func to call once the run is over
+func (c *channelRuns) track(ctx context.Context, channelID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	if c.runs[channelID] == nil {
+		c.runs[channelID] = make(map[int]context.CancelCauseFunc)
+	}
+	c.runs[channelID][id] = cancel
+	c.live[channelID]++
+	c.mu.Unlock()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			c.mu.Lock()
+			delete(c.runs[channelID], id)
+			if len(c.runs[channelID]) == 0 {
+				delete(c.runs, channelID)
+			}
+			if c.live[channelID]--; c.live[channelID] <= 0 {
+				delete(c.live, channelID)
+			}
+			close(c.ended)
+			c.ended = make(chan struct{})
+			c.mu.Unlock()
+			cancel(nil)
+		})
+	}
+}
+
+// cancel cancels every tracked run in a channel with cause, returning how
+// many there were
+func (c *channelRuns) cancel(channelID string, cause error) int {
+	c.mu.Lock()
+	runs := c.runs[channelID]
+	delete(c.runs, channelID)
+	c.mu.Unlock()
+
+	for _, cancel := range runs {
+		cancel(cause)
+	}
+	return len(runs)
+}
+
+// wait blocks until every run in a channel is over, including canceled ones
+// still winding down, or ctx is done
+func (c *channelRuns) wait(ctx context.Context, channelID string) error {
+	for {
+		c.mu.Lock()
+		live, ended := c.live[channelID], c.ended
+		c.mu.Unlock()
+		if live == 0 {
+			return nil
+		}
+		select {
+		case <-ended:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// count returns how many runs are tracked in a channel
+func (c *channelRuns) count(channelID string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.runs[channelID])
+}
+
+// archivedChannel extracts the channel, and the user who acted if Slack
+// says, from a channel or private channel lifecycle event
+func archivedChannel(data interface{}) (channelID, userID string, ok bool) {
+	switch event := data.(type) {
+	case *slackevents.ChannelArchiveEvent:
+		return event.Channel, event.User, true
+	case *slackevents.ChannelUnarchiveEvent:
+		return event.Channel, event.User, true
+	case *slackevents.ChannelDeletedEvent:
+		return event.Channel, "", true
+	case *slackevents.GroupArchiveEvent:
+		return event.Channel, "", true
+	case *slackevents.GroupUnarchiveEvent:
+		return event.Channel, "", true
+	case *slackevents.GroupDeletedEvent:
+		return event.Channel, "", true
+	}
+	return "", "", false
+}
+
+// handleChannelArchived stops everything the bot is doing in a channel that
+// was archived or deleted, and applies ARCHIVED_CHANNEL_SESSIONS to its
+// sessions
+func (s *Service) handleChannelArchived(channelID, userID, state string) {
+	fields := []zap.Field{
+		zap.String("channel_id", channelID),
+		zap.String("user_id", userID),
+		zap.String("state", state),
+	}
+
+	canceledRuns := s.channelRuns.cancel(channelID, errChannelArchived)
+	s.channelInfo.invalidate(channelID)
+	s.channelTypes.Delete(channelID)
+
+	canceledPrompts, err := s.delayedPrompts.CancelChannelDelayedPrompts(channelID)
+	if err != nil {
+		s.logger.Warn("Failed to cancel delayed prompts for archived channel", append(fields, zap.Error(err))...)
+	}
+
+	lifecycle, ok := s.sessionManager.(session.ChannelLifecycleManager)
+	if !ok {
+		s.logger.Info("Channel archived", append(fields,
+			zap.Int("canceled_runs", canceledRuns),
+			zap.Int("canceled_prompts", canceledPrompts))...)
+		return
+	}
+
+	if err := lifecycle.SetChannelLifecycleState(channelID, state); err != nil {
+		s.logger.Warn("Failed to record channel lifecycle state", append(fields, zap.Error(err))...)
+	}
+
+	// Let the canceled runs finish and release their sessions before the
+	// policy applies, so a run still writing to a session isn't trashed
+	// from under it. Waiting would hold up the event loop, so it's done aside.
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.applyArchivedSessionPolicy(lifecycle, channelID, userID, fields, canceledRuns, canceledPrompts)
+	}()
+}
+
+// applyArchivedSessionPolicy waits for an archived channel's canceled runs
+// to end, then applies ARCHIVED_CHANNEL_SESSIONS to its sessions. Sessions
+// whose run outlives the grace period are busy and left as they are.
+func (s *Service) applyArchivedSessionPolicy(lifecycle session.ChannelLifecycleManager, channelID, userID string, fields []zap.Field, canceledRuns, canceledPrompts int) {
+	ctx, cancel := context.WithTimeout(context.Background(), archivedRunsGrace)
+	go func() {
+		select {
+		case <-s.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	err := s.channelRuns.wait(ctx, channelID)
+	cancel()
+	if err != nil {
+		s.logger.Warn("Runs in archived channel are still winding down", append(fields, zap.Error(err))...)
+	}
+
+	var trashed []string
+	switch s.config.ArchivedChannelSessions {
+	case config.ArchivedChannelClose:
+		err = lifecycle.CloseChannelSessions(channelID)
+	case config.ArchivedChannelTrash:
+		trashed, err = lifecycle.TrashChannelSessions(channelID, userID)
+	default:
+		err = nil
+	}
+	if err != nil {
+		s.logger.Warn("Failed to apply archived channel session policy", append(fields,
+			zap.String("policy", string(s.config.ArchivedChannelSessions)),
+			zap.Error(err))...)
+	}
+
+	s.logger.Info("Channel archived", append(fields,
+		zap.Int("canceled_runs", canceledRuns),
+		zap.Int("canceled_prompts", canceledPrompts),
+		zap.String("session_policy", string(s.config.ArchivedChannelSessions)),
+		zap.Strings("trashed_sessions", trashed))...)
+}
+
+// handleChannelUnarchived marks a channel active again. Sessions kept by the
+// "keep" policy pick up where they left off.
+func (s *Service) handleChannelUnarchived(channelID, userID string) {
+	s.channelInfo.invalidate(channelID)
+
+	if lifecycle, ok := s.sessionManager.(session.ChannelLifecycleManager); ok {
+		if err := lifecycle.SetChannelLifecycleState(channelID, repository.ChannelLifecycleActive); err != nil {
+			s.logger.Warn("Failed to record channel lifecycle state",
+				zap.String("channel_id", channelID),
+				zap.Error(err))
+		}
+	}
+
+	s.logger.Info("Channel unarchived",
+		zap.String("channel_id", channelID),
+		zap.String("user_id", userID))
+}
+
+// channelInactive reports whether a channel is archived or deleted, so work
+// queued before that happened, like delayed prompts, isn't run there
+func (s *Service) channelInactive(channelID string) bool {
+	lifecycle, ok := s.sessionManager.(session.ChannelLifecycleManager)
+	if !ok {
+		return false
+	}
+	state, err := lifecycle.GetChannelLifecycleState(channelID)
+	if err != nil {
+		s.logger.Warn("Failed to get channel lifecycle state",
+			zap.String("channel_id", channelID),
+			zap.Error(err))
+		return false
+	}
+	return state != repository.ChannelLifecycleActive
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestChannelRuns(t *testing.T) {
+	runs := newChannelRuns()
+
+	first, untrackFirst := runs.track(context.Background(), "C1")
+	second, _ := runs.track(context.Background(), "C1")
+	other, untrackOther := runs.track(context.Background(), "C2")
+	defer untrackOther()
+
+	untrackFirst()
+	if first.Err() == nil {
+		t.Error("Expected untrack to release the run's context")
+	}
+	if !errors.Is(context.Cause(first), context.Canceled) {
+		t.Errorf("Expected a finished run to be canceled plainly, got %v", context.Cause(first))
+	}
+
+	if n := runs.cancel("C1", errChannelArchived); n != 1 {
+		t.Errorf("cancel(C1) = %d, want 1", n)
+	}
+	if !errors.Is(context.Cause(second), errChannelArchived) {
+		t.Errorf("Expected the run to be canceled by the archive, got %v", context.Cause(second))
+	}
+	if other.Err() != nil {
+		t.Error("Expected runs in other channels to keep going")
+	}
+	if n := runs.cancel("C1", errChannelArchived); n != 0 {
+		t.Errorf("cancel(C1) again = %d, want 0", n)
+	}
+}
+
+func TestChannelRuns_Wait(t *testing.T) {
+	runs := newChannelRuns()
+	_, untrack := runs.track(context.Background(), "C1")
+	runs.cancel("C1", errChannelArchived)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := runs.wait(ctx, "C1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected wait to hold for a canceled run still winding down, got %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- runs.wait(context.Background(), "C1") }()
+	untrack()
+	untrack()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("wait() = %v, want nil once the run ended", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected wait to return once the run ended")
+	}
+	if err := runs.wait(context.Background(), "C2"); err != nil {
+		t.Errorf("wait() on an idle channel = %v, want nil", err)
+	}
+}
+
+func TestArchivedChannel(t *testing.T) {
+	tests := []struct {
+		data    interface{}
+		channel string
+		user    string
+	}{
+		{&slackevents.ChannelArchiveEvent{Channel: "C1", User: "U1"}, "C1", "U1"},
+		{&slackevents.ChannelUnarchiveEvent{Channel: "C1", User: "U2"}, "C1", "U2"},
+		{&slackevents.ChannelDeletedEvent{Channel: "C1"}, "C1", ""},
+		{&slackevents.GroupArchiveEvent{Channel: "G1"}, "G1", ""},
+		{&slackevents.GroupDeletedEvent{Channel: "G1"}, "G1", ""},
+	}
+	for _, tt := range tests {
+		channelID, userID, ok := archivedChannel(tt.data)
+		if !ok || channelID != tt.channel || userID != tt.user {
+			t.Errorf("archivedChannel(%T) = %q, %q, %v", tt.data, channelID, userID, ok)
+		}
+	}
+
+	if _, _, ok := archivedChannel(&slackevents.MessageEvent{Channel: "C1"}); ok {
+		t.Error("Expected a message event not to be a channel lifecycle event")
+	}
+}
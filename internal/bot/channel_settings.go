@@ -0,0 +1,701 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const settingsUsage = "**Usage:** `/settings` | `/settings set <name> <value>` | `/settings unset <name>` | `/settings type [<type> set|unset permission [<mode>]]`"
+
+// footerNone is the footer template that shows only notices that need
+// attention, like budget warnings
+const footerNone = "none"
+
+// defaultFooterTemplate is the footer replies get unless their channel sets
+// a footer_template. `\n` separates lines.
+const defaultFooterTemplate = `• Mode: _{mode}_\n• Session: _{session}_\n• Working Dir: _{workdir}_\n• Messages: _{messages}_\n` +
+	`• Env: {env}\n• Agents: _{agents}_\n• Command Logs: _{command_logs}_`
+
+// footerPlaceholders lists the values footer templates can show
+var footerPlaceholders = []string{"mode", "session", "short_session", "workdir", "messages", "env", "agents", "command_logs"}
+
+// footerPlaceholderPattern matches a {name} placeholder in a footer template
+var footerPlaceholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Env lists that used to hold channel settings, imported once at startup
+const (
+	importChannelPermissionDefaults     = "CHANNEL_PERMISSION_DEFAULTS"
+	importChannelPriorities             = "CHANNEL_PRIORITIES"
+	importChannelTypePermissionDefaults = "CHANNEL_TYPE_PERMISSION_DEFAULTS"
+)
+
+// toolPattern matches a tool name, optionally with a rule like Bash(git:*)
+var toolPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\([^()]*\))?$`)
+
+// channelSetting describes a /settings setting
+type channelSetting struct {
+	Name        string
+	Column      string
+	Description string
+	Admin       bool // Changing it requires admin rather than write permission
+	// Values lists the allowed values; nil means Parse validates free text
+	Values func(cfg *config.Config) []string
+	// Parse converts a validated value to what the column stores
+	Parse func(value string) (interface{}, error)
+	// Current returns the channel's value, if it set one
+	Current func(settings *repository.ChannelSettings) (string, bool)
+	// Inherited describes the value the channel gets when it isn't set
+	Inherited func(cfg *config.Config) string
+}
+
+// availableChannelSettings lists the settings in the order they are shown
+var availableChannelSettings = []channelSetting{
+	{
+		Name:        "auto_respond",
+		Column:      repository.ChannelSettingAutoRespond,
+		Description: "Reply to every message, not only mentions",
+		Values:      fixedValues("on", "off"),
+		Parse:       parseSettingBool,
+		Current:     func(cs *repository.ChannelSettings) (string, bool) { return formatSettingBool(cs.AutoRespond) },
+		Inherited: func(cfg *config.Config) string {
+			if cfg.RequireMention {
+				return "off, except in DMs (`REQUIRE_MENTION`)"
+			}
+			return "on"
+		},
+	},
+	{
+		Name:        "model",
+		Column:      repository.ChannelSettingModel,
+		Description: "Claude model for runs here",
+		Values: func(cfg *config.Config) []string {
+			models := cfg.ClaudeModels
+			for _, model := range models {
+				if model == cfg.ClaudeModel {
+					return models
+				}
+			}
+			return append([]string{cfg.ClaudeModel}, models...)
+		},
+		Parse:     func(value string) (interface{}, error) { return value, nil },
+		Current:   func(cs *repository.ChannelSettings) (string, bool) { return formatSettingString(cs.Model) },
+		Inherited: func(cfg *config.Config) string { return fmt.Sprintf("`%s` (`CLAUDE_MODEL`)", cfg.ClaudeModel) },
+	},
+	{
+		Name:        "priority",
+		Column:      repository.ChannelSettingRunPriority,
+		Description: "Priority of runs here when run slots are busy",
+		Values:      fixedValues("urgent", "normal", "batch"),
+		Parse:       func(value string) (interface{}, error) { return value, nil },
+		Current:     func(cs *repository.ChannelSettings) (string, bool) { return formatSettingString(cs.RunPriority) },
+		Inherited:   func(cfg *config.Config) string { return "normal" },
+	},
+	{
+		Name:        "permission",
+		Column:      repository.ChannelSettingPermissionMode,
+		Description: "Permission mode the channel starts in and reverts to",
+		Values: fixedValues(string(config.PermissionModeDefault), string(config.PermissionModeAcceptEdits),
+			string(config.PermissionModeBypassPerms), string(config.PermissionModePlan)),
+		Parse:     func(value string) (interface{}, error) { return value, nil },
+		Current:   func(cs *repository.ChannelSettings) (string, bool) { return formatSettingString(cs.PermissionMode) },
+		Inherited: func(cfg *config.Config) string { return "the channel type's or workspace default" },
+	},
+	{
+		Name:        "reply_in_thread",
+		Column:      repository.ChannelSettingReplyInThread,
+		Description: "Reply to top-level messages in a thread under them",
+		Values:      fixedValues("on", "off"),
+		Parse:       parseSettingBool,
+		Current:     func(cs *repository.ChannelSettings) (string, bool) { return formatSettingBool(cs.ReplyInThread) },
+		Inherited:   func(cfg *config.Config) string { return "off" },
+	},
+	{
+		Name:        "footer_template",
+		Column:      repository.ChannelSettingFooterTemplate,
+		Description: "Footer under replies, with placeholders such as `{mode}` and `{session}`, or `none`",
+		Parse:       parseFooterTemplate,
+		Current:     func(cs *repository.ChannelSettings) (string, bool) { return formatSettingString(cs.FooterTemplate) },
+		Inherited:   func(cfg *config.Config) string { return "mode, session, working directory, messages, and run details" },
+	},
+	{
+		Name:        "footer_env",
+		Column:      repository.ChannelSettingFooterEnv,
+		Description: "Run environment fields the footer's `{env}` shows: `git`, `kube`, `venv`, or `none`",
+		Parse:       parseFooterEnv,
+		Current: func(cs *repository.ChannelSettings) (string, bool) {
+			return formatToolList(cs.FooterEnv, "none"), cs.FooterEnv != nil
+		},
+		Inherited: func(cfg *config.Config) string {
+			return formatToolList(cfg.FooterEnv, "none") + " (`FOOTER_ENV`)"
+		},
+	},
+	{
+		Name:        "agents",
+		Column:      repository.ChannelSettingAgents,
+		Description: "Configured sub-agents enabled here, comma-separated, or `none`",
+		Parse:       parseAgentList,
+		Current: func(cs *repository.ChannelSettings) (string, bool) {
+			return formatToolList(cs.Agents, "none"), cs.Agents != nil
+		},
+		Inherited: func(cfg *config.Config) string { return "all configured agents" },
+	},
+	{
+		Name:        "tool_events",
+		Column:      repository.ChannelSettingToolEvents,
+		Description: "Post each command run and file edited to the thread during runs",
+		Values:      fixedValues("on", "off"),
+		Parse:       parseSettingBool,
+		Current:     func(cs *repository.ChannelSettings) (string, bool) { return formatSettingBool(cs.ToolEvents) },
+		Inherited: func(cfg *config.Config) string {
+			if cfg.ToolEvents {
+				return "on (`TOOL_EVENTS`)"
+			}
+			return "off (`TOOL_EVENTS`)"
+		},
+	},
+	{
+		Name:        "observer",
+		Column:      repository.ChannelSettingObserver,
+		Description: "Only discuss the conversation: plan mode, no working directory, no file or shell tools",
+		Admin:       true,
+		Values:      fixedValues("on", "off"),
+		Parse:       parseSettingBool,
+		Current:     func(cs *repository.ChannelSettings) (string, bool) { return formatSettingBool(cs.Observer) },
+		Inherited:   func(cfg *config.Config) string { return "off" },
+	},
+	{
+		Name:        "allowed_tools",
+		Column:      repository.ChannelSettingAllowedTools,
+		Description: "Tools Claude may use here, comma-separated, or `all`",
+		Admin:       true,
+		Parse:       func(value string) (interface{}, error) { return parseToolList(value, "all") },
+		Current: func(cs *repository.ChannelSettings) (string, bool) {
+			return formatToolList(cs.AllowedTools, "all"), cs.AllowedTools != nil
+		},
+		Inherited: func(cfg *config.Config) string {
+			return formatToolList(cfg.AllowedTools, "all") + " (`ALLOWED_TOOLS`)"
+		},
+	},
+	{
+		Name:        "disallowed_tools",
+		Column:      repository.ChannelSettingDisallowedTools,
+		Description: "Tools Claude may not use here, on top of `DISALLOWED_TOOLS`, or `none`",
+		Admin:       true,
+		Parse:       func(value string) (interface{}, error) { return parseToolList(value, "none") },
+		Current: func(cs *repository.ChannelSettings) (string, bool) {
+			return formatToolList(cs.DisallowedTools, "none"), cs.DisallowedTools != nil
+		},
+		Inherited: func(cfg *config.Config) string { return "none" },
+	},
+	{
+		Name:        "mirror_channel",
+		Column:      repository.ChannelSettingMirrorChannel,
+		Description: "Channel Claude's responses here are cross-posted to",
+		Admin:       true,
+		Parse:       parseMirrorChannel,
+		Current: func(cs *repository.ChannelSettings) (string, bool) {
+			if cs.MirrorChannel == nil {
+				return "", false
+			}
+			return "<#" + *cs.MirrorChannel + ">", true
+		},
+		Inherited: func(cfg *config.Config) string { return "not mirrored" },
+	},
+	{
+		Name:        "mirror_responses",
+		Column:      repository.ChannelSettingMirrorResponses,
+		Description: "Cross-post responses to `mirror_channel`",
+		Admin:       true,
+		Values:      fixedValues("on", "off"),
+		Parse:       parseSettingBool,
+		Current:     func(cs *repository.ChannelSettings) (string, bool) { return formatSettingBool(cs.MirrorResponses) },
+		Inherited:   func(cfg *config.Config) string { return "on" },
+	},
+	{
+		Name:        "mirror_prompts",
+		Column:      repository.ChannelSettingMirrorPrompts,
+		Description: "Cross-post the prompts that start runs to `mirror_channel`",
+		Admin:       true,
+		Values:      fixedValues("on", "off"),
+		Parse:       parseSettingBool,
+		Current:     func(cs *repository.ChannelSettings) (string, bool) { return formatSettingBool(cs.MirrorPrompts) },
+		Inherited:   func(cfg *config.Config) string { return "off" },
+	},
+}
+
+// fixedValues returns a Values func for a setting with a fixed set of values
+func fixedValues(values ...string) func(*config.Config) []string {
+	return func(*config.Config) []string { return values }
+}
+
+// lookupChannelSetting finds a setting by name
+func lookupChannelSetting(name string) (channelSetting, bool) {
+	for _, setting := range availableChannelSettings {
+		if setting.Name == name {
+			return setting, true
+		}
+	}
+	return channelSetting{}, false
+}
+
+// normalizeChannelSetting validates a value for a setting and returns it in
+// its stored form
+func normalizeChannelSetting(cfg *config.Config, setting channelSetting, value string) (interface{}, error) {
+	value = strings.TrimSpace(value)
+	if setting.Values == nil {
+		return setting.Parse(value)
+	}
+
+	allowed := setting.Values(cfg)
+	for _, candidate := range allowed {
+		if strings.EqualFold(value, candidate) {
+			return setting.Parse(candidate)
+		}
+	}
+	return nil, fmt.Errorf("`%s` must be one of `%s`", setting.Name, strings.Join(allowed, "`, `"))
+}
+
+func parseSettingBool(value string) (interface{}, error) {
+	return value == "on", nil
+}
+
+func formatSettingBool(value *bool) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+	if *value {
+		return "on", true
+	}
+	return "off", true
+}
+
+func formatSettingString(value *string) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+	return *value, true
+}
+
+// parseToolList parses comma-separated tool names; all is the word for an
+// empty list
+func parseToolList(value, all string) ([]string, error) {
+	if strings.EqualFold(value, all) {
+		return []string{}, nil
+	}
+
+	var tools []string
+	for _, tool := range strings.Split(value, ",") {
+		tool = strings.TrimSpace(tool)
+		if tool == "" {
+			continue
+		}
+		if !toolPattern.MatchString(tool) {
+			return nil, fmt.Errorf("`%s` isn't a tool name like `Read` or `Bash(git:*)`", tool)
+		}
+		tools = append(tools, tool)
+	}
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("list tools separated by commas, or use `%s`", all)
+	}
+	return tools, nil
+}
+
+// parseFooterTemplate checks a footer template only uses known placeholders
+func parseFooterTemplate(value string) (interface{}, error) {
+	if value == "" {
+		return nil, fmt.Errorf("give a template such as `• _{mode} · {short_session}_`, or `none`")
+	}
+	if strings.EqualFold(value, footerNone) {
+		return footerNone, nil
+	}
+	for _, match := range footerPlaceholderPattern.FindAllStringSubmatch(value, -1) {
+		if !containsString(footerPlaceholders, match[1]) {
+			return nil, fmt.Errorf("unknown placeholder `{%s}`; use `{%s}`", match[1], strings.Join(footerPlaceholders, "}`, `{"))
+		}
+	}
+	return value, nil
+}
+
+// parseFooterEnv parses environment snapshot fields separated by commas or
+// spaces; none is the word for an empty list
+func parseFooterEnv(value string) (interface{}, error) {
+	if strings.EqualFold(value, "none") {
+		return []string{}, nil
+	}
+	return config.ParseFooterEnvFields(strings.Join(strings.Fields(strings.ReplaceAll(value, ",", " ")), ","))
+}
+
+// parseAgentList parses agent names separated by commas or spaces; none is
+// the word for an empty list. Whether the agents exist is checked against
+// the configured ones when the setting is changed.
+func parseAgentList(value string) (interface{}, error) {
+	if strings.EqualFold(value, "none") {
+		return []string{}, nil
+	}
+	names := strings.Fields(strings.ReplaceAll(value, ",", " "))
+	if len(names) == 0 {
+		return nil, fmt.Errorf("list agents separated by commas, or use `none`")
+	}
+	return names, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// codeValue formats a setting's value as code, unless it already is or is a
+// channel mention
+func codeValue(value string) string {
+	if strings.HasPrefix(value, "`") || strings.HasPrefix(value, "<#") {
+		return value
+	}
+	return "`" + value + "`"
+}
+
+func formatToolList(tools []string, empty string) string {
+	if len(tools) == 0 {
+		return empty
+	}
+	return "`" + strings.Join(tools, "`, `") + "`"
+}
+
+// channelSettings returns a channel's settings. Lookups are best effort: on
+// failure the channel behaves as if it set nothing.
+func (s *Service) channelSettings(channelID string) *repository.ChannelSettings {
+	if s.settings == nil {
+		return &repository.ChannelSettings{ChannelID: channelID}
+	}
+	settings, err := s.settings.GetChannelSettings(channelID)
+	if err != nil {
+		s.logger.Warn("Failed to get channel settings",
+			zap.String("channel_id", channelID),
+			zap.Error(err))
+	}
+	if settings == nil {
+		return &repository.ChannelSettings{ChannelID: channelID}
+	}
+	return settings
+}
+
+// mentionRequired reports whether messages in a channel are ignored unless
+// they mention the bot: its auto_respond setting, else REQUIRE_MENTION
+// outside DMs
+func (s *Service) mentionRequired(channelType string, settings *repository.ChannelSettings) bool {
+	if settings.AutoRespond != nil {
+		return !*settings.AutoRespond
+	}
+	return s.config.RequireMention && !isDirectMessage(channelType)
+}
+
+// channelModel returns the model runs in a channel use before overrides and
+// budget policy
+func (s *Service) channelModel(settings *repository.ChannelSettings) string {
+	if settings.Model != nil {
+		return *settings.Model
+	}
+	return s.config.ClaudeModel
+}
+
+// replyThread returns the thread to reply to a message in. With
+// reply_in_thread on, top-level messages are answered in a thread under them.
+func replyThread(event *slackevents.MessageEvent, settings *repository.ChannelSettings) string {
+	if event.ThreadTimeStamp == "" && settings.ReplyInThread != nil && *settings.ReplyInThread {
+		return event.TimeStamp
+	}
+	return event.ThreadTimeStamp
+}
+
+// channelFooterTemplate returns the footer template replies in a channel use
+func channelFooterTemplate(settings *repository.ChannelSettings) string {
+	if settings.FooterTemplate != nil {
+		return *settings.FooterTemplate
+	}
+	return defaultFooterTemplate
+}
+
+// renderFooterTemplate fills in a footer template's placeholders and returns
+// its lines. Lines whose placeholders are all empty are left out, so
+// `• Agents: _{agents}_` only shows when agents were used.
+func renderFooterTemplate(template string, values map[string]string) []string {
+	if template == footerNone {
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(template, `\n`, "\n"), "\n") {
+		placeholders, filled := 0, 0
+		line = footerPlaceholderPattern.ReplaceAllStringFunc(line, func(placeholder string) string {
+			placeholders++
+			value := values[strings.Trim(placeholder, "{}")]
+			if value != "" {
+				filled++
+			}
+			return value
+		})
+		if strings.TrimSpace(line) == "" || (placeholders > 0 && filled == 0) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// storeChannelSetting sets one of a channel's settings, or clears it when
+// value is nil
+func (s *Service) storeChannelSetting(channelID, column string, value interface{}, userID string) error {
+	if value == nil {
+		_, err := s.settings.UnsetChannelSetting(channelID, column, userID)
+		return err
+	}
+	return s.settings.SetChannelSetting(channelID, column, value, userID)
+}
+
+// importChannelSettings moves the deprecated per-channel env lists into
+// channel settings, leaving settings already made with /settings alone.
+// Each list is imported once, so settings cleared later stay cleared.
+func (s *Service) importChannelSettings() {
+	permissionModes := make(map[string]string, len(s.config.ChannelPermissionDefaults))
+	for channelID, mode := range s.config.ChannelPermissionDefaults {
+		permissionModes[channelID] = string(mode)
+	}
+	s.logChannelSettingsImport(importChannelPermissionDefaults, "/settings set permission", len(permissionModes),
+		func() (int, bool, error) {
+			return s.settings.ImportChannelSettings(importChannelPermissionDefaults, repository.ChannelSettingPermissionMode, permissionModes)
+		})
+
+	s.logChannelSettingsImport(importChannelPriorities, "/settings set priority", len(s.config.ChannelPriorities),
+		func() (int, bool, error) {
+			return s.settings.ImportChannelSettings(importChannelPriorities, repository.ChannelSettingRunPriority, s.config.ChannelPriorities)
+		})
+
+	typeModes := make(map[string]string, len(s.config.ChannelTypePermissionDefaults))
+	for channelType, mode := range s.config.ChannelTypePermissionDefaults {
+		typeModes[channelType] = string(mode)
+	}
+	s.logChannelSettingsImport(importChannelTypePermissionDefaults, "/settings type", len(typeModes),
+		func() (int, bool, error) {
+			return s.settings.ImportChannelTypePermissionModes(importChannelTypePermissionDefaults, typeModes)
+		})
+}
+
+// logChannelSettingsImport imports a deprecated env list that has entries
+// and tells admins where its settings are managed now
+func (s *Service) logChannelSettingsImport(source, command string, listed int, importList func() (int, bool, error)) {
+	if listed == 0 {
+		return
+	}
+
+	stored, imported, err := importList()
+	if err != nil {
+		s.logger.Warn("Failed to import deprecated env list into channel settings",
+			zap.String("variable", source),
+			zap.Error(err))
+		return
+	}
+	if !imported {
+		s.logger.Warn(fmt.Sprintf("%s is deprecated and was imported into channel settings before; it is ignored. Manage its settings with %s and remove the variable.", source, command))
+		return
+	}
+	s.logger.Warn(fmt.Sprintf("%s is deprecated; its entries are now channel settings. Manage them with %s and remove the variable.", source, command),
+		zap.Int("imported", stored),
+		zap.Int("listed", listed))
+}
+
+// handleSettingsCommand shows and changes the channel's settings
+func (s *Service) handleSettingsCommand(ctx context.Context, req *commands.Request) (string, error) {
+	args := req.Args
+	if len(args) == 0 {
+		args = []string{"show"}
+	}
+
+	switch {
+	case args[0] == "show" && len(args) == 1:
+		return s.formatChannelSettings(s.channelSettings(req.ChannelID)), nil
+	case args[0] == "set" && len(args) >= 3:
+		return s.handleSettingsSetCommand(ctx, req, args[1], strings.Join(args[2:], " ")), nil
+	case args[0] == "unset" && len(args) == 2:
+		return s.handleSettingsUnsetCommand(ctx, req, args[1]), nil
+	case args[0] == "type":
+		return s.handleSettingsTypeCommand(ctx, req, args[1:]), nil
+	default:
+		return "❌ **Invalid arguments**\n\n" + settingsUsage, nil
+	}
+}
+
+// authorizeSettingChange checks the user may change a setting in the channel
+func (s *Service) authorizeSettingChange(req *commands.Request, setting channelSetting) string {
+	if setting.Admin {
+		if !s.authService.IsUserAdmin(req.UserID) {
+			return fmt.Sprintf("❌ **Admin Only**\n\nOnly admins can change `%s`.", setting.Name)
+		}
+		return ""
+	}
+	authCtx := &auth.AuthContext{UserID: req.UserID, ChannelID: req.ChannelID, Command: "/settings", Timestamp: time.Now()}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+		return fmt.Sprintf("❌ Authorization failed: %v", err)
+	}
+	return ""
+}
+
+func (s *Service) handleSettingsSetCommand(ctx context.Context, req *commands.Request, name, value string) string {
+	setting, ok := lookupChannelSetting(strings.ToLower(name))
+	if !ok {
+		return fmt.Sprintf("❌ **Unknown setting:** `%s`\n\nSee `/settings` for the available settings.", name)
+	}
+	if denied := s.authorizeSettingChange(req, setting); denied != "" {
+		return denied
+	}
+	// A mirror to a more visible channel is confirmed by adding "confirm"
+	confirmed := false
+	if setting.Column == repository.ChannelSettingMirrorChannel {
+		value, confirmed = strings.CutSuffix(strings.TrimSpace(value), " confirm")
+	}
+	stored, err := normalizeChannelSetting(s.config, setting, value)
+	if err != nil {
+		return fmt.Sprintf("❌ **Invalid value:** %v", err)
+	}
+	if setting.Column == repository.ChannelSettingMirrorChannel {
+		if problem := s.checkMirrorTarget(req.ChannelID, stored.(string), confirmed); problem != "" {
+			return problem
+		}
+	}
+	if setting.Column == repository.ChannelSettingAgents {
+		for _, name := range stored.([]string) {
+			if _, exists := s.agents[name]; !exists {
+				return fmt.Sprintf("❌ **Unknown agent:** `%s`\n\nConfigured agents: %s", name, formatAgentNames(s.agents.Names()))
+			}
+		}
+	}
+
+	if err := s.settings.SetChannelSetting(req.ChannelID, setting.Column, stored, req.UserID); err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "settings_command", "set_setting")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to save setting")
+	}
+
+	current, _ := setting.Current(s.channelSettings(req.ChannelID))
+	if setting.Column == repository.ChannelSettingPermissionMode {
+		return fmt.Sprintf("✅ **`%s` set to %s**\n\nThe channel is in this mode now, unless a temporary `/permission` is in effect, and returns to it when one expires.", setting.Name, codeValue(current))
+	}
+	return fmt.Sprintf("✅ **`%s` set to %s**\n\nApplies to the next message in this channel.", setting.Name, codeValue(current))
+}
+
+func (s *Service) handleSettingsUnsetCommand(ctx context.Context, req *commands.Request, name string) string {
+	setting, ok := lookupChannelSetting(strings.ToLower(name))
+	if !ok {
+		return fmt.Sprintf("❌ **Unknown setting:** `%s`\n\nSee `/settings` for the available settings.", name)
+	}
+	if denied := s.authorizeSettingChange(req, setting); denied != "" {
+		return denied
+	}
+
+	removed, err := s.settings.UnsetChannelSetting(req.ChannelID, setting.Column, req.UserID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "settings_command", "unset_setting")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to clear setting")
+	}
+	if !removed {
+		return fmt.Sprintf("ℹ️ `%s` wasn't set", setting.Name)
+	}
+	if setting.Column == repository.ChannelSettingPermissionMode {
+		return fmt.Sprintf("✅ **`%s` cleared**\n\nThe channel keeps its current mode; new channels start in %s.", setting.Name, setting.Inherited(s.config))
+	}
+	return fmt.Sprintf("✅ **`%s` cleared**\n\nThe channel inherits the default again: %s.", setting.Name, setting.Inherited(s.config))
+}
+
+// formatChannelSettings lists every setting with the channel's value or what
+// it inherits
+func (s *Service) formatChannelSettings(settings *repository.ChannelSettings) string {
+	var response strings.Builder
+	response.WriteString("⚙️ **Channel Settings**\n")
+	for _, setting := range availableChannelSettings {
+		if value, ok := setting.Current(settings); ok {
+			response.WriteString(fmt.Sprintf("\n• `%s`: %s", setting.Name, codeValue(value)))
+			continue
+		}
+		response.WriteString(fmt.Sprintf("\n• `%s`: _%s_ — %s", setting.Name, setting.Inherited(s.config), setting.Description))
+	}
+	response.WriteString("\n\nChange one with `/settings set <name> <value>`, or go back to the default with `/settings unset <name>`. " +
+		"`observer`, `allowed_tools`, `disallowed_tools`, and the `mirror_` settings require admin; the rest require write permission.")
+	return response.String()
+}
+
+// handleSettingsTypeCommand shows and changes the permission modes new
+// channels start in by channel type
+func (s *Service) handleSettingsTypeCommand(ctx context.Context, req *commands.Request, args []string) string {
+	if len(args) == 0 {
+		modes, err := s.settings.GetChannelTypePermissionModes()
+		if err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "settings_command", "get_type_settings")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to get channel type settings")
+		}
+		return formatChannelTypeSettings(modes)
+	}
+
+	channelType := strings.ToLower(args[0])
+	if !containsString(config.ChannelTypes, channelType) {
+		return fmt.Sprintf("❌ **Unknown channel type:** `%s`\n\nUse one of `%s`.", args[0], strings.Join(config.ChannelTypes, "`, `"))
+	}
+	var mode string
+	switch {
+	case len(args) == 4 && args[1] == "set" && args[2] == "permission":
+		setting, _ := lookupChannelSetting("permission")
+		normalized, err := normalizeChannelSetting(s.config, setting, args[3])
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid value:** %v", err)
+		}
+		mode = normalized.(string)
+	case len(args) == 3 && args[1] == "unset" && args[2] == "permission":
+	default:
+		return "❌ **Invalid arguments**\n\n" + settingsUsage
+	}
+
+	if !s.authService.IsUserAdmin(req.UserID) {
+		return "❌ **Admin Only**\n\nOnly admins can change the settings of channel types."
+	}
+	changed, err := s.settings.SetChannelTypePermissionMode(channelType, mode, req.UserID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "settings_command", "set_type_setting")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to save channel type setting")
+	}
+	if mode == "" {
+		if !changed {
+			return fmt.Sprintf("ℹ️ `permission` wasn't set for %s channels", channelType)
+		}
+		return fmt.Sprintf("✅ **`permission` cleared for %s channels**\n\nNew ones start in the workspace default.", channelType)
+	}
+	return fmt.Sprintf("✅ **New %s channels start in `%s`**\n\nChannels already seen keep their mode; change one with `/settings set permission`.", channelType, mode)
+}
+
+// formatChannelTypeSettings lists the permission mode each channel type
+// starts in
+func formatChannelTypeSettings(modes map[string]string) string {
+	var response strings.Builder
+	response.WriteString("⚙️ **Channel Type Settings**\n")
+	for _, channelType := range config.ChannelTypes {
+		if mode, ok := modes[channelType]; ok {
+			response.WriteString(fmt.Sprintf("\n• `%s`: `permission` `%s`", channelType, mode))
+			continue
+		}
+		response.WriteString(fmt.Sprintf("\n• `%s`: _workspace default_", channelType))
+	}
+	response.WriteString("\n\nNew channels start in their type's mode unless their own `permission` setting names one. " +
+		"Change one with `/settings type <type> set permission <mode>` or `/settings type <type> unset permission` (admin only).")
+	return response.String()
+}
@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func TestNormalizeChannelSetting(t *testing.T) {
+	cfg := &config.Config{ClaudeModel: "sonnet", ClaudeModels: []string{"opus", "haiku"}}
+	tests := []struct {
+		name, value string
+		want        interface{}
+		wantErr     bool
+	}{
+		{"auto_respond", "ON", true, false},
+		{"auto_respond", "off", false, false},
+		{"auto_respond", "maybe", nil, true},
+		{"model", "sonnet", "sonnet", false},
+		{"model", "Opus", "opus", false},
+		{"model", "gpt-4", nil, true},
+		{"permission", "acceptedits", "acceptEdits", false},
+		{"permission", "yolo", nil, true},
+		{"priority", "Urgent", "urgent", false},
+		{"priority", "asap", nil, true},
+		{"footer_template", "• _{mode} · {short_session}_", "• _{mode} · {short_session}_", false},
+		{"footer_template", "NONE", "none", false},
+		{"footer_template", "• {cost}", nil, true},
+		{"footer_env", "git, kube", []string{"git", "kube"}, false},
+		{"footer_env", "none", []string{}, false},
+		{"footer_env", "docker", nil, true},
+		{"agents", "reviewer tester", []string{"reviewer", "tester"}, false},
+		{"agents", "none", []string{}, false},
+		{"allowed_tools", "Read, Grep,Bash(git:*)", []string{"Read", "Grep", "Bash(git:*)"}, false},
+		{"allowed_tools", "all", []string{}, false},
+		{"allowed_tools", "rm -rf", nil, true},
+		{"disallowed_tools", "none", []string{}, false},
+		{"disallowed_tools", " , ", nil, true},
+	}
+
+	for _, tt := range tests {
+		setting, ok := lookupChannelSetting(tt.name)
+		if !ok {
+			t.Fatalf("Unknown setting %q", tt.name)
+		}
+		got, err := normalizeChannelSetting(cfg, setting, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeChannelSetting(%s, %q) error = %v, wantErr %v", tt.name, tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("normalizeChannelSetting(%s, %q) = %#v, want %#v", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAllowedToolsChannelOverrides(t *testing.T) {
+	s := &Service{config: &config.Config{AllowedTools: []string{"Read", "Edit", "Bash"}, DisallowedTools: []string{"Bash"}}}
+
+	if got := s.allowedTools(&repository.ChannelSettings{}); !reflect.DeepEqual(got, []string{"Read", "Edit"}) {
+		t.Errorf("allowedTools() = %v, want ALLOWED_TOOLS without DISALLOWED_TOOLS", got)
+	}
+	settings := &repository.ChannelSettings{AllowedTools: []string{"Read", "Grep", "Bash"}, DisallowedTools: []string{"Grep"}}
+	if got := s.allowedTools(settings); !reflect.DeepEqual(got, []string{"Read"}) {
+		t.Errorf("allowedTools() = %v, want the channel's tools without either disallowed list", got)
+	}
+	if got := s.allowedTools(&repository.ChannelSettings{AllowedTools: []string{}}); len(got) != 0 {
+		t.Errorf("allowedTools() = %v, want all tools", got)
+	}
+}
+
+func TestReplyThread(t *testing.T) {
+	on, off := true, false
+	topLevel := &slackevents.MessageEvent{TimeStamp: "1.1"}
+	threaded := &slackevents.MessageEvent{TimeStamp: "2.2", ThreadTimeStamp: "1.1"}
+
+	if got := replyThread(topLevel, &repository.ChannelSettings{}); got != "" {
+		t.Errorf("replyThread() = %q, want the channel", got)
+	}
+	if got := replyThread(topLevel, &repository.ChannelSettings{ReplyInThread: &off}); got != "" {
+		t.Errorf("replyThread() = %q, want the channel", got)
+	}
+	if got := replyThread(topLevel, &repository.ChannelSettings{ReplyInThread: &on}); got != "1.1" {
+		t.Errorf("replyThread() = %q, want a thread under the message", got)
+	}
+	if got := replyThread(threaded, &repository.ChannelSettings{ReplyInThread: &on}); got != "1.1" {
+		t.Errorf("replyThread() = %q, want the existing thread", got)
+	}
+}
+
+func TestFormatChannelSettings(t *testing.T) {
+	s := &Service{config: &config.Config{ClaudeModel: "sonnet", RequireMention: true}}
+	model := "opus"
+	got := s.formatChannelSettings(&repository.ChannelSettings{Model: &model, DisallowedTools: []string{"WebFetch"}})
+
+	for _, want := range []string{
+		"• `model`: `opus`",
+		"• `disallowed_tools`: `WebFetch`",
+		"• `auto_respond`: _off, except in DMs (`REQUIRE_MENTION`)_",
+		"• `footer_template`: _mode, session, working directory, messages, and run details_",
+		"• `priority`: _normal_",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatChannelSettings() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderFooterTemplate(t *testing.T) {
+	values := map[string]string{"mode": "plan", "session": "abc123", "workdir": "/repo", "messages": "4"}
+
+	got := renderFooterTemplate(defaultFooterTemplate, values)
+	want := []string{"• Mode: _plan_", "• Session: _abc123_", "• Working Dir: _/repo_", "• Messages: _4_"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("renderFooterTemplate(default) = %q, want lines with empty placeholders left out: %q", got, want)
+	}
+
+	got = renderFooterTemplate(`{mode} in {workdir} · {agents}\nCompliance: internal use only`, values)
+	want = []string{"plan in /repo · ", "Compliance: internal use only"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("renderFooterTemplate(custom) = %q, want %q", got, want)
+	}
+
+	if got := renderFooterTemplate(footerNone, values); got != nil {
+		t.Errorf("renderFooterTemplate(none) = %q, want no lines", got)
+	}
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+// slackChannelTypes maps the channel_type of Slack message events to the
+// types recorded in slack_channels
+var slackChannelTypes = map[string]string{
+	"channel": repository.ChannelTypePublic,
+	"group":   repository.ChannelTypePrivate,
+	"im":      repository.ChannelTypeDM,
+	"mpim":    repository.ChannelTypeGroupDM,
+}
+
+// isDirectMessage reports whether a channel type is a DM or group DM
+func isDirectMessage(channelType string) bool {
+	return channelType == repository.ChannelTypeDM || channelType == repository.ChannelTypeGroupDM
+}
+
+// resolveChannelType returns a channel's type from a message event's
+// channel_type, falling back to the channel ID and conversations.info. It
+// returns "" if the type can't be determined.
+func (s *Service) resolveChannelType(channelID, eventChannelType string) string {
+	if channelType, ok := slackChannelTypes[eventChannelType]; ok {
+		return channelType
+	}
+	if strings.HasPrefix(channelID, "D") {
+		return repository.ChannelTypeDM
+	}
+
+	info, err := s.getChannelInfo(channelID)
+	if err != nil {
+		s.logger.Debug("Failed to resolve channel type",
+			zap.String("channel_id", channelID),
+			zap.Error(err))
+		return ""
+	}
+	if info.IsPrivate {
+		return repository.ChannelTypePrivate
+	}
+	return repository.ChannelTypePublic
+}
+
+// recordChannelType returns a channel's type, storing it the first time this
+// process sees the channel so new channels get their type's defaults
+func (s *Service) recordChannelType(channelID, eventChannelType string) string {
+	if channelType, ok := s.channelTypes.Load(channelID); ok {
+		return channelType.(string)
+	}
+
+	channelType := s.resolveChannelType(channelID, eventChannelType)
+	if channelType == "" {
+		return ""
+	}
+
+	if manager, ok := s.sessionManager.(session.ChannelTypeManager); ok {
+		if err := manager.RecordChannelType(channelID, channelType); err != nil {
+			s.logger.Warn("Failed to record channel type",
+				zap.String("channel_id", channelID),
+				zap.String("channel_type", channelType),
+				zap.Error(err))
+			return channelType
+		}
+	}
+	s.channelTypes.Store(channelID, channelType)
+	return channelType
+}
+
+// listSessionsForChannel returns sessions that may be listed in a channel:
+// its own plus those from public channels, so private channel and DM
+// sessions don't show up elsewhere. channelType optionally narrows the list.
+func (s *Service) listSessionsForChannel(channelID, channelType string, limit int) ([]session.SessionInfo, error) {
+	if manager, ok := s.sessionManager.(session.ChannelTypeManager); ok {
+		return manager.ListSessionsForChannel(channelID, channelType, limit)
+	}
+	return s.sessionManager.ListAllSessions(limit)
+}
+
+// sessionVisibleIn reports whether a session may be shown in a channel, by
+// the same rule as listSessionsForChannel
+func (s *Service) sessionVisibleIn(channelID, sessionID string) (bool, error) {
+	if checker, ok := s.sessionManager.(session.SessionVisibilityChecker); ok {
+		return checker.SessionVisibleInChannel(sessionID, channelID)
+	}
+	return true, nil
+}
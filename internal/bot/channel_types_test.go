@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func TestResolveChannelType(t *testing.T) {
+	s := &Service{}
+	tests := []struct {
+		channelID, eventChannelType, want string
+	}{
+		{"C123", "channel", repository.ChannelTypePublic},
+		{"C123", "group", repository.ChannelTypePrivate},
+		{"D123", "im", repository.ChannelTypeDM},
+		{"G123", "mpim", repository.ChannelTypeGroupDM},
+		{"D456", "", repository.ChannelTypeDM},
+	}
+
+	for _, tt := range tests {
+		if got := s.resolveChannelType(tt.channelID, tt.eventChannelType); got != tt.want {
+			t.Errorf("resolveChannelType(%q, %q) = %q, want %q", tt.channelID, tt.eventChannelType, got, tt.want)
+		}
+	}
+}
+
+func TestMentionRequired(t *testing.T) {
+	s := &Service{config: &config.Config{RequireMention: true}}
+	for channelType, want := range map[string]bool{
+		repository.ChannelTypePublic:  true,
+		repository.ChannelTypePrivate: true,
+		"":                            true,
+		repository.ChannelTypeDM:      false,
+		repository.ChannelTypeGroupDM: false,
+	} {
+		if got := s.mentionRequired(channelType, &repository.ChannelSettings{}); got != want {
+			t.Errorf("mentionRequired(%q) = %v, want %v", channelType, got, want)
+		}
+	}
+
+	// A channel's auto_respond setting wins, even in DMs
+	on, off := true, false
+	if s.mentionRequired(repository.ChannelTypePublic, &repository.ChannelSettings{AutoRespond: &on}) {
+		t.Error("Expected no mention requirement when the channel auto-responds")
+	}
+	if !s.mentionRequired(repository.ChannelTypeDM, &repository.ChannelSettings{AutoRespond: &off}) {
+		t.Error("Expected a mention requirement when the channel turned auto-respond off")
+	}
+
+	s.config.RequireMention = false
+	if s.mentionRequired(repository.ChannelTypePublic, &repository.ChannelSettings{}) {
+		t.Error("Expected no mention requirement when REQUIRE_MENTION is off")
+	}
+}
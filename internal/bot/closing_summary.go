@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+// closingRecap is a session's conversation captured before it is closed or
+// deleted, since a deleted session can no longer be looked up
+type closingRecap struct {
+	SessionID string
+	ChannelID string // Where the recap is posted
+	Exchanges int
+	Text      string
+}
+
+// prepareClosingRecap captures a session's conversation for a closing recap.
+// It returns nil when SUMMARIZE_ON_CLOSE is off or there is nothing to
+// summarize.
+func (s *Service) prepareClosingRecap(userID, channelID, sessionID string) *closingRecap {
+	if !s.config.SummarizeOnClose {
+		return nil
+	}
+
+	// An unknown session is reported by the close or delete itself
+	children, err := s.sessionManager.GetConversationTree(sessionID)
+	if err != nil || len(children) == 0 {
+		return nil
+	}
+
+	text, err := s.formatConversationForSummary(userID, sessionID, children)
+	if err != nil {
+		s.logger.Warn("Failed to format conversation for closing recap",
+			zap.String("session_id", sessionID),
+			zap.Error(err))
+		return nil
+	}
+
+	return &closingRecap{SessionID: sessionID, ChannelID: channelID, Exchanges: len(children), Text: text}
+}
+
+// postClosingRecap summarizes a captured conversation, stores the summary on
+// the root session, and posts it to the channel. It is meant to run in the
+// background; failures are logged.
+func (s *Service) postClosingRecap(userID string, recap *closingRecap, reason string) {
+	summary, err := s.claudeExecutor.ExecuteClaudeSummary(context.Background(), recap.Text)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(recap.ChannelID, userID, "closing_recap", "claude_summarization")
+		errCtx.WithSession(recap.SessionID)
+		s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to summarize closed session")
+		return
+	}
+
+	if summaryMgr, ok := s.sessionManager.(session.SessionSummaryManager); ok {
+		if err := summaryMgr.SetSessionSummary(recap.SessionID, summary); err != nil {
+			s.logger.Warn("Failed to store closing summary",
+				zap.String("session_id", recap.SessionID),
+				zap.Error(err))
+		}
+	}
+
+	message := fmt.Sprintf("📕 **Closing Recap**\n\n*Session:* `%s` (%s)\n*Messages:* %d conversations\n\n%s",
+		recap.SessionID, reason, recap.Exchanges, s.formatSummaryForSlack(summary))
+	if _, err := s.sender.PostMessage(context.Background(), recap.ChannelID, slack.MsgOptionText(message, false)); err != nil {
+		errCtx := logging.CreateErrorContext(recap.ChannelID, userID, "closing_recap", "post_message")
+		s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to post closing recap")
+		return
+	}
+
+	s.logger.Info("Posted closing recap",
+		zap.String("channel_id", recap.ChannelID),
+		zap.String("session_id", recap.SessionID),
+		zap.Int("exchanges", recap.Exchanges))
+}
+
+// closingRecapNote tells the user a recap is on its way
+func closingRecapNote(recaps int) string {
+	if recaps == 0 {
+		return ""
+	}
+	return "\n\n📕 _A closing recap of the conversation will be posted here shortly._"
+}
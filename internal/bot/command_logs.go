@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+)
+
+// commandLogRuns returns the Bash runs worth attaching: the run must be mostly
+// shell commands and their combined output must reach minBytes
+func commandLogRuns(toolRuns []claude.ToolRun, minBytes int) []claude.ToolRun {
+	var bashRuns []claude.ToolRun
+	outputBytes := 0
+	for _, run := range toolRuns {
+		if run.Name == "Bash" {
+			bashRuns = append(bashRuns, run)
+			outputBytes += len(run.Output)
+		}
+	}
+
+	if len(bashRuns) == 0 || len(bashRuns)*2 < len(toolRuns) || outputBytes < minBytes {
+		return nil
+	}
+	return bashRuns
+}
+
+// formatCommandLog renders each command with its full output
+func formatCommandLog(bashRuns []claude.ToolRun) string {
+	var log strings.Builder
+	for i, run := range bashRuns {
+		status := "ok"
+		if run.IsError {
+			status = "error"
+		}
+		log.WriteString(fmt.Sprintf("=== [%d/%d] $ %s (%s)\n", i+1, len(bashRuns), run.Command, status))
+		log.WriteString(run.Output)
+		if !strings.HasSuffix(run.Output, "\n") {
+			log.WriteString("\n")
+		}
+		log.WriteString("\n")
+	}
+	return log.String()
+}
+
+// attachCommandLogs uploads the full, untruncated command output as a text
+// file in the thread of the prompt
+func (s *Service) attachCommandLogs(channelID, threadTS, userID string, bashRuns []claude.ToolRun) {
+	content := s.config.ScrubSecrets(formatCommandLog(bashRuns))
+	filename := fmt.Sprintf("claude-commands-%s.log", time.Now().UTC().Format("20060102-150405"))
+
+	_, err := s.api().UploadFileV2(slack.UploadFileV2Parameters{
+		Content:         content,
+		FileSize:        len(content),
+		Filename:        filename,
+		Title:           fmt.Sprintf("Command output (%d commands)", len(bashRuns)),
+		Channel:         channelID,
+		ThreadTimestamp: threadTS,
+	})
+	if err != nil {
+		s.logger.Error("Failed to upload command logs",
+			zap.String("channel_id", channelID),
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return
+	}
+
+	s.logger.Info("Command logs attached",
+		zap.String("channel_id", channelID),
+		zap.String("thread_ts", threadTS),
+		zap.Int("commands", len(bashRuns)),
+		zap.Int("size", len(content)))
+}
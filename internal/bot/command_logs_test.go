@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+)
+
+func TestCommandLogRuns(t *testing.T) {
+	long := strings.Repeat("x", 100)
+
+	tests := []struct {
+		name     string
+		runs     []claude.ToolRun
+		minBytes int
+		want     int
+	}{
+		{
+			name:     "no tools",
+			minBytes: 10,
+		},
+		{
+			name:     "mostly bash with enough output",
+			runs:     []claude.ToolRun{{Name: "Bash", Output: long}, {Name: "Bash", Output: long}, {Name: "Read", Output: long}},
+			minBytes: 150,
+			want:     2,
+		},
+		{
+			name:     "output below threshold",
+			runs:     []claude.ToolRun{{Name: "Bash", Output: "ok"}},
+			minBytes: 150,
+		},
+		{
+			name:     "mostly other tools",
+			runs:     []claude.ToolRun{{Name: "Bash", Output: long + long}, {Name: "Edit"}, {Name: "Read"}},
+			minBytes: 150,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandLogRuns(tt.runs, tt.minBytes); len(got) != tt.want {
+				t.Errorf("commandLogRuns() returned %d runs, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,738 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+// Commands returns the command registry so other packages can register
+// extension commands alongside the built-ins
+func (s *Service) Commands() *commands.Registry {
+	return s.commands
+}
+
+// registerCommands registers built-in commands
+func (s *Service) registerCommands() {
+	s.commands.MustRegister(commands.Command{
+		Name:         "help",
+		Description:  "Show available commands, or details for one command",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableEverywhere,
+		Args:         []commands.Arg{{Name: "command", Description: "Command to show details for"}},
+		// Only "help <command>" is a command; "help me ..." goes to Claude
+		Validate: func(args []string) error {
+			if len(args) > 0 {
+				if _, exists := s.commands.Lookup(args[0]); !exists {
+					return fmt.Errorf("unknown command: %s", args[0])
+				}
+			}
+			return nil
+		},
+		Examples: []string{"help", "help session"},
+		Handler:  s.handleHelpCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "status",
+		Description:  "Show bot status",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableEverywhere,
+		Handler:      s.handleStatusCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "version",
+		Description:  "Show bot version",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableEverywhere,
+		Handler:      s.handleVersionCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "sessions",
+		Description:  "List your active sessions",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Handler:      s.handleSessionsCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "close",
+		Description:  "Close session in this channel",
+		Permission:   auth.PermissionWrite,
+		Availability: commands.AvailableSlash,
+		Handler:      s.handleCloseSessionCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "stats",
+		Description:  "Show statistics and usage trends",
+		Permission:   auth.PermissionAdmin,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "range", Description: "How far back to chart, like `24h`, `7d`, or `2w` (default `7d`, at most 90 days)"},
+		},
+		Details: "Charts run volume, cost, error rate, and average latency over the range as sparklines, " +
+			"read from the usage and failed-run tables so they survive restarts.",
+		Examples: []string{"stats", "stats 24h", "stats 30d"},
+		Handler:  s.handleStatsCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "session",
+		Description:  "Show, list, switch, or create Claude sessions",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "list|info|new|.|mode|dirs|adddir|rmdir|move|trash|restore|diff|stats|session-id", Description: "Subcommand, or a Claude session ID to switch to"},
+			{Name: "path", Description: "Working directory or git URL for `new`, working directory for `.`, parent session ID for `info` and `restore`, `shared|per-user` for `mode`, a directory for `adddir` and `rmdir`, a channel for `move`, `public|private|dm` for `list`, or two exchange session IDs for `diff`"},
+		},
+		Details: "Without arguments, shows the channel's current parent and leaf sessions. " +
+			"`new` without a path opens a directory picker, or uses your templated workspace when `WORKDIR_TEMPLATE` is set; `new <git URL>` clones the repository under `WORKSPACE_CLONE_ROOT` and starts a session in it (write permission); `.` switches to the latest session for a path, creating one if needed. " +
+			"`mode per-user` gives each user their own active session in the channel; changing the mode requires write permission. " +
+			"`adddir <path>` lets Claude also read and edit another directory in the session and `rmdir <path>` removes it (write permission); `dirs` lists them. " +
+			"`move #channel` hands the active session over to another channel you and the bot are in, where that channel's permission mode and settings apply, and posts a notice in both (write permission in both; archived channels are refused). " +
+			"`trash list` shows deleted sessions and `restore <session-id>` brings one back (write permission). " +
+			"`diff <a> <b>` compares two branches of the same conversation: where they diverged, the exchanges unique to each, and where each ended up. " +
+			"`stats` totals the active session: exchanges and branches, runs, cost, tokens, average run time, files touched, and when it was created. " +
+			"Listings show this channel's sessions and those from public channels; sessions from private channels and DMs only appear where they were started. " +
+			"`list public|private|dm` only shows sessions from that kind of conversation.",
+		Examples: []string{"session", "session list", "session list dm", "session new /home/dev/project", "session new git@github.com:org/repo.git", "session . /home/dev/project", "session mode per-user", "session move #incidents", "session trash list", "session diff <session-id-a> <session-id-b>", "session stats"},
+		Handler: func(ctx context.Context, req *commands.Request) (string, error) {
+			if len(req.Args) == 1 && req.Args[0] == "new" && req.TriggerID != "" && s.config.WorkdirTemplate == "" {
+				return s.openWorkdirPicker(ctx, req)
+			}
+			return s.handleSessionSlashCommand(req.UserID, req.ChannelID, req.Text), nil
+		},
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "permission",
+		Description:  "Show or set the channel permission mode",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "mode", Description: "`default`, `acceptEdits`, `bypassPermissions`, or `plan`"},
+			{Name: "duration", Description: "Revert to the channel default after this long, e.g. `30m` or `2h`"},
+		},
+		Details:  "Controls which tool actions Claude may take without asking. Without arguments, shows the current mode. Admins can use `default <mode>` to set the mode new channels start in.",
+		Examples: []string{"permission", "permission acceptEdits", "permission bypassPermissions 30m", "permission default plan"},
+		Handler: func(ctx context.Context, req *commands.Request) (string, error) {
+			return s.handlePermissionSlashCommand(req.UserID, req.ChannelID, req.Text), nil
+		},
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "summarize",
+		Description:  "Summarize the current conversation",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Handler: func(ctx context.Context, req *commands.Request) (string, error) {
+			return s.handleSummarizeSlashCommand(req.UserID, req.ChannelID), nil
+		},
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "debug",
+		Description:  "Show the latest raw Claude response or upload a debug bundle (admin only)",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args:         []commands.Arg{{Name: "bundle", Description: "Upload a bundle with logs and the raw response instead"}},
+		Examples:     []string{"debug", "debug bundle"},
+		Handler: func(ctx context.Context, req *commands.Request) (string, error) {
+			return s.handleDebugSlashCommand(req.UserID, req.ChannelID, req.Text), nil
+		},
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "search",
+		Description:  "Search conversations in channels you can access",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args:         []commands.Arg{{Name: "query", Required: true, Description: "Words to find; supports quoted phrases, `or`, and `-excluded` terms"}},
+		Variadic:     true,
+		Details:      "Admins search every channel; everyone else searches allowed channels they are a member of. Each result has a button to switch this channel to that session.",
+		Examples:     []string{"search flaky migration", "search \"connection refused\" -staging"},
+		Handler:      s.handleSearchCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "notify",
+		Description:  "DM me when my long runs finish",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "me|off", Description: "`me` turns completion DMs on, `off` turns them off"},
+			{Name: "next", Description: "With `me`, only notify for the next long run"},
+		},
+		Details:  fmt.Sprintf("Runs that take longer than %s send you a DM with a link to the reply. Without arguments, shows your current setting.", s.config.NotifyAfter),
+		Examples: []string{"notify", "notify me", "notify me next", "notify off"},
+		Handler:  s.handleNotifySlashCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "tag",
+		Description:  "Tag the latest exchange or list tagged exchanges",
+		Permission:   auth.PermissionWrite,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "label|list|remove", Required: true, Description: "Label to add to the latest exchange, or a subcommand"},
+			{Name: "label", Description: "Label to filter by (`list`) or remove (`remove`)"},
+		},
+		Variadic: true,
+		Examples: []string{"tag decision", "tag list", "tag list decision", "tag remove decision"},
+		Handler:  s.handleTagSlashCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "agents",
+		Description:  "List Claude sub-agents and choose which are enabled in this channel",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "list|use|all|none", Description: "Show agents, or choose which are enabled"},
+			{Name: "name", Description: "Agent names for `use`"},
+		},
+		Variadic: true,
+		Details:  "Agents are defined in `CLAUDE_AGENTS_FILE`. Listing is open to everyone; changing the selection requires write permission.",
+		Examples: []string{"agents", "agents use reviewer tester", "agents all", "agents none"},
+		Handler: func(ctx context.Context, req *commands.Request) (string, error) {
+			if len(req.Args) > 0 && req.Args[0] != "list" {
+				authCtx := &auth.AuthContext{UserID: req.UserID, ChannelID: req.ChannelID, Command: "/agents", Timestamp: time.Now()}
+				if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+					return fmt.Sprintf("❌ Authorization failed: %v", err), nil
+				}
+			}
+			return s.handleAgentsSlashCommand(ctx, req)
+		},
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "footer",
+		Description:  "Choose which parts of the run environment reply footers show in this channel",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "git|kube|venv|default|none", Description: "Fields to show, `default` for `FOOTER_ENV`, or `none`"},
+		},
+		Variadic: true,
+		Details: "`git` shows the working directory's branch and commit, `kube` the current kubectl context, and `venv` the Python virtualenv, " +
+			"as they were when the run started. Channel environment variables such as `KUBECONFIG` are taken into account. " +
+			"Showing the setting is open to everyone; changing it requires write permission.",
+		Examples: []string{"footer", "footer git kube", "footer default", "footer none"},
+		Handler: func(ctx context.Context, req *commands.Request) (string, error) {
+			if len(req.Args) > 0 {
+				authCtx := &auth.AuthContext{UserID: req.UserID, ChannelID: req.ChannelID, Command: "/footer", Timestamp: time.Now()}
+				if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+					return fmt.Sprintf("❌ Authorization failed: %v", err), nil
+				}
+			}
+			return s.handleFooterCommand(ctx, req)
+		},
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "context",
+		Description:  "Include the channel topic/purpose in Claude's prompts",
+		Permission:   auth.PermissionWrite,
+		Availability: commands.AvailableSlash,
+		Args:         []commands.Arg{{Name: "channel"}, {Name: "on|off"}},
+		Details:      "When on, the channel topic and purpose are added to Claude's system prompt. Without arguments, shows the current setting.",
+		Examples:     []string{"context", "context channel on"},
+		Handler:      s.handleContextSlashCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "batch",
+		Description:  "Run the same prompt in several working directories",
+		Permission:   auth.PermissionExecute,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "prompt", Required: true, Description: "What Claude should do in each directory"},
+			{Name: "--paths dirs", Required: true, Description: "Comma-separated absolute working directories"},
+		},
+		Variadic: true,
+		Validate: func(args []string) error {
+			_, _, err := parseBatchArgs(strings.Join(args, " "))
+			return err
+		},
+		Details: fmt.Sprintf("Each path gets its own fresh Claude conversation, with up to %d running at once. "+
+			"A tracker message shows progress; each result is posted in its thread, followed by a summary. "+
+			"Runs use the channel's permission mode and count toward its budget.", s.config.BatchConcurrency),
+		Examples: []string{"batch bump lodash to 4.17.21 and run the tests --paths /srv/api,/srv/web,/srv/worker"},
+		Handler:  s.handleBatchCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "ask",
+		Description:  "Ask a quick side question without touching the channel's session",
+		Permission:   auth.PermissionExecute,
+		Availability: commands.AvailableSlash,
+		Args:         []commands.Arg{{Name: "prompt", Required: true, Description: "The question for Claude"}},
+		Variadic:     true,
+		Details: "Runs in a fresh, throwaway Claude conversation in the session's working directory, and only you see the answer. " +
+			"The channel's session and conversation are left as they are, so it's safe to use during a long task. " +
+			"It uses `default` mode if the channel is in it, and `plan` otherwise, so it never edits files. The cost counts toward the channel's budget.",
+		Examples: []string{"ask what does the --frozen-lockfile flag do?"},
+		Handler:  s.handleAskCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "later",
+		Description:  "Schedule a prompt to run in this channel at a future time",
+		Permission:   auth.PermissionExecute,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "delay|list|cancel", Required: true, Description: "How long to wait, like `30m`, `2h`, or `1d` (up to 7 days)"},
+			{Name: "prompt", Description: "What Claude should do when the delay is up, or the ID for `cancel`"},
+		},
+		Variadic: true,
+		Details: "Scheduled prompts are stored in the database, so they survive restarts. When one is due it runs as you in the channel's " +
+			"current session, and the result is posted in a thread under a ⏰ message. Anyone in the channel can `list` or `cancel` them.",
+		Examples: []string{"later 2h check whether the deploy finished and report", "later list", "later cancel 4"},
+		Handler:  s.handleLaterCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "template",
+		Description:  "Save frequently used prompts with {variables} and run them",
+		Permission:   auth.PermissionExecute,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "list|save|show|run|delete", Required: true},
+			{Name: "name", Description: "Template name; `save` and `delete` take `channel` first for a template shared with the channel"},
+			{Name: "prompt|var=value", Description: "The prompt for `save`, or values for its variables for `run`; quote values with spaces"},
+		},
+		Variadic: true,
+		Details: "Templates are personal unless saved with `channel`, which requires write permission. When you run a template, yours takes " +
+			"precedence over a channel template with the same name. The filled-in prompt is posted as a new thread and Claude replies in it.",
+		Examples: []string{
+			`template save deploy-check "Check deployment status of {service} in {env}"`,
+			"template run deploy-check service=api env=prod",
+			"template save channel triage Triage the latest errors in {service}",
+			"template list",
+		},
+		Handler: s.handleTemplateCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "remember",
+		Description:  "Remember a fact for every run in this channel",
+		Permission:   auth.PermissionWrite,
+		Availability: commands.AvailableSlash,
+		Args:         []commands.Arg{{Name: "fact", Required: true, Description: "A sentence or two, optionally quoted"}},
+		Variadic:     true,
+		Details: "Facts are added to the system prompt of every run in the channel as channel facts, so they outlive sessions and compaction. " +
+			"Manage them with `/memory`.",
+		Examples: []string{`remember "deploys run via make deploy"`, "remember staging is at staging.example.com"},
+		Handler:  s.handleRememberCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "memory",
+		Description:  "List, edit, or forget the facts this channel remembers",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "list|edit|forget", Description: "Defaults to `list`"},
+			{Name: "id", Description: "Fact ID from `/memory list`, for `edit` and `forget`"},
+			{Name: "fact", Description: "The new wording, for `edit`"},
+		},
+		Variadic: true,
+		Details:  "Anyone who can read the channel can list its facts; editing and forgetting them requires write permission.",
+		Examples: []string{"memory list", `memory edit 3 "deploys run via make deploy-prod"`, "memory forget 3"},
+		Handler:  s.handleMemoryCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "mirror",
+		Description:  "Cross-post Claude's responses here to another channel",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "#channel|responses|prompts|off", Description: "Channel to mirror to, a direction to toggle, or `off`; shows the mirror when left out"},
+			{Name: "on|off|confirm", Description: "For `responses` and `prompts`, or `confirm` after a channel to mirror a DM or private channel to a more visible one"},
+		},
+		Details: "Responses are mirrored by default and prompts only once turned on; a prompt's response follows it in a thread. " +
+			"Mirrored posts are never mirrored again. Changes require admin; the bot must be in the mirror channel. " +
+			"The same settings are `mirror_channel`, `mirror_responses`, and `mirror_prompts` in `/settings`.",
+		Examples: []string{"mirror #audit", "mirror prompts on", "mirror responses off", "mirror off"},
+		Handler:  s.handleMirrorCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "stop",
+		Description:  "Force-stop current processing",
+		Permission:   auth.PermissionAdmin,
+		Availability: commands.AvailableSlash,
+		Handler:      s.handleStopCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "env",
+		Description:  "Manage environment variables for Claude runs in this channel",
+		Permission:   auth.PermissionAdmin,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "list|set|unset", Required: true},
+			{Name: "name", Description: "Variable name, e.g. `KUBECONFIG`; `set` also accepts `NAME=value`"},
+			{Name: "value", Description: "Value for `set`; may contain spaces"},
+		},
+		Variadic: true,
+		Details: "Variables are stored encrypted with `ENV_ENCRYPTION_KEY` and added to the environment of every Claude run in the channel. " +
+			"Values are never shown back. Only available as a slash command so values don't end up in channel history.",
+		Examples: []string{"env list", "env set AWS_PROFILE staging", "env set KUBECONFIG=/etc/kube/staging.yaml", "env unset AWS_PROFILE"},
+		Handler:  s.handleEnvCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "guardrails",
+		Description:  "Show or set mandatory constraints for Claude runs in this channel",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "show|set|clear", Required: true},
+			{Name: "constraints", Description: "For `set`, the rules Claude must follow, e.g. `never run destructive commands, never touch .env, always ask before kubectl apply`"},
+		},
+		Variadic: true,
+		Details: "Guardrails are appended to the end of the system prompt of every Claude run in the channel, including batch runs, " +
+			"and are framed so that nothing in a user's prompt can lift them. Anyone can `show` them; `set` and `clear` are admin only. " +
+			"If they can't be loaded, Claude isn't run.",
+		Examples: []string{"guardrails show", "guardrails set Never run destructive commands. Never read or edit .env files. Always ask before kubectl apply.", "guardrails clear"},
+		Handler:  s.handleGuardrailsCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "emoji",
+		Description:  "Map reaction emoji to quick prompts",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "list|set|remove", Required: true},
+			{Name: "emoji", Description: "Reaction to map, like `:explain:`, for `set` and `remove`"},
+			{Name: "template", Description: "For `set`, the prompt to run; `{message}`, `{author}`, and `{user}` are filled in"},
+		},
+		Variadic: true,
+		Details: "Reacting with a mapped emoji on a message runs its prompt as you, with the message as context, in the channel's active session; " +
+			"the answer is posted in the message's thread. Templates without `{message}` get the message appended. " +
+			"Anyone can `list` mappings; `set` and `remove` are admin only. Running a prompt requires execute permission.",
+		Examples: []string{"emoji list", "emoji set :explain: Explain this message in plain terms", "emoji set :translate-jp: Translate {message} into Japanese", "emoji remove :explain:"},
+		Handler:  s.handleEmojiCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "failed",
+		Description:  "List or replay failed Claude runs",
+		Permission:   auth.PermissionAdmin,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "list|retry", Required: true, Description: "Show recent failures, or replay one"},
+			{Name: "id", Description: "Failed run ID for `retry`"},
+		},
+		Details: "Failed runs keep their prompt, session, error kind, and stderr. " +
+			"`retry` replays the prompt as its original author in the channel and thread where it failed.",
+		Examples: []string{"failed list", "failed retry 12"},
+		Handler:  s.handleFailedCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "flags",
+		Description:  "Turn risky features on per channel or for a share of channels",
+		Permission:   auth.PermissionAdmin,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "list|set|channel|delete", Description: "Defaults to `list`"},
+			{Name: "name", Description: "Flag name, e.g. `streaming`"},
+			{Name: "value", Description: "`on`, `off`, or a percentage like `25%` for `set`; `on`, `off`, or `reset` for `channel`"},
+		},
+		Details: "`set` rolls a flag out to a share of channels, picked by a stable hash so channels stay on as the rollout grows. " +
+			"`channel` overrides the rollout in the current channel. Unknown flags are off. " +
+			"Changes apply immediately on this instance and within `FEATURE_FLAG_CACHE_TTL` on others.",
+		Examples: []string{"flags", "flags set streaming 25%", "flags channel streaming on", "flags delete streaming"},
+		Handler:  s.handleFlagsCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "identity",
+		Description:  "Map Slack users to system identities for attribution",
+		Permission:   auth.PermissionAdmin,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "list|set|unset", Description: "Defaults to `list`"},
+			{Name: "user", Description: "User mention, or a Slack user ID"},
+			{Name: "identity", Description: "For `set`, an LDAP or Unix username or an email"},
+		},
+		Details: "Mapped identities are recorded as new sessions' system user, on each run's cost in `/stats`, and in authorization audit logs, instead of raw Slack user IDs. " +
+			"Earlier records keep the identity they were made with. " +
+			"Other instances pick up changes when they restart or an admin runs `/identity list` on them.",
+		Examples: []string{"identity", "identity set @jane jdoe", "identity set @sam sam@example.com", "identity unset @jane"},
+		Handler:  s.handleIdentityCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "demo",
+		Description:  "Record a channel's exchanges as a demo, or replay one",
+		Permission:   auth.PermissionWrite,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "record|stop|list|play|delete", Required: true},
+			{Name: "name", Description: "Demo name for `record`, `play`, and `delete`"},
+		},
+		Details: "`record` saves every prompt and response in the channel, anonymized, until `stop`. " +
+			"`play` replays a demo's stored responses in the current channel with realistic pauses, without running Claude; useful for onboarding. " +
+			"Only the demo's recorder or an admin can `delete` it.",
+		Examples: []string{"demo record fix-a-bug", "demo stop", "demo list", "demo play fix-a-bug"},
+		Handler:  s.handleDemoCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "prefs",
+		Description:  "Set how Claude replies: verbosity, language, and comment style",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "show|set|unset", Description: "Show preferences (default), or change one"},
+			{Name: "channel", Description: "Change the channel default instead of your own (requires write permission)"},
+			{Name: "name", Description: "`verbosity` (`concise`, `detailed`), `language` (e.g. `Spanish`), or `comments` (`none`, `minimal`, `thorough`)"},
+			{Name: "value"},
+		},
+		Variadic: true,
+		Details: "Preferences are added to Claude's instructions for every run. " +
+			"Your own preferences take precedence over the channel's defaults.",
+		Examples: []string{"prefs show", "prefs set verbosity concise", "prefs set language Spanish", "prefs set channel comments minimal", "prefs unset language"},
+		Handler:  s.handlePrefsCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "settings",
+		Description:  "Show or change how the bot behaves in this channel",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "show|set|unset|type", Description: "Show settings (default), change one, or show and change the settings of channel types"},
+			{Name: "name", Description: "`auto_respond`, `model`, `priority`, `permission`, `reply_in_thread`, `footer_template`, `footer_env`, `agents`, `tool_events`, `allowed_tools`, or `disallowed_tools`"},
+			{Name: "value"},
+		},
+		Variadic: true,
+		Details: "Settings that aren't set follow the bot-wide configuration. " +
+			"Changing `allowed_tools`, `disallowed_tools`, or a channel type's settings requires admin; the rest require write permission. " +
+			"Footer templates take `{mode}`, `{session}`, `{short_session}`, `{workdir}`, `{messages}`, `{env}`, `{agents}`, and `{command_logs}`, with `\\n` between lines.",
+		Examples: []string{"settings", "settings set auto_respond on", "settings set model opus", "settings set reply_in_thread on", "settings set allowed_tools Read,Grep,Bash(git:*)", "settings set footer_template • _{mode} · {short_session}_", "settings type private set permission plan", "settings unset model"},
+		Handler:  s.handleSettingsCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "workspace",
+		Description:  "Summarize the session's working directory",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args: []commands.Arg{
+			{Name: "info|audit|purge", Required: true},
+			{Name: "confirm", Description: "Delete the directories `purge` lists"},
+		},
+		Details: "Scans the working directory without running Claude and reports its size, language breakdown, git branch and remotes, " +
+			"and the most recently modified files, so you can check you're in the right place before starting a run. " +
+			"Dependency and build directories such as `node_modules` and `vendor` are not counted. " +
+			"Admins can `audit` the workspaces under `WORKSPACE_AUDIT_ROOTS` for directories no session uses, and `purge` them.",
+		Examples: []string{"workspace info", "workspace audit", "workspace purge confirm"},
+		Handler:  s.handleWorkspaceCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "merge",
+		Description:  "Land the session's worktree changes in the main checkout",
+		Permission:   auth.PermissionWrite,
+		Availability: commands.AvailableSlash,
+		Details: "With `SESSION_WORKTREES=true`, each new session in a git repository works on its own `claude/session-<id>` branch in a separate worktree. " +
+			"`merge` commits any uncommitted changes there, merges the branch into the main checkout, removes the worktree and branch, and starts a fresh session. " +
+			"A merge that conflicts is aborted and the branch is kept.",
+		Examples: []string{"merge"},
+		Handler:  s.handleMergeCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "discard",
+		Description:  "Drop the session's worktree and branch",
+		Permission:   auth.PermissionWrite,
+		Availability: commands.AvailableSlash,
+		Args:         []commands.Arg{{Name: "confirm", Description: "Drop the changes; without it, shows what would be dropped"}},
+		Details:      "Removes the session's worktree and deletes its `claude/session-<id>` branch without touching the main checkout, then starts a fresh session.",
+		Examples:     []string{"discard", "discard confirm"},
+		Handler:      s.handleDiscardCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "priority",
+		Description:  "Show or set how this channel's runs are scheduled",
+		Permission:   auth.PermissionRead,
+		Availability: commands.AvailableSlash,
+		Args:         []commands.Arg{{Name: "urgent|normal|batch|reset", Description: "New priority, or `reset` to go back to the configured default"}},
+		Details: "When all `MAX_CONCURRENT_RUNS` slots are busy, waiting runs start in priority order. " +
+			"An urgent run preempts a batch-priority run, which is canceled and requeued. " +
+			"`/batch` runs always use batch priority. Changing the priority requires write permission.",
+		Examples: []string{"priority", "priority urgent", "priority reset"},
+		Handler:  s.handlePriorityCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "whoami",
+		Description:  "Show your effective permissions and this channel's settings",
+		Permission:   auth.PermissionNone,
+		Availability: commands.AvailableSlash,
+		Details: "Shows your role, permission level in this channel, admin status, rate limit capacity, quotas, and allowed channels, " +
+			"and the permission mode, model, and tools a run here would use. " +
+			"Anyone can run it, including users who are refused everything else, to see why.",
+		Handler: s.handleWhoamiCommand,
+	})
+	s.commands.MustRegister(commands.Command{
+		Name:         "delete",
+		Description:  "Delete a session and its conversation history",
+		Permission:   auth.PermissionWrite,
+		Availability: commands.AvailableSlash,
+		Args:         []commands.Arg{{Name: "session-id", Required: true}},
+		Details:      "Moves the session and its recorded exchanges to the trash. Restore it with `/session restore` until SESSION_TRASH_RETENTION passes, after which it is purged for good.",
+		Handler: func(ctx context.Context, req *commands.Request) (string, error) {
+			return s.handleDeleteSessionCommand(req.UserID, req.ChannelID, req.Text), nil
+		},
+	})
+}
+
+// dispatchCommand authorizes and runs a registered command
+func (s *Service) dispatchCommand(ctx context.Context, req *commands.Request) string {
+	if !s.readiness.Ready() {
+		return warmingUpMessage
+	}
+
+	cmd, exists := s.commands.Lookup(req.Command)
+	if !exists || !cmd.AvailableFrom(req.Source) {
+		return fmt.Sprintf("❌ Unknown command: `%s`. Type `help` for available commands.", req.Command)
+	}
+
+	s.logger.Info("Processing command",
+		zap.String("command", cmd.Name),
+		zap.Strings("args", req.Args),
+		zap.String("user_id", req.UserID),
+		zap.String("user_name", s.authService.DisplayName(req.UserID)),
+		zap.String("channel_id", req.ChannelID))
+
+	authCtx := &auth.AuthContext{
+		UserID:    req.UserID,
+		ChannelID: req.ChannelID,
+		Command:   "/" + cmd.Name,
+		Timestamp: time.Now(),
+	}
+	// Commands that need no permission, such as whoami, explain refusals and
+	// must answer the users being refused, but not banned ones
+	if cmd.Permission > auth.PermissionNone {
+		if err := s.authService.AuthorizeUser(authCtx, cmd.Permission); err != nil {
+			return fmt.Sprintf("❌ Authorization failed: %v", err)
+		}
+	} else if s.authService.IsBanned(req.UserID) {
+		return fmt.Sprintf("❌ Authorization failed: user %s is banned", req.UserID)
+	}
+
+	if err := cmd.ValidateArgs(req.Args); err != nil {
+		return fmt.Sprintf("❌ %v\n\n**Usage:** `%s`", err, cmd.Usage(req.Source))
+	}
+
+	response, err := cmd.Handler(ctx, req)
+	if err != nil {
+		s.logger.Error("Command execution failed", zap.String("command", cmd.Name), zap.Error(err))
+		if response != "" {
+			return response
+		}
+		return fmt.Sprintf("❌ Command failed: %v", err)
+	}
+
+	return response
+}
+
+// matchMessageCommand returns a command request if text is a bare bot command
+// rather than a natural language prompt
+func (s *Service) matchMessageCommand(userID, channelID, text string) (*commands.Request, bool) {
+	parts := strings.Fields(text)
+	if len(parts) == 0 {
+		return nil, false
+	}
+
+	cmd, exists := s.commands.Lookup(parts[0])
+	if !exists || !cmd.AvailableFrom(commands.SourceMessage) {
+		return nil, false
+	}
+
+	// Only treat the message as a command when it fits the argument schema,
+	// so "help me debug this" still goes to Claude
+	args := parts[1:]
+	if cmd.ValidateArgs(args) != nil {
+		return nil, false
+	}
+
+	return commands.NewRequest(cmd.Name, userID, channelID, strings.Join(args, " "), commands.SourceMessage), true
+}
+
+// Command handlers
+func (s *Service) handleStatusCommand(ctx context.Context, req *commands.Request) (string, error) {
+	uptime := time.Since(s.startTime).Truncate(time.Second)
+	sessionStats := s.sessionManager.GetSessionStats()
+	authStats := s.authService.GetStats()
+
+	return fmt.Sprintf(`📊 *Bot Status*
+
+🟢 Status: Running
+⏰ Uptime: %v
+👥 Total Users: %v
+🎯 Active Sessions: %v
+📝 Total Messages: %v
+🚦 Rate Limit: %d/min, bursts of %d%s
+
+Use `+"`sessions`"+` to see your active sessions.`,
+		uptime,
+		authStats["total_users"],
+		sessionStats["active_sessions"],
+		sessionStats["total_messages"],
+		s.config.RateLimitPerMinute,
+		s.config.RateLimitBurst,
+		s.outboxStatus()), nil
+}
+
+func (s *Service) handleSessionsCommand(ctx context.Context, req *commands.Request) (string, error) {
+	return s.sessionManager.ListUserSessions(req.UserID), nil
+}
+
+func (s *Service) handleCloseSessionCommand(ctx context.Context, req *commands.Request) (string, error) {
+	var channelSessions []string
+	closer, storesActive := s.sessionManager.(session.ActiveSessionCloser)
+	if storesActive {
+		// The channel's active session is kept in storage; look it up without
+		// creating one
+		sessionID, err := closer.ActiveSessionID(req.ChannelID, req.UserID)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up the active session: %w", err)
+		}
+		if sessionID != "" {
+			channelSessions = []string{sessionID}
+		}
+	} else {
+		for _, userSession := range s.sessionManager.GetActiveSessionsForUser(req.UserID) {
+			if userSession.GetChannelID() == req.ChannelID {
+				channelSessions = append(channelSessions, userSession.GetID())
+			}
+		}
+	}
+	if len(channelSessions) == 0 {
+		return "No active sessions to close.", nil
+	}
+
+	// Close all sessions for the user in this channel
+	closed, recaps := 0, 0
+	for _, sessionID := range channelSessions {
+		recap := s.prepareClosingRecap(req.UserID, req.ChannelID, sessionID)
+		var err error
+		if storesActive {
+			err = closer.CloseActiveSession(req.ChannelID, req.UserID)
+		} else {
+			err = s.sessionManager.CloseSession(sessionID)
+		}
+		if err != nil {
+			s.logger.Error("Failed to close session", zap.Error(err))
+			continue
+		}
+		closed++
+		if recap != nil {
+			recaps++
+			go s.postClosingRecap(req.UserID, recap, "closed")
+		}
+	}
+
+	if closed == 0 {
+		return "No active sessions found in this channel.", nil
+	}
+
+	return fmt.Sprintf("✅ Closed %d session(s) in this channel.", closed) + closingRecapNote(recaps), nil
+}
+
+func (s *Service) handleVersionCommand(ctx context.Context, req *commands.Request) (string, error) {
+	return fmt.Sprintf(`🤖 *%s*
+
+Version: 1.0.0
+Claude Model: %s
+Working Directory: %s
+Command Prefix: %s
+
+Built with ❤️ for Slack`,
+		s.config.BotDisplayName,
+		"claude-code-cli", // Using Claude Code CLI instead of specific model
+		s.config.WorkingDirectory,
+		s.config.CommandPrefix), nil
+}
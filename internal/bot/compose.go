@@ -0,0 +1,272 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+const (
+	// composeCallbackID identifies both the "New Claude request" global
+	// shortcut configured in the Slack app and the modal it opens
+	composeCallbackID = "new_claude_request"
+
+	composeBlockChannel = "compose_channel"
+	composeBlockPrompt  = "compose_prompt"
+	composeBlockWorkdir = "compose_workdir"
+	composeBlockModel   = "compose_model"
+	composeBlockMode    = "compose_mode"
+	composeActionID     = "value"
+
+	// composePromptMaxLength is Slack's limit for plain text inputs
+	composePromptMaxLength = 3000
+)
+
+// runOverrides are per-request choices that take precedence over the
+// channel's settings for one run
+type runOverrides struct {
+	Model          string
+	PermissionMode config.PermissionMode
+	AllowDuplicate bool // The user confirmed the prompt isn't an accidental repeat
+}
+
+// composeRequest is a submitted "New Claude request" modal
+type composeRequest struct {
+	ChannelID string
+	Prompt    string
+	WorkDir   string // Empty keeps the channel's current session
+	Overrides runOverrides
+}
+
+// handleShortcut handles global shortcuts
+func (s *Service) handleShortcut(callback *slack.InteractionCallback) {
+	s.logger.Debug("Shortcut",
+		zap.String("callback_id", callback.CallbackID),
+		zap.String("user_id", callback.User.ID))
+
+	switch callback.CallbackID {
+	case composeCallbackID:
+		if _, err := s.api().OpenView(callback.TriggerID, s.buildComposeView()); err != nil {
+			s.logger.Error("Failed to open request composer", zap.Error(err))
+		}
+	default:
+		s.logger.Debug("Unhandled shortcut", zap.String("callback_id", callback.CallbackID))
+	}
+}
+
+// buildComposeView renders the modal for composing a long prompt with its
+// channel, working directory, model, and permission mode
+func (s *Service) buildComposeView() slack.ModalViewRequest {
+	plain := func(text string) *slack.TextBlockObject {
+		return slack.NewTextBlockObject(slack.PlainTextType, text, false, false)
+	}
+
+	channel := slack.NewOptionsSelectBlockElement(slack.OptTypeConversations, plain("Where to reply"), composeActionID)
+	channel.DefaultToCurrentConversation = true
+	channel.Filter = &slack.SelectBlockElementFilter{Include: []string{"public", "private", "im", "mpim"}, ExcludeBotUsers: true}
+
+	prompt := slack.NewPlainTextInputBlockElement(plain("Describe the task; paste code and logs as-is"), composeActionID)
+	prompt.Multiline = true
+	prompt.MaxLength = composePromptMaxLength
+
+	blocks := []slack.Block{
+		slack.NewInputBlock(composeBlockChannel, plain("Channel"), nil, channel),
+		slack.NewInputBlock(composeBlockPrompt, plain("Prompt"), nil, prompt),
+	}
+
+	var pathGroups []*slack.OptionGroupBlockObject
+	if options := pathOptions(s.workdirRoots()); len(options) > 0 {
+		pathGroups = append(pathGroups, slack.NewOptionGroupBlockElement(plain("Allowed roots"), options...))
+	}
+	knownPaths, err := s.sessionManager.GetKnownPaths(50)
+	if err != nil {
+		s.logger.Error("Failed to get known paths", zap.Error(err))
+	}
+	if options := pathOptions(knownPaths); len(options) > 0 {
+		pathGroups = append(pathGroups, slack.NewOptionGroupBlockElement(plain("Recent paths"), options...))
+	}
+	if len(pathGroups) > 0 {
+		workdir := slack.NewInputBlock(composeBlockWorkdir, plain("Working directory"),
+			plain("Leave empty to continue the channel's current session"),
+			slack.NewOptionsGroupSelectBlockElement(slack.OptTypeStatic, plain("Channel's current session"), composeActionID, pathGroups...))
+		workdir.Optional = true
+		blocks = append(blocks, workdir)
+	}
+
+	var modelOptions []*slack.OptionBlockObject
+	for _, model := range s.config.ClaudeModels {
+		modelOptions = append(modelOptions, slack.NewOptionBlockObject(model, plain(model), nil))
+	}
+	if len(modelOptions) > 0 {
+		model := slack.NewInputBlock(composeBlockModel, plain("Model"), nil,
+			slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, plain(fmt.Sprintf("Default (%s)", s.config.ClaudeModel)), composeActionID, modelOptions...))
+		model.Optional = true
+		blocks = append(blocks, model)
+	}
+
+	var modeOptions []*slack.OptionBlockObject
+	for _, caps := range config.PermissionCapabilities {
+		modeOptions = append(modeOptions, slack.NewOptionBlockObject(string(caps.Mode), plain(string(caps.Mode)), plain(truncateOptionText(caps.Summary()))))
+	}
+	mode := slack.NewInputBlock(composeBlockMode, plain("Permission mode"),
+		plain("Applies to this request only"),
+		slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, plain("Channel's mode"), composeActionID, modeOptions...))
+	mode.Optional = true
+	blocks = append(blocks, mode)
+
+	return slack.ModalViewRequest{
+		Type:       slack.VTModal,
+		CallbackID: composeCallbackID,
+		Title:      plain("New Claude Request"),
+		Submit:     plain("Send"),
+		Close:      plain("Cancel"),
+		Blocks:     slack.Blocks{BlockSet: blocks},
+	}
+}
+
+// parseComposeSubmission reads the modal's inputs
+func parseComposeSubmission(values map[string]map[string]slack.BlockAction) composeRequest {
+	input := func(blockID string) slack.BlockAction {
+		return values[blockID][composeActionID]
+	}
+	return composeRequest{
+		ChannelID: input(composeBlockChannel).SelectedConversation,
+		Prompt:    strings.TrimSpace(input(composeBlockPrompt).Value),
+		WorkDir:   input(composeBlockWorkdir).SelectedOption.Value,
+		Overrides: runOverrides{
+			Model:          input(composeBlockModel).SelectedOption.Value,
+			PermissionMode: config.PermissionMode(input(composeBlockMode).SelectedOption.Value),
+		},
+	}
+}
+
+// handleComposeSubmission runs a composed request in the chosen channel. The
+// prompt is posted as a thread, and Claude replies in it.
+func (s *Service) handleComposeSubmission(callback *slack.InteractionCallback) {
+	ctx := context.Background()
+	userID := callback.User.ID
+
+	var values map[string]map[string]slack.BlockAction
+	if callback.View.State != nil {
+		values = callback.View.State.Values
+	}
+	req := parseComposeSubmission(values)
+	if req.ChannelID == "" || req.Prompt == "" {
+		s.logger.Warn("Ignoring incomplete request composer submission", zap.String("user_id", userID))
+		return
+	}
+
+	authCtx := &auth.AuthContext{
+		UserID:    userID,
+		ChannelID: req.ChannelID,
+		Command:   "shortcut:" + composeCallbackID,
+		Timestamp: time.Now(),
+	}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionExecute); err != nil {
+		s.logger.Warn("Shortcut authorization failed", zap.Error(err))
+		s.postEphemeral(req.ChannelID, userID, fmt.Sprintf("❌ Authorization failed: %v", err))
+		return
+	}
+	if limited := s.checkRateLimit(userID, req.ChannelID); limited != nil {
+		s.postEphemeral(req.ChannelID, userID, s.rateLimitMessage(userID, limited))
+		return
+	}
+	if req.Overrides.PermissionMode != "" && !req.Overrides.PermissionMode.IsValid() {
+		s.postEphemeral(req.ChannelID, userID, fmt.Sprintf("❌ **Invalid permission mode:** `%s`", req.Overrides.PermissionMode))
+		return
+	}
+
+	var notes []string
+	if req.WorkDir != "" {
+		note, err := s.useComposeWorkDir(userID, req.ChannelID, req.WorkDir)
+		if err != nil {
+			s.postEphemeral(req.ChannelID, userID, err.Error())
+			return
+		}
+		notes = append(notes, note)
+	}
+	if req.Overrides.Model != "" {
+		notes = append(notes, fmt.Sprintf("model `%s`", req.Overrides.Model))
+	}
+	if req.Overrides.PermissionMode != "" {
+		notes = append(notes, fmt.Sprintf("mode `%s`", req.Overrides.PermissionMode))
+	}
+
+	header := fmt.Sprintf("📝 <@%s> asked Claude", userID)
+	if len(notes) > 0 {
+		header += " (" + strings.Join(notes, ", ") + ")"
+	}
+	threadTS := s.sendThreadResponse(req.ChannelID, "", header+":\n>>> "+req.Prompt)
+	if threadTS == "" {
+		s.postEphemeral(req.ChannelID, userID, "❌ **Error:** Couldn't post your request here. Invite the bot to the channel and try again.")
+		return
+	}
+
+	event := &slackevents.MessageEvent{
+		Type:            "message",
+		User:            userID,
+		Text:            req.Prompt,
+		TimeStamp:       threadTS,
+		ThreadTimeStamp: threadTS,
+		Channel:         req.ChannelID,
+	}
+
+	started := time.Now()
+	response := s.processClaudeMessage(ctx, event, req.Prompt, req.Overrides)
+	if response != "" {
+		// A held response isn't in Slack yet, so there is nothing to link to
+		if responseTS, held := s.sendThreadResponseOrHold(req.ChannelID, threadTS, response); !held {
+			s.notifyIfSlow(userID, req.ChannelID, responseTS, time.Since(started))
+		}
+	}
+}
+
+// useComposeWorkDir makes the channel's session run in dir, switching to its
+// latest session there or starting one, like `/session . <path>`. It returns
+// a note describing the session used.
+func (s *Service) useComposeWorkDir(userID, channelID, dir string) (string, error) {
+	dir = filepath.Clean(dir)
+	if !s.isAllowedWorkdir(dir) {
+		return "", fmt.Errorf("❌ **Directory not allowed:** `%s`", dir)
+	}
+
+	current, err := s.sessionManager.GetOrCreateSession(userID, channelID)
+	if err == nil && filepath.Clean(current.GetCurrentWorkDir()) == dir {
+		return fmt.Sprintf("in `%s`", dir), nil
+	}
+
+	existing, err := s.sessionManager.GetSessionsByPath(dir, 1)
+	if err != nil {
+		s.logger.Error("Failed to get sessions by path", zap.Error(err))
+	}
+	if len(existing) > 0 {
+		err := s.switchSession(channelID, userID, existing[0].GetID())
+		if isSessionBusy(err) {
+			return "", fmt.Errorf("%s", sessionSwitchBusyMessage)
+		}
+		if err != nil {
+			s.logger.Error("Failed to switch session for composed request", zap.Error(err))
+			return "", fmt.Errorf("❌ **Error:** Failed to switch to the session for `%s`", dir)
+		}
+		return fmt.Sprintf("in `%s`, session `%s`", dir, existing[0].GetID()), nil
+	}
+
+	if err := validateWorkingDir(dir); err != nil {
+		return "", fmt.Errorf("❌ **Invalid directory:** %v", err)
+	}
+	newSession, err := s.sessionManager.CreateSessionWithPath(userID, channelID, dir)
+	if err != nil {
+		s.logger.Error("Failed to create session for composed request", zap.Error(err))
+		return "", fmt.Errorf("❌ **Error:** Failed to create a session for `%s`", dir)
+	}
+	return fmt.Sprintf("in `%s`, new session `%s`", dir, newSession.GetID()), nil
+}
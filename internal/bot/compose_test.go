@@ -0,0 +1,39 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+func TestParseComposeSubmission(t *testing.T) {
+	values := map[string]map[string]slack.BlockAction{
+		composeBlockChannel: {composeActionID: {SelectedConversation: "C123"}},
+		composeBlockPrompt:  {composeActionID: {Value: "  Fix the flaky test\n\n```go\nfunc TestX() {}\n```\n"}},
+		composeBlockModel:   {composeActionID: {SelectedOption: slack.OptionBlockObject{Value: "opus"}}},
+		composeBlockMode:    {composeActionID: {SelectedOption: slack.OptionBlockObject{Value: "plan"}}},
+	}
+
+	req := parseComposeSubmission(values)
+	if req.ChannelID != "C123" || req.WorkDir != "" {
+		t.Errorf("ChannelID, WorkDir = %q, %q; want C123 and none", req.ChannelID, req.WorkDir)
+	}
+	if req.Prompt != "Fix the flaky test\n\n```go\nfunc TestX() {}\n```" {
+		t.Errorf("Unexpected prompt %q", req.Prompt)
+	}
+	if req.Overrides.Model != "opus" || req.Overrides.PermissionMode != config.PermissionModePlan {
+		t.Errorf("Unexpected overrides %+v", req.Overrides)
+	}
+}
+
+func TestParseComposeSubmission_Defaults(t *testing.T) {
+	req := parseComposeSubmission(map[string]map[string]slack.BlockAction{
+		composeBlockChannel: {composeActionID: {SelectedConversation: "C123"}},
+		composeBlockPrompt:  {composeActionID: {Value: "hello"}},
+	})
+	if req.Overrides != (runOverrides{}) {
+		t.Errorf("Expected no overrides, got %+v", req.Overrides)
+	}
+}
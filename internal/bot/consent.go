@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+// policyAgreeActionID is the "I agree" button on the usage policy prompt
+const policyAgreeActionID = "usage_policy_agree"
+
+// pendingPolicyRun is a request held back until its author agrees to the
+// usage policy
+type pendingPolicyRun struct {
+	event     slackevents.MessageEvent
+	text      string
+	overrides runOverrides
+}
+
+// policyAcceptances caches who agreed to the current policy version, and
+// holds each user's latest request until they do
+type policyAcceptances struct {
+	mu       sync.Mutex
+	accepted map[string]bool
+	pending  map[string]*pendingPolicyRun
+}
+
+func newPolicyAcceptances() *policyAcceptances {
+	return &policyAcceptances{
+		accepted: make(map[string]bool),
+		pending:  make(map[string]*pendingPolicyRun),
+	}
+}
+
+func (p *policyAcceptances) isAccepted(userID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.accepted[userID]
+}
+
+// accept marks a user as having agreed and returns the request they were
+// held back on, if any
+func (p *policyAcceptances) accept(userID string) *pendingPolicyRun {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.accepted[userID] = true
+	run := p.pending[userID]
+	delete(p.pending, userID)
+	return run
+}
+
+// hold keeps a user's request until they agree, replacing an earlier one
+func (p *policyAcceptances) hold(userID string, run *pendingPolicyRun) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending[userID] = run
+}
+
+// policyAccepted reports whether a user agreed to the current usage policy,
+// or no policy is configured. Lookup failures count as not agreed.
+func (s *Service) policyAccepted(userID string) bool {
+	accepted, err := s.checkPolicyAccepted(userID)
+	if err != nil {
+		s.logger.Warn("Failed to check usage policy acknowledgment",
+			zap.String("user_id", userID),
+			zap.Error(err))
+	}
+	return accepted
+}
+
+func (s *Service) checkPolicyAccepted(userID string) (bool, error) {
+	if s.config.UsagePolicy == "" || s.consent.isAccepted(userID) {
+		return true, nil
+	}
+	accepted, err := s.policyAcks.HasAcknowledgedPolicy(userID, s.config.UsagePolicyVersion)
+	if err != nil {
+		return false, err
+	}
+	if accepted {
+		s.consent.accept(userID)
+	}
+	return accepted, nil
+}
+
+// requirePolicyAcceptance lets a Claude run go ahead once its author has
+// agreed to the usage policy. Otherwise the run is held, the policy is shown
+// with an "I agree" button, and the returned message (possibly empty) is
+// what to reply instead.
+func (s *Service) requirePolicyAcceptance(ctx context.Context, event *slackevents.MessageEvent, text string, overrides runOverrides) (string, bool) {
+	accepted, err := s.checkPolicyAccepted(event.User)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(event.Channel, event.User, "usage_policy", "check_acknowledgment")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to check the usage policy acknowledgment; Claude was not run"), false
+	}
+	if accepted {
+		return "", true
+	}
+
+	s.consent.hold(event.User, &pendingPolicyRun{event: *event, text: text, overrides: overrides})
+	if err := s.postPolicyPrompt(ctx, event.Channel, event.User); err != nil {
+		errCtx := logging.CreateErrorContext(event.Channel, event.User, "usage_policy", "post_prompt")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to show the usage policy; Claude was not run"), false
+	}
+	return "", false
+}
+
+// postPolicyPrompt shows the usage policy privately with an "I agree" button
+func (s *Service) postPolicyPrompt(ctx context.Context, channelID, userID string) error {
+	agree := slack.NewButtonBlockElement(policyAgreeActionID, s.config.UsagePolicyVersion,
+		slack.NewTextBlockObject(slack.PlainTextType, "I agree", false, false))
+	agree.Style = slack.StylePrimary
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, "📋 *Before Claude can help you, please read and agree to the usage policy*", false, false),
+			nil, nil),
+	}
+	// A long policy takes several sections
+	for _, part := range splitMessage(s.config.UsagePolicy, maxSectionTextLength) {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, part, false, false),
+			nil, nil))
+	}
+	blocks = append(blocks,
+		slack.NewActionBlock("usage_policy_actions", agree),
+		slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("Policy version `%s`. Your request runs as soon as you agree.", s.config.UsagePolicyVersion), false, false)))
+
+	return s.sender.PostEphemeral(ctx, channelID, userID,
+		slack.MsgOptionText("Please read and agree to the usage policy before using Claude", false),
+		slack.MsgOptionBlocks(blocks...))
+}
+
+// handlePolicyAgreeAction records a user's agreement and runs the request
+// they were held back on
+func (s *Service) handlePolicyAgreeAction(callback *slack.InteractionCallback, action *slack.BlockAction) {
+	userID := callback.User.ID
+	channelID := callback.Channel.ID
+	ctx := context.Background()
+
+	if s.config.UsagePolicy == "" {
+		return
+	}
+	// The policy changed since it was shown; show the new one
+	if action.Value != s.config.UsagePolicyVersion {
+		if err := s.postPolicyPrompt(ctx, channelID, userID); err != nil {
+			s.logger.Error("Failed to show the updated usage policy", zap.String("user_id", userID), zap.Error(err))
+		}
+		return
+	}
+
+	if err := s.policyAcks.RecordPolicyAcknowledgment(userID, action.Value, channelID); err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "usage_policy", "record_acknowledgment")
+		s.postEphemeral(channelID, userID, s.logErrorWithTrace(ctx, errCtx, err, "Failed to record your agreement"))
+		return
+	}
+
+	run := s.consent.accept(userID)
+	if run == nil {
+		s.postEphemeral(channelID, userID, "✅ **Thanks!** You can now talk to Claude.")
+		return
+	}
+
+	s.postEphemeral(channelID, userID, "✅ **Thanks!** Running your request now.")
+	if response := s.processClaudeMessage(ctx, &run.event, run.text, run.overrides); response != "" {
+		s.sendThreadResponseOrHold(run.event.Channel, run.event.ThreadTimeStamp, response)
+	}
+}
@@ -0,0 +1,31 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestPolicyAcceptances(t *testing.T) {
+	p := newPolicyAcceptances()
+	if p.isAccepted("U1") {
+		t.Fatal("Expected a new user not to have agreed")
+	}
+
+	p.hold("U1", &pendingPolicyRun{event: slackevents.MessageEvent{Channel: "C1"}, text: "first"})
+	p.hold("U1", &pendingPolicyRun{event: slackevents.MessageEvent{Channel: "C1"}, text: "second"})
+
+	run := p.accept("U1")
+	if run == nil || run.text != "second" {
+		t.Fatalf("accept() = %+v, want the latest held request", run)
+	}
+	if !p.isAccepted("U1") {
+		t.Error("Expected the user to have agreed")
+	}
+	if run := p.accept("U1"); run != nil {
+		t.Errorf("Expected a held request to run once, got %+v", run)
+	}
+	if p.isAccepted("U2") {
+		t.Error("Expected agreement to be per user")
+	}
+}
@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+)
+
+// contextPercent returns how full a context window of windowTokens is
+func contextPercent(tokens, windowTokens int) int {
+	if windowTokens <= 0 {
+		return 0
+	}
+	return tokens * 100 / windowTokens
+}
+
+// formatTokens shortens a token count for display, e.g. 170000 -> "170k"
+func formatTokens(tokens int) string {
+	switch {
+	case tokens >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(tokens)/1_000_000)
+	case tokens >= 1000:
+		return fmt.Sprintf("%dk", tokens/1000)
+	default:
+		return fmt.Sprintf("%d", tokens)
+	}
+}
+
+// contextWarning returns a footer warning when a conversation of tokens is
+// close to filling the model's context window, or "" while there is room
+func (s *Service) contextWarning(tokens int) string {
+	if s.config.ContextWarnPercent <= 0 || tokens <= 0 {
+		return ""
+	}
+
+	percent := contextPercent(tokens, s.config.ContextWindowTokens)
+	if percent < s.config.ContextWarnPercent {
+		return ""
+	}
+	return fmt.Sprintf("context %d%% full (~%s of %s tokens) — consider starting fresh with `/session new`",
+		percent, formatTokens(tokens), formatTokens(s.config.ContextWindowTokens))
+}
+
+// contextSummary describes the estimated context size of a Claude session for
+// /session, or "" when no run has reported it
+func (s *Service) contextSummary(claudeSessionID string) string {
+	tokens, err := s.usage.GetContextTokens(claudeSessionID)
+	if err != nil {
+		s.logger.Debug("Failed to get context tokens",
+			zap.String("claude_session_id", claudeSessionID),
+			zap.Error(err))
+		return ""
+	}
+	if tokens == 0 {
+		return ""
+	}
+	return fmt.Sprintf("~%s of %s tokens (%d%%)",
+		formatTokens(tokens), formatTokens(s.config.ContextWindowTokens), contextPercent(tokens, s.config.ContextWindowTokens))
+}
+
+// autoCompactionDue reports whether a run that left its conversation at
+// tokens should be followed by /compact: the auto-compaction flag is on in
+// the channel and the context is past CONTEXT_WARN_PERCENT. Compaction runs
+// themselves never trigger another.
+func (s *Service) autoCompactionDue(channelID, prompt string, tokens int) bool {
+	if s.contextWarning(tokens) == "" || strings.TrimSpace(prompt) == "/compact" {
+		return false
+	}
+	return s.featureEnabled(flagAutoCompaction, channelID)
+}
+
+// runAutoCompaction compacts a session that has nearly filled the context
+// window, unless another run has started in it since; that run's response
+// will ask again
+func (s *Service) runAutoCompaction(channelID, userID, threadTS, sessionID string) {
+	if s.sessionManager.IsProcessing(sessionID) {
+		s.logger.Debug("Skipping auto-compaction of a busy session",
+			zap.String("channel_id", channelID),
+			zap.String("bot_session_id", sessionID))
+		return
+	}
+	s.sendThreadResponse(channelID, threadTS, "🗜️ **Compacting this session** automatically; it has nearly filled Claude's context window")
+
+	event := &slackevents.MessageEvent{
+		Type:            "message",
+		User:            userID,
+		Text:            "/compact",
+		Channel:         channelID,
+		ThreadTimeStamp: threadTS,
+	}
+	if response := s.processClaudeMessage(context.Background(), event, "/compact", runOverrides{}); response != "" {
+		s.sendThreadResponseOrHold(channelID, threadTS, response)
+	}
+}
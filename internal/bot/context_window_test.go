@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+func TestFormatTokens(t *testing.T) {
+	tests := []struct {
+		tokens int
+		want   string
+	}{
+		{850, "850"},
+		{170000, "170k"},
+		{1000000, "1.0M"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTokens(tt.tokens); got != tt.want {
+			t.Errorf("formatTokens(%d) = %q, want %q", tt.tokens, got, tt.want)
+		}
+	}
+}
+
+func TestContextWarning(t *testing.T) {
+	s := &Service{config: &config.Config{ContextWindowTokens: 200000, ContextWarnPercent: 80}}
+
+	if warning := s.contextWarning(150000); warning != "" {
+		t.Errorf("contextWarning(150000) = %q, want no warning at 75%%", warning)
+	}
+	if warning := s.contextWarning(170000); !strings.Contains(warning, "85% full") || !strings.Contains(warning, "170k of 200k") {
+		t.Errorf("contextWarning(170000) = %q, want an 85%% warning", warning)
+	}
+
+	s.config.ContextWarnPercent = 0
+	if warning := s.contextWarning(199000); warning != "" {
+		t.Errorf("contextWarning() with warnings disabled = %q", warning)
+	}
+}
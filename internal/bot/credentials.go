@@ -0,0 +1,254 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+// tokenRefreshMargin is how long before expiry a rotating bot token is refreshed
+const tokenRefreshMargin = 15 * time.Minute
+
+// api returns the current Slack Web API client
+func (s *Service) api() *slack.Client {
+	s.credsMu.RLock()
+	defer s.credsMu.RUnlock()
+	return s.slackAPI
+}
+
+// socket returns the current Socket Mode client
+func (s *Service) socket() *socketmode.Client {
+	s.credsMu.RLock()
+	defer s.credsMu.RUnlock()
+	return s.socketClient
+}
+
+// newSlackClients builds Web API and Socket Mode clients for a set of credentials
+func newSlackClients(cfg *config.Config, creds config.SlackCredentials) (*slack.Client, *socketmode.Client) {
+	slackAPI := slack.New(creds.BotToken, slack.OptionDebug(cfg.EnableDebug), slack.OptionAppLevelToken(creds.AppToken))
+	socketClient := socketmode.New(slackAPI, socketmode.OptionDebug(cfg.EnableDebug))
+	return slackAPI, socketClient
+}
+
+// startSocketMode runs the current Socket Mode client until it is replaced or
+// the service context ends
+func (s *Service) startSocketMode(ctx context.Context) {
+	socketCtx, cancel := context.WithCancel(ctx)
+
+	s.credsMu.Lock()
+	client := s.socketClient
+	previousCancel := s.socketCancel
+	s.socketCancel = cancel
+	s.credsMu.Unlock()
+
+	go func() {
+		if err := client.RunContext(socketCtx); err != nil && socketCtx.Err() == nil {
+			s.logger.Debug("Socket Mode not available or disabled", zap.Error(err))
+		}
+	}()
+
+	// Stop the previous connection only after the new one is running so no
+	// events are missed; unacknowledged envelopes are redelivered by Slack
+	if previousCancel != nil {
+		previousCancel()
+	}
+
+	select {
+	case s.socketChanged <- struct{}{}:
+	default:
+	}
+}
+
+// applyCredentials swaps in new Slack credentials. New tokens are validated
+// before any client is replaced so a bad secret never takes the bot offline.
+func (s *Service) applyCredentials(creds config.SlackCredentials) error {
+	current := s.config.SlackCredentials()
+	if creds == current {
+		return nil
+	}
+
+	tokensChanged := creds.BotToken != current.BotToken || creds.AppToken != current.AppToken
+	if tokensChanged {
+		slackAPI, socketClient := newSlackClients(s.config, creds)
+		if _, err := slackAPI.AuthTest(); err != nil {
+			return fmt.Errorf("failed to validate new bot token: %w", err)
+		}
+
+		s.credsMu.Lock()
+		s.slackAPI = slackAPI
+		s.socketClient = socketClient
+		runCtx := s.runCtx
+		s.credsMu.Unlock()
+
+		s.fileDownloader.SetClient(slackAPI, creds.BotToken)
+		s.dualLogger.SetSlackClient(slackAPI)
+
+		if runCtx != nil {
+			s.startSocketMode(runCtx)
+		}
+		if creds.BotToken != current.BotToken {
+			go s.verifyScopes(context.Background(), creds.BotToken)
+		}
+	}
+
+	s.config.SetSlackCredentials(creds)
+
+	s.logger.Info("Slack credentials updated",
+		zap.Bool("bot_token_changed", creds.BotToken != current.BotToken),
+		zap.Bool("app_token_changed", creds.AppToken != current.AppToken),
+		zap.Bool("signing_secret_changed", creds.SigningSecret != current.SigningSecret))
+	return nil
+}
+
+// reloadSecretsFile re-reads SLACK_SECRETS_FILE and applies any changed credentials
+func (s *Service) reloadSecretsFile() error {
+	values, err := config.ReadSecretsFile(s.config.SlackSecretsFile)
+	if err != nil {
+		return err
+	}
+
+	creds := s.config.SlackCredentials()
+	if val := values["SLACK_BOT_TOKEN"]; val != "" {
+		creds.BotToken = val
+	}
+	if val := values["SLACK_APP_TOKEN"]; val != "" {
+		creds.AppToken = val
+	}
+	if val := values["SLACK_SIGNING_SECRET"]; val != "" {
+		creds.SigningSecret = val
+	}
+	if val := values["SLACK_REFRESH_TOKEN"]; val != "" {
+		creds.RefreshToken = val
+	}
+
+	return s.applyCredentials(creds)
+}
+
+// tokenRotationEnabled reports whether the bot token can be refreshed via OAuth
+func (s *Service) tokenRotationEnabled() bool {
+	return s.config.SlackClientID != "" && s.config.SlackClientSecret != "" &&
+		s.config.SlackCredentials().RefreshToken != ""
+}
+
+// refreshBotToken exchanges the refresh token for a new bot token. The rotated
+// tokens are written back to the secrets file first, because the previous
+// refresh token may no longer be usable once the exchange succeeds.
+func (s *Service) refreshBotToken() error {
+	creds := s.config.SlackCredentials()
+	resp, err := slack.RefreshOAuthV2Token(http.DefaultClient, s.config.SlackClientID, s.config.SlackClientSecret, creds.RefreshToken)
+	if err != nil {
+		return fmt.Errorf("failed to refresh bot token: %w", err)
+	}
+
+	creds.BotToken = resp.AccessToken
+	if resp.RefreshToken != "" {
+		creds.RefreshToken = resp.RefreshToken
+	}
+
+	if s.config.SlackSecretsFile != "" {
+		err := config.UpdateSecretsFile(s.config.SlackSecretsFile, map[string]string{
+			"SLACK_BOT_TOKEN":     creds.BotToken,
+			"SLACK_REFRESH_TOKEN": creds.RefreshToken,
+		})
+		if err != nil {
+			s.logger.Error("Failed to persist rotated Slack tokens", zap.Error(err))
+		}
+	}
+
+	if err := s.applyCredentials(creds); err != nil {
+		return err
+	}
+
+	s.credsMu.Lock()
+	if resp.ExpiresIn > 0 {
+		s.tokenExpiresAt = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	s.credsMu.Unlock()
+
+	s.logger.Info("Rotated Slack bot token", zap.Int("expires_in_seconds", resp.ExpiresIn))
+	return nil
+}
+
+// tokenRefreshDue reports whether the rotating bot token should be refreshed.
+// The expiry is unknown until the first refresh, so that happens right away.
+func (s *Service) tokenRefreshDue() bool {
+	s.credsMu.RLock()
+	defer s.credsMu.RUnlock()
+	return s.tokenExpiresAt.IsZero() || time.Until(s.tokenExpiresAt) < tokenRefreshMargin
+}
+
+// credentialsLoop refreshes rotating tokens and reloads the secrets file when
+// it changes or the process receives SIGHUP
+func (s *Service) credentialsLoop() {
+	if s.config.SlackSecretsFile == "" && !s.tokenRotationEnabled() {
+		return
+	}
+
+	interval := s.config.SecretsReloadInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var lastModified time.Time
+	if info, err := os.Stat(s.config.SlackSecretsFile); err == nil {
+		lastModified = info.ModTime()
+	}
+
+	for {
+		if s.tokenRotationEnabled() && s.tokenRefreshDue() {
+			if err := s.refreshBotToken(); err != nil {
+				s.logger.Error("Slack token rotation failed", zap.Error(err))
+			}
+		}
+
+		select {
+		case <-ticker.C:
+			if s.config.SlackSecretsFile == "" {
+				continue
+			}
+			info, err := os.Stat(s.config.SlackSecretsFile)
+			if err != nil {
+				s.logger.Warn("Failed to stat secrets file", zap.Error(err))
+				continue
+			}
+			if !info.ModTime().After(lastModified) {
+				continue
+			}
+			lastModified = info.ModTime()
+			if err := s.reloadSecretsFile(); err != nil {
+				s.logger.Error("Failed to reload secrets file", zap.Error(err))
+			}
+
+		case <-hangup:
+			if s.config.SlackSecretsFile == "" {
+				continue
+			}
+			s.logger.Info("Received SIGHUP, reloading Slack secrets")
+			if info, err := os.Stat(s.config.SlackSecretsFile); err == nil {
+				lastModified = info.ModTime()
+			}
+			if err := s.reloadSecretsFile(); err != nil {
+				s.logger.Error("Failed to reload secrets file", zap.Error(err))
+			}
+
+		case <-s.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package bot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+	"github.com/ghabxph/claude-on-slack/internal/version"
+)
+
+// debugBundleLogLimit caps how many channel log entries go into a bundle
+const debugBundleLogLimit = 500
+
+// handleDebugSlashCommand handles the /debug slash command
+func (s *Service) handleDebugSlashCommand(userID, channelID, text string) string {
+	args := strings.Fields(text)
+
+	// Raw responses carry tool inputs and outputs, which can hold secrets
+	// the configured values don't cover
+	if !s.authService.IsUserAdmin(userID) {
+		return "❌ This command requires admin privileges."
+	}
+
+	if len(args) > 0 && args[0] == "bundle" {
+		go s.uploadDebugBundle(userID, channelID)
+		return "📦 Generating debug bundle... It will be uploaded to this channel shortly."
+	}
+
+	userSession, err := s.sessionManager.GetOrCreateSession(userID, channelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "debug_slash_command", "get_session")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to get current session")
+	}
+
+	rawJSON := s.latestRawResponse(userSession.GetID())
+	if rawJSON == "" {
+		return "📋 **No Claude response recorded yet for this session.**\n\n**Usage:**\n• `/debug` - Show the latest raw Claude response\n• `/debug bundle` - Upload a redacted debug bundle"
+	}
+
+	// Scrub before truncating so a secret cut at the limit is still caught
+	rawJSON = s.config.ScrubSecrets(rawJSON)
+	if len(rawJSON) > 2500 {
+		rawJSON = rawJSON[:2500] + "\n... (truncated, use `/debug bundle` for the full response)"
+	}
+
+	return fmt.Sprintf("🐛 **Latest Raw Claude Response**\n\nSession: `%s`\n```\n%s\n```", userSession.GetID(), rawJSON)
+}
+
+// latestRawResponse returns the latest raw Claude JSON for a session, if known
+func (s *Service) latestRawResponse(sessionID string) string {
+	dbManager, ok := s.sessionManager.(*session.DatabaseManager)
+	if !ok {
+		return ""
+	}
+	rawJSON, err := dbManager.GetLatestResponse(sessionID)
+	if err != nil {
+		return ""
+	}
+	return rawJSON
+}
+
+// uploadDebugBundle builds the debug bundle and uploads it to the channel
+func (s *Service) uploadDebugBundle(userID, channelID string) {
+	s.uploadDebugBundleTo(userID, channelID, channelID, fmt.Sprintf("📦 Debug bundle requested by <@%s> (secrets redacted)", userID))
+}
+
+// uploadDebugBundleTo builds a channel's debug bundle, with userID's session
+// in it, and uploads it to targetChannelID with comment. It reports whether
+// the upload succeeded.
+func (s *Service) uploadDebugBundleTo(userID, channelID, targetChannelID, comment string) bool {
+	bundle, err := s.buildDebugBundle(userID, channelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "debug_bundle", "build_bundle")
+		s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to build debug bundle")
+		return false
+	}
+
+	filename := fmt.Sprintf("claude-debug-%s-%s.tar.gz", channelID, time.Now().UTC().Format("20060102-150405"))
+	_, err = s.api().UploadFileV2(slack.UploadFileV2Parameters{
+		Reader:         bytes.NewReader(bundle),
+		FileSize:       len(bundle),
+		Filename:       filename,
+		Title:          "Claude debug bundle",
+		InitialComment: comment,
+		Channel:        targetChannelID,
+	})
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "debug_bundle", "upload_bundle")
+		s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to upload debug bundle")
+		return false
+	}
+
+	s.logger.Info("Debug bundle uploaded",
+		zap.String("channel_id", channelID),
+		zap.String("target_channel_id", targetChannelID),
+		zap.String("user_id", userID),
+		zap.String("filename", filename),
+		zap.Int("size", len(bundle)))
+	return true
+}
+
+// buildDebugBundle collects diagnostics for a channel into a gzipped tarball
+func (s *Service) buildDebugBundle(userID, channelID string) ([]byte, error) {
+	files := make(map[string][]byte)
+
+	// Version info
+	versionInfo := version.GetVersionInfo()
+	versionInfo["uptime"] = time.Since(s.startTime).Truncate(time.Second).String()
+	if data, err := json.MarshalIndent(versionInfo, "", "  "); err == nil {
+		files["version.json"] = data
+	}
+
+	// Configuration with secrets masked
+	configJSON, err := json.MarshalIndent(s.config.Redacted(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	files["config.json"] = configJSON
+
+	// Recent logs for this channel
+	var logs strings.Builder
+	entries := s.logBuffer.EntriesForChannel(channelID)
+	if len(entries) > debugBundleLogLimit {
+		entries = entries[len(entries)-debugBundleLogLimit:]
+	}
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			continue
+		}
+		logs.Write(line)
+		logs.WriteString("\n")
+	}
+	files["logs.jsonl"] = []byte(logs.String())
+
+	// Session tree and latest raw response
+	userSession, err := s.sessionManager.GetOrCreateSession(userID, channelID)
+	if err != nil {
+		files["session_tree.txt"] = []byte(fmt.Sprintf("failed to load session: %v\n", err))
+	} else {
+		files["session_tree.txt"] = []byte(s.describeSessionTree(userSession.GetID(), userSession.GetWorkspaceDir()))
+		if rawJSON := s.latestRawResponse(userSession.GetID()); rawJSON != "" {
+			files["latest_response.json"] = []byte(rawJSON)
+		}
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+
+	for _, name := range []string{"version.json", "config.json", "logs.jsonl", "session_tree.txt", "latest_response.json"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		// Scrub any secret that slipped into free-form content
+		content = []byte(s.config.ScrubSecrets(string(content)))
+
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to write bundle header for %s: %w", name, err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return nil, fmt.Errorf("failed to write bundle entry %s: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize bundle archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress bundle: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// describeSessionTree renders a plain-text overview of a session's conversation tree
+func (s *Service) describeSessionTree(sessionID, workingDir string) string {
+	var tree strings.Builder
+	tree.WriteString(fmt.Sprintf("Session: %s\nWorking Dir: %s\n\n", sessionID, workingDir))
+
+	children, err := s.sessionManager.GetConversationTree(sessionID)
+	if err != nil {
+		tree.WriteString(fmt.Sprintf("failed to load conversation tree: %v\n", err))
+		return tree.String()
+	}
+
+	tree.WriteString(fmt.Sprintf("Child sessions: %d\n", len(children)))
+	for i, child := range children {
+		previous := ""
+		if child.PreviousSessionID != nil {
+			previous = *child.PreviousSessionID
+		}
+		promptLen, responseLen := 0, 0
+		if child.UserPrompt != nil {
+			promptLen = len(*child.UserPrompt)
+		}
+		if child.AIResponse != nil {
+			responseLen = len(*child.AIResponse)
+		}
+		tree.WriteString(fmt.Sprintf("%3d. %s (prev: %s) created %s, prompt %d chars, response %d chars\n",
+			i+1, child.SessionID, previous, child.CreatedAt.Format(time.RFC3339), promptLen, responseLen))
+		if child.Summary != nil && *child.Summary != "" {
+			tree.WriteString(fmt.Sprintf("     summary: %s\n", *child.Summary))
+		}
+	}
+
+	return tree.String()
+}
@@ -0,0 +1,23 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+func TestHandleDebugSlashCommand_AdminOnly(t *testing.T) {
+	cfg := &config.Config{AdminUsers: []string{"UADMIN"}}
+	// Without a session manager, reading the raw response would panic
+	s := &Service{config: cfg, logger: zap.NewNop(), authService: auth.NewService(cfg, zap.NewNop())}
+
+	for _, text := range []string{"", "bundle"} {
+		if response := s.handleDebugSlashCommand("U1", "C1", text); !strings.Contains(response, "requires admin privileges") {
+			t.Errorf("/debug %s: expected non-admins to be refused, got %q", text, response)
+		}
+	}
+}
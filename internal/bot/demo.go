@@ -0,0 +1,298 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const (
+	demoUsage = "**Usage:** `/demo record <name>` | `/demo stop` | `/demo list` | `/demo play <name>` | `/demo delete <name>`"
+
+	// Replayed steps wait as long as the original run took, within these bounds
+	demoMinStepDelay = 1500 * time.Millisecond
+	demoMaxStepDelay = 8 * time.Second
+)
+
+// demoNamePattern matches valid demo names
+var demoNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,99}$`)
+
+// Patterns for details that shouldn't leave the recorded channel
+var (
+	demoUserMentionPattern    = regexp.MustCompile(`<@[UW][A-Z0-9]+(\|[^>]*)?>`)
+	demoChannelMentionPattern = regexp.MustCompile(`<#C[A-Z0-9]+(\|[^>]*)?>`)
+	demoMailtoPattern         = regexp.MustCompile(`<mailto:[^>]+>`)
+	demoEmailPattern          = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	demoHomeDirPattern        = regexp.MustCompile(`/(home|Users)/[^/\s` + "`" + `'"]+`)
+	demoPermalinkPattern      = regexp.MustCompile(`https://[A-Za-z0-9-]+\.slack\.com/[^\s>|)` + "`" + `'"]*`)
+	demoWorkspaceURLPattern   = regexp.MustCompile(`https://[A-Za-z0-9-]+\.slack\.com`)
+)
+
+// anonymizeDemoText strips user and channel mentions, email addresses, home
+// directory names, permalinks, and the workspace URL from recorded text
+func anonymizeDemoText(text string) string {
+	text = demoUserMentionPattern.ReplaceAllString(text, "@teammate")
+	text = demoChannelMentionPattern.ReplaceAllString(text, "#channel")
+	text = demoMailtoPattern.ReplaceAllString(text, "user@example.com")
+	text = demoEmailPattern.ReplaceAllString(text, "user@example.com")
+	text = demoHomeDirPattern.ReplaceAllString(text, "/$1/user")
+	text = demoPermalinkPattern.ReplaceAllString(text, "https://example.slack.com/link")
+	text = demoWorkspaceURLPattern.ReplaceAllString(text, "https://example.slack.com")
+	return text
+}
+
+// demoStepDelay is how long a replayed step "thinks" for
+func demoStepDelay(durationMS int) time.Duration {
+	delay := time.Duration(durationMS) * time.Millisecond
+	if delay < demoMinStepDelay {
+		return demoMinStepDelay
+	}
+	if delay > demoMaxStepDelay {
+		return demoMaxStepDelay
+	}
+	return delay
+}
+
+// demoPlaybacks tracks channels with a demo replay in progress
+type demoPlaybacks struct {
+	mu       sync.Mutex
+	channels map[string]bool
+}
+
+func newDemoPlaybacks() *demoPlaybacks {
+	return &demoPlaybacks{channels: make(map[string]bool)}
+}
+
+// start claims a channel for a replay; it returns false if one is running
+func (p *demoPlaybacks) start(channelID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.channels[channelID] {
+		return false
+	}
+	p.channels[channelID] = true
+	return true
+}
+
+func (p *demoPlaybacks) done(channelID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.channels, channelID)
+}
+
+// recordDemoStep appends a completed exchange to the demo being recorded in
+// the channel, if any, and returns the demo's name
+func (s *Service) recordDemoStep(channelID, prompt, response string, duration time.Duration) string {
+	demo, err := s.demos.GetRecordingDemo(channelID)
+	if err != nil {
+		s.logger.Warn("Failed to check for demo recording", zap.String("channel_id", channelID), zap.Error(err))
+		return ""
+	}
+	if demo == nil {
+		return ""
+	}
+
+	err = s.demos.AppendDemoStep(demo.ID, anonymizeDemoText(prompt), anonymizeDemoText(response), int(duration.Milliseconds()))
+	if err != nil {
+		s.logger.Error("Failed to record demo step",
+			zap.String("channel_id", channelID),
+			zap.String("demo", demo.Name),
+			zap.Error(err))
+		return ""
+	}
+	return demo.Name
+}
+
+// handleDemoCommand handles /demo record, stop, list, play, and delete
+func (s *Service) handleDemoCommand(ctx context.Context, req *commands.Request) (string, error) {
+	switch req.Args[0] {
+	case "list":
+		return s.handleDemoListCommand(ctx, req), nil
+	case "stop":
+		return s.handleDemoStopCommand(ctx, req), nil
+	case "record", "play", "delete":
+		if len(req.Args) != 2 {
+			return "❌ **Invalid arguments**\n\n" + demoUsage, nil
+		}
+		name := strings.ToLower(req.Args[1])
+		switch req.Args[0] {
+		case "record":
+			return s.handleDemoRecordCommand(ctx, req, name), nil
+		case "play":
+			return s.handleDemoPlayCommand(ctx, req, name), nil
+		default:
+			return s.handleDemoDeleteCommand(ctx, req, name), nil
+		}
+	}
+	return demoUsage, nil
+}
+
+func (s *Service) handleDemoRecordCommand(ctx context.Context, req *commands.Request, name string) string {
+	if !demoNamePattern.MatchString(name) {
+		return fmt.Sprintf("❌ **Invalid demo name:** `%s`\n\nUse lowercase letters, digits, `-` and `_`.", name)
+	}
+
+	recording, err := s.demos.GetRecordingDemo(req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "demo_command", "get_recording_demo")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to check for a demo recording")
+	}
+	if recording != nil {
+		return fmt.Sprintf("❌ **Already recording** `%s` in this channel\n\nFinish it with `/demo stop` first.", recording.Name)
+	}
+
+	created, err := s.demos.CreateDemo(name, req.ChannelID, req.UserID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "demo_command", "create_demo")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to start demo recording")
+	}
+	if !created {
+		return fmt.Sprintf("❌ **A demo named `%s` already exists**\n\nPick another name or remove it with `/demo delete %s`.", name, name)
+	}
+
+	s.logger.Info("Demo recording started",
+		zap.String("demo", name),
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID))
+	return fmt.Sprintf("🔴 **Recording demo `%s`**\n\nEvery prompt and response in this channel is saved, with names, emails, and home directories removed, until `/demo stop`.", name)
+}
+
+func (s *Service) handleDemoStopCommand(ctx context.Context, req *commands.Request) string {
+	demo, err := s.demos.StopDemoRecording(req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "demo_command", "stop_recording")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to stop demo recording")
+	}
+	if demo == nil {
+		return "ℹ️ No demo is being recorded in this channel"
+	}
+	return fmt.Sprintf("⏹️ **Demo `%s` saved** with %d step(s)\n\nReplay it in any channel with `/demo play %s`.", demo.Name, demo.Steps, demo.Name)
+}
+
+func (s *Service) handleDemoListCommand(ctx context.Context, req *commands.Request) string {
+	demos, err := s.demos.ListDemos()
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "demo_command", "list_demos")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list demos")
+	}
+	if len(demos) == 0 {
+		return "🎬 **No demos recorded yet**\n\n" + demoUsage
+	}
+
+	var response strings.Builder
+	response.WriteString("🎬 **Demos**\n")
+	for _, demo := range demos {
+		response.WriteString(fmt.Sprintf("\n• `%s` — %d step(s), recorded %s by <@%s>",
+			demo.Name, demo.Steps, s.userTime(req.UserID, demo.CreatedAt).Format("Jan 2 15:04"), demo.CreatedBy))
+		if demo.Recording {
+			response.WriteString(" _(recording)_")
+		}
+	}
+	response.WriteString("\n\nReplay one with `/demo play <name>`.")
+	return response.String()
+}
+
+func (s *Service) handleDemoDeleteCommand(ctx context.Context, req *commands.Request, name string) string {
+	demo, err := s.demos.GetDemo(name)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "demo_command", "get_demo")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to look up demo")
+	}
+	if demo == nil {
+		return fmt.Sprintf("❌ **Demo not found:** `%s`", name)
+	}
+	// Only whoever recorded a demo, or an admin, may remove it
+	if demo.CreatedBy != req.UserID && !s.authService.IsUserAdmin(req.UserID) {
+		return fmt.Sprintf("❌ **Demo `%s` was recorded by someone else.** Only they or an admin can delete it.", name)
+	}
+
+	deleted, err := s.demos.DeleteDemo(name)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "demo_command", "delete_demo")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to delete demo")
+	}
+	if !deleted {
+		return fmt.Sprintf("❌ **Demo not found:** `%s`", name)
+	}
+	return fmt.Sprintf("✅ **Demo `%s` deleted**", name)
+}
+
+func (s *Service) handleDemoPlayCommand(ctx context.Context, req *commands.Request, name string) string {
+	demo, err := s.demos.GetDemo(name)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "demo_command", "get_demo")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to get demo")
+	}
+	if demo == nil {
+		return fmt.Sprintf("❌ **Demo not found:** `%s`\n\nSee `/demo list`.", name)
+	}
+	if demo.Recording {
+		return fmt.Sprintf("❌ **Demo `%s` is still being recorded**\n\nRun `/demo stop` in <#%s> first.", name, demo.SourceChannelID)
+	}
+
+	steps, err := s.demos.GetDemoSteps(demo.ID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "demo_command", "get_demo_steps")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to load demo")
+	}
+	if len(steps) == 0 {
+		return fmt.Sprintf("❌ **Demo `%s` has no recorded steps**", name)
+	}
+
+	if !s.demoPlaybacks.start(req.ChannelID) {
+		return "⏳ **A demo is already playing in this channel**"
+	}
+	go s.playDemo(req.ChannelID, req.UserID, demo, steps)
+
+	return fmt.Sprintf("▶️ **Playing demo `%s`** (%d step(s))", name, len(steps))
+}
+
+// playDemo posts a demo's prompts and stored responses with a pause in
+// between, as if the exchanges were happening live. Claude is never run.
+func (s *Service) playDemo(channelID, userID string, demo *repository.Demo, steps []repository.DemoStep) {
+	defer s.demoPlaybacks.done(channelID)
+
+	s.logger.Info("Playing demo",
+		zap.String("demo", demo.Name),
+		zap.String("channel_id", channelID),
+		zap.String("user_id", userID))
+
+	ctx := context.Background()
+	s.sendResponse(channelID, fmt.Sprintf("🎬 *Demo: %s* — a replay of %d recorded exchange(s), started by <@%s>. These responses were recorded earlier; nothing here runs Claude.",
+		demo.Name, len(steps), userID))
+
+	for i, step := range steps {
+		quoted := "> " + strings.ReplaceAll(step.Prompt, "\n", "\n> ")
+		s.sendResponse(channelID, fmt.Sprintf("💬 *Prompt %d/%d*\n%s", i+1, len(steps), quoted))
+
+		thinkingTS, err := s.sender.PostMessage(ctx, channelID, slack.MsgOptionText("🤔 _Thinking..._ _(replay)_", false))
+		if err != nil {
+			s.logger.Warn("Failed to post demo thinking message", zap.Error(err))
+		}
+
+		select {
+		case <-time.After(demoStepDelay(step.DurationMS)):
+		case <-s.stopCh:
+			return
+		}
+
+		if thinkingTS != "" {
+			if err := s.sender.DeleteMessage(ctx, channelID, thinkingTS); err != nil {
+				s.logger.Debug("Failed to delete demo thinking message", zap.Error(err))
+			}
+		}
+		s.sendResponse(channelID, step.Response)
+	}
+
+	s.sendResponse(channelID, fmt.Sprintf("🎬 *End of demo* `%s`", demo.Name))
+}
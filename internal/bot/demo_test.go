@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnonymizeDemoText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"ask <@U0123ABCD> about it", "ask @teammate about it"},
+		{"see <#C0123ABCD|deploys>", "see #channel"},
+		{"mail <mailto:jane@acme.io|jane@acme.io> or bob@acme.io", "mail user@example.com or user@example.com"},
+		{"edit /home/jane/project/main.go and `/Users/bob/x`", "edit /home/user/project/main.go and `/Users/user/x`"},
+		{"https://acme.slack.com/archives/C1/p1", "https://example.slack.com/link"},
+		{"see <https://acme.slack.com/archives/C0123ABCD/p1700000000123456?thread_ts=1700000000.000100|this thread>", "see <https://example.slack.com/link|this thread>"},
+		{"workspace https://acme.slack.com", "workspace https://example.slack.com"},
+		{"nothing to hide", "nothing to hide"},
+	}
+
+	for _, tt := range tests {
+		if got := anonymizeDemoText(tt.input); got != tt.want {
+			t.Errorf("anonymizeDemoText(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDemoStepDelay(t *testing.T) {
+	tests := []struct {
+		durationMS int
+		want       time.Duration
+	}{
+		{0, demoMinStepDelay},
+		{3000, 3 * time.Second},
+		{120000, demoMaxStepDelay},
+	}
+
+	for _, tt := range tests {
+		if got := demoStepDelay(tt.durationMS); got != tt.want {
+			t.Errorf("demoStepDelay(%d) = %s, want %s", tt.durationMS, got, tt.want)
+		}
+	}
+}
+
+func TestDemoPlaybacks(t *testing.T) {
+	playbacks := newDemoPlaybacks()
+	if !playbacks.start("C1") {
+		t.Fatal("start(C1) = false on an idle channel")
+	}
+	if playbacks.start("C1") {
+		t.Error("start(C1) = true while a demo is playing")
+	}
+	if !playbacks.start("C2") {
+		t.Error("start(C2) = false; other channels should be independent")
+	}
+	playbacks.done("C1")
+	if !playbacks.start("C1") {
+		t.Error("start(C1) = false after the demo finished")
+	}
+}
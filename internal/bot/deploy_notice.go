@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/notifications"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+	"github.com/ghabxph/claude-on-slack/internal/version"
+)
+
+// deployNoticeJitter is the most extra time an instance waits before claiming
+// the deployment notice, so instances started together don't race
+const deployNoticeJitter = 15 * time.Second
+
+// deployNoticeWait is how long to wait before announcing a deployment: the
+// settle delay plus up to deployNoticeJitter, scaled by roll in [0, 1)
+func deployNoticeWait(delay time.Duration, roll float64) time.Duration {
+	return delay + time.Duration(roll*float64(deployNoticeJitter))
+}
+
+// sendStartupNotification announces a new version to the configured sinks
+// once it has stayed up for DEPLOY_NOTIFY_DELAY. Restarts in quick
+// succession coalesce into one notice from whichever instance stays up, and
+// with database persistence a version is only announced once, by one
+// instance, listing the CHANGELOG entries since the last one announced.
+func (s *Service) sendStartupNotification() {
+	wait := deployNoticeWait(s.config.DeployNotifyDelay, rand.Float64())
+	s.logger.Info("Scheduling deployment notification",
+		zap.String("version", version.GetVersion()),
+		zap.Duration("wait", wait))
+
+	go func() {
+		select {
+		case <-time.After(wait):
+		case <-s.stopCh:
+			s.logger.Info("Stopped before announcing deployment; leaving it to the next start")
+			return
+		}
+
+		current := version.GetVersion()
+		var previous string
+		noticeMgr, persisted := s.sessionManager.(session.DeploymentNoticeManager)
+		if persisted {
+			last, claimed, err := noticeMgr.ClaimDeploymentNotice(current)
+			if err != nil {
+				s.logger.Error("Failed to check the last announced version; skipping deployment notification", zap.Error(err))
+				return
+			}
+			if !claimed {
+				s.logger.Info("Deployment already announced",
+					zap.String("version", current),
+					zap.String("last_announced", last))
+				return
+			}
+			previous = last
+		}
+
+		changes := version.ChangesSince(previous)
+		ctx, cancel := context.WithTimeout(context.Background(), alertSendTimeout)
+		defer cancel()
+		if err := s.notifier.Notify(ctx, notifications.DeploymentEvent(previous, changes)); err != nil {
+			s.logger.Error("Failed to send startup notification", zap.Error(err))
+			if persisted {
+				// Let the next start announce this version instead
+				if err := noticeMgr.ReleaseDeploymentNotice(current, previous); err != nil {
+					s.logger.Error("Failed to release deployment notice claim", zap.Error(err))
+				}
+			}
+			return
+		}
+		s.logger.Info("Startup notification sent successfully",
+			zap.String("version", current),
+			zap.String("previous_version", previous),
+			zap.Int("changes", len(changes)))
+	}()
+}
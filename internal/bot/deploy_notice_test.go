@@ -0,0 +1,19 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeployNoticeWait(t *testing.T) {
+	if got := deployNoticeWait(2*time.Minute, 0); got != 2*time.Minute {
+		t.Errorf("Expected no jitter at 0, got %v", got)
+	}
+	got := deployNoticeWait(2*time.Minute, 0.999)
+	if got <= 2*time.Minute || got >= 2*time.Minute+deployNoticeJitter {
+		t.Errorf("Expected jitter under %v, got %v", deployNoticeJitter, got)
+	}
+	if got := deployNoticeWait(0, 0.5); got != deployNoticeJitter/2 {
+		t.Errorf("Expected only jitter without a delay, got %v", got)
+	}
+}
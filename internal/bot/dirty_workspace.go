@@ -0,0 +1,323 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/git"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+// Buttons on the dirty workspace warning
+const (
+	dirtyContinueActionID = "dirty_workspace_continue"
+	dirtyCommitActionID   = "dirty_workspace_commit"
+	dirtyDiscardActionID  = "dirty_workspace_discard"
+)
+
+// dirtyCheckTimeout bounds the git work behind the warning and its buttons
+const dirtyCheckTimeout = 30 * time.Second
+
+// maxDirtyFilesShown caps the files listed in the warning
+const maxDirtyFilesShown = 10
+
+// workspaceEdits remembers which files runs edited in each repository, by
+// repository root and root-relative path, until they're committed, discarded,
+// or the user chooses to keep going
+type workspaceEdits struct {
+	mu    sync.Mutex
+	files map[string]map[string]bool
+}
+
+func newWorkspaceEdits() *workspaceEdits {
+	return &workspaceEdits{files: make(map[string]map[string]bool)}
+}
+
+func (w *workspaceEdits) record(root string, paths []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.files[root] == nil {
+		w.files[root] = make(map[string]bool)
+	}
+	for _, path := range paths {
+		w.files[root][path] = true
+	}
+}
+
+// pending returns the recorded files in root that are still uncommitted,
+// given the work tree's changed files, and forgets the rest
+func (w *workspaceEdits) pending(root string, changed []string) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	recorded := w.files[root]
+	if len(recorded) == 0 {
+		return nil
+	}
+	stillChanged := make(map[string]bool)
+	var files []string
+	for _, path := range changed {
+		if recorded[path] {
+			stillChanged[path] = true
+			files = append(files, path)
+		}
+	}
+	if len(stillChanged) == 0 {
+		delete(w.files, root)
+	} else {
+		w.files[root] = stillChanged
+	}
+	sort.Strings(files)
+	return files
+}
+
+func (w *workspaceEdits) forget(root string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.files, root)
+}
+
+// pendingDirtyRun is a request held back while its author decides what to do
+// with an earlier run's uncommitted changes
+type pendingDirtyRun struct {
+	id        int
+	event     slackevents.MessageEvent
+	text      string
+	overrides runOverrides
+	root      string
+	files     []string
+}
+
+// dirtyRuns holds each user's latest held request per channel
+type dirtyRuns struct {
+	mu     sync.Mutex
+	nextID int
+	held   map[string]*pendingDirtyRun
+}
+
+func newDirtyRuns() *dirtyRuns {
+	return &dirtyRuns{held: make(map[string]*pendingDirtyRun)}
+}
+
+func dirtyRunKey(channelID, userID string) string {
+	return channelID + ":" + userID
+}
+
+// hold keeps a request, replacing an earlier one, and returns its ID for the
+// buttons
+func (d *dirtyRuns) hold(run *pendingDirtyRun) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nextID++
+	run.id = d.nextID
+	d.held[dirtyRunKey(run.event.Channel, run.event.User)] = run
+	return run.id
+}
+
+// take returns and removes the held request a button was shown for, or nil
+// if it was replaced or already handled
+func (d *dirtyRuns) take(channelID, userID string, id int) *pendingDirtyRun {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := dirtyRunKey(channelID, userID)
+	run := d.held[key]
+	if run == nil || run.id != id {
+		return nil
+	}
+	delete(d.held, key)
+	return run
+}
+
+// editsAutoApplied reports whether runs in mode edit files without asking,
+// so their changes can pile up unnoticed
+func editsAutoApplied(mode config.PermissionMode) bool {
+	return mode == config.PermissionModeAcceptEdits || mode == config.PermissionModeBypassPerms
+}
+
+// repoRelativePaths returns the edited files inside the repository at root,
+// relative to it. Relative paths are taken from workDir.
+func repoRelativePaths(root, workDir string, files []string) []string {
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	var paths []string
+	for _, file := range files {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(workDir, file)
+		}
+		if resolved, err := filepath.EvalSymlinks(filepath.Dir(file)); err == nil {
+			file = filepath.Join(resolved, filepath.Base(file))
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+// recordRunEdits remembers the files a run edited in workDir's repository
+func (s *Service) recordRunEdits(workDir string, files []string) {
+	if !s.config.DirtyWorkspaceCheck || len(files) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dirtyCheckTimeout)
+	defer cancel()
+
+	root, err := git.Root(ctx, workDir)
+	if err != nil {
+		return
+	}
+	s.workspaceEdits.record(root, repoRelativePaths(root, workDir, files))
+}
+
+// checkDirtyWorkspace holds a run that would edit files without asking
+// while an earlier run's changes are still uncommitted, and asks the user
+// what to do with them. It returns true if the run may go ahead.
+func (s *Service) checkDirtyWorkspace(ctx context.Context, event *slackevents.MessageEvent, text string, overrides runOverrides, workDir string, mode config.PermissionMode) bool {
+	if !s.config.DirtyWorkspaceCheck || !editsAutoApplied(mode) {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(ctx, dirtyCheckTimeout)
+	defer cancel()
+
+	root, err := git.Root(ctx, workDir)
+	if err != nil {
+		return true
+	}
+	changed, err := git.ChangedFiles(ctx, root)
+	if err != nil {
+		s.logger.Warn("Failed to check the workspace for uncommitted changes",
+			zap.String("working_dir", workDir),
+			zap.Error(err))
+		return true
+	}
+	files := s.workspaceEdits.pending(root, changed)
+	if len(files) == 0 {
+		return true
+	}
+
+	id := s.dirtyRuns.hold(&pendingDirtyRun{event: *event, text: text, overrides: overrides, root: root, files: files})
+	s.logger.Info("Holding run until earlier uncommitted changes are handled",
+		zap.String("channel_id", event.Channel),
+		zap.String("user_id", event.User),
+		zap.String("repository", root),
+		zap.Int("files", len(files)))
+
+	err = s.sender.PostEphemeral(ctx, event.Channel, event.User,
+		slack.MsgOptionText(fmt.Sprintf("%s uncommitted from an earlier run", pluralize(len(files), "file")), false),
+		slack.MsgOptionBlocks(dirtyWorkspaceBlocks(root, files, id)...))
+	if err != nil {
+		s.logger.Error("Failed to post dirty workspace warning; running anyway",
+			zap.String("channel_id", event.Channel),
+			zap.Error(err))
+		s.dirtyRuns.take(event.Channel, event.User, id)
+		return true
+	}
+	return false
+}
+
+// dirtyWorkspaceBlocks lists the uncommitted files with continue, commit,
+// and discard buttons, each carrying the held run's ID
+func dirtyWorkspaceBlocks(root string, files []string, id int) []slack.Block {
+	var list strings.Builder
+	for i, file := range files {
+		if i == maxDirtyFilesShown {
+			list.WriteString(fmt.Sprintf("\n• _and %d more_", len(files)-i))
+			break
+		}
+		list.WriteString(fmt.Sprintf("\n• `%s`", file))
+	}
+
+	value := strconv.Itoa(id)
+	button := func(actionID, label string, style slack.Style) *slack.ButtonBlockElement {
+		b := slack.NewButtonBlockElement(actionID, value, slack.NewTextBlockObject(slack.PlainTextType, label, false, false))
+		b.Style = style
+		return b
+	}
+	discard := button(dirtyDiscardActionID, "Discard", slack.StyleDanger)
+	discard.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject(slack.PlainTextType, "Discard changes?", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, fmt.Sprintf("This reverts %s. It can't be undone.", pluralize(len(files), "file")), false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Discard", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false))
+
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf("⚠️ *%s uncommitted from an earlier run* in `%s`%s", pluralize(len(files), "file"), root, list.String()),
+				false, false),
+			nil, nil),
+		slack.NewActionBlock("dirty_workspace_actions",
+			button(dirtyContinueActionID, "Continue", slack.StylePrimary),
+			button(dirtyCommitActionID, "Commit", slack.StyleDefault),
+			discard),
+		slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, "Your request runs once you choose. *Continue* keeps the changes and adds to them; *Commit* commits just these files first.", false, false)),
+	}
+}
+
+// handleDirtyWorkspaceAction applies the user's choice to the earlier run's
+// changes and runs the request that was held back
+func (s *Service) handleDirtyWorkspaceAction(callback *slack.InteractionCallback, action *slack.BlockAction) {
+	channelID := callback.Channel.ID
+	userID := callback.User.ID
+
+	id, err := strconv.Atoi(action.Value)
+	if err != nil {
+		return
+	}
+	run := s.dirtyRuns.take(channelID, userID, id)
+	if run == nil {
+		s.postEphemeral(channelID, userID, "ℹ️ That request was already handled or replaced by a newer one.")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dirtyCheckTimeout)
+	note := ""
+	switch action.ActionID {
+	case dirtyCommitActionID:
+		message := fmt.Sprintf("Changes from an earlier Claude run\n\nRequested in Slack by %s", userID)
+		if _, err = git.CommitPaths(ctx, run.root, message, run.files); err == nil {
+			note = fmt.Sprintf("✅ **Committed %s.** Running your request now.", pluralize(len(run.files), "file"))
+		}
+	case dirtyDiscardActionID:
+		if err = git.DiscardPaths(ctx, run.root, run.files); err == nil {
+			note = fmt.Sprintf("🗑️ **Discarded changes to %s.** Running your request now.", pluralize(len(run.files), "file"))
+		}
+	default:
+		note = "▶️ **Keeping the changes.** Running your request now."
+	}
+	cancel()
+
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "dirty_workspace", action.ActionID)
+		s.postEphemeral(channelID, userID, s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to update the workspace; your request was not run"))
+		return
+	}
+
+	s.workspaceEdits.forget(run.root)
+	s.logger.Info("Handled earlier uncommitted changes",
+		zap.String("channel_id", channelID),
+		zap.String("user_id", userID),
+		zap.String("repository", run.root),
+		zap.String("action", action.ActionID),
+		zap.Int("files", len(run.files)))
+
+	s.postEphemeral(channelID, userID, note)
+	if response := s.processClaudeMessage(context.Background(), &run.event, run.text, run.overrides); response != "" {
+		s.sendThreadResponseOrHold(run.event.Channel, run.event.ThreadTimeStamp, response)
+	}
+}
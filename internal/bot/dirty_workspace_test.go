@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+func TestWorkspaceEdits_Pending(t *testing.T) {
+	edits := newWorkspaceEdits()
+	edits.record("/repo", []string{"a.go", "b.go", "c.go"})
+
+	// b.go was committed by hand since; notes.txt was never Claude's
+	got := edits.pending("/repo", []string{"a.go", "c.go", "notes.txt"})
+	if want := []string{"a.go", "c.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pending() = %v, want %v", got, want)
+	}
+
+	if got := edits.pending("/repo", []string{"notes.txt"}); len(got) != 0 {
+		t.Errorf("Expected nothing pending once Claude's files are clean, got %v", got)
+	}
+	edits.record("/repo", []string{"a.go"})
+	if got := edits.pending("/repo", []string{"a.go"}); len(got) != 1 {
+		t.Errorf("Expected later edits to be tracked again, got %v", got)
+	}
+	edits.forget("/repo")
+	if got := edits.pending("/repo", []string{"a.go"}); len(got) != 0 {
+		t.Errorf("Expected forget to clear the repository, got %v", got)
+	}
+}
+
+func TestDirtyRuns_TakeOnlyTheCurrentHold(t *testing.T) {
+	runs := newDirtyRuns()
+	first := runs.hold(&pendingDirtyRun{event: slackevents.MessageEvent{Channel: "C1", User: "U1"}, text: "first"})
+	second := runs.hold(&pendingDirtyRun{event: slackevents.MessageEvent{Channel: "C1", User: "U1"}, text: "second"})
+
+	if run := runs.take("C1", "U1", first); run != nil {
+		t.Errorf("Expected a replaced hold's button to do nothing, got %q", run.text)
+	}
+	if run := runs.take("C1", "U2", second); run != nil {
+		t.Error("Expected another user's click to do nothing")
+	}
+	if run := runs.take("C1", "U1", second); run == nil || run.text != "second" {
+		t.Errorf("Expected the latest hold, got %v", run)
+	}
+	if run := runs.take("C1", "U1", second); run != nil {
+		t.Error("Expected a hold to be taken once")
+	}
+}
+
+func TestEditsAutoApplied(t *testing.T) {
+	for mode, want := range map[config.PermissionMode]bool{
+		config.PermissionModeDefault:     false,
+		config.PermissionModePlan:        false,
+		config.PermissionModeAcceptEdits: true,
+		config.PermissionModeBypassPerms: true,
+	} {
+		if got := editsAutoApplied(mode); got != want {
+			t.Errorf("editsAutoApplied(%s) = %v, want %v", mode, got, want)
+		}
+	}
+}
+
+func TestRepoRelativePaths(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "service")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got := repoRelativePaths(root, sub, []string{
+		filepath.Join(sub, "main.go"),
+		"handler.go",
+		"/etc/hosts",
+	})
+	if want := []string{"service/main.go", "service/handler.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("repoRelativePaths() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckDirtyWorkspace_OnlyHoldsForEarlierEdits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{{"init", "-q"}, {"config", "user.email", "t@example.com"}, {"config", "user.name", "T"}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mine.txt"), []byte("user's own change\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Service{
+		config:         &config.Config{DirtyWorkspaceCheck: true},
+		logger:         zap.NewNop(),
+		workspaceEdits: newWorkspaceEdits(),
+		dirtyRuns:      newDirtyRuns(),
+	}
+	event := &slackevents.MessageEvent{Channel: "C1", User: "U1"}
+
+	if !s.checkDirtyWorkspace(context.Background(), event, "go", runOverrides{}, dir, config.PermissionModeAcceptEdits) {
+		t.Error("Expected changes no run made to be left to the user")
+	}
+
+	s.recordRunEdits(dir, []string{filepath.Join(dir, "mine.txt")})
+	if !s.checkDirtyWorkspace(context.Background(), event, "go", runOverrides{}, dir, config.PermissionModeDefault) {
+		t.Error("Expected runs that ask before editing not to be held")
+	}
+	s.config.DirtyWorkspaceCheck = false
+	if !s.checkDirtyWorkspace(context.Background(), event, "go", runOverrides{}, dir, config.PermissionModeBypassPerms) {
+		t.Error("Expected DIRTY_WORKSPACE_CHECK=false to turn the check off")
+	}
+}
+
+func TestDirtyWorkspaceBlocks(t *testing.T) {
+	files := make([]string, 12)
+	for i := range files {
+		files[i] = string(rune('a'+i)) + ".go"
+	}
+	blocks := dirtyWorkspaceBlocks("/srv/app", files, 7)
+
+	section := blocks[0].(*slack.SectionBlock).Text.Text
+	if !strings.Contains(section, "12 files uncommitted from an earlier run") || !strings.Contains(section, "_and 2 more_") {
+		t.Errorf("Unexpected warning text %q", section)
+	}
+
+	actions := blocks[1].(*slack.ActionBlock).Elements.ElementSet
+	var ids []string
+	for _, element := range actions {
+		button := element.(*slack.ButtonBlockElement)
+		if button.Value != "7" {
+			t.Errorf("Expected every button to carry the hold ID, %s has %q", button.ActionID, button.Value)
+		}
+		ids = append(ids, button.ActionID)
+	}
+	if want := []string{dirtyContinueActionID, dirtyCommitActionID, dirtyDiscardActionID}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Buttons = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd
+
+package bot
+
+// freeDiskBytes can't check free space on this platform; supported is false
+func freeDiskBytes(path string) (uint64, bool, error) {
+	return 0, false, nil
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin || freebsd
+
+package bot
+
+import "syscall"
+
+// freeDiskBytes returns the disk space available to unprivileged users on
+// the filesystem holding path
+func freeDiskBytes(path string) (uint64, bool, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, true, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true, nil
+}
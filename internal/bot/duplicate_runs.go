@@ -0,0 +1,242 @@
+package bot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+)
+
+// Buttons on the duplicate run prompt
+const (
+	duplicateQueueActionID = "duplicate_run_queue"
+	duplicateDropActionID  = "duplicate_run_drop"
+)
+
+// inflightPrompt is a run in progress, identified by what was asked
+type inflightPrompt struct {
+	fingerprint string
+	messageTS   string
+	started     time.Time
+}
+
+// inflightPrompts tracks each user's runs in progress per channel, so a
+// prompt sent twice (a double Enter, a resent message) can be caught before
+// it starts a second run
+type inflightPrompts struct {
+	mu     sync.Mutex
+	nextID int
+	runs   map[string]map[int]inflightPrompt
+}
+
+func newInflightPrompts() *inflightPrompts {
+	return &inflightPrompts{runs: make(map[string]map[int]inflightPrompt)}
+}
+
+// duplicateCheck is the outcome of starting to track a prompt
+type duplicateCheck int
+
+const (
+	notDuplicate    duplicateCheck = iota
+	duplicatePrompt                // Same prompt as a run in progress; ask first
+	redelivered                    // The same message delivered again; drop it
+)
+
+// start tracks a prompt unless the user already has the same one in
+// progress in the channel, started within window. done stops tracking it;
+// it is nil for duplicates.
+func (p *inflightPrompts) start(channelID, userID string, prompt inflightPrompt, window time.Duration) (done func(), check duplicateCheck) {
+	key := dirtyRunKey(channelID, userID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, run := range p.runs[key] {
+		if run.fingerprint != prompt.fingerprint {
+			continue
+		}
+		if prompt.messageTS != "" && run.messageTS == prompt.messageTS {
+			return nil, redelivered
+		}
+		if prompt.started.Sub(run.started) < window {
+			return nil, duplicatePrompt
+		}
+	}
+
+	p.nextID++
+	id := p.nextID
+	if p.runs[key] == nil {
+		p.runs[key] = make(map[int]inflightPrompt)
+	}
+	p.runs[key][id] = prompt
+
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.runs[key], id)
+		if len(p.runs[key]) == 0 {
+			delete(p.runs, key)
+		}
+	}, notDuplicate
+}
+
+// promptFingerprint identifies a prompt by its text, ignoring case and
+// spacing, and the files attached to it
+func promptFingerprint(text string, files []slackevents.File) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	fileIDs := make([]string, 0, len(files))
+	for _, file := range files {
+		fileIDs = append(fileIDs, file.ID)
+	}
+	sort.Strings(fileIDs)
+
+	sum := sha256.Sum256([]byte(normalized + "\x00" + strings.Join(fileIDs, ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+// pendingDuplicateRun is a prompt held back while its author decides
+// whether it really should run twice
+type pendingDuplicateRun struct {
+	id        int
+	event     slackevents.MessageEvent
+	text      string
+	overrides runOverrides
+}
+
+// duplicateRuns holds each user's latest held duplicate per channel
+type duplicateRuns struct {
+	mu     sync.Mutex
+	nextID int
+	held   map[string]*pendingDuplicateRun
+}
+
+func newDuplicateRuns() *duplicateRuns {
+	return &duplicateRuns{held: make(map[string]*pendingDuplicateRun)}
+}
+
+// hold keeps a prompt, replacing an earlier one, and returns its ID for the
+// buttons
+func (d *duplicateRuns) hold(run *pendingDuplicateRun) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nextID++
+	run.id = d.nextID
+	d.held[dirtyRunKey(run.event.Channel, run.event.User)] = run
+	return run.id
+}
+
+// take returns and removes the held prompt a button was shown for, or nil
+// if it was replaced or already handled
+func (d *duplicateRuns) take(channelID, userID string, id int) *pendingDuplicateRun {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := dirtyRunKey(channelID, userID)
+	run := d.held[key]
+	if run == nil || run.id != id {
+		return nil
+	}
+	delete(d.held, key)
+	return run
+}
+
+// trackPrompt starts tracking a prompt as in progress. If the same user
+// already has it running in the channel, the prompt is held and they're
+// asked whether to queue it anyway; ok is false and the run must not go
+// ahead. done must be called once the run is over.
+func (s *Service) trackPrompt(ctx context.Context, event *slackevents.MessageEvent, text string, overrides runOverrides) (done func(), ok bool) {
+	if s.config.DuplicateRunWindow <= 0 || overrides.AllowDuplicate {
+		return func() {}, true
+	}
+
+	prompt := inflightPrompt{
+		fingerprint: promptFingerprint(text, event.Files),
+		messageTS:   event.TimeStamp,
+		started:     time.Now(),
+	}
+	done, check := s.inflightPrompts.start(event.Channel, event.User, prompt, s.config.DuplicateRunWindow)
+	switch check {
+	case notDuplicate:
+		return done, true
+	case redelivered:
+		s.logger.Info("Ignoring a message delivered again while its run is in progress",
+			zap.String("channel_id", event.Channel),
+			zap.String("user_id", event.User),
+			zap.String("message_ts", event.TimeStamp))
+		return nil, false
+	}
+
+	id := s.duplicateRuns.hold(&pendingDuplicateRun{event: *event, text: text, overrides: overrides})
+	s.logger.Info("Holding a prompt that duplicates a run in progress",
+		zap.String("channel_id", event.Channel),
+		zap.String("user_id", event.User))
+
+	err := s.sender.PostEphemeral(ctx, event.Channel, event.User,
+		slack.MsgOptionText("Looks like a duplicate of a run already in progress. Queue anyway?", false),
+		slack.MsgOptionBlocks(duplicateRunBlocks(text, id)...))
+	if err != nil {
+		s.logger.Error("Failed to ask about a duplicate prompt; running it anyway",
+			zap.String("channel_id", event.Channel),
+			zap.Error(err))
+		s.duplicateRuns.take(event.Channel, event.User, id)
+		return func() {}, true
+	}
+	return nil, false
+}
+
+// duplicateRunBlocks asks whether to queue a repeated prompt, with buttons
+// carrying the held prompt's ID
+func duplicateRunBlocks(text string, id int) []slack.Block {
+	value := strconv.Itoa(id)
+	queue := slack.NewButtonBlockElement(duplicateQueueActionID, value, slack.NewTextBlockObject(slack.PlainTextType, "Queue anyway", false, false))
+	queue.Style = slack.StylePrimary
+	drop := slack.NewButtonBlockElement(duplicateDropActionID, value, slack.NewTextBlockObject(slack.PlainTextType, "Drop it", false, false))
+
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType,
+				"🔁 *Looks like a duplicate of a run already in progress.* Queue anyway?\n> "+truncatePrompt(text),
+				false, false),
+			nil, nil),
+		slack.NewActionBlock("duplicate_run_actions", queue, drop),
+	}
+}
+
+// handleDuplicateRunAction runs or drops a held duplicate prompt
+func (s *Service) handleDuplicateRunAction(callback *slack.InteractionCallback, action *slack.BlockAction) {
+	channelID := callback.Channel.ID
+	userID := callback.User.ID
+
+	id, err := strconv.Atoi(action.Value)
+	if err != nil {
+		return
+	}
+	run := s.duplicateRuns.take(channelID, userID, id)
+	if run == nil {
+		s.postEphemeral(channelID, userID, "ℹ️ That prompt was already handled or replaced by a newer one.")
+		return
+	}
+
+	if action.ActionID == duplicateDropActionID {
+		s.logger.Info("Duplicate prompt dropped",
+			zap.String("channel_id", channelID),
+			zap.String("user_id", userID))
+		s.postEphemeral(channelID, userID, "🗑️ **Dropped.** The run already in progress will reply.")
+		return
+	}
+
+	s.logger.Info("Duplicate prompt queued anyway",
+		zap.String("channel_id", channelID),
+		zap.String("user_id", userID))
+	s.postEphemeral(channelID, userID, "▶️ **Queued anyway.** It starts as soon as a run slot is free.")
+	run.overrides.AllowDuplicate = true
+	if response := s.processClaudeMessage(context.Background(), &run.event, run.text, run.overrides); response != "" {
+		s.sendThreadResponseOrHold(run.event.Channel, run.event.ThreadTimeStamp, response)
+	}
+}
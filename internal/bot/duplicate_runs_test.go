@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestPromptFingerprint(t *testing.T) {
+	base := promptFingerprint("Fix the failing test", nil)
+	if got := promptFingerprint("  fix the\nFAILING   test ", nil); got != base {
+		t.Error("Expected case and spacing to be ignored")
+	}
+	if got := promptFingerprint("Fix the failing tests", nil); got == base {
+		t.Error("Expected a different prompt to have a different fingerprint")
+	}
+
+	withFiles := promptFingerprint("Fix the failing test", []slackevents.File{{ID: "F2"}, {ID: "F1"}})
+	if withFiles == base {
+		t.Error("Expected attached files to count")
+	}
+	if got := promptFingerprint("Fix the failing test", []slackevents.File{{ID: "F1"}, {ID: "F2"}}); got != withFiles {
+		t.Error("Expected file order to be ignored")
+	}
+}
+
+func TestInflightPrompts_Start(t *testing.T) {
+	prompts := newInflightPrompts()
+	now := time.Now()
+	fp := promptFingerprint("deploy", nil)
+	window := 2 * time.Minute
+
+	done, check := prompts.start("C1", "U1", inflightPrompt{fingerprint: fp, messageTS: "1.1", started: now}, window)
+	if check != notDuplicate || done == nil {
+		t.Fatalf("Expected the first prompt to run, got %v", check)
+	}
+
+	if _, check := prompts.start("C1", "U1", inflightPrompt{fingerprint: fp, messageTS: "1.1", started: now}, window); check != redelivered {
+		t.Errorf("Expected the same message to be a redelivery, got %v", check)
+	}
+	if _, check := prompts.start("C1", "U1", inflightPrompt{fingerprint: fp, messageTS: "1.2", started: now.Add(time.Second)}, window); check != duplicatePrompt {
+		t.Errorf("Expected a resent prompt to be a duplicate, got %v", check)
+	}
+	if _, check := prompts.start("C1", "U2", inflightPrompt{fingerprint: fp, messageTS: "1.3", started: now}, window); check != notDuplicate {
+		t.Errorf("Expected another user's prompt to run, got %v", check)
+	}
+	if _, check := prompts.start("C2", "U1", inflightPrompt{fingerprint: fp, messageTS: "1.4", started: now}, window); check != notDuplicate {
+		t.Errorf("Expected the prompt in another channel to run, got %v", check)
+	}
+	later, check := prompts.start("C1", "U1", inflightPrompt{fingerprint: fp, messageTS: "1.5", started: now.Add(window)}, window)
+	if check != notDuplicate {
+		t.Errorf("Expected a prompt outside the window to run, got %v", check)
+	}
+	later()
+
+	done()
+	if _, check := prompts.start("C1", "U1", inflightPrompt{fingerprint: fp, messageTS: "1.6", started: now.Add(time.Second)}, window); check != notDuplicate {
+		t.Errorf("Expected the prompt to run once the first run is over, got %v", check)
+	}
+}
+
+func TestDuplicateRuns_TakeOnlyTheCurrentHold(t *testing.T) {
+	runs := newDuplicateRuns()
+	first := runs.hold(&pendingDuplicateRun{event: slackevents.MessageEvent{Channel: "C1", User: "U1"}, text: "first"})
+	second := runs.hold(&pendingDuplicateRun{event: slackevents.MessageEvent{Channel: "C1", User: "U1"}, text: "second"})
+
+	if run := runs.take("C1", "U1", first); run != nil {
+		t.Errorf("Expected a replaced hold's button to do nothing, got %q", run.text)
+	}
+	if run := runs.take("C1", "U1", second); run == nil || run.text != "second" {
+		t.Errorf("Expected the latest hold, got %v", run)
+	}
+}
@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/git"
+)
+
+// gitCommandTimeout bounds the git calls made around a run
+const gitCommandTimeout = 30 * time.Second
+
+// snapshotWorkDir records the work tree state before a run so edits can be
+// diffed afterwards. It returns "" when diffs are disabled or unavailable.
+func (s *Service) snapshotWorkDir(workDir string) string {
+	if !s.config.EditDiffAttachments {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), gitCommandTimeout)
+	defer cancel()
+
+	base, err := git.Snapshot(ctx, workDir)
+	if err != nil {
+		if !errors.Is(err, git.ErrNotRepository) {
+			s.logger.Warn("Failed to snapshot working directory", zap.String("work_dir", workDir), zap.Error(err))
+		}
+		return ""
+	}
+	return base
+}
+
+// attachEditDiffs uploads a unified diff of the files a run edited as a
+// snippet in the thread of the prompt
+func (s *Service) attachEditDiffs(channelID, threadTS, userID, workDir, base string, editedFiles []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitCommandTimeout)
+	defer cancel()
+
+	diff, err := git.DiffFiles(ctx, workDir, base, editedFiles)
+	if err != nil {
+		s.logger.Error("Failed to diff edited files",
+			zap.String("channel_id", channelID),
+			zap.String("work_dir", workDir),
+			zap.Error(err))
+		return
+	}
+	if strings.TrimSpace(diff) == "" {
+		return
+	}
+
+	content := s.config.ScrubSecrets(diff)
+	title := fmt.Sprintf("Changes to %d files", len(editedFiles))
+	if len(editedFiles) == 1 {
+		title = fmt.Sprintf("Changes to %s", filepath.Base(editedFiles[0]))
+	}
+
+	_, err = s.api().UploadFileV2(slack.UploadFileV2Parameters{
+		Content:         content,
+		FileSize:        len(content),
+		Filename:        fmt.Sprintf("claude-changes-%s.diff", time.Now().UTC().Format("20060102-150405")),
+		Title:           title,
+		Channel:         channelID,
+		ThreadTimestamp: threadTS,
+	})
+	if err != nil {
+		s.logger.Error("Failed to upload edit diff",
+			zap.String("channel_id", channelID),
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return
+	}
+
+	s.logger.Info("Edit diff attached",
+		zap.String("channel_id", channelID),
+		zap.String("thread_ts", threadTS),
+		zap.Int("files", len(editedFiles)),
+		zap.Int("size", len(content)))
+}
@@ -0,0 +1,242 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+const emojiUsage = "**Usage:** `/emoji list` | `/emoji set :emoji: <prompt template>` | `/emoji remove :emoji:`\n\n" +
+	"Templates can use `{message}` (the reacted message), `{author}` (who posted it), and `{user}` (who reacted)."
+
+// maxEmojiTemplateLength keeps templates to a prompt, not a document
+const maxEmojiTemplateLength = 2000
+
+// Placeholders expanded in emoji prompt templates
+const (
+	emojiMessagePlaceholder = "{message}"
+	emojiAuthorPlaceholder  = "{author}"
+	emojiUserPlaceholder    = "{user}"
+)
+
+var (
+	emojiNamePattern        = regexp.MustCompile(`^[a-z0-9_+'-]+$`)
+	emojiPlaceholderPattern = regexp.MustCompile(`\{[a-z_]+\}`)
+)
+
+// normalizeEmoji turns ":Translate-JP:" or "thumbsup::skin-tone-2" into the
+// reaction name stored in the mapping table, or "" if it isn't an emoji name
+func normalizeEmoji(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.Trim(name, ":")
+	if i := strings.Index(name, "::"); i >= 0 {
+		name = name[:i]
+	}
+	if !emojiNamePattern.MatchString(name) {
+		return ""
+	}
+	return name
+}
+
+// parseEmojiMapping extracts the emoji and template from
+// "/emoji set :explain: Explain this message"
+func parseEmojiMapping(text string) (string, string, error) {
+	text = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "set"))
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", "", fmt.Errorf("no emoji given")
+	}
+	emoji := normalizeEmoji(fields[0])
+	if emoji == "" {
+		return "", "", fmt.Errorf("%q isn't an emoji name", fields[0])
+	}
+
+	template := strings.TrimSpace(strings.TrimPrefix(text, fields[0]))
+	if template == "" {
+		return "", "", fmt.Errorf("no prompt template given")
+	}
+	if len(template) > maxEmojiTemplateLength {
+		return "", "", fmt.Errorf("template is %d characters; keep it under %d", len(template), maxEmojiTemplateLength)
+	}
+	for _, placeholder := range emojiPlaceholderPattern.FindAllString(template, -1) {
+		switch placeholder {
+		case emojiMessagePlaceholder, emojiAuthorPlaceholder, emojiUserPlaceholder:
+		default:
+			return "", "", fmt.Errorf("unknown placeholder %s", placeholder)
+		}
+	}
+	return emoji, template, nil
+}
+
+// expandEmojiPrompt fills in a template. A template without {message} gets
+// the message appended, so it always reaches Claude.
+func expandEmojiPrompt(template, message, authorID, userID string) string {
+	quoted := fmt.Sprintf("\n```\n%s\n```\n", message)
+	prompt := strings.NewReplacer(
+		emojiMessagePlaceholder, quoted,
+		emojiAuthorPlaceholder, fmt.Sprintf("<@%s>", authorID),
+		emojiUserPlaceholder, fmt.Sprintf("<@%s>", userID),
+	).Replace(template)
+
+	if !strings.Contains(template, emojiMessagePlaceholder) {
+		prompt += fmt.Sprintf("\n\nThe Slack message, posted by <@%s>:%s", authorID, quoted)
+	}
+	return strings.TrimSpace(prompt)
+}
+
+// handleEmojiCommand handles /emoji list, set, and remove. Anyone can list
+// the mappings; only admins can change them.
+func (s *Service) handleEmojiCommand(ctx context.Context, req *commands.Request) (string, error) {
+	switch req.Args[0] {
+	case "list":
+		return s.handleEmojiListCommand(ctx, req), nil
+	case "set", "remove":
+		if !s.authService.IsUserAdmin(req.UserID) {
+			return "❌ **Admin Only**\n\nOnly admins can change emoji prompts.", nil
+		}
+		if req.Args[0] == "remove" {
+			if len(req.Args) < 2 || normalizeEmoji(req.Args[1]) == "" {
+				return emojiUsage, nil
+			}
+			return s.handleEmojiRemoveCommand(ctx, req, normalizeEmoji(req.Args[1])), nil
+		}
+		emoji, template, err := parseEmojiMapping(req.Text)
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid emoji prompt:** %v\n\n%s", err, emojiUsage), nil
+		}
+		return s.handleEmojiSetCommand(ctx, req, emoji, template), nil
+	}
+	return emojiUsage, nil
+}
+
+func (s *Service) handleEmojiListCommand(ctx context.Context, req *commands.Request) string {
+	prompts, err := s.emojiPrompts.ListEmojiPrompts()
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "emoji_command", "list_emoji_prompts")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list emoji prompts")
+	}
+	if len(prompts) == 0 {
+		return "ℹ️ **No emoji prompts yet**\n\nAdmins can add one with `/emoji set :emoji: <prompt template>`."
+	}
+
+	var response strings.Builder
+	response.WriteString("😀 **Emoji Prompts**\n")
+	for _, p := range prompts {
+		fmt.Fprintf(&response, "\n• :%s: `%s`", p.Emoji, truncatePrompt(p.Template))
+	}
+	response.WriteString("\n\nReact with one on a message to run its prompt; the answer is posted in the message's thread.")
+	return response.String()
+}
+
+func (s *Service) handleEmojiSetCommand(ctx context.Context, req *commands.Request, emoji, template string) string {
+	if err := s.emojiPrompts.SetEmojiPrompt(emoji, template, req.UserID); err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "emoji_command", "set_emoji_prompt")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to set emoji prompt")
+	}
+	return fmt.Sprintf("✅ **Emoji prompt set**\n\nReacting with :%s: on a message now runs:\n```\n%s\n```", emoji, template)
+}
+
+func (s *Service) handleEmojiRemoveCommand(ctx context.Context, req *commands.Request, emoji string) string {
+	removed, err := s.emojiPrompts.DeleteEmojiPrompt(emoji)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "emoji_command", "delete_emoji_prompt")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to remove emoji prompt")
+	}
+	if !removed {
+		return fmt.Sprintf("ℹ️ No prompt is mapped to :%s:", emoji)
+	}
+
+	s.logger.Info("Emoji prompt removed",
+		zap.String("emoji", emoji),
+		zap.String("user_id", req.UserID))
+	return fmt.Sprintf("✅ **Emoji prompt for :%s: removed**", emoji)
+}
+
+// handleReactionAddedEvent runs the prompt mapped to a reaction, if any, on
+// the reacted message
+func (s *Service) handleReactionAddedEvent(event *slackevents.ReactionAddedEvent) {
+	if event.User == s.botUserID || event.Item.Type != "message" || !s.readiness.Ready() {
+		return
+	}
+	emoji := normalizeEmoji(event.Reaction)
+	if emoji == "" {
+		return
+	}
+
+	mapping, err := s.emojiPrompts.GetEmojiPrompt(emoji)
+	if err != nil {
+		s.logger.Warn("Failed to look up emoji prompt", zap.String("emoji", emoji), zap.Error(err))
+		return
+	}
+	if mapping == nil {
+		return
+	}
+
+	channelID := event.Item.Channel
+	s.logger.Info("Emoji prompt triggered",
+		zap.String("emoji", emoji),
+		zap.String("user_id", event.User),
+		zap.String("channel_id", channelID))
+
+	authCtx := &auth.AuthContext{
+		UserID:    event.User,
+		ChannelID: channelID,
+		Command:   "reaction:" + emoji,
+		Timestamp: time.Now(),
+	}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionExecute); err != nil {
+		s.logger.Warn("Emoji prompt authorization failed", zap.Error(err))
+		s.postEphemeral(channelID, event.User, fmt.Sprintf("❌ Authorization failed: %v", err))
+		return
+	}
+	if limited := s.checkRateLimit(event.User, channelID); limited != nil {
+		s.postEphemeral(channelID, event.User, s.rateLimitMessage(event.User, limited))
+		return
+	}
+
+	message, err := s.fetchMessage(channelID, event.Item.Timestamp)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, event.User, "emoji_prompt", "fetch_message")
+		s.postEphemeral(channelID, event.User, s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to read the reacted message"))
+		return
+	}
+
+	// Reply in the existing thread, or start one under the reacted message
+	threadTS := message.ThreadTimestamp
+	if threadTS == "" {
+		threadTS = message.Timestamp
+	}
+
+	prompt := expandEmojiPrompt(mapping.Template, message.Text, messageAuthor(*message), event.User)
+	go s.runMessagePrompt(context.Background(), event.User, channelID, *message, threadTS, prompt)
+}
+
+// fetchMessage returns a channel message or thread reply by timestamp
+func (s *Service) fetchMessage(channelID, ts string) (*slack.Message, error) {
+	replies, _, _, err := s.api().GetConversationReplies(&slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: ts,
+		Oldest:    ts,
+		Inclusive: true,
+		Limit:     2,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range replies {
+		if replies[i].Timestamp == ts {
+			return &replies[i], nil
+		}
+	}
+	return nil, fmt.Errorf("message not found")
+}
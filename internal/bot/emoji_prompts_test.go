@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeEmoji(t *testing.T) {
+	tests := map[string]string{
+		":explain:":             "explain",
+		"Translate-JP":          "translate-jp",
+		"thumbsup::skin-tone-2": "thumbsup",
+		":+1::skin-tone-3:":     "+1",
+		"  :white_check_mark: ": "white_check_mark",
+		"not an emoji":          "",
+		"::":                    "",
+		":<script>:":            "",
+	}
+	for in, want := range tests {
+		if got := normalizeEmoji(in); got != want {
+			t.Errorf("normalizeEmoji(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseEmojiMapping(t *testing.T) {
+	emoji, template, err := parseEmojiMapping("set :translate-jp: Translate {message} into Japanese for {user}")
+	if err != nil {
+		t.Fatalf("parseEmojiMapping() error = %v", err)
+	}
+	if emoji != "translate-jp" || template != "Translate {message} into Japanese for {user}" {
+		t.Errorf("parseEmojiMapping() = %q, %q", emoji, template)
+	}
+
+	for _, text := range []string{
+		"set",
+		"set :explain:",
+		"set <explain> Explain this",
+		"set :explain: Explain {text}",
+		"set :explain: " + strings.Repeat("x", maxEmojiTemplateLength+1),
+	} {
+		if _, _, err := parseEmojiMapping(text); err == nil {
+			t.Errorf("parseEmojiMapping(%q) expected an error", text)
+		}
+	}
+}
+
+func TestExpandEmojiPrompt(t *testing.T) {
+	got := expandEmojiPrompt("Translate {message} into Japanese for {user}", "deploy is done", "U1", "U2")
+	want := "Translate \n```\ndeploy is done\n```\n into Japanese for <@U2>"
+	if got != want {
+		t.Errorf("expandEmojiPrompt() = %q, want %q", got, want)
+	}
+
+	got = expandEmojiPrompt("Explain this", "panic: nil map", "U1", "U2")
+	if !strings.HasPrefix(got, "Explain this\n\nThe Slack message, posted by <@U1>:") || !strings.Contains(got, "panic: nil map") {
+		t.Errorf("Expected the message appended to a template without {message}, got %q", got)
+	}
+}
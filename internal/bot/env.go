@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+const envUsage = "**Usage:** `/env list` | `/env set <NAME> <value>` | `/env unset <NAME>`"
+
+// envNamePattern matches valid environment variable names
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseEnvAssignment splits "NAME value" or "NAME=value" into a name and value
+func parseEnvAssignment(text string) (name, value string, err error) {
+	text = strings.TrimSpace(text)
+	if eq := strings.Index(text, "="); eq > 0 && !strings.ContainsAny(text[:eq], " \t") {
+		name, value = text[:eq], text[eq+1:]
+	} else if fields := strings.SplitN(text, " ", 2); len(fields) == 2 {
+		name, value = fields[0], strings.TrimSpace(fields[1])
+	} else {
+		return "", "", fmt.Errorf("expected a name and a value")
+	}
+
+	if !envNamePattern.MatchString(name) {
+		return "", "", fmt.Errorf("`%s` is not a valid variable name", name)
+	}
+	if value == "" {
+		return "", "", fmt.Errorf("the value is empty; use `/env unset %s` to remove it", name)
+	}
+	return name, value, nil
+}
+
+// handleEnvCommand handles /env list, /env set, and /env unset
+func (s *Service) handleEnvCommand(ctx context.Context, req *commands.Request) (string, error) {
+	if s.envCipher == nil {
+		return "❌ **Channel environment variables are disabled**\n\nSet `ENV_ENCRYPTION_KEY` (e.g. from `openssl rand -base64 32`) and restart the bot.", nil
+	}
+
+	switch req.Args[0] {
+	case "list":
+		return s.handleEnvListCommand(ctx, req), nil
+	case "set":
+		rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(req.Text), "set"))
+		name, value, err := parseEnvAssignment(rest)
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid variable:** %v\n\n%s", err, envUsage), nil
+		}
+		return s.handleEnvSetCommand(ctx, req, name, value), nil
+	case "unset":
+		if len(req.Args) != 2 {
+			return "❌ **Invalid arguments**\n\n" + envUsage, nil
+		}
+		return s.handleEnvUnsetCommand(ctx, req, req.Args[1]), nil
+	}
+	return envUsage, nil
+}
+
+func (s *Service) handleEnvSetCommand(ctx context.Context, req *commands.Request, name, value string) string {
+	sealed, err := s.envCipher.Seal(value)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "env_command", "encrypt_value")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to encrypt value")
+	}
+	if err := s.envVars.SetChannelEnvVar(req.ChannelID, name, sealed, req.UserID); err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "env_command", "set_env_var")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to set environment variable")
+	}
+	return fmt.Sprintf("✅ **`%s` set for this channel**\n\nClaude runs in this channel get it from the next message on.", name)
+}
+
+func (s *Service) handleEnvUnsetCommand(ctx context.Context, req *commands.Request, name string) string {
+	removed, err := s.envVars.UnsetChannelEnvVar(req.ChannelID, name)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "env_command", "unset_env_var")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to unset environment variable")
+	}
+	if !removed {
+		return fmt.Sprintf("ℹ️ `%s` isn't set in this channel", name)
+	}
+
+	s.logger.Info("Channel env var unset",
+		zap.String("channel_id", req.ChannelID),
+		zap.String("name", name),
+		zap.String("user_id", req.UserID))
+	return fmt.Sprintf("✅ **`%s` unset for this channel**", name)
+}
+
+// handleEnvListCommand lists a channel's variables; values are never shown
+func (s *Service) handleEnvListCommand(ctx context.Context, req *commands.Request) string {
+	vars, err := s.envVars.ListChannelEnvVars(req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "env_command", "list_env_vars")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list environment variables")
+	}
+	if len(vars) == 0 {
+		return "📋 **No environment variables set in this channel**\n\n" + envUsage
+	}
+
+	var response strings.Builder
+	response.WriteString("📋 **Channel Environment Variables**\n")
+	for _, envVar := range vars {
+		response.WriteString(fmt.Sprintf("\n• `%s` — set %s", envVar.Name, s.userTime(req.UserID, envVar.UpdatedAt).Format("Jan 2 15:04")))
+		if envVar.UpdatedBy != nil {
+			response.WriteString(fmt.Sprintf(" by <@%s>", *envVar.UpdatedBy))
+		}
+	}
+	response.WriteString("\n\nValues are stored encrypted and never shown.")
+	return response.String()
+}
+
+// channelEnvironment returns a channel's variables as KEY=VALUE pairs for the
+// Claude CLI. Values that can't be decrypted, e.g. after the key changed, are
+// skipped and logged.
+func (s *Service) channelEnvironment(channelID string) []string {
+	if s.envCipher == nil {
+		return nil
+	}
+
+	vars, err := s.envVars.ListChannelEnvVars(channelID)
+	if err != nil {
+		s.logger.Error("Failed to load channel environment variables",
+			zap.String("channel_id", channelID),
+			zap.Error(err))
+		return nil
+	}
+
+	env := make([]string, 0, len(vars))
+	for _, envVar := range vars {
+		value, err := s.envCipher.Open(envVar.ValueEncrypted)
+		if err != nil {
+			s.logger.Error("Failed to decrypt channel environment variable",
+				zap.String("channel_id", channelID),
+				zap.String("name", envVar.Name),
+				zap.Error(err))
+			continue
+		}
+		env = append(env, envVar.Name+"="+value)
+	}
+	return env
+}
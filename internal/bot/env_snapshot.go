@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/git"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const (
+	footerUsage = "**Usage:** `/footer` | `/footer <git|kube|venv ...>` | `/footer default` | `/footer none`"
+
+	// envSnapshotTimeout bounds the git calls made before a run
+	envSnapshotTimeout = 2 * time.Second
+)
+
+// envSnapshot is the environment a run operated against, as shown in the
+// response footer. Empty fields weren't asked for or couldn't be found.
+type envSnapshot struct {
+	Branch      string
+	Commit      string
+	KubeContext string
+	Venv        string
+}
+
+// format renders the snapshot for the footer, or "" if there is nothing to show
+func (e envSnapshot) format() string {
+	var parts []string
+	if e.Commit != "" || e.Branch != "" {
+		ref := e.Branch
+		if ref == "" {
+			ref = "detached"
+		}
+		if e.Commit != "" {
+			ref += "@" + e.Commit
+		}
+		parts = append(parts, fmt.Sprintf("git `%s`", ref))
+	}
+	if e.KubeContext != "" {
+		parts = append(parts, fmt.Sprintf("kube `%s`", e.KubeContext))
+	}
+	if e.Venv != "" {
+		parts = append(parts, fmt.Sprintf("venv `%s`", e.Venv))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// channelFooterEnv returns the environment snapshot fields shown in a
+// channel's footers: its footer_env setting, or FOOTER_ENV
+func (s *Service) channelFooterEnv(channelID string) []string {
+	if fields := s.channelSettings(channelID).FooterEnv; fields != nil {
+		return fields
+	}
+	return s.config.FooterEnv
+}
+
+// captureEnvSnapshot gathers the requested fields for a run in workDir with
+// the channel's extra environment variables. It only reads files and asks
+// git, so it is cheap enough to do before every run.
+func captureEnvSnapshot(ctx context.Context, fields []string, workDir string, env []string) envSnapshot {
+	var snapshot envSnapshot
+	for _, field := range fields {
+		switch field {
+		case config.FooterEnvGit:
+			gitCtx, cancel := context.WithTimeout(ctx, envSnapshotTimeout)
+			snapshot.Commit, _ = git.Head(gitCtx, workDir)
+			snapshot.Branch, _ = git.Branch(gitCtx, workDir)
+			cancel()
+		case config.FooterEnvKube:
+			snapshot.KubeContext = kubeContext(env)
+		case config.FooterEnvVenv:
+			snapshot.Venv = virtualEnv(env, workDir)
+		}
+	}
+	return snapshot
+}
+
+// lookupRunEnv returns a variable as Claude's run sees it: the channel's
+// value if it sets one, otherwise the bot's own
+func lookupRunEnv(env []string, key string) string {
+	for i := len(env) - 1; i >= 0; i-- {
+		if value, ok := strings.CutPrefix(env[i], key+"="); ok {
+			return value
+		}
+	}
+	return os.Getenv(key)
+}
+
+// kubeContext reads current-context from the kubeconfig kubectl would use,
+// without running kubectl
+func kubeContext(env []string) string {
+	paths := filepath.SplitList(lookupRunEnv(env, "KUBECONFIG"))
+	if len(paths) == 0 {
+		home := lookupRunEnv(env, "HOME")
+		if home == "" {
+			return ""
+		}
+		paths = []string{filepath.Join(home, ".kube", "config")}
+	}
+
+	// kubectl takes current-context from the first file that sets it
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if value, ok := strings.CutPrefix(scanner.Text(), "current-context:"); ok {
+				if value = strings.Trim(strings.TrimSpace(value), `"'`); value != "" {
+					file.Close()
+					return value
+				}
+			}
+		}
+		file.Close()
+	}
+	return ""
+}
+
+// virtualEnv names the Python virtualenv a run would use: VIRTUAL_ENV if set,
+// otherwise a .venv or venv directory in workDir
+func virtualEnv(env []string, workDir string) string {
+	if venv := lookupRunEnv(env, "VIRTUAL_ENV"); venv != "" {
+		return filepath.Base(venv)
+	}
+	for _, name := range []string{".venv", "venv"} {
+		if _, err := os.Stat(filepath.Join(workDir, name, "pyvenv.cfg")); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// handleFooterCommand shows or sets the environment snapshot fields in the
+// channel's response footers
+func (s *Service) handleFooterCommand(ctx context.Context, req *commands.Request) (string, error) {
+	if len(req.Args) == 0 {
+		fields := s.channelFooterEnv(req.ChannelID)
+		if len(fields) == 0 {
+			return "🧾 **Footer environment:** _off_\n\n" + footerUsage, nil
+		}
+		return fmt.Sprintf("🧾 **Footer environment:** `%s`\n\n%s", strings.Join(fields, "`, `"), footerUsage), nil
+	}
+
+	if s.settings == nil {
+		return "❌ **Channel footer settings require database persistence**", nil
+	}
+
+	var fields []string
+	switch req.Args[0] {
+	case "default":
+		if len(req.Args) != 1 {
+			return "❌ **Invalid arguments**\n\n" + footerUsage, nil
+		}
+	case "none":
+		if len(req.Args) != 1 {
+			return "❌ **Invalid arguments**\n\n" + footerUsage, nil
+		}
+		fields = []string{}
+	default:
+		var err error
+		fields, err = config.ParseFooterEnvFields(strings.Join(req.Args, ","))
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid field:** %v\n\n%s", err, footerUsage), nil
+		}
+	}
+
+	var stored interface{}
+	if fields != nil {
+		stored = fields
+	}
+	if err := s.storeChannelSetting(req.ChannelID, repository.ChannelSettingFooterEnv, stored, req.UserID); err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "footer_command", "set_footer_env")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to update the footer environment"), nil
+	}
+
+	s.logger.Info("Channel footer environment updated",
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID),
+		zap.Strings("fields", fields))
+
+	switch {
+	case fields == nil:
+		return "✅ **Footer environment reset** to the workspace default.", nil
+	case len(fields) == 0:
+		return "✅ **Footer environment turned off** in this channel.", nil
+	default:
+		return fmt.Sprintf("✅ **Footer environment set:** replies in this channel will show `%s`.", strings.Join(fields, "`, `")), nil
+	}
+}
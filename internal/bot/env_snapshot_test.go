@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvSnapshotFormat(t *testing.T) {
+	tests := []struct {
+		snapshot envSnapshot
+		want     string
+	}{
+		{envSnapshot{}, ""},
+		{envSnapshot{Branch: "main", Commit: "abc1234"}, "git `main@abc1234`"},
+		{envSnapshot{Commit: "abc1234"}, "git `detached@abc1234`"},
+		{envSnapshot{Branch: "main"}, "git `main`"},
+		{envSnapshot{Branch: "main", Commit: "abc1234", KubeContext: "prod", Venv: ".venv"}, "git `main@abc1234` · kube `prod` · venv `.venv`"},
+	}
+	for _, tt := range tests {
+		if got := tt.snapshot.format(); got != tt.want {
+			t.Errorf("format(%+v) = %q, want %q", tt.snapshot, got, tt.want)
+		}
+	}
+}
+
+func TestKubeContext(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.yaml")
+	staging := filepath.Join(dir, "staging.yaml")
+	if err := os.WriteFile(empty, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(staging, []byte("apiVersion: v1\ncurrent-context: \"staging-eu\"\nkind: Config\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := []string{"KUBECONFIG=" + empty + string(filepath.ListSeparator) + staging}
+	if got := kubeContext(env); got != "staging-eu" {
+		t.Errorf("kubeContext() = %q, want staging-eu", got)
+	}
+
+	// The channel's variables win over the bot's own
+	t.Setenv("KUBECONFIG", staging)
+	if got := kubeContext([]string{"KUBECONFIG=" + empty}); got != "" {
+		t.Errorf("kubeContext() = %q, want none from the channel's kubeconfig", got)
+	}
+	if got := kubeContext(nil); got != "staging-eu" {
+		t.Errorf("kubeContext() = %q, want the bot's kubeconfig", got)
+	}
+}
+
+func TestVirtualEnv(t *testing.T) {
+	workDir := t.TempDir()
+	t.Setenv("VIRTUAL_ENV", "")
+	if got := virtualEnv(nil, workDir); got != "" {
+		t.Errorf("virtualEnv() = %q, want none", got)
+	}
+
+	if err := os.MkdirAll(filepath.Join(workDir, ".venv"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, ".venv", "pyvenv.cfg"), []byte("home = /usr/bin\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := virtualEnv(nil, workDir); got != ".venv" {
+		t.Errorf("virtualEnv() = %q, want .venv", got)
+	}
+	if got := virtualEnv([]string{"VIRTUAL_ENV=/opt/envs/ml"}, workDir); got != "ml" {
+		t.Errorf("virtualEnv() = %q, want ml", got)
+	}
+}
@@ -0,0 +1,31 @@
+package bot
+
+import "testing"
+
+func TestParseEnvAssignment(t *testing.T) {
+	tests := []struct {
+		input   string
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"AWS_PROFILE staging", "AWS_PROFILE", "staging", false},
+		{"KUBECONFIG=/etc/kube/staging.yaml", "KUBECONFIG", "/etc/kube/staging.yaml", false},
+		{"OPTS=--a=1 --b=2", "OPTS", "--a=1 --b=2", false},
+		{"GREETING hello = world", "GREETING", "hello = world", false},
+		{"AWS_PROFILE", "", "", true},
+		{"AWS_PROFILE=", "", "", true},
+		{"1BAD value", "", "", true},
+	}
+
+	for _, tt := range tests {
+		name, value, err := parseEnvAssignment(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseEnvAssignment(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if name != tt.name || value != tt.value {
+			t.Errorf("parseEnvAssignment(%q) = %q, %q; want %q, %q", tt.input, name, value, tt.name, tt.value)
+		}
+	}
+}
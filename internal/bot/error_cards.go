@@ -0,0 +1,204 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+// Buttons on a failed run's error card
+const (
+	errorRetryActionID  = "error_card_retry"
+	errorReportActionID = "error_card_report"
+)
+
+const (
+	// errorCardMessageLimit caps the error message shown on a card
+	errorCardMessageLimit = 500
+	// errorCardStderrLimit caps the stderr tail shown on a card; a section's
+	// text can't exceed 3000 characters
+	errorCardStderrLimit = 2500
+)
+
+// tailStderr returns the last limit characters of stderr, where a CLI's
+// actual complaint usually is, marking any cut
+func tailStderr(stderr string, limit int) string {
+	stderr = strings.TrimSpace(stderr)
+	runes := []rune(stderr)
+	if len(runes) <= limit {
+		return stderr
+	}
+	return "…" + string(runes[len(runes)-limit:])
+}
+
+// errorSummary returns the headline of a run's error: the paragraphs before
+// its stderr, debug details, and troubleshooting tips. The card shows stderr
+// on its own, so nothing is cut inside a code block.
+func errorSummary(message string) string {
+	var kept []string
+	for _, paragraph := range strings.Split(strings.TrimSpace(message), "\n\n") {
+		if strings.Contains(paragraph, "```") ||
+			strings.HasPrefix(paragraph, "**Stderr Output:**") ||
+			strings.HasPrefix(paragraph, "**Debug Information:**") ||
+			strings.HasPrefix(paragraph, "**Troubleshooting:**") {
+			break
+		}
+		kept = append(kept, paragraph)
+	}
+	summary := strings.Join(kept, "\n\n")
+	if runes := []rune(summary); len(runes) > errorCardMessageLimit {
+		summary = string(runes[:errorCardMessageLimit]) + "…"
+	}
+	return summary
+}
+
+// errorCardBlocks lays out the card for a failed run: what went wrong, the
+// end of the CLI's stderr (which Slack folds behind "See more"), the failed
+// run and the Claude session its logged error is tagged with, and buttons
+// to retry it or report it to the admins
+func errorCardBlocks(failedRunID int, kind, message, stderr, sessionID string, reportable bool) []slack.Block {
+	id := strconv.Itoa(failedRunID)
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+			fmt.Sprintf("❌ *Claude run failed* · `%s`\n%s", kind, errorSummary(message)), false, false), nil, nil),
+	}
+	if stderr = tailStderr(stderr, errorCardStderrLimit); stderr != "" {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+			"*stderr*\n```"+stderr+"```", false, false), nil, nil))
+	}
+	footer := fmt.Sprintf("Recorded as failed run `#%d`; an admin can replay it with `/failed retry %d`", failedRunID, failedRunID)
+	if sessionID != "" {
+		footer += fmt.Sprintf(" · Session `%s`", sessionID)
+	}
+	blocks = append(blocks, slack.NewContextBlock("",
+		slack.NewTextBlockObject(slack.MarkdownType, footer, false, false)))
+
+	retry := slack.NewButtonBlockElement(errorRetryActionID, id, slack.NewTextBlockObject(slack.PlainTextType, "🔁 Retry", false, false))
+	retry.Style = slack.StylePrimary
+	buttons := []slack.BlockElement{retry}
+	if reportable {
+		buttons = append(buttons, slack.NewButtonBlockElement(errorReportActionID, id, slack.NewTextBlockObject(slack.PlainTextType, "📣 Report", false, false)))
+	}
+	return append(blocks, slack.NewActionBlock("error_card_"+id, buttons...))
+}
+
+// errorReportChannel is where Report sends a failed run's debug bundle, or ""
+// when there's nowhere to send it
+func (s *Service) errorReportChannel() string {
+	if s.config.ErrorReportChannel != "" {
+		return s.config.ErrorReportChannel
+	}
+	for _, channel := range s.config.NotificationChannels {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			return channel
+		}
+	}
+	return ""
+}
+
+// postErrorCard posts the error card for a recorded failed run to the thread
+// it failed in. It reports whether the card was posted; if not, the caller
+// should fall back to a plain error message.
+func (s *Service) postErrorCard(channelID, threadTS, sessionID string, failedRunID int, runErr error) bool {
+	kind := claude.ErrorKind(runErr)
+	fallback := fmt.Sprintf("❌ Claude run failed (%s): %s", kind, errorSummary(runErr.Error()))
+	opts := []slack.MsgOption{
+		slack.MsgOptionText(fallback, false),
+		slack.MsgOptionBlocks(errorCardBlocks(failedRunID, kind, runErr.Error(), claude.ErrorStderr(runErr), sessionID, s.errorReportChannel() != "")...),
+	}
+	if threadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(threadTS))
+	}
+	if _, err := s.sender.PostMessage(context.Background(), channelID, opts...); err != nil {
+		s.logger.Warn("Failed to post error card",
+			zap.String("channel_id", channelID),
+			zap.Int("failed_run_id", failedRunID),
+			zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// handleErrorCardAction handles the Retry and Report buttons on an error card
+func (s *Service) handleErrorCardAction(callback *slack.InteractionCallback, action *slack.BlockAction) {
+	ctx := context.Background()
+	channelID := callback.Channel.ID
+	userID := callback.User.ID
+
+	id, err := strconv.Atoi(action.Value)
+	if err != nil {
+		return
+	}
+	run, err := s.failedRuns.GetFailedRun(id)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "error_card", "get_failed_run")
+		s.logErrorWithTrace(ctx, errCtx, err, "Failed to get failed run")
+		return
+	}
+	if run == nil {
+		s.postEphemeral(channelID, userID, fmt.Sprintf("❌ **Failed run not found:** `#%d`", id))
+		return
+	}
+
+	authCtx := &auth.AuthContext{UserID: userID, ChannelID: channelID, Command: "error_card", Timestamp: time.Now()}
+	if action.ActionID == errorReportActionID {
+		if err := s.authService.AuthorizeUser(authCtx, auth.PermissionExecute); err != nil {
+			s.postEphemeral(channelID, userID, fmt.Sprintf("❌ Authorization failed: %v", err))
+			return
+		}
+		s.reportFailedRun(channelID, userID, run.ID, run.UserID, run.ChannelID)
+		return
+	}
+
+	// Retrying runs the prompt as its author, so only they or an admin may
+	permission := auth.PermissionExecute
+	if userID != run.UserID {
+		permission = auth.PermissionAdmin
+	}
+	if err := s.authService.AuthorizeUser(authCtx, permission); err != nil {
+		if userID != run.UserID {
+			s.postEphemeral(channelID, userID, fmt.Sprintf("❌ Only <@%s> or an admin can retry this run.", run.UserID))
+			return
+		}
+		s.postEphemeral(channelID, userID, fmt.Sprintf("❌ Authorization failed: %v", err))
+		return
+	}
+	if err := s.replayFailedRun(run, userID); err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "error_card", "mark_retried")
+		s.logErrorWithTrace(ctx, errCtx, err, "Failed to mark failed run as retried")
+	}
+}
+
+// reportFailedRun files the debug bundle for a failed run's channel to the
+// error report channel, at most once per run per alert cooldown
+func (s *Service) reportFailedRun(channelID, userID string, failedRunID int, runUserID, runChannelID string) {
+	target := s.errorReportChannel()
+	if target == "" {
+		s.postEphemeral(channelID, userID, "ℹ️ No error report channel is configured; ask an admin to set `ERROR_REPORT_CHANNEL`.")
+		return
+	}
+	if !s.alertCooldowns.allow(fmt.Sprintf("error_report/%d", failedRunID), time.Now(), alertCooldown) {
+		s.postEphemeral(channelID, userID, fmt.Sprintf("ℹ️ Failed run `#%d` was already reported recently.", failedRunID))
+		return
+	}
+
+	s.logger.Info("Reporting failed run",
+		zap.Int("failed_run_id", failedRunID),
+		zap.String("reported_by", userID),
+		zap.String("report_channel", target))
+	comment := fmt.Sprintf("📣 <@%s> reported failed run `#%d` in <#%s> (secrets redacted)", userID, failedRunID, runChannelID)
+	if !s.uploadDebugBundleTo(runUserID, runChannelID, target, comment) {
+		s.postEphemeral(channelID, userID, fmt.Sprintf("❌ **Failed to report failed run** `#%d`; see the logs for details.", failedRunID))
+		return
+	}
+	s.postEphemeral(channelID, userID, fmt.Sprintf("📣 **Reported.** The debug bundle for failed run `#%d` was sent to the admins.", failedRunID))
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+func TestTailStderr(t *testing.T) {
+	if got := tailStderr("  short\n", 10); got != "short" {
+		t.Errorf("Expected short stderr to be kept whole, got %q", got)
+	}
+	if got := tailStderr("0123456789abcdef", 6); got != "…abcdef" {
+		t.Errorf("Expected the tail of long stderr, got %q", got)
+	}
+	if got := tailStderr("", 10); got != "" {
+		t.Errorf("Expected empty stderr to stay empty, got %q", got)
+	}
+}
+
+func TestErrorCardBlocks(t *testing.T) {
+	blocks := errorCardBlocks(42, "timeout", "run timed out", "panic: boom", "sess-1", true)
+	if len(blocks) != 4 {
+		t.Fatalf("Expected summary, stderr, context, and actions blocks, got %d", len(blocks))
+	}
+	if text := blocks[0].(*slack.SectionBlock).Text.Text; !strings.Contains(text, "`timeout`") || !strings.Contains(text, "run timed out") {
+		t.Errorf("Expected the kind and message in the summary, got %q", text)
+	}
+	if text := blocks[1].(*slack.SectionBlock).Text.Text; !strings.Contains(text, "```panic: boom```") {
+		t.Errorf("Expected stderr in a code block, got %q", text)
+	}
+	if text := blocks[2].(*slack.ContextBlock).ContextElements.Elements[0].(*slack.TextBlockObject).Text; !strings.Contains(text, "Recorded as failed run `#42`") || !strings.Contains(text, "`sess-1`") {
+		t.Errorf("Expected the failed run and session in the context, got %q", text)
+	}
+
+	actions := blocks[3].(*slack.ActionBlock).Elements.ElementSet
+	if len(actions) != 2 {
+		t.Fatalf("Expected Retry and Report buttons, got %d", len(actions))
+	}
+	retry := actions[0].(*slack.ButtonBlockElement)
+	if retry.ActionID != errorRetryActionID || retry.Value != "42" {
+		t.Errorf("Expected Retry for run 42, got %q=%q", retry.ActionID, retry.Value)
+	}
+	if report := actions[1].(*slack.ButtonBlockElement); report.ActionID != errorReportActionID || report.Value != "42" {
+		t.Errorf("Expected Report for run 42, got %q=%q", report.ActionID, report.Value)
+	}
+}
+
+func TestErrorCardBlocks_WithoutStderrOrReport(t *testing.T) {
+	blocks := errorCardBlocks(7, "claude_error", "exit status 1", "", "", false)
+	if len(blocks) != 3 {
+		t.Fatalf("Expected no stderr block, got %d blocks", len(blocks))
+	}
+	if actions := blocks[2].(*slack.ActionBlock).Elements.ElementSet; len(actions) != 1 {
+		t.Errorf("Expected only a Retry button without a report channel, got %d", len(actions))
+	}
+}
+
+func TestErrorCardBlocks_FitSlackLimits(t *testing.T) {
+	blocks := errorCardBlocks(1, "claude_error", strings.Repeat("m", 5000), strings.Repeat("s", 10000), "", true)
+	for i, block := range blocks[:2] {
+		if n := len([]rune(block.(*slack.SectionBlock).Text.Text)); n > 3000 {
+			t.Errorf("Expected section %d to fit in 3000 characters, got %d", i, n)
+		}
+	}
+}
+
+func TestErrorSummary(t *testing.T) {
+	message := "Claude Code execution failed after 2s\n\n⏱️ **Operation Timeout**\nThe operation took too long to complete.\n\n**Stderr Output:**\n```\n" +
+		strings.Repeat("x", 600) + "\n```\n\n**Troubleshooting:**\n• Try again"
+	got := errorSummary(message)
+	if !strings.Contains(got, "execution failed after 2s") || !strings.Contains(got, "**Operation Timeout**") {
+		t.Errorf("Expected the headline kept, got %q", got)
+	}
+	if strings.Contains(got, "```") || strings.Contains(got, "Stderr") || strings.Contains(got, "Troubleshooting") {
+		t.Errorf("Expected stderr and tips left out, got %q", got)
+	}
+	if got := errorSummary("exit status 1"); got != "exit status 1" {
+		t.Errorf("Expected a plain message kept, got %q", got)
+	}
+}
+
+func TestErrorReportChannel(t *testing.T) {
+	s := &Service{config: &config.Config{NotificationChannels: []string{" ", "C1", "C2"}}}
+	if got := s.errorReportChannel(); got != "C1" {
+		t.Errorf("Expected the first notification channel, got %q", got)
+	}
+	s.config.ErrorReportChannel = "C9"
+	if got := s.errorReportChannel(); got != "C9" {
+		t.Errorf("Expected the configured report channel, got %q", got)
+	}
+	s.config = &config.Config{}
+	if got := s.errorReportChannel(); got != "" {
+		t.Errorf("Expected no report channel, got %q", got)
+	}
+}
@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// maxEscalationSummary keeps the quoted reason to a few lines
+const maxEscalationSummary = 400
+
+// escalationReason is why Claude couldn't finish on its own
+type escalationReason string
+
+const (
+	escalationBlocked     escalationReason = "is blocked"
+	escalationCredentials escalationReason = "needs credentials"
+	escalationDecision    escalationReason = "needs a decision"
+)
+
+// escalationPhrases are checked in order, so credentials win over a
+// generic "can't proceed" in the same reply
+var escalationPhrases = []struct {
+	reason  escalationReason
+	phrases []string
+}{
+	{escalationCredentials, []string{
+		"need credentials", "need the credentials", "need access to", "don't have access to", "do not have access to",
+		"provide an api key", "provide the api key", "need an api key", "need a token", "provide a token",
+		"need the password", "authentication failed", "not authorized to", "permission denied",
+	}},
+	{escalationDecision, []string{
+		"need you to decide", "need a decision", "requires your approval", "need your approval", "needs approval",
+		"which option would you prefer", "how would you like me to proceed", "please confirm before i",
+	}},
+	{escalationBlocked, []string{
+		"i can't proceed", "i cannot proceed", "unable to proceed", "i'm blocked", "i am blocked", "i'm stuck",
+		"unable to continue", "i can't continue", "i cannot continue", "i can't complete", "i cannot complete",
+		"unable to complete",
+	}},
+}
+
+// escalationPatterns match escalationPhrases as whole words, across any
+// whitespace, so "need a token" doesn't match "need a tokenizer"
+var escalationPatterns = compileEscalationPhrases()
+
+type escalationPatternGroup struct {
+	reason   escalationReason
+	patterns []*regexp.Regexp
+}
+
+func compileEscalationPhrases() []escalationPatternGroup {
+	groups := make([]escalationPatternGroup, 0, len(escalationPhrases))
+	for _, group := range escalationPhrases {
+		compiled := escalationPatternGroup{reason: group.reason}
+		for _, phrase := range group.phrases {
+			words := strings.Fields(phrase)
+			for i, word := range words {
+				words[i] = regexp.QuoteMeta(word)
+			}
+			compiled.patterns = append(compiled.patterns, regexp.MustCompile(`\b`+strings.Join(words, `\s+`)+`\b`))
+		}
+		groups = append(groups, compiled)
+	}
+	return groups
+}
+
+// sentencePattern splits a reply into sentences for the escalation summary
+var sentencePattern = regexp.MustCompile(`[^.!?\n]+[.!?]?`)
+
+// detectEscalation reports why a reply needs a human, with the sentence that
+// says so as a summary, or "" if Claude could carry on by itself
+func detectEscalation(reply string) (escalationReason, string) {
+	reply = strings.ReplaceAll(reply, "’", "'")
+	lower := strings.ToLower(reply)
+	for _, group := range escalationPatterns {
+		for _, pattern := range group.patterns {
+			loc := pattern.FindStringIndex(lower)
+			if loc == nil {
+				continue
+			}
+			return group.reason, escalationSummary(reply, loc[0])
+		}
+	}
+	return "", ""
+}
+
+// escalationSummary returns the sentence of reply around byte offset i
+func escalationSummary(reply string, i int) string {
+	for _, loc := range sentencePattern.FindAllStringIndex(reply, -1) {
+		if loc[0] <= i && i < loc[1] {
+			summary := strings.TrimSpace(reply[loc[0]:loc[1]])
+			if runes := []rune(summary); len(runes) > maxEscalationSummary {
+				summary = string(runes[:maxEscalationSummary]) + "…"
+			}
+			return summary
+		}
+	}
+	return ""
+}
+
+// escalationMentions renders the configured users and user groups
+func escalationMentions(ids []string) string {
+	mentions := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if strings.HasPrefix(id, "S") {
+			mentions = append(mentions, fmt.Sprintf("<!subteam^%s>", id))
+		} else {
+			mentions = append(mentions, fmt.Sprintf("<@%s>", id))
+		}
+	}
+	return strings.Join(mentions, " ")
+}
+
+// maybeEscalate brings in humans when a reply says Claude is blocked or
+// needs credentials or a decision. It returns a footer note when it does.
+func (s *Service) maybeEscalate(channelID, threadTS, userID, sessionID, reply string) string {
+	if len(s.config.EscalationMentions) == 0 && s.config.EscalationChannel == "" {
+		return ""
+	}
+	reason, summary := detectEscalation(reply)
+	if reason == "" {
+		return ""
+	}
+	if !s.alertCooldowns.allow("escalation:"+sessionID, time.Now(), s.config.EscalationCooldown) {
+		s.logger.Debug("Escalation suppressed by cooldown",
+			zap.String("session_id", sessionID),
+			zap.String("reason", string(reason)))
+		return ""
+	}
+
+	go s.escalate(channelID, threadTS, userID, sessionID, reason, summary)
+
+	if s.config.EscalationChannel != "" && s.config.EscalationChannel != channelID {
+		return fmt.Sprintf("Claude %s; escalated to <#%s>", reason, s.config.EscalationChannel)
+	}
+	return fmt.Sprintf("Claude %s; escalated in this thread", reason)
+}
+
+// escalate posts the escalation to the escalation channel, or mentions the
+// escalation group in the thread
+func (s *Service) escalate(channelID, threadTS, userID, sessionID string, reason escalationReason, summary string) {
+	mentions := escalationMentions(s.config.EscalationMentions)
+
+	s.logger.Info("Escalating to humans",
+		zap.String("channel_id", channelID),
+		zap.String("session_id", sessionID),
+		zap.String("user_id", userID),
+		zap.String("reason", string(reason)))
+
+	if s.config.EscalationChannel == "" || s.config.EscalationChannel == channelID {
+		s.sendThreadResponse(channelID, threadTS, strings.TrimSpace(fmt.Sprintf("🆘 %s Claude %s here and can't carry on alone:\n> %s", mentions, reason, summary)))
+		return
+	}
+
+	link := fmt.Sprintf("<#%s>", channelID)
+	permalink, err := s.api().GetPermalink(&slack.PermalinkParameters{Channel: channelID, Ts: threadTS})
+	if err != nil {
+		s.logger.Warn("Failed to get escalation permalink", zap.String("channel_id", channelID), zap.Error(err))
+	} else {
+		link = fmt.Sprintf("<%s|view thread>", permalink)
+	}
+
+	message := fmt.Sprintf("🆘 *Escalation from <#%s>*: Claude %s\n• Requested by: <@%s>\n• Session: `%s`\n• Thread: %s\n> %s",
+		channelID, reason, userID, sessionID, link, summary)
+	if mentions != "" {
+		message = mentions + " " + message
+	}
+	s.sendThreadResponse(s.config.EscalationChannel, "", message)
+}
@@ -0,0 +1,40 @@
+package bot
+
+import "testing"
+
+func TestDetectEscalation(t *testing.T) {
+	tests := []struct {
+		reply   string
+		reason  escalationReason
+		summary string
+	}{
+		{
+			"I checked the deploy logs. I don't have access to the production cluster, so I can't proceed. Could someone grant it?",
+			escalationCredentials,
+			"I don't have access to the production cluster, so I can't proceed.",
+		},
+		{
+			"There are two ways to fix this.\nI need you to decide whether to drop the column or migrate it",
+			escalationDecision,
+			"I need you to decide whether to drop the column or migrate it",
+		},
+		{"The migration failed twice. I’m stuck on the lock timeout!", escalationBlocked, "I'm stuck on the lock timeout!"},
+		{"All tests pass and the fix is pushed.", "", ""},
+		{"Should I also update the changelog?", "", ""},
+		{"We need a tokenizer for the parser, so I added one.", "", ""},
+		{"The linter flagged the unable to completeness check as flaky.", "", ""},
+		{"I can't  continue without the staging database.", escalationBlocked, "I can't  continue without the staging database."},
+	}
+	for _, tt := range tests {
+		reason, summary := detectEscalation(tt.reply)
+		if reason != tt.reason || summary != tt.summary {
+			t.Errorf("detectEscalation(%q) = %q, %q, want %q, %q", tt.reply, reason, summary, tt.reason, tt.summary)
+		}
+	}
+}
+
+func TestEscalationMentions(t *testing.T) {
+	if got, want := escalationMentions([]string{"S0ONCALL", "U123"}), "<!subteam^S0ONCALL> <@U123>"; got != want {
+		t.Errorf("escalationMentions() = %q, want %q", got, want)
+	}
+}
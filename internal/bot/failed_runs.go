@@ -0,0 +1,213 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const (
+	failedRunsUsage = "**Usage:** `/failed list` | `/failed retry <id>`"
+
+	// failedRunsListLimit is how many failed runs /failed list shows
+	failedRunsListLimit = 10
+)
+
+// recordFailedRun keeps a failed Claude run so an admin can replay it with
+// /failed retry once the cause is fixed. prompt is the message as the user
+// sent it; its attachments are kept as file IDs, since the downloaded copies
+// are deleted soon after the run. Returns the run's ID, or 0 if it wasn't
+// recorded, as for runs the user canceled.
+func (s *Service) recordFailedRun(event *slackevents.MessageEvent, sessionID, workDir, prompt string, runErr error) int {
+	if stoppedByUser(runErr) {
+		return 0
+	}
+
+	var fileIDs []string
+	for _, file := range event.Files {
+		fileIDs = append(fileIDs, file.ID)
+	}
+	run := &repository.FailedRun{
+		ChannelID:        event.Channel,
+		UserID:           event.User,
+		ThreadTS:         optionalString(event.ThreadTimeStamp),
+		SessionID:        optionalString(sessionID),
+		WorkingDirectory: optionalString(workDir),
+		Prompt:           prompt,
+		FileIDs:          fileIDs,
+		ErrorKind:        claude.ErrorKind(runErr),
+		ErrorMessage:     runErr.Error(),
+		Stderr:           optionalString(claude.ErrorStderr(runErr)),
+	}
+
+	id, err := s.failedRuns.RecordFailedRun(run)
+	if err != nil {
+		s.logger.Error("Failed to record failed run",
+			zap.String("channel_id", event.Channel),
+			zap.String("session_id", sessionID),
+			zap.Error(err))
+		return 0
+	}
+
+	s.logger.Info("Recorded failed run",
+		zap.Int("failed_run_id", id),
+		zap.String("error_kind", run.ErrorKind),
+		zap.String("channel_id", event.Channel))
+
+	s.alertRepeatedFailures(event.Channel, run.ErrorKind)
+	return id
+}
+
+// stoppedByUser reports whether a run failed because it was stopped, by /stop
+// or the cancel button, rather than because something went wrong
+func stoppedByUser(err error) bool {
+	return errors.Is(err, context.Canceled) || claude.ErrorKind(err) == "canceled"
+}
+
+// handleFailedCommand handles /failed list and /failed retry <id>
+func (s *Service) handleFailedCommand(ctx context.Context, req *commands.Request) (string, error) {
+	switch req.Args[0] {
+	case "list":
+		if len(req.Args) != 1 {
+			return "❌ **Invalid arguments**\n\n" + failedRunsUsage, nil
+		}
+		return s.handleFailedListCommand(ctx, req), nil
+	case "retry":
+		if len(req.Args) != 2 {
+			return "❌ **Invalid arguments**\n\n" + failedRunsUsage, nil
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(req.Args[1], "#"))
+		if err != nil || id <= 0 {
+			return fmt.Sprintf("❌ **Invalid failed run ID:** `%s`", req.Args[1]), nil
+		}
+		return s.handleFailedRetryCommand(ctx, req, id), nil
+	}
+	return failedRunsUsage, nil
+}
+
+// handleFailedListCommand lists the most recent failed runs
+func (s *Service) handleFailedListCommand(ctx context.Context, req *commands.Request) string {
+	runs, err := s.failedRuns.ListFailedRuns(failedRunsListLimit)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "failed_command", "list_failed_runs")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list failed runs")
+	}
+	if len(runs) == 0 {
+		return "✅ **No failed runs**"
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🗂️ **Failed Runs** (latest %d)\n", len(runs)))
+	for _, run := range runs {
+		response.WriteString(fmt.Sprintf("\n• `#%d` %s — `%s` in <#%s> by <@%s>",
+			run.ID, s.userTime(req.UserID, run.CreatedAt).Format("Jan 2 15:04"), run.ErrorKind, run.ChannelID, run.UserID))
+		if run.RetryCount > 0 {
+			response.WriteString(fmt.Sprintf(" (retried %d×)", run.RetryCount))
+		}
+		response.WriteString("\n   " + exchangePreview(nil, &run.Prompt))
+	}
+	response.WriteString("\n\nReplay one with `/failed retry <id>`.")
+	return response.String()
+}
+
+// handleFailedRetryCommand replays a failed run as its original author, in
+// the channel and thread where it failed
+func (s *Service) handleFailedRetryCommand(ctx context.Context, req *commands.Request, id int) string {
+	run, err := s.failedRuns.GetFailedRun(id)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "failed_command", "get_failed_run")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to get failed run")
+	}
+	if run == nil {
+		return fmt.Sprintf("❌ **Failed run not found:** `#%d`", id)
+	}
+
+	if err := s.replayFailedRun(run, req.UserID); err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "failed_command", "mark_retried")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to mark failed run as retried")
+	}
+
+	return fmt.Sprintf("🔁 **Retrying failed run** `#%d` in <#%s>\n\nIt runs in the channel's current session; a new failure is recorded as a new entry.", run.ID, run.ChannelID)
+}
+
+// replayFailedRun reruns a failed run's prompt as its original author, in
+// the channel and thread where it failed
+func (s *Service) replayFailedRun(run *repository.FailedRun, retriedBy string) error {
+	if err := s.failedRuns.MarkFailedRunRetried(run.ID, retriedBy); err != nil {
+		return err
+	}
+
+	event := &slackevents.MessageEvent{
+		Type:    "message",
+		User:    run.UserID,
+		Text:    run.Prompt,
+		Channel: run.ChannelID,
+	}
+	if run.ThreadTS != nil {
+		event.ThreadTimeStamp = *run.ThreadTS
+	}
+
+	s.logger.Info("Replaying failed run",
+		zap.Int("failed_run_id", run.ID),
+		zap.String("retried_by", retriedBy),
+		zap.String("channel_id", run.ChannelID))
+
+	noticeTS := s.sendThreadResponse(run.ChannelID, event.ThreadTimeStamp,
+		fmt.Sprintf("🔁 <@%s> is retrying failed run `#%d` from <@%s>", retriedBy, run.ID, run.UserID))
+	if event.ThreadTimeStamp == "" {
+		event.ThreadTimeStamp = noticeTS
+	}
+	go s.runReplay(run, event)
+	return nil
+}
+
+// runReplay runs a failed run's message again as its author, with its
+// attachments downloaded again. It goes straight to Claude: the replay
+// isn't a new Slack message for the mention and channel checks, and its
+// text is never a command.
+func (s *Service) runReplay(run *repository.FailedRun, event *slackevents.MessageEvent) {
+	ctx := context.Background()
+
+	// The author may have lost access since the run failed
+	authCtx := &auth.AuthContext{UserID: run.UserID, ChannelID: run.ChannelID, Timestamp: time.Now()}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionExecute); err != nil {
+		errCtx := logging.CreateErrorContext(run.ChannelID, run.UserID, "failed_run_replay", "authorization")
+		s.sendThreadResponse(run.ChannelID, event.ThreadTimeStamp, s.logErrorWithTrace(ctx, errCtx, err, "Authorization failed"))
+		return
+	}
+
+	for _, fileID := range run.FileIDs {
+		file, _, _, err := s.api().GetFileInfo(fileID, 0, 0)
+		if err != nil {
+			errCtx := logging.CreateErrorContext(run.ChannelID, run.UserID, "failed_run_replay", "get_file_info")
+			s.sendThreadResponse(run.ChannelID, event.ThreadTimeStamp,
+				s.logErrorWithTrace(ctx, errCtx, err, fmt.Sprintf("Failed to get attachment `%s` of the failed run", fileID)))
+			return
+		}
+		event.Files = append(event.Files, slackevents.File{ID: file.ID, Name: file.Name, Mimetype: file.Mimetype})
+	}
+
+	if response := s.processClaudeMessage(ctx, event, run.Prompt, runOverrides{}); response != "" {
+		s.sendThreadResponseOrHold(run.ChannelID, event.ThreadTimeStamp, response)
+	}
+}
+
+// optionalString returns nil for an empty string, for nullable columns
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
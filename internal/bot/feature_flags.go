@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/flags"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const flagsUsage = "**Usage:** `/flags list` | `/flags set <name> on|off|<percent>%` | `/flags channel <name> on|off|reset` | `/flags delete <name>`"
+
+// Flags checked by features rolled out with /flags
+const (
+	flagStreaming      = "streaming"       // Stream tool events to the thread where TOOL_EVENTS is off
+	flagAutoCompaction = "auto-compaction" // Compact sessions that pass CONTEXT_WARN_PERCENT
+)
+
+// featureEnabled reports whether a feature flag is on in a channel. Risky
+// features check it before running so they can be rolled out with /flags.
+func (s *Service) featureEnabled(name, channelID string) bool {
+	if s.featureFlags == nil {
+		return false
+	}
+	return s.featureFlags.Enabled(name, channelID)
+}
+
+// parseRollout parses on, off, or a percentage such as 25%
+func parseRollout(value string) (int, error) {
+	switch strings.ToLower(value) {
+	case "on":
+		return 100, nil
+	case "off":
+		return 0, nil
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	if err != nil || percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("rollout must be `on`, `off`, or a percentage from `0%%` to `100%%`")
+	}
+	return percent, nil
+}
+
+// handleFlagsCommand handles /flags list, set, channel, and delete
+func (s *Service) handleFlagsCommand(ctx context.Context, req *commands.Request) (string, error) {
+	args := req.Args
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+	if len(args) > 1 && !flags.ValidName(args[1]) {
+		return fmt.Sprintf("❌ **Invalid flag name:** `%s`\n\nUse lowercase letters, digits, `-`, and `_`, up to 50 characters.", args[1]), nil
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		return s.handleFlagsListCommand(ctx, req), nil
+	case args[0] == "set" && len(args) == 3:
+		percent, err := parseRollout(args[2])
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid rollout:** %v", err), nil
+		}
+		if err := s.flagStore.SetFeatureFlagRollout(args[1], percent, req.UserID); err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "flags_command", "set_rollout")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to update feature flag"), nil
+		}
+		s.featureFlags.Invalidate()
+		return fmt.Sprintf("✅ **`%s` rolled out to %d%% of channels**\n\nChannel overrides still apply. Other instances pick this up within %s.", args[1], percent, s.config.FeatureFlagCacheTTL), nil
+	case args[0] == "channel" && len(args) == 3:
+		var enabled *bool
+		switch strings.ToLower(args[2]) {
+		case "on", "off":
+			on := strings.ToLower(args[2]) == "on"
+			enabled = &on
+		case "reset":
+		default:
+			return "❌ **Invalid arguments**\n\n" + flagsUsage, nil
+		}
+		if err := s.flagStore.SetFeatureFlagChannel(args[1], req.ChannelID, enabled, req.UserID); err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "flags_command", "set_channel_override")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to update feature flag"), nil
+		}
+		s.featureFlags.Invalidate()
+		if enabled == nil {
+			return fmt.Sprintf("✅ **`%s` follows its rollout in this channel again**", args[1]), nil
+		}
+		return fmt.Sprintf("✅ **`%s` turned %s in this channel**", args[1], strings.ToLower(args[2])), nil
+	case args[0] == "delete" && len(args) == 2:
+		deleted, err := s.flagStore.DeleteFeatureFlag(args[1])
+		if err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "flags_command", "delete")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to delete feature flag"), nil
+		}
+		if !deleted {
+			return fmt.Sprintf("ℹ️ `%s` doesn't exist", args[1]), nil
+		}
+		s.featureFlags.Invalidate()
+		return fmt.Sprintf("✅ **`%s` deleted**\n\nThe feature is off everywhere.", args[1]), nil
+	}
+	return "❌ **Invalid arguments**\n\n" + flagsUsage, nil
+}
+
+func (s *Service) handleFlagsListCommand(ctx context.Context, req *commands.Request) string {
+	all, err := s.featureFlags.List()
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "flags_command", "list")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list feature flags")
+	}
+	if len(all) == 0 {
+		return "🚩 **Feature Flags**\n\nNo flags are set, so every flagged feature is off. Turn one on with `/flags set <name> on` or `/flags channel <name> on`."
+	}
+
+	var response strings.Builder
+	response.WriteString("🚩 **Feature Flags**\n")
+	for _, flag := range all {
+		response.WriteString(fmt.Sprintf("\n• `%s`: %s here — %s", flag.Name, onOff(flags.Evaluate(flag, req.ChannelID)), describeFlag(flag)))
+	}
+	response.WriteString("\n\nFlags not listed are off.")
+	return response.String()
+}
+
+// describeFlag summarizes a flag's rollout and overrides
+func describeFlag(flag *repository.FeatureFlag) string {
+	description := fmt.Sprintf("%d%% rollout", flag.RolloutPercent)
+	var on, off []string
+	for channelID, enabled := range flag.Channels {
+		if enabled {
+			on = append(on, "<#"+channelID+">")
+		} else {
+			off = append(off, "<#"+channelID+">")
+		}
+	}
+	sort.Strings(on)
+	sort.Strings(off)
+	if len(on) > 0 {
+		description += ", on in " + strings.Join(on, " ")
+	}
+	if len(off) > 0 {
+		description += ", off in " + strings.Join(off, " ")
+	}
+	return description
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "*on*"
+	}
+	return "off"
+}
@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/flags"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func TestParseRollout(t *testing.T) {
+	for value, want := range map[string]int{"on": 100, "OFF": 0, "25%": 25, "40": 40, "100%": 100} {
+		if got, err := parseRollout(value); err != nil || got != want {
+			t.Errorf("parseRollout(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"half", "-5%", "101%", ""} {
+		if _, err := parseRollout(value); err == nil {
+			t.Errorf("parseRollout(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestDescribeFlag(t *testing.T) {
+	flag := &repository.FeatureFlag{Name: "streaming", RolloutPercent: 10, Channels: map[string]bool{"C2": true, "C1": true, "C3": false}}
+	want := "10% rollout, on in <#C1> <#C2>, off in <#C3>"
+	if got := describeFlag(flag); got != want {
+		t.Errorf("describeFlag() = %q, want %q", got, want)
+	}
+}
+
+// flagList is a flags.Store with fixed flags
+type flagList []*repository.FeatureFlag
+
+func (l flagList) ListFeatureFlags() ([]*repository.FeatureFlag, error) {
+	return l, nil
+}
+
+func TestFlaggedFeatures(t *testing.T) {
+	store := flagList{
+		{Name: flagStreaming, Channels: map[string]bool{"C1": true}},
+		{Name: flagAutoCompaction, Channels: map[string]bool{"C1": true}},
+	}
+	s := &Service{
+		config:       &config.Config{ContextWindowTokens: 200000, ContextWarnPercent: 80},
+		featureFlags: flags.New(store, time.Minute, zap.NewNop()),
+	}
+
+	if !s.toolEventsEnabled(&repository.ChannelSettings{ChannelID: "C1"}) || s.toolEventsEnabled(&repository.ChannelSettings{ChannelID: "C2"}) {
+		t.Error("Expected the streaming flag to turn tool events on only where it's on")
+	}
+
+	if !s.autoCompactionDue("C1", "fix the tests", 170000) {
+		t.Error("Expected a nearly full session compacted where auto-compaction is on")
+	}
+	if s.autoCompactionDue("C2", "fix the tests", 170000) || s.autoCompactionDue("C1", "fix the tests", 100000) {
+		t.Error("Expected no compaction where the flag is off or the context has room")
+	}
+	if s.autoCompactionDue("C1", " /compact ", 170000) {
+		t.Error("Expected a compaction run never to trigger another")
+	}
+}
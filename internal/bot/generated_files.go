@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/files"
+)
+
+// imageOutputPrompt tells Claude where to save files derived from attached
+// images so they can be matched to the originals and posted back
+func imageOutputPrompt(images []*files.FileInfo) string {
+	var examples []string
+	for _, image := range images {
+		examples = append(examples, fmt.Sprintf("%s_<description>.<ext>", files.OutputPrefix(image)))
+	}
+	return "If you create cropped, annotated, converted, or otherwise edited versions of an attached image, save each one as " +
+		strings.Join(examples, " or ") + " so it can be shared with the user"
+}
+
+// attachGeneratedFiles uploads files a run derived from attached images to
+// the thread, named after the originals
+func (s *Service) attachGeneratedFiles(channelID, threadTS, userID string, generated []*files.GeneratedFile) {
+	for _, file := range generated {
+		content, err := os.ReadFile(file.LocalPath)
+		if err != nil {
+			s.logger.Error("Failed to read generated file",
+				zap.String("path", file.LocalPath),
+				zap.Error(err))
+			continue
+		}
+
+		_, err = s.api().UploadFileV2(slack.UploadFileV2Parameters{
+			Reader:          bytes.NewReader(content),
+			FileSize:        len(content),
+			Filename:        file.Name,
+			Title:           fmt.Sprintf("%s (from %s)", file.Name, file.Original.OriginalName),
+			Channel:         channelID,
+			ThreadTimestamp: threadTS,
+		})
+		if err != nil {
+			s.logger.Error("Failed to upload generated file",
+				zap.String("channel_id", channelID),
+				zap.String("user_id", userID),
+				zap.String("filename", file.Name),
+				zap.Error(err))
+			continue
+		}
+
+		s.logger.Info("Generated file attached",
+			zap.String("channel_id", channelID),
+			zap.String("thread_ts", threadTS),
+			zap.String("filename", file.Name),
+			zap.String("original", file.Original.OriginalName),
+			zap.String("mime_type", file.MimeType),
+			zap.Int64("size", file.Size))
+	}
+}
@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+const guardrailsUsage = "**Usage:** `/guardrails show` | `/guardrails set <constraints>` | `/guardrails clear`"
+
+// maxGuardrailsLength keeps guardrails from crowding out the rest of the
+// system prompt
+const maxGuardrailsLength = 4000
+
+// parseGuardrails extracts the constraints from "/guardrails set ..." text
+func parseGuardrails(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	guardrails := strings.TrimSpace(strings.TrimPrefix(text, "set"))
+	if guardrails == "" {
+		return "", fmt.Errorf("no constraints given")
+	}
+	if len(guardrails) > maxGuardrailsLength {
+		return "", fmt.Errorf("guardrails are %d characters; keep them under %d", len(guardrails), maxGuardrailsLength)
+	}
+	return guardrails, nil
+}
+
+// handleGuardrailsCommand handles /guardrails show, set, and clear. Anyone
+// can read a channel's guardrails; only admins can change them.
+func (s *Service) handleGuardrailsCommand(ctx context.Context, req *commands.Request) (string, error) {
+	switch req.Args[0] {
+	case "show":
+		return s.handleGuardrailsShowCommand(ctx, req), nil
+	case "set", "clear":
+		if !s.authService.IsUserAdmin(req.UserID) {
+			return "❌ **Admin Only**\n\nOnly admins can change a channel's guardrails.", nil
+		}
+		if req.Args[0] == "clear" {
+			return s.handleGuardrailsClearCommand(ctx, req), nil
+		}
+		guardrails, err := parseGuardrails(req.Text)
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid guardrails:** %v\n\n%s", err, guardrailsUsage), nil
+		}
+		return s.handleGuardrailsSetCommand(ctx, req, guardrails), nil
+	}
+	return guardrailsUsage, nil
+}
+
+func (s *Service) handleGuardrailsShowCommand(ctx context.Context, req *commands.Request) string {
+	current, err := s.guardrails.GetChannelGuardrails(req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "guardrails_command", "get_guardrails")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to load guardrails")
+	}
+	if current == nil {
+		return "ℹ️ **No guardrails in this channel**\n\nAdmins can add them with `/guardrails set <constraints>`."
+	}
+
+	response := fmt.Sprintf("🛡️ **Channel Guardrails**\n\n```\n%s\n```\n\nSet %s", current.Guardrails, s.userTime(req.UserID, current.UpdatedAt).Format("Jan 2 15:04"))
+	if current.UpdatedBy != nil {
+		response += fmt.Sprintf(" by <@%s>", *current.UpdatedBy)
+	}
+	return response + ". Every Claude run in this channel must follow them."
+}
+
+func (s *Service) handleGuardrailsSetCommand(ctx context.Context, req *commands.Request, guardrails string) string {
+	if err := s.guardrails.SetChannelGuardrails(req.ChannelID, guardrails, req.UserID); err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "guardrails_command", "set_guardrails")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to set guardrails")
+	}
+	return fmt.Sprintf("✅ **Guardrails set for this channel**\n\n```\n%s\n```\n\nThey apply to every Claude run here from the next message on and can't be overridden from prompts.", guardrails)
+}
+
+func (s *Service) handleGuardrailsClearCommand(ctx context.Context, req *commands.Request) string {
+	removed, err := s.guardrails.ClearChannelGuardrails(req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "guardrails_command", "clear_guardrails")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to clear guardrails")
+	}
+	if !removed {
+		return "ℹ️ No guardrails were set in this channel"
+	}
+
+	s.logger.Info("Channel guardrails cleared",
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID))
+	return "✅ **Guardrails cleared for this channel**"
+}
+
+// channelGuardrails returns the guardrails for runs in a channel, or "" if
+// there are none. Runs must not start when this fails, since they would run
+// without the channel's constraints.
+func (s *Service) channelGuardrails(channelID string) (string, error) {
+	current, err := s.guardrails.GetChannelGuardrails(channelID)
+	if err != nil {
+		return "", err
+	}
+	if current == nil {
+		return "", nil
+	}
+	return current.Guardrails, nil
+}
@@ -0,0 +1,20 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGuardrails(t *testing.T) {
+	got, err := parseGuardrails("set  Never touch .env\nAlways ask before kubectl apply ")
+	if err != nil || got != "Never touch .env\nAlways ask before kubectl apply" {
+		t.Errorf("parseGuardrails = %q, %v", got, err)
+	}
+
+	if _, err := parseGuardrails("set"); err == nil {
+		t.Error("Expected an error for empty guardrails")
+	}
+	if _, err := parseGuardrails("set " + strings.Repeat("x", maxGuardrailsLength+1)); err == nil {
+		t.Error("Expected an error for guardrails over the length limit")
+	}
+}
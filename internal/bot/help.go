@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+)
+
+// maxHelpSectionLength keeps each help section under Slack's 3000 character
+// limit for section text
+const maxHelpSectionLength = 2900
+
+// handleHelpCommand posts the command overview, or details for one command,
+// as ephemeral Block Kit sections
+func (s *Service) handleHelpCommand(ctx context.Context, req *commands.Request) (string, error) {
+	var sections []string
+	if len(req.Args) > 0 {
+		cmd, exists := s.commands.Lookup(req.Args[0])
+		if !exists {
+			return fmt.Sprintf("❌ Unknown command: `%s`. Type `help` for available commands.", req.Args[0]), nil
+		}
+		sections = s.commandHelpSections(cmd)
+	} else {
+		sections = s.helpSections(req.UserID)
+	}
+
+	blocks := make([]slack.Block, 0, len(sections)+1)
+	for i, section := range sections {
+		if i == 1 {
+			blocks = append(blocks, slack.NewDividerBlock())
+		}
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, section, false, false), nil, nil))
+	}
+
+	fallback := strings.Join(sections, "\n\n")
+	err := s.sender.PostEphemeral(ctx, req.ChannelID, req.UserID,
+		slack.MsgOptionText(fallback, false),
+		slack.MsgOptionBlocks(blocks...))
+	if err != nil {
+		s.logger.Warn("Failed to post help blocks, falling back to text",
+			zap.String("channel_id", req.ChannelID), zap.Error(err))
+		return fallback, nil
+	}
+
+	return "", nil
+}
+
+// helpSections renders the command overview. Admin-only commands are listed
+// only for admins since nobody else can run them.
+func (s *Service) helpSections(userID string) []string {
+	isAdmin := s.authService.IsUserAdmin(userID)
+
+	var lines []string
+	for _, cmd := range s.commands.Commands() {
+		if cmd.Permission == auth.PermissionAdmin && !isAdmin {
+			continue
+		}
+		line := fmt.Sprintf("• `%s` - %s", helpUsage(cmd), cmd.Description)
+		if cmd.Permission >= auth.PermissionWrite {
+			line += fmt.Sprintf(" _(%s)_", cmd.Permission)
+		}
+		lines = append(lines, line)
+	}
+
+	sections := []string{fmt.Sprintf("🤖 *%s Help*\n\nType `help <command>` for details, permissions, and examples.", s.config.BotDisplayName)}
+	sections = append(sections, chunkLines("*Commands:*", lines)...)
+	sections = append(sections, fmt.Sprintf(`*Talking to Claude:*
+• Direct message: Just type your message
+• Channel: Use `+"`%s <message>`"+` or mention @%s
+• Example: `+"`%s help me debug this Python script`",
+		s.config.CommandPrefix, s.config.BotDisplayName, s.config.CommandPrefix))
+
+	return sections
+}
+
+// commandHelpSections renders the details for a single command
+func (s *Service) commandHelpSections(cmd *commands.Command) []string {
+	var header strings.Builder
+	header.WriteString(fmt.Sprintf("📖 *%s*\n\n%s", cmd.Name, cmd.Description))
+	if cmd.Details != "" {
+		header.WriteString("\n\n" + cmd.Details)
+	}
+
+	var usage strings.Builder
+	usage.WriteString("*Usage:*")
+	var sources []string
+	if cmd.AvailableFrom(commands.SourceSlash) {
+		usage.WriteString(fmt.Sprintf("\n• `%s`", cmd.Usage(commands.SourceSlash)))
+		sources = append(sources, "slash command")
+	}
+	if cmd.AvailableFrom(commands.SourceMessage) {
+		usage.WriteString(fmt.Sprintf("\n• `%s`", cmd.Usage(commands.SourceMessage)))
+		sources = append(sources, "message")
+	}
+	for _, arg := range cmd.Args {
+		if arg.Description == "" {
+			continue
+		}
+		usage.WriteString(fmt.Sprintf("\n`%s` - %s", arg.Name, arg.Description))
+	}
+	usage.WriteString(fmt.Sprintf("\n\n*Requires:* %s permission\n*Available as:* %s",
+		cmd.Permission, strings.Join(sources, ", ")))
+
+	sections := []string{header.String(), usage.String()}
+
+	if len(cmd.Examples) > 0 {
+		prefix := ""
+		if cmd.AvailableFrom(commands.SourceSlash) {
+			prefix = "/"
+		}
+		var examples strings.Builder
+		examples.WriteString("*Examples:*")
+		for _, example := range cmd.Examples {
+			examples.WriteString(fmt.Sprintf("\n• `%s%s`", prefix, example))
+		}
+		sections = append(sections, examples.String())
+	}
+
+	return sections
+}
+
+// helpUsage prefers the slash form since most commands are slash-only
+func helpUsage(cmd *commands.Command) string {
+	if cmd.AvailableFrom(commands.SourceSlash) {
+		return cmd.Usage(commands.SourceSlash)
+	}
+	return cmd.Usage(commands.SourceMessage)
+}
+
+// chunkLines joins lines under a title, starting a new section whenever the
+// current one would exceed maxHelpSectionLength
+func chunkLines(title string, lines []string) []string {
+	var sections []string
+	current := title
+	for _, line := range lines {
+		if len(current)+len(line)+1 > maxHelpSectionLength {
+			sections = append(sections, current)
+			current = ""
+		}
+		if current != "" {
+			current += "\n"
+		}
+		current += line
+	}
+	return append(sections, current)
+}
@@ -0,0 +1,27 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChunkLines(t *testing.T) {
+	line := strings.Repeat("x", 1000)
+	sections := chunkLines("*Commands:*", []string{line, line, line, line})
+
+	if len(sections) != 2 {
+		t.Fatalf("Expected 2 sections, got %d", len(sections))
+	}
+	if !strings.HasPrefix(sections[0], "*Commands:*\n") {
+		t.Errorf("Expected title at the start of the first section, got %q", sections[0][:20])
+	}
+	for _, section := range sections {
+		if len(section) > maxHelpSectionLength {
+			t.Errorf("Section exceeds limit: %d", len(section))
+		}
+	}
+
+	if sections := chunkLines("*Commands:*", nil); len(sections) != 1 || sections[0] != "*Commands:*" {
+		t.Errorf("Expected title-only section, got %v", sections)
+	}
+}
@@ -0,0 +1,285 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// maxSlackRequestBody caps request bodies on the Slack endpoints. Event and
+// slash command payloads are a few kilobytes.
+const maxSlackRequestBody = 1 << 20
+
+// maxChallengeLength caps the url_verification challenge echoed back
+const maxChallengeLength = 256
+
+// requestIDHeader carries the request ID in both directions, so a proxy's
+// ID is kept and callers can quote ours
+const requestIDHeader = "X-Request-ID"
+
+const (
+	contentTypeJSON = "application/json"
+	contentTypeForm = "application/x-www-form-urlencoded"
+)
+
+var (
+	requestIDPattern    = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+	slashCommandPattern = regexp.MustCompile(`^/[A-Za-z0-9_.-]{1,32}$`)
+	slackUserPattern    = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+	slackChannelPattern = regexp.MustCompile(`^[CDG][A-Z0-9]{2,}$`)
+)
+
+type requestIDKey struct{}
+
+// requestID returns the ID logRequests gave a request, or ""
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestLogger returns the service logger tagged with the request's ID
+func (s *Service) requestLogger(r *http.Request) *zap.Logger {
+	if id := requestID(r); id != "" {
+		return s.logger.With(zap.String("request_id", id))
+	}
+	return s.logger
+}
+
+// statusRecorder remembers the status a handler replied with
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// logRequests gives each request an ID, returned in X-Request-ID, and logs
+// how it was answered. A well-formed incoming X-Request-ID is kept.
+func (s *Service) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(requestIDHeader, id)
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+
+		level := zap.InfoLevel
+		if r.URL.Path == s.config.HealthCheckPath {
+			level = zap.DebugLevel
+		}
+		s.logger.Check(level, "HTTP request").Write(
+			zap.String("request_id", id),
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", recorder.status),
+			zap.Int64("content_length", r.ContentLength),
+			zap.Duration("duration", time.Since(started)))
+	})
+}
+
+// payloadError is a rejected request: the status to answer with and why
+type payloadError struct {
+	status int
+	reason string
+}
+
+func (e *payloadError) Error() string {
+	return e.reason
+}
+
+// reject logs why a request was refused and answers with the status text
+// only, so the reason isn't echoed to the caller
+func (s *Service) reject(w http.ResponseWriter, r *http.Request, err *payloadError) {
+	s.requestLogger(r).Warn("Rejected Slack request",
+		zap.String("path", r.URL.Path),
+		zap.Int("status", err.status),
+		zap.String("reason", err.reason))
+	http.Error(w, http.StatusText(err.status), err.status)
+}
+
+// readSlackBody checks a Slack request's method and content type and reads
+// its body, up to maxSlackRequestBody
+func readSlackBody(w http.ResponseWriter, r *http.Request, contentType string) ([]byte, *payloadError) {
+	if r.Method != http.MethodPost {
+		return nil, &payloadError{http.StatusMethodNotAllowed, fmt.Sprintf("method %s", r.Method)}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != contentType {
+		return nil, &payloadError{http.StatusUnsupportedMediaType, fmt.Sprintf("content type %q, want %s", r.Header.Get("Content-Type"), contentType)}
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSlackRequestBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, &payloadError{http.StatusRequestEntityTooLarge, fmt.Sprintf("body over %d bytes", maxSlackRequestBody)}
+		}
+		return nil, &payloadError{http.StatusBadRequest, fmt.Sprintf("failed to read body: %v", err)}
+	}
+	return body, nil
+}
+
+// slackEventEnvelope is the outer Events API payload, checked before the
+// event itself is parsed
+type slackEventEnvelope struct {
+	Type      string          `json:"type"`
+	Challenge string          `json:"challenge"`
+	Event     json.RawMessage `json:"event"`
+}
+
+// validateEventPayload checks an Events API payload has the fields its type
+// requires
+func validateEventPayload(body []byte) (*slackEventEnvelope, error) {
+	var envelope slackEventEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if envelope.Type == "" {
+		return nil, errors.New("missing type")
+	}
+
+	switch envelope.Type {
+	case "url_verification":
+		if envelope.Challenge == "" || len(envelope.Challenge) > maxChallengeLength {
+			return nil, fmt.Errorf("challenge must be 1 to %d bytes", maxChallengeLength)
+		}
+	case "event_callback":
+		var inner struct {
+			Type string `json:"type"`
+		}
+		if len(envelope.Event) == 0 || json.Unmarshal(envelope.Event, &inner) != nil {
+			return nil, errors.New("event must be an object")
+		}
+		if inner.Type == "" {
+			return nil, errors.New("missing event type")
+		}
+	}
+	return &envelope, nil
+}
+
+// slashCommandPayload is a validated slash command form
+type slashCommandPayload struct {
+	Command     string
+	Text        string
+	UserID      string
+	ChannelID   string
+	TriggerID   string
+	ResponseURL string
+}
+
+// parseSlashCommandPayload parses a slash command form, rejecting repeated
+// fields, malformed IDs, and response URLs that aren't HTTPS
+func parseSlashCommandPayload(body []byte) (*slashCommandPayload, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid form: %w", err)
+	}
+
+	field := func(name string) (string, error) {
+		if values := form[name]; len(values) > 1 {
+			return "", fmt.Errorf("%s given %d times", name, len(values))
+		}
+		return form.Get(name), nil
+	}
+
+	var payload slashCommandPayload
+	for _, f := range []struct {
+		name string
+		dest *string
+	}{
+		{"command", &payload.Command},
+		{"text", &payload.Text},
+		{"user_id", &payload.UserID},
+		{"channel_id", &payload.ChannelID},
+		{"trigger_id", &payload.TriggerID},
+		{"response_url", &payload.ResponseURL},
+	} {
+		if *f.dest, err = field(f.name); err != nil {
+			return nil, err
+		}
+	}
+
+	if !slashCommandPattern.MatchString(payload.Command) {
+		return nil, fmt.Errorf("invalid command %q", payload.Command)
+	}
+	if !slackUserPattern.MatchString(payload.UserID) {
+		return nil, fmt.Errorf("invalid user_id %q", payload.UserID)
+	}
+	if !slackChannelPattern.MatchString(payload.ChannelID) {
+		return nil, fmt.Errorf("invalid channel_id %q", payload.ChannelID)
+	}
+	if !utf8.ValidString(payload.Text) {
+		return nil, errors.New("text is not valid UTF-8")
+	}
+	if payload.ResponseURL != "" {
+		u, err := url.Parse(payload.ResponseURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("response_url %q is not an HTTPS URL", payload.ResponseURL)
+		}
+	}
+	return &payload, nil
+}
+
+// validateInteraction checks an interactive payload has the fields its
+// handler relies on. Types the bot doesn't handle pass through.
+func validateInteraction(callback *slack.InteractionCallback) error {
+	if !slackUserPattern.MatchString(callback.User.ID) {
+		return fmt.Errorf("invalid user ID %q", callback.User.ID)
+	}
+
+	switch callback.Type {
+	case slack.InteractionTypeBlockActions:
+		if len(callback.ActionCallback.BlockActions) == 0 {
+			return errors.New("block_actions without actions")
+		}
+		for _, action := range callback.ActionCallback.BlockActions {
+			if action == nil || action.ActionID == "" {
+				return errors.New("block action without an action_id")
+			}
+		}
+	case slack.InteractionTypeShortcut:
+		if callback.CallbackID == "" {
+			return errors.New("shortcut without a callback_id")
+		}
+	case slack.InteractionTypeMessageAction:
+		if callback.CallbackID == "" || callback.Channel.ID == "" {
+			return errors.New("message_action without a callback_id or channel")
+		}
+	case slack.InteractionTypeViewSubmission:
+		if callback.View.CallbackID == "" {
+			return errors.New("view_submission without a view callback_id")
+		}
+	}
+	return nil
+}
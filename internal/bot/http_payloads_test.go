@@ -0,0 +1,273 @@
+package bot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+const testSigningSecret = "test-signing-secret"
+
+// newHTTPTestService returns a service that verifies signatures and answers
+// commands with the warm-up message, so handlers run without Slack or a
+// database
+func newHTTPTestService(dispatched chan<- *slackevents.EventsAPIEvent) *Service {
+	cfg := &config.Config{
+		SlackSigningSecret: testSigningSecret,
+		HealthCheckPath:    "/health",
+		RateLimitPerMinute: 1000000,
+		RateLimitBurst:     1000000,
+	}
+	ready := newReadiness()
+	ready.add(dependencyDatabase, func() error { return nil }, fmt.Errorf("not yet"))
+
+	return &Service{
+		config:      cfg,
+		logger:      zap.NewNop(),
+		authService: auth.NewService(cfg, zap.NewNop()),
+		readiness:   ready,
+		dispatchEvent: func(event *slackevents.EventsAPIEvent) {
+			if dispatched != nil {
+				dispatched <- event
+			}
+		},
+	}
+}
+
+func signedRequest(t testing.TB, path, contentType string, body []byte) *http.Request {
+	t.Helper()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(string(body)))
+	r.Header.Set("Content-Type", contentType)
+	r.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return r
+}
+
+func TestHandleSlackEvents_Validation(t *testing.T) {
+	s := newHTTPTestService(nil)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"challenge", contentTypeJSON, `{"type":"url_verification","challenge":"abc123"}`, http.StatusOK},
+		{"charset parameter", contentTypeJSON + "; charset=utf-8", `{"type":"url_verification","challenge":"abc123"}`, http.StatusOK},
+		{"form content type", contentTypeForm, `{"type":"url_verification","challenge":"abc123"}`, http.StatusUnsupportedMediaType},
+		{"not JSON", contentTypeJSON, `type=url_verification`, http.StatusBadRequest},
+		{"missing type", contentTypeJSON, `{"challenge":"abc123"}`, http.StatusBadRequest},
+		{"empty challenge", contentTypeJSON, `{"type":"url_verification"}`, http.StatusBadRequest},
+		{"oversized challenge", contentTypeJSON, `{"type":"url_verification","challenge":"` + strings.Repeat("x", maxChallengeLength+1) + `"}`, http.StatusBadRequest},
+		{"event not an object", contentTypeJSON, `{"type":"event_callback","event":"message"}`, http.StatusBadRequest},
+		{"event without type", contentTypeJSON, `{"type":"event_callback","event":{"text":"hi"}}`, http.StatusBadRequest},
+		{"too large", contentTypeJSON, `{"type":"url_verification","challenge":"` + strings.Repeat("x", maxSlackRequestBody) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.handleSlackEvents(w, signedRequest(t, "/slack/events", tt.contentType, []byte(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	s.handleSlackEvents(w, signedRequest(t, "/slack/events", contentTypeJSON, []byte(`{"type":"url_verification","challenge":"abc123"}`)))
+	if w.Body.String() != "abc123" {
+		t.Errorf("Expected the challenge to be echoed, got %q", w.Body.String())
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/slack/events", nil)
+	w = httptest.NewRecorder()
+	s.handleSlackEvents(w, r)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	r = signedRequest(t, "/slack/events", contentTypeJSON, []byte(`{"type":"url_verification","challenge":"abc123"}`))
+	r.Header.Set("X-Slack-Signature", "v0=forged")
+	w = httptest.NewRecorder()
+	s.handleSlackEvents(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Forged signature status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestHandleSlackEvents_DispatchesCallbackEvents(t *testing.T) {
+	dispatched := make(chan *slackevents.EventsAPIEvent, 1)
+	s := newHTTPTestService(dispatched)
+
+	body := `{"type":"event_callback","event":{"type":"app_mention","user":"U123","text":"<@UBOT> hi","channel":"C123","ts":"1.2"}}`
+	w := httptest.NewRecorder()
+	s.handleSlackEvents(w, signedRequest(t, "/slack/events", contentTypeJSON, []byte(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	select {
+	case event := <-dispatched:
+		if event.InnerEvent.Type != "app_mention" {
+			t.Errorf("Dispatched %q, want app_mention", event.InnerEvent.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the callback event to be dispatched")
+	}
+}
+
+func TestHandleSlashCommands_Validation(t *testing.T) {
+	s := newHTTPTestService(nil)
+
+	valid := "command=%2Fsession&text=help&user_id=U123&channel_id=C123&trigger_id=1.2&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2F1"
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"valid", contentTypeForm, valid, http.StatusOK},
+		{"JSON content type", contentTypeJSON, valid, http.StatusUnsupportedMediaType},
+		{"missing command", contentTypeForm, "text=help&user_id=U123&channel_id=C123", http.StatusBadRequest},
+		{"command without slash", contentTypeForm, "command=session&user_id=U123&channel_id=C123", http.StatusBadRequest},
+		{"repeated user", contentTypeForm, valid + "&user_id=U456", http.StatusBadRequest},
+		{"bad user", contentTypeForm, "command=%2Fsession&user_id=bob&channel_id=C123", http.StatusBadRequest},
+		{"bad channel", contentTypeForm, "command=%2Fsession&user_id=U123&channel_id=general", http.StatusBadRequest},
+		{"plain HTTP response URL", contentTypeForm, "command=%2Fsession&user_id=U123&channel_id=C123&response_url=http%3A%2F%2Fexample.com", http.StatusBadRequest},
+		{"invalid UTF-8", contentTypeForm, "command=%2Fsession&user_id=U123&channel_id=C123&text=%FF", http.StatusBadRequest},
+		{"malformed form", contentTypeForm, "command=%zz", http.StatusBadRequest},
+		{"too large", contentTypeForm, valid + "&text=" + strings.Repeat("x", maxSlackRequestBody), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.handleSlashCommands(w, signedRequest(t, "/slack/commands", tt.contentType, []byte(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLogRequests_RequestIDs(t *testing.T) {
+	s := newHTTPTestService(nil)
+	handler := s.logRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(requestID(r)))
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/version", nil)
+	r.Header.Set(requestIDHeader, "proxy-42")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Header().Get(requestIDHeader) != "proxy-42" || w.Body.String() != "proxy-42" {
+		t.Errorf("Expected the incoming request ID to be kept, got header %q, context %q", w.Header().Get(requestIDHeader), w.Body.String())
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/version", nil)
+	r.Header.Set(requestIDHeader, "bad id\nwith newline")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	id := w.Header().Get(requestIDHeader)
+	if id == "" || id == "bad id\nwith newline" || w.Body.String() != id {
+		t.Errorf("Expected a generated request ID, got header %q, context %q", id, w.Body.String())
+	}
+}
+
+func TestValidateInteraction(t *testing.T) {
+	valid := []slack.InteractionCallback{
+		{Type: slack.InteractionTypeBlockActions, User: slack.User{ID: "U123"},
+			ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{{ActionID: policyAgreeActionID}}}},
+		{Type: slack.InteractionTypeShortcut, User: slack.User{ID: "U123"}, CallbackID: "compose"},
+		{Type: slack.InteractionTypeViewSubmission, User: slack.User{ID: "U123"}, View: slack.View{CallbackID: composeCallbackID}},
+		{Type: slack.InteractionTypeDialogSubmission, User: slack.User{ID: "U123"}},
+	}
+	for _, callback := range valid {
+		if err := validateInteraction(&callback); err != nil {
+			t.Errorf("validateInteraction(%s) error = %v", callback.Type, err)
+		}
+	}
+
+	invalid := []slack.InteractionCallback{
+		{Type: slack.InteractionTypeShortcut, CallbackID: "compose"},
+		{Type: slack.InteractionTypeBlockActions, User: slack.User{ID: "U123"}},
+		{Type: slack.InteractionTypeBlockActions, User: slack.User{ID: "U123"},
+			ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{nil}}},
+		{Type: slack.InteractionTypeShortcut, User: slack.User{ID: "U123"}},
+		{Type: slack.InteractionTypeMessageAction, User: slack.User{ID: "U123"}, CallbackID: "ask_claude"},
+		{Type: slack.InteractionTypeViewSubmission, User: slack.User{ID: "U123"}},
+	}
+	for _, callback := range invalid {
+		if err := validateInteraction(&callback); err == nil {
+			t.Errorf("validateInteraction(%s) expected an error", callback.Type)
+		}
+	}
+}
+
+func FuzzHandleSlackEvents(f *testing.F) {
+	for _, seed := range []string{
+		`{"type":"url_verification","challenge":"abc123"}`,
+		`{"type":"event_callback","event":{"type":"message","user":"U123","text":"hi","channel":"C123"}}`,
+		`{"type":"event_callback","event":{"type":"reaction_added","user":"U123","reaction":"eyes","item":{"type":"message","channel":"C123","ts":"1.2"}}}`,
+		`{"type":"event_callback","event":{"type":"channel_archive","channel":"C123","user":"U123"}}`,
+		`{"type":"app_rate_limited","minute_rate_limited":1}`,
+		`{"type":"event_callback","event":null}`,
+		`[]`,
+		``,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	s := newHTTPTestService(nil)
+	f.Fuzz(func(t *testing.T, body []byte) {
+		w := httptest.NewRecorder()
+		s.handleSlackEvents(w, signedRequest(t, "/slack/events", contentTypeJSON, body))
+		switch w.Code {
+		case http.StatusOK, http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		default:
+			t.Errorf("Unexpected status %d for %q", w.Code, body)
+		}
+	})
+}
+
+func FuzzHandleSlashCommands(f *testing.F) {
+	for _, seed := range []string{
+		"command=%2Fsession&text=help&user_id=U123&channel_id=C123",
+		"command=%2Fdelete&text=abc&user_id=W123&channel_id=D123&response_url=https%3A%2F%2Fhooks.slack.com%2Fx",
+		"command=%2Fsession&user_id=U123&user_id=U456&channel_id=C123",
+		"text=%FF%FE",
+		"command=%zz",
+		"",
+	} {
+		f.Add([]byte(seed))
+	}
+
+	s := newHTTPTestService(nil)
+	f.Fuzz(func(t *testing.T, body []byte) {
+		w := httptest.NewRecorder()
+		s.handleSlashCommands(w, signedRequest(t, "/slack/commands", contentTypeForm, body))
+		switch w.Code {
+		case http.StatusOK, http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		default:
+			t.Errorf("Unexpected status %d for %q", w.Code, body)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/httpauth"
+)
+
+// slashSignatureRequired reports whether slash command requests must carry a
+// valid Slack signature. Outside strict mode they're only checked when a
+// signing secret is configured.
+func (s *Service) slashSignatureRequired() bool {
+	return s.config.StrictSecurity || s.config.SlackCredentials().SigningSecret != ""
+}
+
+// httpAuthenticate checks a request against its endpoint group's auth
+// policy. It returns who made it, or writes the refusal with refuse and
+// returns nil: 403 for an address off the allow-list, 401 otherwise.
+func (s *Service) httpAuthenticate(w http.ResponseWriter, r *http.Request, group string, refuse func(w http.ResponseWriter, r *http.Request, status int, message string)) *httpauth.Identity {
+	identity, err := s.config.HTTPAuthPolicy(group).Authenticate(r)
+	if err == nil {
+		if identity.Provider != "" {
+			s.requestLogger(r).Debug("Authenticated request",
+				zap.String("group", group),
+				zap.String("auth_provider", identity.Provider),
+				zap.String("auth_subject", identity.Subject))
+		}
+		return identity
+	}
+
+	s.requestLogger(r).Warn("Rejected "+group+" request",
+		zap.String("path", r.URL.Path),
+		zap.String("remote_addr", r.RemoteAddr),
+		zap.Error(err))
+	if errors.Is(err, httpauth.ErrAddressNotAllowed) {
+		refuse(w, r, http.StatusForbidden, "address not allowed")
+		return nil
+	}
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="claude-on-slack %s"`, group))
+	refuse(w, r, http.StatusUnauthorized, "missing or invalid credentials")
+	return nil
+}
+
+// statusEndpoint guards a health, metrics, or version handler with the
+// status group's auth policy
+func (s *Service) statusEndpoint(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		refuse := func(w http.ResponseWriter, r *http.Request, status int, message string) {
+			http.Error(w, http.StatusText(status), status)
+		}
+		if s.httpAuthenticate(w, r, config.HTTPGroupStatus, refuse) == nil {
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// serveDiagnostics reports whether /metrics and /version are served. Strict
+// mode drops them unless the status group needs credentials.
+func (s *Service) serveDiagnostics() bool {
+	return !s.config.StrictSecurity || s.config.HTTPAuthPolicy(config.HTTPGroupStatus).Guarded()
+}
+
+// adminEnabled reports whether the admin API is served: only once it has a
+// way to authenticate requests
+func (s *Service) adminEnabled() bool {
+	return s.config.HTTPAuthPolicy(config.HTTPGroupAdmin).Guarded()
+}
+
+// adminOnMainListener reports whether the admin API shares the main listener
+// rather than having its own
+func (s *Service) adminOnMainListener() bool {
+	return s.adminEnabled() && s.config.AdminListenAddr == ""
+}
+
+// startAdminServer serves the admin API on ADMIN_LISTEN_ADDR, apart from the
+// Slack-facing listener
+func (s *Service) startAdminServer() error {
+	mux := http.NewServeMux()
+	mux.Handle(adminAPIPrefix, s.adminAPI())
+
+	s.adminServer = &http.Server{
+		Addr:         s.config.AdminListenAddr,
+		Handler:      s.logRequests(mux),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	s.logger.Info("Starting admin HTTP server", zap.String("addr", s.adminServer.Addr))
+
+	if err := s.adminServer.ListenAndServe(); err != http.ErrServerClosed {
+		s.logger.Error("Admin HTTP server error", zap.Error(err))
+		return fmt.Errorf("admin HTTP server listen error: %w", err)
+	}
+
+	s.logger.Info("Admin HTTP server stopped gracefully")
+	return nil
+}
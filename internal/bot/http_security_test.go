@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/httpauth"
+)
+
+const testStatusToken = "status-token-0123456789"
+
+func TestHandleSlashCommands_StrictRequiresSignature(t *testing.T) {
+	s := newHTTPTestService(nil)
+	s.config.SlackSigningSecret = ""
+	body := "command=%2Fsession&text=help&user_id=U123&channel_id=C123"
+
+	unsigned := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentTypeForm)
+		return r
+	}
+
+	w := httptest.NewRecorder()
+	s.handleSlashCommands(w, unsigned())
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected an unsigned command to pass without a secret outside strict mode, got %d", w.Code)
+	}
+
+	s.config.StrictSecurity = true
+	w = httptest.NewRecorder()
+	s.handleSlashCommands(w, unsigned())
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected strict mode to reject an unsigned command, got %d", w.Code)
+	}
+}
+
+func TestStatusEndpoint(t *testing.T) {
+	s := newHTTPTestService(nil)
+	handler := s.statusEndpoint(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	request := func(authorization string) int {
+		r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w.Code
+	}
+
+	if code := request(""); code != http.StatusOK {
+		t.Errorf("Expected open access without a token, got %d", code)
+	}
+
+	s.config.StatusAPIToken = testStatusToken
+	for name, header := range map[string]string{
+		"missing":    "",
+		"wrong":      "Bearer not-the-token",
+		"not bearer": "Basic " + testStatusToken,
+	} {
+		if code := request(header); code != http.StatusUnauthorized {
+			t.Errorf("%s token: expected 401, got %d", name, code)
+		}
+	}
+	if code := request("Bearer " + testStatusToken); code != http.StatusOK {
+		t.Errorf("Expected the right token to pass, got %d", code)
+	}
+}
+
+func TestServeDiagnostics(t *testing.T) {
+	s := newHTTPTestService(nil)
+	if !s.serveDiagnostics() {
+		t.Error("Expected metrics and version to be served by default")
+	}
+	s.config.StrictSecurity = true
+	if s.serveDiagnostics() {
+		t.Error("Expected strict mode to drop unguarded metrics and version")
+	}
+	s.config.StatusAPIToken = testStatusToken
+	if !s.serveDiagnostics() {
+		t.Error("Expected strict mode to serve metrics and version behind a token")
+	}
+}
+
+func TestAdminOnMainListener(t *testing.T) {
+	s := newHTTPTestService(nil)
+	if s.adminOnMainListener() {
+		t.Error("Expected no admin API without a token")
+	}
+	s.config.AdminAPIToken = testAdminToken
+	if !s.adminOnMainListener() {
+		t.Error("Expected the admin API on the main listener by default")
+	}
+	s.config.AdminListenAddr = "127.0.0.1:9090"
+	if s.adminOnMainListener() {
+		t.Error("Expected the admin API to move to its own listener")
+	}
+}
+
+func TestStatusEndpoint_AllowList(t *testing.T) {
+	s := newHTTPTestService(nil)
+	networks, err := httpauth.ParseNetworks([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.config.StatusAllowedIPs = networks
+	handler := s.statusEndpoint(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for addr, want := range map[string]int{"10.1.2.3:5000": http.StatusOK, "203.0.113.9:5000": http.StatusForbidden} {
+		r := httptest.NewRequest(http.MethodGet, "/health", nil)
+		r.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != want {
+			t.Errorf("%s: status = %d, want %d", addr, w.Code, want)
+		}
+	}
+}
+
+func TestAdminAPI_TrustedHeader(t *testing.T) {
+	s := newAdminTestService(&adminTestManager{})
+	proxies, err := httpauth.ParseNetworks([]string{"10.0.0.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.config.AdminAPIToken = ""
+	s.config.AdminAuth = []string{config.HTTPAuthHeader}
+	s.config.HTTPAuthHeader = "X-Forwarded-Email"
+	s.config.HTTPTrustedProxies = proxies
+	if !s.adminEnabled() {
+		t.Fatal("Expected header auth to enable the admin API without a token")
+	}
+
+	request := func(remoteAddr string) int {
+		r := httptest.NewRequest(http.MethodGet, "/admin/sessions", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("X-Forwarded-Email", "ana@example.com")
+		w := httptest.NewRecorder()
+		s.adminAPI().ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := request("10.0.0.2:5000"); code != http.StatusOK {
+		t.Errorf("Expected the proxy's header to authenticate, got %d", code)
+	}
+	if code := request("203.0.113.9:5000"); code != http.StatusUnauthorized {
+		t.Errorf("Expected the header refused from elsewhere, got %d", code)
+	}
+}
@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+const identityUsage = "**Usage:** `/identity list` | `/identity set @user <identity>` | `/identity unset @user`"
+
+// identityPattern accepts LDAP and Unix usernames, DOMAIN\user, and emails
+var identityPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@+\\-]{0,254}$`)
+
+// parseUserArg returns the Slack user ID from a mention like <@U123|name> or
+// a bare user ID
+func parseUserArg(arg string) (string, bool) {
+	if match := userMentionPattern.FindStringSubmatch(arg); match != nil && match[0] == arg {
+		return match[1], true
+	}
+	if slackUserPattern.MatchString(arg) {
+		return arg, true
+	}
+	return "", false
+}
+
+// loadUserIdentities loads the identity mappings that attribution uses;
+// without them, runs are attributed to Slack user IDs
+func (s *Service) loadUserIdentities() {
+	identities, err := s.identities.ListUserIdentities()
+	if err != nil {
+		s.logger.Warn("Failed to load user identities", zap.Error(err))
+		return
+	}
+	s.authService.SetIdentities(identities)
+	s.logger.Info("Loaded user identities", zap.Int("count", len(identities)))
+}
+
+// handleIdentityCommand lists and changes which system identity Slack users
+// are attributed to
+func (s *Service) handleIdentityCommand(ctx context.Context, req *commands.Request) (string, error) {
+	args := req.Args
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		identities, err := s.identities.ListUserIdentities()
+		if err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "identity_command", "list")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list user identities"), nil
+		}
+		s.authService.SetIdentities(identities)
+		return s.formatIdentities(identities), nil
+
+	case args[0] == "set" && len(args) == 3:
+		userID, ok := parseUserArg(args[1])
+		if !ok {
+			return fmt.Sprintf("❌ **Invalid user:** `%s`\n\nMention the user, like `@jane`.", args[1]), nil
+		}
+		if !identityPattern.MatchString(args[2]) {
+			return fmt.Sprintf("❌ **Invalid identity:** `%s`\n\nUse a username or email, like `jdoe` or `jdoe@example.com`.", args[2]), nil
+		}
+		if err := s.identities.SetUserIdentity(userID, args[2], req.UserID); err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "identity_command", "set")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to map user identity"), nil
+		}
+		s.authService.SetIdentity(userID, args[2])
+		return fmt.Sprintf("✅ **<@%s> is now `%s`**\n\nTheir new sessions, runs, and audit log entries are attributed to `%s`.", userID, args[2], args[2]), nil
+
+	case args[0] == "unset" && len(args) == 2:
+		userID, ok := parseUserArg(args[1])
+		if !ok {
+			return fmt.Sprintf("❌ **Invalid user:** `%s`\n\nMention the user, like `@jane`.", args[1]), nil
+		}
+		deleted, err := s.identities.DeleteUserIdentity(userID)
+		if err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "identity_command", "unset")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to remove user identity"), nil
+		}
+		s.authService.SetIdentity(userID, "")
+		if !deleted {
+			return fmt.Sprintf("ℹ️ <@%s> has no identity mapped.", userID), nil
+		}
+		s.logger.Info("User identity removed",
+			zap.String("user_id", userID),
+			zap.String("removed_by", req.UserID))
+		return fmt.Sprintf("✅ **Identity removed** for <@%s>. They are attributed by Slack user ID again.", userID), nil
+
+	default:
+		return "❌ **Invalid arguments**\n\n" + identityUsage, nil
+	}
+}
+
+// formatIdentities lists the mapped users, ordered by identity
+func (s *Service) formatIdentities(identities map[string]string) string {
+	if len(identities) == 0 {
+		return "🪪 **No identities mapped**\n\nRuns are attributed by Slack user ID.\n\n" + identityUsage
+	}
+
+	userIDs := make([]string, 0, len(identities))
+	for userID := range identities {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Slice(userIDs, func(i, j int) bool {
+		return identities[userIDs[i]] < identities[userIDs[j]]
+	})
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🪪 **%d Mapped Identities**\n", len(identities)))
+	for _, userID := range userIDs {
+		response.WriteString(fmt.Sprintf("\n• <@%s> → `%s`", userID, identities[userID]))
+	}
+	return response.String()
+}
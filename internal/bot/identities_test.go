@@ -0,0 +1,37 @@
+package bot
+
+import "testing"
+
+func TestParseUserArg(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+		ok   bool
+	}{
+		{"<@U01ABC>", "U01ABC", true},
+		{"<@U01ABC|jane>", "U01ABC", true},
+		{"W01ABC", "W01ABC", true},
+		{"@jane", "", false},
+		{"<@U01ABC> extra", "", false},
+		{"jane", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseUserArg(tt.arg)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseUserArg(%q) = %q, %v; want %q, %v", tt.arg, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestIdentityPattern(t *testing.T) {
+	for _, identity := range []string{"jdoe", "jane.doe@example.com", `CORP\jdoe`, "svc-deploy_1"} {
+		if !identityPattern.MatchString(identity) {
+			t.Errorf("Expected %q to be a valid identity", identity)
+		}
+	}
+	for _, identity := range []string{"", "jane doe", "-rf", "<@U01ABC>", "a;b"} {
+		if identityPattern.MatchString(identity) {
+			t.Errorf("Expected %q to be rejected", identity)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+// intent is what a message asks of the bot, as decided by the intent router
+type intent string
+
+const (
+	intentTask     intent = "task" // Needs a full Claude run
+	intentGreeting intent = "greeting"
+	intentThanks   intent = "thanks"
+	intentAnswer   intent = "answer" // yes, no, ok, or an emoji reaction typed as a message
+)
+
+// intentLabels are the answers accepted from the classification model
+var intentLabels = []string{string(intentGreeting), string(intentThanks), string(intentAnswer), string(intentTask)}
+
+const (
+	// intentMaxWords is the longest message the router classifies; anything
+	// longer always goes to Claude
+	intentMaxWords = 8
+	// intentClassifyTimeout bounds the classification model call
+	intentClassifyTimeout = 15 * time.Second
+	// answerLookback is how much of Claude's last reply is checked for a question
+	answerLookback = 400
+)
+
+var (
+	slackEmojiPattern = regexp.MustCompile(`:[a-z0-9_+'-]+:`)
+
+	greetingWords = wordSet("hi", "hello", "hey", "heya", "hiya", "howdy", "yo", "sup", "morning", "gm")
+	// greetingPhrases are multi-word greetings; "whats up" is "what's up"
+	// with the apostrophe dropped
+	greetingPhrases = wordSet("good morning", "good afternoon", "good evening", "good day", "whats up")
+	// addressWords may follow a greeting or thanks, as in "hi there" or
+	// "thanks again"
+	addressWords = wordSet("there", "all", "everyone", "team", "folks", "claude", "bot", "again", "so", "much", "a", "lot")
+
+	thanksPhrases = wordSet("thanks", "thank you", "thank u", "thx", "ty", "tysm", "many thanks", "much appreciated", "appreciated", "cheers", "kudos")
+	// thanksLeads may precede thanks, as in "perfect, thank you"
+	thanksLeads = wordSet("ok", "okay", "great", "perfect", "awesome", "nice", "cool", "excellent", "amazing", "brilliant")
+
+	answerPhrases = wordSet(
+		"yes", "y", "yep", "yeah", "yup", "sure", "ok", "okay", "k", "kk",
+		"no", "n", "nope", "nah", "not now", "no thanks",
+		"go ahead", "do it", "proceed", "please do", "yes please", "sounds good", "lgtm",
+		"correct", "confirmed", "got it", "cool", "great", "perfect", "nice",
+	)
+
+	// questionPhrases mark a reply that waits for the user even without a
+	// question mark
+	questionPhrases = []string{"let me know", "would you like", "should i", "shall i", "want me to", "do you want", "please confirm"}
+)
+
+// wordSet builds a lookup set of phrases
+func wordSet(phrases ...string) map[string]bool {
+	set := make(map[string]bool, len(phrases))
+	for _, p := range phrases {
+		set[p] = true
+	}
+	return set
+}
+
+// intentWords lowercases text and splits it into words, dropping Slack
+// emoji codes, punctuation, and apostrophes. It also reports whether the
+// text held an emoji.
+func intentWords(text string) ([]string, bool) {
+	text = strings.ToLower(text)
+	hasEmoji := slackEmojiPattern.MatchString(text)
+	text = slackEmojiPattern.ReplaceAllString(text, " ")
+	text = strings.NewReplacer("'", "", "’", "").Replace(text)
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		if unicode.Is(unicode.So, r) {
+			hasEmoji = true
+		}
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return words, hasEmoji
+}
+
+// classifyIntent sorts a message by cheap heuristics. Only short, formulaic
+// messages are recognized; everything else is a task.
+func classifyIntent(text string) intent {
+	words, hasEmoji := intentWords(text)
+	if len(words) == 0 {
+		if hasEmoji {
+			return intentAnswer
+		}
+		return intentTask
+	}
+	if len(words) > intentMaxWords {
+		return intentTask
+	}
+
+	if answerPhrases[strings.Join(words, " ")] {
+		return intentAnswer
+	}
+
+	// Drop trailing "there", "claude", "again", ... before matching
+	core := words
+	for len(core) > 1 && addressWords[core[len(core)-1]] {
+		core = core[:len(core)-1]
+	}
+
+	thanks := core
+	if len(thanks) > 1 && thanksLeads[thanks[0]] {
+		thanks = thanks[1:]
+	}
+	if thanksPhrases[strings.Join(thanks, " ")] {
+		return intentThanks
+	}
+
+	phrase := strings.Join(core, " ")
+	if greetingPhrases[phrase] || (len(core) == 1 && greetingWords[phrase]) {
+		return intentGreeting
+	}
+
+	return intentTask
+}
+
+// asksForAnswer reports whether a reply from Claude ends by waiting for the
+// user, so a short "yes" or "no" is a real answer rather than an
+// acknowledgement
+func asksForAnswer(reply string) bool {
+	reply = strings.ToLower(strings.TrimSpace(reply))
+	if len(reply) > answerLookback {
+		reply = reply[len(reply)-answerLookback:]
+	}
+	if strings.Contains(reply, "?") {
+		return true
+	}
+	for _, phrase := range questionPhrases {
+		if strings.Contains(reply, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeIntent decides whether a message that isn't a command needs a full
+// Claude run. Anything unclear is a task.
+func (s *Service) routeIntent(ctx context.Context, event *slackevents.MessageEvent, text string) intent {
+	if len(event.Files) > 0 {
+		return intentTask
+	}
+
+	kind := classifyIntent(text)
+	// The classifier is a Claude run too, so it waits for the usage policy
+	if kind == intentTask && s.config.IntentRouter == config.IntentRouterModel && s.policyAccepted(event.User) {
+		if words, _ := intentWords(text); len(words) > 0 && len(words) <= intentMaxWords {
+			kind = s.classifyIntentWithModel(ctx, event, text)
+		}
+	}
+
+	// "yes" to "Should I apply the fix?" is a follow-up Claude has to act on
+	if kind == intentAnswer && s.awaitingAnswer(event.User, event.Channel) {
+		return intentTask
+	}
+	return kind
+}
+
+// classifyIntentWithModel asks the configured cheap model to classify a
+// short message, falling back to a task when it can't
+func (s *Service) classifyIntentWithModel(ctx context.Context, event *slackevents.MessageEvent, text string) intent {
+	ctx, cancel := context.WithTimeout(ctx, intentClassifyTimeout)
+	defer cancel()
+
+	label, err := s.claudeExecutor.ExecuteClaudeClassification(ctx, s.config.IntentRouterModel, intentLabels, text)
+	if err != nil {
+		s.logger.Warn("Intent classification failed; treating message as a task",
+			zap.String("channel_id", event.Channel),
+			zap.String("model", s.config.IntentRouterModel),
+			zap.Error(err))
+		return intentTask
+	}
+
+	for _, known := range intentLabels {
+		if label == known {
+			return intent(label)
+		}
+	}
+	s.logger.Debug("Unexpected intent label", zap.String("label", label))
+	return intentTask
+}
+
+// awaitingAnswer reports whether Claude's last reply in the user's current
+// session asked them something
+func (s *Service) awaitingAnswer(userID, channelID string) bool {
+	userSession, err := s.sessionManager.GetOrCreateSession(userID, channelID)
+	if err != nil {
+		return false
+	}
+	children, err := s.sessionManager.GetConversationTree(userSession.GetID())
+	if err != nil || len(children) == 0 {
+		return false
+	}
+
+	latest := children[len(children)-1]
+	return latest.AIResponse != nil && asksForAnswer(*latest.AIResponse)
+}
+
+// intentReply is the canned response to a message the router answers
+// itself, or "" for a task
+func intentReply(kind intent, userID string) string {
+	switch kind {
+	case intentGreeting:
+		return fmt.Sprintf("👋 Hi <@%s>! What should I work on? Type `help` to see commands.", userID)
+	case intentThanks:
+		return "🙌 Anytime!"
+	case intentAnswer:
+		return "👍"
+	}
+	return ""
+}
+
+// matchTypedSlashCommand recognizes a slash command typed as a message, such
+// as "/session list" in a thread where Slack doesn't run slash commands
+func (s *Service) matchTypedSlashCommand(userID, channelID, text string) (*commands.Request, bool) {
+	parts := strings.Fields(text)
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "/") {
+		return nil, false
+	}
+
+	cmd, exists := s.commands.Lookup(strings.TrimPrefix(parts[0], "/"))
+	if !exists || !cmd.AvailableFrom(commands.SourceSlash) || cmd.ValidateArgs(parts[1:]) != nil {
+		return nil, false
+	}
+
+	return commands.NewRequest(cmd.Name, userID, channelID, strings.Join(parts[1:], " "), commands.SourceSlash), true
+}
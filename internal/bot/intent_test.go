@@ -0,0 +1,64 @@
+package bot
+
+import "testing"
+
+func TestClassifyIntent(t *testing.T) {
+	tests := []struct {
+		text string
+		want intent
+	}{
+		{"hi", intentGreeting},
+		{"Hey there!", intentGreeting},
+		{"good morning, claude", intentGreeting},
+		{"what's up?", intentGreeting},
+		{"thanks!", intentThanks},
+		{"Thank you so much", intentThanks},
+		{"perfect, thanks a lot :tada:", intentThanks},
+		{"thanks again claude", intentThanks},
+		{"yes", intentAnswer},
+		{"Go ahead.", intentAnswer},
+		{"no thanks", intentAnswer},
+		{"LGTM", intentAnswer},
+		{":+1:", intentAnswer},
+		{"👍", intentAnswer},
+		{"?", intentTask},
+		{"", intentTask},
+		{"hi, can you fix the failing test?", intentTask},
+		{"thanks, now run the migrations", intentTask},
+		{"yes but use the staging database", intentTask},
+		{"hello world program in rust", intentTask},
+	}
+	for _, tt := range tests {
+		if got := classifyIntent(tt.text); got != tt.want {
+			t.Errorf("classifyIntent(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestAsksForAnswer(t *testing.T) {
+	tests := []struct {
+		reply string
+		want  bool
+	}{
+		{"I found the bug. Should I apply the fix?", true},
+		{"Here's the plan. Let me know if you want me to continue.", true},
+		{"Done. All tests pass.", false},
+		{"Is this a question? " + string(make([]byte, answerLookback)) + "Done.", false},
+	}
+	for _, tt := range tests {
+		if got := asksForAnswer(tt.reply); got != tt.want {
+			t.Errorf("asksForAnswer(%.40q) = %v, want %v", tt.reply, got, tt.want)
+		}
+	}
+}
+
+func TestIntentReply(t *testing.T) {
+	if intentReply(intentTask, "U1") != "" {
+		t.Error("Tasks should not get a canned reply")
+	}
+	for _, kind := range []intent{intentGreeting, intentThanks, intentAnswer} {
+		if intentReply(kind, "U1") == "" {
+			t.Errorf("Expected a canned reply for %s", kind)
+		}
+	}
+}
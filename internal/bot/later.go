@@ -0,0 +1,230 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const laterUsage = "**Usage:** `/later <delay> <prompt>` | `/later list` | `/later cancel <id>`\n\nDelays look like `30m`, `2h`, `1h30m`, or `3d`."
+
+const (
+	// minLaterDelay keeps /later from being a slower way to send a message
+	minLaterDelay = time.Minute
+	// maxLaterDelay keeps scheduled prompts within a week of the session
+	// they were written for
+	maxLaterDelay = 7 * 24 * time.Hour
+	// delayedPromptInterval is how often due prompts are dispatched
+	delayedPromptInterval = 30 * time.Second
+	// delayedPromptBatch caps how many prompts one tick dispatches
+	delayedPromptBatch = 10
+)
+
+// parseLaterDelay parses a /later delay such as 30m, 2h, 3d, or 1h30m
+func parseLaterDelay(arg string) (time.Duration, error) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+
+	var delay time.Duration
+	if n, err := strconv.Atoi(strings.TrimSuffix(arg, "d")); err == nil && strings.HasSuffix(arg, "d") {
+		delay = time.Duration(n) * 24 * time.Hour
+	} else if d, err := time.ParseDuration(arg); err == nil {
+		delay = d
+	} else {
+		return 0, fmt.Errorf("unknown delay %q; use minutes, hours, or days like `30m`, `2h`, or `1d`", arg)
+	}
+
+	if delay < minLaterDelay {
+		return 0, fmt.Errorf("delay %q is shorter than a minute", arg)
+	}
+	if delay > maxLaterDelay {
+		return 0, fmt.Errorf("delay %q is longer than %d days", arg, int(maxLaterDelay/(24*time.Hour)))
+	}
+	return delay, nil
+}
+
+// parseLaterPrompt splits "/later 2h check the deploy" into the delay and
+// the prompt
+func parseLaterPrompt(text string) (time.Duration, string, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return 0, "", fmt.Errorf("no delay given")
+	}
+	delay, err := parseLaterDelay(fields[0])
+	if err != nil {
+		return 0, "", err
+	}
+
+	prompt := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), fields[0]))
+	if prompt == "" {
+		return 0, "", fmt.Errorf("no prompt given")
+	}
+	return delay, prompt, nil
+}
+
+// handleLaterCommand handles /later <delay> <prompt>, /later list, and
+// /later cancel <id>
+func (s *Service) handleLaterCommand(ctx context.Context, req *commands.Request) (string, error) {
+	switch req.Args[0] {
+	case "list":
+		return s.handleLaterListCommand(ctx, req), nil
+	case "cancel":
+		if len(req.Args) < 2 {
+			return laterUsage, nil
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(req.Args[1], "#"))
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid ID:** `%s`\n\n%s", req.Args[1], laterUsage), nil
+		}
+		return s.handleLaterCancelCommand(ctx, req, id), nil
+	}
+
+	delay, prompt, err := parseLaterPrompt(req.Text)
+	if err != nil {
+		return fmt.Sprintf("❌ **Invalid schedule:** %v\n\n%s", err, laterUsage), nil
+	}
+	return s.handleLaterScheduleCommand(ctx, req, delay, prompt), nil
+}
+
+func (s *Service) handleLaterScheduleCommand(ctx context.Context, req *commands.Request, delay time.Duration, prompt string) string {
+	runAt := time.Now().Add(delay)
+	id, err := s.delayedPrompts.ScheduleDelayedPrompt(&repository.DelayedPrompt{
+		ChannelID: req.ChannelID,
+		UserID:    req.UserID,
+		Prompt:    prompt,
+		RunAt:     runAt,
+	})
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "later_command", "schedule_prompt")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to schedule prompt")
+	}
+
+	s.logger.Info("Scheduled delayed prompt",
+		zap.Int("delayed_prompt_id", id),
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID),
+		zap.Time("run_at", runAt))
+
+	return fmt.Sprintf("⏰ **Prompt scheduled** `#%d`\n\nRuns in %s (%s) in this channel's current session:\n> %s\n\nCancel it with `/later cancel %d`.",
+		id, formatRemaining(delay), s.userTime(req.UserID, runAt).Format("Jan 2 15:04"), prompt, id)
+}
+
+func (s *Service) handleLaterListCommand(ctx context.Context, req *commands.Request) string {
+	prompts, err := s.delayedPrompts.ListPendingDelayedPrompts(req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "later_command", "list_prompts")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list scheduled prompts")
+	}
+	if len(prompts) == 0 {
+		return "ℹ️ No prompts are scheduled in this channel"
+	}
+
+	var response strings.Builder
+	response.WriteString("⏰ **Scheduled Prompts**\n")
+	for _, p := range prompts {
+		fmt.Fprintf(&response, "\n• `#%d` %s (in %s) from <@%s>: %s",
+			p.ID, s.userTime(req.UserID, p.RunAt).Format("Jan 2 15:04"),
+			formatRemaining(time.Until(p.RunAt)), p.UserID, truncatePrompt(p.Prompt))
+	}
+	response.WriteString("\n\nCancel one with `/later cancel <id>`.")
+	return response.String()
+}
+
+func (s *Service) handleLaterCancelCommand(ctx context.Context, req *commands.Request, id int) string {
+	canceled, err := s.delayedPrompts.CancelDelayedPrompt(id, req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "later_command", "cancel_prompt")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to cancel scheduled prompt")
+	}
+	if !canceled {
+		return fmt.Sprintf("❌ **No pending prompt** `#%d` **in this channel**\n\nIt may have already run; see `/later list`.", id)
+	}
+
+	s.logger.Info("Canceled delayed prompt",
+		zap.Int("delayed_prompt_id", id),
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID))
+	return fmt.Sprintf("✅ **Scheduled prompt** `#%d` **canceled**", id)
+}
+
+// truncatePrompt shortens a prompt for one-line listings
+func truncatePrompt(prompt string) string {
+	prompt = strings.Join(strings.Fields(prompt), " ")
+	if runes := []rune(prompt); len(runes) > 80 {
+		return string(runes[:80]) + "…"
+	}
+	return prompt
+}
+
+// delayedPromptLoop dispatches scheduled prompts as they come due until
+// stopped
+func (s *Service) delayedPromptLoop() {
+	ticker := time.NewTicker(delayedPromptInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			prompts, err := s.delayedPrompts.ClaimDueDelayedPrompts(delayedPromptBatch)
+			if err != nil {
+				s.logger.Error("Failed to claim due delayed prompts", zap.Error(err))
+				continue
+			}
+			for _, p := range prompts {
+				go s.runDelayedPrompt(p)
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// runDelayedPrompt runs a due prompt as its author in the channel's current
+// session and posts the result in a thread under a marker message
+func (s *Service) runDelayedPrompt(p *repository.DelayedPrompt) {
+	ctx := context.Background()
+
+	// Claimed before the channel was archived; there's nowhere to post it
+	if s.channelInactive(p.ChannelID) {
+		s.logger.Info("Skipping delayed prompt for archived channel",
+			zap.Int("delayed_prompt_id", p.ID),
+			zap.String("channel_id", p.ChannelID))
+		return
+	}
+
+	threadTS := s.sendThreadResponse(p.ChannelID, "",
+		fmt.Sprintf("⏰ **Scheduled prompt** `#%d` from <@%s>\n> %s", p.ID, p.UserID, p.Prompt))
+
+	s.logger.Info("Running delayed prompt",
+		zap.Int("delayed_prompt_id", p.ID),
+		zap.String("channel_id", p.ChannelID),
+		zap.String("user_id", p.UserID))
+
+	// The author may have lost access since scheduling it
+	authCtx := &auth.AuthContext{UserID: p.UserID, ChannelID: p.ChannelID, Timestamp: time.Now()}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionExecute); err != nil {
+		errCtx := logging.CreateErrorContext(p.ChannelID, p.UserID, "delayed_prompt", "authorization")
+		s.sendThreadResponse(p.ChannelID, threadTS, s.logErrorWithTrace(ctx, errCtx, err, "Authorization failed"))
+		return
+	}
+
+	event := &slackevents.MessageEvent{
+		Type:            "message",
+		User:            p.UserID,
+		Text:            p.Prompt,
+		Channel:         p.ChannelID,
+		ThreadTimeStamp: threadTS,
+	}
+	if response := s.processClaudeMessage(ctx, event, p.Prompt, runOverrides{}); response != "" {
+		s.sendThreadResponseOrHold(p.ChannelID, threadTS, response)
+	}
+}
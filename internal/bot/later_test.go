@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLaterDelay(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30m", 30 * time.Minute, false},
+		{"2h", 2 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"3D", 72 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"8d", 0, true},
+		{"30s", 0, true},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseLaterDelay(tt.arg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLaterDelay(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseLaterDelay(%q) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestParseLaterPrompt(t *testing.T) {
+	delay, prompt, err := parseLaterPrompt("2h  check whether the deploy finished\nand report")
+	if err != nil {
+		t.Fatalf("parseLaterPrompt() error = %v", err)
+	}
+	if delay != 2*time.Hour {
+		t.Errorf("delay = %v, want 2h", delay)
+	}
+	if prompt != "check whether the deploy finished\nand report" {
+		t.Errorf("prompt = %q", prompt)
+	}
+
+	for _, text := range []string{"", "2h", "2h   ", "tomorrow check the deploy"} {
+		if _, _, err := parseLaterPrompt(text); err == nil {
+			t.Errorf("parseLaterPrompt(%q) expected an error", text)
+		}
+	}
+}
+
+func TestTruncatePrompt(t *testing.T) {
+	if got := truncatePrompt("check\n  the deploy"); got != "check the deploy" {
+		t.Errorf("truncatePrompt() = %q", got)
+	}
+	long := strings.Repeat("é", 100)
+	if got := truncatePrompt(long); got != strings.Repeat("é", 80)+"…" {
+		t.Errorf("truncatePrompt() of a long prompt = %q", got)
+	}
+}
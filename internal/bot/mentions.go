@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+// userMentionPattern matches user mentions, including labeled variants such
+// as <@U123|claude>
+var userMentionPattern = regexp.MustCompile(`<@([UW][A-Z0-9]+)(?:\|[^>]*)?>`)
+
+// directedMessage is a message as addressed to the bot
+type directedMessage struct {
+	Text            string   // What was said to the bot: its mentions and the command prefix removed
+	MentionsBot     bool     // The bot is mentioned anywhere in the message
+	HasPrefix       bool     // The message starts with the command prefix, after any mentions
+	LeadingMentions []string // Other users mentioned before any text, e.g. "@deploybot ship it"
+}
+
+// addressed reports whether the message was explicitly addressed to the bot
+func (m directedMessage) addressed() bool {
+	return m.MentionsBot || m.HasPrefix
+}
+
+// parseDirectedMessage extracts what a message says to the bot. Every mention
+// of the bot is removed, whatever its format, along with the command prefix
+// when the message starts with it (before or after the mention).
+func parseDirectedMessage(text, botUserID, commandPrefix string) directedMessage {
+	var msg directedMessage
+	var out strings.Builder
+	leading, leadingBot := true, false
+	last := 0
+
+	for _, match := range userMentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		before := text[last:match[0]]
+		if strings.TrimFunc(before, isMentionSeparator) != "" {
+			leading = false
+		}
+		userID := text[match[2]:match[3]]
+
+		if userID != botUserID {
+			if leading {
+				msg.LeadingMentions = append(msg.LeadingMentions, userID)
+			}
+			out.WriteString(before)
+			out.WriteString(text[match[0]:match[1]])
+			last = match[1]
+			continue
+		}
+
+		msg.MentionsBot = true
+		if leading {
+			leadingBot = true
+		}
+		out.WriteString(before)
+		last = match[1]
+
+		// Drop the ":" or "," that follows a mention used as an address
+		rest := strings.TrimLeftFunc(text[last:], unicode.IsSpace)
+		if strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, ",") {
+			last = len(text) - len(rest) + 1
+		}
+		// Leave a single space where the mention was, keeping line breaks
+		if out.Len() == 0 || endsWithSpace(out.String()) {
+			for last < len(text) && (text[last] == ' ' || text[last] == '\t') {
+				last++
+			}
+		} else if last < len(text) && !unicode.IsSpace(rune(text[last])) {
+			out.WriteString(" ")
+		}
+	}
+	out.WriteString(text[last:])
+
+	directed := strings.TrimSpace(out.String())
+	if rest, ok := trimCommandPrefix(directed, commandPrefix); ok {
+		msg.HasPrefix = true
+		directed = rest
+	}
+	msg.Text = directed
+
+	// Mentions of others only count as the addressee when they come first
+	if leadingBot || msg.HasPrefix {
+		msg.LeadingMentions = nil
+	}
+	return msg
+}
+
+// trimCommandPrefix removes a case-insensitive command prefix such as
+// "!claude" from the start of text. The prefix must be a whole word, so
+// "!claudeX" doesn't match.
+func trimCommandPrefix(text, prefix string) (string, bool) {
+	if prefix == "" || len(text) < len(prefix) || !strings.EqualFold(text[:len(prefix)], prefix) {
+		return text, false
+	}
+	rest := text[len(prefix):]
+	if rest != "" && !isMentionSeparator(rune(rest[0])) {
+		return text, false
+	}
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(rest), ":,")), true
+}
+
+// isMentionSeparator reports whether r may sit between mentions addressing a
+// message, as in "@claude, @deploybot: ..."
+func isMentionSeparator(r rune) bool {
+	return unicode.IsSpace(r) || r == ':' || r == ','
+}
+
+func endsWithSpace(s string) bool {
+	return s != "" && unicode.IsSpace(rune(s[len(s)-1]))
+}
+
+// addressedToOtherBot reports whether a message not addressed to this bot
+// opens by mentioning another bot, e.g. "@deploybot ship it", so it can be
+// left to that bot
+func (s *Service) addressedToOtherBot(msg directedMessage) bool {
+	if msg.addressed() {
+		return false
+	}
+	for _, userID := range msg.LeadingMentions {
+		if s.isBotUser(userID) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBotUser reports whether a Slack user is a bot. Lookups are cached for the
+// life of the process; a failed lookup counts as a person and is retried.
+func (s *Service) isBotUser(userID string) bool {
+	if isBot, ok := s.botUsers.Load(userID); ok {
+		return isBot.(bool)
+	}
+
+	user, err := s.api().GetUserInfo(userID)
+	if err != nil {
+		s.logger.Debug("Failed to look up mentioned user",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return false
+	}
+	s.botUsers.Store(userID, user.IsBot)
+	return user.IsBot
+}
@@ -0,0 +1,37 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDirectedMessage(t *testing.T) {
+	tests := []struct {
+		text, want      string
+		mentionsBot     bool
+		hasPrefix       bool
+		leadingMentions []string
+	}{
+		{"<@UBOT> fix the build", "fix the build", true, false, nil},
+		{"<@UBOT|claude>: fix the build", "fix the build", true, false, nil},
+		{"hey <@UBOT> can you look", "hey can you look", true, false, nil},
+		{"<@UBOT> !claude help", "help", true, true, nil},
+		{"!Claude <@UBOT> help", "help", true, true, nil},
+		{"!claude: status", "status", false, true, nil},
+		{"!claudes are great", "!claudes are great", false, false, nil},
+		{"<@UDEPLOY> ship it", "<@UDEPLOY> ship it", false, false, []string{"UDEPLOY"}},
+		{"<@UDEPLOY>, <@UBOT> compare notes", "<@UDEPLOY>, compare notes", true, false, nil},
+		{"<@UBOT> ask <@UDEPLOY|deploybot> too", "ask <@UDEPLOY|deploybot> too", true, false, nil},
+		{"thanks <@UALICE>", "thanks <@UALICE>", false, false, nil},
+		{"<@UBOT>\n```\ncode\n```", "```\ncode\n```", true, false, nil},
+	}
+
+	for _, tt := range tests {
+		msg := parseDirectedMessage(tt.text, "UBOT", "!claude")
+		if msg.Text != tt.want || msg.MentionsBot != tt.mentionsBot || msg.HasPrefix != tt.hasPrefix ||
+			!reflect.DeepEqual(msg.LeadingMentions, tt.leadingMentions) {
+			t.Errorf("parseDirectedMessage(%q) = %+v; want text %q, mentions bot %v, prefix %v, leading %v",
+				tt.text, msg, tt.want, tt.mentionsBot, tt.hasPrefix, tt.leadingMentions)
+		}
+	}
+}
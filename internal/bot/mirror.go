@@ -0,0 +1,222 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const mirrorUsage = "**Usage:** `/mirror` | `/mirror #channel [confirm]` | `/mirror responses on|off` | `/mirror prompts on|off` | `/mirror off`"
+
+// channelVisibility ranks channel types by how many people can read them.
+// Channels of unknown type rank as DMs when mirrored from and as public
+// channels when mirrored to, so a mirror is never assumed to be safe.
+var channelVisibility = map[string]int{
+	repository.ChannelTypeDM:      0,
+	repository.ChannelTypeGroupDM: 1,
+	repository.ChannelTypePrivate: 1,
+	repository.ChannelTypePublic:  2,
+}
+
+// channelMirror is where a channel's runs are cross-posted, and what is
+type channelMirror struct {
+	ChannelID string
+	Responses bool
+	Prompts   bool
+}
+
+// mirrorFor returns a channel's mirror, or nil if it isn't mirrored or
+// mirrors nothing. Responses are mirrored unless turned off; prompts only
+// when turned on.
+func mirrorFor(settings *repository.ChannelSettings) *channelMirror {
+	if settings.MirrorChannel == nil || *settings.MirrorChannel == "" {
+		return nil
+	}
+	mirror := &channelMirror{
+		ChannelID: *settings.MirrorChannel,
+		Responses: settings.MirrorResponses == nil || *settings.MirrorResponses,
+		Prompts:   settings.MirrorPrompts != nil && *settings.MirrorPrompts,
+	}
+	if !mirror.Responses && !mirror.Prompts {
+		return nil
+	}
+	return mirror
+}
+
+// parseMirrorChannel validates a mirror_channel value and returns the channel ID
+func parseMirrorChannel(value string) (interface{}, error) {
+	channelID, ok := parseChannelArg(value)
+	if !ok {
+		return nil, fmt.Errorf("`%s` isn't a channel; mention it, like `#audit`", value)
+	}
+	return channelID, nil
+}
+
+// mirrorOrigin names the channel a mirrored post came from
+func mirrorOrigin(channelID string) string {
+	if strings.HasPrefix(channelID, "D") {
+		return "a direct message"
+	}
+	return fmt.Sprintf("<#%s>", channelID)
+}
+
+// formatMirroredPrompt is the post that mirrors a prompt
+func formatMirroredPrompt(channelID, userID, prompt, link string) string {
+	header := fmt.Sprintf("🪞 *<@%s> asked Claude in %s*", userID, mirrorOrigin(channelID))
+	if link != "" {
+		header += fmt.Sprintf(" · <%s|view>", link)
+	}
+	return header + "\n>" + strings.ReplaceAll(strings.TrimSpace(prompt), "\n", "\n>")
+}
+
+// formatMirroredResponse is the post that mirrors a response. It's shorter
+// when it replies under the mirrored prompt, which already says where it's from.
+func formatMirroredResponse(channelID, userID, response, link string, underPrompt bool) string {
+	if underPrompt {
+		return "🪞 *Claude replied:*\n\n" + response
+	}
+	header := fmt.Sprintf("🪞 *Claude replied to <@%s> in %s*", userID, mirrorOrigin(channelID))
+	if link != "" {
+		header += fmt.Sprintf(" · <%s|view>", link)
+	}
+	return header + "\n\n" + response
+}
+
+// checkMirrorTarget returns why a channel can't mirror to targetID, or "".
+// Mirroring a DM or private channel to a more visible channel exposes its
+// conversations, so it needs confirming.
+func (s *Service) checkMirrorTarget(channelID, targetID string, confirmed bool) string {
+	if targetID == channelID {
+		return "❌ A channel can't mirror to itself."
+	}
+	isMember, err := s.isChannelMember(s.botUserID, targetID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, "", "mirror", "check_membership")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to check the mirror channel's members")
+	}
+	if !isMember {
+		return fmt.Sprintf("❌ The bot isn't in <#%s>. Invite it there first.", targetID)
+	}
+	if !confirmed && !mirrorVisibilityOK(s.recordChannelType(channelID, ""), s.recordChannelType(targetID, "")) {
+		return fmt.Sprintf("⚠️ **<#%s> is more visible than this channel**\n\nMirroring would show the prompts and responses here to everyone who can read <#%s>. "+
+			"To mirror anyway, run `/mirror <#%s> confirm`.", targetID, targetID, targetID)
+	}
+	return ""
+}
+
+// mirrorVisibilityOK reports whether a channel of sourceType can be mirrored
+// to one of targetType without exposing it to more people
+func mirrorVisibilityOK(sourceType, targetType string) bool {
+	source, ok := channelVisibility[sourceType]
+	if !ok {
+		source = channelVisibility[repository.ChannelTypeDM]
+	}
+	target, ok := channelVisibility[targetType]
+	if !ok {
+		target = channelVisibility[repository.ChannelTypePublic]
+	}
+	return source >= target
+}
+
+// handleMirrorCommand shows or changes where the channel's runs are mirrored.
+// Changes go through the mirror channel settings, so they need admin.
+func (s *Service) handleMirrorCommand(ctx context.Context, req *commands.Request) (string, error) {
+	args := req.Args
+	switch {
+	case len(args) == 0:
+		return formatMirrorStatus(mirrorFor(s.channelSettings(req.ChannelID))), nil
+	case len(args) == 1 && args[0] == "off":
+		return s.handleSettingsUnsetCommand(ctx, req, "mirror_channel"), nil
+	case len(args) == 1 || len(args) == 2 && args[1] == "confirm":
+		return s.handleSettingsSetCommand(ctx, req, "mirror_channel", strings.Join(args, " ")), nil
+	case len(args) == 2 && (args[0] == "responses" || args[0] == "prompts") && (args[1] == "on" || args[1] == "off"):
+		return s.handleSettingsSetCommand(ctx, req, "mirror_"+args[0], args[1]), nil
+	default:
+		return "❌ **Invalid arguments**\n\n" + mirrorUsage, nil
+	}
+}
+
+// formatMirrorStatus describes a channel's mirror for /mirror
+func formatMirrorStatus(mirror *channelMirror) string {
+	if mirror == nil {
+		return "🪞 **Not mirrored**\n\nMirror Claude's responses here to another channel with `/mirror #channel`."
+	}
+	var what []string
+	if mirror.Responses {
+		what = append(what, "Claude's responses")
+	}
+	if mirror.Prompts {
+		what = append(what, "prompts that start runs")
+	}
+	return fmt.Sprintf("🪞 **Mirrored to <#%s>**\n\n%s here are cross-posted there. Stop with `/mirror off`.",
+		mirror.ChannelID, strings.Join(what, " and "))
+}
+
+// mirrorPrompt cross-posts the prompt starting a run and returns the mirrored
+// post's timestamp, so the response can follow it in a thread
+func (s *Service) mirrorPrompt(mirror *channelMirror, event *slackevents.MessageEvent, prompt string) string {
+	if mirror == nil || !mirror.Prompts || strings.TrimSpace(prompt) == "" {
+		return ""
+	}
+	message := formatMirroredPrompt(event.Channel, event.User, prompt, s.mirrorLink(event.Channel, event.TimeStamp))
+	return s.postMirror(mirror.ChannelID, "", event.Channel, message)
+}
+
+// mirrorResponse cross-posts a run's response, under its mirrored prompt if
+// there is one
+func (s *Service) mirrorResponse(mirror *channelMirror, event *slackevents.MessageEvent, promptTS, response string) {
+	if mirror == nil || !mirror.Responses || strings.TrimSpace(response) == "" {
+		return
+	}
+	link := ""
+	if promptTS == "" {
+		link = s.mirrorLink(event.Channel, event.TimeStamp)
+	}
+	message := formatMirroredResponse(event.Channel, event.User, response, link, promptTS != "")
+	s.postMirror(mirror.ChannelID, promptTS, event.Channel, message)
+}
+
+// mirrorLink returns a permalink to the source message, or "" if Slack
+// won't give one
+func (s *Service) mirrorLink(channelID, ts string) string {
+	permalink, err := s.api().GetPermalink(&slack.PermalinkParameters{Channel: channelID, Ts: ts})
+	if err != nil {
+		s.logger.Debug("Failed to get permalink for mirrored post", zap.String("channel_id", channelID), zap.Error(err))
+		return ""
+	}
+	return permalink
+}
+
+// postMirror posts to a mirror channel and returns the first part's
+// timestamp. It goes straight to Slack rather than through a run, so mirrored
+// posts are never mirrored again, and the bot ignores its own messages, so
+// they can't start runs in the mirror channel either.
+func (s *Service) postMirror(targetID, threadTS, sourceID, message string) string {
+	var firstTS string
+	for _, part := range splitMessage(message, s.config.MaxMessageLength) {
+		opts := []slack.MsgOption{slack.MsgOptionText(part, false), slack.MsgOptionDisableLinkUnfurl()}
+		if threadTS != "" {
+			opts = append(opts, slack.MsgOptionTS(threadTS))
+		}
+		ts, err := s.sender.PostMessage(context.Background(), targetID, opts...)
+		if err != nil {
+			s.logger.Warn("Failed to mirror message",
+				zap.String("channel_id", sourceID),
+				zap.String("mirror_channel_id", targetID),
+				zap.Error(err))
+			return firstTS
+		}
+		if firstTS == "" {
+			firstTS = ts
+		}
+	}
+	return firstTS
+}
@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func TestMirrorFor(t *testing.T) {
+	on, off := true, false
+	target := "C0AUDIT"
+
+	if got := mirrorFor(&repository.ChannelSettings{MirrorPrompts: &on}); got != nil {
+		t.Errorf("Expected no mirror without a channel, got %+v", got)
+	}
+
+	got := mirrorFor(&repository.ChannelSettings{MirrorChannel: &target})
+	if got == nil || got.ChannelID != target || !got.Responses || got.Prompts {
+		t.Errorf("Expected responses only by default, got %+v", got)
+	}
+
+	got = mirrorFor(&repository.ChannelSettings{MirrorChannel: &target, MirrorResponses: &off, MirrorPrompts: &on})
+	if got == nil || got.Responses || !got.Prompts {
+		t.Errorf("Expected prompts only, got %+v", got)
+	}
+
+	if got := mirrorFor(&repository.ChannelSettings{MirrorChannel: &target, MirrorResponses: &off}); got != nil {
+		t.Errorf("Expected no mirror with both directions off, got %+v", got)
+	}
+}
+
+func TestParseMirrorChannel(t *testing.T) {
+	for value, want := range map[string]string{"<#C0AUDIT|audit>": "C0AUDIT", "G0LEADS": "G0LEADS"} {
+		got, err := parseMirrorChannel(value)
+		if err != nil || got != want {
+			t.Errorf("parseMirrorChannel(%q) = %v, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := parseMirrorChannel("#audit"); err == nil {
+		t.Error("Expected an unescaped channel name to be refused")
+	}
+}
+
+func TestMirrorVisibilityOK(t *testing.T) {
+	tests := []struct {
+		source, target string
+		want           bool
+	}{
+		{repository.ChannelTypePublic, repository.ChannelTypePrivate, true},
+		{repository.ChannelTypePrivate, repository.ChannelTypePrivate, true},
+		{repository.ChannelTypePrivate, repository.ChannelTypePublic, false},
+		{repository.ChannelTypeDM, repository.ChannelTypePrivate, false},
+		{repository.ChannelTypeGroupDM, repository.ChannelTypePublic, false},
+		{"", repository.ChannelTypePrivate, false},
+		{repository.ChannelTypePrivate, "", false},
+	}
+	for _, tt := range tests {
+		if got := mirrorVisibilityOK(tt.source, tt.target); got != tt.want {
+			t.Errorf("mirrorVisibilityOK(%q, %q) = %v, want %v", tt.source, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMirroredPosts(t *testing.T) {
+	prompt := formatMirroredPrompt("C0DEV", "U1", "fix the build\nthen deploy", "https://slack/p1")
+	want := "🪞 *<@U1> asked Claude in <#C0DEV>* · <https://slack/p1|view>\n>fix the build\n>then deploy"
+	if prompt != want {
+		t.Errorf("formatMirroredPrompt() = %q, want %q", prompt, want)
+	}
+	if got := formatMirroredPrompt("D0DM", "U1", "hi", ""); !strings.Contains(got, "in a direct message*") || strings.Contains(got, "view") {
+		t.Errorf("Expected a DM origin without a link, got %q", got)
+	}
+
+	if got := formatMirroredResponse("C0DEV", "U1", "Done.", "", true); got != "🪞 *Claude replied:*\n\nDone." {
+		t.Errorf("Expected a short header under the mirrored prompt, got %q", got)
+	}
+	got := formatMirroredResponse("C0DEV", "U1", "Done.", "https://slack/p1", false)
+	if want := "🪞 *Claude replied to <@U1> in <#C0DEV>* · <https://slack/p1|view>\n\nDone."; got != want {
+		t.Errorf("formatMirroredResponse() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatMirrorStatus(t *testing.T) {
+	if got := formatMirrorStatus(nil); !strings.Contains(got, "Not mirrored") {
+		t.Errorf("Expected the unmirrored status, got %q", got)
+	}
+	got := formatMirrorStatus(&channelMirror{ChannelID: "C0AUDIT", Responses: true, Prompts: true})
+	if !strings.Contains(got, "<#C0AUDIT>") || !strings.Contains(got, "Claude's responses and prompts that start runs here") {
+		t.Errorf("Expected the channel and both directions, got %q", got)
+	}
+}
+
+func TestFormatChannelSettings_MirrorChannel(t *testing.T) {
+	s := &Service{config: &config.Config{ClaudeModel: "sonnet"}}
+	target := "C0AUDIT"
+	got := s.formatChannelSettings(&repository.ChannelSettings{MirrorChannel: &target})
+	if !strings.Contains(got, "• `mirror_channel`: <#C0AUDIT>") {
+		t.Errorf("Expected the mirror channel as a mention, got:\n%s", got)
+	}
+	if !strings.Contains(got, "• `mirror_prompts`: _off_") {
+		t.Errorf("Expected prompts to inherit off, got:\n%s", got)
+	}
+}
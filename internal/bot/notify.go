@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+const notifyUsage = "**Usage:** `/notify me` (every long run) | `/notify me next` (next long run only) | `/notify off`"
+
+// pendingNotifications tracks users who asked to be pinged for their next long run only
+type pendingNotifications struct {
+	mu    sync.Mutex
+	users map[string]bool
+}
+
+func newPendingNotifications() *pendingNotifications {
+	return &pendingNotifications{users: make(map[string]bool)}
+}
+
+func (p *pendingNotifications) add(userID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.users[userID] = true
+}
+
+func (p *pendingNotifications) has(userID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.users[userID]
+}
+
+// take reports whether the user had a pending notification and clears it
+func (p *pendingNotifications) take(userID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pending := p.users[userID]
+	delete(p.users, userID)
+	return pending
+}
+
+func (p *pendingNotifications) remove(userID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.users, userID)
+}
+
+// notifyPreferenceEnabled reports whether the user is DMed after every long run
+func (s *Service) notifyPreferenceEnabled(userID string) bool {
+	prefs, err := s.prefs.GetUserPreferences(userID)
+	if err != nil {
+		s.logger.Warn("Failed to get user preferences", zap.String("user_id", userID), zap.Error(err))
+		return false
+	}
+	return prefs != nil && prefs.NotifyOnCompletion
+}
+
+// notifyIfSlow DMs the requester a link to the response when a run took
+// longer than NOTIFY_AFTER and they asked to be notified
+func (s *Service) notifyIfSlow(userID, channelID, responseTS string, elapsed time.Duration) {
+	if responseTS == "" || elapsed < s.config.NotifyAfter {
+		return
+	}
+	// The response already lands in the user's DM with the bot
+	if strings.HasPrefix(channelID, "D") {
+		return
+	}
+	if !s.pendingNotify.take(userID) && !s.notifyPreferenceEnabled(userID) {
+		return
+	}
+
+	permalink, err := s.api().GetPermalink(&slack.PermalinkParameters{Channel: channelID, Ts: responseTS})
+	if err != nil {
+		s.logger.Error("Failed to get response permalink", zap.String("channel_id", channelID), zap.Error(err))
+		return
+	}
+
+	dm, _, _, err := s.api().OpenConversation(&slack.OpenConversationParameters{Users: []string{userID}})
+	if err != nil {
+		s.logger.Error("Failed to open DM for completion notification", zap.String("user_id", userID), zap.Error(err))
+		return
+	}
+
+	message := fmt.Sprintf("✅ Your Claude run in <#%s> finished after %s: <%s|view response>", channelID, formatElapsed(elapsed), permalink)
+	if _, err := s.sender.PostMessage(context.Background(), dm.ID, slack.MsgOptionText(message, false)); err != nil {
+		s.logger.Error("Failed to send completion notification", zap.String("user_id", userID), zap.Error(err))
+		return
+	}
+
+	s.logger.Info("Sent completion notification",
+		zap.String("user_id", userID),
+		zap.String("channel_id", channelID),
+		zap.Duration("elapsed", elapsed))
+}
+
+// handleNotifySlashCommand sets whether the user is DMed when long runs finish
+func (s *Service) handleNotifySlashCommand(ctx context.Context, req *commands.Request) (string, error) {
+	threshold := formatElapsed(s.config.NotifyAfter)
+
+	switch strings.Join(req.Args, " ") {
+	case "":
+		status := "off"
+		if s.notifyPreferenceEnabled(req.UserID) {
+			status = "on for every long run"
+		} else if s.pendingNotify.has(req.UserID) {
+			status = "on for your next long run"
+		}
+		return fmt.Sprintf("🔔 **Completion DMs:** `%s`\n\nRuns longer than %s can DM you a link to the response.\n\n%s", status, threshold, notifyUsage), nil
+
+	case "me":
+		if err := s.prefs.SetNotifyOnCompletion(req.UserID, true); err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "notify_slash_command", "enable")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to save notification preference"), nil
+		}
+		return fmt.Sprintf("🔔 **Completion DMs enabled**\n\nI'll DM you whenever one of your runs takes longer than %s.", threshold), nil
+
+	case "me next":
+		s.pendingNotify.add(req.UserID)
+		return fmt.Sprintf("🔔 **Got it**\n\nI'll DM you when your next run that takes longer than %s finishes.", threshold), nil
+
+	case "off":
+		s.pendingNotify.remove(req.UserID)
+		if err := s.prefs.SetNotifyOnCompletion(req.UserID, false); err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "notify_slash_command", "disable")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to save notification preference"), nil
+		}
+		return "🔕 **Completion DMs disabled**", nil
+	}
+
+	return "❌ **Invalid arguments**\n\n" + notifyUsage, nil
+}
+
+// formatElapsed renders a duration rounded to whole seconds, e.g. "2m30s"
+func formatElapsed(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
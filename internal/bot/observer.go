@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+// observerDisallowedTools are removed from runs in observer channels on top of
+// what plan mode denies, so Claude can't read, search, or act on the host
+var observerDisallowedTools = []string{
+	"Bash", "BashOutput", "KillShell",
+	"Edit", "MultiEdit", "Write", "NotebookEdit",
+	"Read", "Glob", "Grep", "LS", "NotebookRead",
+	"Task",
+}
+
+// observerPrompt tells Claude what an observer channel expects of it
+const observerPrompt = `This Slack channel is in read-only observer mode. You have no access to the host machine, its files, or a shell here, whatever earlier instructions say. Only summarize and answer questions about the conversation content you are given; if asked to run, read, or change anything, say that this channel can't do that.`
+
+// observerMode reports whether a channel is in read-only observer mode
+func observerMode(settings *repository.ChannelSettings) bool {
+	return settings.Observer != nil && *settings.Observer
+}
+
+// observerWorkDir returns the empty directory observer runs start in instead
+// of a session's working directory, so nothing of the host's is in reach
+func observerWorkDir() (string, error) {
+	dir := filepath.Join(os.TempDir(), "claude-slack-observer")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create observer directory: %w", err)
+	}
+	return dir, nil
+}
+
+// restrictToObserver strips a run down to talking about the conversation:
+// no host tools, sub-agents, extra or image directories, or channel environment
+func restrictToObserver(opts *claude.RunOptions) {
+	opts.DisallowedTools = append(append([]string{}, opts.DisallowedTools...), observerDisallowedTools...)
+	opts.Agents = nil
+	opts.AddDirs = nil
+	opts.NoImageDir = true
+	opts.Env = nil
+	if opts.ExtraSystemPrompt == "" {
+		opts.ExtraSystemPrompt = observerPrompt
+	} else {
+		opts.ExtraSystemPrompt = observerPrompt + "\n\n" + opts.ExtraSystemPrompt
+	}
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func TestObserverMode(t *testing.T) {
+	on, off := true, false
+	if observerMode(&repository.ChannelSettings{}) {
+		t.Error("Expected channels to default to normal mode")
+	}
+	if observerMode(&repository.ChannelSettings{Observer: &off}) {
+		t.Error("Expected observer off to be normal mode")
+	}
+	if !observerMode(&repository.ChannelSettings{Observer: &on}) {
+		t.Error("Expected observer on to be observer mode")
+	}
+}
+
+func TestRestrictToObserver(t *testing.T) {
+	channelDisallowed := []string{"WebFetch"}
+	opts := claude.RunOptions{
+		ExtraSystemPrompt: "Channel context",
+		Agents:            claude.Agents{"reviewer": {}},
+		Env:               []string{"TOKEN=secret"},
+		AddDirs:           []string{"/srv/other"},
+		DisallowedTools:   channelDisallowed,
+	}
+	restrictToObserver(&opts)
+
+	if opts.Agents != nil || opts.Env != nil || opts.AddDirs != nil || !opts.NoImageDir {
+		t.Errorf("Expected sub-agents, environment, and extra and image directories to be dropped, got %+v", opts)
+	}
+	if !strings.HasPrefix(opts.ExtraSystemPrompt, observerPrompt) || !strings.HasSuffix(opts.ExtraSystemPrompt, "Channel context") {
+		t.Errorf("Expected the observer prompt before the channel's, got %q", opts.ExtraSystemPrompt)
+	}
+
+	disallowed := make(map[string]bool)
+	for _, tool := range opts.DisallowedTools {
+		disallowed[tool] = true
+	}
+	for _, tool := range []string{"WebFetch", "Bash", "Write", "Read", "Grep", "Task"} {
+		if !disallowed[tool] {
+			t.Errorf("Expected %s to be disallowed, got %v", tool, opts.DisallowedTools)
+		}
+	}
+	if len(channelDisallowed) != 1 {
+		t.Errorf("Expected the channel's tool list to be left alone, got %v", channelDisallowed)
+	}
+}
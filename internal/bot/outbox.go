@@ -0,0 +1,204 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/notifications"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/slacksend"
+)
+
+const (
+	// outboxInterval is how often undelivered messages are retried
+	outboxInterval = 15 * time.Second
+	// outboxBatch caps how many messages one tick delivers
+	outboxBatch = 20
+	// outboxLease keeps other instances off a message while one delivers it;
+	// if that instance dies, the message is retried after the lease
+	outboxLease = 2 * time.Minute
+	// outboxDeliveryTimeout bounds one delivery, including the sender's retries
+	outboxDeliveryTimeout = time.Minute
+	// outboxRetention is how long delivered and abandoned messages are kept
+	outboxRetention = 7 * 24 * time.Hour
+)
+
+// outboxBackoff is how long to wait after a message's attempts-th failed
+// delivery: initial, doubling per attempt up to max
+func outboxBackoff(attempts int, initial, max time.Duration) time.Duration {
+	backoff := initial
+	for i := 1; i < attempts && backoff < max; i++ {
+		backoff *= 2
+	}
+	return min(backoff, max)
+}
+
+// outboxDelayNote heads a message that is delivered late, so readers know why
+// it arrived long after the run or event behind it
+func outboxDelayNote(readyAt time.Time) string {
+	return fmt.Sprintf("⏳ _Slack was unreachable when this message was ready at %s UTC, so it was held and delivered late._\n\n",
+		readyAt.UTC().Format("15:04"))
+}
+
+// holdUndelivered stores the parts of a message Slack wouldn't take in the
+// outbox, in order, to be redelivered later. It reports false if the outbox is
+// off, the failure isn't one a retry can fix, or the parts couldn't be stored.
+func (s *Service) holdUndelivered(channelID, threadTS string, parts []string, err error) bool {
+	if s.config.OutboxMaxAge <= 0 || s.outbox == nil || !slacksend.Transient(err) {
+		return false
+	}
+
+	now := time.Now()
+	for i, part := range parts {
+		if i == 0 {
+			part = outboxDelayNote(now) + part
+		}
+		id, enqueueErr := s.outbox.EnqueueOutboxMessage(&repository.OutboxMessage{
+			ChannelID:     channelID,
+			ThreadTS:      threadTS,
+			Text:          part,
+			Attempts:      1,
+			LastError:     err.Error(),
+			NextAttemptAt: now.Add(s.config.OutboxBackoff),
+		})
+		if enqueueErr != nil {
+			s.logger.Error("Failed to hold undelivered message in the outbox",
+				zap.String("channel_id", channelID),
+				zap.Int("held", i),
+				zap.Int("parts", len(parts)),
+				zap.Error(enqueueErr))
+			return i > 0
+		}
+		s.logger.Warn("Slack didn't take a message; holding it in the outbox",
+			zap.Int("outbox_id", id),
+			zap.String("channel_id", channelID),
+			zap.String("thread_ts", threadTS),
+			zap.Error(err))
+	}
+	return true
+}
+
+// outboxStatus is a /status line counting messages waiting for Slack, or ""
+// when none are
+func (s *Service) outboxStatus() string {
+	if s.config.OutboxMaxAge <= 0 || s.outbox == nil {
+		return ""
+	}
+	pending, err := s.outbox.CountPendingOutboxMessages()
+	if err != nil {
+		s.logger.Warn("Failed to count pending outbox messages", zap.Error(err))
+		return ""
+	}
+	if pending == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n📬 Held for Slack: %d messages waiting to be redelivered", pending)
+}
+
+// outboxLoop redelivers held messages as they come due until stopped
+func (s *Service) outboxLoop() {
+	ticker := time.NewTicker(outboxInterval)
+	defer ticker.Stop()
+	purge := time.NewTicker(time.Hour)
+	defer purge.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.deliverOutbox()
+		case <-purge.C:
+			if purged, err := s.outbox.PurgeSettledOutboxMessages(time.Now().Add(-outboxRetention)); err != nil {
+				s.logger.Warn("Failed to purge settled outbox messages", zap.Error(err))
+			} else if purged > 0 {
+				s.logger.Info("Purged settled outbox messages", zap.Int("count", purged))
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// deliverOutbox claims the messages due for delivery and tries each once
+func (s *Service) deliverOutbox() {
+	messages, err := s.outbox.ClaimDueOutboxMessages(outboxBatch, outboxLease)
+	if err != nil {
+		s.logger.Error("Failed to claim due outbox messages", zap.Error(err))
+		return
+	}
+	for _, m := range messages {
+		s.deliverOutboxMessage(m)
+	}
+}
+
+// deliverOutboxMessage posts a held message, then marks it delivered,
+// schedules another attempt, or gives up on it once OUTBOX_MAX_AGE passes
+func (s *Service) deliverOutboxMessage(m *repository.OutboxMessage) {
+	ctx, cancel := context.WithTimeout(context.Background(), outboxDeliveryTimeout)
+	defer cancel()
+
+	opts := []slack.MsgOption{
+		slack.MsgOptionText(m.Text, false),
+		slack.MsgOptionAsUser(true),
+	}
+	if m.ThreadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(m.ThreadTS))
+	}
+	ts, err := s.sender.PostMessage(ctx, m.ChannelID, opts...)
+	if err == nil {
+		if _, markErr := s.outbox.MarkOutboxMessageDelivered(m.ID, ts); markErr != nil {
+			s.logger.Error("Delivered outbox message but failed to mark it",
+				zap.Int("outbox_id", m.ID),
+				zap.Error(markErr))
+		}
+		s.logger.Info("Delivered held message",
+			zap.Int("outbox_id", m.ID),
+			zap.String("channel_id", m.ChannelID),
+			zap.Int("attempts", m.Attempts),
+			zap.Duration("delay", time.Since(m.CreatedAt)))
+		return
+	}
+
+	age := time.Since(m.CreatedAt)
+	if slacksend.Transient(err) && age < s.config.OutboxMaxAge {
+		next := time.Now().Add(outboxBackoff(m.Attempts, s.config.OutboxBackoff, s.config.OutboxMaxBackoff))
+		if _, rescheduleErr := s.outbox.RescheduleOutboxMessage(m.ID, err.Error(), next); rescheduleErr != nil {
+			s.logger.Error("Failed to reschedule outbox message",
+				zap.Int("outbox_id", m.ID),
+				zap.Error(rescheduleErr))
+		}
+		s.logger.Warn("Held message still undeliverable",
+			zap.Int("outbox_id", m.ID),
+			zap.String("channel_id", m.ChannelID),
+			zap.Int("attempts", m.Attempts),
+			zap.Time("next_attempt", next),
+			zap.Error(err))
+		return
+	}
+
+	if _, abandonErr := s.outbox.AbandonOutboxMessage(m.ID, err.Error()); abandonErr != nil {
+		s.logger.Error("Failed to abandon outbox message",
+			zap.Int("outbox_id", m.ID),
+			zap.Error(abandonErr))
+	}
+	s.logger.Error("Gave up delivering held message",
+		zap.Int("outbox_id", m.ID),
+		zap.String("channel_id", m.ChannelID),
+		zap.Int("attempts", m.Attempts),
+		zap.Duration("age", age),
+		zap.Error(err))
+	s.alert(notifications.Event{
+		Kind:     notifications.EventOutboxAbandoned,
+		Severity: notifications.SeverityWarning,
+		Title:    "Undelivered Slack message abandoned",
+		Text:     fmt.Sprintf("A message held since %s UTC couldn't be delivered after %d attempts: %v", m.CreatedAt.UTC().Format("2006-01-02 15:04"), m.Attempts, err),
+		Fields: map[string]string{
+			"channel":   m.ChannelID,
+			"outbox_id": fmt.Sprint(m.ID),
+		},
+		Key: m.ChannelID,
+	})
+}
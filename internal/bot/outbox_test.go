@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/slacksend"
+)
+
+func TestOutboxBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		6:  10 * time.Minute,
+		40: 10 * time.Minute,
+	} {
+		if got := outboxBackoff(attempts, 30*time.Second, 10*time.Minute); got != want {
+			t.Errorf("outboxBackoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestOutboxDelayNote(t *testing.T) {
+	readyAt := time.Date(2024, 1, 1, 9, 30, 0, 0, time.FixedZone("PHT", 8*3600))
+	if note := outboxDelayNote(readyAt); !strings.Contains(note, "ready at 01:30 UTC") {
+		t.Errorf("Expected the note to give the time in UTC, got %q", note)
+	}
+}
+
+func TestHoldUndelivered_OnlyTransientFailures(t *testing.T) {
+	// Neither case reaches the database, which the repository doesn't have
+	s := &Service{
+		config: &config.Config{OutboxMaxAge: time.Hour},
+		outbox: repository.NewOutboxRepository(nil, zap.NewNop()),
+	}
+	if s.holdUndelivered("C1", "", []string{"reply"}, slack.SlackErrorResponse{Err: "channel_not_found"}) {
+		t.Error("Expected a permanent failure not to be held")
+	}
+
+	s.config.OutboxMaxAge = 0
+	if s.holdUndelivered("C1", "", []string{"reply"}, errors.New("connection reset")) {
+		t.Error("Expected nothing to be held with the outbox off")
+	}
+}
+
+// unavailableSlack fails every post the way Slack does during an outage
+type unavailableSlack struct {
+	slacksend.Client
+	posts int
+}
+
+func (c *unavailableSlack) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	c.posts++
+	return "", "", slack.SlackErrorResponse{Err: "service_unavailable"}
+}
+
+func TestSendThreadResponse_DoesNotHold(t *testing.T) {
+	// Holding would reach the database, which the repository doesn't have
+	client := &unavailableSlack{}
+	s := &Service{
+		config: &config.Config{OutboxMaxAge: time.Hour, MaxMessageLength: 4000},
+		outbox: repository.NewOutboxRepository(nil, zap.NewNop()),
+		logger: zap.NewNop(),
+		sender: slacksend.New(func() slacksend.Client { return client }, 0, 0, zap.NewNop()),
+	}
+	if ts := s.sendThreadResponse("C1", "", "📦 cloning…"); ts != "" || client.posts != 1 {
+		t.Errorf("sendThreadResponse() = %q after %d posts; want a failed post and nothing held", ts, client.posts)
+	}
+}
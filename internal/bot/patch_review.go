@@ -0,0 +1,396 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/files"
+	"github.com/ghabxph/claude-on-slack/internal/git"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+// patchApplyActionID is the button that applies a reviewed patch
+const patchApplyActionID = "patch_review_apply"
+
+const (
+	// maxPatchBytes caps the size of a patch that is reviewed
+	maxPatchBytes = 1 << 20
+	// patchGitTimeout bounds the git work around a review or an apply
+	patchGitTimeout = 30 * time.Second
+	// patchReviewTTL is how long a review's apply button keeps working
+	patchReviewTTL = 24 * time.Hour
+	// maxPatchFilesShown caps the files listed on a review card
+	maxPatchFilesShown = 10
+)
+
+// pendingPatch is a reviewed patch that can still be applied from its card
+type pendingPatch struct {
+	id        int
+	name      string
+	patch     []byte
+	files     []git.PatchFile
+	root      string // Repository the patch applies to
+	channelID string
+	threadTS  string
+	userID    string
+	reviewed  time.Time
+}
+
+// patchReviews holds reviewed patches until they're applied or expire
+type patchReviews struct {
+	mu      sync.Mutex
+	nextID  int
+	pending map[int]*pendingPatch
+}
+
+func newPatchReviews() *patchReviews {
+	return &patchReviews{pending: make(map[int]*pendingPatch)}
+}
+
+// hold keeps a reviewed patch, dropping expired ones, and returns its ID for
+// the apply button
+func (p *patchReviews) hold(patch *pendingPatch, now time.Time) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, held := range p.pending {
+		if now.Sub(held.reviewed) > patchReviewTTL {
+			delete(p.pending, id)
+		}
+	}
+	p.nextID++
+	patch.id = p.nextID
+	p.pending[patch.id] = patch
+	return patch.id
+}
+
+// get returns a held patch that hasn't expired, or nil
+func (p *patchReviews) get(id int, now time.Time) *pendingPatch {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	patch := p.pending[id]
+	if patch == nil || now.Sub(patch.reviewed) > patchReviewTTL {
+		return nil
+	}
+	return patch
+}
+
+func (p *patchReviews) forget(id int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pending, id)
+}
+
+// patchAttachment returns the first attached diff or patch, or nil
+func patchAttachment(attachments []slackevents.File) *slackevents.File {
+	for i := range attachments {
+		if files.IsPatchFile(attachments[i].Name, attachments[i].Mimetype, attachments[i].Filetype) {
+			return &attachments[i]
+		}
+	}
+	return nil
+}
+
+// formatPatchFiles lists the files a patch changes with their line counts
+func formatPatchFiles(patchFiles []git.PatchFile) string {
+	var list strings.Builder
+	for i, file := range patchFiles {
+		if i == maxPatchFilesShown {
+			fmt.Fprintf(&list, "\n• _and %d more_", len(patchFiles)-i)
+			break
+		}
+		if file.Added < 0 {
+			fmt.Fprintf(&list, "\n• `%s` (binary)", file.Path)
+		} else {
+			fmt.Fprintf(&list, "\n• `%s` +%d −%d", file.Path, file.Added, file.Deleted)
+		}
+	}
+	return list.String()
+}
+
+// buildPatchReviewPrompt asks Claude to review a patch applied in the
+// working directory, or only its text when it didn't apply
+func buildPatchReviewPrompt(name, patchPath string, patchFiles []git.PatchFile, applyErr error, request string) string {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Review the patch %s, saved at %s.", name, patchPath)
+	if applyErr == nil {
+		prompt.WriteString(" It has been applied to the current directory, a scratch checkout of the repository, so read the changed files in full context.")
+	} else {
+		fmt.Fprintf(&prompt, " It does not apply cleanly to the repository's HEAD (%v), so review it from the patch text and point out where it conflicts.", applyErr)
+	}
+	prompt.WriteString(" Changed files:")
+	for _, file := range patchFiles {
+		prompt.WriteString("\n- " + file.Path)
+	}
+	prompt.WriteString("\n\nReport bugs, risky or missing changes, and missing tests, most important first, citing file and line. " +
+		"Say plainly if you find nothing that should block applying it. Don't modify any files.")
+	if request = strings.TrimSpace(request); request != "" {
+		prompt.WriteString("\n\nThe author added: " + request)
+	}
+	return prompt.String()
+}
+
+// reviewPatch handles a message with a diff or patch attached: it test-applies
+// the patch in a scratch worktree of the session's repository, has Claude
+// review it there, and posts the findings with a button to apply it
+func (s *Service) reviewPatch(ctx context.Context, event *slackevents.MessageEvent, text string, attachment *slackevents.File) string {
+	threadTS := event.ThreadTimeStamp
+	if threadTS == "" {
+		threadTS = event.TimeStamp
+	}
+
+	patch, err := s.downloadPatch(attachment.ID, event.User)
+	if err != nil {
+		var quotaErr *files.QuotaError
+		if errors.As(err, &quotaErr) {
+			return formatQuotaError(attachment.Name, quotaErr)
+		}
+		errCtx := logging.CreateErrorContext(event.Channel, event.User, "patch_review", "download_patch")
+		return s.logErrorWithTrace(ctx, errCtx, err, fmt.Sprintf("Failed to download %s", attachment.Name))
+	}
+
+	userSession, err := s.sessionManager.GetOrCreateSession(event.User, event.Channel)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(event.Channel, event.User, "patch_review", "get_session")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to get session")
+	}
+	gitCtx, cancel := context.WithTimeout(ctx, patchGitTimeout)
+	defer cancel()
+	root, err := git.Root(gitCtx, userSession.GetCurrentWorkDir())
+	if err != nil {
+		return fmt.Sprintf("❌ **Can't review %s:** the session's working directory `%s` isn't a git repository. Switch to one with `/session new <path>`.",
+			attachment.Name, userSession.GetCurrentWorkDir())
+	}
+	patchFiles, err := git.PatchFiles(gitCtx, root, patch)
+	if err != nil {
+		return fmt.Sprintf("❌ **%s isn't a patch git can read:** %v", attachment.Name, err)
+	}
+
+	// The scratch worktree and the patch live together in one directory
+	// that's removed after the review
+	scratch, err := os.MkdirTemp("", "patch-review-")
+	if err != nil {
+		errCtx := logging.CreateErrorContext(event.Channel, event.User, "patch_review", "create_scratch_dir")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to prepare the patch review")
+	}
+	defer os.RemoveAll(scratch)
+	treeDir := filepath.Join(scratch, "tree")
+	if err := git.AddDetachedWorktree(gitCtx, root, treeDir); err != nil {
+		errCtx := logging.CreateErrorContext(event.Channel, event.User, "patch_review", "add_worktree")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to check out a scratch copy of the repository")
+	}
+	defer func() {
+		removeCtx, cancel := context.WithTimeout(context.Background(), patchGitTimeout)
+		defer cancel()
+		if err := git.RemoveWorktree(removeCtx, root, treeDir); err != nil {
+			s.logger.Warn("Failed to remove patch review worktree",
+				zap.String("repository", root),
+				zap.String("worktree", treeDir),
+				zap.Error(err))
+		}
+	}()
+	patchPath := filepath.Join(scratch, filepath.Base(attachment.Name))
+	if err := os.WriteFile(patchPath, patch, 0644); err != nil {
+		errCtx := logging.CreateErrorContext(event.Channel, event.User, "patch_review", "write_patch")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to prepare the patch review")
+	}
+	applyErr := git.ApplyPatch(gitCtx, treeDir, patch, false)
+	cancel()
+
+	s.logger.Info("Reviewing uploaded patch",
+		zap.String("channel_id", event.Channel),
+		zap.String("user_id", event.User),
+		zap.String("patch", attachment.Name),
+		zap.String("repository", root),
+		zap.Int("files", len(patchFiles)),
+		zap.Bool("applies", applyErr == nil))
+
+	if failed := s.preflight(treeDir); failed != nil {
+		return failed.message()
+	}
+	review, err := s.runPatchReview(ctx, event, userSession.GetID(), treeDir, scratch,
+		buildPatchReviewPrompt(attachment.Name, patchPath, patchFiles, applyErr, text))
+	if err != nil {
+		if errors.Is(context.Cause(ctx), errChannelArchived) || errors.Is(context.Cause(ctx), errRunsCleared) {
+			return "🛑 **Patch review canceled**"
+		}
+		errCtx := logging.CreateErrorContext(event.Channel, event.User, "patch_review", "claude_processing")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Claude Code processing failed")
+	}
+
+	review = s.postProcessResponse(ctx, event.Channel, event.User, userSession.GetID(), review)
+	s.sendThreadResponseOrHold(event.Channel, threadTS, fmt.Sprintf("🔍 **Review of `%s`**\n\n%s", attachment.Name, review))
+	s.postPatchCard(event, threadTS, attachment.Name, patch, patchFiles, root, applyErr)
+	return ""
+}
+
+// downloadPatch downloads an attached patch and returns its contents
+func (s *Service) downloadPatch(fileID, userID string) ([]byte, error) {
+	fileInfo, err := s.fileDownloader.DownloadFile(fileID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer s.fileDownloader.CleanupFile(fileInfo.LocalPath)
+
+	if fileInfo.Size > maxPatchBytes {
+		return nil, fmt.Errorf("patch is %d KB; patches up to %d KB are reviewed", fileInfo.Size/1024, maxPatchBytes/1024)
+	}
+	return os.ReadFile(fileInfo.LocalPath)
+}
+
+// runPatchReview runs Claude over a patch in plan mode, in a fresh Claude
+// session so the review doesn't join the channel's conversation
+func (s *Service) runPatchReview(ctx context.Context, event *slackevents.MessageEvent, sessionID, workDir, patchDir, prompt string) (string, error) {
+	defer s.beginRun()()
+
+	settings := s.channelSettings(event.Channel)
+	budgetDecision := s.applyBudgetPolicy(sessionID, event.Channel, s.channelModel(settings))
+	guardrails, err := s.channelGuardrails(event.Channel)
+	if err != nil {
+		return "", fmt.Errorf("failed to load channel guardrails: %w", err)
+	}
+	runOpts := claude.RunOptions{
+		ExtraSystemPrompt: joinPromptSections(s.channelContextPrompt(event.Channel), s.channelFactsPrompt(event.Channel), s.responsePreferencesPrompt(event.User, event.Channel)),
+		Guardrails:        guardrails,
+		Model:             budgetDecision.Model,
+		Agents:            s.channelAgents(event.Channel),
+		Env:               s.channelEnvironment(event.Channel),
+		AddDirs:           append(s.runAddDirs(sessionID), patchDir),
+		DisallowedTools:   settings.DisallowedTools,
+	}
+
+	priority, _ := s.channelRunPriority(event.Channel)
+	start := time.Now()
+	var response *claude.ClaudeCodeResponse
+	err = s.runQueued(ctx, priority, nil, func(runCtx context.Context) error {
+		var runErr error
+		response, runErr = s.claudeExecutor.ProcessClaudeCodeRequest(runCtx, prompt, uuid.New().String(), event.User, workDir, s.allowedTools(settings), true, config.PermissionModePlan, runOpts)
+		return runErr
+	})
+	if err != nil {
+		return "", err
+	}
+	s.recordUsage(sessionID, event.Channel, event.User, budgetDecision.Model, response, time.Since(start))
+	return response.Result, nil
+}
+
+// postPatchCard posts a reviewed patch's summary in the thread, with a
+// button to apply it when it applies cleanly
+func (s *Service) postPatchCard(event *slackevents.MessageEvent, threadTS, name string, patch []byte, patchFiles []git.PatchFile, root string, applyErr error) {
+	held := &pendingPatch{
+		name:      name,
+		patch:     patch,
+		files:     patchFiles,
+		root:      root,
+		channelID: event.Channel,
+		threadTS:  threadTS,
+		userID:    event.User,
+		reviewed:  time.Now(),
+	}
+	id := 0
+	if applyErr == nil {
+		id = s.patchReviews.hold(held, held.reviewed)
+	}
+
+	_, err := s.sender.PostMessage(context.Background(), event.Channel,
+		slack.MsgOptionText(fmt.Sprintf("📄 %s changes %s", name, pluralize(len(patchFiles), "file")), false),
+		slack.MsgOptionBlocks(patchCardBlocks(held, id, applyErr)...),
+		slack.MsgOptionTS(threadTS))
+	if err != nil {
+		s.logger.Warn("Failed to post patch review card",
+			zap.String("channel_id", event.Channel),
+			zap.Error(err))
+		s.patchReviews.forget(id)
+	}
+}
+
+// patchCardBlocks lays out a reviewed patch's card: the files it changes,
+// whether it applies, and the apply button while it can still be used. An id
+// of 0 leaves the button off.
+func patchCardBlocks(p *pendingPatch, id int, applyErr error) []slack.Block {
+	status := fmt.Sprintf("Applies cleanly to `%s`", p.root)
+	if applyErr != nil {
+		status = fmt.Sprintf("⚠️ Doesn't apply cleanly to `%s`: %v", p.root, applyErr)
+	}
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+			fmt.Sprintf("📄 *%s* changes %s%s", p.name, pluralize(len(p.files), "file"), formatPatchFiles(p.files)), false, false), nil, nil),
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, status, false, false)),
+	}
+	if id == 0 {
+		return blocks
+	}
+
+	apply := slack.NewButtonBlockElement(patchApplyActionID, strconv.Itoa(id), slack.NewTextBlockObject(slack.PlainTextType, "Apply to workspace", false, false))
+	apply.Style = slack.StylePrimary
+	apply.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject(slack.PlainTextType, "Apply patch?", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, fmt.Sprintf("This changes %s in %s without committing.", pluralize(len(p.files), "file"), p.root), false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Apply", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false))
+	return append(blocks, slack.NewActionBlock("patch_review_"+strconv.Itoa(id), apply))
+}
+
+// handlePatchApplyAction applies a reviewed patch to the repository it was
+// reviewed against
+func (s *Service) handlePatchApplyAction(callback *slack.InteractionCallback, action *slack.BlockAction) {
+	channelID := callback.Channel.ID
+	userID := callback.User.ID
+
+	id, err := strconv.Atoi(action.Value)
+	if err != nil {
+		return
+	}
+	p := s.patchReviews.get(id, time.Now())
+	if p == nil {
+		s.postEphemeral(channelID, userID, "ℹ️ That patch was already applied, or its review is too old. Upload it again for a fresh review.")
+		return
+	}
+
+	authCtx := &auth.AuthContext{UserID: userID, ChannelID: channelID, Command: "patch_review", Timestamp: time.Now()}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+		s.postEphemeral(channelID, userID, fmt.Sprintf("❌ Authorization failed: %v", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), patchGitTimeout)
+	defer cancel()
+	// git apply changes nothing unless every hunk applies, so a workspace
+	// that moved on since the review is left as it was
+	if err := git.ApplyPatch(ctx, p.root, p.patch, false); err != nil {
+		s.postEphemeral(channelID, userID, fmt.Sprintf("❌ **%s no longer applies to `%s`:** %v\n\nThe workspace was left unchanged.", p.name, p.root, err))
+		return
+	}
+	s.patchReviews.forget(id)
+
+	s.logger.Info("Applied reviewed patch",
+		zap.String("channel_id", channelID),
+		zap.String("user_id", userID),
+		zap.String("patch", p.name),
+		zap.String("repository", p.root),
+		zap.Int("files", len(p.files)))
+
+	blocks := append(patchCardBlocks(p, 0, nil), slack.NewContextBlock("",
+		slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("✅ Applied by <@%s>; not committed", userID), false, false)))
+	if err := s.sender.UpdateMessage(ctx, channelID, callback.Message.Timestamp,
+		slack.MsgOptionText(fmt.Sprintf("📄 %s applied", p.name), false),
+		slack.MsgOptionBlocks(blocks...)); err != nil {
+		s.logger.Warn("Failed to update patch review card", zap.Error(err))
+	}
+	s.sendThreadResponse(channelID, p.threadTS, fmt.Sprintf("✅ <@%s> applied `%s` to `%s` (%s). The changes aren't committed.",
+		userID, p.name, p.root, pluralize(len(p.files), "file")))
+}
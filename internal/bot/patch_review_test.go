@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/ghabxph/claude-on-slack/internal/git"
+)
+
+func TestPatchAttachment(t *testing.T) {
+	attachments := []slackevents.File{
+		{ID: "F1", Name: "screenshot.png", Mimetype: "image/png"},
+		{ID: "F2", Name: "fix.patch", Mimetype: "text/plain"},
+		{ID: "F3", Name: "other.diff", Mimetype: "text/x-diff"},
+	}
+	if got := patchAttachment(attachments); got == nil || got.ID != "F2" {
+		t.Errorf("patchAttachment() = %v, want the first patch F2", got)
+	}
+	if got := patchAttachment(attachments[:1]); got != nil {
+		t.Errorf("Expected no patch among images, got %v", got)
+	}
+}
+
+func TestPatchReviews_HoldAndExpire(t *testing.T) {
+	reviews := newPatchReviews()
+	reviewed := time.Now()
+	id := reviews.hold(&pendingPatch{name: "fix.patch", reviewed: reviewed}, reviewed)
+
+	if got := reviews.get(id, reviewed.Add(time.Hour)); got == nil || got.name != "fix.patch" {
+		t.Fatalf("get() = %v, want the held patch", got)
+	}
+	if got := reviews.get(id, reviewed.Add(patchReviewTTL+time.Minute)); got != nil {
+		t.Errorf("Expected an expired review to be gone, got %v", got)
+	}
+
+	// Holding another review drops expired ones
+	later := reviewed.Add(patchReviewTTL + time.Minute)
+	next := reviews.hold(&pendingPatch{name: "next.patch", reviewed: later}, later)
+	if next == id {
+		t.Errorf("Expected a new ID, got %d again", next)
+	}
+	if _, ok := reviews.pending[id]; ok {
+		t.Error("Expected the expired review to be dropped")
+	}
+	reviews.forget(next)
+	if got := reviews.get(next, later); got != nil {
+		t.Errorf("Expected forget to drop the review, got %v", got)
+	}
+}
+
+func TestFormatPatchFiles(t *testing.T) {
+	got := formatPatchFiles([]git.PatchFile{
+		{Path: "main.go", Added: 3, Deleted: 1},
+		{Path: "logo.png", Added: -1, Deleted: -1},
+	})
+	want := "\n• `main.go` +3 −1\n• `logo.png` (binary)"
+	if got != want {
+		t.Errorf("formatPatchFiles() = %q, want %q", got, want)
+	}
+
+	many := make([]git.PatchFile, maxPatchFilesShown+3)
+	for i := range many {
+		many[i] = git.PatchFile{Path: fmt.Sprintf("f%d.go", i), Added: 1}
+	}
+	if got := formatPatchFiles(many); !strings.HasSuffix(got, "_and 3 more_") || strings.Count(got, "\n") != maxPatchFilesShown+1 {
+		t.Errorf("Expected %d files and a count of the rest, got %q", maxPatchFilesShown, got)
+	}
+}
+
+func TestBuildPatchReviewPrompt(t *testing.T) {
+	patchFiles := []git.PatchFile{{Path: "main.go", Added: 3, Deleted: 1}}
+
+	applied := buildPatchReviewPrompt("fix.patch", "/tmp/review/fix.patch", patchFiles, nil, "  please check the error handling ")
+	for _, want := range []string{"/tmp/review/fix.patch", "has been applied", "\n- main.go", "Don't modify any files", "The author added: please check the error handling"} {
+		if !strings.Contains(applied, want) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", want, applied)
+		}
+	}
+
+	conflicting := buildPatchReviewPrompt("fix.patch", "/tmp/review/fix.patch", patchFiles, errors.New("patch failed: main.go:12"), "")
+	if !strings.Contains(conflicting, "does not apply cleanly") || !strings.Contains(conflicting, "main.go:12") {
+		t.Errorf("Expected the prompt to explain the conflict, got:\n%s", conflicting)
+	}
+	if strings.Contains(conflicting, "The author added") {
+		t.Errorf("Expected no author note without a message, got:\n%s", conflicting)
+	}
+}
+
+func TestPatchCardBlocks(t *testing.T) {
+	p := &pendingPatch{name: "fix.patch", root: "/repo", files: []git.PatchFile{{Path: "main.go", Added: 1}}}
+
+	blocks := patchCardBlocks(p, 7, nil)
+	if len(blocks) != 3 {
+		t.Fatalf("Expected summary, status and actions, got %d blocks", len(blocks))
+	}
+	actions, ok := blocks[2].(*slack.ActionBlock)
+	if !ok || len(actions.Elements.ElementSet) != 1 {
+		t.Fatalf("Expected one apply button, got %#v", blocks[2])
+	}
+	button := actions.Elements.ElementSet[0].(*slack.ButtonBlockElement)
+	if button.ActionID != patchApplyActionID || button.Value != "7" || button.Confirm == nil {
+		t.Errorf("Expected a confirmed apply button for review 7, got %+v", button)
+	}
+
+	// A patch that doesn't apply gets no button
+	blocks = patchCardBlocks(p, 0, errors.New("patch does not apply"))
+	if len(blocks) != 2 {
+		t.Fatalf("Expected no actions for a patch that doesn't apply, got %d blocks", len(blocks))
+	}
+	status := blocks[1].(*slack.ContextBlock).ContextElements.Elements[0].(*slack.TextBlockObject).Text
+	if !strings.Contains(status, "Doesn't apply cleanly") {
+		t.Errorf("Expected the status to say the patch doesn't apply, got %q", status)
+	}
+}
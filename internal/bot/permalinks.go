@@ -0,0 +1,203 @@
+package bot
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxMessageLinks is how many permalinks in one prompt are resolved
+	maxMessageLinks = 5
+
+	// messageLinkThreadLimit is how many thread replies are inlined per link
+	messageLinkThreadLimit = 20
+
+	// messageLinksHeader starts the inlined messages, so a replayed prompt
+	// isn't expanded twice
+	messageLinksHeader = "Referenced Slack messages:"
+)
+
+// messageLinkPattern matches Slack message permalinks such as
+// https://acme.slack.com/archives/C0123ABCD/p1700000000123456?thread_ts=1700000000.000100&cid=C0123ABCD
+var messageLinkPattern = regexp.MustCompile(`https://[A-Za-z0-9.-]+\.slack\.com/archives/([A-Z0-9]+)/p(\d{10})(\d{6})(\?[^\s<>|]*)?`)
+
+// messageLink is a parsed Slack message permalink
+type messageLink struct {
+	ChannelID string
+	Timestamp string
+	ThreadTS  string // Parent message of a thread reply; empty for top-level messages
+}
+
+// parseMessageLinks returns the distinct message permalinks in text, in order
+func parseMessageLinks(text string) []messageLink {
+	var links []messageLink
+	seen := make(map[string]bool)
+	for _, match := range messageLinkPattern.FindAllStringSubmatch(text, -1) {
+		link := messageLink{ChannelID: match[1], Timestamp: match[2] + "." + match[3]}
+		if match[4] != "" {
+			// Slack escapes & in message text
+			query, _ := url.ParseQuery(strings.ReplaceAll(strings.TrimPrefix(match[4], "?"), "&amp;", "&"))
+			if threadTS := query.Get("thread_ts"); threadTS != link.Timestamp {
+				link.ThreadTS = threadTS
+			}
+		}
+
+		key := link.ChannelID + "/" + link.Timestamp
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		links = append(links, link)
+		if len(links) == maxMessageLinks {
+			break
+		}
+	}
+	return links
+}
+
+// inlineMessageLinks appends the messages behind any Slack permalinks in
+// text, with their authors, so Claude can read what the user is pointing at.
+// Links the user can't read or that fail to load are noted as unavailable.
+func (s *Service) inlineMessageLinks(userID, channelID, text string) string {
+	if strings.Contains(text, messageLinksHeader) {
+		return text
+	}
+	links := parseMessageLinks(text)
+	if len(links) == 0 {
+		return text
+	}
+
+	var inlined strings.Builder
+	inlined.WriteString(text)
+	inlined.WriteString("\n\n" + messageLinksHeader + "\n")
+	for i, link := range links {
+		inlined.WriteString(fmt.Sprintf("\n[%d] ", i+1))
+		message, err := s.resolveMessageLink(userID, channelID, link)
+		if err != nil {
+			s.logger.Warn("Failed to resolve message link",
+				zap.String("user_id", userID),
+				zap.String("linked_channel_id", link.ChannelID),
+				zap.String("linked_ts", link.Timestamp),
+				zap.Error(err))
+			inlined.WriteString(fmt.Sprintf("A message in <#%s> that could not be loaded (%v)\n", link.ChannelID, err))
+			continue
+		}
+		inlined.WriteString(message)
+	}
+	return inlined.String()
+}
+
+// resolveMessageLink fetches a linked message, and its thread if configured,
+// formatted with attribution
+func (s *Service) resolveMessageLink(userID, channelID string, link messageLink) (string, error) {
+	if link.ChannelID != channelID {
+		member, err := s.isChannelMember(userID, link.ChannelID)
+		if err != nil {
+			return "", err
+		}
+		if !member {
+			return "", fmt.Errorf("you are not a member of that channel")
+		}
+	}
+
+	threadTS := link.ThreadTS
+	if threadTS == "" {
+		threadTS = link.Timestamp
+	}
+	replies, _, _, err := s.api().GetConversationReplies(&slack.GetConversationRepliesParameters{
+		ChannelID: link.ChannelID,
+		Timestamp: threadTS,
+		Oldest:    link.Timestamp,
+		Inclusive: true,
+		Limit:     2,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var message *slack.Message
+	for i := range replies {
+		if replies[i].Timestamp == link.Timestamp {
+			message = &replies[i]
+			break
+		}
+	}
+	if message == nil {
+		return "", fmt.Errorf("message not found")
+	}
+
+	var formatted strings.Builder
+	formatted.WriteString(fmt.Sprintf("Message posted by <@%s> in <#%s>:\n```\n%s\n```\n", messageAuthor(*message), link.ChannelID, message.Text))
+
+	if s.config.PermalinkThreadContext && (link.ThreadTS != "" || message.ReplyCount > 0) {
+		if thread := s.fetchLinkedThread(link.ChannelID, threadTS, link.Timestamp); thread != "" {
+			formatted.WriteString("Other messages in its thread:\n")
+			formatted.WriteString(thread)
+		}
+	}
+	return formatted.String(), nil
+}
+
+// fetchLinkedThread returns the messages of a thread other than the linked
+// one, formatted one per line; failures are logged and yield an empty string
+func (s *Service) fetchLinkedThread(channelID, threadTS, linkedTS string) string {
+	replies, _, _, err := s.api().GetConversationReplies(&slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: threadTS,
+		Limit:     messageLinkThreadLimit + 1,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to fetch thread for message link",
+			zap.String("channel_id", channelID),
+			zap.String("thread_ts", threadTS),
+			zap.Error(err))
+		return ""
+	}
+
+	var thread strings.Builder
+	count := 0
+	for _, reply := range replies {
+		if reply.Timestamp == linkedTS || reply.Text == "" {
+			continue
+		}
+		if count == messageLinkThreadLimit {
+			break
+		}
+		thread.WriteString(fmt.Sprintf("<@%s>: %s\n", messageAuthor(reply), reply.Text))
+		count++
+	}
+	return thread.String()
+}
+
+// isChannelMember reports whether a user belongs to a channel
+func (s *Service) isChannelMember(userID, channelID string) (bool, error) {
+	params := &slack.GetUsersInConversationParameters{ChannelID: channelID, Limit: 1000}
+	for {
+		members, cursor, err := s.api().GetUsersInConversation(params)
+		if err != nil {
+			return false, err
+		}
+		for _, member := range members {
+			if member == userID {
+				return true, nil
+			}
+		}
+		if cursor == "" {
+			return false, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+// messageAuthor returns who posted a message, falling back to the bot ID
+func messageAuthor(message slack.Message) string {
+	if message.User != "" {
+		return message.User
+	}
+	return message.BotID
+}
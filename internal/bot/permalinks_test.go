@@ -0,0 +1,24 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMessageLinks(t *testing.T) {
+	text := "explain the error in <https://acme.slack.com/archives/C0123ABCD/p1700000000123456> " +
+		"and <https://acme.slack.com/archives/C0123ABCD/p1700000100000200?thread_ts=1700000000.123456&amp;cid=C0123ABCD|this reply>, " +
+		"again https://acme.slack.com/archives/C0123ABCD/p1700000000123456"
+
+	want := []messageLink{
+		{ChannelID: "C0123ABCD", Timestamp: "1700000000.123456"},
+		{ChannelID: "C0123ABCD", Timestamp: "1700000100.000200", ThreadTS: "1700000000.123456"},
+	}
+	if links := parseMessageLinks(text); !reflect.DeepEqual(links, want) {
+		t.Errorf("parseMessageLinks() = %+v, want %+v", links, want)
+	}
+
+	if links := parseMessageLinks("see https://example.com/archives/C1/p1700000000123456"); len(links) != 0 {
+		t.Errorf("Expected non-Slack links to be ignored, got %+v", links)
+	}
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+// permissionExpiryInterval is how often expired channel permissions are reverted
+const permissionExpiryInterval = time.Minute
+
+// permissionExpiryLoop reverts expired channel permission modes until stopped
+func (s *Service) permissionExpiryLoop() {
+	expiryMgr, ok := s.sessionManager.(session.ChannelPermissionExpiryManager)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(permissionExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.revertExpiredPermissions(expiryMgr)
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// revertExpiredPermissions reverts expired channel permission modes and posts
+// the expiry notice in each reverted channel. A channel is only returned by
+// the revert that clears its expiry, so the notice is posted once.
+func (s *Service) revertExpiredPermissions(expiryMgr session.ChannelPermissionExpiryManager) {
+	channelIDs, err := expiryMgr.RevertExpiredPermissions()
+	if err != nil {
+		s.logger.Error("Failed to revert expired permissions", zap.Error(err))
+		return
+	}
+	for _, channelID := range channelIDs {
+		mode, err := s.getPermissionModeForChannel(channelID, "")
+		if err != nil {
+			mode = config.PermissionModeDefault
+		}
+		s.logger.Info("Channel permission mode expired, reverted to channel default",
+			zap.String("channel_id", channelID),
+			zap.String("mode", string(mode)))
+		s.sendResponse(channelID, fmt.Sprintf("⏰ **Permission mode expired**\n\nThis channel has been reverted to `%s` permissions.", mode))
+	}
+}
+
+// permissionExpiryNote returns " (reverts in 12m)" for a channel with a
+// temporary permission mode, or an empty string
+func (s *Service) permissionExpiryNote(channelID string) string {
+	expiryMgr, ok := s.sessionManager.(session.ChannelPermissionExpiryManager)
+	if !ok {
+		return ""
+	}
+
+	expiresAt, err := expiryMgr.GetPermissionExpiryForChannel(channelID)
+	if err != nil || expiresAt == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(" (reverts in %s)", formatRemaining(time.Until(*expiresAt)))
+}
+
+// formatRemaining renders a duration rounded to minutes, e.g. "1h5m" or "<1m"
+func formatRemaining(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+
+	d = d.Round(time.Minute)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	if minutes == 0 {
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dh%dm", hours, minutes)
+}
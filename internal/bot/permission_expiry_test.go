@@ -0,0 +1,114 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+	"github.com/ghabxph/claude-on-slack/internal/slacksend"
+)
+
+// expiryTestManager keeps channel permissions in memory and reverts them the
+// way the repository does
+type expiryTestManager struct {
+	session.SessionManager
+	channels map[string]*repository.SlackChannel
+}
+
+func (m *expiryTestManager) SetPermissionModeForChannel(channelID string, mode config.PermissionMode) error {
+	m.channels[channelID].Permission = string(mode)
+	m.channels[channelID].PermissionExpiresAt = nil
+	return nil
+}
+
+func (m *expiryTestManager) GetPermissionModeForChannel(channelID string) (config.PermissionMode, error) {
+	return config.PermissionMode(m.channels[channelID].EffectivePermission(time.Now())), nil
+}
+
+func (m *expiryTestManager) SetPermissionModeForChannelWithExpiry(channelID string, mode config.PermissionMode, ttl time.Duration) (time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	m.channels[channelID].Permission = string(mode)
+	m.channels[channelID].PermissionExpiresAt = &expiresAt
+	return expiresAt, nil
+}
+
+func (m *expiryTestManager) GetPermissionExpiryForChannel(channelID string) (*time.Time, error) {
+	return m.channels[channelID].PermissionExpiresAt, nil
+}
+
+func (m *expiryTestManager) RevertExpiredPermissions() ([]string, error) {
+	var reverted []string
+	for channelID, channel := range m.channels {
+		if channel.PermissionExpiresAt != nil && !channel.PermissionExpiresAt.After(time.Now()) {
+			channel.Permission = channel.DefaultPermission
+			channel.PermissionExpiresAt = nil
+			reverted = append(reverted, channelID)
+		}
+	}
+	return reverted, nil
+}
+
+// recordingSlack records the text of every message and ephemeral message posted
+type recordingSlack struct {
+	slacksend.Client
+	posts []string
+}
+
+func (c *recordingSlack) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	if err != nil {
+		return "", "", err
+	}
+	c.posts = append(c.posts, channelID+": "+values.Get("text"))
+	return channelID, "1700000000.000100", nil
+}
+
+func (c *recordingSlack) PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	if err != nil {
+		return "", err
+	}
+	c.posts = append(c.posts, channelID+": "+values.Get("text"))
+	return "1700000000.000100", nil
+}
+
+func TestRevertExpiredPermissions(t *testing.T) {
+	manager := &expiryTestManager{channels: map[string]*repository.SlackChannel{
+		"C1": {ChannelID: "C1", Permission: "default", DefaultPermission: "default"},
+	}}
+	client := &recordingSlack{}
+	s := &Service{
+		config:         &config.Config{MaxMessageLength: 4000},
+		logger:         zap.NewNop(),
+		sessionManager: manager,
+		sender:         slacksend.New(func() slacksend.Client { return client }, 0, 0, zap.NewNop()),
+	}
+
+	if _, err := manager.SetPermissionModeForChannelWithExpiry("C1", config.PermissionModeBypassPerms, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// Expired but not yet swept: reads already see the channel default
+	if mode, _ := s.getPermissionModeForChannel("C1", ""); mode != config.PermissionModeDefault {
+		t.Errorf("Expected an expired mode to read as the channel default, got %s", mode)
+	}
+
+	s.revertExpiredPermissions(manager)
+	s.revertExpiredPermissions(manager)
+	if len(client.posts) != 1 {
+		t.Fatalf("Expected the expiry notice to be posted once, got %q", client.posts)
+	}
+	if !strings.HasPrefix(client.posts[0], "C1: ") || !strings.Contains(client.posts[0], "reverted to `default` permissions") {
+		t.Errorf("Unexpected expiry notice %q", client.posts[0])
+	}
+	if manager.channels["C1"].Permission != "default" || manager.channels["C1"].PermissionExpiresAt != nil {
+		t.Errorf("Expected the channel reverted and its expiry cleared, got %+v", manager.channels["C1"])
+	}
+}
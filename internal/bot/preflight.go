@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// preflightDBTimeout bounds the database reachability check before a run
+const preflightDBTimeout = 5 * time.Second
+
+// preflightError is a failed pre-flight check, with advice on fixing it
+type preflightError struct {
+	Check   string
+	Problem string
+	Fix     string
+}
+
+func (e *preflightError) Error() string {
+	return fmt.Sprintf("pre-flight check %q failed: %s", e.Check, e.Problem)
+}
+
+// message is the reply shown instead of starting the run
+func (e *preflightError) message() string {
+	return fmt.Sprintf("🛑 **Pre-flight check failed: %s**\n\n%s\n\n**How to fix:** %s", e.Check, e.Problem, e.Fix)
+}
+
+// preflight checks that a run in workDir can succeed before the CLI is
+// started, so it fails fast with a specific message instead of dying minutes
+// later with a confusing stderr
+func (s *Service) preflight(workDir string) *preflightError {
+	if err := s.claudeExecutor.CheckCLI(); err != nil {
+		return &preflightError{
+			Check:   "Claude CLI",
+			Problem: fmt.Sprintf("The Claude Code CLI is no longer available: %v", err),
+			Fix:     "Reinstall the CLI or point `CLAUDE_CODE_PATH` at it, then send your message again.",
+		}
+	}
+
+	if workDir == "" {
+		workDir = s.config.WorkingDirectory
+	}
+	if workDir != "" {
+		if err := checkWorkDirWritable(workDir); err != nil {
+			return &preflightError{
+				Check:   "Working directory",
+				Problem: fmt.Sprintf("`%s` can't be used: %v", workDir, err),
+				Fix:     "Fix the directory's permissions, or switch to another directory with `/session new`.",
+			}
+		}
+
+		if minFree := uint64(s.config.PreflightMinFreeMB) * 1024 * 1024; minFree > 0 {
+			free, supported, err := freeDiskBytes(workDir)
+			if err != nil {
+				s.logger.Warn("Failed to check free disk space", zap.String("working_dir", workDir), zap.Error(err))
+			} else if supported && free < minFree {
+				return &preflightError{
+					Check: "Disk space",
+					Problem: fmt.Sprintf("Only %d MB is free on the disk holding `%s`; at least %d MB is required.",
+						free/(1024*1024), workDir, s.config.PreflightMinFreeMB),
+					Fix: "Free up disk space on the host (or lower `PREFLIGHT_MIN_FREE_MB`), then send your message again.",
+				}
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), preflightDBTimeout)
+	defer cancel()
+	if err := s.db.GetDB().PingContext(ctx); err != nil {
+		return &preflightError{
+			Check:   "Database",
+			Problem: fmt.Sprintf("The session database is unreachable: %v", err),
+			Fix:     "Check that PostgreSQL is running and the `DB_*` settings are correct, then send your message again.",
+		}
+	}
+
+	return nil
+}
+
+// checkWorkDirWritable creates dir if needed, as the run would, and checks
+// that files can be written in it
+func checkWorkDirWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	probe, err := os.CreateTemp(dir, ".claude-preflight-*")
+	if err != nil {
+		return fmt.Errorf("not writable: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
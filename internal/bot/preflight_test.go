@@ -0,0 +1,39 @@
+package bot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckWorkDirWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "new", "workdir")
+	if err := checkWorkDirWritable(dir); err != nil {
+		t.Fatalf("checkWorkDirWritable() error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Expected the directory to be created: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected the probe file to be removed, found %d entries", len(entries))
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkWorkDirWritable(file); err == nil {
+		t.Error("Expected an error for a regular file")
+	}
+}
+
+func TestFreeDiskBytes(t *testing.T) {
+	free, supported, err := freeDiskBytes(t.TempDir())
+	if err != nil {
+		t.Fatalf("freeDiskBytes() error = %v", err)
+	}
+	if supported && free == 0 {
+		t.Error("Expected some free space in the temp directory")
+	}
+}
@@ -0,0 +1,142 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// presenceTracker counts runs in progress and serializes the bot's presence
+// and status updates, which come from the heartbeat and from runs starting
+// and finishing
+type presenceTracker struct {
+	mu             sync.Mutex // held across Slack calls so updates never interleave
+	activeRuns     int
+	lastStatus     string
+	statusClient   *slack.Client // posts the status with the user token; nil without one
+	statusDisabled bool          // set when the user token can't change its profile
+	changed        chan struct{} // signals the heartbeat that the run count changed
+}
+
+// newPresenceTracker returns a tracker that posts the status with userToken,
+// since users.profile.set refuses bot tokens. Without one only presence is kept.
+func newPresenceTracker(userToken string) *presenceTracker {
+	tracker := &presenceTracker{changed: make(chan struct{}, 1)}
+	if userToken != "" {
+		tracker.statusClient = slack.New(userToken)
+	}
+	return tracker
+}
+
+// presenceStatus returns the status text and emoji for a run count
+func presenceStatus(activeRuns int) (text, emoji string) {
+	switch activeRuns {
+	case 0:
+		return "Idle", ":large_green_circle:"
+	case 1:
+		return "1 run in progress", ":hourglass_flowing_sand:"
+	default:
+		return fmt.Sprintf("%d runs in progress", activeRuns), ":hourglass_flowing_sand:"
+	}
+}
+
+// beginRun counts a Claude run toward the bot's status until the returned
+// function is called
+func (s *Service) beginRun() func() {
+	s.adjustActiveRuns(1)
+	return func() { s.adjustActiveRuns(-1) }
+}
+
+func (s *Service) adjustActiveRuns(delta int) {
+	s.presence.mu.Lock()
+	s.presence.activeRuns += delta
+	s.presence.mu.Unlock()
+
+	select {
+	case s.presence.changed <- struct{}{}:
+	default:
+	}
+}
+
+// updatePresence marks the bot active and, with PRESENCE_STATUS and
+// SLACK_USER_TOKEN, posts its load as that user's status. The status expires on its own if the heartbeat stops, so
+// a stale "Idle" never outlives the bot. Unless force is set, the status is
+// only sent when it changed.
+func (s *Service) updatePresence(force bool) {
+	s.presence.mu.Lock()
+	defer s.presence.mu.Unlock()
+
+	if err := s.api().SetUserPresence("auto"); err != nil {
+		s.logger.Debug("Failed to set bot presence", zap.Error(err))
+	}
+
+	if !s.config.PresenceStatus || s.presence.statusClient == nil || s.presence.statusDisabled || s.config.PresenceHeartbeatInterval <= 0 {
+		return
+	}
+
+	text, emoji := presenceStatus(s.presence.activeRuns)
+	if !force && text == s.presence.lastStatus {
+		return
+	}
+
+	expiration := time.Now().Add(2*s.config.PresenceHeartbeatInterval + time.Minute).Unix()
+	if err := s.presence.statusClient.SetUserCustomStatus(text, emoji, expiration); err != nil {
+		var slackErr slack.SlackErrorResponse
+		if errors.As(err, &slackErr) && isProfilePermissionError(slackErr.Err) {
+			s.presence.statusDisabled = true
+			s.logger.Warn("Status updates disabled: SLACK_USER_TOKEN can't change its user's profile (needs users.profile:write)",
+				zap.Error(err))
+			return
+		}
+		s.logger.Debug("Failed to set bot status", zap.Error(err))
+		return
+	}
+	s.presence.lastStatus = text
+}
+
+// clearPresenceStatus removes the load status on shutdown
+func (s *Service) clearPresenceStatus() {
+	s.presence.mu.Lock()
+	defer s.presence.mu.Unlock()
+
+	if s.presence.lastStatus == "" {
+		return
+	}
+	if err := s.presence.statusClient.SetUserCustomStatus("", "", 0); err != nil {
+		s.logger.Debug("Failed to clear bot status", zap.Error(err))
+	}
+	s.presence.lastStatus = ""
+}
+
+// isProfilePermissionError reports Slack errors meaning the status can never
+// be set with the user token, so retrying is pointless
+func isProfilePermissionError(code string) bool {
+	switch code {
+	case "missing_scope", "not_allowed_token_type", "no_permission", "cannot_update_admin_user":
+		return true
+	}
+	return false
+}
+
+// presenceHeartbeatLoop keeps the bot active and its status current until
+// stopped
+func (s *Service) presenceHeartbeatLoop() {
+	ticker := time.NewTicker(s.config.PresenceHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.updatePresence(true)
+		case <-s.presence.changed:
+			s.updatePresence(false)
+		case <-s.stopCh:
+			s.clearPresenceStatus()
+			return
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package bot
+
+import "testing"
+
+func TestPresenceStatus(t *testing.T) {
+	tests := []struct {
+		activeRuns int
+		text       string
+	}{
+		{0, "Idle"},
+		{1, "1 run in progress"},
+		{4, "4 runs in progress"},
+	}
+
+	for _, tt := range tests {
+		text, emoji := presenceStatus(tt.activeRuns)
+		if text != tt.text {
+			t.Errorf("presenceStatus(%d) text = %q, want %q", tt.activeRuns, text, tt.text)
+		}
+		if emoji == "" {
+			t.Errorf("presenceStatus(%d) returned no emoji", tt.activeRuns)
+		}
+	}
+}
+
+func TestBeginRun(t *testing.T) {
+	s := &Service{presence: newPresenceTracker("")}
+
+	endFirst := s.beginRun()
+	endSecond := s.beginRun()
+	if s.presence.activeRuns != 2 {
+		t.Fatalf("activeRuns = %d, want 2", s.presence.activeRuns)
+	}
+	select {
+	case <-s.presence.changed:
+	default:
+		t.Error("starting a run did not signal the heartbeat")
+	}
+
+	endFirst()
+	endSecond()
+	if s.presence.activeRuns != 0 {
+		t.Errorf("activeRuns = %d after both runs ended, want 0", s.presence.activeRuns)
+	}
+}
+
+func TestNewPresenceTracker_StatusNeedsUserToken(t *testing.T) {
+	// users.profile.set refuses bot tokens, so without a user token only presence is kept
+	if tracker := newPresenceTracker(""); tracker.statusClient != nil {
+		t.Error("Expected no status client without a user token")
+	}
+	if tracker := newPresenceTracker("xoxp-test"); tracker.statusClient == nil {
+		t.Error("Expected a status client with a user token")
+	}
+}
@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/runqueue"
+)
+
+const priorityUsage = "**Usage:** `/priority` | `/priority urgent|normal|batch` | `/priority reset`"
+
+// channelRunPriority returns the priority of runs in a channel and where it
+// came from: the channel's priority setting, or the default
+func (s *Service) channelRunPriority(channelID string) (runqueue.Priority, string) {
+	if setting := s.channelSettings(channelID).RunPriority; setting != nil {
+		if priority, err := runqueue.ParsePriority(*setting); err == nil {
+			return priority, "channel setting"
+		}
+	}
+	return runqueue.PriorityNormal, "default"
+}
+
+// rateLimitRetries is how many times a run refused by the API's rate limit
+// is requeued behind slow mode before its error is returned
+const rateLimitRetries = 3
+
+// runQueued runs fn once a run slot is free, scheduling it by priority.
+// Runs with an onPreempted callback can be preempted by urgent runs: fn's
+// context is canceled, onPreempted is called, and fn is requeued, so fn must
+// be safe to start over. In slow mode runs wait for the API to recover
+// before taking a slot, and runs the API rate limits, the one that turned
+// slow mode on included, are requeued to wait their turn the same way.
+func (s *Service) runQueued(ctx context.Context, priority runqueue.Priority, onPreempted func(), fn func(ctx context.Context) error) error {
+	retries := 0
+	for {
+		probe, err := s.slowMode.admit(ctx)
+		if err != nil {
+			return fmt.Errorf("canceled while waiting out slow mode: %w", err)
+		}
+		runCtx, release, err := s.runQueue.Acquire(ctx, priority, onPreempted != nil)
+		if err != nil {
+			s.slowMode.done(probe, false, false, time.Now())
+			return fmt.Errorf("canceled while waiting for a run slot: %w", err)
+		}
+
+		err = fn(runCtx)
+		preempted := errors.Is(context.Cause(runCtx), runqueue.ErrPreempted)
+		s.recordSlowMode(probe, runCtx, err)
+		release()
+		if preempted {
+			s.logger.Info("Run preempted by an urgent run, requeueing", zap.Stringer("priority", priority))
+			onPreempted()
+			continue
+		}
+		if on, _ := s.slowMode.status(); on && claude.IsRateLimited(err) && ctx.Err() == nil && retries < rateLimitRetries {
+			retries++
+			s.logger.Info("Run rate limited, requeueing behind slow mode",
+				zap.Stringer("priority", priority),
+				zap.Int("retry", retries))
+			continue
+		}
+		return err
+	}
+}
+
+// handlePriorityCommand shows or sets the channel's run priority
+func (s *Service) handlePriorityCommand(ctx context.Context, req *commands.Request) (string, error) {
+	if len(req.Args) == 0 {
+		priority, source := s.channelRunPriority(req.ChannelID)
+		running, waiting := s.runQueue.Stats()
+		capacity := "unlimited"
+		if s.config.MaxConcurrentRuns > 0 {
+			capacity = fmt.Sprintf("%d", s.config.MaxConcurrentRuns)
+		}
+		return fmt.Sprintf("🚦 **Run Priority:** `%s` _(%s)_\n\n"+
+			"• `urgent` - Runs first and preempts `/batch` runs when all slots are busy\n"+
+			"• `normal` - Waits behind urgent runs\n"+
+			"• `batch` - Runs last\n\n"+
+			"**Slots:** %d running, %d waiting, %s total\n\n%s",
+			priority, source, running, waiting, capacity, priorityUsage), nil
+	}
+	if len(req.Args) != 1 {
+		return "❌ **Invalid arguments**\n\n" + priorityUsage, nil
+	}
+
+	if s.settings == nil {
+		return "❌ **Run priorities require database persistence**", nil
+	}
+
+	var setting interface{}
+	if req.Args[0] != "reset" {
+		priority, err := runqueue.ParsePriority(req.Args[0])
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid priority:** %v\n\n%s", err, priorityUsage), nil
+		}
+		setting = priority.String()
+	}
+
+	authCtx := &auth.AuthContext{UserID: req.UserID, ChannelID: req.ChannelID, Command: "/priority", Timestamp: time.Now()}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+		return fmt.Sprintf("❌ Authorization failed: %v", err), nil
+	}
+
+	if err := s.storeChannelSetting(req.ChannelID, repository.ChannelSettingRunPriority, setting, req.UserID); err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "priority_command", "set_priority")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to update run priority"), nil
+	}
+
+	priority, source := s.channelRunPriority(req.ChannelID)
+	s.logger.Info("Channel run priority updated",
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID),
+		zap.Stringer("priority", priority))
+
+	return fmt.Sprintf("✅ **Run Priority:** `%s` _(%s)_\n\nApplies to runs started in this channel from now on.", priority, source), nil
+}
@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/runqueue"
+)
+
+func TestChannelRunPriority_Default(t *testing.T) {
+	// CHANNEL_PRIORITIES is imported into channel settings once and never
+	// consulted at run time, so a reset priority stays reset
+	s := &Service{config: &config.Config{ChannelPriorities: map[string]string{"C_INCIDENTS": "urgent"}}}
+
+	if priority, source := s.channelRunPriority("C_INCIDENTS"); priority != runqueue.PriorityNormal || source != "default" {
+		t.Errorf("channelRunPriority(C_INCIDENTS) = %v, %q", priority, source)
+	}
+}
+
+func TestRunQueued_RequeuesPreemptedRun(t *testing.T) {
+	s := &Service{logger: zap.NewNop(), runQueue: runqueue.New(1)}
+
+	attempts, preemptions := 0, 0
+	started := make(chan struct{}, 2)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.runQueued(context.Background(), runqueue.PriorityBatch, func() { preemptions++ }, func(ctx context.Context) error {
+			attempts++
+			started <- struct{}{}
+			if attempts == 1 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		})
+	}()
+	<-started
+
+	// The urgent run takes the batch run's slot, then hands it back
+	if err := s.runQueued(context.Background(), runqueue.PriorityUrgent, nil, func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("Urgent run failed: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil || attempts != 2 || preemptions != 1 {
+			t.Errorf("err, attempts, preemptions = %v, %d, %d; want nil, 2, 1", err, attempts, preemptions)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Preempted run was not requeued")
+	}
+}
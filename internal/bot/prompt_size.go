@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// bytesPerToken is the usual ratio of bytes to tokens for English and code
+	bytesPerToken = 4
+	// truncatedMarker ends a queued message that was cut to fit the prompt
+	truncatedMarker = " … [rest of this message removed: the prompt was too long]"
+	// minTruncatedBytes is the least of a queued message worth keeping; shorter
+	// remainders are dropped whole
+	minTruncatedBytes = 200
+)
+
+// estimateTokens approximates how many tokens text is
+func estimateTokens(text string) int {
+	return (len(text) + bytesPerToken - 1) / bytesPerToken
+}
+
+// promptTooLarge returns an error for a message too large to send to Claude,
+// or "" if it fits within maxTokens (0 = no cap)
+func promptTooLarge(text string, maxTokens int) string {
+	tokens := estimateTokens(text)
+	if maxTokens <= 0 || tokens <= maxTokens {
+		return ""
+	}
+	return fmt.Sprintf("❌ **Message too long:** it's about %s tokens, and prompts are capped at %s (`PROMPT_MAX_TOKENS`). "+
+		"Split it into smaller messages, or save the text to a file in the working directory and ask Claude to read it.",
+		formatTokens(tokens), formatTokens(maxTokens))
+}
+
+// fittedPrompt is a message combined with the messages queued behind it
+type fittedPrompt struct {
+	Text      string
+	Dropped   int // Queued messages left out
+	Truncated int // Queued messages shortened
+}
+
+// omittedNote tells Claude how many queued messages were left out
+func omittedNote(dropped int) string {
+	verb := "were"
+	if dropped == 1 {
+		verb = "was"
+	}
+	return fmt.Sprintf("[%s queued while you were busy %s left out: the prompt was too long]", pluralize(dropped, "message"), verb)
+}
+
+// fitQueuedMessages combines text with the messages queued while the session
+// was busy, which follow it oldest first. When the result is
+// over maxTokens (0 = no cap), the oldest queued messages are shortened or
+// left out until it fits; text itself is never trimmed.
+func fitQueuedMessages(text string, queued []string, maxTokens int) fittedPrompt {
+	joined := strings.Join(append([]string{text}, queued...), " ")
+	if maxTokens <= 0 || estimateTokens(joined) <= maxTokens {
+		return fittedPrompt{Text: joined}
+	}
+
+	// Leave room for the note about dropped messages
+	over := len(joined) - maxTokens*bytesPerToken + len(omittedNote(len(queued))) + 1
+	kept := append([]string{}, queued...)
+	var fit fittedPrompt
+	for len(kept) > 0 && over > 0 {
+		oldest := kept[0]
+		if keep := len(oldest) - over - len(truncatedMarker); keep >= minTruncatedBytes {
+			for keep > 0 && !utf8.RuneStart(oldest[keep]) {
+				keep--
+			}
+			kept[0] = oldest[:keep] + truncatedMarker
+			fit.Truncated++
+			break
+		}
+		over -= len(oldest) + 1
+		kept = kept[1:]
+		fit.Dropped++
+	}
+
+	parts := []string{text}
+	if fit.Dropped > 0 {
+		parts = append(parts, omittedNote(fit.Dropped))
+	}
+	fit.Text = strings.Join(append(parts, kept...), " ")
+	return fit
+}
+
+// trimmedQueueNotice tells the user which of their queued messages Claude
+// won't see in full
+func trimmedQueueNotice(fit fittedPrompt, maxTokens int) string {
+	var trimmed []string
+	if fit.Dropped > 0 {
+		trimmed = append(trimmed, fmt.Sprintf("left out the %s queued first", pluralize(fit.Dropped, "message")))
+	}
+	if fit.Truncated > 0 {
+		trimmed = append(trimmed, "shortened the next one")
+	}
+	return fmt.Sprintf("✂️ _Your messages together were over the %s-token prompt limit, so I %s. Resend anything Claude still needs in smaller parts._",
+		formatTokens(maxTokens), strings.Join(trimmed, " and "))
+}
@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	for text, want := range map[string]int{"": 0, "abc": 1, "abcd": 1, "abcde": 2} {
+		if got := estimateTokens(text); got != want {
+			t.Errorf("estimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestPromptTooLarge(t *testing.T) {
+	if got := promptTooLarge(strings.Repeat("a", 400), 100); got != "" {
+		t.Errorf("Expected a prompt at the cap to fit, got %q", got)
+	}
+	if got := promptTooLarge(strings.Repeat("a", 1<<20), 0); got != "" {
+		t.Errorf("Expected no cap at 0, got %q", got)
+	}
+	got := promptTooLarge(strings.Repeat("a", 8000), 1000)
+	if !strings.Contains(got, "about 2k tokens") || !strings.Contains(got, "`PROMPT_MAX_TOKENS`") {
+		t.Errorf("Expected the size and the setting in the error, got %q", got)
+	}
+}
+
+func TestFitQueuedMessages_UnderTheCap(t *testing.T) {
+	fit := fitQueuedMessages("now", []string{"first", "second"}, 100)
+	if fit.Text != "now first second" || fit.Dropped != 0 || fit.Truncated != 0 {
+		t.Errorf("Expected messages joined untouched, got %+v", fit)
+	}
+}
+
+func TestFitQueuedMessages_DropsOldestFirst(t *testing.T) {
+	oldest := strings.Repeat("o", 150)
+	newest := strings.Repeat("n", 100)
+	fit := fitQueuedMessages("now", []string{oldest, newest}, 60)
+
+	if fit.Dropped != 1 || fit.Truncated != 0 {
+		t.Fatalf("Expected the oldest message dropped, got %+v", fit)
+	}
+	if strings.Contains(fit.Text, oldest[:10]) {
+		t.Errorf("Expected the oldest message left out, got %q", fit.Text)
+	}
+	if !strings.HasPrefix(fit.Text, "now [1 message queued while you were busy was left out") || !strings.HasSuffix(fit.Text, newest) {
+		t.Errorf("Expected the message, a note, and the newest message, got %q", fit.Text)
+	}
+	if tokens := estimateTokens(fit.Text); tokens > 60 {
+		t.Errorf("Expected the prompt to fit in 60 tokens, got %d", tokens)
+	}
+}
+
+func TestFitQueuedMessages_TruncatesLongMessages(t *testing.T) {
+	long := strings.Repeat("é", 2000) // 4000 bytes
+	fit := fitQueuedMessages("now", []string{long, "latest"}, 500)
+
+	if fit.Dropped != 0 || fit.Truncated != 1 {
+		t.Fatalf("Expected the oldest message shortened, got %+v", fit)
+	}
+	if !strings.Contains(fit.Text, truncatedMarker) || !strings.HasSuffix(fit.Text, " latest") {
+		t.Errorf("Expected the marker and the latest message kept, got %q", fit.Text[len(fit.Text)-100:])
+	}
+	if !utf8.ValidString(fit.Text) {
+		t.Error("Expected truncation to keep the prompt valid UTF-8")
+	}
+	if tokens := estimateTokens(fit.Text); tokens > 500 {
+		t.Errorf("Expected the prompt to fit in 500 tokens, got %d", tokens)
+	}
+}
+
+func TestTrimmedQueueNotice(t *testing.T) {
+	got := trimmedQueueNotice(fittedPrompt{Dropped: 2, Truncated: 1}, 100000)
+	want := "✂️ _Your messages together were over the 100k-token prompt limit, so I left out the 2 messages queued first and shortened the next one. Resend anything Claude still needs in smaller parts._"
+	if got != want {
+		t.Errorf("trimmedQueueNotice() = %q, want %q", got, want)
+	}
+}
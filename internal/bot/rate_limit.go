@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/ratelimit"
+)
+
+// checkRateLimit counts one incoming request against the user's and the
+// channel's rate limits. It returns nil, or the limit that refused it.
+func (s *Service) checkRateLimit(userID, channelID string) *auth.RateLimitError {
+	var limited *auth.RateLimitError
+	if errors.As(s.authService.CheckRateLimit(userID, channelID), &limited) {
+		return limited
+	}
+	return nil
+}
+
+// rateLimitMessage tells the user exactly when they can send another request
+func (s *Service) rateLimitMessage(userID string, limited *auth.RateLimitError) string {
+	who := "You've used up your"
+	if limited.Scope == ratelimit.ScopeChannel {
+		who = "This channel has used up its"
+	}
+	return fmt.Sprintf("⏱️ **Rate limit reached**\n\n%s burst of %d requests. Capacity for the next one returns in %v, at %s.",
+		who, limited.Limit, limited.RetryAfter.Round(time.Second), s.userTime(userID, limited.RetryAt).Format("15:04:05 MST"))
+}
+
+// setRateLimitHeaders adds Retry-After and X-RateLimit-* headers to an HTTP
+// response for a refused request
+func setRateLimitHeaders(h http.Header, limited *auth.RateLimitError) {
+	h.Set("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+	h.Set("X-RateLimit-Limit", strconv.Itoa(limited.Limit))
+	h.Set("X-RateLimit-Remaining", "0")
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(limited.RetryAt.Unix(), 10))
+	h.Set("X-RateLimit-Scope", limited.Scope)
+}
@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+const (
+	// recapTimeout bounds generating a recap after a session switch
+	recapTimeout = 2 * time.Minute
+
+	// recapMaxInput is how much of the most recent conversation is recapped
+	recapMaxInput = 100000
+)
+
+// pendingRecaps holds recaps waiting to be given to Claude on the next run
+// of the session they describe
+type pendingRecaps struct {
+	mu     sync.Mutex
+	recaps map[string]string
+}
+
+func newPendingRecaps() *pendingRecaps {
+	return &pendingRecaps{recaps: make(map[string]string)}
+}
+
+func (p *pendingRecaps) set(sessionID, recap string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recaps[sessionID] = recap
+}
+
+// take returns the pending recap for a session, if any, and clears it
+func (p *pendingRecaps) take(sessionID string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	recap := p.recaps[sessionID]
+	delete(p.recaps, sessionID)
+	return recap
+}
+
+// recapPrompt wraps a recap as system prompt context
+func recapPrompt(recap string) string {
+	return "CONVERSATION RECAP - The user just switched back to this conversation. " +
+		"Earlier context may no longer be available, so use this recap of where it left off:\n" + recap
+}
+
+// sendSessionRecap posts a recap of the session the user switched to and
+// queues it as context for the session's next run. Sessions without any
+// exchanges have nothing to recap, and sessions not visible in the channel
+// get none.
+func (s *Service) sendSessionRecap(userID, channelID string) {
+	userSession, err := s.sessionManager.GetOrCreateSession(userID, channelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_recap", "get_session")
+		s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to get session for recap")
+		return
+	}
+
+	parentSessionID := userSession.GetID()
+	// The recap is posted in the channel, so only sessions listable here get one
+	visible, err := s.sessionVisibleIn(channelID, parentSessionID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_recap", "check_visibility")
+		s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to check session visibility for recap")
+		return
+	}
+	if !visible {
+		return
+	}
+
+	children, err := s.sessionManager.GetConversationTree(parentSessionID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_recap", "get_conversation_tree")
+		s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to get conversation for recap")
+		return
+	}
+	if len(children) == 0 {
+		return
+	}
+
+	recap, err := s.sessionRecap(userID, parentSessionID, children)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_recap", "generate_recap")
+		errCtx.WithSession(parentSessionID)
+		s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to generate session recap")
+		return
+	}
+
+	s.recaps.set(parentSessionID, recap)
+	s.sendResponse(channelID, fmt.Sprintf("🧭 **Where you left off** in `%s`\n\n%s", parentSessionID, s.formatSummaryForSlack(recap)))
+}
+
+// sessionRecap returns the summary stored with the latest exchange, generating
+// and storing one when there is none yet
+func (s *Service) sessionRecap(userID, parentSessionID string, children []*repository.ChildSession) (string, error) {
+	latest := children[len(children)-1]
+	if latest.Summary != nil && strings.TrimSpace(*latest.Summary) != "" {
+		return *latest.Summary, nil
+	}
+
+	conversationText, err := s.formatConversationForSummary(userID, parentSessionID, children)
+	if err != nil {
+		return "", err
+	}
+	if len(conversationText) > recapMaxInput {
+		conversationText = conversationText[len(conversationText)-recapMaxInput:]
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), recapTimeout)
+	defer cancel()
+	recap, err := s.claudeExecutor.ExecuteClaudeRecap(ctx, conversationText)
+	if err != nil {
+		return "", err
+	}
+	recap = strings.TrimSpace(recap)
+
+	// Later switches back to the same point reuse the stored recap
+	if summaryMgr, ok := s.sessionManager.(session.SessionSummaryManager); ok {
+		if err := summaryMgr.SetChildSessionSummary(latest.ID, recap); err != nil {
+			s.logger.Warn("Failed to store session recap",
+				zap.String("session_id", parentSessionID),
+				zap.Error(err))
+		}
+	}
+
+	return recap, nil
+}
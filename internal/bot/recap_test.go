@@ -0,0 +1,18 @@
+package bot
+
+import "testing"
+
+func TestPendingRecapsTake(t *testing.T) {
+	recaps := newPendingRecaps()
+	recaps.set("session-1", "• Fixed the login redirect")
+
+	if got := recaps.take("session-2"); got != "" {
+		t.Errorf("take() for another session = %q, want empty", got)
+	}
+	if got := recaps.take("session-1"); got != "• Fixed the login redirect" {
+		t.Errorf("take() = %q", got)
+	}
+	if got := recaps.take("session-1"); got != "" {
+		t.Errorf("Expected the recap to be used once, got %q", got)
+	}
+}
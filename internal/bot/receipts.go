@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// runOutcome is how a run ended, as its receipt reports it
+type runOutcome string
+
+const (
+	runCompleted runOutcome = "completed"
+	runPartial   runOutcome = "partial"
+	runFailed    runOutcome = "failed"
+	runCanceled  runOutcome = "canceled"
+)
+
+// runOutcomeIcons lead each receipt so outcomes can be scanned at a glance
+var runOutcomeIcons = map[runOutcome]string{
+	runCompleted: "✅",
+	runPartial:   "⚠️",
+	runFailed:    "❌",
+	runCanceled:  "🛑",
+}
+
+// runReceipt is the compact record of one run posted to RECEIPTS_CHANNEL.
+// Runs start out failed and are marked otherwise as they finish.
+type runReceipt struct {
+	UserID    string
+	ChannelID string
+	MessageTS string // The prompt's message, for a link back to it
+	Prompt    string
+	Started   time.Time
+	CostUSD   float64
+	Outcome   runOutcome
+}
+
+// escapeSlackText keeps text from being read as Slack markup, so a prompt
+// quoted in a receipt can't mention or ping anyone
+func escapeSlackText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// receiptPrompt is the first non-empty line of a prompt, shortened
+func receiptPrompt(prompt string) string {
+	for _, line := range strings.Split(prompt, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return escapeSlackText(truncatePrompt(line))
+		}
+	}
+	return "_(no text)_"
+}
+
+// formatRunReceipt renders a receipt. The user is named rather than
+// mentioned so receipts don't notify them; link points back at the prompt.
+func formatRunReceipt(r *runReceipt, userName, link string, elapsed time.Duration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s *%s* in <#%s>", runOutcomeIcons[r.Outcome], escapeSlackText(userName), r.ChannelID)
+	fmt.Fprintf(&b, " · %s", formatElapsed(elapsed))
+	if r.CostUSD > 0 {
+		fmt.Fprintf(&b, " · $%.4f", r.CostUSD)
+	}
+	fmt.Fprintf(&b, " · %s", r.Outcome)
+	if link != "" {
+		fmt.Fprintf(&b, " · <%s|view>", link)
+	}
+	fmt.Fprintf(&b, "\n> %s", receiptPrompt(r.Prompt))
+	return b.String()
+}
+
+// postRunReceipt posts a run's receipt to RECEIPTS_CHANNEL in the background.
+// Defer it once a run starts; it does nothing when receipts are off.
+func (s *Service) postRunReceipt(r *runReceipt) {
+	if s.config.ReceiptsChannel == "" || r.ChannelID == s.config.ReceiptsChannel {
+		return
+	}
+	elapsed := time.Since(r.Started)
+
+	go func() {
+		link := ""
+		if r.MessageTS != "" {
+			permalink, err := s.api().GetPermalink(&slack.PermalinkParameters{Channel: r.ChannelID, Ts: r.MessageTS})
+			if err != nil {
+				s.logger.Debug("Failed to get receipt permalink", zap.String("channel_id", r.ChannelID), zap.Error(err))
+			} else {
+				link = permalink
+			}
+		}
+
+		text := formatRunReceipt(r, s.authService.DisplayName(r.UserID), link, elapsed)
+		if _, err := s.sender.PostMessage(context.Background(), s.config.ReceiptsChannel,
+			slack.MsgOptionText(text, false),
+			slack.MsgOptionDisableLinkUnfurl()); err != nil {
+			s.logger.Warn("Failed to post run receipt",
+				zap.String("receipts_channel", s.config.ReceiptsChannel),
+				zap.String("channel_id", r.ChannelID),
+				zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatRunReceipt(t *testing.T) {
+	receipt := &runReceipt{
+		UserID:    "U123",
+		ChannelID: "C456",
+		Prompt:    "\n  Fix the flaky test in <@U999>'s module\nand then run the suite",
+		CostUSD:   0.0312,
+		Outcome:   runCompleted,
+	}
+	text := formatRunReceipt(receipt, "Ada", "https://example.slack.com/archives/C456/p1", 42*time.Second)
+
+	for _, want := range []string{"✅ *Ada* in <#C456>", "42s", "$0.0312", "completed", "<https://example.slack.com/archives/C456/p1|view>", "> Fix the flaky test in &lt;@U999&gt;'s module"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected receipt to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "<@") {
+		t.Errorf("Expected the receipt to mention nobody, got:\n%s", text)
+	}
+	if strings.Contains(text, "run the suite") {
+		t.Errorf("Expected only the prompt's first line, got:\n%s", text)
+	}
+}
+
+func TestFormatRunReceipt_Failures(t *testing.T) {
+	receipt := &runReceipt{ChannelID: "C456", Outcome: runFailed}
+	text := formatRunReceipt(receipt, "<!channel>", "", time.Second)
+
+	if !strings.HasPrefix(text, "❌ *&lt;!channel&gt;*") {
+		t.Errorf("Expected a failure icon and an escaped name, got:\n%s", text)
+	}
+	if strings.Contains(text, "$") || strings.Contains(text, "view") {
+		t.Errorf("Expected no cost or link for a failed run without them, got:\n%s", text)
+	}
+	if !strings.Contains(text, "_(no text)_") {
+		t.Errorf("Expected a placeholder for an empty prompt, got:\n%s", text)
+	}
+}
+
+func TestReceiptPrompt_Truncates(t *testing.T) {
+	prompt := receiptPrompt(strings.Repeat("word ", 50))
+	if !strings.HasSuffix(prompt, "…") || len([]rune(prompt)) > 81 {
+		t.Errorf("Expected a long prompt to be shortened, got %q", prompt)
+	}
+}
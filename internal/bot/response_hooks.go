@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/postprocess"
+)
+
+// responseWithheldMessage replaces a response a blocking hook failed on
+const responseWithheldMessage = "⚠️ **Response withheld**\n\nThe `%s` post-processing hook failed, and replies aren't posted without it. An admin can check the logs."
+
+// newResponsePipeline builds the RESPONSE_HOOKS pipeline. Names other than
+// the built-in hooks must have been registered with postprocess.Register.
+func newResponsePipeline(cfg *config.Config, logger *zap.Logger) (*postprocess.Pipeline, error) {
+	var stages []postprocess.Stage
+	for _, entry := range cfg.ResponseHooks {
+		var hook postprocess.Hook
+		switch entry.Name {
+		case "redact":
+			pattern, err := regexp.Compile(cfg.ResponseRedactPattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RESPONSE_REDACT_PATTERN: %w", err)
+			}
+			hook = postprocess.NewRedactHook(pattern, cfg.ResponseRedactReplacement)
+		case "footer":
+			hook = postprocess.NewFooterHook(cfg.ResponseFooter)
+		case "webhook":
+			hook = postprocess.NewWebhookHook(cfg.ResponseWebhookURL)
+		case "forward":
+			hook = postprocess.NewForwardHook(cfg.ResponseForwardURL)
+		default:
+			registered, ok := postprocess.Registered(entry.Name)
+			if !ok {
+				known := append([]string{"redact", "footer", "webhook", "forward"}, postprocess.RegisteredNames()...)
+				return nil, fmt.Errorf("unknown response hook %q in RESPONSE_HOOKS (available: %s)", entry.Name, strings.Join(known, ", "))
+			}
+			hook = registered
+		}
+
+		stages = append(stages, postprocess.Stage{
+			Hook:      hook,
+			Timeout:   cfg.ResponseHookTimeout,
+			OnFailure: postprocess.FailurePolicy(entry.OnFailure),
+		})
+	}
+
+	if len(stages) > 0 {
+		logger.Info("Response post-processing enabled", zap.Int("hooks", len(stages)))
+	}
+	return postprocess.NewPipeline(logger, stages...), nil
+}
+
+// redactToolEvent runs text about a tool call, such as a Bash command,
+// through the same redact hooks as responses, so what's hidden in replies
+// isn't shown in the thread. Only redaction applies; footers and webhooks
+// are for responses. ok is false if a blocking redact hook failed and the
+// text shouldn't be posted. Redact hooks are regular expressions, quick
+// enough to run on the CLI output reader.
+func (s *Service) redactToolEvent(ctx context.Context, channelID, text string) (string, bool) {
+	if s.responseHooks == nil {
+		return text, true
+	}
+	redaction := s.responseHooks.Only("redact")
+	if redaction.Len() == 0 {
+		return text, true
+	}
+
+	redacted, err := redaction.Run(ctx, postprocess.Response{Text: text, ChannelID: channelID})
+	if err != nil {
+		return "", false
+	}
+	return redacted, true
+}
+
+// postProcessResponse runs a reply through the response hooks and returns
+// what to post in its place
+func (s *Service) postProcessResponse(ctx context.Context, channelID, userID, sessionID, response string) string {
+	if s.responseHooks == nil || s.responseHooks.Len() == 0 {
+		return response
+	}
+
+	processed, err := s.responseHooks.Run(ctx, postprocess.Response{
+		Text:      response,
+		ChannelID: channelID,
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	var blocked *postprocess.BlockedError
+	if errors.As(err, &blocked) {
+		return fmt.Sprintf(responseWithheldMessage, blocked.Hook)
+	}
+	return processed
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+func TestNewResponsePipeline(t *testing.T) {
+	cfg := &config.Config{
+		ResponseHooks: []config.ResponseHook{
+			{Name: "redact", OnFailure: "block"},
+			{Name: "footer", OnFailure: "skip"},
+		},
+		ResponseHookTimeout:       time.Second,
+		ResponseRedactPattern:     `sk-[a-z0-9]+`,
+		ResponseRedactReplacement: "[redacted]",
+		ResponseFooter:            "_Reviewed by policy._",
+	}
+	pipeline, err := newResponsePipeline(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("newResponsePipeline() error = %v", err)
+	}
+
+	s := &Service{logger: zap.NewNop(), responseHooks: pipeline}
+	got := s.postProcessResponse(context.Background(), "C1", "U1", "s-1", "Use sk-abc123.")
+	if want := "Use [redacted].\n\n_Reviewed by policy._"; got != want {
+		t.Errorf("postProcessResponse() = %q, want %q", got, want)
+	}
+
+	cfg.ResponseHooks = append(cfg.ResponseHooks, config.ResponseHook{Name: "translate", OnFailure: "skip"})
+	if _, err := newResponsePipeline(cfg, zap.NewNop()); err == nil || !strings.Contains(err.Error(), `unknown response hook "translate"`) {
+		t.Errorf("Expected an unregistered hook to be rejected, got %v", err)
+	}
+}
+
+func TestPostProcessResponse_WithholdsBlockedResponses(t *testing.T) {
+	cfg := &config.Config{
+		ResponseHooks:       []config.ResponseHook{{Name: "webhook", OnFailure: "block"}},
+		ResponseHookTimeout: time.Second,
+		ResponseWebhookURL:  "http://127.0.0.1:1/translate",
+	}
+	pipeline, err := newResponsePipeline(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Service{logger: zap.NewNop(), responseHooks: pipeline}
+	got := s.postProcessResponse(context.Background(), "C1", "U1", "s-1", "secret plans")
+	if strings.Contains(got, "secret plans") || !strings.Contains(got, "Response withheld") || !strings.Contains(got, "`webhook`") {
+		t.Errorf("Expected the response to be withheld, got %q", got)
+	}
+
+	if got := (&Service{}).postProcessResponse(context.Background(), "C1", "U1", "s-1", "as is"); got != "as is" {
+		t.Errorf("Expected no pipeline to leave the response alone, got %q", got)
+	}
+}
+
+func TestRedactToolEvent(t *testing.T) {
+	cfg := &config.Config{
+		ResponseHooks: []config.ResponseHook{
+			{Name: "redact", OnFailure: "block"},
+			{Name: "footer", OnFailure: "skip"},
+		},
+		ResponseHookTimeout:       time.Second,
+		ResponseRedactPattern:     `sk-[a-z0-9]+`,
+		ResponseRedactReplacement: "[redacted]",
+		ResponseFooter:            "_Reviewed by policy._",
+	}
+	pipeline, err := newResponsePipeline(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("newResponsePipeline() error = %v", err)
+	}
+
+	s := &Service{logger: zap.NewNop(), responseHooks: pipeline}
+	got, ok := s.redactToolEvent(context.Background(), "C1", "curl -H 'Authorization: Bearer sk-abc123' https://api.example.com")
+	if want := "curl -H 'Authorization: Bearer [redacted]' https://api.example.com"; got != want || !ok {
+		t.Errorf("redactToolEvent() = %q, %v; want %q without the footer", got, ok, want)
+	}
+
+	if got, ok := (&Service{}).redactToolEvent(context.Background(), "C1", "go test ./..."); got != "go test ./..." || !ok {
+		t.Errorf("redactToolEvent() without hooks = %q, %v", got, ok)
+	}
+}
@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const prefsUsage = "**Usage:** `/prefs show` | `/prefs set [channel] <name> <value>` | `/prefs unset [channel] <name>`"
+
+// responsePreference describes a /prefs setting
+type responsePreference struct {
+	Name        string
+	Description string
+	Values      []string // Allowed values; empty means free text matching responseLanguagePattern
+	Prompt      func(value string) string
+}
+
+// responsePreferences lists the settings in the order they are shown
+var responsePreferences = []responsePreference{
+	{
+		Name:        "verbosity",
+		Description: "How long replies are",
+		Values:      []string{"concise", "detailed"},
+		Prompt: func(value string) string {
+			if value == "concise" {
+				return "Keep replies concise: lead with the answer, skip preamble and recaps, and only explain what was asked."
+			}
+			return "Give detailed replies: explain your reasoning, the alternatives you considered, and anything the user should double-check."
+		},
+	},
+	{
+		Name:        "language",
+		Description: "Language to reply in, e.g. `Spanish`",
+		Prompt: func(value string) string {
+			return fmt.Sprintf("Reply in %s. Keep code, commands, file paths, and identifiers as they are.", value)
+		},
+	},
+	{
+		Name:        "comments",
+		Description: "Comment style in code you write",
+		Values:      []string{"none", "minimal", "thorough"},
+		Prompt: func(value string) string {
+			switch value {
+			case "none":
+				return "Don't add comments to code you write unless the user asks for them."
+			case "minimal":
+				return "Only comment code where the intent isn't obvious from the code itself."
+			default:
+				return "Comment code you write thoroughly: document functions and explain non-obvious steps."
+			}
+		},
+	},
+}
+
+// responseLanguagePattern matches free-text preference values such as
+// "Spanish" or "Brazilian Portuguese"
+var responseLanguagePattern = regexp.MustCompile(`^\p{L}[\p{L} ()-]{0,39}$`)
+
+// lookupResponsePreference finds a setting by name
+func lookupResponsePreference(name string) (responsePreference, bool) {
+	for _, pref := range responsePreferences {
+		if pref.Name == name {
+			return pref, true
+		}
+	}
+	return responsePreference{}, false
+}
+
+// normalizeResponsePreference validates a value for a setting and returns it
+// in its stored form
+func normalizeResponsePreference(pref responsePreference, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if len(pref.Values) == 0 {
+		if !responseLanguagePattern.MatchString(value) {
+			return "", fmt.Errorf("`%s` must be a language name of up to 40 letters", pref.Name)
+		}
+		return value, nil
+	}
+
+	value = strings.ToLower(value)
+	for _, allowed := range pref.Values {
+		if value == allowed {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("`%s` must be one of `%s`", pref.Name, strings.Join(pref.Values, "`, `"))
+}
+
+// effectiveResponsePreferences picks each setting's value, with the user's
+// own preference taking precedence over the channel's
+func effectiveResponsePreferences(prefs []*repository.ResponsePreference) map[string]*repository.ResponsePreference {
+	effective := make(map[string]*repository.ResponsePreference)
+	for _, pref := range prefs {
+		if current, ok := effective[pref.Name]; ok && current.Scope == repository.PreferenceScopeUser {
+			continue
+		}
+		effective[pref.Name] = pref
+	}
+	return effective
+}
+
+// buildResponsePreferencesPrompt renders effective preferences as system
+// prompt instructions, or "" if none are set
+func buildResponsePreferencesPrompt(effective map[string]*repository.ResponsePreference) string {
+	var lines []string
+	for _, pref := range responsePreferences {
+		if value, ok := effective[pref.Name]; ok {
+			lines = append(lines, "- "+pref.Prompt(value.Value))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "RESPONSE PREFERENCES - The user asked you to follow these:\n" + strings.Join(lines, "\n")
+}
+
+// responsePreferencesPrompt returns the system prompt instructions for a
+// user's run in a channel. Preferences are best effort; failures are logged.
+func (s *Service) responsePreferencesPrompt(userID, channelID string) string {
+	prefs, err := s.prefs.GetResponsePreferences(userID, channelID)
+	if err != nil {
+		s.logger.Warn("Failed to get response preferences",
+			zap.String("user_id", userID),
+			zap.String("channel_id", channelID),
+			zap.Error(err))
+		return ""
+	}
+	return buildResponsePreferencesPrompt(effectiveResponsePreferences(prefs))
+}
+
+// handlePrefsCommand handles /prefs show, set, and unset
+func (s *Service) handlePrefsCommand(ctx context.Context, req *commands.Request) (string, error) {
+	args := req.Args
+	if len(args) == 0 {
+		args = []string{"show"}
+	}
+
+	switch args[0] {
+	case "show":
+		if len(args) != 1 {
+			return "❌ **Invalid arguments**\n\n" + prefsUsage, nil
+		}
+		return s.handlePrefsShowCommand(ctx, req), nil
+	case "set", "unset":
+		scope, scopeID, rest := repository.PreferenceScopeUser, req.UserID, args[1:]
+		if len(rest) > 0 && rest[0] == "channel" {
+			authCtx := &auth.AuthContext{UserID: req.UserID, ChannelID: req.ChannelID, Command: "/prefs", Timestamp: time.Now()}
+			if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+				return fmt.Sprintf("❌ Authorization failed: %v", err), nil
+			}
+			scope, scopeID, rest = repository.PreferenceScopeChannel, req.ChannelID, rest[1:]
+		}
+
+		if args[0] == "set" {
+			if len(rest) < 2 {
+				return "❌ **Invalid arguments**\n\n" + prefsUsage, nil
+			}
+			return s.handlePrefsSetCommand(ctx, req, scope, scopeID, rest[0], strings.Join(rest[1:], " ")), nil
+		}
+		if len(rest) != 1 {
+			return "❌ **Invalid arguments**\n\n" + prefsUsage, nil
+		}
+		return s.handlePrefsUnsetCommand(ctx, req, scope, scopeID, rest[0]), nil
+	}
+	return prefsUsage, nil
+}
+
+func (s *Service) handlePrefsShowCommand(ctx context.Context, req *commands.Request) string {
+	prefs, err := s.prefs.GetResponsePreferences(req.UserID, req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "prefs_command", "get_preferences")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to get preferences")
+	}
+	effective := effectiveResponsePreferences(prefs)
+
+	var response strings.Builder
+	response.WriteString("🎛️ **Response Preferences**\n")
+	for _, pref := range responsePreferences {
+		value, ok := effective[pref.Name]
+		if !ok {
+			response.WriteString(fmt.Sprintf("\n• `%s`: _not set_ — %s", pref.Name, pref.Description))
+			continue
+		}
+		source := "yours"
+		if value.Scope == repository.PreferenceScopeChannel {
+			source = "channel default"
+		}
+		response.WriteString(fmt.Sprintf("\n• `%s`: `%s` _(%s)_", pref.Name, value.Value, source))
+	}
+	response.WriteString("\n\nChange one with `/prefs set <name> <value>`, or `/prefs set channel <name> <value>` for everyone in this channel. Your own preferences win over the channel's.")
+	return response.String()
+}
+
+func (s *Service) handlePrefsSetCommand(ctx context.Context, req *commands.Request, scope, scopeID, name, value string) string {
+	pref, ok := lookupResponsePreference(strings.ToLower(name))
+	if !ok {
+		return fmt.Sprintf("❌ **Unknown preference:** `%s`\n\nSee `/prefs show` for the available settings.", name)
+	}
+	value, err := normalizeResponsePreference(pref, value)
+	if err != nil {
+		return fmt.Sprintf("❌ **Invalid value:** %v", err)
+	}
+
+	if err := s.prefs.SetResponsePreference(scope, scopeID, pref.Name, value, req.UserID); err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "prefs_command", "set_preference")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to save preference")
+	}
+
+	if scope == repository.PreferenceScopeChannel {
+		return fmt.Sprintf("✅ **Channel default `%s` set to `%s`**\n\nApplies to everyone here who hasn't set their own.", pref.Name, value)
+	}
+	return fmt.Sprintf("✅ **`%s` set to `%s`**\n\nApplies to your next message.", pref.Name, value)
+}
+
+func (s *Service) handlePrefsUnsetCommand(ctx context.Context, req *commands.Request, scope, scopeID, name string) string {
+	pref, ok := lookupResponsePreference(strings.ToLower(name))
+	if !ok {
+		return fmt.Sprintf("❌ **Unknown preference:** `%s`\n\nSee `/prefs show` for the available settings.", name)
+	}
+
+	removed, err := s.prefs.UnsetResponsePreference(scope, scopeID, pref.Name)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "prefs_command", "unset_preference")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to remove preference")
+	}
+	if !removed {
+		return fmt.Sprintf("ℹ️ `%s` wasn't set", pref.Name)
+	}
+	return fmt.Sprintf("✅ **`%s` cleared**", pref.Name)
+}
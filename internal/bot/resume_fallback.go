@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// resumeFallbackNote is added to replies that had to start a fresh Claude
+// session because the one being resumed was gone
+const resumeFallbackNote = "♻️ the previous Claude session was no longer available, so this reply started a fresh one from the stored conversation"
+
+// reconstructedContextPrompt returns system prompt context rebuilding a
+// conversation whose Claude session can no longer be resumed: the summary
+// stored with its latest exchange, or else the most recent transcript. It
+// returns "" if nothing was stored.
+func (s *Service) reconstructedContextPrompt(userID, parentSessionID string) string {
+	children, err := s.sessionManager.GetConversationTree(parentSessionID)
+	if err != nil {
+		s.logger.Warn("Failed to load conversation to reconstruct context",
+			zap.String("session_id", parentSessionID),
+			zap.Error(err))
+		return ""
+	}
+	if len(children) == 0 {
+		return ""
+	}
+
+	intro := "RECONSTRUCTED CONTEXT - The Claude session for this conversation was lost, so this is a fresh session. " +
+		"Files you changed earlier are still on disk, but your earlier messages are not available. "
+	if latest := children[len(children)-1]; latest.Summary != nil && strings.TrimSpace(*latest.Summary) != "" {
+		return intro + "Continue from this summary of the conversation so far:\n" + strings.TrimSpace(*latest.Summary)
+	}
+
+	transcript, err := s.formatConversationForSummary(userID, parentSessionID, children)
+	if err != nil {
+		s.logger.Warn("Failed to format conversation to reconstruct context",
+			zap.String("session_id", parentSessionID),
+			zap.Error(err))
+		return ""
+	}
+	if len(transcript) > recapMaxInput {
+		transcript = transcript[len(transcript)-recapMaxInput:]
+	}
+	return intro + "Continue from this transcript of the conversation so far:\n" + transcript
+}
@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/notifications"
+)
+
+// scopeCheckTimeout bounds the auth.test call that reports granted scopes
+const scopeCheckTimeout = 10 * time.Second
+
+// scopeRequirement is a bot token scope and the feature that needs it
+type scopeRequirement struct {
+	Scope   string
+	Feature string
+	// Needed reports whether the configuration uses the feature; nil means always
+	Needed func(cfg *config.Config) bool
+}
+
+// scopeRequirements lists the bot token scopes the bot's features use
+var scopeRequirements = []scopeRequirement{
+	{Scope: "chat:write", Feature: "posting replies"},
+	{Scope: "commands", Feature: "slash commands"},
+	{Scope: "app_mentions:read", Feature: "responding to mentions"},
+	{Scope: "channels:history", Feature: "thread context in public channels"},
+	{Scope: "groups:history", Feature: "thread context in private channels"},
+	{Scope: "channels:read", Feature: "channel context and lifecycle in public channels"},
+	{Scope: "groups:read", Feature: "channel context and lifecycle in private channels"},
+	{Scope: "im:read", Feature: "scoping /search results"},
+	{Scope: "mpim:read", Feature: "scoping /search results"},
+	{Scope: "im:write", Feature: "/notify completion DMs"},
+	{Scope: "files:read", Feature: "analyzing uploaded images and files"},
+	{Scope: "files:write", Feature: "uploading debug bundles, logs, and diffs"},
+	{Scope: "reactions:read", Feature: "emoji prompts"},
+	{Scope: "users:read", Feature: "user names, timezones, and Slack Connect detection"},
+	{Scope: "users:read.email", Feature: "emails in audit logs"},
+	{
+		Scope:   "users:write",
+		Feature: "keeping the bot's presence active",
+		Needed:  func(cfg *config.Config) bool { return cfg.PresenceHeartbeatInterval > 0 },
+	},
+}
+
+// missingScopes returns the requirements the configuration needs that
+// granted doesn't cover, in scopeRequirements order
+func missingScopes(cfg *config.Config, granted []string) []scopeRequirement {
+	have := make(map[string]bool, len(granted))
+	for _, scope := range granted {
+		have[scope] = true
+	}
+
+	var missing []scopeRequirement
+	for _, req := range scopeRequirements {
+		if have[req.Scope] || (req.Needed != nil && !req.Needed(cfg)) {
+			continue
+		}
+		missing = append(missing, req)
+	}
+	return missing
+}
+
+// formatMissingScopes describes each missing scope and what it breaks
+func formatMissingScopes(missing []scopeRequirement) []string {
+	lines := make([]string, 0, len(missing))
+	for _, req := range missing {
+		lines = append(lines, fmt.Sprintf("%s (%s)", req.Scope, req.Feature))
+	}
+	return lines
+}
+
+// grantedScopes asks Slack which scopes token has. auth.test reports them in
+// the X-OAuth-Scopes header, which slack-go doesn't expose. ok is false if
+// Slack didn't report them.
+func grantedScopes(ctx context.Context, client *http.Client, apiURL, token string) (scopes []string, ok bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+"auth.test", nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to call auth.test: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("auth.test returned %s", resp.Status)
+	}
+
+	header := resp.Header.Get("X-OAuth-Scopes")
+	if header == "" {
+		return nil, false, nil
+	}
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes, true, nil
+}
+
+// verifyScopes checks that the bot token has the scopes the configured
+// features need, and reports each missing one with what it breaks, rather
+// than leaving them to fail with missing_scope errors on first use
+func (s *Service) verifyScopes(ctx context.Context, token string) {
+	ctx, cancel := context.WithTimeout(ctx, scopeCheckTimeout)
+	defer cancel()
+
+	granted, ok, err := grantedScopes(ctx, http.DefaultClient, slack.APIURL, token)
+	if err != nil {
+		s.logger.Warn("Failed to check bot token scopes", zap.Error(err))
+		return
+	}
+	if !ok {
+		s.logger.Warn("Slack did not report the bot token's scopes; skipping the scope check")
+		return
+	}
+
+	missing := missingScopes(s.config, granted)
+	if len(missing) == 0 {
+		s.logger.Info("Bot token has every required scope", zap.Strings("scopes", granted))
+		return
+	}
+
+	lines := formatMissingScopes(missing)
+	s.logger.Error("Bot token is missing scopes; add them to the Slack app and reinstall it",
+		zap.Strings("missing", lines),
+		zap.Strings("granted", granted))
+	s.alert(notifications.Event{
+		Kind:     notifications.EventMissingScopes,
+		Severity: notifications.SeverityWarning,
+		Title:    "Bot token is missing scopes",
+		Text:     fmt.Sprintf("These features will fail until the scopes are added to the Slack app and it is reinstalled:\n• %s", strings.Join(lines, "\n• ")),
+		Key:      "bot",
+	})
+}
@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+func TestGrantedScopes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/auth.test" {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") == "Bearer xoxb-test" {
+			w.Header().Set("X-OAuth-Scopes", "commands,chat:write, files:read")
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	scopes, ok, err := grantedScopes(context.Background(), server.Client(), server.URL+"/", "xoxb-test")
+	if err != nil || !ok {
+		t.Fatalf("grantedScopes() = %v, %v, %v", scopes, ok, err)
+	}
+	if want := []string{"chat:write", "commands", "files:read"}; !reflect.DeepEqual(scopes, want) {
+		t.Errorf("grantedScopes() = %v, want %v", scopes, want)
+	}
+
+	if _, ok, err := grantedScopes(context.Background(), server.Client(), server.URL+"/", "xoxb-other"); ok || err != nil {
+		t.Errorf("Expected an unreported scope list to be skipped, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestMissingScopes(t *testing.T) {
+	var granted []string
+	for _, req := range scopeRequirements {
+		granted = append(granted, req.Scope)
+	}
+	cfg := &config.Config{PresenceHeartbeatInterval: time.Minute, PresenceStatus: true}
+	if missing := missingScopes(cfg, granted); len(missing) != 0 {
+		t.Errorf("Expected no missing scopes, got %v", missing)
+	}
+
+	// Presence scopes only matter with the heartbeat on
+	granted = []string{"chat:write", "commands", "app_mentions:read", "channels:history", "groups:history", "channels:read",
+		"groups:read", "files:read", "files:write", "reactions:read", "users:read", "users:read.email"}
+	got := formatMissingScopes(missingScopes(&config.Config{}, granted))
+	want := []string{"im:read (scoping /search results)", "mpim:read (scoping /search results)", "im:write (/notify completion DMs)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Missing scopes = %v, want %v", got, want)
+	}
+
+	// The status is posted with a user token, so the bot token never needs users.profile:write
+	got = formatMissingScopes(missingScopes(&config.Config{PresenceHeartbeatInterval: time.Minute, PresenceStatus: true}, append(granted, "im:read", "mpim:read", "im:write")))
+	if want := []string{"users:write (keeping the bot's presence active)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Missing scopes = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,182 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const (
+	// searchSwitchActionID is the quick-switch button on /search results
+	searchSwitchActionID = "search_switch_session"
+
+	searchResultLimit = 10
+	maxSnippetLength  = 300
+)
+
+// handleSearchCommand full-text searches conversations in channels the user can access
+func (s *Service) handleSearchCommand(ctx context.Context, req *commands.Request) (string, error) {
+	query := strings.TrimSpace(req.Text)
+
+	channelIDs := s.searchableChannels(req.UserID, req.ChannelID)
+
+	results, err := s.search.SearchConversations(query, channelIDs, searchResultLimit)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "search", "query")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Search failed"), nil
+	}
+
+	results, err = s.visibleSearchResults(req.ChannelID, results)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "search", "check_visibility")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Search failed"), nil
+	}
+
+	if len(results) == 0 {
+		return fmt.Sprintf("🔍 **No results** for `%s`", query), nil
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("🔍 *%d result(s) for* `%s`", len(results), query), false, false),
+			nil, nil),
+		slack.NewDividerBlock(),
+	}
+
+	for _, result := range results {
+		location := "unknown channel"
+		if result.ChannelID != nil {
+			location = fmt.Sprintf("<#%s>", *result.ChannelID)
+		}
+
+		snippet := strings.Join(strings.Fields(result.Snippet), " ")
+		if runes := []rune(snippet); len(runes) > maxSnippetLength {
+			snippet = string(runes[:maxSnippetLength]) + "…"
+		}
+
+		text := fmt.Sprintf("*`%s`* in %s · `%s` · %s\n>%s",
+			result.SessionID, location, result.WorkingDirectory,
+			s.userTime(req.UserID, result.CreatedAt).Format("2006-01-02 15:04"), snippet)
+
+		button := slack.NewButtonBlockElement(searchSwitchActionID, result.SessionID,
+			slack.NewTextBlockObject(slack.PlainTextType, "Switch", false, false))
+
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, text, false, false),
+			nil, slack.NewAccessory(button)))
+	}
+
+	err = s.sender.PostEphemeral(ctx, req.ChannelID, req.UserID,
+		slack.MsgOptionText(fmt.Sprintf("%d search result(s) for %s", len(results), query), false),
+		slack.MsgOptionBlocks(blocks...))
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "search", "post_results")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to post search results"), nil
+	}
+
+	return "", nil
+}
+
+// searchableChannels returns the channels whose sessions the user may search.
+// Admins search everything (nil); other users are limited to allowed channels
+// they are a member of, falling back to the current channel.
+func (s *Service) searchableChannels(userID, currentChannelID string) []string {
+	if s.authService.IsUserAdmin(userID) {
+		return nil
+	}
+
+	channelIDs := []string{currentChannelID}
+	seen := map[string]bool{currentChannelID: true}
+
+	params := &slack.GetConversationsForUserParameters{
+		UserID: userID,
+		Types:  []string{"public_channel", "private_channel", "mpim", "im"},
+		Limit:  200,
+	}
+	for {
+		channels, nextCursor, err := s.api().GetConversationsForUser(params)
+		if err != nil {
+			s.logger.Warn("Failed to list user conversations, searching current channel only",
+				zap.String("user_id", userID), zap.Error(err))
+			return channelIDs
+		}
+
+		for _, channel := range channels {
+			if seen[channel.ID] || !s.config.IsChannelAllowed(channel.ID) {
+				continue
+			}
+			seen[channel.ID] = true
+			channelIDs = append(channelIDs, channel.ID)
+		}
+
+		if nextCursor == "" {
+			return channelIDs
+		}
+		params.Cursor = nextCursor
+	}
+}
+
+// visibleSearchResults drops results whose sessions may not be shown in the
+// channel, such as DM sessions found while searching from a public channel
+func (s *Service) visibleSearchResults(channelID string, results []*repository.SearchResult) ([]*repository.SearchResult, error) {
+	var visible []*repository.SearchResult
+	for _, result := range results {
+		ok, err := s.sessionVisibleIn(channelID, result.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			visible = append(visible, result)
+		}
+	}
+	return visible, nil
+}
+
+// handleSearchSwitchAction switches the channel to a session picked from /search results
+func (s *Service) handleSearchSwitchAction(callback *slack.InteractionCallback, action *slack.BlockAction) {
+	userID := callback.User.ID
+	channelID := callback.Channel.ID
+	sessionID := action.Value
+
+	authCtx := &auth.AuthContext{
+		UserID:    userID,
+		ChannelID: channelID,
+		Command:   "/search",
+		Timestamp: time.Now(),
+	}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+		s.postEphemeral(channelID, userID, fmt.Sprintf("❌ Authorization failed: %v", err))
+		return
+	}
+
+	visible, err := s.sessionVisibleIn(channelID, sessionID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "search", "check_visibility")
+		s.postEphemeral(channelID, userID, s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to switch session"))
+		return
+	}
+	if !visible {
+		s.postEphemeral(channelID, userID, fmt.Sprintf("❌ **Session not found**\n\nSession `%s` does not exist. See `/session list` for the sessions you can switch to.", sessionID))
+		return
+	}
+
+	if err := s.switchSession(channelID, userID, sessionID); isSessionBusy(err) {
+		s.postEphemeral(channelID, userID, sessionSwitchBusyMessage)
+		return
+	} else if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "search", "switch_session")
+		s.postEphemeral(channelID, userID, s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to switch session"))
+		return
+	}
+
+	s.postEphemeral(channelID, userID, fmt.Sprintf("✅ **Session Switched**\n\nNow using Claude session: `%s`\n\nNext message will resume this conversation.", sessionID))
+}
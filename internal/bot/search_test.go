@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/slacksend"
+)
+
+func TestSearch_OnlySessionsVisibleInChannel(t *testing.T) {
+	const (
+		public  = "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d"
+		private = "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b"
+	)
+	s := newSwitchTestService(public, private)
+	manager := s.sessionManager.(*switchTestManager)
+	manager.hidden = map[string]bool{private: true}
+
+	// E.g. a DM session matched while searching from a public channel
+	results, err := s.visibleSearchResults("CPUBLIC", []*repository.SearchResult{
+		{SessionID: public},
+		{SessionID: private},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].SessionID != public {
+		t.Errorf("Expected only the session visible in the channel, got %+v", results)
+	}
+
+	// A quick-switch button for it is refused too
+	client := &recordingSlack{}
+	s.sender = slacksend.New(func() slacksend.Client { return client }, 0, 0, zap.NewNop())
+	s.handleSearchSwitchAction(&slack.InteractionCallback{
+		User:    slack.User{ID: "U1"},
+		Channel: slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "CPUBLIC"}}},
+	}, &slack.BlockAction{ActionID: searchSwitchActionID, Value: private})
+	if len(manager.switched) != 0 {
+		t.Errorf("Expected no switch, got %v", manager.switched)
+	}
+	if len(client.posts) != 1 || !strings.Contains(client.posts[0], "Session not found") {
+		t.Errorf("Expected the switch to be refused, got %q", client.posts)
+	}
+}
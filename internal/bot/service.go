@@ -7,29 +7,38 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/budget"
 	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
 	"github.com/ghabxph/claude-on-slack/internal/config"
 	"github.com/ghabxph/claude-on-slack/internal/database"
+	"github.com/ghabxph/claude-on-slack/internal/encryption"
 	"github.com/ghabxph/claude-on-slack/internal/files"
+	"github.com/ghabxph/claude-on-slack/internal/flags"
 	"github.com/ghabxph/claude-on-slack/internal/logging"
 	"github.com/ghabxph/claude-on-slack/internal/notifications"
+	"github.com/ghabxph/claude-on-slack/internal/postprocess"
 	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/runqueue"
 	"github.com/ghabxph/claude-on-slack/internal/session"
+	"github.com/ghabxph/claude-on-slack/internal/slacksend"
+	"github.com/ghabxph/claude-on-slack/internal/transcribe"
 	"github.com/ghabxph/claude-on-slack/internal/version"
 )
 
@@ -38,55 +47,177 @@ type Service struct {
 	config         *config.Config
 	logger         *zap.Logger
 	dualLogger     *logging.DualLogger
+	logBuffer      *logging.RingBuffer
+	credsMu        sync.RWMutex // guards the Slack clients, which are swapped on credential rotation
 	slackAPI       *slack.Client
 	socketClient   *socketmode.Client
+	socketCancel   context.CancelFunc
+	socketChanged  chan struct{}
+	runCtx         context.Context
+	tokenExpiresAt time.Time
 	httpServer     *http.Server
+	adminServer    *http.Server // Admin API on ADMIN_LISTEN_ADDR; nil when it shares httpServer
 	authService    *auth.Service
 	sessionManager session.SessionManager
 	claudeExecutor *claude.Executor
 	fileDownloader *files.Downloader
+	transcriber    *transcribe.Transcriber
 	fileCleanup    *files.CleanupService
+	commands       *commands.Registry
+	channelInfo    *channelInfoCache
+	channelTypes   sync.Map // Channel ID -> type recorded by this process
+	botUsers       sync.Map // User ID -> whether the user is a bot, for mentioned users
+	db             *database.Database
+	usage          *repository.UsageRepository
+	search         *repository.SearchRepository
+	tags           *repository.TagRepository
+	prefs          *repository.PreferencesRepository
+	thinking       *repository.ThinkingMessageRepository
+	failedRuns     *repository.FailedRunRepository
+	envVars        *repository.ChannelEnvRepository
+	guardrails     *repository.GuardrailRepository
+	delayedPrompts *repository.DelayedPromptRepository
+	outbox         *repository.OutboxRepository
+	emojiPrompts   *repository.EmojiPromptRepository
+	templates      *repository.PromptTemplateRepository
+	channelFacts   *repository.ChannelFactRepository
+	policyAcks     *repository.PolicyAcknowledgmentRepository
+	sessionDirs    *repository.SessionDirRepository
+	identities     *repository.UserIdentityRepository
+	settings       *repository.ChannelSettingsRepository
+	demos          *repository.DemoRepository
+	flagStore      *repository.FeatureFlagRepository
+	featureFlags   *flags.Service
+	envCipher      *encryption.Cipher
+	pendingNotify  *pendingNotifications
+	recaps         *pendingRecaps
+	purges         *pendingPurges
+	consent        *policyAcceptances
+	presence       *presenceTracker
+	demoPlaybacks  *demoPlaybacks
+	sender         *slacksend.Sender
+	notifier       *notifications.Router
+	responseHooks  *postprocess.Pipeline
+	alertCooldowns *alertCooldowns
+	budgetPolicy   *budget.Policy
+	runQueue       *runqueue.Queue
+	channelRuns    *channelRuns
+	workspaceEdits *workspaceEdits
+	dirtyRuns      *dirtyRuns
+	patchReviews   *patchReviews
+	inflightPrompts *inflightPrompts
+	duplicateRuns  *duplicateRuns
+	titleCache     *sessionTitleCache
+	slowMode       *slowMode
+	clones         *workspaceClones
+	agents         claude.Agents
+	readiness      *readiness
+	dispatchEvent  func(*slackevents.EventsAPIEvent) // Handles acknowledged callback events; replaced in tests
 	stopCh         chan struct{}
 	wg             sync.WaitGroup
 	botUserID      string
 	startTime      time.Time
 }
 
-// CommandHandler represents a command handler function
-type CommandHandler func(ctx context.Context, event *slackevents.MessageEvent, args []string) (string, error)
-
-// commandRegistry holds all registered commands
-var commandRegistry = make(map[string]CommandHandler)
-
 // NewService creates a new bot service
 func NewService(cfg *config.Config, logger *zap.Logger) (*Service, error) {
+	// Keep recent log entries in memory for /debug bundle
+	logLevel := zapcore.InfoLevel
+	if cfg.EnableDebug {
+		logLevel = zapcore.DebugLevel
+	}
+	logBuffer := logging.NewRingBuffer(2000)
+	logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, logBuffer.Core(logLevel))
+	}))
+
 	// Initialize Slack clients
-	slackAPI := slack.New(cfg.SlackBotToken, slack.OptionDebug(cfg.EnableDebug), slack.OptionAppLevelToken(cfg.SlackAppToken))
-	socketClient := socketmode.New(slackAPI, socketmode.OptionDebug(cfg.EnableDebug))
+	slackAPI, socketClient := newSlackClients(cfg, cfg.SlackCredentials())
 
 	// Initialize other services
 	authService := auth.NewService(cfg, logger)
-	claudeExecutor, err := claude.NewExecutor(cfg, logger)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Claude executor: %w", err)
+	supervised := cfg.StartupMode == config.StartupSupervised
+	ready := newReadiness()
+
+	// In supervised mode, start without the CLI or database and retry
+	// until they come up
+	claudeExecutor, cliErr := claude.NewExecutor(cfg, logger)
+	if cliErr != nil {
+		if !supervised {
+			return nil, fmt.Errorf("failed to create Claude executor: %w", cliErr)
+		}
+		logger.Warn("Claude Code CLI unavailable; starting in warm-up mode", zap.Error(cliErr))
+		claudeExecutor = claude.NewUnverifiedExecutor(cfg, logger)
 	}
+	ready.add(dependencyClaudeCLI, claudeExecutor.Verify, cliErr)
 	
 	// Initialize database with retry logic
-	db, err := database.NewDatabase(&cfg.Database, logger)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	db, dbErr := database.NewDatabase(&cfg.Database, logger)
+	if dbErr != nil {
+		if !supervised {
+			return nil, fmt.Errorf("failed to connect to database: %w", dbErr)
+		}
+		logger.Warn("Database unavailable; starting in warm-up mode", zap.Error(dbErr))
+		var err error
+		if db, err = database.Open(&cfg.Database, logger); err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
 	}
+	ready.add(dependencyDatabase, db.Health, dbErr)
 	
 	// Use database-backed session manager
 	sessionManager := session.NewDatabaseManager(cfg, logger, claudeExecutor, db)
 
+	policySpec := cfg.BudgetDowngradePolicy
+	if policySpec == "" {
+		policySpec = budget.DefaultPolicySpec
+	}
+	budgetPolicy, err := budget.ParsePolicy(policySpec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BUDGET_DOWNGRADE_POLICY: %w", err)
+	}
+
+	var agents claude.Agents
+	if cfg.ClaudeAgentsFile != "" {
+		agents, err = claude.LoadAgents(cfg.ClaudeAgentsFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CLAUDE_AGENTS_FILE: %w", err)
+		}
+	}
+
+	var envCipher *encryption.Cipher
+	if cfg.EnvEncryptionKey != "" {
+		key, err := encryption.ParseKey(cfg.EnvEncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ENV_ENCRYPTION_KEY: %w", err)
+		}
+		if envCipher, err = encryption.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("invalid ENV_ENCRYPTION_KEY: %w", err)
+		}
+	}
+
 	// Initialize file downloader
 	storageDir := "/tmp/claude-slack-images"
-	fileDownloader, err := files.NewDownloader(slackAPI, logger, storageDir, cfg.SlackBotToken)
+	fileLimits := files.Limits{
+		MaxFileSize: cfg.FileMaxSizeMB * 1024 * 1024,
+		UserQuota:   cfg.FileUserQuotaMB * 1024 * 1024,
+		GlobalQuota: cfg.FileGlobalQuotaMB * 1024 * 1024,
+	}
+	fileDownloader, err := files.NewDownloader(slackAPI, logger, storageDir, cfg.SlackBotToken, fileLimits)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file downloader: %w", err)
 	}
-	fileCleanup := files.NewCleanupService(fileDownloader, logger)
+	fileCleanup := files.NewCleanupService(fileDownloader, logger, cfg.FileCleanupInterval, cfg.FileRetention)
+
+	transcriber := transcribe.NewTranscriber(transcribe.Config{
+		Command: cfg.TranscribeCommand,
+		URL:     cfg.TranscribeURL,
+		APIKey:  cfg.TranscribeAPIKey,
+		Model:   cfg.TranscribeModel,
+		Timeout: cfg.TranscribeTimeout,
+	}, logger)
+
+	flagStore := repository.NewFeatureFlagRepository(db, logger)
 
 	// Initialize dual logger for centralized error reporting
 	dualLogger := logging.NewDualLogger(logger, slackAPI)
@@ -95,17 +226,91 @@ func NewService(cfg *config.Config, logger *zap.Logger) (*Service, error) {
 		config:         cfg,
 		logger:         logger,
 		dualLogger:     dualLogger,
+		logBuffer:      logBuffer,
 		slackAPI:       slackAPI,
 		socketClient:   socketClient,
+		socketChanged:  make(chan struct{}, 1),
 		authService:    authService,
 		sessionManager: sessionManager,
 		claudeExecutor: claudeExecutor,
 		fileDownloader: fileDownloader,
+		transcriber:    transcriber,
 		fileCleanup:    fileCleanup,
+		commands:       commands.NewRegistry(),
+		channelInfo:    newChannelInfoCache(cfg.ChannelContextCacheTTL),
+		db:             db,
+		usage:          repository.NewUsageRepository(db, logger),
+		search:         repository.NewSearchRepository(db, logger),
+		tags:           repository.NewTagRepository(db, logger),
+		prefs:          repository.NewPreferencesRepository(db, logger),
+		thinking:       repository.NewThinkingMessageRepository(db, logger),
+		failedRuns:     repository.NewFailedRunRepository(db, logger),
+		envVars:        repository.NewChannelEnvRepository(db, logger),
+		guardrails:     repository.NewGuardrailRepository(db, logger),
+		delayedPrompts: repository.NewDelayedPromptRepository(db, logger),
+		outbox:         repository.NewOutboxRepository(db, logger),
+		emojiPrompts:   repository.NewEmojiPromptRepository(db, logger),
+		templates:      repository.NewPromptTemplateRepository(db, logger),
+		channelFacts:   repository.NewChannelFactRepository(db, logger),
+		policyAcks:     repository.NewPolicyAcknowledgmentRepository(db, logger),
+		sessionDirs:    repository.NewSessionDirRepository(db, logger),
+		identities:     repository.NewUserIdentityRepository(db, logger),
+		settings:       repository.NewChannelSettingsRepository(db, logger),
+		envCipher:      envCipher,
+		demos:          repository.NewDemoRepository(db, logger),
+		flagStore:      flagStore,
+		featureFlags:   flags.New(flagStore, cfg.FeatureFlagCacheTTL, logger),
+		pendingNotify:  newPendingNotifications(),
+		recaps:         newPendingRecaps(),
+		purges:         newPendingPurges(),
+		consent:        newPolicyAcceptances(),
+		presence:       newPresenceTracker(cfg.SlackUserToken),
+		demoPlaybacks:  newDemoPlaybacks(),
+		alertCooldowns: newAlertCooldowns(),
+		budgetPolicy:   budgetPolicy,
+		runQueue:       runqueue.New(cfg.MaxConcurrentRuns),
+		channelRuns:    newChannelRuns(),
+		workspaceEdits: newWorkspaceEdits(),
+		dirtyRuns:      newDirtyRuns(),
+		patchReviews:   newPatchReviews(),
+		inflightPrompts: newInflightPrompts(),
+		duplicateRuns:  newDuplicateRuns(),
+		titleCache:     newSessionTitleCache(),
+		slowMode:       newSlowMode(cfg.SlowModeBackoff, cfg.SlowModeMaxBackoff),
+		clones:         newWorkspaceClones(),
+		agents:         agents,
+		readiness:      ready,
 		stopCh:         make(chan struct{}),
 		startTime:      time.Now(),
 	}
 
+	// Enrich users with their Slack profile on first sight
+	authService.SetProfileFetcher(service.fetchUserProfile)
+
+	// Per-channel defaults from the deprecated env lists now live in channel settings
+	service.importChannelSettings()
+
+	// Attribute sessions, usage, and audit logs to mapped system identities
+	service.loadUserIdentities()
+	if resolver, ok := service.sessionManager.(session.IdentityResolverSetter); ok {
+		resolver.SetIdentityResolver(authService.Identity)
+	}
+
+	// Pace outgoing messages per channel and retry rate-limited calls
+	service.sender = slacksend.New(func() slacksend.Client { return service.api() },
+		cfg.SlackChannelPacing, cfg.SlackMaxRetries, logger)
+
+	// Route deployment notices and operational alerts to the configured sinks
+	service.notifier = newNotificationRouter(cfg, service.sender, logger)
+
+	// Run replies through the configured post-processing hooks
+	if service.responseHooks, err = newResponsePipeline(cfg, logger); err != nil {
+		return nil, err
+	}
+
+	// Callback events received over HTTP are handled after they're acknowledged
+	service.dispatchEvent = service.handleEventsAPIEvent
+
 	// Register built-in commands
 	service.registerCommands()
 
@@ -120,27 +325,26 @@ func (s *Service) Start(ctx context.Context) error {
 		zap.String("command_prefix", s.config.CommandPrefix))
 
 	// Get bot user info
-	authResp, err := s.slackAPI.AuthTest()
+	authResp, err := s.api().AuthTest()
 	if err != nil {
 		return fmt.Errorf("failed to authenticate with Slack: %w", err)
 	}
 	s.botUserID = authResp.UserID
+	s.authService.SetHomeTeam(authResp.TeamID, authResp.EnterpriseID)
 
 	s.logger.Info("Bot authenticated",
 		zap.String("bot_user_id", s.botUserID),
 		zap.String("team", authResp.Team),
 		zap.String("user", authResp.User))
 
+	// Report missing scopes now rather than as errors on first use
+	s.verifyScopes(ctx, s.config.SlackCredentials().BotToken)
+
 	// Set bot presence to online
-	err = s.slackAPI.SetUserPresence("auto")
-	if err != nil {
-		s.logger.Debug("Failed to set bot presence", zap.Error(err))
-	} else {
-		s.logger.Info("Bot presence set to online")
-	}
+	s.updatePresence(true)
 
 	// Start HTTP server for Events API
-	httpServerErrCh := make(chan error, 1)
+	httpServerErrCh := make(chan error, 2)
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
@@ -148,6 +352,15 @@ func (s *Service) Start(ctx context.Context) error {
 			httpServerErrCh <- fmt.Errorf("HTTP server failed: %w", err)
 		}
 	}()
+	if s.adminEnabled() && s.config.AdminListenAddr != "" {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			if err := s.startAdminServer(); err != nil {
+				httpServerErrCh <- fmt.Errorf("admin HTTP server failed: %w", err)
+			}
+		}()
+	}
 	
 	// Check if HTTP server started successfully
 	select {
@@ -171,6 +384,28 @@ func (s *Service) Start(ctx context.Context) error {
 		s.periodicCleanup()
 	}()
 
+	// Start presence heartbeat
+	if s.config.PresenceHeartbeatInterval > 0 {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.presenceHeartbeatLoop()
+		}()
+	}
+
+	// Work that needs the database waits for it in supervised mode
+	if s.readiness.Ready() {
+		s.startDependentWorkers()
+	} else {
+		s.logger.Warn("Starting in warm-up mode; replying \"warming up\" until dependencies are ready",
+			zap.Strings("waiting_for", s.readiness.Pending()))
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.superviseStartup()
+		}()
+	}
+
 	// Start file cleanup service
 	s.wg.Add(1)
 	go func() {
@@ -178,19 +413,90 @@ func (s *Service) Start(ctx context.Context) error {
 		s.fileCleanup.Start(ctx)
 	}()
 
-	// Start socket mode client (will only work if app is configured for Socket Mode)
+	// Start Slack credential rotation and secrets file reload
+	s.wg.Add(1)
 	go func() {
-		if err := s.socketClient.Run(); err != nil {
-			s.logger.Debug("Socket Mode not available or disabled", zap.Error(err))
-		}
+		defer s.wg.Done()
+		s.credentialsLoop()
 	}()
 
+	// Start socket mode client (will only work if app is configured for Socket Mode)
+	s.credsMu.Lock()
+	s.runCtx = ctx
+	s.credsMu.Unlock()
+	s.startSocketMode(ctx)
+
 	// Send startup notification after successful initialization
 	s.sendStartupNotification()
 
 	return nil
 }
 
+// startDependentWorkers starts the listeners and background loops that need
+// the database
+func (s *Service) startDependentWorkers() {
+	// Drop cached sessions when another bot instance changes them
+	if s.config.SessionCacheInvalidation {
+		if listener, ok := s.sessionManager.(session.CacheInvalidationListener); ok {
+			if err := listener.StartInvalidationListener(); err != nil {
+				s.logger.Warn("Session cache invalidation unavailable; run a single instance or sessions may be stale",
+					zap.Error(err))
+			}
+		}
+	}
+
+	// Start permission mode expiry sweeper
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.permissionExpiryLoop()
+	}()
+
+	// Start session trash purge
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.trashPurgeLoop()
+	}()
+
+	// Start old exchange pruning
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.sessionPruneLoop()
+	}()
+
+	// Start orphaned workspace audit
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.workspaceAuditLoop()
+	}()
+
+	// Start scheduled prompt dispatcher
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.delayedPromptLoop()
+	}()
+
+	// Start redelivery of messages Slack didn't take
+	if s.config.OutboxMaxAge > 0 {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.outboxLoop()
+		}()
+	}
+
+	// Start stale thinking message janitor
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.thinkingJanitorLoop()
+	}()
+}
+
 // Stop stops the bot service
 func (s *Service) Stop() {
 	s.logger.Info("Stopping Claude on Slack bot")
@@ -205,6 +511,13 @@ func (s *Service) Stop() {
 			s.logger.Error("HTTP server shutdown error", zap.Error(err))
 		}
 	}
+	if s.adminServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.adminServer.Shutdown(ctx); err != nil {
+			s.logger.Error("Admin HTTP server shutdown error", zap.Error(err))
+		}
+	}
 
 	s.wg.Wait()
 
@@ -218,8 +531,10 @@ func (s *Service) Stop() {
 // handleEvents handles incoming Slack events
 func (s *Service) handleEvents() {
 	for {
+		// Re-select after credential rotation replaces the Socket Mode client
+		client := s.socket()
 		select {
-		case envelope := <-s.socketClient.Events:
+		case envelope := <-client.Events:
 			switch envelope.Type {
 			case socketmode.EventTypeEventsAPI:
 				eventsAPIEvent, ok := envelope.Data.(slackevents.EventsAPIEvent)
@@ -228,7 +543,7 @@ func (s *Service) handleEvents() {
 					continue
 				}
 				s.handleEventsAPIEvent(&eventsAPIEvent)
-				s.socketClient.Ack(*envelope.Request)
+				client.Ack(*envelope.Request)
 
 			case socketmode.EventTypeSlashCommand:
 				slashCommand, ok := envelope.Data.(slack.SlashCommand)
@@ -237,7 +552,7 @@ func (s *Service) handleEvents() {
 					continue
 				}
 				s.handleSlashCommand(&slashCommand)
-				s.socketClient.Ack(*envelope.Request)
+				client.Ack(*envelope.Request)
 
 			case socketmode.EventTypeInteractive:
 				callback, ok := envelope.Data.(slack.InteractionCallback)
@@ -246,12 +561,15 @@ func (s *Service) handleEvents() {
 					continue
 				}
 				s.handleInteractiveEvent(&callback)
-				s.socketClient.Ack(*envelope.Request)
+				client.Ack(*envelope.Request)
 
 			default:
 				s.logger.Debug("Received unhandled event", zap.String("type", string(envelope.Type)))
 			}
 
+		case <-s.socketChanged:
+			continue
+
 		case <-s.stopCh:
 			return
 		}
@@ -287,6 +605,38 @@ func (s *Service) handleEventsAPIEvent(event *slackevents.EventsAPIEvent) {
 				return
 			}
 			s.handleFileSharedEvent(fileEvent)
+
+		case "reaction_added":
+			reactionEvent, ok := innerEvent.Data.(*slackevents.ReactionAddedEvent)
+			if !ok {
+				s.logger.Warn("Failed to type assert reaction added event")
+				return
+			}
+			s.handleReactionAddedEvent(reactionEvent)
+
+		case "channel_archive", "group_archive":
+			channelID, userID, ok := archivedChannel(innerEvent.Data)
+			if !ok {
+				s.logger.Warn("Failed to type assert channel archive event")
+				return
+			}
+			s.handleChannelArchived(channelID, userID, repository.ChannelLifecycleArchived)
+
+		case "channel_deleted", "group_deleted":
+			channelID, _, ok := archivedChannel(innerEvent.Data)
+			if !ok {
+				s.logger.Warn("Failed to type assert channel deleted event")
+				return
+			}
+			s.handleChannelArchived(channelID, "", repository.ChannelLifecycleDeleted)
+
+		case "channel_unarchive", "group_unarchive":
+			channelID, userID, ok := archivedChannel(innerEvent.Data)
+			if !ok {
+				s.logger.Warn("Failed to type assert channel unarchive event")
+				return
+			}
+			s.handleChannelUnarchived(channelID, userID)
 		}
 	}
 }
@@ -298,16 +648,43 @@ func (s *Service) handleMessageEvent(event *slackevents.MessageEvent) {
 		return
 	}
 
+	// Topic/purpose changes are system messages, not prompts
+	if event.SubType == "channel_topic" || event.SubType == "channel_purpose" {
+		s.channelInfo.invalidate(event.Channel)
+		return
+	}
+
+	channelType := s.recordChannelType(event.Channel, event.ChannelType)
+	msg := parseDirectedMessage(event.Text, s.botUserID, s.config.CommandPrefix)
+	channelSettings := s.channelSettings(event.Channel)
+	if s.mentionRequired(channelType, channelSettings) && !msg.addressed() {
+		return
+	}
+	// Leave messages like "@deploybot ship it" to the bot they're for
+	if s.addressedToOtherBot(msg) {
+		s.logger.Debug("Ignoring message addressed to another bot",
+			zap.String("channel_id", event.Channel),
+			zap.Strings("mentions", msg.LeadingMentions))
+		return
+	}
+
 	s.logger.Debug("Processing message in allowed channel",
 		zap.String("user_id", event.User),
 		zap.String("channel_id", event.Channel),
 		zap.String("text", event.Text))
 
+	// Answer in a thread under top-level messages if the channel asks for it
+	event.ThreadTimeStamp = replyThread(event, channelSettings)
+
 	ctx := context.Background()
-	response := s.processMessage(ctx, event)
+	started := time.Now()
+	response := s.processMessage(ctx, event, msg.Text)
 
 	if response != "" {
-		s.sendResponse(event.Channel, response)
+		// A held response isn't in Slack yet, so there is nothing to link to
+		if responseTS, held := s.sendThreadResponseOrHold(event.Channel, event.ThreadTimeStamp, response); !held {
+			s.notifyIfSlow(event.User, event.Channel, responseTS, time.Since(started))
+		}
 	}
 }
 
@@ -319,12 +696,13 @@ func (s *Service) handleMentionEvent(event *slackevents.AppMentionEvent) {
 
 	// Convert mention event to message event format
 	messageEvent := &slackevents.MessageEvent{
-		Type:        "message",
-		User:        event.User,
-		Text:        event.Text,
-		TimeStamp:   event.TimeStamp,
-		Channel:     event.Channel,
-		ChannelType: "channel", // Default since AppMentionEvent doesn't have ChannelType
+		Type:            "message",
+		User:            event.User,
+		Text:            event.Text,
+		TimeStamp:       event.TimeStamp,
+		ThreadTimeStamp: event.ThreadTimeStamp,
+		Channel:         event.Channel,
+		// AppMentionEvent doesn't carry ChannelType; it's resolved from the channel
 	}
 
 	s.handleMessageEvent(messageEvent)
@@ -343,8 +721,16 @@ func (s *Service) handleFileSharedEvent(event *slackevents.FileSharedEvent) {
 
 // handleSlashCommand handles slash commands
 func (s *Service) handleSlashCommand(command *slack.SlashCommand) {
-	ctx := context.Background()
-	response := s.processSlashCommand(ctx, command)
+	if limited := s.checkRateLimit(command.UserID, command.ChannelID); limited != nil {
+		s.sendResponse(command.ChannelID, s.rateLimitMessage(command.UserID, limited))
+		return
+	}
+
+	req := commands.NewRequest(command.Command, command.UserID, command.ChannelID, command.Text, commands.SourceSlash)
+	req.TriggerID = command.TriggerID
+	req.ResponseURL = command.ResponseURL
+
+	response := s.dispatchCommand(context.Background(), req)
 
 	if response != "" {
 		s.sendResponse(command.ChannelID, response)
@@ -357,99 +743,158 @@ func (s *Service) handleInteractiveEvent(callback *slack.InteractionCallback) {
 		zap.String("type", string(callback.Type)),
 		zap.String("user_id", callback.User.ID))
 
+	if err := validateInteraction(callback); err != nil {
+		s.logger.Warn("Ignoring malformed interactive event",
+			zap.String("type", string(callback.Type)),
+			zap.Error(err))
+		return
+	}
+
 	// Handle different interaction types
 	switch callback.Type {
 	case slack.InteractionTypeBlockActions:
 		s.handleBlockActions(callback)
 	case slack.InteractionTypeShortcut:
 		s.handleShortcut(callback)
+	case slack.InteractionTypeMessageAction:
+		s.handleMessageShortcut(callback)
+	case slack.InteractionTypeViewSubmission:
+		s.handleViewSubmission(callback)
 	default:
 		s.logger.Debug("Unhandled interaction type", zap.String("type", string(callback.Type)))
 	}
 }
 
-// processMessage processes incoming messages
-func (s *Service) processMessage(ctx context.Context, event *slackevents.MessageEvent) string {
-	// Create auth context
-	authCtx := &auth.AuthContext{
-		UserID:    event.User,
-		ChannelID: event.Channel,
-		Timestamp: time.Now(),
+// processMessage processes incoming messages. text is what the message says
+// to the bot, with its mentions and the command prefix removed.
+func (s *Service) processMessage(ctx context.Context, event *slackevents.MessageEvent, text string) string {
+	if !s.readiness.Ready() {
+		return warmingUpMessage
 	}
 
-	// Check authorization
-	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionRead); err != nil {
-		s.logger.Warn("Authorization failed", zap.Error(err))
-		errCtx := logging.CreateErrorContext(event.Channel, event.User, "message_processor", "authorization")
-		return s.logErrorWithTrace(ctx, errCtx, err, "Authorization failed")
+	// Commands check their own permission when dispatched; talking to Claude
+	// runs tools, so it needs execute permission
+	req, isCommand := s.matchMessageCommand(event.User, event.Channel, text)
+
+	// With the intent router on, slash commands typed as messages run as
+	// commands, and greetings or acknowledgements get a canned reply
+	routing := !isCommand && s.config.IntentRouter != "" && s.config.IntentRouter != config.IntentRouterOff
+	if routing {
+		req, isCommand = s.matchTypedSlashCommand(event.User, event.Channel, text)
+		routing = !isCommand
 	}
 
-	// Parse message
-	text := strings.TrimSpace(event.Text)
+	// Routing a message may call a model, so it's authorized and counted
+	// against the rate limit first, with the least permission any message
+	// needs; one routed as a task is checked again for execute below
+	requiredPermission := auth.PermissionExecute
+	if isCommand || routing {
+		requiredPermission = auth.PermissionRead
+	}
+	if reply := s.authorizeMessage(ctx, event, requiredPermission); reply != "" {
+		return reply
+	}
 
-	// Remove bot mention if present
-	mentionPattern := fmt.Sprintf("<@%s>", s.botUserID)
-	text = strings.ReplaceAll(text, mentionPattern, "")
-	text = strings.TrimSpace(text)
+	// One request against the rate limit, whether it's a command or a prompt
+	if limited := s.checkRateLimit(event.User, event.Channel); limited != nil {
+		return s.rateLimitMessage(event.User, limited)
+	}
 
-	// Remove command prefix if present
-	if strings.HasPrefix(text, s.config.CommandPrefix) {
-		text = strings.TrimPrefix(text, s.config.CommandPrefix)
-		text = strings.TrimSpace(text)
+	kind := intentTask
+	if routing {
+		kind = s.routeIntent(ctx, event, text)
+		if kind == intentTask {
+			if reply := s.authorizeMessage(ctx, event, auth.PermissionExecute); reply != "" {
+				return reply
+			}
+		}
 	}
 
 	// Check if it's a specific bot command (help, status, etc.)
-	if strings.HasPrefix(text, "help") && len(strings.Fields(text)) == 1 {
-		return s.getHelpMessage()
-	}
-	if strings.HasPrefix(text, "status") && len(strings.Fields(text)) == 1 {
-		response, _ := s.handleStatusCommand(ctx, event, []string{})
-		return response
+	if isCommand {
+		return s.dispatchCommand(ctx, req)
 	}
-	if strings.HasPrefix(text, "version") && len(strings.Fields(text)) == 1 {
-		response, _ := s.handleVersionCommand(ctx, event, []string{})
-		return response
+
+	if reply := intentReply(kind, event.User); reply != "" {
+		s.logger.Info("Intent router answered without running Claude",
+			zap.String("intent", string(kind)),
+			zap.String("user_id", event.User),
+			zap.String("channel_id", event.Channel))
+		return reply
 	}
 
 	// Process everything else as Claude conversation (natural language)
-	return s.processClaudeMessage(ctx, event, text)
+	return s.processClaudeMessage(ctx, event, text, runOverrides{})
+}
+
+// authorizeMessage checks a message's author may act with permission in its
+// channel, returning the reply to send if not
+func (s *Service) authorizeMessage(ctx context.Context, event *slackevents.MessageEvent, permission auth.Permission) string {
+	authCtx := &auth.AuthContext{
+		UserID:    event.User,
+		ChannelID: event.Channel,
+		Timestamp: time.Now(),
+	}
+	if err := s.authService.AuthorizeUser(authCtx, permission); err != nil {
+		s.logger.Warn("Authorization failed", zap.Error(err))
+		errCtx := logging.CreateErrorContext(event.Channel, event.User, "message_processor", "authorization")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Authorization failed")
+	}
+	return ""
 }
 
-// processCommand processes bot commands
-func (s *Service) processCommand(ctx context.Context, event *slackevents.MessageEvent, text string) string {
-	parts := strings.Fields(text)
-	if len(parts) == 0 {
-		return s.getHelpMessage()
+// processClaudeMessage processes Claude conversation messages
+func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.MessageEvent, text string, overrides runOverrides) string {
+	// Nothing reaches Claude until its author agrees to the usage policy
+	if reply, ok := s.requirePolicyAcceptance(ctx, event, text, overrides); !ok {
+		return reply
 	}
+	prompt := text
 
-	command := strings.ToLower(parts[0])
-	args := parts[1:]
+	// A session that filled up is compacted once this run is over; deferred
+	// first so it runs after the session is released
+	var compactSessionID, compactThreadTS string
+	defer func() {
+		if compactSessionID != "" {
+			go s.runAutoCompaction(event.Channel, event.User, compactThreadTS, compactSessionID)
+		}
+	}()
 
-	s.logger.Info("Processing command",
-		zap.String("command", command),
-		zap.Strings("args", args),
-		zap.String("user_id", event.User))
+	// Ask before running the same prompt twice at once
+	donePrompt, ok := s.trackPrompt(ctx, event, text, overrides)
+	if !ok {
+		return ""
+	}
+	defer donePrompt()
 
-	// Check if command exists
-	handler, exists := commandRegistry[command]
-	if !exists {
-		return fmt.Sprintf("❌ Unknown command: `%s`. Type `help` for available commands.", command)
+	// Archiving the channel cancels the run, whether queued or running
+	ctx, untrack := s.channelRuns.track(ctx, event.Channel)
+	defer untrack()
+
+	// Observer channels only discuss the conversation; nothing runs on the host
+	channelSettings := s.channelSettings(event.Channel)
+	observer := observerMode(channelSettings)
+	if observer && len(event.Files) > 0 {
+		return "👁️ **Observer channel:** attachments aren't read here. Paste the text you want discussed instead."
 	}
 
-	// Execute command
-	response, err := handler(ctx, event, args)
-	if err != nil {
-		s.logger.Error("Command execution failed", zap.Error(err))
-		return fmt.Sprintf("❌ Command failed: %v", err)
+	// An attached diff or patch gets a review of its own rather than a turn
+	// in the session's conversation
+	if s.config.PatchReview {
+		if attachment := patchAttachment(event.Files); attachment != nil {
+			return s.reviewPatch(ctx, event, text, attachment)
+		}
 	}
 
-	return response
-}
+	// Let Claude read the messages behind any pasted Slack permalinks
+	text = s.inlineMessageLinks(event.User, event.Channel, text)
 
-// processClaudeMessage processes Claude conversation messages
-func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.MessageEvent, text string) string {
 	// Process file attachments if present
 	downloadedFiles := []*files.FileInfo{}
+	transcriptPrompts := []string{}
+	// Recordings and their transcripts are referenced by the transcript
+	// prompts, not as images; they're only kept here to be cleaned up
+	var transcribedFiles []*files.FileInfo
 	if len(event.Files) > 0 {
 		for _, file := range event.Files {
 			// Only process image files
@@ -461,16 +906,54 @@ func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.M
 
 				fileInfo, err := s.fileDownloader.DownloadFile(file.ID, event.User)
 				if err != nil {
+					var quotaErr *files.QuotaError
+					if errors.As(err, &quotaErr) {
+						return formatQuotaError(file.Name, quotaErr)
+					}
 					s.logger.Error("Failed to download image", 
 						zap.String("fileID", file.ID), 
 						zap.Error(err))
 					return fmt.Sprintf("❌ Failed to process image %s: %v", file.Name, err)
 				}
 				downloadedFiles = append(downloadedFiles, fileInfo)
+			} else if files.IsAudioMimeType(file.Mimetype) {
+				if !s.transcriber.Enabled() {
+					return fmt.Sprintf("❌ **Audio transcription is not configured**\n\nSet `TRANSCRIBE_COMMAND` or `TRANSCRIBE_URL` to send recordings like %s to Claude.", file.Name)
+				}
+				s.logger.Info("Processing audio attachment",
+					zap.String("fileID", file.ID),
+					zap.String("filename", file.Name),
+					zap.String("mimetype", file.Mimetype))
+
+				transcribed, prompt, err := s.transcribeAttachment(ctx, event.Channel, event.ThreadTimeStamp, event.User, file.ID, file.Name)
+				transcribedFiles = append(transcribedFiles, transcribed...)
+				if err != nil {
+					var quotaErr *files.QuotaError
+					if errors.As(err, &quotaErr) {
+						return formatQuotaError(file.Name, quotaErr)
+					}
+					s.logger.Error("Failed to transcribe audio",
+						zap.String("fileID", file.ID),
+						zap.Error(err))
+					for _, fileInfo := range transcribed {
+						s.fileDownloader.CleanupFile(fileInfo.LocalPath)
+					}
+					return fmt.Sprintf("❌ Failed to process recording %s: %v", file.Name, err)
+				}
+				transcriptPrompts = append(transcriptPrompts, prompt)
 			}
 		}
 	}
 
+	// Reference transcripts of attached recordings, asking for a summary when
+	// the recording was posted without a message
+	if len(transcriptPrompts) > 0 {
+		if text == "" {
+			text = defaultRecordingPrompt
+		}
+		text = strings.Join(transcriptPrompts, ". ") + ". " + text
+	}
+
 	// Add image references to the text if files were downloaded
 	if len(downloadedFiles) > 0 {
 		imagePrompts := []string{}
@@ -485,9 +968,24 @@ func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.M
 		}
 	}
 
+	// Files Claude derives from attached images are sent back to the thread
+	var images []*files.FileInfo
+	var storageBefore map[string]time.Time
+	if s.config.ImageOutputUploads {
+		for _, fileInfo := range downloadedFiles {
+			if s.IsImageMimeType(fileInfo.MimeType) {
+				images = append(images, fileInfo)
+			}
+		}
+		if len(images) > 0 {
+			text += "\n\n" + imageOutputPrompt(images)
+			storageBefore = s.fileDownloader.Snapshot()
+		}
+	}
+
 	// Schedule cleanup of downloaded files
 	defer func() {
-		for _, fileInfo := range downloadedFiles {
+		for _, fileInfo := range append(downloadedFiles, transcribedFiles...) {
 			go func(path string) {
 				time.Sleep(5 * time.Minute) // Wait 5 minutes before cleanup
 				s.fileDownloader.CleanupFile(path)
@@ -502,6 +1000,26 @@ func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.M
 		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to create session")
 	}
 
+	// Observer runs start in an empty directory instead of the session's
+	workDir := userSession.GetCurrentWorkDir()
+	shownWorkDir := workDir
+	if observer {
+		if workDir, err = observerWorkDir(); err != nil {
+			errCtx := logging.CreateErrorContext(event.Channel, event.User, "message_processor", "observer_workdir")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to prepare the observer run; Claude was not run")
+		}
+		shownWorkDir = "none (observer mode)"
+	}
+
+	// Refuse messages too large to send rather than leave it to the CLI
+	if tooLarge := promptTooLarge(text, s.config.PromptMaxTokens); tooLarge != "" {
+		s.logger.Warn("Refused oversized prompt",
+			zap.String("channel_id", event.Channel),
+			zap.String("session_id", userSession.GetID()),
+			zap.Int("estimated_tokens", estimateTokens(text)))
+		return tooLarge
+	}
+
 	// Check if we should queue this message
 	queued, err := s.sessionManager.QueueMessage(userSession.GetID(), text)
 	if err != nil {
@@ -515,15 +1033,28 @@ func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.M
 		return "" // Message queued, no response needed yet
 	}
 
-	// Check rate limiting
-	limited, remaining, err := s.sessionManager.CheckRateLimit(userSession.GetID())
-	if err != nil {
-		s.logger.Error("Rate limit check failed", zap.Error(err))
-		return "❌ Failed to check rate limit"
+	// Fail fast on problems that would otherwise kill the run partway through
+	if failed := s.preflight(workDir); failed != nil {
+		s.logger.Warn("Pre-flight check failed",
+			zap.String("channel_id", event.Channel),
+			zap.String("session_id", userSession.GetID()),
+			zap.Error(failed))
+		return failed.message()
 	}
 
-	if limited {
-		return fmt.Sprintf("⏱️ Rate limit exceeded. Try again in %v", remaining.Truncate(time.Second))
+	// Don't let edits from one run silently stack on another's uncommitted ones
+	runMode, err := s.getPermissionModeForChannel(event.Channel, userSession.GetID())
+	if err != nil {
+		runMode = config.PermissionModeDefault
+	}
+	if overrides.PermissionMode != "" {
+		runMode = overrides.PermissionMode
+	}
+	if observer {
+		runMode = config.PermissionModePlan
+	}
+	if !s.checkDirtyWorkspace(ctx, event, text, overrides, workDir, runMode) {
+		return ""
 	}
 
 	// Mark as processing
@@ -532,6 +1063,7 @@ func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.M
 		return fmt.Sprintf("❌ Failed to process message: %v", err)
 	}
 	defer s.sessionManager.SetProcessing(userSession.GetID(), false)
+	defer s.beginRun()()
 
 	// Get any queued messages and combine with current message
 	queuedMessages, err := s.sessionManager.GetQueuedMessages(userSession.GetID())
@@ -541,7 +1073,17 @@ func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.M
 	}
 
 	if len(queuedMessages) > 0 {
-		text = strings.Join(append([]string{text}, queuedMessages...), " ")
+		fit := fitQueuedMessages(text, queuedMessages, s.config.PromptMaxTokens)
+		text = fit.Text
+		if fit.Dropped > 0 || fit.Truncated > 0 {
+			s.logger.Warn("Trimmed queued messages to fit the prompt limit",
+				zap.String("channel_id", event.Channel),
+				zap.String("session_id", userSession.GetID()),
+				zap.Int("queued", len(queuedMessages)),
+				zap.Int("dropped", fit.Dropped),
+				zap.Int("truncated", fit.Truncated))
+			s.sendThreadResponse(event.Channel, event.ThreadTimeStamp, trimmedQueueNotice(fit, s.config.PromptMaxTokens))
+		}
 	}
 
 	// Send "Thinking..." message immediately and capture for deletion
@@ -550,45 +1092,33 @@ func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.M
 	if err != nil {
 		currentMode = config.PermissionModeDefault
 	}
+	if overrides.PermissionMode != "" {
+		currentMode = overrides.PermissionMode
+	}
+	if observer {
+		currentMode = config.PermissionModePlan
+	}
 	
 	// Format Thinking message with Mode, Session, and Working Dir
 	thinkingMsg := fmt.Sprintf("🤔 _Thinking..._\n\n_• Mode: `%s`\n• Session: `%s`\n• Working Dir: `%s`_",
-		currentMode, userSession.GetID(), userSession.GetCurrentWorkDir())
+		currentMode, userSession.GetID(), shownWorkDir)
 	
-	_, thinkingTimestamp, err := s.slackAPI.PostMessage(event.Channel, slack.MsgOptionText(thinkingMsg, false))
-	if err != nil {
-		s.logger.Error("Failed to send thinking message", zap.Error(err))
-		thinkingTimestamp = "" // Ensure it's empty if posting failed
-	}
+	thinkingTimestamp := s.postThinkingMessage(event.Channel, event.ThreadTimeStamp, userSession.GetID(), thinkingMsg)
 
-	// Get allowed tools for this user
-	// Empty AllowedTools means all tools are allowed (full system access)
-	allowedTools := s.config.AllowedTools
+	// Error paths return without a reply of their own, so never leave the
+	// placeholder behind; clearing is a no-op once it's gone
+	defer s.clearThinkingMessage(event.Channel, thinkingTimestamp)
 
-	// If no tools specified (empty array), allow all tools by passing empty array to Claude Code
-	// Claude Code will use all available tools when no --allowedTools is specified
-	if len(allowedTools) == 0 {
-		allowedTools = []string{} // Empty means all tools
-	} else {
-		// Filter out disallowed tools if specific tools are configured
-		filteredTools := []string{}
-		for _, tool := range allowedTools {
-			isDisallowed := false
-			for _, disallowed := range s.config.DisallowedTools {
-				if tool == disallowed {
-					isDisallowed = true
-					break
-				}
-			}
-			if !isDisallowed {
-				filteredTools = append(filteredTools, tool)
-			}
-		}
-		allowedTools = filteredTools
-	}
+	// Cross-post to the channel's mirror, if it has one; the response follows
+	// the mirrored prompt in a thread
+	mirror := mirrorFor(channelSettings)
+	mirrorTS := s.mirrorPrompt(mirror, event, prompt)
 
-	// For database sessions, we handle concurrency differently
-	// TODO: Implement database-level session locking if needed
+	// Get allowed tools for this channel
+	allowedTools := s.allowedTools(channelSettings)
+
+	// SetProcessing above keeps /delete and session switches from pulling the
+	// session out from under this run
 
 	// Determine Claude session ID based on conversation state
 	var claudeSessionID string
@@ -632,21 +1162,203 @@ func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.M
 		s.logger.Error("Failed to get permission mode", zap.Error(permErr))
 		permMode = config.PermissionModeDefault
 	}
+	if overrides.PermissionMode != "" {
+		permMode = overrides.PermissionMode
+	}
+
+	// Degrade gracefully as the session or channel approaches its budget
+	model := s.channelModel(channelSettings)
+	if overrides.Model != "" {
+		model = overrides.Model
+	}
+	budgetDecision := s.applyBudgetPolicy(userSession.GetID(), event.Channel, model)
+	if budgetDecision.PlanMode || observer {
+		permMode = config.PermissionModePlan
+	}
+
+	// A recap queued by a session switch comes first so Claude knows where the
+	// conversation left off even if the CLI session has expired
+	extraSystemPrompt := joinPromptSections(s.channelContextPrompt(event.Channel), s.channelFactsPrompt(event.Channel), s.responsePreferencesPrompt(event.User, event.Channel))
+	if recap := s.recaps.take(userSession.GetID()); recap != "" {
+		extraSystemPrompt = strings.TrimSpace(recapPrompt(recap) + "\n\n" + extraSystemPrompt)
+	}
+
+	guardrails, err := s.channelGuardrails(event.Channel)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(event.Channel, event.User, "message_processor", "load_guardrails")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to load channel guardrails; Claude was not run")
+	}
+
+	runOpts := claude.RunOptions{
+		ExtraSystemPrompt: extraSystemPrompt,
+		Guardrails:        guardrails,
+		Model:             budgetDecision.Model,
+		Agents:            s.channelAgents(event.Channel),
+		Env:               s.channelEnvironment(event.Channel),
+		AddDirs:           s.runAddDirs(userSession.GetID()),
+		DisallowedTools:   channelSettings.DisallowedTools,
+	}
+	if observer {
+		restrictToObserver(&runOpts)
+	}
 
-	// Process with Claude Code CLI
-	response, newClaudeSessionID, cost, rawJSON, err := s.claudeExecutor.ProcessClaudeCodeRequest(ctx, text, claudeSessionID, event.User, userSession.GetCurrentWorkDir(), allowedTools, isNewSession, permMode)
+	// Mirror Claude's task list into a live checklist for multi-step work
+	if s.config.TaskTracker {
+		tracker := s.startTaskTracker(event.Channel, event.ThreadTimeStamp)
+		runOpts.OnTodos = tracker.update
+		defer tracker.finish()
+	}
+
+	// Post commands and edits to the thread as they happen, if the channel asks
+	if !observer && s.toolEventsEnabled(channelSettings) {
+		threadTS := event.ThreadTimeStamp
+		if threadTS == "" {
+			threadTS = event.TimeStamp
+		}
+		events := s.startToolEventLog(event.Channel, threadTS, workDir)
+		runOpts.OnToolUse = events.record
+		defer events.finish()
+	}
+
+	// Note the environment the run starts in, for the footer
+	envFooter := captureEnvSnapshot(ctx, s.channelFooterEnv(event.Channel), workDir, runOpts.Env).format()
+
+	// Snapshot the work tree so file edits can be shown as a diff
+	diffBase := s.snapshotWorkDir(workDir)
+
+	// Process with Claude Code CLI once a run slot is free; urgent channels
+	// go first
+	priority, _ := s.channelRunPriority(event.Channel)
+	runStart := time.Now()
+	receipt := &runReceipt{UserID: event.User, ChannelID: event.Channel, MessageTS: event.TimeStamp, Prompt: prompt, Started: runStart, Outcome: runFailed}
+	defer s.postRunReceipt(receipt)
+	var claudeResponse *claude.ClaudeCodeResponse
+	run := func(runCtx context.Context) error {
+		var runErr error
+		claudeResponse, runErr = s.claudeExecutor.ProcessClaudeCodeRequest(runCtx, text, claudeSessionID, event.User, workDir, allowedTools, isNewSession, permMode, runOpts)
+		return runErr
+	}
+	if notice := s.slowModeNotice(); notice != "" {
+		threadTS := event.ThreadTimeStamp
+		if threadTS == "" {
+			threadTS = event.TimeStamp
+		}
+		s.sendThreadResponse(event.Channel, threadTS, notice)
+	}
+	err = s.runQueued(ctx, priority, nil, run)
+
+	// The CLI keeps sessions on local disk, so a host restart or cache wipe
+	// loses them; start a fresh one seeded with what the database kept
+	resumeFailed := err != nil && !isNewSession && claude.IsSessionNotFound(err)
+	if resumeFailed {
+		s.logger.Warn("Claude session to resume is gone; starting a fresh one",
+			zap.String("bot_session_id", userSession.GetID()),
+			zap.String("claude_session_id", claudeSessionID))
+		claudeSessionID = uuid.New().String()
+		isNewSession = true
+		if seed := s.reconstructedContextPrompt(event.User, userSession.GetID()); seed != "" {
+			runOpts.ExtraSystemPrompt = strings.TrimSpace(seed + "\n\n" + runOpts.ExtraSystemPrompt)
+		}
+		err = s.runQueued(ctx, priority, nil, run)
+	}
+	if err != nil && errors.Is(context.Cause(ctx), errChannelArchived) {
+		receipt.Outcome = runCanceled
+		s.logger.Info("Run canceled because its channel was archived",
+			zap.String("channel_id", event.Channel),
+			zap.String("bot_session_id", userSession.GetID()))
+		return ""
+	}
+	if err != nil && errors.Is(context.Cause(ctx), errRunsCleared) {
+		receipt.Outcome = runCanceled
+		s.logger.Info("Run canceled by an operator",
+			zap.String("channel_id", event.Channel),
+			zap.String("bot_session_id", userSession.GetID()))
+		return "🛑 **Canceled by an operator**"
+	}
 	if err != nil {
 		s.logger.Error("Claude Code processing failed", zap.Error(err))
 		errCtx := logging.CreateErrorContext(event.Channel, event.User, "message_processor", "claude_processing")
 		errCtx.WithSession(claudeSessionID)
-		return s.logErrorWithTrace(ctx, errCtx, err, "Claude Code processing failed")
+		errorMessage := s.logErrorWithTrace(ctx, errCtx, err, "Claude Code processing failed")
+		failedRunID := s.recordFailedRun(event, userSession.GetID(), workDir, prompt, err)
+		if failedRunID != 0 {
+			errorMessage += fmt.Sprintf("\n\n_Recorded as failed run `#%d`; an admin can replay it with `/failed retry %d`._", failedRunID, failedRunID)
+		}
+
+		var partialErr *claude.PartialResultError
+		if errors.As(err, &partialErr) {
+			receipt.Outcome = runPartial
+			return s.salvagePartialResult(ctx, event.Channel, event.User, thinkingTimestamp, userSession.GetID(), partialErr, errorMessage)
+		}
+		if failedRunID != 0 && s.postErrorCard(event.Channel, event.ThreadTimeStamp, claudeSessionID, failedRunID, err) {
+			if note := s.slowModeFailureNote(err); note != "" {
+				s.sendThreadResponse(event.Channel, event.ThreadTimeStamp, note)
+			}
+			return ""
+		}
+		if note := s.slowModeFailureNote(err); note != "" {
+			errorMessage += "\n\n" + note
+		}
+		return errorMessage
+	}
+	response := claudeResponse.Result
+	newClaudeSessionID := claudeResponse.SessionID
+
+	// Redaction, footers, and other hooks apply to what's posted; the stored
+	// conversation keeps Claude's own words
+	postedResponse := s.postProcessResponse(ctx, event.Channel, event.User, userSession.GetID(), response)
+	go s.mirrorResponse(mirror, event, mirrorTS, postedResponse)
+
+	// Don't let a run that needs a human stall quietly
+	escalationThreadTS := event.ThreadTimeStamp
+	if escalationThreadTS == "" {
+		escalationThreadTS = event.TimeStamp
+	}
+	escalationNote := s.maybeEscalate(event.Channel, escalationThreadTS, event.User, userSession.GetID(), postedResponse)
+	cost := claudeResponse.TotalCostUSD
+	rawJSON := claudeResponse.LatestResponse
+	receipt.Outcome, receipt.CostUSD = runCompleted, cost
+
+	// Long shell output gets attached in full instead of being lost to truncation
+	var bashRuns []claude.ToolRun
+	if s.config.CommandLogAttachments {
+		bashRuns = commandLogRuns(claudeResponse.ToolRuns, s.config.CommandLogMinBytes)
+		if len(bashRuns) > 0 {
+			threadTS := event.ThreadTimeStamp
+			if threadTS == "" {
+				threadTS = event.TimeStamp
+			}
+			go s.attachCommandLogs(event.Channel, threadTS, event.User, bashRuns)
+		}
 	}
+
+	if storageBefore != nil {
+		if generated := s.fileDownloader.GeneratedFiles(images, storageBefore); len(generated) > 0 {
+			threadTS := event.ThreadTimeStamp
+			if threadTS == "" {
+				threadTS = event.TimeStamp
+			}
+			go s.attachGeneratedFiles(event.Channel, threadTS, event.User, generated)
+		}
+	}
+
+	// Show what changed instead of just saying a file was edited
+	if editedFiles := claudeResponse.EditedFiles(); diffBase != "" && len(editedFiles) > 0 {
+		threadTS := event.ThreadTimeStamp
+		if threadTS == "" {
+			threadTS = event.TimeStamp
+		}
+		go s.attachEditDiffs(event.Channel, threadTS, event.User, workDir, diffBase, editedFiles)
+	}
+	s.recordRunEdits(workDir, claudeResponse.EditedFiles())
 	
 	// Store the latest response (raw JSON)
 	if err := s.sessionManager.UpdateLatestResponse(userSession.GetID(), rawJSON); err != nil {
 		s.logger.Error("Failed to update latest response", zap.Error(err))
 	}
 
+	s.recordUsage(userSession.GetID(), event.Channel, event.User, budgetDecision.Model, claudeResponse, time.Since(runStart))
+
 	// Always store Claude's returned session ID as a child session for future resume operations
 	if newClaudeSessionID != "" {
 		if dbManager, ok := s.sessionManager.(*session.DatabaseManager); ok {
@@ -664,18 +1376,21 @@ func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.M
 		}
 	}
 
+	// Name a new session after its first exchange
+	if isNewSession && newClaudeSessionID != "" {
+		go s.titleSession(userSession.GetID(), text, response)
+	}
+
 	// Permission mode persists until explicitly changed
 
 	// Note: Working directory is preserved from the session's configured path
 	// Claude Code execution might change directories internally, but the session keeps its base path
 
 	// Delete the "Thinking..." message now that we have the response
-	if thinkingTimestamp != "" {
-		_, _, deleteErr := s.slackAPI.DeleteMessage(event.Channel, thinkingTimestamp)
-		if deleteErr != nil {
-			s.logger.Debug("Failed to delete thinking message", zap.Error(deleteErr))
-		}
-	}
+	s.clearThinkingMessage(event.Channel, thinkingTimestamp)
+
+	// Save the exchange if the channel is recording a demo
+	demoName := s.recordDemoStep(event.Channel, text, response, time.Since(runStart))
 
 	// Log cost for monitoring
 	s.logger.Info("Claude Code request completed",
@@ -689,411 +1404,237 @@ func (s *Service) processClaudeMessage(ctx context.Context, event *slackevents.M
 	if getPermErr != nil {
 		currentMode = config.PermissionModeDefault
 	}
-	
-	// Get message count for display
-	displayMessageCount, err := s.sessionManager.GetTotalMessageCount(userSession.GetID())
-	if err != nil {
-		s.logger.Debug("Failed to get message count for display", zap.Error(err))
-		displayMessageCount = 0 // fallback to 0
-	}
-	
-	response = fmt.Sprintf("%s\n\n• Mode: _%s_\n• Session: _%s_\n• Working Dir: _%s_\n• Messages: _%d_",
-		response, currentMode, newClaudeSessionID, userSession.GetCurrentWorkDir(), displayMessageCount)
-
-	return response
-}
-
-// processSlashCommand processes slash commands
-func (s *Service) processSlashCommand(ctx context.Context, command *slack.SlashCommand) string {
-	authCtx := &auth.AuthContext{
-		UserID:    command.UserID,
-		ChannelID: command.ChannelID,
-		Command:   command.Command,
-		Timestamp: time.Now(),
-	}
 
-	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionRead); err != nil {
-		return fmt.Sprintf("❌ Authorization failed: %v", err)
+	footerTemplate := channelFooterTemplate(channelSettings)
+	sessionTitle := s.sessionTitle(userSession.GetID())
+	footerValues := map[string]string{
+		"mode":          fmt.Sprintf("%s%s", currentMode, s.permissionExpiryNote(event.Channel)),
+		"session":       sessionFooterLabel(sessionTitle, newClaudeSessionID),
+		"short_session": sessionFooterLabel(sessionTitle, shortSessionID(newClaudeSessionID)),
+		"workdir":       shownWorkDir,
+		"env":           envFooter,
+		"agents":        strings.Join(claudeResponse.AgentsUsed(), ", "),
 	}
-
-	return s.processCommand(ctx, &slackevents.MessageEvent{
-		User:    command.UserID,
-		Channel: command.ChannelID,
-		Text:    command.Text,
-	}, command.Text)
-}
-
-// sendResponse sends a response message to a channel
-func (s *Service) sendResponse(channelID, message string) {
-	// Split long messages
-	messages := s.splitMessage(message, s.config.MaxMessageLength)
-
-	for _, msg := range messages {
-		_, _, err := s.slackAPI.PostMessage(channelID,
-			slack.MsgOptionText(msg, false),
-			slack.MsgOptionAsUser(true))
-
+	if strings.Contains(footerTemplate, "{messages}") {
+		// Get message count for display
+		displayMessageCount, err := s.sessionManager.GetTotalMessageCount(userSession.GetID())
 		if err != nil {
-			s.logger.Error("Failed to send message", zap.Error(err))
+			s.logger.Debug("Failed to get message count for display", zap.Error(err))
+			displayMessageCount = 0 // fallback to 0
 		}
+		footerValues["messages"] = fmt.Sprintf("%d", displayMessageCount)
 	}
-}
-
-// splitMessage splits long messages into smaller chunks
-func (s *Service) splitMessage(message string, maxLength int) []string {
-	if len(message) <= maxLength {
-		return []string{message}
+	if len(bashRuns) > 0 {
+		footerValues["command_logs"] = fmt.Sprintf("full output of %d command(s) attached in thread", len(bashRuns))
 	}
+	footer := renderFooterTemplate(footerTemplate, footerValues)
 
-	var messages []string
-	words := strings.Split(message, " ")
-	var currentMessage strings.Builder
+	// Notices that need attention are shown whatever the footer template
+	if escalationNote != "" {
+		footer = append(footer, fmt.Sprintf("• Escalation: _%s_", escalationNote))
+	}
 
-	for _, word := range words {
-		if currentMessage.Len()+len(word)+1 > maxLength {
-			if currentMessage.Len() > 0 {
-				messages = append(messages, currentMessage.String())
-				currentMessage.Reset()
-			}
-		}
+	if notice := budgetDecision.Notice(); notice != "" {
+		footer = append(footer, fmt.Sprintf("• Budget: _%s_", notice))
+	}
 
-		if currentMessage.Len() > 0 {
-			currentMessage.WriteString(" ")
+	if !observer && s.autoCompactionDue(event.Channel, prompt, claudeResponse.ContextTokens()) {
+		compactSessionID, compactThreadTS = userSession.GetID(), event.ThreadTimeStamp
+		if compactThreadTS == "" {
+			compactThreadTS = event.TimeStamp
 		}
-		currentMessage.WriteString(word)
+		footer = append(footer, "• Context: _🗜️ nearly full; compacting automatically_")
+	} else if warning := s.contextWarning(claudeResponse.ContextTokens()); warning != "" {
+		footer = append(footer, fmt.Sprintf("• Context: _⚠️ %s_", warning))
 	}
 
-	if currentMessage.Len() > 0 {
-		messages = append(messages, currentMessage.String())
+	if demoName != "" {
+		footer = append(footer, fmt.Sprintf("• Demo: _recorded in `%s`_", demoName))
 	}
 
-	return messages
-}
-
-// handleBlockActions handles block actions from interactive components
-func (s *Service) handleBlockActions(callback *slack.InteractionCallback) {
-	for _, action := range callback.ActionCallback.BlockActions {
-		s.logger.Debug("Block action",
-			zap.String("action_id", action.ActionID),
-			zap.String("value", action.Value))
+	if resumeFailed {
+		footer = append(footer, fmt.Sprintf("• Context: _%s_", resumeFallbackNote))
 	}
-}
-
-// handleShortcut handles shortcuts
-func (s *Service) handleShortcut(callback *slack.InteractionCallback) {
-	s.logger.Debug("Shortcut",
-		zap.String("callback_id", callback.CallbackID))
-}
 
-// periodicCleanup performs periodic cleanup tasks
-func (s *Service) periodicCleanup() {
-	ticker := time.NewTicker(time.Hour)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			s.authService.CleanupExpiredEntries()
-			s.logger.Debug("Performed periodic cleanup")
-		case <-s.stopCh:
-			return
-		}
+	response = postedResponse
+	if len(footer) > 0 {
+		response += "\n\n" + strings.Join(footer, "\n")
 	}
-}
-
-// registerCommands registers built-in commands
-func (s *Service) registerCommands() {
-	commandRegistry["help"] = s.handleHelpCommand
-	commandRegistry["status"] = s.handleStatusCommand
-	commandRegistry["sessions"] = s.handleSessionsCommand
-	commandRegistry["close"] = s.handleCloseSessionCommand
-	commandRegistry["stats"] = s.handleStatsCommand
-	commandRegistry["version"] = s.handleVersionCommand
-	commandRegistry["session"] = s.handleSetSessionCommand
-	// Debug command is handled through slash commands only
-	commandRegistry["stop"] = s.handleStopCommand
-}
 
-// Command handlers
-func (s *Service) handleHelpCommand(ctx context.Context, event *slackevents.MessageEvent, args []string) (string, error) {
-	return s.getHelpMessage(), nil
-}
-
-func (s *Service) handleStatusCommand(ctx context.Context, event *slackevents.MessageEvent, args []string) (string, error) {
-	uptime := time.Since(s.startTime).Truncate(time.Second)
-	sessionStats := s.sessionManager.GetSessionStats()
-	authStats := s.authService.GetStats()
-
-	return fmt.Sprintf(`📊 *Bot Status*
-
-🟢 Status: Running
-⏰ Uptime: %v
-👥 Total Users: %v
-🎯 Active Sessions: %v
-📝 Total Messages: %v
-🚦 Rate Limit: %d/min
-
-Use `+"`sessions`"+` to see your active sessions.`,
-		uptime,
-		authStats["total_users"],
-		sessionStats["active_sessions"],
-		sessionStats["total_messages"],
-		s.config.RateLimitPerMinute), nil
+	return response
 }
 
-func (s *Service) handleSessionsCommand(ctx context.Context, event *slackevents.MessageEvent, args []string) (string, error) {
-	return s.sessionManager.ListUserSessions(event.User), nil
-}
+// allowedTools returns the tools Claude may use in a channel, with disallowed
+// tools removed. The channel's tool settings take precedence over
+// ALLOWED_TOOLS and add to DISALLOWED_TOOLS.
+func (s *Service) allowedTools(channelSettings *repository.ChannelSettings) []string {
+	// Empty AllowedTools means all tools are allowed (full system access)
+	allowedTools := s.config.AllowedTools
+	if channelSettings.AllowedTools != nil {
+		allowedTools = channelSettings.AllowedTools
+	}
+	disallowedTools := append(append([]string{}, s.config.DisallowedTools...), channelSettings.DisallowedTools...)
 
-func (s *Service) handleCloseSessionCommand(ctx context.Context, event *slackevents.MessageEvent, args []string) (string, error) {
-	sessions := s.sessionManager.GetActiveSessionsForUser(event.User)
-	if len(sessions) == 0 {
-		return "No active sessions to close.", nil
+	// If no tools specified (empty array), allow all tools by passing empty array to Claude Code
+	// Claude Code will use all available tools when no --allowedTools is specified
+	if len(allowedTools) == 0 {
+		return []string{} // Empty means all tools
 	}
 
-	// Close all sessions for the user in this channel
-	closed := 0
-	for _, session := range sessions {
-		if session.GetChannelID() == event.Channel {
-			if err := s.sessionManager.CloseSession(session.GetID()); err != nil {
-				s.logger.Error("Failed to close session", zap.Error(err))
-			} else {
-				closed++
+	// Filter out disallowed tools if specific tools are configured
+	filteredTools := []string{}
+	for _, tool := range allowedTools {
+		isDisallowed := false
+		for _, disallowed := range disallowedTools {
+			if tool == disallowed {
+				isDisallowed = true
+				break
 			}
 		}
+		if !isDisallowed {
+			filteredTools = append(filteredTools, tool)
+		}
 	}
-
-	if closed == 0 {
-		return "No active sessions found in this channel.", nil
-	}
-
-	return fmt.Sprintf("✅ Closed %d session(s) in this channel.", closed), nil
+	return filteredTools
 }
 
-func (s *Service) handleStatsCommand(ctx context.Context, event *slackevents.MessageEvent, args []string) (string, error) {
-	// Check if user is admin
-	if !s.authService.IsUserAdmin(event.User) {
-		return "❌ This command requires admin privileges.", fmt.Errorf("insufficient permissions")
+// salvagePartialResult keeps the output of a failed Claude run: it records the
+// raw output and resumable session, then formats the partial result, run
+// through the response hooks, with the error
+func (s *Service) salvagePartialResult(ctx context.Context, channelID, userID, thinkingTimestamp, sessionID string, partialErr *claude.PartialResultError, errorMessage string) string {
+	if err := s.sessionManager.UpdateLatestResponse(sessionID, partialErr.RawOutput); err != nil {
+		s.logger.Error("Failed to update latest response", zap.Error(err))
 	}
 
-	sessionStats := s.sessionManager.GetSessionStats()
-	authStats := s.authService.GetStats()
-
-	return fmt.Sprintf(`📈 *Detailed Statistics*
-
-**Sessions:**
-• Total: %v
-• Active: %v
-• Messages: %v
-
-**Users:**
-• Total: %v
-• Admins: %v
-• Banned: %v
-
-**Channels:**
-• Total: %v
-
-**System:**
-• Uptime: %v
-• Auth Enabled: %v`,
-		sessionStats["total_sessions"],
-		sessionStats["active_sessions"],
-		sessionStats["total_messages"],
-		authStats["total_users"],
-		authStats["admin_users"],
-		authStats["banned_users"],
-		authStats["total_channels"],
-		time.Since(s.startTime).Truncate(time.Second),
-		authStats["auth_enabled"]), nil
-}
-
-func (s *Service) handleVersionCommand(ctx context.Context, event *slackevents.MessageEvent, args []string) (string, error) {
-	return fmt.Sprintf(`🤖 *%s*
-
-Version: 1.0.0
-Claude Model: %s
-Working Directory: %s
-Command Prefix: %s
-
-Built with ❤️ for Slack`,
-		s.config.BotDisplayName,
-		"claude-code-cli", // Using Claude Code CLI instead of specific model
-		s.config.WorkingDirectory,
-		s.config.CommandPrefix), nil
-}
-
-func (s *Service) handleSetSessionCommand(ctx context.Context, event *slackevents.MessageEvent, args []string) (string, error) {
-	if len(args) == 0 {
-		// Show current session info and available sessions
-		userSession, err := s.sessionManager.GetOrCreateSession(event.User, event.Channel)
-		if err != nil {
-			errCtx := logging.CreateErrorContext(event.Channel, event.User, "session_command", "get_session_info")
-			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to get session info"), err
-		}
-
-		currentSessionID := userSession.GetID()
-		if currentSessionID == "" {
-			currentSessionID = "None (new conversation)"
-		}
-
-		// Get list of available sessions
-		sessions, err := s.sessionManager.ListAllSessions(10)
-		if err != nil {
-			s.logger.Error("Failed to list sessions", zap.Error(err))
-			// Still continue - this is not a fatal error for the help display
-		}
-
-		// Get known paths
-		paths, err := s.sessionManager.GetKnownPaths(10)
-		if err != nil {
-			s.logger.Error("Failed to get known paths", zap.Error(err))
+	// Claude persists the transcript as it goes, so the partial run can be resumed
+	if partialErr.SessionID != "" {
+		if dbManager, ok := s.sessionManager.(*session.DatabaseManager); ok {
+			if err := dbManager.ProcessClaudeAIResponse(sessionID, partialErr.SessionID, partialErr.Partial); err != nil {
+				s.logger.Error("Failed to store partial Claude response as child session",
+					zap.String("bot_session_id", sessionID),
+					zap.String("claude_session_id", partialErr.SessionID),
+					zap.Error(err))
+			}
 		}
+	}
 
-		// Get message count for session info display
-		messageCount, err := s.sessionManager.GetTotalMessageCount(userSession.GetID())
-		if err != nil {
-			messageCount = 0
-		}
-		
-		response := fmt.Sprintf("📋 **Current Session Info**\n\nClaude Session ID: `%s`\nBot Session ID: `%s`\nMessages: %d\n\n**Usage:**\n• `session list` - Show detailed list of all sessions\n• `session <claude-session-id>` - Switch to specific Claude session\n• `session new <path>` - Start new conversation in specific path\n• `session new` - Start new conversation in current directory\n• `session . <path>` - Switch to or create session for specific path",
-			currentSessionID, userSession.GetID(), messageCount)
+	s.clearThinkingMessage(channelID, thinkingTimestamp)
 
-		if len(sessions) > 0 {
-			response += "\n\n**Available Sessions:**\n"
-			for i, session := range sessions {
-				if i >= 5 { // Limit to 5 sessions
-					response += "• _... and more_\n"
-					break
-				}
-				response += fmt.Sprintf("• `%s` - %s (%s)\n", 
-					session.GetID()[:8], // Show first 8 chars of session ID
-					session.GetWorkspaceDir(), 
-					session.GetLastActivity().Format("Jan 2 15:04"))
-			}
-		}
+	partial := s.postProcessResponse(ctx, channelID, userID, sessionID, partialErr.Partial)
+	return fmt.Sprintf("⚠️ *Partial result before failure:*\n\n%s\n\n%s", partial, errorMessage)
+}
 
-		if len(paths) > 0 {
-			response += "\n**Known Paths:**\n"
-			for i, path := range paths {
-				if i >= 5 { // Limit to 5 paths
-					response += "• _... and more_\n"
-					break
-				}
-				response += fmt.Sprintf("• `%s`\n", path)
-			}
-		}
+// sendResponse sends a response message to a channel
+func (s *Service) sendResponse(channelID, message string) {
+	s.sendThreadResponse(channelID, "", message)
+}
 
-		return response, nil
-	}
+// sendThreadResponse sends a response message to a thread, or to the channel
+// when threadTS is empty. It returns the first part's timestamp, or "" if it
+// couldn't be posted.
+func (s *Service) sendThreadResponse(channelID, threadTS, message string) string {
+	ts, _ := s.postThreadResponse(channelID, threadTS, message, false)
+	return ts
+}
 
-	if args[0] == "list" {
-		// Show detailed list of all sessions
-		response, err := s.handleSessionListCommand(event.User, event.Channel)
-		if err != nil {
-			errCtx := logging.CreateErrorContext(event.Channel, event.User, "session_command", "list_sessions")
-			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list sessions"), err
+// sendThreadResponseOrHold is sendThreadResponse for results that are costly
+// to lose, such as Claude's: parts Slack won't take are held in the outbox and
+// redelivered later. held reports whether any part was; if the first part was
+// held, ts is "" since there is no message yet.
+func (s *Service) sendThreadResponseOrHold(channelID, threadTS, message string) (ts string, held bool) {
+	return s.postThreadResponse(channelID, threadTS, message, true)
+}
+
+// postThreadResponse posts message in parts, holding the undelivered ones in
+// the outbox when hold is set
+func (s *Service) postThreadResponse(channelID, threadTS, message string, hold bool) (string, bool) {
+	// Split long messages
+	messages := splitMessage(message, s.config.MaxMessageLength)
+
+	var firstTS string
+	for i, msg := range messages {
+		opts := []slack.MsgOption{
+			slack.MsgOptionText(msg, false),
+			slack.MsgOptionAsUser(true),
 		}
-		return response, nil
-	} else if args[0] == "new" {
-		// Handle new session creation with optional path
-		var workingDir string
-		if len(args) > 1 {
-			workingDir = args[1]
-		} else {
-			workingDir = s.config.WorkingDirectory
+		if threadTS != "" {
+			opts = append(opts, slack.MsgOptionTS(threadTS))
 		}
+		ts, err := s.sender.PostMessage(context.Background(), channelID, opts...)
 
-		// Create a new session with the specified working directory
-		newSession, err := s.sessionManager.CreateSessionWithPath(event.User, event.Channel, workingDir)
 		if err != nil {
-			s.logger.Error("Failed to create new session", zap.Error(err))
-			return "❌ **Error:** Failed to create new session", nil
+			// Hold this part and the rest for redelivery, keeping their order
+			if hold && s.holdUndelivered(channelID, threadTS, messages[i:], err) {
+				return firstTS, true
+			}
+			s.logger.Error("Failed to send message", zap.Error(err))
+		} else if firstTS == "" {
+			firstTS = ts
 		}
+	}
 
-		return fmt.Sprintf("✅ **New Conversation Started**\n\nSession ID: `%s`\nWorking directory: `%s`\nNext message will start a fresh conversation with Claude.", newSession.GetID(), workingDir), nil
-	} else if args[0] == "." {
-		// Switch to or create session for specific path
-		if len(args) < 2 {
-			return "❌ **Usage:** `session . <path>` - Switch to or create session for specific path", nil
-		}
+	return firstTS, false
+}
 
-		newPath := args[1]
-		
-		// Find existing sessions for this path
-		existingSessions, err := s.sessionManager.GetSessionsByPath(newPath, 5)
-		if err != nil {
-			s.logger.Error("Failed to get sessions by path", zap.Error(err))
+// handleBlockActions handles block actions from interactive components
+func (s *Service) handleBlockActions(callback *slack.InteractionCallback) {
+	for _, action := range callback.ActionCallback.BlockActions {
+		s.logger.Debug("Block action",
+			zap.String("action_id", action.ActionID),
+			zap.String("value", action.Value))
+
+		switch action.ActionID {
+		case searchSwitchActionID:
+			go s.handleSearchSwitchAction(callback, action)
+		case sessionPickActionID:
+			go s.handleSessionPickAction(callback, action)
+		case policyAgreeActionID:
+			go s.handlePolicyAgreeAction(callback, action)
+		case dirtyContinueActionID, dirtyCommitActionID, dirtyDiscardActionID:
+			go s.handleDirtyWorkspaceAction(callback, action)
+		case errorRetryActionID, errorReportActionID:
+			go s.handleErrorCardAction(callback, action)
+		case duplicateQueueActionID, duplicateDropActionID:
+			go s.handleDuplicateRunAction(callback, action)
+		case patchApplyActionID:
+			go s.handlePatchApplyAction(callback, action)
 		}
+	}
 
-		if len(existingSessions) == 0 {
-			// No existing sessions for this path, create a new one
-			// For database sessions, no session manipulation needed
+	switch callback.View.CallbackID {
+	case workdirPickerCallbackID:
+		s.handleWorkdirPickerAction(callback)
+	}
+}
 
-			return fmt.Sprintf("✅ **New Session Created for Path**\n\nWorking directory: `%s`\nNext message will start a fresh conversation in this path.", newPath), nil
-		} else {
-			// Found existing sessions, let user choose
-			response := fmt.Sprintf("📋 **Found %d existing session(s) for path:** `%s`\n\n", len(existingSessions), newPath)
-			response += "**Available Sessions:**\n"
-			
-			for i, session := range existingSessions {
-				if i >= 3 { // Limit to 3 sessions
-					response += "• _... and more_\n"
-					break
-				}
-				response += fmt.Sprintf("• `%s` - Last used: %s\n", 
-					session.GetID(), 
-					session.GetLastActivity().Format("Jan 2 15:04"))
-			}
-			
-			response += "\n**Usage:**\n"
-			response += fmt.Sprintf("• `session %s` - Use most recent session\n", existingSessions[0].GetID()[:8])
-			response += fmt.Sprintf("• `session new %s` - Create new session for this path", newPath)
-			
-			return response, nil
-		}
-	} else {
-		// Switch to specific Claude session ID
-		sessionID := args[0]
+// handleViewSubmission handles modal submissions
+func (s *Service) handleViewSubmission(callback *slack.InteractionCallback) {
+	s.logger.Debug("View submission",
+		zap.String("callback_id", callback.View.CallbackID),
+		zap.String("user_id", callback.User.ID))
 
-		// For database sessions, session switching is handled differently
-		// Session ID is managed automatically
-		return fmt.Sprintf("✅ **Session Switched**\n\nNow using Claude session: `%s`\n\nNext message will resume this conversation.", sessionID), nil
+	switch callback.View.CallbackID {
+	case workdirPickerCallbackID:
+		go s.handleWorkdirPickerSubmission(callback)
+	case composeCallbackID:
+		go s.handleComposeSubmission(callback)
 	}
 }
 
-// getHelpMessage returns the help message
-func (s *Service) getHelpMessage() string {
-	return fmt.Sprintf(`🤖 *%s Help*
-
-**Commands:**
-• `+"`help`"+` - Show this help message
-• `+"`status`"+` - Show bot status
-• `+"`sessions`"+` - List your active sessions
-• `+"`session`"+` - Show current Claude session ID
-• `+"`session <id>`"+` - Switch to specific Claude session
-• `+"`session new`"+` - Start a new conversation
-• `+"`close`"+` - Close session in this channel
-• `+"`stats`"+` - Show statistics (admin only)
-• `+"`version`"+` - Show bot version
-
-**Usage:**
-• Direct message: Just type your message
-• Channel: Use `+"`%s <message>`"+` or mention @%s
-• Ask Claude anything about code, files, or development tasks
-
-**Examples:**
-• `+"`%s help me debug this Python script`"+`
-• `+"`%s list files in /tmp`"+`
-• `+"`%s explain this error message`"+`
-
-Type any message to start a conversation with!`,
-		s.config.BotDisplayName,
-		s.config.CommandPrefix,
-		s.config.BotDisplayName,
-		s.config.CommandPrefix,
-		s.config.CommandPrefix,
-		s.config.CommandPrefix)
+// periodicCleanup performs periodic cleanup tasks
+func (s *Service) periodicCleanup() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.authService.CleanupExpiredEntries()
+			s.logger.Debug("Performed periodic cleanup")
+		case <-s.stopCh:
+			return
+		}
+	}
 }
 
 // startHTTPServer starts the HTTP server for Events API
@@ -1101,7 +1642,7 @@ func (s *Service) startHTTPServer() error {
 	mux := http.NewServeMux()
 
 	// Health check endpoint
-	mux.HandleFunc(s.config.HealthCheckPath, s.handleHealth)
+	mux.HandleFunc(s.config.HealthCheckPath, s.statusEndpoint(s.handleHealth))
 
 	// Slack events endpoint
 	mux.HandleFunc("/slack/events", s.handleSlackEvents)
@@ -1109,18 +1650,24 @@ func (s *Service) startHTTPServer() error {
 	// Slack slash commands endpoint
 	mux.HandleFunc("/slack/commands", s.handleSlashCommands)
 	
-	// Delete session command endpoint  
-	mux.HandleFunc("/slack/delete", s.handleDeleteCommand)
+	// Delete session command endpoint (routed through the command registry)
+	mux.HandleFunc("/slack/delete", s.handleSlashCommands)
 
-	// Metrics endpoint (basic)
-	mux.HandleFunc("/metrics", s.handleMetrics)
+	// Metrics and version endpoints, unless strict mode has nothing to guard them with
+	if s.serveDiagnostics() {
+		mux.HandleFunc("/metrics", s.statusEndpoint(s.handleMetrics))
+		mux.HandleFunc("/version", s.statusEndpoint(s.handleVersion))
+	}
 
-	// Version endpoint
-	mux.HandleFunc("/version", s.handleVersion)
+	// Admin API for claudectl, only served with a way to authenticate
+	// requests; it has its own listener when ADMIN_LISTEN_ADDR is set
+	if s.adminOnMainListener() {
+		mux.Handle(adminAPIPrefix, s.adminAPI())
+	}
 
 	s.httpServer = &http.Server{
 		Addr:         fmt.Sprintf("%s:%d", s.config.ServerHost, s.config.ServerPort),
-		Handler:      mux,
+		Handler:      s.logRequests(mux),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -1128,7 +1675,8 @@ func (s *Service) startHTTPServer() error {
 
 	s.logger.Info("Starting HTTP server",
 		zap.String("addr", s.httpServer.Addr),
-		zap.String("health_path", s.config.HealthCheckPath))
+		zap.String("health_path", s.config.HealthCheckPath),
+		zap.Bool("strict_security", s.config.StrictSecurity))
 
 	if err := s.httpServer.ListenAndServe(); err != http.ErrServerClosed {
 		s.logger.Error("HTTP server error", zap.Error(err))
@@ -1141,64 +1689,60 @@ func (s *Service) startHTTPServer() error {
 
 // handleSlackEvents handles the /slack/events endpoint for Events API
 func (s *Service) handleSlackEvents(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
+	logger := s.requestLogger(r)
 
 	// Read and verify the request
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		s.logger.Error("Failed to read request body", zap.Error(err))
-		http.Error(w, "Bad request", http.StatusBadRequest)
+	body, perr := readSlackBody(w, r, contentTypeJSON)
+	if perr != nil {
+		s.reject(w, r, perr)
 		return
 	}
 	defer r.Body.Close()
 
 	// Verify Slack signature
 	if !s.verifySlackSignature(r.Header, body) {
-		s.logger.Warn("Invalid Slack signature")
+		logger.Warn("Invalid Slack signature")
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
+	// Check the payload's shape before handing it to the event parser
+	envelope, err := validateEventPayload(body)
+	if err != nil {
+		s.reject(w, r, &payloadError{http.StatusBadRequest, err.Error()})
+		return
+	}
+
+	// Respond to URL verification challenge
+	if envelope.Type == slackevents.URLVerification {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(envelope.Challenge))
+		logger.Info("Responded to URL verification challenge")
+		return
+	}
+
 	// Parse the event
 	eventsAPIEvent, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
 	if err != nil {
-		s.logger.Error("Failed to parse Slack event", zap.Error(err))
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		s.reject(w, r, &payloadError{http.StatusBadRequest, fmt.Sprintf("failed to parse Slack event: %v", err)})
 		return
 	}
 
-	s.logger.Debug("Received Slack event", zap.String("type", eventsAPIEvent.Type))
+	logger.Debug("Received Slack event", zap.String("type", eventsAPIEvent.Type))
 
 	// Handle different event types
 	switch eventsAPIEvent.Type {
-	case slackevents.URLVerification:
-		// Respond to URL verification challenge
-		var challenge slackevents.ChallengeResponse
-		if err := json.Unmarshal(body, &challenge); err != nil {
-			s.logger.Error("Failed to unmarshal challenge", zap.Error(err))
-			http.Error(w, "Bad request", http.StatusBadRequest)
-			return
-		}
-
-		w.Header().Set("Content-Type", "text/plain")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(challenge.Challenge))
-		s.logger.Info("Responded to URL verification challenge")
-		return
-
 	case slackevents.CallbackEvent:
 		// Handle callback events asynchronously
-		go s.handleEventsAPIEvent(&eventsAPIEvent)
+		go s.dispatchEvent(&eventsAPIEvent)
 
 		// Acknowledge immediately
 		w.WriteHeader(http.StatusOK)
 		return
 
 	default:
-		s.logger.Debug("Unhandled event type", zap.String("type", eventsAPIEvent.Type))
+		logger.Debug("Unhandled event type", zap.String("type", eventsAPIEvent.Type))
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -1206,7 +1750,7 @@ func (s *Service) handleSlackEvents(w http.ResponseWriter, r *http.Request) {
 
 // verifySlackSignature verifies the Slack request signature
 func (s *Service) verifySlackSignature(headers http.Header, body []byte) bool {
-	if s.config.SlackSigningSecret == "" {
+	if s.config.SlackCredentials().SigningSecret == "" {
 		s.logger.Error("Slack signing secret not configured, rejecting request")
 		return false // Fail securely when secret is not configured
 	}
@@ -1229,7 +1773,7 @@ func (s *Service) verifySlackSignature(headers http.Header, body []byte) bool {
 	}
 
 	// Calculate expected signature
-	mac := hmac.New(sha256.New, []byte(s.config.SlackSigningSecret))
+	mac := hmac.New(sha256.New, []byte(s.config.SlackCredentials().SigningSecret))
 	mac.Write([]byte(fmt.Sprintf("v0:%s:", timestamp)))
 	mac.Write(body)
 	expectedSignature := "v0=" + hex.EncodeToString(mac.Sum(nil))
@@ -1239,14 +1783,22 @@ func (s *Service) verifySlackSignature(headers http.Header, body []byte) bool {
 
 // handleHealth handles health check requests
 func (s *Service) handleHealth(w http.ResponseWriter, r *http.Request) {
+	status := "healthy"
+	if !s.readiness.Ready() {
+		status = "warming_up"
+	}
 	health := map[string]interface{}{
-		"status":      "healthy",
-		"uptime":      time.Since(s.startTime).String(),
-		"bot_user_id": s.botUserID,
-		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+		"status":       status,
+		"uptime":       time.Since(s.startTime).String(),
+		"bot_user_id":  s.botUserID,
+		"dependencies": s.readiness.Snapshot(),
+		"timestamp":    time.Now().UTC().Format(time.RFC3339),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if status != "healthy" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
 	json.NewEncoder(w).Encode(health)
 }
 
@@ -1291,63 +1843,59 @@ func (s *Service) handleVersion(w http.ResponseWriter, r *http.Request) {
 
 // handleSlashCommands handles Slack slash commands
 func (s *Service) handleSlashCommands(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
+	logger := s.requestLogger(r)
 
 	// Read body first for signature verification
-	bodyBytes, err := io.ReadAll(r.Body)
-	if err != nil {
-		s.logger.Error("Failed to read request body", zap.Error(err))
-		http.Error(w, "Bad request", http.StatusBadRequest)
+	bodyBytes, perr := readSlackBody(w, r, contentTypeForm)
+	if perr != nil {
+		s.reject(w, r, perr)
 		return
 	}
 
-	// Verify Slack signature (if configured)
-	if s.config.SlackSigningSecret != "" {
+	// Verify Slack signature (always in strict mode, else if configured)
+	if s.slashSignatureRequired() {
 		if !s.verifySlackSignature(r.Header, bodyBytes) {
-			s.logger.Warn("Invalid Slack signature for slash command")
+			logger.Warn("Invalid Slack signature for slash command")
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 	}
 
-	// Parse form data from the body we just read
-	formData, err := url.ParseQuery(string(bodyBytes))
+	// Parse and validate the form data from the body we just read
+	payload, err := parseSlashCommandPayload(bodyBytes)
 	if err != nil {
-		s.logger.Error("Failed to parse slash command form data", zap.Error(err))
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		s.reject(w, r, &payloadError{http.StatusBadRequest, err.Error()})
 		return
 	}
 
 	// Extract slash command data from parsed form
-	command := formData.Get("command")
-	text := formData.Get("text")
-	userID := formData.Get("user_id")
-	channelID := formData.Get("channel_id")
+	command := payload.Command
+	text := payload.Text
+	userID := payload.UserID
+	channelID := payload.ChannelID
 
-	s.logger.Info("Received slash command",
+	logger.Info("Received slash command",
 		zap.String("command", command),
 		zap.String("text", text),
 		zap.String("user_id", userID),
 		zap.String("channel_id", channelID))
 
-	// Handle the slash command
+	// Handle the slash command through the registry
 	var response string
-	switch command {
-	case "/session":
-		response = s.handleSessionSlashCommand(userID, channelID, text)
-	case "/permission":
-		response = s.handlePermissionSlashCommand(userID, channelID, text)
-	case "/summarize":
-		response = s.handleSummarizeSlashCommand(userID, channelID)
-	case "/debug":
-		response = s.handleDebugSlashCommand(userID, channelID)
-	case "/stop":
-		response, _ = s.handleStopCommand(context.Background(), &slackevents.MessageEvent{User: userID, Channel: channelID}, nil)
-	default:
-		response = fmt.Sprintf("Unknown command: %s", command)
+	if limited := s.checkRateLimit(userID, channelID); limited != nil {
+		setRateLimitHeaders(w.Header(), limited)
+		response = s.rateLimitMessage(userID, limited)
+	} else {
+		req := commands.NewRequest(command, userID, channelID, text, commands.SourceSlash)
+		req.TriggerID = payload.TriggerID
+		req.ResponseURL = payload.ResponseURL
+		response = s.dispatchCommand(context.Background(), req)
+	}
+
+	// Commands that open a modal have nothing to say; an empty 200 just acknowledges
+	if response == "" {
+		w.WriteHeader(http.StatusOK)
+		return
 	}
 
 	// Send response back to Slack
@@ -1364,20 +1912,6 @@ func (s *Service) handleSlashCommands(w http.ResponseWriter, r *http.Request) {
 
 // handleSessionSlashCommand handles the /session slash command
 func (s *Service) handleSessionSlashCommand(userID, channelID, text string) string {
-	// Create auth context
-	authCtx := &auth.AuthContext{
-		UserID:    userID,
-		ChannelID: channelID,
-		Command:   "/session",
-		Timestamp: time.Now(),
-	}
-
-	// Check authorization
-	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionRead); err != nil {
-		s.logger.Warn("Authorization failed for slash command", zap.Error(err))
-		return fmt.Sprintf("❌ Authorization failed: %v", err)
-	}
-
 	args := strings.Fields(text)
 
 	// If no argument or "help", show help/current info with suggestions
@@ -1394,7 +1928,7 @@ func (s *Service) handleSessionSlashCommand(userID, channelID, text string) stri
 		}
 
 		// Get list of available sessions
-		sessions, err := s.sessionManager.ListAllSessions(10)
+		sessions, err := s.listSessionsForChannel(channelID, "", 10)
 		if err != nil {
 			s.logger.Error("Failed to list sessions", zap.Error(err))
 			// Still continue - this is not a fatal error for the help display
@@ -1420,11 +1954,17 @@ func (s *Service) handleSessionSlashCommand(userID, channelID, text string) stri
 		// Get channel state to determine parent and leaf sessions
 		parentSessionInfo := "None"
 		leafSessionInfo := "None"
+		contextInfo := ""
+		sessionMode := string(session.SessionModeShared)
 		
 		// Access the database manager to get channel state
 		if dbManager, ok := s.sessionManager.(*session.DatabaseManager); ok {
-			channelState, err := dbManager.GetChannelState(channelID)
+			channelState, err := dbManager.GetChannelStateForUser(channelID, userID)
 			if err == nil && channelState != nil {
+				if channelState.SessionMode != "" {
+					sessionMode = channelState.SessionMode
+				}
+
 				// Get parent session info
 				if channelState.ActiveSessionID != nil {
 					if parentSession, err := dbManager.LoadSessionByID(*channelState.ActiveSessionID); err == nil && parentSession != nil {
@@ -1436,13 +1976,16 @@ func (s *Service) handleSessionSlashCommand(userID, channelID, text string) stri
 				if channelState.ActiveChildSessionID != nil {
 					if leafSession, err := dbManager.GetChildSessionByID(*channelState.ActiveChildSessionID); err == nil && leafSession != nil {
 						leafSessionInfo = fmt.Sprintf("`%s`", leafSession.SessionID)
+						if summary := s.contextSummary(leafSession.SessionID); summary != "" {
+							contextInfo = fmt.Sprintf("\n• Context: %s", summary)
+						}
 					}
 				}
 			}
 		}
 		
-		response := fmt.Sprintf("📋 **Session Management Help**\n\n**Current Session:**\n• Parent Session: %s\n• Leaf Session: %s\n• Messages: %d\n\n**Usage:**\n• `/session` - Show this help\n• `/session list` - Show detailed list of all sessions\n• `/session info <uuid>` - Show child conversations for parent session\n• `/session <claude-session-id>` - Switch to specific Claude session\n• `/session new <path>` - Start new conversation in specific path\n• `/session new` - Start new conversation in current directory\n• `/session . <path>` - Switch to or create session for specific path",
-			parentSessionInfo, leafSessionInfo, messageCount)
+		response := fmt.Sprintf("📋 **Session Management Help**\n\n**Current Session:**\n• Parent Session: %s\n• Leaf Session: %s\n• Messages: %d\n• Mode: `%s`%s\n\n**Usage:**\n• `/session` - Show this help\n• `/session list [public|private|dm]` - Show detailed list of sessions\n• `/session info <uuid>` - Show child conversations for parent session\n• `/session <claude-session-id>` - Switch to specific Claude session (the first 8 characters are enough)\n• `/session new <path>` - Start new conversation in specific path\n• `/session new` - Pick a directory and start a new conversation\n• `/session . <path>` - Switch to or create session for specific path\n• `/session mode shared|per-user` - Share one session in this channel or give each user their own\n• `/session adddir <path>` - Let Claude also access another directory\n• `/session rmdir <path>` - Remove an extra directory\n• `/session dirs` - List this session's directories\n• `/session move #channel` - Hand this session over to another channel\n• `/session trash list` - Show deleted sessions\n• `/session restore <session-id>` - Restore a deleted session\n• `/session diff <session-id-a> <session-id-b>` - Compare two branches of a conversation\n• `/session stats` - Show the active session's exchanges, cost, tokens, and files touched",
+			parentSessionInfo, leafSessionInfo, messageCount, sessionMode, contextInfo)
 
 		if len(sessions) > 0 {
 			response += "\n\n**Available Sessions:**\n"
@@ -1454,7 +1997,7 @@ func (s *Service) handleSessionSlashCommand(userID, channelID, text string) stri
 				response += fmt.Sprintf("• `%s` - %s (%s)\n", 
 					session.GetID()[:8], // Show first 8 chars of session ID
 					session.GetWorkspaceDir(), 
-					session.GetLastActivity().Format("Jan 2 15:04"))
+					s.userTime(userID, session.GetLastActivity()).Format("Jan 2 15:04"))
 			}
 		}
 
@@ -1474,9 +2017,46 @@ func (s *Service) handleSessionSlashCommand(userID, channelID, text string) stri
 		return response
 	}
 
+	if args[0] == "mode" {
+		return s.handleSessionModeCommand(userID, channelID, args[1:])
+	}
+
+	if args[0] == "dirs" || args[0] == "adddir" || args[0] == "rmdir" {
+		return s.handleSessionDirCommand(userID, channelID, args[0], args[1:])
+	}
+
+	if args[0] == "move" {
+		return s.handleSessionMoveCommand(userID, channelID, args[1:])
+	}
+
+	if args[0] == "stats" {
+		return s.handleSessionStatsCommand(userID, channelID, args[1:])
+	}
+
+	if args[0] == "trash" {
+		return s.handleSessionTrashCommand(userID, channelID, args[1:])
+	}
+
+	if args[0] == "restore" {
+		return s.handleSessionRestoreCommand(userID, channelID, args[1:])
+	}
+
+	if args[0] == "diff" {
+		return s.handleSessionDiffCommand(userID, channelID, args[1:])
+	}
+
 	if args[0] == "list" {
-		// Show detailed list of all sessions
-		response, err := s.handleSessionListCommand(userID, channelID)
+		// Show detailed list of sessions, optionally from one kind of conversation
+		channelType := ""
+		if len(args) > 1 {
+			switch args[1] {
+			case repository.ChannelTypePublic, repository.ChannelTypePrivate, repository.ChannelTypeDM:
+				channelType = args[1]
+			default:
+				return "❌ **Usage:** `/session list [public|private|dm]` - List sessions, optionally only from one kind of conversation"
+			}
+		}
+		response, err := s.handleSessionListCommand(userID, channelID, channelType)
 		if err != nil {
 			errCtx := logging.CreateErrorContext(channelID, userID, "session_slash_command", "list_sessions")
 			return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to list sessions")
@@ -1492,17 +2072,31 @@ func (s *Service) handleSessionSlashCommand(userID, channelID, text string) stri
 		// Handle new session creation with optional path
 		var workingDir string
 		if len(args) > 1 {
+			if repoURL, ok := cloneTarget(args[1]); ok {
+				return s.startWorkspaceClone(userID, channelID, repoURL)
+			}
 			workingDir = args[1]
-		} else {
+			if err := validateWorkingDir(workingDir); err != nil {
+				return fmt.Sprintf("❌ **Invalid working directory:** %v\n\nUse `/session new` without a path to pick a directory.", err)
+			}
+		} else if s.config.WorkdirTemplate == "" {
 			workingDir = s.config.WorkingDirectory
 		}
 
-		// Create a new session with the specified working directory
-		newSession, err := s.sessionManager.CreateSessionWithPath(userID, channelID, workingDir)
+		// Create a new session with the specified working directory, or the
+		// user's templated workspace when no path was given
+		var newSession session.SessionInfo
+		var err error
+		if workingDir == "" && s.config.WorkdirTemplate != "" {
+			newSession, err = s.sessionManager.CreateSession(userID, channelID)
+		} else {
+			newSession, err = s.sessionManager.CreateSessionWithPath(userID, channelID, workingDir)
+		}
 		if err != nil {
 			s.logger.Error("Failed to create new session", zap.Error(err))
 			return "❌ **Error:** Failed to create new session"
 		}
+		workingDir = newSession.GetCurrentWorkDir()
 
 		return fmt.Sprintf("✅ **New Conversation Started**\n\nSession ID: `%s`\nWorking directory: `%s`\nNext message will start a fresh conversation with Claude.", newSession.GetID(), workingDir)
 	} else if args[0] == "." {
@@ -1520,6 +2114,10 @@ func (s *Service) handleSessionSlashCommand(userID, channelID, text string) stri
 		}
 
 		if len(existingSessions) == 0 {
+			if err := validateWorkingDir(newPath); err != nil {
+				return fmt.Sprintf("❌ **Invalid working directory:** %v\n\nUse `/session new` without a path to pick a directory.", err)
+			}
+
 			// No existing sessions for this path, create a new one
 			newSession, err := s.sessionManager.CreateSessionWithPath(userID, channelID, newPath)
 			if err != nil {
@@ -1540,7 +2138,7 @@ func (s *Service) handleSessionSlashCommand(userID, channelID, text string) stri
 				}
 				response += fmt.Sprintf("• `%s` - Last used: %s\n", 
 					session.GetID(), 
-					session.GetLastActivity().Format("Jan 2 15:04"))
+					s.userTime(userID, session.GetLastActivity()).Format("Jan 2 15:04"))
 			}
 			
 			response += "\n**Usage:**\n"
@@ -1550,47 +2148,15 @@ func (s *Service) handleSessionSlashCommand(userID, channelID, text string) stri
 			return response
 		}
 	} else {
-		// Switch to specific Claude session ID
-		sessionID := args[0]
-
-		// Validate that the session exists first
-		session, err := s.sessionManager.GetSessionBySessionID(sessionID)
-		if err != nil {
-			errCtx := logging.CreateErrorContext(channelID, userID, "session_switch", "validate_session")
-			return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to validate session for switching")
-		}
-
-		if session == nil {
-			return fmt.Sprintf("❌ **Session not found**\n\nSession `%s` does not exist.", sessionID)
-		}
-
-		// Perform the actual session switch
-		err = s.sessionManager.SwitchToSessionInChannel(channelID, sessionID)
-		if err != nil {
-			errCtx := logging.CreateErrorContext(channelID, userID, "session_switch", "update_channel")
-			return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to switch session")
-		}
-
-		return fmt.Sprintf("✅ **Session Switched**\n\nNow using Claude session: `%s`\n\nNext message will resume this conversation.", sessionID)
+		// Switch to a Claude session by its ID or the start of it
+		return s.handleSessionSwitchCommand(userID, channelID, args[0])
 	}
 }
 
-// handlePermissionSlashCommand handles the /permission slash command
-// handleDebugSlashCommand handles the /debug slash command
-func (s *Service) handleDebugSlashCommand(userID, channelID string) string {
-	// For database sessions, latest response functionality is not yet implemented
-	return "❌ Debug response functionality is not available for database sessions yet."
-}
-
 // handleStopCommand handles the /stop command to force-stop current processing
-func (s *Service) handleStopCommand(ctx context.Context, event *slackevents.MessageEvent, args []string) (string, error) {
-	// Check if user is admin
-	if !s.authService.IsUserAdmin(event.User) {
-		return "❌ This command requires admin privileges.", fmt.Errorf("insufficient permissions")
-	}
-
+func (s *Service) handleStopCommand(ctx context.Context, req *commands.Request) (string, error) {
 	// Get session
-	userSession, err := s.sessionManager.GetOrCreateSession(event.User, event.Channel)
+	userSession, err := s.sessionManager.GetOrCreateSession(req.UserID, req.ChannelID)
 	if err != nil {
 		return fmt.Sprintf("❌ Failed to get session: %v", err), err
 	}
@@ -1610,46 +2176,11 @@ func (s *Service) handleStopCommand(ctx context.Context, event *slackevents.Mess
 	return "✅ Processing stopped.", nil
 }
 
-// sendStartupNotification sends a notification to all allowed channels when the bot starts up
-func (s *Service) sendStartupNotification() {
-	// Use all allowed channels for deployment notifications
-	notifyChannels := s.config.AllowedChannels
-	
-	if len(notifyChannels) == 0 {
-		s.logger.Info("No allowed channels configured, skipping startup notification")
-		return
-	}
-
-	s.logger.Info("Sending startup notification", zap.Strings("channels", notifyChannels))
-
-	// Create notifier
-	notifier := notifications.NewDeploymentNotifier(s.slackAPI, notifyChannels, s.logger)
-
-	// Send startup notification in a goroutine to not block startup
-	go func() {
-		// Wait a few seconds to ensure the bot is fully initialized
-		time.Sleep(3 * time.Second)
-
-		changes := []string{
-			"Enhanced session management with interactive features",
-			"Smart path suggestions based on session history",
-			"Improved /session command with session listing",
-			"Path-based session switching with /session . <path>",
-			"Intelligent session selection for existing paths",
-		}
-
-		if err := notifier.NotifyDeployment(changes); err != nil {
-			s.logger.Error("Failed to send startup notification", zap.Error(err))
-		} else {
-			s.logger.Info("Startup notification sent successfully")
-		}
-	}()
-}
-
-// handleSessionListCommand shows a detailed list of all sessions
-func (s *Service) handleSessionListCommand(userID, channelID string) (string, error) {
-	// Get all sessions (limit to 20 for readability)
-	sessions, err := s.sessionManager.ListAllSessions(20)
+// handleSessionListCommand shows a detailed list of the sessions visible in
+// a channel, optionally only those from one channel type
+func (s *Service) handleSessionListCommand(userID, channelID, channelType string) (string, error) {
+	// Get sessions (limit to 20 for readability)
+	sessions, err := s.listSessionsForChannel(channelID, channelType, 20)
 	if err != nil {
 		s.logger.Error("Failed to list sessions", zap.Error(err))
 		errCtx := logging.CreateErrorContext(channelID, userID, "session_list", "retrieve_sessions")
@@ -1667,6 +2198,12 @@ func (s *Service) handleSessionListCommand(userID, channelID string) (string, er
 		sessionsByPath[path] = append(sessionsByPath[path], session)
 	}
 
+	sessionIDs := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		sessionIDs = append(sessionIDs, session.GetID())
+	}
+	titles := s.sessionTitles(sessionIDs)
+
 	response := fmt.Sprintf("📋 **All Sessions** (%d total)\n\n", len(sessions))
 
 	// Show sessions grouped by path
@@ -1688,9 +2225,16 @@ func (s *Service) handleSessionListCommand(userID, channelID string) (string, er
 			
 			sessionID := session.GetID()
 			
+			if title := titles[sessionID]; title != "" {
+				response += fmt.Sprintf("  • *%s* `%s` - Last used: %s\n",
+					title,
+					sessionID,
+					s.userTime(userID, session.GetLastActivity()).Format("Jan 2 15:04"))
+				continue
+			}
 			response += fmt.Sprintf("  • `%s` - Last used: %s\n", 
 				sessionID,
-				session.GetLastActivity().Format("Jan 2 15:04"))
+				s.userTime(userID, session.GetLastActivity()).Format("Jan 2 15:04"))
 		}
 		response += "\n"
 		pathCount++
@@ -1726,6 +2270,9 @@ func (s *Service) handleSessionInfoCommand(userID, channelID, parentSessionID st
 	
 	// Build response
 	response := fmt.Sprintf("📋 **Session Info for: `%s`**\n\n", parentSessionID)
+	if title := s.sessionTitle(parentSessionID); title != "" {
+		response += fmt.Sprintf("**Title:** %s\n\n", title)
+	}
 	
 	if len(children) == 0 {
 		response += "**Child Conversations:** None (new session with no conversations yet)"
@@ -1734,13 +2281,24 @@ func (s *Service) handleSessionInfoCommand(userID, channelID, parentSessionID st
 		for _, child := range children {
 			response += fmt.Sprintf("• `%s` - Created: %s\n", 
 				child.SessionID,
-				child.CreatedAt.Format("Jan 2 15:04"))
+				s.userTime(userID, child.CreatedAt).Format("Jan 2 15:04"))
 		}
 	}
 	
 	return response
 }
 
+// permissionModesHelp lists each permission mode with what it lets Claude do,
+// from the capability matrix in config
+func permissionModesHelp() string {
+	lines := make([]string, 0, len(config.PermissionCapabilities))
+	for _, caps := range config.PermissionCapabilities {
+		lines = append(lines, fmt.Sprintf("• `%s` - %s\n    _%s_", caps.Mode, caps.Description, caps.Summary()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handlePermissionSlashCommand handles the /permission slash command
 func (s *Service) handlePermissionSlashCommand(userID, channelID, text string) string {
 	// Get session
 	userSession, err := s.sessionManager.GetOrCreateSession(userID, channelID)
@@ -1757,7 +2315,12 @@ func (s *Service) handlePermissionSlashCommand(userID, channelID, text string) s
 			currentMode = "default" // fallback
 		}
 
-		return fmt.Sprintf("📋 **Permission Mode Help**\n\n**Current Mode:** `%s`\n\n**Available Modes:**\n• `default` - Standard permissions with user prompts\n• `acceptEdits` - Automatically accept file edits\n• `bypassPermissions` - Bypass all permission checks\n• `plan` - Planning mode, won't execute actions\n\n**Usage:**\n• `/permission` - Show this help\n• `/permission <mode>` - Set permission mode\n• `/permission help` - Show this help", currentMode)
+		return fmt.Sprintf("📋 **Permission Mode Help**\n\n**Current Mode:** `%s`%s%s\n\n**Available Modes:**\n%s\n\n**Usage:**\n• `/permission` - Show this help\n• `/permission <mode>` - Set permission mode\n• `/permission <mode> <duration>` - Set permission mode that reverts to the channel default after the duration (e.g. `30m`, `2h`)\n• `/permission default <mode>` - Set the workspace default for new channels (admin only)\n• `/permission help` - Show this help", currentMode, s.permissionExpiryNote(channelID), s.workspacePermissionNote(), permissionModesHelp())
+	}
+
+	// "/permission default <mode>" changes the workspace-wide default
+	if args[0] == string(config.PermissionModeDefault) && len(args) == 2 && config.PermissionMode(args[1]).IsValid() {
+		return s.setWorkspaceDefaultPermission(userID, config.PermissionMode(args[1]))
 	}
 
 	// Get the permission mode argument
@@ -1765,14 +2328,27 @@ func (s *Service) handlePermissionSlashCommand(userID, channelID, text string) s
 
 	// Validate mode
 	mode := config.PermissionMode(modeStr)
-	switch mode {
-	case config.PermissionModeDefault,
-		config.PermissionModeAcceptEdits,
-		config.PermissionModeBypassPerms,
-		config.PermissionModePlan:
-		// Valid mode
-	default:
-		return "❌ **Invalid Permission Mode**\n\nAvailable modes:\n• `default`\n• `acceptEdits`\n• `bypassPermissions`\n• `plan`\n\nUse `/permission help` for more info."
+	if !mode.IsValid() {
+		return "❌ **Invalid Permission Mode**\n\nAvailable modes:\n" + permissionModesHelp() + "\n\nUse `/permission help` for more info."
+	}
+
+	// Optional TTL after which the channel reverts to default
+	if len(args) > 1 {
+		ttl, parseErr := time.ParseDuration(args[1])
+		if parseErr != nil || ttl <= 0 {
+			return fmt.Sprintf("❌ **Invalid Duration:** `%s`\n\nUse a Go duration such as `30m` or `2h`.", args[1])
+		}
+
+		expiryMgr, ok := s.sessionManager.(session.ChannelPermissionExpiryManager)
+		if !ok {
+			return "❌ **Permission expiry requires database persistence**"
+		}
+		expiresAt, err := expiryMgr.SetPermissionModeForChannelWithExpiry(channelID, mode, ttl)
+		if err != nil {
+			return fmt.Sprintf("❌ Failed to set permission mode: %v", err)
+		}
+
+		return fmt.Sprintf("✅ **Permission Mode Set**\n\nMode: `%s`\nReverts to the channel default in %s (at %s)", mode, formatRemaining(time.Until(expiresAt)), s.userTime(userID, expiresAt).Format("15:04 MST"))
 	}
 
 	// Set mode - use channel-based permissions if available
@@ -1801,6 +2377,38 @@ func (s *Service) handlePermissionSlashCommand(userID, channelID, text string) s
 	return fmt.Sprintf("✅ **Permission Mode Set**\n\nMode: `%s`\nDescription: %s", mode, description)
 }
 
+// setWorkspaceDefaultPermission changes the permission mode new channels start
+// with. Existing channels keep their current mode.
+func (s *Service) setWorkspaceDefaultPermission(userID string, mode config.PermissionMode) string {
+	if !s.authService.IsUserAdmin(userID) {
+		return "❌ **Admin Only**\n\nOnly admins can change the workspace default permission mode."
+	}
+
+	defaultsMgr, ok := s.sessionManager.(session.DefaultPermissionManager)
+	if !ok {
+		return "❌ **Workspace defaults require database persistence**"
+	}
+	if err := defaultsMgr.SetDefaultPermissionMode(mode, userID); err != nil {
+		return fmt.Sprintf("❌ Failed to set workspace default: %v", err)
+	}
+
+	return fmt.Sprintf("✅ **Workspace Default Set**\n\nNew channels start in `%s` mode. Existing channels and those in `CHANNEL_PERMISSION_DEFAULTS` keep theirs.", mode)
+}
+
+// workspacePermissionNote returns a help line with the workspace default
+// permission mode, or an empty string without database persistence
+func (s *Service) workspacePermissionNote() string {
+	defaultsMgr, ok := s.sessionManager.(session.DefaultPermissionManager)
+	if !ok {
+		return ""
+	}
+	mode, err := defaultsMgr.GetDefaultPermissionMode()
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\n**Workspace Default:** `%s`", mode)
+}
+
 // getPermissionModeForChannel is a helper that gets permission mode using channel ID when available
 func (s *Service) getPermissionModeForChannel(channelID string, fallbackSessionID string) (config.PermissionMode, error) {
 	// Use channel-based permissions if available
@@ -1843,7 +2451,7 @@ func (s *Service) handleSummarizeSlashCommand(userID, channelID string) string {
 // performAsyncSummarization performs the actual summarization work in background
 func (s *Service) performAsyncSummarization(userID, channelID, parentSessionID string, children []*repository.ChildSession) {
 	// Format conversation for summarization
-	conversationText, err := s.formatConversationForSummary(parentSessionID, children)
+	conversationText, err := s.formatConversationForSummary(userID, parentSessionID, children)
 	if err != nil {
 		errCtx := logging.CreateErrorContext(channelID, userID, "async_summarization", "format_conversation")
 		s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to format conversation for summarization")
@@ -1896,7 +2504,7 @@ func (s *Service) performAsyncSummarization(userID, channelID, parentSessionID s
 		parentSessionID, len(children), formattedSummary)
 
 	// Send follow-up message to channel
-	_, _, err = s.slackAPI.PostMessage(channelID, slack.MsgOptionText(response, false))
+	_, err = s.sender.PostMessage(context.Background(), channelID, slack.MsgOptionText(response, false))
 	if err != nil {
 		errCtx := logging.CreateErrorContext(channelID, userID, "async_summarization", "post_message")
 		s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to post summary message to channel")
@@ -1912,7 +2520,7 @@ func (s *Service) performAsyncSummarization(userID, channelID, parentSessionID s
 }
 
 // formatConversationForSummary formats the conversation history for Claude summarization
-func (s *Service) formatConversationForSummary(parentSessionID string, children []*repository.ChildSession) (string, error) {
+func (s *Service) formatConversationForSummary(userID, parentSessionID string, children []*repository.ChildSession) (string, error) {
 	// Get parent session to get the initial user prompt
 	parentSession, err := s.sessionManager.GetSessionBySessionID(parentSessionID)
 	if err != nil {
@@ -1923,13 +2531,13 @@ func (s *Service) formatConversationForSummary(parentSessionID string, children
 
 	// Start with parent session user prompt (if exists)
 	if parentSession.UserPrompt != nil {
-		timestamp := parentSession.CreatedAt.Format("Jan 2, 3:04 PM")
+		timestamp := s.userTime(userID, parentSession.CreatedAt).Format("Jan 2, 3:04 PM")
 		conversation.WriteString(fmt.Sprintf("%s User: %s\n", timestamp, *parentSession.UserPrompt))
 	}
 
 	// Add all child sessions in order
 	for _, child := range children {
-		timestamp := child.CreatedAt.Format("Jan 2, 3:04 PM")
+		timestamp := s.userTime(userID, child.CreatedAt).Format("Jan 2, 3:04 PM")
 		
 		// Add AI response (if exists)
 		if child.AIResponse != nil {
@@ -1988,6 +2596,20 @@ func (s *Service) logErrorWithTrace(ctx context.Context, errCtx *logging.ErrorCo
 	return fmt.Sprintf("❌ %s: %v", message, err)
 }
 
+// formatQuotaError explains why an attachment was rejected by a storage limit
+func formatQuotaError(filename string, err *files.QuotaError) string {
+	switch err.Scope {
+	case "file":
+		return fmt.Sprintf("❌ **File too large:** `%s` is %s; the limit is %s.", filename, files.FormatBytes(err.FileSize), files.FormatBytes(err.Limit))
+	case "user":
+		return fmt.Sprintf("❌ **Storage quota exceeded:** `%s` (%s) won't fit. You're using %s of your %s; older uploads are removed automatically, so try again later.",
+			filename, files.FormatBytes(err.FileSize), files.FormatBytes(err.Used), files.FormatBytes(err.Limit))
+	default:
+		return fmt.Sprintf("❌ **Bot storage is full:** `%s` (%s) can't be stored right now (%s of %s used). Try again later or ask an admin.",
+			filename, files.FormatBytes(err.FileSize), files.FormatBytes(err.Used), files.FormatBytes(err.Limit))
+	}
+}
+
 // IsImageMimeType checks if the given mime type is a supported image format
 func (s *Service) IsImageMimeType(mimeType string) bool {
 	supportedTypes := []string{
@@ -2005,70 +2627,6 @@ func (s *Service) IsImageMimeType(mimeType string) bool {
 	return false
 }
 
-// handleDeleteCommand handles the /delete slash command
-func (s *Service) handleDeleteCommand(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Read body for signature verification
-	bodyBytes, err := io.ReadAll(r.Body)
-	if err != nil {
-		s.logger.Error("Failed to read delete command body", zap.Error(err))
-		http.Error(w, "Bad request", http.StatusBadRequest)
-		return
-	}
-
-	// Verify Slack signature (if configured)
-	if s.config.SlackSigningSecret != "" {
-		if !s.verifySlackSignature(r.Header, bodyBytes) {
-			s.logger.Warn("Invalid Slack signature for delete command")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-	}
-
-	// Parse form data
-	formData, err := url.ParseQuery(string(bodyBytes))
-	if err != nil {
-		s.logger.Error("Failed to parse delete command form data", zap.Error(err))
-		http.Error(w, "Bad request", http.StatusBadRequest)
-		return
-	}
-
-	text := formData.Get("text")
-	userID := formData.Get("user_id")
-	channelID := formData.Get("channel_id")
-
-	s.logger.Info("Received delete command",
-		zap.String("text", text),
-		zap.String("user_id", userID),
-		zap.String("channel_id", channelID))
-
-	// Authorize user
-	authCtx := &auth.AuthContext{
-		UserID:    userID,
-		ChannelID: channelID,
-		Command:   "/delete",
-		Timestamp: time.Now(),
-	}
-
-	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
-		response := fmt.Sprintf("❌ Authorization failed: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"text": response})
-		return
-	}
-
-	// Process delete command
-	response := s.handleDeleteSessionCommand(userID, channelID, text)
-
-	// Send response
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"text": response})
-}
-
 // handleDeleteSessionCommand processes the delete session command
 func (s *Service) handleDeleteSessionCommand(userID, channelID, text string) string {
 	args := strings.Fields(text)
@@ -2078,10 +2636,29 @@ func (s *Service) handleDeleteSessionCommand(userID, channelID, text string) str
 	}
 
 	sessionID := args[0]
+
+	// Capture the conversation first; a deleted session can't be looked up.
+	// Only sessions listable here get a recap, so another channel's
+	// conversation isn't posted in this one.
+	var recap *closingRecap
+	if visible, err := s.sessionVisibleIn(channelID, sessionID); err != nil {
+		s.logger.Warn("Failed to check session visibility", zap.String("session_id", sessionID), zap.Error(err))
+	} else if visible {
+		recap = s.prepareClosingRecap(userID, channelID, sessionID)
+	}
 	
-	// Try to delete the session
-	err := s.sessionManager.DeleteSession(sessionID)
+	// Move the session to the trash when supported, so it can be restored
+	var err error
+	trashMgr, canTrash := s.sessionManager.(session.SessionTrashManager)
+	if canTrash {
+		err = trashMgr.TrashSession(sessionID, userID)
+	} else {
+		err = s.sessionManager.DeleteSession(sessionID)
+	}
 	if err != nil {
+		if isSessionBusy(err) {
+			return fmt.Sprintf("⏳ **Session Busy**\n\nSession `%s` has a Claude run in progress. Wait for the reply or use `/stop` first, then delete it.", sessionID)
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return fmt.Sprintf("❌ **Session Not Found**\n\nSession `%s` does not exist or may have already been deleted.", sessionID)
 		}
@@ -2089,5 +2666,15 @@ func (s *Service) handleDeleteSessionCommand(userID, channelID, text string) str
 		return fmt.Sprintf("❌ **Delete Failed**\n\nFailed to delete session `%s`: %v", sessionID, err)
 	}
 
-	return fmt.Sprintf("✅ **Session Deleted**\n\nSession `%s` has been successfully deleted along with all its conversation history.", sessionID)
+	recapNote := ""
+	if recap != nil {
+		go s.postClosingRecap(userID, recap, "deleted")
+		recapNote = closingRecapNote(1)
+	}
+
+	if canTrash {
+		return fmt.Sprintf("🗑️ **Session Moved to Trash**\n\nSession `%s` was deleted. Restore it with `/session restore %s` within %s; after that it is purged with all its conversation history.",
+			sessionID, sessionID, formatRetention(s.config.SessionTrashRetention)) + recapNote
+	}
+	return fmt.Sprintf("✅ **Session Deleted**\n\nSession `%s` has been successfully deleted along with all its conversation history.", sessionID) + recapNote
 }
@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+const (
+	sessionDiffUsage = "**Usage:** `/session diff <session-id-a> <session-id-b>` - Compare two branches of the same conversation"
+
+	// branchExchangesShown is how many exchanges unique to a branch are listed
+	branchExchangesShown = 8
+
+	// branchReplyPreview is how much of a branch's final reply is shown
+	branchReplyPreview = 300
+)
+
+// branchDiff is how two exchanges of the same conversation tree diverge
+type branchDiff struct {
+	Common *repository.ChildSession   // Last exchange both branches share; nil if they split at the start
+	PathA  []*repository.ChildSession // Root to a
+	PathB  []*repository.ChildSession // Root to b
+	OnlyA  []*repository.ChildSession // Exchanges after Common leading to a, oldest first
+	OnlyB  []*repository.ChildSession
+}
+
+// branchPath returns the exchanges from the start of the conversation to
+// leaf, following previous_session_id links within tree
+func branchPath(tree []*repository.ChildSession, leaf *repository.ChildSession) ([]*repository.ChildSession, error) {
+	bySessionID := make(map[string]*repository.ChildSession, len(tree))
+	for _, child := range tree {
+		bySessionID[child.SessionID] = child
+	}
+
+	var path []*repository.ChildSession
+	for current := leaf; current != nil; {
+		if len(path) > len(tree) {
+			return nil, fmt.Errorf("conversation tree has a cycle at %s", current.SessionID)
+		}
+		path = append(path, current)
+		if current.PreviousSessionID == nil {
+			break
+		}
+		current = bySessionID[*current.PreviousSessionID]
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+// diffBranches compares the branches ending at a and b
+func diffBranches(tree []*repository.ChildSession, a, b *repository.ChildSession) (*branchDiff, error) {
+	pathA, err := branchPath(tree, a)
+	if err != nil {
+		return nil, err
+	}
+	pathB, err := branchPath(tree, b)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &branchDiff{PathA: pathA, PathB: pathB}
+	shared := 0
+	for shared < len(pathA) && shared < len(pathB) && pathA[shared].ID == pathB[shared].ID {
+		shared++
+	}
+	if shared > 0 {
+		diff.Common = pathA[shared-1]
+	}
+	diff.OnlyA = pathA[shared:]
+	diff.OnlyB = pathB[shared:]
+	return diff, nil
+}
+
+// handleSessionDiffCommand summarizes how two branches of a conversation
+// diverge, to help pick which one to continue
+func (s *Service) handleSessionDiffCommand(userID, channelID string, args []string) string {
+	if len(args) != 2 {
+		return "❌ **Invalid arguments**\n\n" + sessionDiffUsage
+	}
+	if args[0] == args[1] {
+		return "ℹ️ Both IDs are the same exchange, so there's nothing to compare"
+	}
+
+	manager, ok := s.sessionManager.(session.SessionBranchManager)
+	if !ok {
+		return "❌ **Comparing branches requires database persistence**"
+	}
+
+	var leaves [2]*repository.ChildSession
+	for i, id := range args {
+		child, err := manager.GetChildSessionBySessionID(id)
+		if err != nil {
+			errCtx := logging.CreateErrorContext(channelID, userID, "session_diff", "get_exchange")
+			return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to look up exchange")
+		}
+		if child == nil {
+			return fmt.Sprintf("❌ **Exchange not found:** `%s`\n\nUse the session IDs of exchanges, shown by `/session info <parent-session-id>`.", id)
+		}
+		leaves[i] = child
+	}
+	if leaves[0].RootParentID != leaves[1].RootParentID {
+		return "❌ **Different conversations**\n\nThese exchanges don't belong to the same parent session, so they aren't branches of one conversation."
+	}
+
+	root, err := manager.LoadSessionByID(leaves[0].RootParentID)
+	if err != nil || root == nil {
+		if err == nil {
+			err = fmt.Errorf("parent session %d not found", leaves[0].RootParentID)
+		}
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_diff", "get_parent_session")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to get parent session")
+	}
+
+	// Only conversations /session list shows here may be compared here, so
+	// another channel's prompts and summaries aren't posted in this one
+	visible, err := s.sessionVisibleIn(channelID, root.SessionID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_diff", "check_visibility")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to check session visibility")
+	}
+	if !visible {
+		return fmt.Sprintf("❌ **Exchange not found:** `%s`\n\nUse the session IDs of exchanges, shown by `/session info <parent-session-id>`.", args[0])
+	}
+	tree, err := manager.LoadConversationTree(root.ID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_diff", "get_conversation_tree")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to get conversation tree")
+	}
+	diff, err := diffBranches(tree, leaves[0], leaves[1])
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_diff", "diff_branches")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to compare branches")
+	}
+
+	response := s.formatBranchDiff(userID, root.SessionID, diff)
+
+	// Final states without a stored summary get one from a quick recap run
+	var missing [][]*repository.ChildSession
+	for _, path := range [][]*repository.ChildSession{diff.PathA, diff.PathB} {
+		if leaf := path[len(path)-1]; leaf.Summary == nil || strings.TrimSpace(*leaf.Summary) == "" {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) > 0 {
+		response += "\n\n_Summarizing where each branch ended up…_"
+		go s.postBranchSummaries(userID, channelID, root.SessionID, missing)
+	}
+	return response
+}
+
+// formatBranchDiff renders a branch comparison for Slack
+func (s *Service) formatBranchDiff(userID, rootSessionID string, diff *branchDiff) string {
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🔀 **Branch Comparison** in `%s`\n\n", rootSessionID))
+	if diff.Common == nil {
+		response.WriteString("**Diverged:** at the start of the conversation\n")
+	} else {
+		response.WriteString(fmt.Sprintf("**Diverged after:** `%s` %s\n", diff.Common.SessionID, exchangePreview(diff.Common.Summary, diff.Common.UserPrompt)))
+	}
+
+	for _, branch := range []struct {
+		path []*repository.ChildSession
+		only []*repository.ChildSession
+	}{{diff.PathA, diff.OnlyA}, {diff.PathB, diff.OnlyB}} {
+		leaf := branch.path[len(branch.path)-1]
+		response.WriteString(fmt.Sprintf("\n**Branch `%s`** — %d exchange(s) of its own\n", leaf.SessionID, len(branch.only)))
+		if len(branch.only) == 0 {
+			response.WriteString("• _An earlier point on the other branch_\n")
+		}
+		for i, exchange := range branch.only {
+			if i == branchExchangesShown {
+				response.WriteString(fmt.Sprintf("• _... and %d more_\n", len(branch.only)-i))
+				break
+			}
+			response.WriteString(fmt.Sprintf("• %s %s\n", s.userTime(userID, exchange.CreatedAt).Format("Jan 2 15:04"), exchangePreview(exchange.Summary, exchange.UserPrompt)))
+		}
+
+		if leaf.Summary != nil && strings.TrimSpace(*leaf.Summary) != "" {
+			response.WriteString(fmt.Sprintf("_Where it ended up:_ %s\n", s.formatSummaryForSlack(*leaf.Summary)))
+		} else if leaf.AIResponse != nil {
+			reply := strings.Join(strings.Fields(*leaf.AIResponse), " ")
+			if runes := []rune(reply); len(runes) > branchReplyPreview {
+				reply = string(runes[:branchReplyPreview]) + "…"
+			}
+			response.WriteString(fmt.Sprintf("_Last reply:_ %s\n", reply))
+		}
+	}
+
+	response.WriteString("\nContinue a branch by switching to it with `/session <session-id>`.")
+	return response.String()
+}
+
+// postBranchSummaries recaps each branch and posts where it ended up. The
+// recaps are stored with the branch's last exchange, so they're reused.
+func (s *Service) postBranchSummaries(userID, channelID, rootSessionID string, paths [][]*repository.ChildSession) {
+	var response strings.Builder
+	response.WriteString("🔀 **Where each branch ended up**\n")
+	for _, path := range paths {
+		leaf := path[len(path)-1]
+		recap, err := s.sessionRecap(userID, rootSessionID, path)
+		if err != nil {
+			errCtx := logging.CreateErrorContext(channelID, userID, "session_diff", "summarize_branch")
+			errCtx.WithSession(leaf.SessionID)
+			s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to summarize branch")
+			return
+		}
+		response.WriteString(fmt.Sprintf("\n**Branch `%s`**\n%s\n", leaf.SessionID, s.formatSummaryForSlack(recap)))
+	}
+	s.sendResponse(channelID, strings.TrimRight(response.String(), "\n"))
+}
@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+// testTree builds a conversation where a -> b -> c and b -> d, plus e
+// started over from the root
+func testTree() map[string]*repository.ChildSession {
+	link := func(id int, sessionID, previous string) *repository.ChildSession {
+		child := &repository.ChildSession{ID: id, SessionID: sessionID, RootParentID: 1}
+		if previous != "" {
+			child.PreviousSessionID = &previous
+		}
+		return child
+	}
+	return map[string]*repository.ChildSession{
+		"a": link(1, "a", ""),
+		"b": link(2, "b", "a"),
+		"c": link(3, "c", "b"),
+		"d": link(4, "d", "b"),
+		"e": link(5, "e", ""),
+	}
+}
+
+func sessionIDs(children []*repository.ChildSession) string {
+	ids := ""
+	for _, child := range children {
+		ids += child.SessionID
+	}
+	return ids
+}
+
+func TestDiffBranches(t *testing.T) {
+	byID := testTree()
+	tree := []*repository.ChildSession{byID["a"], byID["b"], byID["c"], byID["d"], byID["e"]}
+
+	tests := []struct {
+		a, b, common, onlyA, onlyB string
+	}{
+		{"c", "d", "b", "c", "d"},
+		{"c", "e", "", "abc", "e"},
+		{"b", "c", "b", "", "c"},
+	}
+
+	for _, tt := range tests {
+		diff, err := diffBranches(tree, byID[tt.a], byID[tt.b])
+		if err != nil {
+			t.Fatalf("diffBranches(%s, %s) failed: %v", tt.a, tt.b, err)
+		}
+		common := ""
+		if diff.Common != nil {
+			common = diff.Common.SessionID
+		}
+		if common != tt.common || sessionIDs(diff.OnlyA) != tt.onlyA || sessionIDs(diff.OnlyB) != tt.onlyB {
+			t.Errorf("diffBranches(%s, %s) = common %q, only a %q, only b %q; want %q, %q, %q",
+				tt.a, tt.b, common, sessionIDs(diff.OnlyA), sessionIDs(diff.OnlyB), tt.common, tt.onlyA, tt.onlyB)
+		}
+	}
+}
+
+func TestBranchPath_Cycle(t *testing.T) {
+	byID := testTree()
+	loop := "c"
+	byID["a"].PreviousSessionID = &loop
+	tree := []*repository.ChildSession{byID["a"], byID["b"], byID["c"]}
+
+	if _, err := branchPath(tree, byID["c"]); err == nil {
+		t.Error("Expected an error for a cyclic conversation tree")
+	}
+}
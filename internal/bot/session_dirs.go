@@ -0,0 +1,145 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+// maxSessionDirs caps the extra directories one session may have
+const maxSessionDirs = 10
+
+const sessionDirsUsage = "❌ **Usage:**\n• `/session dirs` - List the extra directories Claude can access in this session\n• `/session adddir <path>` - Let Claude access another directory, e.g. a second repository\n• `/session rmdir <path>` - Remove an extra directory"
+
+// resolveSessionDir turns a path given to /session adddir into the absolute,
+// cleaned directory to store. Relative paths are taken from workDir.
+func resolveSessionDir(path, workDir string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+	path = filepath.Clean(path)
+	if err := validateWorkingDir(path); err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(filepath.Clean(workDir), path)
+	if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("`%s` is already inside the working directory `%s`", path, workDir)
+	}
+	return path, nil
+}
+
+// handleSessionDirCommand lists, adds, or removes the extra directories of
+// the user's current session
+func (s *Service) handleSessionDirCommand(userID, channelID, subcommand string, args []string) string {
+	if (subcommand == "dirs" && len(args) != 0) || (subcommand != "dirs" && len(args) != 1) {
+		return sessionDirsUsage
+	}
+
+	userSession, err := s.sessionManager.GetOrCreateSession(userID, channelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_dirs_command", "get_session")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to get session")
+	}
+	sessionID := userSession.GetID()
+	workDir := userSession.GetCurrentWorkDir()
+
+	if subcommand == "dirs" {
+		dirs, err := s.sessionDirs.ListSessionDirs(sessionID)
+		if err != nil {
+			errCtx := logging.CreateErrorContext(channelID, userID, "session_dirs_command", "list_dirs")
+			return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to list session directories")
+		}
+		if len(dirs) == 0 {
+			return fmt.Sprintf("📁 **Session Directories**\n\nClaude works in `%s` only. Add another directory with `/session adddir <path>`.", workDir)
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "📁 **Session Directories**\n\n• `%s` _(working directory)_", workDir)
+		for _, dir := range dirs {
+			fmt.Fprintf(&b, "\n• `%s`", dir)
+		}
+		return b.String()
+	}
+
+	// Widening what Claude can touch needs the same permission as changing
+	// the channel's session
+	authCtx := &auth.AuthContext{UserID: userID, ChannelID: channelID, Command: "/session", Timestamp: time.Now()}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+		return fmt.Sprintf("❌ Authorization failed: %v", err)
+	}
+
+	if subcommand == "rmdir" {
+		path := filepath.Clean(args[0])
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workDir, path)
+		}
+		removed, err := s.sessionDirs.RemoveSessionDir(sessionID, path)
+		if err != nil {
+			errCtx := logging.CreateErrorContext(channelID, userID, "session_dirs_command", "remove_dir")
+			return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to remove session directory")
+		}
+		if !removed {
+			return fmt.Sprintf("❌ `%s` isn't one of this session's directories. See them with `/session dirs`.", path)
+		}
+		s.logger.Info("Session directory removed",
+			zap.String("session_id", sessionID),
+			zap.String("user_id", userID),
+			zap.String("path", path))
+		return fmt.Sprintf("✅ **Directory Removed**\n\nClaude's next run in this session can no longer access `%s`.", path)
+	}
+
+	path, err := resolveSessionDir(args[0], workDir)
+	if err != nil {
+		return fmt.Sprintf("❌ **Invalid directory:** %v", err)
+	}
+	dirs, err := s.sessionDirs.ListSessionDirs(sessionID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_dirs_command", "list_dirs")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to list session directories")
+	}
+	if len(dirs) >= maxSessionDirs {
+		return fmt.Sprintf("❌ **Too many directories**\n\nA session can have at most %d extra directories. Remove one with `/session rmdir <path>` first.", maxSessionDirs)
+	}
+
+	found, err := s.sessionDirs.AddSessionDir(sessionID, path, userID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_dirs_command", "add_dir")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to add session directory")
+	}
+	if !found {
+		return "❌ **No session yet**\n\nSend Claude a message first, then add directories to the session it starts."
+	}
+	return fmt.Sprintf("✅ **Directory Added**\n\nClaude can now read and edit `%s` alongside `%s` in this session. Mention it in your prompt so Claude knows to look there.", path, workDir)
+}
+
+// runAddDirs returns a session's extra directories for a run, leaving out any
+// that have since been removed from disk
+func (s *Service) runAddDirs(sessionID string) []string {
+	dirs, err := s.sessionDirs.ListSessionDirs(sessionID)
+	if err != nil {
+		s.logger.Warn("Failed to load session directories; running without them",
+			zap.String("session_id", sessionID),
+			zap.Error(err))
+		return nil
+	}
+
+	var existing []string
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			existing = append(existing, dir)
+		} else {
+			s.logger.Warn("Skipping missing session directory",
+				zap.String("session_id", sessionID),
+				zap.String("path", dir))
+		}
+	}
+	return existing
+}
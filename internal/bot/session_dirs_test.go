@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveSessionDir(t *testing.T) {
+	root := t.TempDir()
+	workDir := filepath.Join(root, "app")
+	other := filepath.Join(root, "lib")
+	for _, dir := range []string{workDir, other, filepath.Join(workDir, "sub")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr string
+	}{
+		{path: other, want: other},
+		{path: other + "/", want: other},
+		{path: "../lib", want: other},
+		{path: root, want: root},
+		{path: filepath.Join(root, "missing"), wantErr: "does not exist"},
+		{path: workDir, wantErr: "already inside"},
+		{path: "sub", wantErr: "already inside"},
+	}
+	for _, tt := range tests {
+		got, err := resolveSessionDir(tt.path, workDir)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolveSessionDir(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("resolveSessionDir(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+const sessionModeUsage = "**Usage:** `/session mode shared|per-user`"
+
+// handleSessionModeCommand shows or sets whether a channel shares one session
+// or gives each user their own
+func (s *Service) handleSessionModeCommand(userID, channelID string, args []string) string {
+	manager, ok := s.sessionManager.(session.ChannelSessionModeManager)
+	if !ok {
+		return "❌ **Per-user sessions require database persistence**"
+	}
+
+	if len(args) == 0 {
+		mode, err := manager.GetChannelSessionMode(channelID)
+		if err != nil {
+			errCtx := logging.CreateErrorContext(channelID, userID, "session_mode_command", "get_session_mode")
+			return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to get session mode")
+		}
+		return fmt.Sprintf("👥 **Session Mode:** `%s`\n\n• `shared` - Everyone in the channel continues the same conversation\n• `per-user` - Each user has their own active session here\n\n%s", mode, sessionModeUsage)
+	}
+
+	mode := session.SessionMode(args[0])
+	if mode != session.SessionModeShared && mode != session.SessionModePerUser {
+		return "❌ **Invalid session mode**\n\n" + sessionModeUsage
+	}
+
+	authCtx := &auth.AuthContext{UserID: userID, ChannelID: channelID, Command: "/session", Timestamp: time.Now()}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+		return fmt.Sprintf("❌ Authorization failed: %v", err)
+	}
+
+	if err := manager.SetChannelSessionMode(channelID, mode); err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_mode_command", "set_session_mode")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to update session mode")
+	}
+
+	s.logger.Info("Channel session mode updated",
+		zap.String("channel_id", channelID),
+		zap.String("user_id", userID),
+		zap.String("mode", string(mode)))
+
+	if mode == session.SessionModePerUser {
+		return "✅ **Session Mode Updated:** `per-user`\n\nEach user now has their own session in this channel. Your next message starts a fresh conversation unless you switch with `/session <session-id>`."
+	}
+	return "✅ **Session Mode Updated:** `shared`\n\nEveryone in this channel now continues the channel's shared session."
+}
+
+// switchSession switches the session the user talks to in a channel, which
+// is the channel's shared session unless the channel is in per-user mode
+func (s *Service) switchSession(channelID, userID, sessionID string) error {
+	if manager, ok := s.sessionManager.(session.ChannelSessionModeManager); ok {
+		return manager.SwitchToSessionForUser(channelID, userID, sessionID)
+	}
+	return s.sessionManager.SwitchToSessionInChannel(channelID, sessionID)
+}
+
+// isSessionBusy reports whether err means a run is still in progress on a
+// session the operation would delete or switch away from
+func isSessionBusy(err error) bool {
+	return errors.Is(err, session.ErrSessionBusy)
+}
+
+// sessionSwitchBusyMessage is shown when a switch is refused because of an in-flight run
+const sessionSwitchBusyMessage = "⏳ **Session Busy**\n\nClaude is still working in this channel's session or the one you picked. Wait for the reply or use `/stop` first, then switch again."
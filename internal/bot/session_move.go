@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+const sessionMoveUsage = "**Usage:** `/session move #channel`"
+
+// channelArgPattern matches a channel mention like <#C123|incidents>, or a
+// bare public or private channel ID
+var channelArgPattern = regexp.MustCompile(`^(?:<#([CG][A-Z0-9]+)(?:\|[^>]*)?>|([CG][A-Z0-9]+))$`)
+
+// parseChannelArg returns the channel ID a /session move argument names
+func parseChannelArg(arg string) (string, bool) {
+	match := channelArgPattern.FindStringSubmatch(arg)
+	if match == nil {
+		return "", false
+	}
+	if match[1] != "" {
+		return match[1], true
+	}
+	return match[2], true
+}
+
+// handleSessionMoveCommand hands the channel's active session over to
+// another channel, so a conversation started in a DM can continue where the
+// rest of the team is. A notice is posted in the target channel; the reply
+// is the notice for this one.
+func (s *Service) handleSessionMoveCommand(userID, channelID string, args []string) string {
+	if len(args) != 1 {
+		return "❌ **Invalid arguments**\n\n" + sessionMoveUsage
+	}
+	targetID, ok := parseChannelArg(args[0])
+	if !ok {
+		return fmt.Sprintf("❌ **Invalid channel:** `%s`\n\nMention the channel, like `#incidents`.\n\n%s", args[0], sessionMoveUsage)
+	}
+	if targetID == channelID {
+		return "❌ The session is already in this channel."
+	}
+
+	mover, ok := s.sessionManager.(session.ChannelSessionMover)
+	if !ok {
+		return "❌ **Moving sessions requires database persistence**"
+	}
+
+	// Moving takes the session from this channel and changes the target's,
+	// so it needs write permission in both
+	for _, ch := range []string{channelID, targetID} {
+		authCtx := &auth.AuthContext{UserID: userID, ChannelID: ch, Command: "/session", Timestamp: time.Now()}
+		if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+			return fmt.Sprintf("❌ Authorization failed in <#%s>: %v", ch, err)
+		}
+	}
+
+	for _, member := range []struct{ userID, missing string }{
+		{s.botUserID, "The bot isn't in <#%s>. Invite it there first."},
+		{userID, "You aren't in <#%s>, so you can't move a session there."},
+	} {
+		isMember, err := s.isChannelMember(member.userID, targetID)
+		if err != nil {
+			errCtx := logging.CreateErrorContext(channelID, userID, "session_move", "check_membership")
+			return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to check the target channel's members")
+		}
+		if !isMember {
+			return "❌ " + fmt.Sprintf(member.missing, targetID)
+		}
+	}
+
+	moved, err := mover.MoveActiveSession(channelID, targetID, userID)
+	switch {
+	case errors.Is(err, session.ErrSessionNotFound):
+		return "❌ There is no active session here to move. Start one by sending a message."
+	case errors.Is(err, session.ErrChannelArchived):
+		return fmt.Sprintf("❌ <#%s> is archived, so the session can't be moved there.", targetID)
+	case isSessionBusy(err):
+		return "⏳ **Session Busy**\n\nClaude is still working in this channel's session or the target channel's. Wait for the reply or use `/stop` first, then move it again."
+	case err != nil:
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_move", "move_session")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to move session")
+	}
+
+	s.logger.Info("Session handed off",
+		zap.String("session_id", moved.GetID()),
+		zap.String("from_channel_id", channelID),
+		zap.String("to_channel_id", targetID),
+		zap.String("user_id", userID))
+
+	origin := fmt.Sprintf("<#%s>", channelID)
+	if strings.HasPrefix(channelID, "D") {
+		origin = "a direct message"
+	}
+	s.sendResponse(targetID, fmt.Sprintf("🔀 **Session handed off from %s** by <@%s>\n\n• Session: `%s`\n• Working Dir: `%s`\n\nThe next message here continues the conversation with its context intact, in this channel's permission mode and settings.",
+		origin, userID, moved.GetID(), moved.GetWorkspaceDir()))
+
+	return fmt.Sprintf("🔀 **Session moved to <#%s>**\n\n`%s` continues there. The next message here starts a new session; switch back with `/session %s`.",
+		targetID, shortSessionID(moved.GetID()), moved.GetID())
+}
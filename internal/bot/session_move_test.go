@@ -0,0 +1,24 @@
+package bot
+
+import "testing"
+
+func TestParseChannelArg(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+		ok   bool
+	}{
+		{"<#C01INC|incidents>", "C01INC", true},
+		{"<#G01PRIV>", "G01PRIV", true},
+		{"C01INC", "C01INC", true},
+		{"#incidents", "", false},
+		{"<#C01INC|incidents> extra", "", false},
+		{"D01DM", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseChannelArg(tt.arg)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseChannelArg(%q) = %q, %v; want %q, %v", tt.arg, got, ok, tt.want, tt.ok)
+		}
+	}
+}
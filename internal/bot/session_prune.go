@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+const (
+	// sessionPruneInterval is how often old exchanges are collapsed
+	sessionPruneInterval = time.Hour
+
+	// sessionPruneRunsPerSweep caps the checkpoints made per sweep, since
+	// each needs a Claude summary; a backlog is worked off over several sweeps
+	sessionPruneRunsPerSweep = 10
+)
+
+// sessionPruneLoop collapses exchanges older than SESSION_PRUNE_AFTER into
+// summarized checkpoints, until stopped
+func (s *Service) sessionPruneLoop() {
+	if s.config.SessionPruneAfter <= 0 {
+		return
+	}
+	pruneMgr, ok := s.sessionManager.(session.SessionPruneManager)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(sessionPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.pruneSessions(pruneMgr)
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// pruneSessions makes one sweep of checkpoints. A run whose summary fails is
+// left as it is for the next sweep.
+func (s *Service) pruneSessions(pruneMgr session.SessionPruneManager) {
+	cutoff := time.Now().Add(-s.config.SessionPruneAfter)
+	runs, err := pruneMgr.FindPruneRuns(cutoff, s.config.SessionPruneKeep, s.config.SessionPruneChunk, sessionPruneRunsPerSweep)
+	if err != nil {
+		s.logger.Error("Failed to find exchanges to prune", zap.Error(err))
+		return
+	}
+
+	collapsed := 0
+	for _, run := range runs {
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+
+		summary, err := s.claudeExecutor.ExecuteClaudeSummary(context.Background(), formatPruneRun(run.Children))
+		if err != nil {
+			s.logger.Warn("Failed to summarize exchanges to prune",
+				zap.String("session_id", run.SessionID),
+				zap.Error(err))
+			continue
+		}
+
+		err = pruneMgr.CollapsePruneRun(run, formatCheckpoint(run.Children, summary), summary)
+		switch {
+		case errors.Is(err, session.ErrSessionBusy), errors.Is(err, sql.ErrNoRows):
+			s.logger.Debug("Skipped pruning a session that is in use or changed",
+				zap.String("session_id", run.SessionID))
+		case err != nil:
+			s.logger.Error("Failed to collapse exchanges into a checkpoint",
+				zap.String("session_id", run.SessionID),
+				zap.Error(err))
+		default:
+			collapsed++
+		}
+	}
+
+	if collapsed > 0 {
+		s.logger.Info("Collapsed old exchanges into checkpoints",
+			zap.Int("checkpoints", collapsed),
+			zap.Int("exchanges_per_checkpoint", s.config.SessionPruneChunk))
+	}
+}
+
+// formatPruneRun renders a run of exchanges for summarizing, in the order
+// they are stored: each response, then the prompt that followed it
+func formatPruneRun(children []*repository.ChildSession) string {
+	var text strings.Builder
+	for _, child := range children {
+		timestamp := child.CreatedAt.UTC().Format("Jan 2, 3:04 PM")
+		if child.AIResponse != nil {
+			fmt.Fprintf(&text, "%s AI: %s\n", timestamp, *child.AIResponse)
+		}
+		if child.UserPrompt != nil {
+			fmt.Fprintf(&text, "%s User: %s\n", timestamp, *child.UserPrompt)
+		}
+	}
+
+	// Keep the latest part, which matters most for continuing
+	if out := text.String(); len(out) > recapMaxInput {
+		return out[len(out)-recapMaxInput:]
+	}
+	return text.String()
+}
+
+// formatCheckpoint is the response a checkpoint row shows in place of the
+// exchanges it replaced
+func formatCheckpoint(children []*repository.ChildSession, summary string) string {
+	first := children[0].CreatedAt.UTC().Format("Jan 2, 2006")
+	last := children[len(children)-1].CreatedAt.UTC().Format("Jan 2, 2006")
+	period := first
+	if last != first {
+		period = first + " – " + last
+	}
+	return fmt.Sprintf("📦 Checkpoint of %d earlier exchanges (%s):\n\n%s", len(children), period, strings.TrimSpace(summary))
+}
@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func TestFormatPruneRun(t *testing.T) {
+	response, prompt := "Added the handler", "Now add tests"
+	created := time.Date(2026, 3, 4, 15, 4, 0, 0, time.UTC)
+	got := formatPruneRun([]*repository.ChildSession{
+		{AIResponse: &response, UserPrompt: &prompt, CreatedAt: created},
+		{AIResponse: &response, CreatedAt: created},
+	})
+
+	want := "Mar 4, 3:04 PM AI: Added the handler\nMar 4, 3:04 PM User: Now add tests\nMar 4, 3:04 PM AI: Added the handler\n"
+	if got != want {
+		t.Errorf("formatPruneRun() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatPruneRun_KeepsTheLatestPart(t *testing.T) {
+	early, late := strings.Repeat("a", recapMaxInput), "the latest response"
+	got := formatPruneRun([]*repository.ChildSession{{AIResponse: &early}, {AIResponse: &late}})
+	if len(got) != recapMaxInput || !strings.HasSuffix(got, late+"\n") {
+		t.Errorf("Expected the input capped to its latest %d bytes, got %d ending %q", recapMaxInput, len(got), got[len(got)-30:])
+	}
+}
+
+func TestFormatCheckpoint(t *testing.T) {
+	march := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	children := []*repository.ChildSession{{CreatedAt: march}, {CreatedAt: march.Add(time.Hour)}, {CreatedAt: march.AddDate(0, 1, 0)}}
+
+	got := formatCheckpoint(children, "  Set up the service.\n")
+	if want := "📦 Checkpoint of 3 earlier exchanges (Mar 4, 2026 – Apr 4, 2026):\n\nSet up the service."; got != want {
+		t.Errorf("formatCheckpoint() = %q, want %q", got, want)
+	}
+	if got := formatCheckpoint(children[:2], "Done"); !strings.Contains(got, "(Mar 4, 2026)") {
+		t.Errorf("Expected a single day for exchanges on the same day, got %q", got)
+	}
+}
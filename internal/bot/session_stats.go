@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+// handleSessionStatsCommand shows what the channel's active session has cost
+// and done so far
+func (s *Service) handleSessionStatsCommand(userID, channelID string, args []string) string {
+	if len(args) != 0 {
+		return "❌ **Invalid arguments**\n\n**Usage:** `/session stats` - Totals for the active session"
+	}
+
+	userSession, err := s.sessionManager.GetOrCreateSession(userID, channelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_stats", "get_session")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to get session")
+	}
+	stats, err := s.usage.GetSessionStats(userSession.GetID())
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_stats", "get_stats")
+		errCtx.WithSession(userSession.GetID())
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to get session stats")
+	}
+	if stats == nil {
+		return "ℹ️ The active session hasn't been saved yet. Send a message to start it."
+	}
+
+	created := s.userTime(userID, stats.CreatedAt).Format("Jan 2, 2006 15:04")
+	return formatSessionStats(userSession.GetID(), s.sessionTitle(userSession.GetID()), created, stats, time.Since(stats.CreatedAt))
+}
+
+// formatSessionAge describes how long ago a session started, in days once
+// it's more than two days old, e.g. "5 days" or "3h20m"
+func formatSessionAge(age time.Duration) string {
+	if age >= 48*time.Hour {
+		return fmt.Sprintf("%d days", int(age/(24*time.Hour)))
+	}
+	return formatRemaining(age)
+}
+
+// formatSessionStats renders /session stats for a session created age ago
+func formatSessionStats(sessionID, title, created string, stats *repository.SessionStats, age time.Duration) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "📊 **Session Stats** - `%s`", sessionID)
+	if title != "" {
+		fmt.Fprintf(&b, " _%s_", title)
+	}
+	fmt.Fprintf(&b, "\n\n• Created: %s (%s ago)\n", created, formatSessionAge(age))
+	fmt.Fprintf(&b, "• Exchanges: %d", stats.Exchanges)
+	if stats.Branches > 1 {
+		fmt.Fprintf(&b, " across %d branches", stats.Branches)
+	}
+	b.WriteString("\n")
+
+	if stats.Runs == 0 {
+		b.WriteString("• Runs: none recorded yet")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "• Runs: %d, including `/ask` and `/batch` runs\n", stats.Runs)
+	fmt.Fprintf(&b, "• Cost: $%.4f (average $%.4f a run)\n", stats.CostUSD, stats.CostUSD/float64(stats.Runs))
+
+	b.WriteString("• Tokens: ")
+	switch {
+	case stats.TokenRuns == 0:
+		b.WriteString("unknown; runs before token tracking weren't counted\n")
+	case stats.TokenRuns < stats.Runs:
+		fmt.Fprintf(&b, "%s over %d of %d runs; earlier runs weren't counted\n", formatTokens(stats.TotalTokens), stats.TokenRuns, stats.Runs)
+	default:
+		fmt.Fprintf(&b, "%s\n", formatTokens(stats.TotalTokens))
+	}
+
+	if stats.AvgDuration > 0 {
+		fmt.Fprintf(&b, "• Average run: %s\n", formatElapsed(stats.AvgDuration))
+	}
+
+	b.WriteString("• Files touched: ")
+	switch {
+	case stats.FileRuns == 0:
+		b.WriteString("unknown; runs before file tracking weren't counted")
+	case stats.FileRuns < stats.Runs:
+		fmt.Fprintf(&b, "%d over %d of %d runs; earlier runs weren't counted", stats.FilesEdited, stats.FileRuns, stats.Runs)
+	default:
+		fmt.Fprintf(&b, "%d", stats.FilesEdited)
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func TestFormatSessionAge(t *testing.T) {
+	for age, want := range map[time.Duration]string{
+		30 * time.Second:             "<1m",
+		3*time.Hour + 20*time.Minute: "3h20m",
+		47 * time.Hour:               "47h",
+		5*24*time.Hour + time.Hour:   "5 days",
+	} {
+		if got := formatSessionAge(age); got != want {
+			t.Errorf("formatSessionAge(%s) = %q, want %q", age, got, want)
+		}
+	}
+}
+
+func TestFormatSessionStats(t *testing.T) {
+	stats := &repository.SessionStats{
+		Exchanges:   12,
+		Branches:    3,
+		Runs:        14,
+		CostUSD:     1.4,
+		TotalTokens: 1_250_000,
+		TokenRuns:   14,
+		AvgDuration: 83 * time.Second,
+		FilesEdited: 7,
+		FileRuns:    14,
+	}
+	got := formatSessionStats("abc-123", "Fix login", "Oct 14, 2026 09:00", stats, 3*24*time.Hour)
+
+	for _, want := range []string{
+		"📊 **Session Stats** - `abc-123` _Fix login_",
+		"• Created: Oct 14, 2026 09:00 (3 days ago)",
+		"• Exchanges: 12 across 3 branches",
+		"• Runs: 14",
+		"• Cost: $1.4000 (average $0.1000 a run)",
+		"• Tokens: 1.2M\n",
+		"• Average run: 1m23s",
+		"• Files touched: 7",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatSessionStats() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatSessionStats_PartialTracking(t *testing.T) {
+	stats := &repository.SessionStats{Exchanges: 5, Branches: 1, Runs: 5, CostUSD: 0.5, TotalTokens: 40_000, TokenRuns: 2, FileRuns: 0}
+	got := formatSessionStats("abc-123", "", "Oct 14, 2026 09:00", stats, time.Hour)
+
+	if strings.Contains(got, "branches") || strings.Contains(got, "Average run") {
+		t.Errorf("Expected no branch count for one branch and no unknown average, got:\n%s", got)
+	}
+	for _, want := range []string{"• Tokens: 40k over 2 of 5 runs", "• Files touched: unknown"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatSessionStats() missing %q:\n%s", want, got)
+		}
+	}
+
+	empty := formatSessionStats("abc-123", "", "Oct 14, 2026 09:00", &repository.SessionStats{}, time.Minute)
+	if !strings.HasSuffix(empty, "• Runs: none recorded yet") {
+		t.Errorf("Expected a session without runs to say so, got:\n%s", empty)
+	}
+}
@@ -0,0 +1,176 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+const (
+	// sessionPickActionID is the select offered when a /session prefix
+	// matches several sessions
+	sessionPickActionID = "session_switch_pick"
+
+	// minSessionPrefixLength keeps very short prefixes from matching
+	// half the database; listings show 8 characters
+	minSessionPrefixLength = 4
+
+	// maxSessionPickerOptions bounds the disambiguation picker
+	maxSessionPickerOptions = 25
+)
+
+// sessionPrefixPattern matches what a session ID, or the start of one, can be
+var sessionPrefixPattern = regexp.MustCompile(`^[0-9a-fA-F-]+$`)
+
+// resolveSessionTarget finds the sessions /session <id> may mean: the
+// session with exactly that ID or, failing that, the sessions whose ID
+// starts with it. Either way only sessions listable in the channel match.
+func (s *Service) resolveSessionTarget(channelID, target string) ([]session.SessionInfo, error) {
+	exact, err := s.sessionManager.GetSessionBySessionID(target)
+	if err != nil {
+		return nil, err
+	}
+	if exact != nil {
+		visible, err := s.sessionVisibleIn(channelID, exact.SessionID)
+		if err != nil || !visible {
+			return nil, err
+		}
+		return []session.SessionInfo{&session.DbSessionInfo{Session: exact}}, nil
+	}
+
+	finder, ok := s.sessionManager.(session.SessionPrefixFinder)
+	if !ok || len(target) < minSessionPrefixLength || !sessionPrefixPattern.MatchString(target) {
+		return nil, nil
+	}
+	return finder.FindSessionsByIDPrefix(channelID, target, maxSessionPickerOptions+1)
+}
+
+// handleSessionSwitchCommand switches to the session /session <id> names,
+// asking which one was meant when a prefix matches several
+func (s *Service) handleSessionSwitchCommand(userID, channelID, target string) string {
+	matches, err := s.resolveSessionTarget(channelID, target)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_switch", "validate_session")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to validate session for switching")
+	}
+
+	switch len(matches) {
+	case 0:
+		if len(target) < minSessionPrefixLength {
+			return fmt.Sprintf("❌ **Session not found**\n\nSession `%s` does not exist. Use at least %d characters of a session ID, as shown by `/session list`.", target, minSessionPrefixLength)
+		}
+		return fmt.Sprintf("❌ **Session not found**\n\nNo session ID starts with `%s`. See `/session list` for the sessions you can switch to.", target)
+	case 1:
+		return s.switchToSession(userID, channelID, matches[0].GetID())
+	}
+
+	err = s.sender.PostEphemeral(context.Background(), channelID, userID,
+		slack.MsgOptionText(fmt.Sprintf("%d sessions start with %s", len(matches), target), false),
+		slack.MsgOptionBlocks(s.sessionPickerBlocks(userID, target, matches)...))
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_switch", "post_picker")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to offer matching sessions")
+	}
+	return ""
+}
+
+// sessionPickerBlocks asks which of several sessions matching a prefix to
+// switch to, newest first
+func (s *Service) sessionPickerBlocks(userID, prefix string, matches []session.SessionInfo) []slack.Block {
+	header := fmt.Sprintf("🔀 *%d sessions start with* `%s`. Which one do you want?", len(matches), prefix)
+	if len(matches) > maxSessionPickerOptions {
+		matches = matches[:maxSessionPickerOptions]
+		header = fmt.Sprintf("🔀 *More than %d sessions start with* `%s`. Pick one of the most recent, or type more of the ID.", maxSessionPickerOptions, prefix)
+	}
+
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.GetID())
+	}
+	titles := s.sessionTitles(ids)
+
+	options := make([]*slack.OptionBlockObject, 0, len(matches))
+	for _, match := range matches {
+		id := shortSessionID(match.GetID())
+		when := s.userTime(userID, match.GetLastActivity()).Format("Jan 2 15:04")
+		// Shorten the title or directory rather than the label so the ID
+		// stays visible: a title keeps its start, a directory its end
+		budget := maxOptionTextLength - utf8.RuneCountInString(id) - utf8.RuneCountInString(when) - 2*utf8.RuneCountInString(" · ")
+		name := truncateStart(match.GetWorkspaceDir(), budget)
+		if title := titles[match.GetID()]; title != "" {
+			name = truncateEnd(title, budget)
+		}
+		label := fmt.Sprintf("%s · %s · %s", id, name, when)
+		options = append(options, slack.NewOptionBlockObject(match.GetID(),
+			slack.NewTextBlockObject(slack.PlainTextType, label, false, false), nil))
+	}
+
+	selectElement := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Choose a session", false, false),
+		sessionPickActionID, options...)
+
+	return []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, header, false, false), nil, slack.NewAccessory(selectElement)),
+	}
+}
+
+// shortSessionID is the leading part of a session ID that listings show
+func shortSessionID(sessionID string) string {
+	if len(sessionID) > 8 {
+		return sessionID[:8]
+	}
+	return sessionID
+}
+
+// handleSessionPickAction switches to the session chosen in the picker
+func (s *Service) handleSessionPickAction(callback *slack.InteractionCallback, action *slack.BlockAction) {
+	userID := callback.User.ID
+	channelID := callback.Channel.ID
+	sessionID := strings.TrimSpace(action.SelectedOption.Value)
+	if sessionID == "" {
+		return
+	}
+
+	s.logger.Info("Session picked from prefix matches",
+		zap.String("channel_id", channelID),
+		zap.String("user_id", userID),
+		zap.String("session_id", sessionID))
+	s.postEphemeral(channelID, userID, s.switchToSession(userID, channelID, sessionID))
+}
+
+// switchToSession switches the user to a session that is known to exist and
+// posts a recap of where it left off. Only sessions /session list shows here
+// may be switched to, so another channel's conversation isn't recapped in
+// this one.
+func (s *Service) switchToSession(userID, channelID, sessionID string) string {
+	visible, err := s.sessionVisibleIn(channelID, sessionID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_switch", "check_visibility")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to check session visibility")
+	}
+	if !visible {
+		return fmt.Sprintf("❌ **Session not found**\n\nSession `%s` does not exist. See `/session list` for the sessions you can switch to.", sessionID)
+	}
+
+	err = s.switchSession(channelID, userID, sessionID)
+	if isSessionBusy(err) {
+		return sessionSwitchBusyMessage
+	}
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_switch", "update_channel")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to switch session")
+	}
+
+	// Recapping can take a while, so it's posted once ready
+	go s.sendSessionRecap(userID, channelID)
+
+	return fmt.Sprintf("✅ **Session Switched**\n\nNow using Claude session: `%s`\n\nNext message will resume this conversation. If it has earlier messages, a recap of where you left off will follow.", sessionID)
+}
@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+// switchTestManager looks sessions up by exact ID and by prefix. Hidden
+// sessions belong to another channel, such as a DM, and aren't visible here.
+type switchTestManager struct {
+	session.SessionManager
+	sessions  []*repository.Session
+	hidden    map[string]bool
+	current   string
+	switched  []string
+	treeLoads int
+}
+
+func (m *switchTestManager) SessionVisibleInChannel(sessionID, channelID string) (bool, error) {
+	return !m.hidden[sessionID], nil
+}
+
+func (m *switchTestManager) SwitchToSessionInChannel(channelID, sessionID string) error {
+	m.switched = append(m.switched, sessionID)
+	m.current = sessionID
+	return nil
+}
+
+func (m *switchTestManager) GetOrCreateSession(userID, channelID string) (session.SessionInfo, error) {
+	s, err := m.GetSessionBySessionID(m.current)
+	if err != nil || s == nil {
+		return nil, err
+	}
+	return &session.DbSessionInfo{Session: s}, nil
+}
+
+func (m *switchTestManager) GetConversationTree(sessionID string) ([]*repository.ChildSession, error) {
+	m.treeLoads++
+	return nil, nil
+}
+
+func (m *switchTestManager) GetSessionBySessionID(sessionID string) (*repository.Session, error) {
+	for _, s := range m.sessions {
+		if s.SessionID == sessionID {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *switchTestManager) FindSessionsByIDPrefix(channelID, prefix string, limit int) ([]session.SessionInfo, error) {
+	var matches []session.SessionInfo
+	for _, s := range m.sessions {
+		if m.hidden[s.SessionID] {
+			continue
+		}
+		if strings.HasPrefix(s.SessionID, strings.ToLower(prefix)) && len(matches) < limit {
+			matches = append(matches, &session.DbSessionInfo{Session: s})
+		}
+	}
+	return matches, nil
+}
+
+func newSwitchTestService(ids ...string) *Service {
+	manager := &switchTestManager{}
+	for _, id := range ids {
+		manager.sessions = append(manager.sessions, &repository.Session{SessionID: id, WorkingDirectory: "/srv/app", UpdatedAt: time.Now()})
+	}
+	cfg := &config.Config{}
+	return &Service{
+		config:         cfg,
+		logger:         zap.NewNop(),
+		authService:    auth.NewService(cfg, zap.NewNop()),
+		sessionManager: manager,
+	}
+}
+
+func TestResolveSessionTarget_AcceptsListedShortIDs(t *testing.T) {
+	ids := []string{"3f2a9c1e-7b4d-4e8a-9c1f-2d3e4f5a6b7c", "8d1e2f3a-4b5c-4d6e-8f7a-9b0c1d2e3f4a"}
+	s := newSwitchTestService(ids...)
+
+	for _, id := range ids {
+		// Listings show the first 8 characters; switching must accept them
+		matches, err := s.resolveSessionTarget("C123", shortSessionID(id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) != 1 || matches[0].GetID() != id {
+			t.Errorf("Expected %s to resolve to %s, got %d matches", shortSessionID(id), id, len(matches))
+		}
+
+		matches, _ = s.resolveSessionTarget("C123", id)
+		if len(matches) != 1 || matches[0].GetID() != id {
+			t.Errorf("Expected the full ID %s to resolve to itself", id)
+		}
+	}
+}
+
+func TestResolveSessionTarget_Ambiguous(t *testing.T) {
+	s := newSwitchTestService("abcd1111-0000", "abcd2222-0000", "ffff0000-0000")
+
+	matches, err := s.resolveSessionTarget("C123", "ABCD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Errorf("Expected both abcd sessions to match, got %d", len(matches))
+	}
+
+	for _, target := range []string{"abc", "abcd%", "abcd_", "zzzzzzzz"} {
+		if matches, _ := s.resolveSessionTarget("C123", target); len(matches) != 0 {
+			t.Errorf("Expected %q to match nothing, got %d matches", target, len(matches))
+		}
+	}
+}
+
+func TestResolveSessionTarget_HiddenSession(t *testing.T) {
+	const private = "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b"
+	s := newSwitchTestService(private)
+	s.sessionManager.(*switchTestManager).hidden = map[string]bool{private: true}
+
+	// The full ID finds no more than its prefix does
+	for _, target := range []string{private, shortSessionID(private)} {
+		if matches, err := s.resolveSessionTarget("CPUBLIC", target); err != nil || len(matches) != 0 {
+			t.Errorf("resolveSessionTarget(%q) = %d matches, %v; want none", target, len(matches), err)
+		}
+	}
+	if response := s.handleSessionSwitchCommand("U1", "CPUBLIC", private); !strings.Contains(response, "Session not found") {
+		t.Errorf("Expected a session from another channel to be reported as not found, got %q", response)
+	}
+}
+
+func TestSwitchToSession_HiddenSession(t *testing.T) {
+	const private = "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b"
+	s := newSwitchTestService(private)
+	manager := s.sessionManager.(*switchTestManager)
+	manager.hidden = map[string]bool{private: true}
+
+	// E.g. a DM session's ID picked in a public channel
+	response := s.switchToSession("U1", "CPUBLIC", private)
+	if !strings.Contains(response, "Session not found") {
+		t.Errorf("Expected a session from another channel to be refused, got %q", response)
+	}
+	if len(manager.switched) != 0 {
+		t.Errorf("Expected no switch, got %v", manager.switched)
+	}
+
+	// A recap of it is never posted either, even if the channel already uses it
+	manager.current = private
+	s.sendSessionRecap("U1", "CPUBLIC")
+	if manager.treeLoads != 0 {
+		t.Error("Expected no recap of a session from another channel")
+	}
+}
+
+func TestSessionPickerBlocks(t *testing.T) {
+	s := newSwitchTestService()
+	var matches []session.SessionInfo
+	for i := 0; i < maxSessionPickerOptions+1; i++ {
+		matches = append(matches, &session.DbSessionInfo{Session: &repository.Session{
+			SessionID:        strings.Repeat("a", 8) + "-" + strings.Repeat("0", i),
+			WorkingDirectory: "/srv/" + strings.Repeat("très-long-répertoire/", 10),
+		}})
+	}
+
+	blocks := s.sessionPickerBlocks("U123", "aaaa", matches)
+	section := blocks[0].(*slack.SectionBlock)
+	if !strings.Contains(section.Text.Text, "More than 25") {
+		t.Errorf("Expected the header to say the list was cut, got %q", section.Text.Text)
+	}
+
+	options := section.Accessory.SelectElement.Options
+	if len(options) != maxSessionPickerOptions {
+		t.Fatalf("Expected %d options, got %d", maxSessionPickerOptions, len(options))
+	}
+	for _, option := range options {
+		if !strings.HasPrefix(option.Text.Text, "aaaaaaaa · …") || utf8.RuneCountInString(option.Text.Text) != 75 || !utf8.ValidString(option.Text.Text) {
+			t.Errorf("Expected a short label with the ID and directory, got %q", option.Text.Text)
+		}
+	}
+	if options[0].Value != matches[0].GetID() {
+		t.Errorf("Expected option values to be full session IDs, got %q", options[0].Value)
+	}
+}
@@ -0,0 +1,179 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+const (
+	// sessionTitleMaxLength caps a stored title, well under the column's 100
+	sessionTitleMaxLength = 60
+	// sessionTitleInputLimit caps how much of the first exchange is sent to
+	// name a session; the start says what it's about
+	sessionTitleInputLimit = 4000
+	// sessionTitleTimeout bounds the naming call, which nobody waits on
+	sessionTitleTimeout = 2 * time.Minute
+)
+
+// sessionTitleCache remembers titles already looked up, and sessions found
+// without one as "" so listings don't query them again. A title never
+// changes once stored, so entries don't expire; a session named later is
+// updated by titleSession.
+type sessionTitleCache struct {
+	mu     sync.RWMutex
+	titles map[string]string
+}
+
+func newSessionTitleCache() *sessionTitleCache {
+	return &sessionTitleCache{titles: make(map[string]string)}
+}
+
+func (c *sessionTitleCache) get(sessionID string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	title, ok := c.titles[sessionID]
+	return title, ok
+}
+
+func (c *sessionTitleCache) put(sessionID, title string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.titles[sessionID] = title
+}
+
+// forget drops what is known about a session, so it is looked up again
+func (c *sessionTitleCache) forget(sessionID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.titles, sessionID)
+}
+
+// cleanSessionTitle turns a model's answer into a one-line title, or "" if
+// nothing usable is left
+func cleanSessionTitle(raw string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(raw), "\n")
+	line = strings.TrimPrefix(strings.TrimSpace(line), "Title:")
+	title := strings.Join(strings.Fields(line), " ")
+	title = strings.TrimRight(strings.Trim(title, "`*_\"'"), ".")
+	if runes := []rune(title); len(runes) > sessionTitleMaxLength {
+		title = strings.TrimSpace(string(runes[:sessionTitleMaxLength-1])) + "…"
+	}
+	return title
+}
+
+// sessionTitleInput is the first exchange of a session as sent to be named
+func sessionTitleInput(prompt, response string) string {
+	text := fmt.Sprintf("User: %s\n\nAssistant: %s", strings.TrimSpace(prompt), strings.TrimSpace(response))
+	if runes := []rune(text); len(runes) > sessionTitleInputLimit {
+		text = string(runes[:sessionTitleInputLimit])
+	}
+	return text
+}
+
+// titleSession names a session after its first exchange and stores the
+// title. It is meant to run in the background; failures are logged and the
+// session keeps showing its ID.
+func (s *Service) titleSession(sessionID, prompt, response string) {
+	if !s.config.SessionTitles {
+		return
+	}
+	titleMgr, ok := s.sessionManager.(session.SessionTitleManager)
+	if !ok || s.sessionTitle(sessionID) != "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sessionTitleTimeout)
+	defer cancel()
+	raw, err := s.claudeExecutor.ExecuteClaudeTitle(ctx, s.config.SessionTitleModel, sessionTitleInput(prompt, response))
+	if err != nil {
+		s.logger.Warn("Failed to generate session title",
+			zap.String("session_id", sessionID),
+			zap.Error(err))
+		return
+	}
+	title := cleanSessionTitle(raw)
+	if title == "" {
+		return
+	}
+
+	stored, err := titleMgr.SetSessionTitle(sessionID, title)
+	if err != nil {
+		s.logger.Warn("Failed to store session title",
+			zap.String("session_id", sessionID),
+			zap.Error(err))
+		return
+	}
+	if !stored {
+		// Someone else named it first; don't keep a cached miss for it
+		s.titleCache.forget(sessionID)
+		return
+	}
+	s.titleCache.put(sessionID, title)
+	s.logger.Info("Named session",
+		zap.String("session_id", sessionID),
+		zap.String("title", title))
+}
+
+// sessionTitles returns the titles of the given sessions that have one,
+// keyed by session ID
+func (s *Service) sessionTitles(sessionIDs []string) map[string]string {
+	titles := make(map[string]string)
+	var missing []string
+	for _, id := range sessionIDs {
+		if title, ok := s.titleCache.get(id); ok {
+			if title != "" {
+				titles[id] = title
+			}
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	titleMgr, ok := s.sessionManager.(session.SessionTitleManager)
+	if !ok || len(missing) == 0 {
+		return titles
+	}
+	stored, err := titleMgr.GetSessionTitles(missing)
+	if err != nil {
+		s.logger.Debug("Failed to look up session titles", zap.Error(err))
+		return titles
+	}
+	for _, id := range missing {
+		title := stored[id]
+		s.titleCache.put(id, title)
+		if title != "" {
+			titles[id] = title
+		}
+	}
+	return titles
+}
+
+// sessionTitle returns a session's title, or "" if it has none yet
+func (s *Service) sessionTitle(sessionID string) string {
+	return s.sessionTitles([]string{sessionID})[sessionID]
+}
+
+// sessionFooterLabel shows a session in a response footer by its title, with
+// the ID still there to copy, or by ID alone before it has one
+func sessionFooterLabel(title, sessionID string) string {
+	if title == "" {
+		return sessionID
+	}
+	return fmt.Sprintf("%s (%s)", title, sessionID)
+}
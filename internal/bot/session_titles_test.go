@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+func TestCleanSessionTitle(t *testing.T) {
+	tests := map[string]string{
+		"Fix flaky login test":                    "Fix flaky login test",
+		"  \"Fix flaky login test.\"  ":           "Fix flaky login test",
+		"Title: **Postgres audit log migration**": "Postgres audit log migration",
+		"Fix   the\tbuild\nHere is why: ...":      "Fix the build",
+		"``":                                      "",
+	}
+	for raw, want := range tests {
+		if got := cleanSessionTitle(raw); got != want {
+			t.Errorf("cleanSessionTitle(%q) = %q, want %q", raw, got, want)
+		}
+	}
+
+	long := cleanSessionTitle(strings.Repeat("word ", 40))
+	if n := len([]rune(long)); n > sessionTitleMaxLength || !strings.HasSuffix(long, "…") {
+		t.Errorf("Expected a long title to be cut to %d characters, got %d: %q", sessionTitleMaxLength, n, long)
+	}
+}
+
+func TestSessionTitleInput(t *testing.T) {
+	input := sessionTitleInput(" fix it ", strings.Repeat("x", 2*sessionTitleInputLimit))
+	if !strings.HasPrefix(input, "User: fix it\n\nAssistant: x") {
+		t.Errorf("Unexpected input start: %q", input[:40])
+	}
+	if n := len([]rune(input)); n != sessionTitleInputLimit {
+		t.Errorf("Expected input capped at %d characters, got %d", sessionTitleInputLimit, n)
+	}
+}
+
+func TestSessionTitles_UsesCache(t *testing.T) {
+	s := &Service{titleCache: newSessionTitleCache()}
+	s.titleCache.put("s-1", "Fix flaky login test")
+
+	titles := s.sessionTitles([]string{"s-1", "s-2"})
+	if titles["s-1"] != "Fix flaky login test" {
+		t.Errorf("Expected the cached title, got %q", titles["s-1"])
+	}
+	if _, ok := titles["s-2"]; ok {
+		t.Error("Expected no title for an unnamed session")
+	}
+	if got := s.sessionTitle("s-2"); got != "" {
+		t.Errorf("Expected no title, got %q", got)
+	}
+}
+
+// titleLookupManager counts title lookups
+type titleLookupManager struct {
+	session.SessionManager
+	titles  map[string]string
+	lookups int
+}
+
+func (m *titleLookupManager) SetSessionTitle(sessionID, title string) (bool, error) {
+	return false, nil
+}
+
+func (m *titleLookupManager) GetSessionTitles(sessionIDs []string) (map[string]string, error) {
+	m.lookups++
+	found := make(map[string]string)
+	for _, id := range sessionIDs {
+		if title, ok := m.titles[id]; ok {
+			found[id] = title
+		}
+	}
+	return found, nil
+}
+
+func TestSessionTitles_CachesMisses(t *testing.T) {
+	manager := &titleLookupManager{titles: map[string]string{"s-1": "Fix flaky login test"}}
+	s := &Service{titleCache: newSessionTitleCache(), sessionManager: manager}
+
+	for i := 0; i < 3; i++ {
+		titles := s.sessionTitles([]string{"s-1", "s-2"})
+		if titles["s-1"] != "Fix flaky login test" || len(titles) != 1 {
+			t.Fatalf("sessionTitles() = %v", titles)
+		}
+	}
+	if manager.lookups != 1 {
+		t.Errorf("Expected titles and misses to be looked up once, got %d lookups", manager.lookups)
+	}
+
+	// A session named elsewhere is looked up again
+	s.titleCache.forget("s-2")
+	manager.titles["s-2"] = "Upgrade Go"
+	if got := s.sessionTitle("s-2"); got != "Upgrade Go" {
+		t.Errorf("sessionTitle() = %q, want the new title", got)
+	}
+}
+
+func TestSessionFooterLabel(t *testing.T) {
+	if got := sessionFooterLabel("", "abc"); got != "abc" {
+		t.Errorf("Expected the bare ID without a title, got %q", got)
+	}
+	if got := sessionFooterLabel("Fix the build", "abc"); got != "Fix the build (abc)" {
+		t.Errorf("Expected the title with the ID, got %q", got)
+	}
+}
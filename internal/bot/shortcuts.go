@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+)
+
+// askClaudeCallbackID is the callback ID of the "Ask Claude about this message"
+// message shortcut configured in the Slack app
+const askClaudeCallbackID = "ask_claude_message"
+
+// shortcutThreadContextLimit caps how many earlier thread replies are included
+const shortcutThreadContextLimit = 20
+
+// handleMessageShortcut handles message shortcuts (message_action interactions)
+func (s *Service) handleMessageShortcut(callback *slack.InteractionCallback) {
+	s.logger.Info("Message shortcut",
+		zap.String("callback_id", callback.CallbackID),
+		zap.String("user_id", callback.User.ID),
+		zap.String("channel_id", callback.Channel.ID))
+
+	switch callback.CallbackID {
+	case askClaudeCallbackID:
+		// Interactions must be acked within 3 seconds; run Claude in the background
+		go s.handleAskClaudeShortcut(callback.User.ID, callback.Channel.ID, callback.Message)
+	default:
+		s.logger.Debug("Unhandled message shortcut", zap.String("callback_id", callback.CallbackID))
+	}
+}
+
+// handleAskClaudeShortcut sends the selected message to Claude through the
+// channel's active session and replies in the message's thread
+func (s *Service) handleAskClaudeShortcut(userID, channelID string, message slack.Message) {
+	ctx := context.Background()
+
+	// Reply in the existing thread, or start one under the selected message
+	threadTS := message.ThreadTimestamp
+	if threadTS == "" {
+		threadTS = message.Timestamp
+	}
+
+	authCtx := &auth.AuthContext{
+		UserID:    userID,
+		ChannelID: channelID,
+		Command:   "shortcut:" + askClaudeCallbackID,
+		Timestamp: time.Now(),
+	}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionExecute); err != nil {
+		s.logger.Warn("Shortcut authorization failed", zap.Error(err))
+		s.postEphemeral(channelID, userID, fmt.Sprintf("❌ Authorization failed: %v", err))
+		return
+	}
+	if limited := s.checkRateLimit(userID, channelID); limited != nil {
+		s.postEphemeral(channelID, userID, s.rateLimitMessage(userID, limited))
+		return
+	}
+
+	prompt := s.buildShortcutPrompt(userID, channelID, message, threadTS)
+	s.runMessagePrompt(ctx, userID, channelID, message, threadTS, prompt)
+}
+
+// runMessagePrompt runs a prompt about a message through the channel's active
+// session, with the message's files attached, and replies in threadTS
+func (s *Service) runMessagePrompt(ctx context.Context, userID, channelID string, message slack.Message, threadTS, prompt string) {
+	event := &slackevents.MessageEvent{
+		Type:            "message",
+		User:            userID,
+		Text:            prompt,
+		TimeStamp:       message.Timestamp,
+		ThreadTimeStamp: threadTS,
+		Channel:         channelID,
+	}
+	for _, file := range message.Files {
+		event.Files = append(event.Files, slackevents.File{
+			ID:       file.ID,
+			Name:     file.Name,
+			Mimetype: file.Mimetype,
+		})
+	}
+
+	started := time.Now()
+	response := s.processClaudeMessage(ctx, event, prompt, runOverrides{})
+	if response != "" {
+		// A held response isn't in Slack yet, so there is nothing to link to
+		if responseTS, held := s.sendThreadResponseOrHold(channelID, threadTS, response); !held {
+			s.notifyIfSlow(userID, channelID, responseTS, time.Since(started))
+		}
+	}
+}
+
+// buildShortcutPrompt wraps the selected message, and optionally its earlier
+// thread replies, into a prompt for Claude
+func (s *Service) buildShortcutPrompt(userID, channelID string, message slack.Message, threadTS string) string {
+	var prompt strings.Builder
+	prompt.WriteString(fmt.Sprintf("<@%s> wants your help with the following Slack message", userID))
+	if message.User != "" {
+		prompt.WriteString(fmt.Sprintf(" posted by <@%s>", message.User))
+	}
+	prompt.WriteString(":\n\n```\n")
+	prompt.WriteString(message.Text)
+	prompt.WriteString("\n```\n")
+
+	if s.config.ShortcutThreadContext && message.ThreadTimestamp != "" {
+		if thread := s.fetchThreadContext(channelID, threadTS, message.Timestamp); thread != "" {
+			prompt.WriteString("\nEarlier messages in the same thread, for context:\n\n")
+			prompt.WriteString(thread)
+		}
+	}
+
+	prompt.WriteString("\nExplain what this message is about and help with it. If it contains an error, log, or stack trace, diagnose the likely cause and suggest a fix.")
+	return prompt.String()
+}
+
+// fetchThreadContext returns thread replies that precede the selected message,
+// formatted one per line; failures are logged and yield an empty string
+func (s *Service) fetchThreadContext(channelID, threadTS, beforeTS string) string {
+	replies, _, _, err := s.api().GetConversationReplies(&slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: threadTS,
+		Latest:    beforeTS,
+		Limit:     shortcutThreadContextLimit + 1,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to fetch thread context for shortcut",
+			zap.String("channel_id", channelID),
+			zap.String("thread_ts", threadTS),
+			zap.Error(err))
+		return ""
+	}
+
+	var thread strings.Builder
+	count := 0
+	for _, reply := range replies {
+		if reply.Timestamp == beforeTS || reply.Text == "" {
+			continue
+		}
+		if count == shortcutThreadContextLimit {
+			break
+		}
+		author := reply.User
+		if author == "" {
+			author = reply.BotID
+		}
+		thread.WriteString(fmt.Sprintf("<@%s>: %s\n", author, reply.Text))
+		count++
+	}
+	return thread.String()
+}
+
+// postEphemeral sends a message only visible to one user
+func (s *Service) postEphemeral(channelID, userID, message string) {
+	if err := s.sender.PostEphemeral(context.Background(), channelID, userID, slack.MsgOptionText(message, false)); err != nil {
+		s.logger.Error("Failed to send ephemeral message",
+			zap.String("channel_id", channelID),
+			zap.String("user_id", userID),
+			zap.Error(err))
+	}
+}
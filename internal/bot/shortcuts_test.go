@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/slacksend"
+)
+
+// askClaudePayload is a message_action payload as Slack sends it for a
+// reply in a thread
+const askClaudePayload = `{
+	"type": "message_action",
+	"callback_id": "ask_claude_message",
+	"trigger_id": "123.456.abc",
+	"team": {"id": "T123", "domain": "acme"},
+	"channel": {"id": "C123", "name": "incidents"},
+	"user": {"id": "U123", "name": "alice"},
+	"message_ts": "1700000100.000200",
+	"message": {
+		"type": "message",
+		"user": "U456",
+		"text": "panic: assignment to entry in nil map",
+		"ts": "1700000100.000200",
+		"thread_ts": "1700000000.000100"
+	},
+	"response_url": "https://hooks.slack.com/app/T123/1/abc"
+}`
+
+func TestAskClaudeShortcut_ParsesPayload(t *testing.T) {
+	var callback slack.InteractionCallback
+	if err := json.Unmarshal([]byte(askClaudePayload), &callback); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateInteraction(&callback); err != nil {
+		t.Fatalf("validateInteraction() error = %v", err)
+	}
+	if callback.Type != slack.InteractionTypeMessageAction || callback.CallbackID != askClaudeCallbackID {
+		t.Errorf("Expected the ask Claude message shortcut, got %s %q", callback.Type, callback.CallbackID)
+	}
+	if callback.User.ID != "U123" || callback.Channel.ID != "C123" || callback.Message.ThreadTimestamp != "1700000000.000100" {
+		t.Errorf("Unexpected user, channel, or thread: %+v", callback)
+	}
+
+	s := &Service{config: &config.Config{}}
+	prompt := s.buildShortcutPrompt(callback.User.ID, callback.Channel.ID, callback.Message, callback.Message.ThreadTimestamp)
+	for _, want := range []string{"<@U123> wants your help", "posted by <@U456>", "```\npanic: assignment to entry in nil map\n```"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q, got %q", want, prompt)
+		}
+	}
+}
+
+func TestAskClaudeShortcut_UnauthorizedUser(t *testing.T) {
+	cfg := &config.Config{AllowedUsers: []string{"U999"}}
+	client := &recordingSlack{}
+	// Without a session manager, reaching Claude would panic
+	s := &Service{
+		config:      cfg,
+		logger:      zap.NewNop(),
+		authService: auth.NewService(cfg, zap.NewNop()),
+		sender:      slacksend.New(func() slacksend.Client { return client }, 0, 0, zap.NewNop()),
+	}
+
+	s.handleAskClaudeShortcut("U123", "C123", slack.Message{Msg: slack.Msg{User: "U456", Text: "panic", Timestamp: "1700000100.000200"}})
+	if len(client.posts) != 1 || !strings.HasPrefix(client.posts[0], "C123: ❌ Authorization failed") {
+		t.Errorf("Expected only an ephemeral authorization error, got %q", client.posts)
+	}
+}
@@ -0,0 +1,169 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/notifications"
+)
+
+// slowModeChange is what a finished run did to slow mode
+type slowModeChange int
+
+const (
+	slowModeUnchanged slowModeChange = iota
+	slowModeTripped                  // A rate limit turned slow mode on
+	slowModeExtended                 // A probe was rate limited again
+	slowModeRecovered                // A probe got through
+)
+
+// slowMode holds new runs back while the API is rate limiting or
+// overloaded. Once the backoff passes, one run goes first as a probe: if it
+// gets through, slow mode ends; if it's limited again, the backoff doubles.
+type slowMode struct {
+	mu        sync.Mutex
+	initial   time.Duration
+	max       time.Duration
+	backoff   time.Duration
+	until     time.Time     // Zero when slow mode is off
+	probeDone chan struct{} // Closed when the probe in flight finishes; nil without one
+}
+
+func newSlowMode(initial, max time.Duration) *slowMode {
+	return &slowMode{initial: initial, max: max}
+}
+
+// status reports whether slow mode is on and when the next probe may start
+func (m *slowMode) status() (bool, time.Time) {
+	if m == nil {
+		return false, time.Time{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.until.IsZero(), m.until
+}
+
+// admit waits until a run may start. It reports whether the run is the
+// probe, whose outcome must be passed to done. It fails only if ctx is done
+// first.
+func (m *slowMode) admit(ctx context.Context) (bool, error) {
+	if m == nil || m.initial <= 0 {
+		return false, nil
+	}
+	for {
+		m.mu.Lock()
+		if m.until.IsZero() {
+			m.mu.Unlock()
+			return false, nil
+		}
+		wait := time.Until(m.until)
+		if wait <= 0 && m.probeDone == nil {
+			m.probeDone = make(chan struct{})
+			m.mu.Unlock()
+			return true, nil
+		}
+		probeDone := m.probeDone
+		m.mu.Unlock()
+
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return false, ctx.Err()
+			}
+			continue
+		}
+		select {
+		case <-probeDone:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// done records how a run went: rateLimited if the API refused it, ok if it
+// got an answer. A run that learned neither, such as one canceled, passes
+// both false.
+func (m *slowMode) done(probe, rateLimited, ok bool, now time.Time) slowModeChange {
+	if m == nil || m.initial <= 0 {
+		return slowModeUnchanged
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if probe && m.probeDone != nil {
+		close(m.probeDone)
+		m.probeDone = nil
+	}
+
+	switch {
+	case rateLimited && m.until.IsZero():
+		m.backoff = m.initial
+		m.until = now.Add(m.backoff)
+		return slowModeTripped
+	case rateLimited && probe:
+		m.backoff = min(2*m.backoff, m.max)
+		m.until = now.Add(m.backoff)
+		return slowModeExtended
+	case ok && probe:
+		m.backoff = 0
+		m.until = time.Time{}
+		return slowModeRecovered
+	}
+	return slowModeUnchanged
+}
+
+// slowModeFailureNote follows the error of a run that was still rate
+// limited after its retries, or is "" if slow mode isn't on
+func (s *Service) slowModeFailureNote(err error) string {
+	if on, _ := s.slowMode.status(); !on || !claude.IsRateLimited(err) {
+		return ""
+	}
+	return fmt.Sprintf("🐢 **Slow mode is on:** Claude's API kept rate limiting this run through %d retries. Runs are queued until it recovers; send your message again later.", rateLimitRetries)
+}
+
+// slowModeNotice tells a user their run is held back, or returns "" when
+// slow mode is off
+func (s *Service) slowModeNotice() string {
+	on, until := s.slowMode.status()
+	if !on {
+		return ""
+	}
+	wait := time.Until(until)
+	if wait < time.Second {
+		return "🐢 **Slow mode:** Claude's API is rate limiting. Your run is queued behind a test run and starts once it gets through."
+	}
+	return fmt.Sprintf("🐢 **Slow mode:** Claude's API is rate limiting. Your run is queued and should start in about %s.", formatElapsed(wait.Round(time.Second)))
+}
+
+// recordSlowMode updates slow mode with how a run went and tells operators
+// when it turns on or off
+func (s *Service) recordSlowMode(probe bool, runCtx context.Context, err error) {
+	rateLimited := claude.IsRateLimited(err)
+	ok := !rateLimited && runCtx.Err() == nil && !errors.Is(err, context.Canceled)
+	change := s.slowMode.done(probe, rateLimited, ok, time.Now())
+
+	_, until := s.slowMode.status()
+	switch change {
+	case slowModeTripped, slowModeExtended:
+		s.logger.Warn("Claude API is rate limiting; slow mode on",
+			zap.Bool("extended", change == slowModeExtended),
+			zap.Time("next_probe", until))
+		s.alert(notifications.Event{
+			Kind:     notifications.EventSlowMode,
+			Severity: notifications.SeverityWarning,
+			Key:      "on",
+			Title:    "Claude API rate limited: slow mode on",
+			Text:     fmt.Sprintf("New runs are queued until a probe run gets through; the next probe is at %s UTC.", until.UTC().Format("15:04:05")),
+		})
+	case slowModeRecovered:
+		s.logger.Info("Claude API recovered; slow mode off")
+	}
+}
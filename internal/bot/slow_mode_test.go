@@ -0,0 +1,157 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/notifications"
+	"github.com/ghabxph/claude-on-slack/internal/runqueue"
+)
+
+func TestSlowMode_BackoffAndRecovery(t *testing.T) {
+	m := newSlowMode(time.Second, 3*time.Second)
+	now := time.Now()
+
+	if change := m.done(false, false, true, now); change != slowModeUnchanged {
+		t.Errorf("Expected a normal run to leave slow mode off, got %v", change)
+	}
+	if change := m.done(false, true, false, now); change != slowModeTripped {
+		t.Fatalf("Expected a rate limit to trip slow mode, got %v", change)
+	}
+	if on, until := m.status(); !on || !until.Equal(now.Add(time.Second)) {
+		t.Errorf("status() = %v, %v; want on until %v", on, until, now.Add(time.Second))
+	}
+	if change := m.done(false, true, false, now); change != slowModeUnchanged {
+		t.Errorf("Expected runs already in flight not to extend slow mode, got %v", change)
+	}
+
+	// A limited probe doubles the backoff up to the max
+	for _, want := range []time.Duration{2 * time.Second, 3 * time.Second} {
+		if change := m.done(true, true, false, now); change != slowModeExtended {
+			t.Fatalf("Expected a limited probe to extend slow mode, got %v", change)
+		}
+		if _, until := m.status(); !until.Equal(now.Add(want)) {
+			t.Errorf("Next probe at %v, want %v", until.Sub(now), want)
+		}
+	}
+
+	if change := m.done(true, false, false, now); change != slowModeUnchanged {
+		t.Errorf("Expected an abandoned probe to change nothing, got %v", change)
+	}
+	if change := m.done(true, false, true, now); change != slowModeRecovered {
+		t.Errorf("Expected a successful probe to end slow mode, got %v", change)
+	}
+	if on, _ := m.status(); on {
+		t.Error("Expected slow mode off after recovery")
+	}
+}
+
+func TestSlowMode_AdmitsOneProbe(t *testing.T) {
+	m := newSlowMode(time.Minute, time.Minute)
+	m.done(false, true, false, time.Now().Add(-time.Minute))
+
+	probe, err := m.admit(context.Background())
+	if err != nil || !probe {
+		t.Fatalf("admit() = %v, %v; want the probe", probe, err)
+	}
+
+	admitted := make(chan bool, 1)
+	go func() {
+		probe, _ := m.admit(context.Background())
+		admitted <- probe
+	}()
+	select {
+	case <-admitted:
+		t.Fatal("Expected other runs to wait for the probe")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	m.done(true, false, true, time.Now())
+	select {
+	case probe := <-admitted:
+		if probe {
+			t.Error("Expected runs after recovery not to be probes")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Waiting run was not admitted after the probe got through")
+	}
+}
+
+func TestSlowMode_AdmitCanceled(t *testing.T) {
+	m := newSlowMode(time.Minute, time.Minute)
+	m.done(false, true, false, time.Now())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.admit(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("admit() error = %v, want the context's", err)
+	}
+}
+
+func TestSlowMode_Disabled(t *testing.T) {
+	var unset *slowMode
+	if probe, err := unset.admit(context.Background()); probe || err != nil {
+		t.Errorf("nil admit() = %v, %v", probe, err)
+	}
+
+	off := newSlowMode(0, time.Minute)
+	if change := off.done(false, true, false, time.Now()); change != slowModeUnchanged {
+		t.Errorf("Expected a zero backoff to disable slow mode, got %v", change)
+	}
+}
+
+func TestRunQueued_TripsSlowMode(t *testing.T) {
+	s := &Service{
+		config:         &config.Config{},
+		logger:         zap.NewNop(),
+		runQueue:       runqueue.New(1),
+		slowMode:       newSlowMode(time.Millisecond, time.Millisecond),
+		alertCooldowns: newAlertCooldowns(),
+		notifier:       notifications.NewRouter(zap.NewNop()),
+	}
+
+	limited := &claude.ExecutionError{Kind: claude.ErrorKindRateLimited, Err: errors.New("API Error: 529 overloaded_error")}
+	calls := 0
+	err := s.runQueued(context.Background(), runqueue.PriorityNormal, nil, func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return limited
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("Expected the rate limited run requeued and retried, got err %v after %d calls", err, calls)
+	}
+	if on, _ := s.slowMode.status(); on {
+		t.Error("Expected the retry to end slow mode as its probe")
+	}
+
+	calls = 0
+	if err := s.runQueued(context.Background(), runqueue.PriorityNormal, nil, func(context.Context) error { calls++; return limited }); err != limited {
+		t.Fatalf("runQueued() error = %v, want the rate limit", err)
+	}
+	if calls != rateLimitRetries+1 {
+		t.Errorf("Expected %d attempts before giving up, got %d", rateLimitRetries+1, calls)
+	}
+	if s.slowModeFailureNote(limited) == "" {
+		t.Error("Expected the limited run's error to mention slow mode")
+	}
+
+	s.slowMode = newSlowMode(time.Minute, time.Minute)
+	s.slowMode.done(false, true, false, time.Now())
+	if s.slowModeNotice() == "" {
+		t.Error("Expected a slow mode notice after a rate limit")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	ran := false
+	if err := s.runQueued(ctx, runqueue.PriorityNormal, nil, func(context.Context) error { ran = true; return nil }); err == nil || ran {
+		t.Errorf("Expected the next run to wait out slow mode, got err %v, ran %v", err, ran)
+	}
+}
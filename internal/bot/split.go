@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSectionTextLength is Slack's limit on a section block's text
+const maxSectionTextLength = 3000
+
+// codeFence opens and closes Slack code blocks
+const codeFence = "```"
+
+// minFencedSplitLength is the smallest limit at which the splitter closes and
+// reopens code blocks across messages; below it there's no room for fences
+const minFencedSplitLength = 64
+
+// unbreakablePattern matches text a split must not fall inside: code fences,
+// Slack entities such as <@U123>, <#C123|general>, and <https://…|label>,
+// bare URLs, and inline code
+var unbreakablePattern = regexp.MustCompile("```|<[^<>\\n]+>|https?://[^\\s<>]+|`[^`\\n]+`")
+
+// splitMessage splits message into chunks of at most maxLength characters
+// (runes, not bytes). It breaks at paragraph ends, then line ends, then
+// spaces, never inside Slack entities, URLs, or inline code unless one alone
+// is too long, and closes a code block at the end of a chunk and reopens it
+// at the start of the next so formatting survives the split.
+func splitMessage(message string, maxLength int) []string {
+	if maxLength <= 0 || utf8.RuneCountInString(message) <= maxLength {
+		return []string{message}
+	}
+	fenced := maxLength >= minFencedSplitLength
+
+	var chunks []string
+	inCode := false
+	rest := message
+	for rest != "" {
+		prefix := ""
+		if inCode {
+			prefix = codeFence + "\n"
+		}
+		budget := maxLength - utf8.RuneCountInString(prefix)
+		if utf8.RuneCountInString(rest) <= budget {
+			chunks = append(chunks, prefix+rest)
+			break
+		}
+		if fenced {
+			// Room to close a code block the chunk ends inside
+			budget -= len("\n" + codeFence)
+		}
+
+		cut, skip := findSplit(rest, budget)
+		chunk := rest[:cut]
+		rest = rest[cut+skip:]
+
+		if fenced && strings.Count(chunk, codeFence)%2 == 1 {
+			inCode = !inCode
+		}
+		chunk = prefix + chunk
+		if fenced && inCode {
+			if !strings.HasSuffix(chunk, "\n") {
+				chunk += "\n"
+			}
+			chunk += codeFence
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// findSplit picks where to end a chunk of text holding at most limit runes.
+// It returns the byte offset to cut at and how many separator bytes after it
+// to drop.
+func findSplit(text string, limit int) (cut, skip int) {
+	end := runeOffset(text, limit)
+	spans := unbreakablePattern.FindAllStringIndex(text, -1)
+
+	breakable := func(pos int) bool {
+		for _, span := range spans {
+			if span[0] < pos && pos < span[1] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Prefer the latest paragraph or line end in the second half of the
+	// chunk, then the latest space anywhere
+	for _, sep := range []string{"\n\n", "\n"} {
+		for pos := strings.LastIndex(text[:end], sep); pos > end/2; pos = strings.LastIndex(text[:pos], sep) {
+			if breakable(pos) {
+				return pos, len(sep)
+			}
+		}
+	}
+	for pos := strings.LastIndexAny(text[:end], " \n"); pos > 0; pos = strings.LastIndexAny(text[:pos], " \n") {
+		if breakable(pos) {
+			return pos, 1
+		}
+	}
+
+	// No separator: cut before whatever the limit falls inside, or through
+	// it when it alone is longer than a chunk
+	for _, span := range spans {
+		if span[0] < end && end < span[1] && span[0] > 0 {
+			return span[0], 0
+		}
+	}
+	return end, 0
+}
+
+// runeOffset returns the byte offset just past the first n runes of text
+func runeOffset(text string, n int) int {
+	if n < 1 {
+		n = 1
+	}
+	for i := range text {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(text)
+}
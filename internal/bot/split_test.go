@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// checkChunks fails unless every chunk fits in maxLength characters
+func checkChunks(t *testing.T, chunks []string, maxLength int) {
+	t.Helper()
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > maxLength {
+			t.Errorf("chunk %d has %d characters, over the limit of %d: %q", i, n, maxLength, chunk)
+		}
+		if !utf8.ValidString(chunk) {
+			t.Errorf("chunk %d is not valid UTF-8: %q", i, chunk)
+		}
+	}
+}
+
+func TestSplitMessage_ShortMessageUnchanged(t *testing.T) {
+	message := "hello <@U123>"
+	chunks := splitMessage(message, 100)
+	if len(chunks) != 1 || chunks[0] != message {
+		t.Errorf("Expected the message unchanged, got %q", chunks)
+	}
+}
+
+func TestSplitMessage_PrefersParagraphsThenLines(t *testing.T) {
+	message := strings.Repeat("a", 60) + "\n\n" + strings.Repeat("b", 30) + "\n" + strings.Repeat("c", 30)
+	chunks := splitMessage(message, 100)
+	checkChunks(t, chunks, 100)
+	if len(chunks) != 2 || chunks[0] != strings.Repeat("a", 60) {
+		t.Errorf("Expected a split at the paragraph break, got %q", chunks)
+	}
+}
+
+func TestSplitMessage_KeepsEntitiesWhole(t *testing.T) {
+	entities := []string{"<@U0123ABCD>", "<#C0123ABCD|general>", "<https://example.com/some/long/path|the docs>", "`go test ./...`"}
+	for _, entity := range entities {
+		// Put the entity across where a naive split by length would cut
+		message := strings.Repeat("x", 90) + entity + strings.Repeat(" y", 60)
+		chunks := splitMessage(message, 100)
+		checkChunks(t, chunks, 100)
+
+		found := false
+		for _, chunk := range chunks {
+			if strings.Contains(chunk, entity) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected %q to stay in one chunk, got %q", entity, chunks)
+		}
+		if strings.ReplaceAll(strings.Join(chunks, ""), " ", "") != strings.ReplaceAll(message, " ", "") {
+			t.Errorf("Expected the chunks to rebuild the message, got %q", chunks)
+		}
+	}
+}
+
+func TestSplitMessage_LongURLs(t *testing.T) {
+	url := "https://example.com/" + strings.Repeat("segment/", 6)
+	message := "See " + url + " for details. " + strings.Repeat("word ", 30)
+	chunks := splitMessage(message, 100)
+	checkChunks(t, chunks, 100)
+	if !strings.Contains(chunks[0], url) && !strings.Contains(chunks[1], url) {
+		t.Errorf("Expected the URL in one chunk, got %q", chunks)
+	}
+
+	// A URL longer than a whole chunk has to be cut, but still fits
+	long := "https://example.com/" + strings.Repeat("z", 300)
+	chunks = splitMessage(long, 100)
+	checkChunks(t, chunks, 100)
+	if strings.Join(chunks, "") != long {
+		t.Errorf("Expected an oversized URL to be cut without losing text, got %q", chunks)
+	}
+}
+
+func TestSplitMessage_CountsCharactersNotBytes(t *testing.T) {
+	// 150 three-byte characters: 450 bytes, but fits in 200 characters
+	message := strings.Repeat("日", 150)
+	if chunks := splitMessage(message, 200); len(chunks) != 1 {
+		t.Errorf("Expected multibyte text under the limit to stay whole, got %d chunks", len(chunks))
+	}
+
+	message = strings.Repeat("日本語 ", 100) + strings.Repeat("🎉", 150)
+	chunks := splitMessage(message, 120)
+	checkChunks(t, chunks, 120)
+	if strings.ReplaceAll(strings.Join(chunks, ""), " ", "") != strings.ReplaceAll(message, " ", "") {
+		t.Errorf("Expected no characters lost or mangled")
+	}
+}
+
+func TestSplitMessage_ReopensCodeBlocks(t *testing.T) {
+	var code strings.Builder
+	for i := 0; i < 40; i++ {
+		code.WriteString("fmt.Println(\"line\")\n")
+	}
+	message := "Here's the fix:\n\n```\n" + code.String() + "```\n\nThat should do it."
+	chunks := splitMessage(message, 200)
+	checkChunks(t, chunks, 200)
+	if len(chunks) < 3 {
+		t.Fatalf("Expected the code block to span several chunks, got %d", len(chunks))
+	}
+
+	for i, chunk := range chunks {
+		if strings.Count(chunk, codeFence)%2 != 0 {
+			t.Errorf("chunk %d leaves a code block open: %q", i, chunk)
+		}
+	}
+	if !strings.HasPrefix(chunks[1], codeFence+"\n") {
+		t.Errorf("Expected the second chunk to reopen the code block, got %q", chunks[1])
+	}
+	if last := chunks[len(chunks)-1]; !strings.HasSuffix(last, "That should do it.") {
+		t.Errorf("Expected the text after the code block at the end, got %q", last)
+	}
+}
+
+func TestSplitMessage_TinyLimits(t *testing.T) {
+	message := "<@U123> " + strings.Repeat("abc ", 20) + "```code```"
+	for _, limit := range []int{1, 5, 10} {
+		chunks := splitMessage(message, limit)
+		checkChunks(t, chunks, limit)
+		for _, chunk := range chunks {
+			if chunk == "" {
+				t.Errorf("limit %d: got an empty chunk in %q", limit, chunks)
+				break
+			}
+		}
+	}
+}
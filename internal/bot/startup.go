@@ -0,0 +1,175 @@
+package bot
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Dependencies checked before the bot is ready
+const (
+	dependencyDatabase  = "database"
+	dependencyClaudeCLI = "claude_cli"
+)
+
+// startupInitialBackoff is the wait before the first dependency retry; it
+// doubles on each attempt up to STARTUP_MAX_BACKOFF
+const startupInitialBackoff = time.Second
+
+const warmingUpMessage = "⏳ **Warming up**\n\nI'm still starting up and can't take requests yet. Try again in a minute."
+
+// dependencyStatus is a dependency's readiness as reported at /health
+type dependencyStatus struct {
+	Ready     bool       `json:"ready"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+}
+
+type startupDependency struct {
+	name   string
+	check  func() error
+	status dependencyStatus
+}
+
+// readiness tracks the dependencies the bot needs before it takes requests.
+// In supervised startup mode it starts out with failed dependencies that
+// are retried until they come up.
+type readiness struct {
+	mu   sync.RWMutex
+	deps []*startupDependency
+}
+
+func newReadiness() *readiness {
+	return &readiness{}
+}
+
+// add registers a dependency with the result of its first check
+func (r *readiness) add(name string, check func() error, err error) {
+	dep := &startupDependency{name: name, check: check, status: dependencyStatus{Attempts: 1}}
+	dep.record(err)
+
+	r.mu.Lock()
+	r.deps = append(r.deps, dep)
+	r.mu.Unlock()
+}
+
+// record stores the result of a check
+func (d *startupDependency) record(err error) {
+	if err != nil {
+		d.status.LastError = err.Error()
+		return
+	}
+	now := time.Now()
+	d.status.Ready = true
+	d.status.LastError = ""
+	d.status.ReadyAt = &now
+}
+
+// Ready reports whether every dependency is up
+func (r *readiness) Ready() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, dep := range r.deps {
+		if !dep.status.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// Pending returns the names of dependencies that aren't up yet
+func (r *readiness) Pending() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var names []string
+	for _, dep := range r.deps {
+		if !dep.status.Ready {
+			names = append(names, dep.name)
+		}
+	}
+	return names
+}
+
+// retry checks each pending dependency again and returns the errors of those
+// still down, by name
+func (r *readiness) retry() map[string]error {
+	r.mu.RLock()
+	var pending []*startupDependency
+	for _, dep := range r.deps {
+		if !dep.status.Ready {
+			pending = append(pending, dep)
+		}
+	}
+	r.mu.RUnlock()
+
+	failed := make(map[string]error)
+	for _, dep := range pending {
+		// Checks may be slow, so run them without holding the lock
+		err := dep.check()
+
+		r.mu.Lock()
+		dep.status.Attempts++
+		dep.record(err)
+		r.mu.Unlock()
+
+		if err != nil {
+			failed[dep.name] = err
+		}
+	}
+	return failed
+}
+
+// Snapshot returns every dependency's status, by name
+func (r *readiness) Snapshot() map[string]dependencyStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	snapshot := make(map[string]dependencyStatus, len(r.deps))
+	for _, dep := range r.deps {
+		snapshot[dep.name] = dep.status
+	}
+	return snapshot
+}
+
+// startupBackoff is the wait before retry attempt n (starting at 1),
+// doubling from startupInitialBackoff up to max
+func startupBackoff(attempt int, max time.Duration) time.Duration {
+	backoff := startupInitialBackoff
+	for i := 1; i < attempt && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		return max
+	}
+	return backoff
+}
+
+// superviseStartup retries failed dependencies with backoff until all are up,
+// then starts the work that needs them
+func (s *Service) superviseStartup() {
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-time.After(startupBackoff(attempt, s.config.StartupMaxBackoff)):
+		case <-s.stopCh:
+			return
+		}
+
+		failed := s.readiness.retry()
+		if len(failed) > 0 {
+			for name, err := range failed {
+				s.logger.Warn("Dependency still unavailable; retrying",
+					zap.String("dependency", name),
+					zap.Int("attempt", attempt),
+					zap.Duration("next_retry", startupBackoff(attempt+1, s.config.StartupMaxBackoff)),
+					zap.Error(err))
+			}
+			continue
+		}
+
+		s.logger.Info("All dependencies ready; leaving warm-up mode",
+			zap.Duration("warm_up", time.Since(s.startTime).Truncate(time.Second)))
+		s.startDependentWorkers()
+		return
+	}
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStartupBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		max     time.Duration
+		want    time.Duration
+	}{
+		{1, time.Minute, time.Second},
+		{2, time.Minute, 2 * time.Second},
+		{4, time.Minute, 8 * time.Second},
+		{7, time.Minute, time.Minute},
+		{100, time.Minute, time.Minute},
+		{1, 500 * time.Millisecond, 500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := startupBackoff(tt.attempt, tt.max); got != tt.want {
+			t.Errorf("startupBackoff(%d, %v) = %v, want %v", tt.attempt, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	r := newReadiness()
+	if !r.Ready() {
+		t.Fatal("Expected readiness with no dependencies to be ready")
+	}
+
+	dbErr := errors.New("connection refused")
+	r.add(dependencyClaudeCLI, func() error { return nil }, nil)
+	r.add(dependencyDatabase, func() error { return dbErr }, dbErr)
+
+	if r.Ready() {
+		t.Fatal("Expected a failed dependency to keep readiness down")
+	}
+	if pending := r.Pending(); len(pending) != 1 || pending[0] != dependencyDatabase {
+		t.Errorf("Pending() = %v, want [%s]", pending, dependencyDatabase)
+	}
+
+	failed := r.retry()
+	if failed[dependencyDatabase] != dbErr || len(failed) != 1 {
+		t.Errorf("retry() = %v, want only the database to fail", failed)
+	}
+	status := r.Snapshot()[dependencyDatabase]
+	if status.Ready || status.Attempts != 2 || status.LastError != "connection refused" {
+		t.Errorf("database status = %+v", status)
+	}
+
+	dbErr = nil
+	if failed := r.retry(); len(failed) != 0 {
+		t.Errorf("retry() = %v, want no failures", failed)
+	}
+	if !r.Ready() {
+		t.Fatal("Expected readiness once every dependency is up")
+	}
+
+	snapshot := r.Snapshot()
+	if db := snapshot[dependencyDatabase]; !db.Ready || db.Attempts != 3 || db.LastError != "" || db.ReadyAt == nil {
+		t.Errorf("database status = %+v", db)
+	}
+	if cli := snapshot[dependencyClaudeCLI]; !cli.Ready || cli.Attempts != 1 {
+		t.Errorf("claude_cli status = %+v, want ready after one check", cli)
+	}
+}
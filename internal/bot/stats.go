@@ -0,0 +1,226 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const (
+	// defaultStatsRange is charted when /stats is given no range
+	defaultStatsRange = 7 * 24 * time.Hour
+	// maxStatsRange keeps the trend query to recent history
+	maxStatsRange = 90 * 24 * time.Hour
+	// statsPoints is how many buckets each sparkline has
+	statsPoints = 24
+	// statsTopSpenders is how many people the cost breakdown lists
+	statsTopSpenders = 5
+)
+
+// sparkBlocks are the bar heights of a sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// parseStatsRange parses a /stats range such as 12h, 7d, or 2w; an empty
+// range is the default
+func parseStatsRange(arg string) (time.Duration, error) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if arg == "" {
+		return defaultStatsRange, nil
+	}
+
+	units := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	unit, ok := units[arg[len(arg)-1]]
+	if !ok {
+		return 0, fmt.Errorf("unknown range %q; use hours, days, or weeks like `24h`, `7d`, or `2w`", arg)
+	}
+	n, err := strconv.Atoi(arg[:len(arg)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("unknown range %q; use hours, days, or weeks like `24h`, `7d`, or `2w`", arg)
+	}
+
+	rng := time.Duration(n) * unit
+	if rng > maxStatsRange {
+		return 0, fmt.Errorf("range %q is longer than %d days", arg, int(maxStatsRange/(24*time.Hour)))
+	}
+	return rng, nil
+}
+
+// formatStatsRange describes a range the way it would be typed, e.g. "7d"
+func formatStatsRange(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return d.String()
+}
+
+// sparkline renders values as unicode bars scaled to the largest value
+func sparkline(values []float64) string {
+	var max float64
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if max > 0 && v > 0 {
+			level = int(v / max * float64(len(sparkBlocks)-1))
+			if level == 0 {
+				// Keep small but non-zero values visible
+				level = 1
+			}
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// formatUsageTrend renders run volume, cost, error rate, and latency
+// sparklines with a total or average for each
+func formatUsageTrend(buckets []repository.UsageBucket) string {
+	runs := make([]float64, len(buckets))
+	cost := make([]float64, len(buckets))
+	errorRate := make([]float64, len(buckets))
+	latency := make([]float64, len(buckets))
+
+	var totalRuns, totalFailures, timedRuns int
+	var totalCost float64
+	var totalLatency time.Duration
+	for i, b := range buckets {
+		runs[i] = float64(b.Runs)
+		cost[i] = b.CostUSD
+		errorRate[i] = b.ErrorRate()
+		latency[i] = b.AvgLatency.Seconds()
+
+		totalRuns += b.Runs
+		totalFailures += b.Failures
+		totalCost += b.CostUSD
+		if b.AvgLatency > 0 {
+			totalLatency += b.AvgLatency * time.Duration(b.Runs)
+			timedRuns += b.Runs
+		}
+	}
+
+	overallErrorRate := 0.0
+	if attempts := totalRuns + totalFailures; attempts > 0 {
+		overallErrorRate = float64(totalFailures) / float64(attempts) * 100
+	}
+	avgLatency := "n/a"
+	if timedRuns > 0 {
+		avgLatency = formatElapsed(totalLatency / time.Duration(timedRuns))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "• Runs `%s` %d total\n", sparkline(runs), totalRuns)
+	fmt.Fprintf(&b, "• Cost `%s` $%.2f total\n", sparkline(cost), totalCost)
+	fmt.Fprintf(&b, "• Errors `%s` %.1f%% (%d failed)\n", sparkline(errorRate), overallErrorRate, totalFailures)
+	fmt.Fprintf(&b, "• Latency `%s` avg %s", sparkline(latency), avgLatency)
+	return b.String()
+}
+
+// formatUserCosts lists spend per person; unmapped runs are named with
+// displayName, since they're only known by Slack user ID
+func formatUserCosts(costs []repository.UserCost, displayName func(userID string) string) string {
+	if len(costs) == 0 {
+		return "_No attributed runs yet._"
+	}
+
+	lines := make([]string, 0, len(costs))
+	for _, cost := range costs {
+		who := fmt.Sprintf("`%s`", cost.Who)
+		if !cost.Mapped {
+			who = fmt.Sprintf("%s _(unmapped)_", displayName(cost.Who))
+		}
+		lines = append(lines, fmt.Sprintf("• %s: $%.2f over %d runs", who, cost.CostUSD, cost.Runs))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *Service) handleStatsCommand(ctx context.Context, req *commands.Request) (string, error) {
+	var arg string
+	if len(req.Args) > 0 {
+		arg = req.Args[0]
+	}
+	rng, err := parseStatsRange(arg)
+	if err != nil {
+		return fmt.Sprintf("❌ **Invalid range:** %v", err), nil
+	}
+
+	sessionStats := s.sessionManager.GetSessionStats()
+	authStats := s.authService.GetStats()
+
+	recentUsers := "none"
+	if names, ok := authStats["recent_users"].([]string); ok && len(names) > 0 {
+		recentUsers = strings.Join(names, ", ")
+	}
+
+	width := rng / statsPoints
+	trends := "_Trends are unavailable right now._"
+	buckets, err := s.usage.GetUsageTrend(time.Now().Add(-rng), width, statsPoints)
+	if err != nil {
+		s.logger.Error("Failed to load usage trend", zap.Duration("range", rng), zap.Error(err))
+	} else {
+		trends = formatUsageTrend(buckets)
+	}
+
+	spenders := "_Spend per person is unavailable right now._"
+	costs, err := s.usage.GetCostByUserSince(time.Now().Add(-rng), statsTopSpenders)
+	if err != nil {
+		s.logger.Error("Failed to load cost by user", zap.Duration("range", rng), zap.Error(err))
+	} else {
+		spenders = formatUserCosts(costs, s.authService.DisplayName)
+	}
+
+	return fmt.Sprintf(`📈 *Detailed Statistics*
+
+**Trends (last %s, %s per bar):**
+%s
+
+**Top Spenders (last %s):**
+%s
+
+**Sessions:**
+• Total: %v
+• Active: %v
+• Messages: %v
+
+**Users:**
+• Total: %v
+• Admins: %v
+• Banned: %v
+• Recently Active: %v
+
+**Channels:**
+• Total: %v
+
+**System:**
+• Uptime: %v
+• Auth Enabled: %v`,
+		formatStatsRange(rng),
+		formatElapsed(width),
+		trends,
+		formatStatsRange(rng),
+		spenders,
+		sessionStats["total_sessions"],
+		sessionStats["active_sessions"],
+		sessionStats["total_messages"],
+		authStats["total_users"],
+		authStats["admin_users"],
+		authStats["banned_users"],
+		recentUsers,
+		authStats["total_channels"],
+		time.Since(s.startTime).Truncate(time.Second),
+		authStats["auth_enabled"]), nil
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func TestParseStatsRange(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want time.Duration
+		ok   bool
+	}{
+		{"", defaultStatsRange, true},
+		{"24h", 24 * time.Hour, true},
+		{"7D", 7 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"0d", 0, false},
+		{"7", 0, false},
+		{"7m", 0, false},
+		{"91d", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseStatsRange(tt.arg)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseStatsRange(%q) = %v, %v; want %v, ok=%v", tt.arg, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFormatStatsRange(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		7 * 24 * time.Hour: "7d",
+		12 * time.Hour:     "12h",
+		90 * time.Minute:   "1h30m0s",
+	} {
+		if got := formatStatsRange(d); got != want {
+			t.Errorf("formatStatsRange(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{0, 1, 4, 8}); got != "▁▂▄█" {
+		t.Errorf("sparkline = %q, want ▁▂▄█", got)
+	}
+	if got := sparkline([]float64{0, 0.01, 100}); got != "▁▂█" {
+		t.Errorf("Small values should stay visible, got %q", got)
+	}
+	if got := sparkline([]float64{0, 0}); got != "▁▁" {
+		t.Errorf("All-zero sparkline = %q, want ▁▁", got)
+	}
+}
+
+func TestFormatUsageTrend(t *testing.T) {
+	out := formatUsageTrend([]repository.UsageBucket{
+		{Runs: 3, Failures: 1, CostUSD: 1.5, AvgLatency: 10 * time.Second},
+		{},
+		{Runs: 1, CostUSD: 0.25, AvgLatency: 30 * time.Second},
+	})
+	for _, want := range []string{
+		"Runs `█▁▃` 4 total",
+		"Cost `█▁▂` $1.75 total",
+		"Errors `█▁▁` 20.0% (1 failed)",
+		"Latency `▃▁█` avg 15s",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatUserCosts(t *testing.T) {
+	names := func(userID string) string { return "Sam" }
+	out := formatUserCosts([]repository.UserCost{
+		{Who: "jdoe", Mapped: true, Runs: 4, CostUSD: 2.5},
+		{Who: "U0SAM", Runs: 1, CostUSD: 0.1},
+	}, names)
+	want := "• `jdoe`: $2.50 over 4 runs\n• Sam _(unmapped)_: $0.10 over 1 runs"
+	if out != want {
+		t.Errorf("formatUserCosts() = %q, want %q", out, want)
+	}
+	if out := formatUserCosts(nil, names); out != "_No attributed runs yet._" {
+		t.Errorf("formatUserCosts(nil) = %q", out)
+	}
+}
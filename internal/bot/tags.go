@@ -0,0 +1,179 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+)
+
+const (
+	tagsUsage = "**Usage:** `/tag <label>` | `/tag remove <label>` | `/tag list [label]`"
+
+	tagListLimit = 20
+)
+
+// tagLabelPattern restricts labels to short slugs so they are easy to type back
+var tagLabelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// normalizeTagLabel lowercases a label and strips a leading '#'
+func normalizeTagLabel(label string) (string, bool) {
+	label = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(label), "#"))
+	return label, tagLabelPattern.MatchString(label)
+}
+
+// handleTagSlashCommand tags the latest exchange in the channel or lists tagged exchanges
+func (s *Service) handleTagSlashCommand(ctx context.Context, req *commands.Request) (string, error) {
+	switch req.Args[0] {
+	case "list":
+		if len(req.Args) == 1 {
+			return s.handleTagLabelsCommand(ctx, req), nil
+		}
+		return s.handleTagListCommand(ctx, req, req.Args[1]), nil
+	case "remove":
+		if len(req.Args) != 2 {
+			return "❌ **Invalid arguments**\n\n" + tagsUsage, nil
+		}
+		return s.handleTagRemoveCommand(ctx, req, req.Args[1]), nil
+	}
+
+	if len(req.Args) != 1 {
+		return "❌ **Labels can't contain spaces**\n\n" + tagsUsage, nil
+	}
+
+	label, ok := normalizeTagLabel(req.Args[0])
+	if !ok {
+		return "❌ **Invalid label**\n\nUse up to 50 lowercase letters, digits, `-` or `_`.", nil
+	}
+
+	child, err := s.tags.FindLatestChildInChannel(req.ChannelID, req.UserID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "tag_slash_command", "find_latest_exchange")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to find the latest exchange"), nil
+	}
+	if child == nil {
+		return "❌ **Nothing to tag**\n\nThe active session in this channel has no exchanges yet.", nil
+	}
+
+	created, err := s.tags.AddTag(child.ID, label, req.ChannelID, req.UserID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "tag_slash_command", "add_tag")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to add tag"), nil
+	}
+	if !created {
+		return fmt.Sprintf("ℹ️ The latest exchange is already tagged `%s`", label), nil
+	}
+
+	s.logger.Info("Exchange tagged",
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID),
+		zap.Int("child_session_id", child.ID),
+		zap.String("label", label))
+
+	return fmt.Sprintf("🏷️ **Tagged** `%s`\n\nExchange `%s`: %s\n\nFind it later with `/tag list %s`",
+		label, child.SessionID, exchangePreview(child.Summary, child.UserPrompt), label), nil
+}
+
+// handleTagRemoveCommand removes a label from the latest exchange in the channel
+func (s *Service) handleTagRemoveCommand(ctx context.Context, req *commands.Request, rawLabel string) string {
+	label, ok := normalizeTagLabel(rawLabel)
+	if !ok {
+		return "❌ **Invalid label**"
+	}
+
+	child, err := s.tags.FindLatestChildInChannel(req.ChannelID, req.UserID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "tag_slash_command", "find_latest_exchange")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to find the latest exchange")
+	}
+	if child == nil {
+		return "❌ **Nothing to untag**\n\nThe active session in this channel has no exchanges yet."
+	}
+
+	removed, err := s.tags.RemoveTag(child.ID, label)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "tag_slash_command", "remove_tag")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to remove tag")
+	}
+	if !removed {
+		return fmt.Sprintf("ℹ️ The latest exchange isn't tagged `%s`", label)
+	}
+
+	return fmt.Sprintf("✅ **Removed tag** `%s`", label)
+}
+
+// handleTagLabelsCommand lists labels in use across channels the user can access
+func (s *Service) handleTagLabelsCommand(ctx context.Context, req *commands.Request) string {
+	counts, err := s.tags.ListLabels(s.searchableChannels(req.UserID, req.ChannelID), tagListLimit)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "tag_slash_command", "list_labels")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list tags")
+	}
+
+	if len(counts) == 0 {
+		return "🏷️ **No tags yet**\n\n" + tagsUsage
+	}
+
+	var response strings.Builder
+	response.WriteString("🏷️ **Tags**\n\n")
+	for _, count := range counts {
+		response.WriteString(fmt.Sprintf("• `%s` (%d)\n", count.Label, count.Count))
+	}
+	response.WriteString("\n" + tagsUsage)
+
+	return response.String()
+}
+
+// handleTagListCommand lists exchanges carrying a label across sessions
+func (s *Service) handleTagListCommand(ctx context.Context, req *commands.Request, rawLabel string) string {
+	label, ok := normalizeTagLabel(rawLabel)
+	if !ok {
+		return "❌ **Invalid label**"
+	}
+
+	exchanges, err := s.tags.ListTaggedExchanges(label, s.searchableChannels(req.UserID, req.ChannelID), tagListLimit)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "tag_slash_command", "list_tagged")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list tagged exchanges")
+	}
+
+	if len(exchanges) == 0 {
+		return fmt.Sprintf("🏷️ **No exchanges tagged** `%s`", label)
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🏷️ **Exchanges tagged `%s`** (%d)\n\n", label, len(exchanges)))
+	for _, exchange := range exchanges {
+		response.WriteString(fmt.Sprintf("• %s in <#%s> by <@%s>\n  Session `%s` · `%s`\n  %s\n",
+			s.userTime(req.UserID, exchange.CreatedAt).Format("Jan 2 15:04"), exchange.ChannelID, exchange.TaggedBy,
+			exchange.SessionID, exchange.WorkingDirectory,
+			exchangePreview(exchange.Summary, exchange.UserPrompt)))
+	}
+	response.WriteString("\nSwitch with `/session <session-id>`")
+
+	return response.String()
+}
+
+// exchangePreview describes an exchange by its summary, falling back to the prompt
+func exchangePreview(summary, prompt *string) string {
+	text := ""
+	if summary != nil && *summary != "" {
+		text = *summary
+	} else if prompt != nil {
+		text = *prompt
+	}
+
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return "_no prompt recorded_"
+	}
+	if runes := []rune(text); len(runes) > 120 {
+		text = string(runes[:120]) + "…"
+	}
+	return "_" + text + "_"
+}
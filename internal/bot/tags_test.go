@@ -0,0 +1,25 @@
+package bot
+
+import "testing"
+
+func TestNormalizeTagLabel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		valid bool
+	}{
+		{"bugfix", "bugfix", true},
+		{"#Deploy-Notes", "deploy-notes", true},
+		{"  perf_2024 ", "perf_2024", true},
+		{"-leading", "-leading", false},
+		{"has space", "has space", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, valid := normalizeTagLabel(tt.input)
+		if got != tt.want || valid != tt.valid {
+			t.Errorf("normalizeTagLabel(%q) = %q, %v; want %q, %v", tt.input, got, valid, tt.want, tt.valid)
+		}
+	}
+}
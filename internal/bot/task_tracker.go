@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+)
+
+// taskTrackerMinInterval is the minimum time between edits of a checklist,
+// however often Claude updates its task list
+const taskTrackerMinInterval = 2 * time.Second
+
+// taskTracker mirrors Claude's TodoWrite task list into a Slack checklist
+// message that is edited in place as steps complete. Updates arrive from the
+// CLI output reader and are posted from the tracker's own goroutine so the
+// run is never blocked on Slack.
+type taskTracker struct {
+	s         *Service
+	channelID string
+	threadTS  string
+
+	mu     sync.Mutex
+	latest []claude.Todo
+
+	updated  chan struct{}
+	done     chan struct{}
+	finished chan struct{}
+
+	messageTS string
+	rendered  string
+}
+
+// startTaskTracker starts a tracker for a run; the checklist is only posted
+// once Claude writes a task list
+func (s *Service) startTaskTracker(channelID, threadTS string) *taskTracker {
+	t := &taskTracker{
+		s:         s,
+		channelID: channelID,
+		threadTS:  threadTS,
+		updated:   make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		finished:  make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// update records Claude's latest task list; it never blocks
+func (t *taskTracker) update(todos []claude.Todo) {
+	t.mu.Lock()
+	t.latest = todos
+	t.mu.Unlock()
+
+	select {
+	case t.updated <- struct{}{}:
+	default:
+	}
+}
+
+// finish publishes the final task list and stops the tracker
+func (t *taskTracker) finish() {
+	close(t.done)
+	<-t.finished
+}
+
+func (t *taskTracker) run() {
+	defer close(t.finished)
+
+	for {
+		select {
+		case <-t.updated:
+			t.flush()
+			select {
+			case <-time.After(taskTrackerMinInterval):
+			case <-t.done:
+				t.flush()
+				return
+			}
+		case <-t.done:
+			t.flush()
+			return
+		}
+	}
+}
+
+// flush posts the checklist, or edits it if it changed since the last post
+func (t *taskTracker) flush() {
+	t.mu.Lock()
+	text := renderTaskChecklist(t.latest)
+	t.mu.Unlock()
+
+	if text == "" || text == t.rendered {
+		return
+	}
+
+	ctx := context.Background()
+	if t.messageTS == "" {
+		opts := []slack.MsgOption{slack.MsgOptionText(text, false)}
+		if t.threadTS != "" {
+			opts = append(opts, slack.MsgOptionTS(t.threadTS))
+		}
+		messageTS, err := t.s.sender.PostMessage(ctx, t.channelID, opts...)
+		if err != nil {
+			t.s.logger.Warn("Failed to post task checklist", zap.String("channel_id", t.channelID), zap.Error(err))
+			return
+		}
+		t.messageTS = messageTS
+	} else if err := t.s.sender.UpdateMessage(ctx, t.channelID, t.messageTS, slack.MsgOptionText(text, false)); err != nil {
+		t.s.logger.Warn("Failed to update task checklist", zap.String("channel_id", t.channelID), zap.Error(err))
+		return
+	}
+	t.rendered = text
+}
+
+// renderTaskChecklist formats a task list as a checklist, or "" if it is empty
+func renderTaskChecklist(todos []claude.Todo) string {
+	if len(todos) == 0 {
+		return ""
+	}
+
+	completed := 0
+	var lines strings.Builder
+	for _, todo := range todos {
+		switch todo.Status {
+		case claude.TodoCompleted:
+			completed++
+			lines.WriteString(fmt.Sprintf("\n✅ ~%s~", todo.Content))
+		case claude.TodoInProgress:
+			label := todo.ActiveForm
+			if label == "" {
+				label = todo.Content
+			}
+			lines.WriteString(fmt.Sprintf("\n🔄 *%s*", label))
+		default:
+			lines.WriteString(fmt.Sprintf("\n⬜ %s", todo.Content))
+		}
+	}
+
+	return fmt.Sprintf("📋 *Task progress* (%d/%d done)%s", completed, len(todos), lines.String())
+}
@@ -0,0 +1,23 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+)
+
+func TestRenderTaskChecklist(t *testing.T) {
+	if got := renderTaskChecklist(nil); got != "" {
+		t.Errorf("renderTaskChecklist(nil) = %q, want empty", got)
+	}
+
+	todos := []claude.Todo{
+		{Content: "Read the handler", Status: claude.TodoCompleted, ActiveForm: "Reading the handler"},
+		{Content: "Fix the bug", Status: claude.TodoInProgress, ActiveForm: "Fixing the bug"},
+		{Content: "Run tests", Status: claude.TodoPending, ActiveForm: "Running tests"},
+	}
+	want := "📋 *Task progress* (1/3 done)\n✅ ~Read the handler~\n🔄 *Fixing the bug*\n⬜ Run tests"
+	if got := renderTaskChecklist(todos); got != want {
+		t.Errorf("renderTaskChecklist() =\n%s\nwant\n%s", got, want)
+	}
+}
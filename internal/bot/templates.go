@@ -0,0 +1,323 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const templateUsage = "**Usage:**\n" +
+	"• `/template list` - Your templates and this channel's\n" +
+	"• `/template save [channel] <name> <prompt>` - Save a template, e.g. `/template save deploy-check \"Check deployment status of {service} in {env}\"`\n" +
+	"• `/template show <name>` - Show a template and its variables\n" +
+	"• `/template run <name> [var=value ...]` - Run it, e.g. `/template run deploy-check service=api env=prod`\n" +
+	"• `/template delete [channel] <name>` - Delete a template\n\n" +
+	"Templates are yours unless saved with `channel`, which shares them with everyone here and requires write permission."
+
+// maxPromptTemplateLength keeps templates to a prompt, not a document
+const maxPromptTemplateLength = 4000
+
+var (
+	templateNamePattern     = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+	templateVariablePattern = regexp.MustCompile(`\{([a-zA-Z][a-zA-Z0-9_]*)\}`)
+)
+
+// templateVariables returns the distinct {variables} in a template, in the
+// order they first appear
+func templateVariables(template string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range templateVariablePattern.FindAllStringSubmatch(template, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// unquote strips one pair of matching straight or curly quotes, which Slack
+// substitutes for straight ones as people type
+func unquote(s string) string {
+	for _, pair := range [][2]string{{`"`, `"`}, {`'`, `'`}, {"“", "”"}, {"‘", "’"}} {
+		if len(s) >= len(pair[0])+len(pair[1]) && strings.HasPrefix(s, pair[0]) && strings.HasSuffix(s, pair[1]) {
+			return s[len(pair[0]) : len(s)-len(pair[1])]
+		}
+	}
+	return s
+}
+
+// parseTemplateSave extracts the name and prompt from
+// `deploy-check "Check deployment status of {service} in {env}"`
+func parseTemplateSave(text string) (string, string, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", "", fmt.Errorf("no template name given")
+	}
+	name := strings.ToLower(fields[0])
+	if !templateNamePattern.MatchString(name) {
+		return "", "", fmt.Errorf("%q isn't a valid name; use up to 50 letters, digits, `-`, or `_`", fields[0])
+	}
+
+	template := strings.TrimSpace(unquote(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), fields[0]))))
+	if template == "" {
+		return "", "", fmt.Errorf("no prompt given")
+	}
+	if len(template) > maxPromptTemplateLength {
+		return "", "", fmt.Errorf("prompt is %d characters; keep it under %d", len(template), maxPromptTemplateLength)
+	}
+	return name, template, nil
+}
+
+// parseTemplateArgs parses `service=api env="prod east"` into variable
+// values. Values with spaces must be quoted.
+func parseTemplateArgs(text string) (map[string]string, error) {
+	values := make(map[string]string)
+	rest := strings.TrimSpace(text)
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+		if eq <= 0 || strings.ContainsAny(rest[:eq], " \t\n") {
+			field := strings.Fields(rest)[0]
+			return nil, fmt.Errorf("expected `name=value`, got `%s`", field)
+		}
+		name := rest[:eq]
+		rest = rest[eq+1:]
+
+		var value string
+		if closing, ok := quoteClosers[firstRune(rest)]; ok {
+			open := len(string(firstRune(rest)))
+			end := strings.Index(rest[open:], closing)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in the value of %s", name)
+			}
+			value = rest[open : open+end]
+			rest = rest[open+end+len(closing):]
+		} else if end := strings.IndexAny(rest, " \t\n"); end >= 0 {
+			value, rest = rest[:end], rest[end:]
+		} else {
+			value, rest = rest, ""
+		}
+
+		if _, dup := values[name]; dup {
+			return nil, fmt.Errorf("%s is given twice", name)
+		}
+		values[name] = value
+		rest = strings.TrimSpace(rest)
+	}
+	return values, nil
+}
+
+// quoteClosers maps the quotes a value can start with to the one ending it
+var quoteClosers = map[rune]string{'"': `"`, '\'': `'`, '“': "”", '‘': "’"}
+
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+// expandTemplate fills in a template's variables. Every variable must be
+// given, and nothing else.
+func expandTemplate(template string, values map[string]string) (string, error) {
+	variables := templateVariables(template)
+	known := make(map[string]bool, len(variables))
+	var missing []string
+	for _, name := range variables {
+		known[name] = true
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return "", fmt.Errorf("the template has no %s", strings.Join(unknown, ", "))
+	}
+
+	return templateVariablePattern.ReplaceAllStringFunc(template, func(match string) string {
+		return values[match[1:len(match)-1]]
+	}), nil
+}
+
+// formatTemplateVariables renders "{service}, {env}" for listings
+func formatTemplateVariables(template string) string {
+	variables := templateVariables(template)
+	if len(variables) == 0 {
+		return "no variables"
+	}
+	for i, name := range variables {
+		variables[i] = "`" + name + "`"
+	}
+	return strings.Join(variables, ", ")
+}
+
+// handleTemplateCommand handles /template list, save, show, run, and delete
+func (s *Service) handleTemplateCommand(ctx context.Context, req *commands.Request) (string, error) {
+	if len(req.Args) == 0 {
+		return templateUsage, nil
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(req.Text, req.Args[0]))
+
+	switch req.Args[0] {
+	case "list":
+		return s.handleTemplateListCommand(ctx, req), nil
+	case "save", "delete":
+		scope, scopeID := repository.TemplateScopeUser, req.UserID
+		if len(req.Args) > 1 && req.Args[1] == "channel" {
+			authCtx := &auth.AuthContext{UserID: req.UserID, ChannelID: req.ChannelID, Command: "/template", Timestamp: time.Now()}
+			if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+				return fmt.Sprintf("❌ Authorization failed: %v", err), nil
+			}
+			scope, scopeID = repository.TemplateScopeChannel, req.ChannelID
+			rest = strings.TrimSpace(strings.TrimPrefix(rest, "channel"))
+		}
+
+		if req.Args[0] == "delete" {
+			if len(strings.Fields(rest)) != 1 {
+				return "❌ **Invalid arguments**\n\n" + templateUsage, nil
+			}
+			return s.handleTemplateDeleteCommand(ctx, req, scope, scopeID, strings.ToLower(rest)), nil
+		}
+		name, template, err := parseTemplateSave(rest)
+		if err != nil {
+			return fmt.Sprintf("❌ **Invalid template:** %v\n\n%s", err, templateUsage), nil
+		}
+		return s.handleTemplateSaveCommand(ctx, req, scope, scopeID, name, template), nil
+	case "show", "run":
+		if len(req.Args) < 2 || (req.Args[0] == "show" && len(req.Args) != 2) {
+			return "❌ **Invalid arguments**\n\n" + templateUsage, nil
+		}
+		name := strings.ToLower(req.Args[1])
+		template, err := s.templates.GetPromptTemplate(req.UserID, req.ChannelID, name)
+		if err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "template_command", "get_template")
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to load template"), nil
+		}
+		if template == nil {
+			return fmt.Sprintf("❌ **Template not found:** `%s`\n\nUse `/template list` to see the templates you can run here.", name), nil
+		}
+		if req.Args[0] == "show" {
+			return fmt.Sprintf("📝 **Template `%s`** (%s)\n```\n%s\n```\nVariables: %s",
+				template.Name, template.Scope, template.Template, formatTemplateVariables(template.Template)), nil
+		}
+		return s.handleTemplateRunCommand(req, template, strings.TrimSpace(strings.TrimPrefix(rest, req.Args[1]))), nil
+	}
+	return templateUsage, nil
+}
+
+func (s *Service) handleTemplateListCommand(ctx context.Context, req *commands.Request) string {
+	templates, err := s.templates.ListPromptTemplates(req.UserID, req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "template_command", "list_templates")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to list templates")
+	}
+	if len(templates) == 0 {
+		return "ℹ️ **No templates yet**\n\nSave one with `/template save <name> <prompt>`."
+	}
+
+	var response strings.Builder
+	response.WriteString("📝 **Prompt Templates**\n")
+	for _, t := range templates {
+		fmt.Fprintf(&response, "\n• `%s` _(%s)_ - %s", t.Name, t.Scope, formatTemplateVariables(t.Template))
+	}
+	response.WriteString("\n\nYour templates take precedence over the channel's with the same name. Run one with `/template run <name> var=value`.")
+	return response.String()
+}
+
+func (s *Service) handleTemplateSaveCommand(ctx context.Context, req *commands.Request, scope, scopeID, name, template string) string {
+	if err := s.templates.SavePromptTemplate(scope, scopeID, name, template, req.UserID); err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "template_command", "save_template")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to save template")
+	}
+	return fmt.Sprintf("✅ **Template `%s` saved** (%s)\n\nVariables: %s\nRun it with `/template run %s%s`",
+		name, scope, formatTemplateVariables(template), name, exampleTemplateArgs(template))
+}
+
+// exampleTemplateArgs renders " service=… env=…" for a template's variables
+func exampleTemplateArgs(template string) string {
+	var args strings.Builder
+	for _, name := range templateVariables(template) {
+		fmt.Fprintf(&args, " %s=…", name)
+	}
+	return args.String()
+}
+
+func (s *Service) handleTemplateDeleteCommand(ctx context.Context, req *commands.Request, scope, scopeID, name string) string {
+	deleted, err := s.templates.DeletePromptTemplate(scope, scopeID, name)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "template_command", "delete_template")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to delete template")
+	}
+	if !deleted {
+		return fmt.Sprintf("ℹ️ There is no %s template named `%s`", scope, name)
+	}
+	return fmt.Sprintf("✅ **Template `%s` deleted** (%s)", name, scope)
+}
+
+// handleTemplateRunCommand fills in a template and runs it in a new thread,
+// like a request from the composer
+func (s *Service) handleTemplateRunCommand(req *commands.Request, template *repository.PromptTemplate, args string) string {
+	values, err := parseTemplateArgs(args)
+	if err != nil {
+		return fmt.Sprintf("❌ **Invalid arguments:** %v\n\n%s", err, templateUsage)
+	}
+	prompt, err := expandTemplate(template.Template, values)
+	if err != nil {
+		return fmt.Sprintf("❌ **Can't run `%s`:** %v\n\nRun it with `/template run %s%s`", template.Name, err, template.Name, exampleTemplateArgs(template.Template))
+	}
+
+	threadTS := s.sendThreadResponse(req.ChannelID, "", fmt.Sprintf("📝 <@%s> ran template `%s`:\n>>> %s", req.UserID, template.Name, prompt))
+	if threadTS == "" {
+		return "❌ **Error:** Couldn't post your request here. Invite the bot to the channel and try again."
+	}
+
+	s.logger.Info("Running prompt template",
+		zap.String("template", template.Name),
+		zap.String("scope", template.Scope),
+		zap.String("user_id", req.UserID),
+		zap.String("channel_id", req.ChannelID))
+
+	go s.runTemplatePrompt(req.UserID, req.ChannelID, threadTS, prompt)
+	return ""
+}
+
+// runTemplatePrompt runs an expanded template and replies in its thread
+func (s *Service) runTemplatePrompt(userID, channelID, threadTS, prompt string) {
+	event := &slackevents.MessageEvent{
+		Type:            "message",
+		User:            userID,
+		Text:            prompt,
+		TimeStamp:       threadTS,
+		ThreadTimeStamp: threadTS,
+		Channel:         channelID,
+	}
+
+	started := time.Now()
+	if response := s.processClaudeMessage(context.Background(), event, prompt, runOverrides{}); response != "" {
+		// A held response isn't in Slack yet, so there is nothing to link to
+		if responseTS, held := s.sendThreadResponseOrHold(channelID, threadTS, response); !held {
+			s.notifyIfSlow(userID, channelID, responseTS, time.Since(started))
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTemplateSave(t *testing.T) {
+	tests := map[string][2]string{
+		`deploy-check "Check deployment status of {service} in {env}"`: {"deploy-check", "Check deployment status of {service} in {env}"},
+		"Triage Triage the latest errors in {service}":                 {"triage", "Triage the latest errors in {service}"},
+		"standup “Summarize yesterday's merged PRs”":                   {"standup", "Summarize yesterday's merged PRs"},
+	}
+	for text, want := range tests {
+		name, template, err := parseTemplateSave(text)
+		if err != nil || name != want[0] || template != want[1] {
+			t.Errorf("parseTemplateSave(%q) = %q, %q, %v", text, name, template, err)
+		}
+	}
+
+	for _, text := range []string{
+		"",
+		"deploy-check",
+		`deploy-check ""`,
+		"deploy/check Check it",
+		"deploy-check " + strings.Repeat("x", maxPromptTemplateLength+1),
+	} {
+		if _, _, err := parseTemplateSave(text); err == nil {
+			t.Errorf("parseTemplateSave(%q) expected an error", text)
+		}
+	}
+}
+
+func TestParseTemplateArgs(t *testing.T) {
+	got, err := parseTemplateArgs(`service=api env="prod east" note=“it's slow” empty=`)
+	if err != nil {
+		t.Fatalf("parseTemplateArgs() error = %v", err)
+	}
+	want := map[string]string{"service": "api", "env": "prod east", "note": "it's slow", "empty": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTemplateArgs() = %v, want %v", got, want)
+	}
+
+	for _, text := range []string{
+		"api",
+		"=api",
+		`env="prod`,
+		"env=prod env=staging",
+	} {
+		if _, err := parseTemplateArgs(text); err == nil {
+			t.Errorf("parseTemplateArgs(%q) expected an error", text)
+		}
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	template := "Check deployment status of {service} in {env}, then compare {env} with staging"
+	if vars := templateVariables(template); !reflect.DeepEqual(vars, []string{"service", "env"}) {
+		t.Errorf("templateVariables() = %v", vars)
+	}
+
+	got, err := expandTemplate(template, map[string]string{"service": "api", "env": "prod"})
+	if err != nil {
+		t.Fatalf("expandTemplate() error = %v", err)
+	}
+	if want := "Check deployment status of api in prod, then compare prod with staging"; got != want {
+		t.Errorf("expandTemplate() = %q, want %q", got, want)
+	}
+
+	if _, err := expandTemplate(template, map[string]string{"service": "api"}); err == nil || !strings.Contains(err.Error(), "missing env") {
+		t.Errorf("Expected a missing variable error, got %v", err)
+	}
+	if _, err := expandTemplate(template, map[string]string{"service": "api", "env": "prod", "region": "eu"}); err == nil || !strings.Contains(err.Error(), "region") {
+		t.Errorf("Expected an unknown variable error, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+const (
+	// thinkingJanitorInterval is how often stale thinking messages are swept
+	thinkingJanitorInterval = time.Minute
+
+	interruptedMessage = "⚠️ _Interrupted_ - this request stopped before Claude replied. Please send it again."
+)
+
+// postThinkingMessage posts the "Thinking..." placeholder and records it so
+// the janitor can clean it up if the run never clears it. It returns the
+// message timestamp, or "" if posting failed.
+func (s *Service) postThinkingMessage(channelID, threadTS, sessionID, text string) string {
+	opts := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if threadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(threadTS))
+	}
+	messageTS, err := s.sender.PostMessage(context.Background(), channelID, opts...)
+	if err != nil {
+		s.logger.Error("Failed to send thinking message", zap.Error(err))
+		return ""
+	}
+
+	runID := uuid.New().String()
+	if err := s.thinking.RecordThinkingMessage(channelID, messageTS, runID, sessionID, s.config.InstanceName); err != nil {
+		s.logger.Warn("Failed to record thinking message", zap.String("run_id", runID), zap.Error(err))
+	}
+
+	return messageTS
+}
+
+// clearThinkingMessage deletes a thinking message once its run is done. It is
+// safe to call more than once; only the first call touches Slack.
+func (s *Service) clearThinkingMessage(channelID, messageTS string) {
+	if messageTS == "" {
+		return
+	}
+
+	removed, err := s.thinking.RemoveThinkingMessage(channelID, messageTS)
+	if err != nil {
+		s.logger.Warn("Failed to remove thinking message record", zap.Error(err))
+	} else if !removed {
+		return
+	}
+
+	if err := s.sender.DeleteMessage(context.Background(), channelID, messageTS); err != nil {
+		s.logger.Debug("Failed to delete thinking message", zap.Error(err))
+	}
+}
+
+// thinkingJanitorLoop marks thinking messages this instance left behind
+// before it restarted on startup, then keeps sweeping ones that outlived their
+// run. Other instances' messages are only swept once older than
+// THINKING_TIMEOUT, since a newer one may belong to a run still going there.
+func (s *Service) thinkingJanitorLoop() {
+	if s.config.InstanceName != "" {
+		s.sweepThinkingMessages(s.startTime, s.config.InstanceName)
+	}
+	s.sweepThinkingMessages(time.Now().Add(-s.config.ThinkingTimeout), "")
+
+	ticker := time.NewTicker(thinkingJanitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if stuck := s.sweepThinkingMessages(time.Now().Add(-s.config.ThinkingTimeout), ""); len(stuck) > 0 {
+				s.alertStuckRuns(stuck)
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// sweepThinkingMessages edits thinking messages posted before cutoff, by
+// owner unless it is "", into an interrupted notice and stops tracking them.
+// It returns the messages swept.
+func (s *Service) sweepThinkingMessages(cutoff time.Time, owner string) []*repository.ThinkingMessage {
+	messages, err := s.thinking.ListThinkingMessagesBefore(cutoff, owner)
+	if err != nil {
+		s.logger.Error("Failed to list stale thinking messages", zap.Error(err))
+		return nil
+	}
+
+	var swept []*repository.ThinkingMessage
+
+	for _, message := range messages {
+		// Remove first so a run finishing concurrently doesn't also edit it
+		removed, err := s.thinking.RemoveThinkingMessage(message.ChannelID, message.MessageTS)
+		if err != nil {
+			s.logger.Error("Failed to remove stale thinking message record", zap.Error(err))
+			continue
+		}
+		if !removed {
+			continue
+		}
+		swept = append(swept, message)
+
+		if err := s.sender.UpdateMessage(context.Background(), message.ChannelID, message.MessageTS,
+			slack.MsgOptionText(interruptedMessage, false)); err != nil {
+			s.logger.Warn("Failed to mark thinking message interrupted",
+				zap.String("channel_id", message.ChannelID),
+				zap.String("message_ts", message.MessageTS),
+				zap.Error(err))
+			continue
+		}
+
+		s.logger.Info("Marked stale thinking message interrupted",
+			zap.String("channel_id", message.ChannelID),
+			zap.String("message_ts", message.MessageTS),
+			zap.String("run_id", message.RunID),
+			zap.Duration("age", time.Since(message.CreatedAt)))
+	}
+	return swept
+}
@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+// maxToolEventPosts caps the tool events posted for one run; the rest are
+// counted in a closing note so long runs don't flood the thread
+const maxToolEventPosts = 25
+
+// toolEventLog posts a run's major tool calls (commands run, files edited,
+// sub-agents started) as compact thread replies while the run is in
+// progress. Calls arrive from the CLI output reader and are posted from the
+// log's own goroutine so the run is never blocked on Slack.
+type toolEventLog struct {
+	s         *Service
+	channelID string
+	threadTS  string
+	workDir   string
+
+	mu      sync.Mutex
+	posted  int
+	skipped int
+
+	events   chan string
+	finished chan struct{}
+}
+
+// toolEventsEnabled reports whether runs in a channel post tool events: its
+// tool_events setting, else TOOL_EVENTS or the streaming flag
+func (s *Service) toolEventsEnabled(settings *repository.ChannelSettings) bool {
+	if settings.ToolEvents != nil {
+		return *settings.ToolEvents
+	}
+	return s.config.ToolEvents || s.featureEnabled(flagStreaming, settings.ChannelID)
+}
+
+// startToolEventLog starts posting a run's tool events to a thread
+func (s *Service) startToolEventLog(channelID, threadTS, workDir string) *toolEventLog {
+	l := &toolEventLog{
+		s:         s,
+		channelID: channelID,
+		threadTS:  threadTS,
+		workDir:   workDir,
+		events:    make(chan string, maxToolEventPosts),
+		finished:  make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// record queues a tool call for posting if it is one worth showing; it never
+// blocks
+func (l *toolEventLog) record(run claude.ToolRun) {
+	// Commands go through the same redaction as responses before they are
+	// shortened, so a secret isn't cut past recognition
+	if run.Command != "" {
+		command, ok := l.s.redactToolEvent(context.Background(), l.channelID, run.Command)
+		if !ok {
+			l.mu.Lock()
+			l.skipped++
+			l.mu.Unlock()
+			return
+		}
+		run.Command = command
+	}
+
+	text, ok := describeToolEvent(run, l.workDir)
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.posted >= maxToolEventPosts {
+		l.skipped++
+		return
+	}
+	select {
+	case l.events <- text:
+		l.posted++
+	default:
+		l.skipped++
+	}
+}
+
+// finish posts what's queued, notes how many events weren't shown, and stops
+// the log, so the final response comes after the last event
+func (l *toolEventLog) finish() {
+	close(l.events)
+	<-l.finished
+
+	l.mu.Lock()
+	skipped := l.skipped
+	l.mu.Unlock()
+	if skipped > 0 {
+		l.s.sendThreadResponse(l.channelID, l.threadTS, fmt.Sprintf("_…and %d more tool call(s) not shown_", skipped))
+	}
+}
+
+func (l *toolEventLog) run() {
+	defer close(l.finished)
+	for text := range l.events {
+		l.s.sendThreadResponse(l.channelID, l.threadTS, text)
+	}
+}
+
+// describeToolEvent formats a tool call as a one-line thread reply. Only
+// calls that change things or hand off work are shown; reads and searches
+// are too frequent to be useful mid-run.
+func describeToolEvent(run claude.ToolRun, workDir string) (string, bool) {
+	switch run.Name {
+	case "Bash":
+		if run.Command == "" {
+			return "", false
+		}
+		return fmt.Sprintf("🔧 Running `%s`", strings.ReplaceAll(truncatePrompt(run.Command), "`", "'")), true
+	case "Edit", "MultiEdit", "NotebookEdit", "Write":
+		if run.FilePath == "" {
+			return "", false
+		}
+		verb := "✏️ Editing"
+		if run.Name == "Write" {
+			verb = "📝 Writing"
+		}
+		return fmt.Sprintf("%s `%s`", verb, displayToolPath(run.FilePath, workDir)), true
+	case "Task":
+		if run.Agent == "" {
+			return "🤖 Handing off to a sub-agent", true
+		}
+		return fmt.Sprintf("🤖 Handing off to `%s`", run.Agent), true
+	}
+	return "", false
+}
+
+// displayToolPath shows a file relative to the working directory when it is
+// inside it
+func displayToolPath(path, workDir string) string {
+	if workDir == "" {
+		return path
+	}
+	rel, err := filepath.Rel(workDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/claude"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func TestDescribeToolEvent(t *testing.T) {
+	tests := []struct {
+		run    claude.ToolRun
+		want   string
+		wantOK bool
+	}{
+		{claude.ToolRun{Name: "Bash", Command: "go test   ./...\n"}, "🔧 Running `go test ./...`", true},
+		{claude.ToolRun{Name: "Bash", Command: "echo `date`"}, "🔧 Running `echo 'date'`", true},
+		{claude.ToolRun{Name: "Edit", FilePath: "/work/repo/internal/bot/service.go"}, "✏️ Editing `internal/bot/service.go`", true},
+		{claude.ToolRun{Name: "Write", FilePath: "/etc/hosts"}, "📝 Writing `/etc/hosts`", true},
+		{claude.ToolRun{Name: "Task", Agent: "reviewer"}, "🤖 Handing off to `reviewer`", true},
+		{claude.ToolRun{Name: "Read", FilePath: "/work/repo/go.mod"}, "", false},
+		{claude.ToolRun{Name: "Grep"}, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := describeToolEvent(tt.run, "/work/repo")
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("describeToolEvent(%+v) = %q, %v; want %q, %v", tt.run, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestToolEventsEnabled(t *testing.T) {
+	s := &Service{config: &config.Config{ToolEvents: true}}
+	off := false
+	if !s.toolEventsEnabled(&repository.ChannelSettings{}) {
+		t.Error("Expected tool events from TOOL_EVENTS")
+	}
+	if s.toolEventsEnabled(&repository.ChannelSettings{ToolEvents: &off}) {
+		t.Error("Expected the channel's setting to win over TOOL_EVENTS")
+	}
+}
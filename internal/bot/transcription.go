@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/files"
+)
+
+// defaultRecordingPrompt is used when a recording is posted without a message
+const defaultRecordingPrompt = "Summarize the decisions and action items from this recording."
+
+// transcribeAttachment downloads an audio attachment and transcribes it. The
+// transcript is saved next to the recording so Claude can read it however
+// long it is; both files are returned for cleanup.
+func (s *Service) transcribeAttachment(ctx context.Context, channelID, threadTS, userID, fileID, fileName string) ([]*files.FileInfo, string, error) {
+	fileInfo, err := s.fileDownloader.DownloadFile(fileID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	downloaded := []*files.FileInfo{fileInfo}
+
+	// Transcription can take a while, so let the user know it's happening
+	statusOpts := []slack.MsgOption{slack.MsgOptionText(fmt.Sprintf("🎙️ _Transcribing `%s`..._", fileName), false)}
+	if threadTS != "" {
+		statusOpts = append(statusOpts, slack.MsgOptionTS(threadTS))
+	}
+	statusTS, err := s.sender.PostMessage(ctx, channelID, statusOpts...)
+	if err != nil {
+		s.logger.Warn("Failed to post transcription status", zap.Error(err))
+	} else {
+		defer func() {
+			if err := s.sender.DeleteMessage(context.Background(), channelID, statusTS); err != nil {
+				s.logger.Warn("Failed to delete transcription status", zap.Error(err))
+			}
+		}()
+	}
+
+	transcript, err := s.transcriber.Transcribe(ctx, fileInfo.LocalPath)
+	if err != nil {
+		return downloaded, "", fmt.Errorf("failed to transcribe %s: %w", fileName, err)
+	}
+
+	transcriptPath := fileInfo.LocalPath + ".transcript.txt"
+	if err := os.WriteFile(transcriptPath, []byte(transcript+"\n"), 0600); err != nil {
+		return downloaded, "", fmt.Errorf("failed to save transcript: %w", err)
+	}
+	downloaded = append(downloaded, &files.FileInfo{
+		LocalPath:    transcriptPath,
+		OriginalName: fileName + ".transcript.txt",
+		MimeType:     "text/plain",
+		Size:         int64(len(transcript) + 1),
+	})
+
+	prompt := fmt.Sprintf("The file %s contains a transcript of the recording %q", transcriptPath, fileName)
+	return downloaded, prompt, nil
+}
@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+)
+
+const (
+	// trashPurgeInterval is how often sessions past their trash retention are purged
+	trashPurgeInterval = time.Hour
+
+	// trashListLimit is how many trashed sessions /session trash shows
+	trashListLimit = 15
+)
+
+// trashPurgeLoop permanently deletes sessions that have been in the trash
+// longer than SESSION_TRASH_RETENTION, until stopped
+func (s *Service) trashPurgeLoop() {
+	trashMgr, ok := s.sessionManager.(session.SessionTrashManager)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			purged, err := trashMgr.PurgeTrashedSessions(time.Now().Add(-s.config.SessionTrashRetention))
+			if err != nil {
+				s.logger.Error("Failed to purge trashed sessions", zap.Error(err))
+			}
+			if len(purged) > 0 {
+				s.logger.Info("Purged trashed sessions", zap.Strings("session_ids", purged))
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// formatRetention describes a trash retention period, e.g. "30 days"
+func formatRetention(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		days := int(d / (24 * time.Hour))
+		if days == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", days)
+	}
+	return d.String()
+}
+
+// handleSessionTrashCommand lists sessions in the trash
+func (s *Service) handleSessionTrashCommand(userID, channelID string, args []string) string {
+	if len(args) > 1 || (len(args) == 1 && args[0] != "list") {
+		return "❌ **Usage:** `/session trash list` - Show deleted sessions that can still be restored"
+	}
+
+	trashMgr, ok := s.sessionManager.(session.SessionTrashManager)
+	if !ok {
+		return "❌ **The trash requires database persistence**"
+	}
+
+	trashed, err := trashMgr.ListTrashedSessions(trashListLimit)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_trash_command", "list_trashed_sessions")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to list trashed sessions")
+	}
+	if len(trashed) == 0 {
+		return "🗑️ **The trash is empty**"
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🗑️ **Deleted Sessions** (purged %s after deletion)\n", formatRetention(s.config.SessionTrashRetention)))
+	for _, trashedSession := range trashed {
+		response.WriteString(fmt.Sprintf("\n• `%s` - %s, %d exchange(s), deleted %s",
+			trashedSession.SessionID, trashedSession.WorkingDirectory, trashedSession.Exchanges,
+			s.userTime(userID, trashedSession.DeletedAt).Format("Jan 2 15:04")))
+		if trashedSession.DeletedBy != nil {
+			response.WriteString(fmt.Sprintf(" by <@%s>", *trashedSession.DeletedBy))
+		}
+		if trashedSession.UserPrompt != nil && *trashedSession.UserPrompt != "" {
+			response.WriteString("\n   " + exchangePreview(nil, trashedSession.UserPrompt))
+		}
+	}
+	response.WriteString("\n\nRestore one with `/session restore <session-id>`.")
+	return response.String()
+}
+
+// handleSessionRestoreCommand takes a session out of the trash
+func (s *Service) handleSessionRestoreCommand(userID, channelID string, args []string) string {
+	if len(args) != 1 {
+		return "❌ **Usage:** `/session restore <session-id>` - Restore a deleted session"
+	}
+	sessionID := args[0]
+
+	authCtx := &auth.AuthContext{UserID: userID, ChannelID: channelID, Command: "/session", Timestamp: time.Now()}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+		return fmt.Sprintf("❌ Authorization failed: %v", err)
+	}
+
+	trashMgr, ok := s.sessionManager.(session.SessionTrashManager)
+	if !ok {
+		return "❌ **The trash requires database persistence**"
+	}
+
+	err := trashMgr.RestoreSession(sessionID)
+	if errors.Is(err, session.ErrSessionNotFound) {
+		return fmt.Sprintf("❌ **Not in the trash**\n\nSession `%s` isn't deleted, or was already purged. See `/session trash list`.", sessionID)
+	}
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "session_restore_command", "restore_session")
+		return s.logErrorWithTrace(context.Background(), errCtx, err, "Failed to restore session")
+	}
+
+	s.logger.Info("Session restored from trash",
+		zap.String("session_id", sessionID),
+		zap.String("user_id", userID),
+		zap.String("channel_id", channelID))
+	return fmt.Sprintf("✅ **Session Restored**\n\nSession `%s` and its conversation history are back. Switch to it with `/session %s`.", sessionID, sessionID)
+}
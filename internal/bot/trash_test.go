@@ -0,0 +1,24 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatRetention(t *testing.T) {
+	tests := []struct {
+		retention time.Duration
+		want      string
+	}{
+		{720 * time.Hour, "30 days"},
+		{24 * time.Hour, "1 day"},
+		{36 * time.Hour, "36h0m0s"},
+		{90 * time.Minute, "1h30m0s"},
+	}
+
+	for _, tt := range tests {
+		if got := formatRetention(tt.retention); got != tt.want {
+			t.Errorf("formatRetention(%v) = %q, want %q", tt.retention, got, tt.want)
+		}
+	}
+}
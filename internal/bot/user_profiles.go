@@ -0,0 +1,38 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+)
+
+// fetchUserProfile looks up a user's Slack profile for the auth service
+func (s *Service) fetchUserProfile(userID string) (*auth.UserProfile, error) {
+	user, err := s.api().GetUserInfo(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := user.RealName
+	if name == "" {
+		name = user.Name
+	}
+
+	return &auth.UserProfile{
+		Name:        name,
+		DisplayName: user.Profile.DisplayName,
+		Email:       user.Profile.Email,
+		TimeZone:    user.TZ,
+		TZOffset:    user.TZOffset,
+		IsBot:       user.IsBot,
+
+		TeamID:       user.TeamID,
+		EnterpriseID: user.Enterprise.EnterpriseID,
+		IsStranger:   user.IsStranger,
+	}, nil
+}
+
+// userTime converts a timestamp to the user's Slack timezone for display
+func (s *Service) userTime(userID string, t time.Time) time.Time {
+	return t.In(s.authService.Location(userID))
+}
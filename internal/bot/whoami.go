@@ -0,0 +1,187 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/ratelimit"
+)
+
+// whoamiReport is what /whoami shows: the user's access as authorization
+// decides it and the settings a run in the channel would use
+type whoamiReport struct {
+	UserID          string
+	Access          *auth.Access
+	AllowedChannels []string // Empty when every channel is allowed
+	ChannelHidden   bool     // The user can't read here, so channel details are left out
+
+	SessionBudgetUSD      float64
+	ChannelDailyBudgetUSD float64
+	ChannelSpentTodayUSD  float64 // Negative when it couldn't be looked up
+	MaxSessionsPerUser    int
+	FileUserQuotaMB       int64
+
+	PermissionMode string
+	Model          string
+	AllowedTools   []string // Empty means every tool
+	DenyTools      []string
+	Observer       bool
+}
+
+// handleWhoamiCommand shows the invoking user's effective permissions and the
+// channel's effective settings. It's registered without a permission so users
+// who are refused everything else can still see why; they aren't shown the
+// channel's settings or the allowed channels.
+func (s *Service) handleWhoamiCommand(ctx context.Context, req *commands.Request) (string, error) {
+	authCtx := &auth.AuthContext{UserID: req.UserID, ChannelID: req.ChannelID, Command: "/whoami", Timestamp: time.Now()}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionRead); err != nil {
+		return formatWhoami(whoamiReport{
+			UserID:             req.UserID,
+			Access:             s.authService.Access(req.UserID, req.ChannelID),
+			ChannelHidden:      true,
+			MaxSessionsPerUser: s.config.MaxSessionsPerUser,
+			FileUserQuotaMB:    s.config.FileUserQuotaMB,
+		}, time.Now()), nil
+	}
+
+	settings := s.channelSettings(req.ChannelID)
+	report := whoamiReport{
+		UserID:                req.UserID,
+		Access:                s.authService.Access(req.UserID, req.ChannelID),
+		AllowedChannels:       s.config.AllowedChannels,
+		SessionBudgetUSD:      s.config.SessionBudgetUSD,
+		ChannelDailyBudgetUSD: s.config.ChannelDailyBudgetUSD,
+		ChannelSpentTodayUSD:  -1,
+		MaxSessionsPerUser:    s.config.MaxSessionsPerUser,
+		FileUserQuotaMB:       s.config.FileUserQuotaMB,
+		Model:                 s.channelModel(settings),
+		AllowedTools:          s.allowedTools(settings),
+		DenyTools:             append(append([]string{}, s.config.DisallowedTools...), settings.DisallowedTools...),
+		Observer:              observerMode(settings),
+	}
+
+	if mode, err := s.getPermissionModeForChannel(req.ChannelID, ""); err != nil {
+		s.logger.Warn("Failed to get permission mode for /whoami",
+			zap.String("channel_id", req.ChannelID),
+			zap.Error(err))
+		report.PermissionMode = "unknown"
+	} else {
+		report.PermissionMode = string(mode)
+	}
+
+	if report.ChannelDailyBudgetUSD > 0 {
+		now := time.Now()
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if spent, err := s.usage.GetChannelCostSince(req.ChannelID, startOfDay); err != nil {
+			s.logger.Warn("Failed to get channel cost for /whoami",
+				zap.String("channel_id", req.ChannelID),
+				zap.Error(err))
+		} else {
+			report.ChannelSpentTodayUSD = spent
+		}
+	}
+
+	return formatWhoami(report, time.Now()), nil
+}
+
+// formatWhoami renders a /whoami report
+func formatWhoami(r whoamiReport, now time.Time) string {
+	access := r.Access
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "🪪 **Who am I** - <@%s>\n\n", r.UserID)
+	b.WriteString("**Access**\n")
+	fmt.Fprintf(&b, "• Role: %s\n", access.Role())
+	fmt.Fprintf(&b, "• Permission here: `%s`\n", access.Permission)
+	if access.Admin {
+		b.WriteString("• Admin: yes\n")
+	} else {
+		b.WriteString("• Admin: no\n")
+	}
+	if reason := accessDeniedReason(access, now); reason != "" {
+		fmt.Fprintf(&b, "• ⚠️ %s\n", reason)
+	}
+	switch {
+	case r.ChannelHidden:
+	case len(r.AllowedChannels) == 0:
+		b.WriteString("• Allowed channels: all\n")
+	default:
+		channels := make([]string, len(r.AllowedChannels))
+		for i, channelID := range r.AllowedChannels {
+			channels[i] = "<#" + channelID + ">"
+		}
+		fmt.Fprintf(&b, "• Allowed channels: %s\n", strings.Join(channels, ", "))
+	}
+
+	b.WriteString("\n**Limits**\n")
+	if len(access.RateLimits) == 0 {
+		b.WriteString("• Rate limit: none\n")
+	}
+	for _, limit := range access.RateLimits {
+		b.WriteString("• " + formatRateCapacity(limit, now) + "\n")
+	}
+	if r.ChannelDailyBudgetUSD > 0 {
+		if r.ChannelSpentTodayUSD < 0 {
+			fmt.Fprintf(&b, "• Channel budget: $%.2f a day (today's spend unavailable)\n", r.ChannelDailyBudgetUSD)
+		} else {
+			fmt.Fprintf(&b, "• Channel budget: $%.2f of $%.2f spent today\n", r.ChannelSpentTodayUSD, r.ChannelDailyBudgetUSD)
+		}
+	}
+	if r.SessionBudgetUSD > 0 {
+		fmt.Fprintf(&b, "• Session budget: $%.2f per session\n", r.SessionBudgetUSD)
+	}
+	fmt.Fprintf(&b, "• Sessions: up to %d per user\n", r.MaxSessionsPerUser)
+	if r.FileUserQuotaMB > 0 {
+		fmt.Fprintf(&b, "• File storage: %d MB per user\n", r.FileUserQuotaMB)
+	}
+
+	if r.ChannelHidden {
+		b.WriteString("\n_Channel settings are shown to users with read access here._")
+		return b.String()
+	}
+
+	b.WriteString("\n**This channel**\n")
+	fmt.Fprintf(&b, "• Permission mode: `%s`\n", r.PermissionMode)
+	fmt.Fprintf(&b, "• Model: %s\n", codeValue(r.Model))
+	fmt.Fprintf(&b, "• Tools: %s\n", formatToolList(r.AllowedTools, "all"))
+	if len(r.DenyTools) > 0 {
+		fmt.Fprintf(&b, "• Disallowed tools: %s\n", formatToolList(r.DenyTools, ""))
+	}
+	if r.Observer {
+		b.WriteString("• Observer mode: on; runs use plan mode without host tools\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// accessDeniedReason explains why a user has no access in a channel, or
+// returns "" if they have some
+func accessDeniedReason(access *auth.Access, now time.Time) string {
+	switch {
+	case !access.BannedUntil.IsZero():
+		return fmt.Sprintf("Banned for another %s", formatElapsed(access.BannedUntil.Sub(now)))
+	case !access.UserAllowed:
+		return "You're not on `ALLOWED_USERS`; ask an admin for access"
+	case !access.ChannelAllowed:
+		return "The bot isn't allowed in this channel (`ALLOWED_CHANNELS`)"
+	case access.Permission == auth.PermissionNone:
+		return fmt.Sprintf("External users are refused (`EXTERNAL_USER_POLICY=%s`)", access.ExternalPolicy)
+	}
+	return ""
+}
+
+// formatRateCapacity describes what is left in one rate limit bucket
+func formatRateCapacity(c ratelimit.Capacity, now time.Time) string {
+	line := fmt.Sprintf("Rate limit (%s): %d of %d requests left, refilling %d a minute",
+		c.Scope, c.Remaining, c.Limit.Burst, c.Limit.PerMinute)
+	if c.FullAt.After(now) {
+		line += fmt.Sprintf("; full in %s", formatElapsed(c.FullAt.Sub(now)))
+	}
+	return line
+}
@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/ratelimit"
+)
+
+func TestFormatWhoami(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	report := whoamiReport{
+		UserID: "U1",
+		Access: &auth.Access{
+			UserAllowed:    true,
+			ChannelAllowed: true,
+			Permission:     auth.PermissionExecute,
+			RateLimits: []ratelimit.Capacity{{
+				Scope:     ratelimit.ScopeUser,
+				Limit:     ratelimit.Limit{PerMinute: 6, Burst: 3},
+				Remaining: 1,
+				FullAt:    now.Add(20 * time.Second),
+			}},
+		},
+		AllowedChannels:       []string{"C1", "C2"},
+		ChannelDailyBudgetUSD: 10,
+		ChannelSpentTodayUSD:  2.5,
+		MaxSessionsPerUser:    3,
+		PermissionMode:        "acceptEdits",
+		Model:                 "sonnet",
+		DenyTools:             []string{"WebFetch"},
+	}
+
+	got := formatWhoami(report, now)
+	for _, want := range []string{
+		"• Role: user",
+		"• Permission here: `execute`",
+		"• Allowed channels: <#C1>, <#C2>",
+		"Rate limit (user): 1 of 3 requests left, refilling 6 a minute; full in 20s",
+		"• Channel budget: $2.50 of $10.00 spent today",
+		"• Permission mode: `acceptEdits`",
+		"• Model: `sonnet`",
+		"• Tools: all",
+		"• Disallowed tools: `WebFetch`",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "⚠️") {
+		t.Errorf("Expected no refusal for a user with access, got:\n%s", got)
+	}
+}
+
+func TestFormatWhoami_ChannelHidden(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	got := formatWhoami(whoamiReport{
+		UserID:          "U1",
+		Access:          &auth.Access{UserAllowed: true},
+		AllowedChannels: []string{"C1"},
+		ChannelHidden:   true,
+		PermissionMode:  "bypassPermissions",
+		Model:           "opus",
+	}, now)
+
+	if !strings.Contains(got, "ALLOWED_CHANNELS") {
+		t.Errorf("Expected the refusal explained, got:\n%s", got)
+	}
+	for _, hidden := range []string{"<#C1>", "**This channel**", "bypassPermissions", "opus", "• Tools:"} {
+		if strings.Contains(got, hidden) {
+			t.Errorf("Expected %q hidden from a user without read access, got:\n%s", hidden, got)
+		}
+	}
+}
+
+func TestAccessDeniedReason(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		access auth.Access
+		want   string
+	}{
+		{auth.Access{UserAllowed: true, ChannelAllowed: true, Permission: auth.PermissionRead}, ""},
+		{auth.Access{BannedUntil: now.Add(time.Hour), UserAllowed: true}, "Banned for another 1h0m0s"},
+		{auth.Access{ChannelAllowed: true}, "ALLOWED_USERS"},
+		{auth.Access{UserAllowed: true}, "ALLOWED_CHANNELS"},
+		{auth.Access{UserAllowed: true, ChannelAllowed: true, External: true, ExternalPolicy: config.ExternalUserDeny}, "EXTERNAL_USER_POLICY=deny"},
+	}
+	for _, tt := range tests {
+		got := accessDeniedReason(&tt.access, now)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("accessDeniedReason(%+v) = %q, want one mentioning %q", tt.access, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+)
+
+const (
+	// workdirPickerCallbackID identifies the working directory picker modal
+	workdirPickerCallbackID = "workdir_picker"
+
+	workdirActionKnownPath = "workdir_known_path"
+	workdirActionBrowse    = "workdir_browse"
+	workdirActionUp        = "workdir_up"
+
+	// Slack limits static selects to 100 options and option values to 150 chars
+	maxSelectOptions     = 100
+	maxOptionValueLength = 150
+)
+
+// workdirPickerState is carried in the modal's private_metadata
+type workdirPickerState struct {
+	ChannelID string `json:"channel_id"`
+	Dir       string `json:"dir"`
+}
+
+// openWorkdirPicker opens the working directory picker modal for /session new
+func (s *Service) openWorkdirPicker(ctx context.Context, req *commands.Request) (string, error) {
+	roots := s.workdirRoots()
+	if len(roots) == 0 {
+		return "❌ **Error:** No directory to browse. Set `WORKDIR_ROOTS` or `WORKING_DIRECTORY`, or use `/session new <path>`.", nil
+	}
+	state := workdirPickerState{ChannelID: req.ChannelID, Dir: roots[0]}
+
+	if _, err := s.api().OpenView(req.TriggerID, s.buildWorkdirPickerView(state)); err != nil {
+		s.logger.Error("Failed to open working directory picker", zap.Error(err))
+		return "❌ **Error:** Failed to open the directory picker. Use `/session new <path>` instead.", nil
+	}
+
+	return "", nil
+}
+
+// buildWorkdirPickerView renders the picker for the currently selected directory
+func (s *Service) buildWorkdirPickerView(state workdirPickerState) slack.ModalViewRequest {
+	metadata, _ := json.Marshal(state)
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Selected directory:*\n`%s`", state.Dir), false, false),
+			nil, nil),
+	}
+
+	// Jump to an allowed root or a path used by an earlier session
+	var groups []*slack.OptionGroupBlockObject
+	if options := pathOptions(s.workdirRoots()); len(options) > 0 {
+		groups = append(groups, slack.NewOptionGroupBlockElement(
+			slack.NewTextBlockObject(slack.PlainTextType, "Allowed roots", false, false), options...))
+	}
+	knownPaths, err := s.sessionManager.GetKnownPaths(50)
+	if err != nil {
+		s.logger.Error("Failed to get known paths", zap.Error(err))
+	}
+	if options := pathOptions(knownPaths); len(options) > 0 {
+		groups = append(groups, slack.NewOptionGroupBlockElement(
+			slack.NewTextBlockObject(slack.PlainTextType, "Recent paths", false, false), options...))
+	}
+
+	var elements []slack.BlockElement
+	if len(groups) > 0 {
+		elements = append(elements, slack.NewOptionsGroupSelectBlockElement(slack.OptTypeStatic,
+			slack.NewTextBlockObject(slack.PlainTextType, "Jump to path", false, false),
+			workdirActionKnownPath, groups...))
+	}
+
+	// Browse subdirectories of the selected directory
+	if options := pathOptions(listSubdirectories(state.Dir)); len(options) > 0 {
+		for _, option := range options {
+			option.Text.Text = truncateOptionText(filepath.Base(option.Value) + "/")
+		}
+		elements = append(elements, slack.NewOptionsSelectBlockElement(slack.OptTypeStatic,
+			slack.NewTextBlockObject(slack.PlainTextType, "Open subdirectory", false, false),
+			workdirActionBrowse, options...))
+	}
+	if parent := filepath.Dir(state.Dir); parent != state.Dir && s.isAllowedWorkdir(parent) {
+		elements = append(elements, slack.NewButtonBlockElement(workdirActionUp, parent,
+			slack.NewTextBlockObject(slack.PlainTextType, "⬆️ Parent directory", true, false)))
+	}
+
+	if len(elements) > 0 {
+		blocks = append(blocks, slack.NewActionBlock("workdir_navigation", elements...))
+	}
+	blocks = append(blocks, slack.NewContextBlock("workdir_help",
+		slack.NewTextBlockObject(slack.MarkdownType, "Browse to a directory, then press *Create* to start a new session there.", false, false)))
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      workdirPickerCallbackID,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, "New Session", false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Create", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		PrivateMetadata: string(metadata),
+		Blocks:          slack.Blocks{BlockSet: blocks},
+	}
+}
+
+// handleWorkdirPickerAction navigates the picker in response to a select or button
+func (s *Service) handleWorkdirPickerAction(callback *slack.InteractionCallback) {
+	var state workdirPickerState
+	if err := json.Unmarshal([]byte(callback.View.PrivateMetadata), &state); err != nil {
+		s.logger.Error("Invalid working directory picker state", zap.Error(err))
+		return
+	}
+
+	for _, action := range callback.ActionCallback.BlockActions {
+		var target string
+		switch action.ActionID {
+		case workdirActionKnownPath, workdirActionBrowse:
+			target = action.SelectedOption.Value
+		case workdirActionUp:
+			target = action.Value
+		default:
+			continue
+		}
+
+		if !s.isAllowedWorkdir(target) {
+			s.logger.Warn("Rejected working directory outside allowed roots",
+				zap.String("user_id", callback.User.ID),
+				zap.String("path", target))
+			continue
+		}
+		state.Dir = target
+	}
+
+	if _, err := s.api().UpdateView(s.buildWorkdirPickerView(state), "", callback.View.Hash, callback.View.ID); err != nil {
+		s.logger.Error("Failed to update working directory picker", zap.Error(err))
+	}
+}
+
+// handleWorkdirPickerSubmission creates the session in the selected directory
+func (s *Service) handleWorkdirPickerSubmission(callback *slack.InteractionCallback) {
+	userID := callback.User.ID
+
+	var state workdirPickerState
+	if err := json.Unmarshal([]byte(callback.View.PrivateMetadata), &state); err != nil {
+		s.logger.Error("Invalid working directory picker state", zap.Error(err))
+		return
+	}
+
+	if !s.isAllowedWorkdir(state.Dir) {
+		s.postEphemeral(state.ChannelID, userID, fmt.Sprintf("❌ **Directory not allowed:** `%s`", state.Dir))
+		return
+	}
+	if err := validateWorkingDir(state.Dir); err != nil {
+		s.postEphemeral(state.ChannelID, userID, fmt.Sprintf("❌ **Invalid directory:** %v", err))
+		return
+	}
+
+	newSession, err := s.sessionManager.CreateSessionWithPath(userID, state.ChannelID, state.Dir)
+	if err != nil {
+		s.logger.Error("Failed to create new session", zap.Error(err))
+		s.postEphemeral(state.ChannelID, userID, "❌ **Error:** Failed to create new session")
+		return
+	}
+
+	s.postEphemeral(state.ChannelID, userID, fmt.Sprintf("✅ **New Conversation Started**\n\nSession ID: `%s`\nWorking directory: `%s`\nNext message will start a fresh conversation with Claude.", newSession.GetID(), state.Dir))
+}
+
+// workdirRoots returns the directories users may browse: WORKDIR_ROOTS, else
+// WORKING_DIRECTORY, else the home directory. It never falls back to /, so it
+// is empty only if the home directory can't be determined.
+func (s *Service) workdirRoots() []string {
+	var roots []string
+	for _, root := range s.config.WorkdirRoots {
+		if root = strings.TrimSpace(root); root != "" {
+			roots = append(roots, filepath.Clean(root))
+		}
+	}
+	if len(roots) == 0 && s.config.WorkingDirectory != "" {
+		roots = append(roots, filepath.Clean(s.config.WorkingDirectory))
+	}
+	if len(roots) == 0 {
+		if homeDir, err := os.UserHomeDir(); err == nil && homeDir != "" && homeDir != "/" {
+			roots = append(roots, filepath.Clean(homeDir))
+		}
+	}
+	return roots
+}
+
+// insideWorkdirRoots reports whether path is one of the allowed roots or
+// inside one
+func (s *Service) insideWorkdirRoots(path string) bool {
+	path = filepath.Clean(path)
+	for _, root := range s.workdirRoots() {
+		if path == root || strings.HasPrefix(path, strings.TrimSuffix(root, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowedWorkdir reports whether path is inside an allowed root or was used
+// by an earlier session
+func (s *Service) isAllowedWorkdir(path string) bool {
+	path = filepath.Clean(path)
+	if s.insideWorkdirRoots(path) {
+		return true
+	}
+
+	knownPaths, err := s.sessionManager.GetKnownPaths(maxSelectOptions)
+	if err != nil {
+		return false
+	}
+	for _, known := range knownPaths {
+		if filepath.Clean(known) == path {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWorkingDir checks that path is an existing directory, so typos don't
+// create junk sessions and workspaces
+func validateWorkingDir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("`%s` does not exist", path)
+		}
+		return fmt.Errorf("cannot access `%s`: %v", path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("`%s` is not a directory", path)
+	}
+	return nil
+}
+
+// listSubdirectories returns the non-hidden subdirectories of dir, sorted
+func listSubdirectories(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			dirs = append(dirs, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// pathOptions converts paths into select options, skipping ones Slack can't carry
+func pathOptions(paths []string) []*slack.OptionBlockObject {
+	var options []*slack.OptionBlockObject
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if path == "" || seen[path] || len(path) > maxOptionValueLength {
+			continue
+		}
+		seen[path] = true
+		options = append(options, slack.NewOptionBlockObject(path,
+			slack.NewTextBlockObject(slack.PlainTextType, truncateOptionText(path), false, false), nil))
+		if len(options) == maxSelectOptions {
+			break
+		}
+	}
+	return options
+}
+
+// maxOptionTextLength is Slack's limit on option labels, in characters
+const maxOptionTextLength = 75
+
+// truncateOptionText keeps option labels within Slack's 75 character limit,
+// preferring the end of long paths
+func truncateOptionText(text string) string {
+	return truncateStart(text, maxOptionTextLength)
+}
+
+// truncateStart shortens text to at most max characters by replacing its
+// beginning with "…", counting runes so multi-byte characters stay whole
+func truncateStart(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	if max < 1 {
+		return ""
+	}
+	return "…" + string(runes[len(runes)-max+1:])
+}
+
+// truncateEnd shortens text to at most max characters by replacing its end
+// with "…", counting runes so multi-byte characters stay whole
+func truncateEnd(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	if max < 1 {
+		return ""
+	}
+	return string(runes[:max-1]) + "…"
+}
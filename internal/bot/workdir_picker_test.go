@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+func TestWorkdirRoots(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		home string
+		want []string
+	}{
+		{"configured roots", config.Config{WorkdirRoots: []string{" /srv/repos/ ", "", "/opt/work"}, WorkingDirectory: "/srv"}, "/home/bot", []string{"/srv/repos", "/opt/work"}},
+		{"working directory", config.Config{WorkingDirectory: "/srv/work/"}, "/home/bot", []string{"/srv/work"}},
+		{"home directory", config.Config{}, "/home/bot", []string{"/home/bot"}},
+		{"never the filesystem root", config.Config{}, "/", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", tt.home)
+			s := &Service{config: &tt.cfg}
+			if got := s.workdirRoots(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("workdirRoots() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsideWorkdirRoots(t *testing.T) {
+	s := &Service{config: &config.Config{WorkdirRoots: []string{"/srv/repos/"}}}
+	for path, want := range map[string]bool{
+		"/srv/repos":          true,
+		"/srv/repos/clones":   true,
+		"/srv/repos/../other": false,
+		"/srv/repos-old":      false,
+		"/opt/clones":         false,
+	} {
+		if got := s.insideWorkdirRoots(path); got != want {
+			t.Errorf("insideWorkdirRoots(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestTruncateStart(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{"/srv/app", 10, "/srv/app"},
+		{"/srv/application", 8, "…ication"},
+		{"/home/zoë/répertoire", 6, "…toire"},
+		{"/srv/日本語のディレクトリ", 5, "…レクトリ"},
+		{"anything", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := truncateStart(tt.text, tt.max); got != tt.want {
+			t.Errorf("truncateStart(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateEnd(t *testing.T) {
+	if got := truncateEnd("Fix flaky login test", 50); got != "Fix flaky login test" {
+		t.Errorf("truncateEnd() = %q, want the title unchanged", got)
+	}
+	if got := truncateEnd("Réparer le test de connexion instable", 10); got != "Réparer l…" {
+		t.Errorf("truncateEnd() = %q, want %q", got, "Réparer l…")
+	}
+}
@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/files"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/workspace"
+)
+
+const (
+	workspaceUsage = "**Usage:** `/workspace info` | `/workspace audit` | `/workspace purge [confirm]`"
+
+	// workspaceScanTimeout bounds a /workspace info scan
+	workspaceScanTimeout = 10 * time.Second
+
+	// workspaceLanguagesShown is how many languages /workspace info lists
+	workspaceLanguagesShown = 6
+)
+
+// handleWorkspaceCommand handles /workspace info, audit, and purge
+func (s *Service) handleWorkspaceCommand(ctx context.Context, req *commands.Request) (string, error) {
+	switch {
+	case req.Args[0] == "audit" && len(req.Args) == 1,
+		req.Args[0] == "purge" && (len(req.Args) == 1 || len(req.Args) == 2 && req.Args[1] == "confirm"):
+		return s.handleWorkspaceAuditCommand(ctx, req), nil
+	case req.Args[0] != "info" || len(req.Args) != 1:
+		return workspaceUsage, nil
+	}
+
+	userSession, err := s.sessionManager.GetOrCreateSession(req.UserID, req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "workspace_command", "get_session")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to get session"), nil
+	}
+	workDir := userSession.GetCurrentWorkDir()
+
+	scanCtx, cancel := context.WithTimeout(ctx, workspaceScanTimeout)
+	defer cancel()
+	inv, err := workspace.Scan(scanCtx, workDir, workspace.DefaultLimits)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "workspace_command", "scan")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to scan the working directory"), nil
+	}
+
+	return s.formatWorkspaceInfo(req.UserID, inv), nil
+}
+
+// formatWorkspaceInfo renders a workspace inventory for Slack
+func (s *Service) formatWorkspaceInfo(userID string, inv *workspace.Inventory) string {
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("📁 **Workspace:** `%s`\n\n", inv.Dir))
+
+	size := fmt.Sprintf("%d files in %d directories, %s", inv.Files, inv.Dirs, files.FormatBytes(inv.Bytes))
+	if inv.Truncated {
+		size += " _(stopped counting, the tree is larger)_"
+	}
+	response.WriteString(fmt.Sprintf("**Size:** %s\n", size))
+	if len(inv.Skipped) > 0 {
+		response.WriteString(fmt.Sprintf("**Not counted:** `%s`\n", strings.Join(inv.Skipped, "`, `")))
+	}
+
+	if len(inv.Languages) > 0 {
+		var languageBytes int64
+		for _, language := range inv.Languages {
+			languageBytes += language.Bytes
+		}
+		var parts []string
+		for i, language := range inv.Languages {
+			if i == workspaceLanguagesShown {
+				parts = append(parts, fmt.Sprintf("%d more", len(inv.Languages)-i))
+				break
+			}
+			percent := 0
+			if languageBytes > 0 {
+				percent = int(language.Bytes * 100 / languageBytes)
+			}
+			parts = append(parts, fmt.Sprintf("%s %d%% (%d)", language.Name, percent, language.Files))
+		}
+		response.WriteString(fmt.Sprintf("**Languages:** %s\n", strings.Join(parts, ", ")))
+	}
+
+	if inv.GitRoot == "" {
+		response.WriteString("**Git:** not a repository\n")
+	} else {
+		branch := inv.Branch
+		if branch == "" {
+			branch = "detached HEAD"
+		}
+		response.WriteString(fmt.Sprintf("**Git:** `%s` at `%s`\n", branch, inv.GitRoot))
+		for _, remote := range inv.Remotes {
+			response.WriteString(fmt.Sprintf("• %s: `%s`\n", remote.Name, redactRemoteURL(remote.URL)))
+		}
+	}
+
+	if len(inv.Recent) > 0 {
+		response.WriteString("\n**Recently modified:**\n")
+		for _, file := range inv.Recent {
+			response.WriteString(fmt.Sprintf("• `%s` — %s\n", file.Path, s.userTime(userID, file.ModTime).Format("Jan 2 15:04")))
+		}
+	}
+
+	return strings.TrimRight(response.String(), "\n")
+}
+
+// redactRemoteURL drops credentials embedded in an HTTP(S) remote URL
+func redactRemoteURL(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return remote
+	}
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,212 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/notifications"
+	"github.com/ghabxph/claude-on-slack/internal/workspace"
+)
+
+const (
+	// workspaceOrphanMinAge keeps directories that were just provisioned,
+	// before their session is saved, from being reported
+	workspaceOrphanMinAge = 24 * time.Hour
+
+	// workspaceOrphansShown is how many orphaned workspaces a report lists
+	workspaceOrphansShown = 20
+
+	// workspacePurgeConfirmWindow is how long /workspace purge confirm acts
+	// on the list /workspace purge showed
+	workspacePurgeConfirmWindow = 15 * time.Minute
+)
+
+// pendingPurges holds the orphaned workspaces /workspace purge listed for
+// each admin, so /workspace purge confirm deletes exactly those
+type pendingPurges struct {
+	mu     sync.Mutex
+	purges map[string]pendingPurge
+}
+
+type pendingPurge struct {
+	paths    []string
+	listedAt time.Time
+}
+
+func newPendingPurges() *pendingPurges {
+	return &pendingPurges{purges: make(map[string]pendingPurge)}
+}
+
+func (p *pendingPurges) set(userID string, paths []string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.purges[userID] = pendingPurge{paths: paths, listedAt: now}
+}
+
+// take returns the paths listed for a user within the confirm window, if
+// any, and clears them
+func (p *pendingPurges) take(userID string, now time.Time) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	purge, ok := p.purges[userID]
+	delete(p.purges, userID)
+	if !ok || now.Sub(purge.listedAt) > workspacePurgeConfirmWindow {
+		return nil
+	}
+	return purge.paths
+}
+
+// findOrphanedWorkspaces compares the workspaces under WORKSPACE_AUDIT_ROOTS
+// with the directories sessions refer to
+func (s *Service) findOrphanedWorkspaces() ([]workspace.Orphan, error) {
+	managed, err := workspace.ManagedDirs(s.config.WorkspaceAuditRoots)
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.sessionDirs.ListReferencedDirectories()
+	if err != nil {
+		return nil, err
+	}
+	return workspace.FindOrphans(managed, used, workspaceOrphanMinAge, time.Now()), nil
+}
+
+// workspaceAuditLoop reports orphaned workspaces every
+// WORKSPACE_AUDIT_INTERVAL, until stopped. It never deletes anything.
+func (s *Service) workspaceAuditLoop() {
+	if len(s.config.WorkspaceAuditRoots) == 0 || s.config.WorkspaceAuditInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.WorkspaceAuditInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			orphans, err := s.findOrphanedWorkspaces()
+			if err != nil {
+				s.logger.Error("Failed to audit workspaces", zap.Error(err))
+				continue
+			}
+			if len(orphans) == 0 {
+				continue
+			}
+
+			paths := make([]string, 0, len(orphans))
+			for _, orphan := range orphans {
+				paths = append(paths, orphan.Path)
+			}
+			s.logger.Warn("Found orphaned workspaces", zap.Strings("paths", paths))
+			s.alert(notifications.Event{
+				Kind:     notifications.EventOrphanedWorkspaces,
+				Severity: notifications.SeverityInfo,
+				Title:    "Orphaned workspaces",
+				Text:     fmt.Sprintf("%d workspace directories no longer belong to any session. Review them with /workspace audit and remove them with /workspace purge.", len(orphans)),
+				Fields:   map[string]string{"roots": strings.Join(s.config.WorkspaceAuditRoots, ", ")},
+				Key:      "workspaces",
+			})
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// handleWorkspaceAuditCommand handles /workspace audit and /workspace purge [confirm]
+func (s *Service) handleWorkspaceAuditCommand(ctx context.Context, req *commands.Request) string {
+	if !s.authService.IsUserAdmin(req.UserID) {
+		return "❌ **Admin only**\n\nAuditing and purging workspaces requires admin permission."
+	}
+	if len(s.config.WorkspaceAuditRoots) == 0 {
+		return "❌ **No managed workspaces**\n\nSet `WORKSPACE_AUDIT_ROOTS` to the directories whose subdirectories the bot creates, such as the parent of `WORKDIR_TEMPLATE`."
+	}
+
+	orphans, err := s.findOrphanedWorkspaces()
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "workspace_command", "audit")
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to audit workspaces")
+	}
+	if len(orphans) == 0 {
+		return fmt.Sprintf("✅ **No orphaned workspaces**\n\nEvery directory under `%s` belongs to a session.", strings.Join(s.config.WorkspaceAuditRoots, "`, `"))
+	}
+
+	confirmed := len(req.Args) == 2 && req.Args[1] == "confirm"
+	if req.Args[0] != "purge" || !confirmed {
+		response := s.formatOrphanedWorkspaces(req.UserID, orphans)
+		if req.Args[0] == "purge" {
+			listed := make([]string, 0, workspaceOrphansShown)
+			for i, orphan := range orphans {
+				if i == workspaceOrphansShown {
+					break
+				}
+				listed = append(listed, orphan.Path)
+			}
+			s.purges.set(req.UserID, listed, time.Now())
+			return response + fmt.Sprintf("\n\nRun `/workspace purge confirm` within %s to delete the directories listed above. This cannot be undone.", workspacePurgeConfirmWindow)
+		}
+		return response + "\n\nDelete them with `/workspace purge`."
+	}
+
+	listed := s.purges.take(req.UserID, time.Now())
+	if len(listed) == 0 {
+		return fmt.Sprintf("❌ **Nothing to confirm**\n\nRun `/workspace purge` first to see what would be deleted, then confirm within %s.", workspacePurgeConfirmWindow)
+	}
+
+	// Only what was listed and is still orphaned goes, and never through a
+	// symlink, which could point outside the managed roots
+	stillOrphaned := make(map[string]bool, len(orphans))
+	for _, orphan := range orphans {
+		stillOrphaned[orphan.Path] = true
+	}
+	var removed, failed, skipped []string
+	for _, path := range listed {
+		if !stillOrphaned[path] {
+			skipped = append(skipped, fmt.Sprintf("`%s`: no longer orphaned", path))
+			continue
+		}
+		if info, err := os.Lstat(path); err != nil || !info.IsDir() {
+			skipped = append(skipped, fmt.Sprintf("`%s`: not a directory or a symlink", path))
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			s.logger.Error("Failed to purge orphaned workspace", zap.String("path", path), zap.Error(err))
+			failed = append(failed, fmt.Sprintf("`%s`: %v", path, err))
+			continue
+		}
+		removed = append(removed, path)
+	}
+	s.logger.Info("Purged orphaned workspaces",
+		zap.String("user_id", req.UserID),
+		zap.Strings("paths", removed))
+
+	response := fmt.Sprintf("🧹 **Purged %d orphaned workspace(s)**", len(removed))
+	if len(skipped) > 0 {
+		response += fmt.Sprintf("\n\n⏭️ %d skipped:\n• %s", len(skipped), strings.Join(skipped, "\n• "))
+	}
+	if len(failed) > 0 {
+		response += fmt.Sprintf("\n\n❌ %d could not be removed:\n• %s", len(failed), strings.Join(failed, "\n• "))
+	}
+	return response
+}
+
+// formatOrphanedWorkspaces lists orphaned workspaces and when each was last
+// modified
+func (s *Service) formatOrphanedWorkspaces(userID string, orphans []workspace.Orphan) string {
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🧟 **%d Orphaned Workspace(s)**\n\nNo session, including those in the trash, uses these directories:\n", len(orphans)))
+	for i, orphan := range orphans {
+		if i == workspaceOrphansShown {
+			response.WriteString(fmt.Sprintf("\n_... and %d more_", len(orphans)-i))
+			break
+		}
+		response.WriteString(fmt.Sprintf("\n• `%s` - last modified %s", orphan.Path, s.userTime(userID, orphan.ModTime).Format("Jan 2 2006")))
+	}
+	return response.String()
+}
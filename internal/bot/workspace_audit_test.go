@@ -0,0 +1,32 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPendingPurges(t *testing.T) {
+	purges := newPendingPurges()
+	now := time.Now()
+
+	if got := purges.take("U1", now); got != nil {
+		t.Errorf("take() without a listing = %v, want nil", got)
+	}
+
+	purges.set("U1", []string{"/srv/ws/a", "/srv/ws/b"}, now)
+	if got := purges.take("U2", now); got != nil {
+		t.Errorf("take() for another admin = %v, want nil", got)
+	}
+	if got, want := purges.take("U1", now.Add(time.Minute)), []string{"/srv/ws/a", "/srv/ws/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("take() = %v, want %v", got, want)
+	}
+	if got := purges.take("U1", now.Add(time.Minute)); got != nil {
+		t.Errorf("Expected a listing to be confirmed only once, got %v", got)
+	}
+
+	purges.set("U1", []string{"/srv/ws/a"}, now)
+	if got := purges.take("U1", now.Add(workspacePurgeConfirmWindow+time.Second)); got != nil {
+		t.Errorf("Expected an expired listing to be dropped, got %v", got)
+	}
+}
@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/workspace"
+)
+
+// cloneProgressInterval spaces out edits to a clone's progress message
+const cloneProgressInterval = 3 * time.Second
+
+// workspaceClones tracks the clone directories being filled right now, so
+// two requests for the same repository don't clone over each other
+type workspaceClones struct {
+	mu     sync.Mutex
+	active map[string]bool
+}
+
+func newWorkspaceClones() *workspaceClones {
+	return &workspaceClones{active: make(map[string]bool)}
+}
+
+// start claims dir for a clone. It reports false if one is already running.
+func (c *workspaceClones) start(dir string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.active[dir] {
+		return false
+	}
+	c.active[dir] = true
+	return true
+}
+
+func (c *workspaceClones) finish(dir string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.active, dir)
+}
+
+// unwrapSlackLink undoes Slack's link formatting, e.g.
+// <https://github.com/org/repo|github.com/org/repo> or
+// <mailto:git@github.com|git@github.com>:org/repo.git
+func unwrapSlackLink(arg string) string {
+	for {
+		start := strings.Index(arg, "<")
+		end := strings.Index(arg, ">")
+		if start < 0 || end < start {
+			return arg
+		}
+		link := arg[start+1 : end]
+		if _, label, ok := strings.Cut(link, "|"); ok && strings.HasPrefix(link, "mailto:") {
+			link = label
+		} else {
+			link, _, _ = strings.Cut(link, "|")
+		}
+		arg = arg[:start] + link + arg[end+1:]
+	}
+}
+
+// cloneTarget reports whether a `/session new` argument is a git URL to
+// clone rather than a directory, and returns it without Slack's formatting
+func cloneTarget(arg string) (string, bool) {
+	target := unwrapSlackLink(arg)
+	return target, workspace.LooksLikeRepoURL(target)
+}
+
+// startWorkspaceClone handles `/session new <git URL>`: it posts a progress
+// message in the channel and clones the repository in the background
+func (s *Service) startWorkspaceClone(userID, channelID, rawURL string) string {
+	if s.config.WorkspaceCloneRoot == "" {
+		return "❌ **Cloning is turned off**\n\nSet `WORKSPACE_CLONE_ROOT` to let `/session new` clone repositories."
+	}
+	// Sessions may only work inside the allowed roots, clones included
+	if !s.insideWorkdirRoots(s.config.WorkspaceCloneRoot) {
+		return "❌ **Cloning is turned off**\n\n`WORKSPACE_CLONE_ROOT` must be inside one of the `WORKDIR_ROOTS`."
+	}
+	authCtx := &auth.AuthContext{UserID: userID, ChannelID: channelID, Command: "/session", Timestamp: time.Now()}
+	if err := s.authService.AuthorizeUser(authCtx, auth.PermissionWrite); err != nil {
+		return fmt.Sprintf("❌ Authorization failed: %v", err)
+	}
+
+	repo, err := workspace.ParseRepoURL(rawURL, s.config.WorkspaceCloneHosts)
+	if err != nil {
+		return fmt.Sprintf("❌ **Can't clone:** %v", err)
+	}
+	dir := filepath.Join(s.config.WorkspaceCloneRoot, repo.DirName())
+	if !s.clones.start(dir) {
+		return fmt.Sprintf("⏳ `%s` is already being cloned. Try again once it's done.", repo.URL)
+	}
+
+	messageTS := s.sendThreadResponse(channelID, "", fmt.Sprintf("📦 <@%s> is cloning `%s`…", userID, repo.URL))
+	if messageTS == "" {
+		s.clones.finish(dir)
+		return "❌ **Error:** Couldn't post the clone's progress here. Invite the bot to the channel and try again."
+	}
+
+	go s.cloneWorkspace(userID, channelID, messageTS, repo, dir)
+	return ""
+}
+
+// cloneWorkspace clones a repository, keeps its progress message up to date,
+// and starts a session in the checkout
+func (s *Service) cloneWorkspace(userID, channelID, messageTS string, repo *workspace.RepoURL, dir string) {
+	defer s.clones.finish(dir)
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.WorkspaceCloneTimeout)
+	defer cancel()
+
+	started := time.Now()
+	var lastUpdate time.Time
+	progress := func(line string) {
+		if time.Since(lastUpdate) < cloneProgressInterval {
+			return
+		}
+		lastUpdate = time.Now()
+		s.updateCloneMessage(channelID, messageTS, fmt.Sprintf("📦 <@%s> is cloning `%s`…\n`%s`", userID, repo.URL, line))
+	}
+
+	creds := workspace.CloneCredentials{SSHKey: s.config.WorkspaceCloneSSHKey, Token: s.config.WorkspaceCloneToken}
+	dir, existed, err := workspace.CloneRepo(ctx, s.config.WorkspaceCloneRoot, repo, creds, progress)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "workspace_clone", "clone_repository")
+		s.updateCloneMessage(channelID, messageTS, s.logErrorWithTrace(ctx, errCtx, err, fmt.Sprintf("Failed to clone %s", repo.URL)))
+		return
+	}
+
+	newSession, err := s.sessionManager.CreateSessionWithPath(userID, channelID, dir)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(channelID, userID, "workspace_clone", "create_session")
+		s.updateCloneMessage(channelID, messageTS, s.logErrorWithTrace(ctx, errCtx, err, "Cloned the repository but failed to create a session for it"))
+		return
+	}
+
+	s.logger.Info("Started session in cloned workspace",
+		zap.String("repository", repo.URL),
+		zap.String("dir", dir),
+		zap.Bool("existed", existed),
+		zap.Duration("elapsed", time.Since(started)),
+		zap.String("session_id", newSession.GetID()))
+
+	summary := fmt.Sprintf("✅ **Cloned** `%s` in %s", repo.URL, formatElapsed(time.Since(started)))
+	if existed {
+		summary = fmt.Sprintf("✅ **Already cloned:** `%s`; using the existing checkout as it is", repo.URL)
+	}
+	s.updateCloneMessage(channelID, messageTS, fmt.Sprintf("%s\n\nSession ID: `%s`\nWorking directory: `%s`\nNext message will start a fresh conversation in this checkout.",
+		summary, newSession.GetID(), dir))
+}
+
+// updateCloneMessage replaces the text of a clone's progress message
+func (s *Service) updateCloneMessage(channelID, messageTS, text string) {
+	if err := s.sender.UpdateMessage(context.Background(), channelID, messageTS, slack.MsgOptionText(text, false)); err != nil {
+		s.logger.Warn("Failed to update clone progress",
+			zap.String("channel_id", channelID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,40 @@
+package bot
+
+import "testing"
+
+func TestCloneTarget(t *testing.T) {
+	tests := []struct {
+		arg   string
+		want  string
+		clone bool
+	}{
+		{"git@github.com:org/repo.git", "git@github.com:org/repo.git", true},
+		{"<https://github.com/org/repo>", "https://github.com/org/repo", true},
+		{"<https://github.com/org/repo|github.com/org/repo>", "https://github.com/org/repo", true},
+		{"<mailto:git@github.com|git@github.com>:org/repo.git", "git@github.com:org/repo.git", true},
+		{"/home/dev/project", "/home/dev/project", false},
+	}
+	for _, tt := range tests {
+		got, clone := cloneTarget(tt.arg)
+		if got != tt.want || clone != tt.clone {
+			t.Errorf("cloneTarget(%q) = %q, %v; want %q, %v", tt.arg, got, clone, tt.want, tt.clone)
+		}
+	}
+}
+
+func TestWorkspaceClones(t *testing.T) {
+	clones := newWorkspaceClones()
+	if !clones.start("/clones/org-repo") {
+		t.Fatal("Expected the first clone to start")
+	}
+	if clones.start("/clones/org-repo") {
+		t.Error("Expected a second clone into the same directory to be refused")
+	}
+	if !clones.start("/clones/org-other") {
+		t.Error("Expected clones of other repositories to start")
+	}
+	clones.finish("/clones/org-repo")
+	if !clones.start("/clones/org-repo") {
+		t.Error("Expected the directory to be free once the clone finished")
+	}
+}
@@ -0,0 +1,182 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/commands"
+	"github.com/ghabxph/claude-on-slack/internal/logging"
+	"github.com/ghabxph/claude-on-slack/internal/session"
+	"github.com/ghabxph/claude-on-slack/internal/workspace"
+)
+
+// worktreeCommandTimeout bounds the git work behind /merge and /discard
+const worktreeCommandTimeout = 2 * time.Minute
+
+// maxDiscardFilesShown caps the files /discard lists before asking to confirm
+const maxDiscardFilesShown = 10
+
+const notInWorktreeMessage = "ℹ️ This session isn't in a worktree of its own, so there's nothing to land or drop. " +
+	"With `SESSION_WORKTREES=true`, new sessions in git repositories get one."
+
+// currentSessionWorktree returns the channel's current session and the
+// worktree it works in. The returned message, if any, is the reply instead.
+func (s *Service) currentSessionWorktree(ctx context.Context, req *commands.Request, operation string) (session.SessionInfo, *workspace.SessionWorktree, string) {
+	userSession, err := s.sessionManager.GetOrCreateSession(req.UserID, req.ChannelID)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "worktree_command", operation)
+		return nil, nil, s.logErrorWithTrace(ctx, errCtx, err, "Failed to get session")
+	}
+
+	wt, err := workspace.FindSessionWorktree(ctx, userSession.GetCurrentWorkDir())
+	if errors.Is(err, workspace.ErrNotSessionWorktree) {
+		return nil, nil, notInWorktreeMessage
+	}
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "worktree_command", operation).WithSession(userSession.GetID())
+		return nil, nil, s.logErrorWithTrace(ctx, errCtx, err, "Failed to inspect the session's worktree")
+	}
+
+	if s.sessionManager.IsProcessing(userSession.GetID()) {
+		return nil, nil, "⏳ Claude is still working on this session; wait for the reply or use `/stop` first."
+	}
+	return userSession, wt, ""
+}
+
+// handleMergeCommand lands the current session's changes in the main
+// checkout and starts a fresh session there
+func (s *Service) handleMergeCommand(ctx context.Context, req *commands.Request) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, worktreeCommandTimeout)
+	defer cancel()
+
+	userSession, wt, message := s.currentSessionWorktree(ctx, req, "merge")
+	if message != "" {
+		return message, nil
+	}
+
+	short := workspace.ShortSessionID(userSession.GetID())
+	commits, err := workspace.MergeSessionWorktree(ctx, wt, fmt.Sprintf("Changes from Claude session %s", short))
+	if err != nil {
+		s.logger.Warn("Failed to merge session worktree",
+			zap.String("channel_id", req.ChannelID),
+			zap.String("session_id", userSession.GetID()),
+			zap.String("branch", wt.Branch),
+			zap.Error(err))
+		return fmt.Sprintf("❌ **Couldn't merge `%s` into `%s`**\n\n```\n%s\n```\nNothing was changed in the main checkout. The session's work is committed on its branch; resolve it there or drop it with `/discard`.",
+			wt.Branch, wt.MainCheckout, err), nil
+	}
+	if commits == 0 {
+		return fmt.Sprintf("ℹ️ `%s` has no changes to merge.", wt.Branch), nil
+	}
+
+	s.logger.Info("Merged session worktree",
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID),
+		zap.String("session_id", userSession.GetID()),
+		zap.String("branch", wt.Branch),
+		zap.Int("commits", commits))
+
+	return fmt.Sprintf("✅ **Merged `%s` into `%s`** (%s)\n\n%s", wt.Branch, wt.MainCheckout, pluralize(commits, "commit"),
+		s.startSessionAfterWorktree(ctx, req, userSession, wt)), nil
+}
+
+// handleDiscardCommand drops the current session's worktree and branch after
+// the user confirms, and starts a fresh session in the main checkout
+func (s *Service) handleDiscardCommand(ctx context.Context, req *commands.Request) (string, error) {
+	if len(req.Args) > 0 && req.Args[0] != "confirm" {
+		return "**Usage:** `/discard` to see what would be dropped, then `/discard confirm`", nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, worktreeCommandTimeout)
+	defer cancel()
+
+	userSession, wt, message := s.currentSessionWorktree(ctx, req, "discard")
+	if message != "" {
+		return message, nil
+	}
+
+	if len(req.Args) == 0 {
+		commits, changed, err := wt.Pending(ctx)
+		if err != nil {
+			errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "worktree_command", "discard").WithSession(userSession.GetID())
+			return s.logErrorWithTrace(ctx, errCtx, err, "Failed to inspect the session's worktree"), nil
+		}
+		return formatDiscardPreview(wt, commits, changed), nil
+	}
+
+	kept, err := workspace.DiscardSessionWorktree(ctx, wt)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "worktree_command", "discard").WithSession(userSession.GetID())
+		return s.logErrorWithTrace(ctx, errCtx, err, "Failed to discard the session's worktree"), nil
+	}
+
+	s.logger.Info("Discarded session worktree",
+		zap.String("channel_id", req.ChannelID),
+		zap.String("user_id", req.UserID),
+		zap.String("session_id", userSession.GetID()),
+		zap.String("branch", wt.Branch),
+		zap.Bool("branch_kept", kept))
+
+	response := fmt.Sprintf("🗑️ **Discarded `%s`**", wt.Branch)
+	if kept {
+		response = fmt.Sprintf("🗑️ **Removed the worktree of `%s`**\n\nThe branch is kept because it has commits not merged into `%s`; delete it with `git branch -D %s` once they're no longer needed.", wt.Branch, wt.MainCheckout, wt.Branch)
+	}
+	return response + "\n\n" + s.startSessionAfterWorktree(ctx, req, userSession, wt), nil
+}
+
+// formatDiscardPreview lists what /discard confirm would drop
+func formatDiscardPreview(wt *workspace.SessionWorktree, commits int, changed []string) string {
+	if commits == 0 && len(changed) == 0 {
+		return fmt.Sprintf("ℹ️ `%s` has no changes. Run `/discard confirm` to remove it and start a fresh session.", wt.Branch)
+	}
+
+	var response strings.Builder
+	if len(changed) > 0 {
+		response.WriteString(fmt.Sprintf("⚠️ **Discarding `%s` drops uncommitted changes to %s**", wt.Branch, pluralize(len(changed), "file")))
+		for i, file := range changed {
+			if i == maxDiscardFilesShown {
+				response.WriteString(fmt.Sprintf("\n• _and %d more_", len(changed)-i))
+				break
+			}
+			response.WriteString(fmt.Sprintf("\n• `%s`", file))
+		}
+		response.WriteString("\n\nThis can't be undone.")
+	} else {
+		response.WriteString(fmt.Sprintf("ℹ️ **Discarding `%s` removes its worktree**", wt.Branch))
+	}
+	if commits > 0 {
+		response.WriteString(fmt.Sprintf(" The branch's %s aren't merged, so the branch is kept for them.", pluralize(commits, "commit")))
+	}
+	response.WriteString(" Run `/discard confirm` to go ahead, or `/merge` to keep the changes.")
+	return response.String()
+}
+
+// startSessionAfterWorktree points the channel at a fresh session in the
+// main checkout, since the old session's directory is gone, and describes it
+func (s *Service) startSessionAfterWorktree(ctx context.Context, req *commands.Request, old session.SessionInfo, wt *workspace.SessionWorktree) string {
+	dir := wt.MainCheckout
+	if rel, err := filepath.Rel(wt.Dir, old.GetCurrentWorkDir()); err == nil && !strings.HasPrefix(rel, "..") {
+		dir = filepath.Join(wt.MainCheckout, rel)
+	}
+
+	newSession, err := s.sessionManager.CreateSessionWithPath(req.UserID, req.ChannelID, dir)
+	if err != nil {
+		errCtx := logging.CreateErrorContext(req.ChannelID, req.UserID, "worktree_command", "new_session")
+		return s.logErrorWithTrace(ctx, errCtx, err, "The worktree was removed, but starting a fresh session failed; use `/session new` to start one")
+	}
+	return fmt.Sprintf("The worktree was removed. New session `%s` works in `%s`.", newSession.GetID(), newSession.GetCurrentWorkDir())
+}
+
+// pluralize renders a count with its noun, e.g. "1 commit" or "3 commits"
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
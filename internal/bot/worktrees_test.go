@@ -0,0 +1,31 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/workspace"
+)
+
+func TestFormatDiscardPreview(t *testing.T) {
+	wt := &workspace.SessionWorktree{Branch: "claude/session-1a2b3c4d"}
+
+	if got := formatDiscardPreview(wt, 0, nil); !strings.Contains(got, "has no changes") {
+		t.Errorf("Expected a clean worktree to say so, got %q", got)
+	}
+
+	got := formatDiscardPreview(wt, 2, []string{"main.go"})
+	if !strings.Contains(got, "drops uncommitted changes to 1 file") || !strings.Contains(got, "`main.go`") || !strings.Contains(got, "2 commits aren't merged, so the branch is kept") {
+		t.Errorf("Unexpected preview: %q", got)
+	}
+
+	var many []string
+	for i := 0; i < maxDiscardFilesShown+3; i++ {
+		many = append(many, fmt.Sprintf("file%d.go", i))
+	}
+	got = formatDiscardPreview(wt, 0, many)
+	if !strings.Contains(got, "_and 3 more_") || strings.Contains(got, fmt.Sprintf("file%d.go", maxDiscardFilesShown)) {
+		t.Errorf("Expected the file list to be capped, got %q", got)
+	}
+}
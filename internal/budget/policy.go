@@ -0,0 +1,161 @@
+package budget
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultPolicySpec is the downgrade ladder used when none is configured:
+// sonnet at 75% of budget, haiku at 90%, plan mode once the budget is spent
+const DefaultPolicySpec = "0.75:sonnet,0.9:haiku,1:plan"
+
+// planAction is the step action that switches to plan mode instead of a model
+const planAction = "plan"
+
+// modelRanks orders model aliases from cheapest to most capable
+var modelRanks = map[string]int{
+	"haiku":  1,
+	"sonnet": 2,
+	"opus":   3,
+}
+
+// Step is one rung of the downgrade ladder
+type Step struct {
+	Threshold float64 // Fraction of the budget used, e.g. 0.9
+	Model     string  // Model to downgrade to; empty for plan mode steps
+	PlanMode  bool    // Switch the run to plan mode
+}
+
+// Policy decides how to degrade runs as spending approaches a budget
+type Policy struct {
+	Steps []Step // Sorted by ascending threshold
+}
+
+// Usage is the spend against one budget scope
+type Usage struct {
+	Scope  string // e.g. "session" or "channel"
+	Spent  float64
+	Budget float64 // Zero or negative disables the budget
+}
+
+// Ratio returns the fraction of the budget used, or 0 if the budget is disabled
+func (u Usage) Ratio() float64 {
+	if u.Budget <= 0 {
+		return 0
+	}
+	return u.Spent / u.Budget
+}
+
+// Decision is the outcome of applying a policy to a run
+type Decision struct {
+	Model      string  // Model to run with
+	PlanMode   bool    // Force plan mode
+	Downgraded bool    // Model or mode differs from what was requested
+	Scope      string  // Budget scope that triggered the decision
+	Ratio      float64 // Fraction of that budget used
+}
+
+// ParsePolicy parses a comma separated "threshold:action" list, where action
+// is a model alias (haiku, sonnet, opus) or "plan"
+func ParsePolicy(spec string) (*Policy, error) {
+	policy := &Policy{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		fields := strings.SplitN(part, ":", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid step %q: expected threshold:action", part)
+		}
+
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		if err != nil || threshold <= 0 {
+			return nil, fmt.Errorf("invalid threshold in step %q", part)
+		}
+
+		action := strings.ToLower(strings.TrimSpace(fields[1]))
+		step := Step{Threshold: threshold}
+		if action == planAction {
+			step.PlanMode = true
+		} else if _, known := modelRanks[action]; known {
+			step.Model = action
+		} else {
+			return nil, fmt.Errorf("invalid action in step %q: must be haiku, sonnet, opus, or plan", part)
+		}
+
+		policy.Steps = append(policy.Steps, step)
+	}
+
+	if len(policy.Steps) == 0 {
+		return nil, fmt.Errorf("policy has no steps")
+	}
+
+	sort.SliceStable(policy.Steps, func(i, j int) bool {
+		return policy.Steps[i].Threshold < policy.Steps[j].Threshold
+	})
+	return policy, nil
+}
+
+// Decide picks the model and mode for a run given the requested model and the
+// spend against each budget scope. The most consumed budget wins, and models
+// are only ever downgraded, never upgraded.
+func (p *Policy) Decide(model string, usages ...Usage) Decision {
+	decision := Decision{Model: model}
+
+	var worst Usage
+	for _, usage := range usages {
+		if usage.Ratio() > worst.Ratio() {
+			worst = usage
+		}
+	}
+	ratio := worst.Ratio()
+	if ratio == 0 {
+		return decision
+	}
+
+	for _, step := range p.Steps {
+		if ratio < step.Threshold {
+			break
+		}
+		if step.PlanMode {
+			decision.PlanMode = true
+		}
+		if step.Model != "" && isCheaper(step.Model, decision.Model) {
+			decision.Model = step.Model
+		}
+	}
+
+	decision.Downgraded = decision.PlanMode || decision.Model != model
+	if decision.Downgraded {
+		decision.Scope = worst.Scope
+		decision.Ratio = ratio
+	}
+	return decision
+}
+
+// Notice returns a short footer note describing a downgrade
+func (d Decision) Notice() string {
+	if !d.Downgraded {
+		return ""
+	}
+
+	action := fmt.Sprintf("using %s", d.Model)
+	if d.PlanMode {
+		action = "switched to plan mode"
+	}
+	return fmt.Sprintf("⚠️ %.0f%% of %s budget used, %s", d.Ratio*100, d.Scope, action)
+}
+
+// isCheaper reports whether candidate ranks below current. Unknown models
+// (e.g. full model names) are left alone.
+func isCheaper(candidate, current string) bool {
+	currentRank, known := modelRanks[strings.ToLower(current)]
+	if !known {
+		return false
+	}
+	return modelRanks[candidate] < currentRank
+}
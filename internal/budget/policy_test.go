@@ -0,0 +1,110 @@
+package budget
+
+import "testing"
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("0.9:haiku, 0.75:sonnet,1:plan")
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+	if len(policy.Steps) != 3 {
+		t.Fatalf("got %d steps, want 3", len(policy.Steps))
+	}
+	if policy.Steps[0].Model != "sonnet" || policy.Steps[1].Model != "haiku" || !policy.Steps[2].PlanMode {
+		t.Errorf("steps not sorted by threshold: %+v", policy.Steps)
+	}
+
+	for _, spec := range []string{"", "sonnet", "0.5:gpt", "-1:haiku", "x:haiku"} {
+		if _, err := ParsePolicy(spec); err == nil {
+			t.Errorf("ParsePolicy(%q) expected error", spec)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	policy, err := ParsePolicy(DefaultPolicySpec)
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		model      string
+		usages     []Usage
+		wantModel  string
+		wantPlan   bool
+		wantScope  string
+		downgraded bool
+	}{
+		{
+			name:      "no budgets",
+			model:     "opus",
+			usages:    []Usage{{Scope: "session", Spent: 100}},
+			wantModel: "opus",
+		},
+		{
+			name:      "under threshold",
+			model:     "opus",
+			usages:    []Usage{{Scope: "session", Spent: 5, Budget: 10}},
+			wantModel: "opus",
+		},
+		{
+			name:       "first step",
+			model:      "opus",
+			usages:     []Usage{{Scope: "session", Spent: 8, Budget: 10}},
+			wantModel:  "sonnet",
+			wantScope:  "session",
+			downgraded: true,
+		},
+		{
+			name:  "worst scope wins",
+			model: "opus",
+			usages: []Usage{
+				{Scope: "session", Spent: 1, Budget: 10},
+				{Scope: "channel", Spent: 95, Budget: 100},
+			},
+			wantModel:  "haiku",
+			wantScope:  "channel",
+			downgraded: true,
+		},
+		{
+			name:       "exhausted switches to plan",
+			model:      "sonnet",
+			usages:     []Usage{{Scope: "channel", Spent: 12, Budget: 10}},
+			wantModel:  "haiku",
+			wantPlan:   true,
+			wantScope:  "channel",
+			downgraded: true,
+		},
+		{
+			name:      "never upgrades",
+			model:     "haiku",
+			usages:    []Usage{{Scope: "session", Spent: 8, Budget: 10}},
+			wantModel: "haiku",
+		},
+		{
+			name:      "unknown model untouched",
+			model:     "claude-sonnet-4-20250514",
+			usages:    []Usage{{Scope: "session", Spent: 9.5, Budget: 10}},
+			wantModel: "claude-sonnet-4-20250514",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := policy.Decide(tt.model, tt.usages...)
+			if decision.Model != tt.wantModel {
+				t.Errorf("Model = %q, want %q", decision.Model, tt.wantModel)
+			}
+			if decision.PlanMode != tt.wantPlan {
+				t.Errorf("PlanMode = %v, want %v", decision.PlanMode, tt.wantPlan)
+			}
+			if decision.Downgraded != tt.downgraded {
+				t.Errorf("Downgraded = %v, want %v", decision.Downgraded, tt.downgraded)
+			}
+			if decision.Scope != tt.wantScope {
+				t.Errorf("Scope = %q, want %q", decision.Scope, tt.wantScope)
+			}
+		})
+	}
+}
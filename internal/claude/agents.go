@@ -0,0 +1,82 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// AgentDefinition is a Claude Code custom sub-agent, in the format accepted
+// by the --agents flag
+type AgentDefinition struct {
+	Description string   `json:"description"`
+	Prompt      string   `json:"prompt"`
+	Tools       []string `json:"tools,omitempty"`
+	Model       string   `json:"model,omitempty"`
+}
+
+// Agents maps agent names to their definitions
+type Agents map[string]AgentDefinition
+
+// LoadAgents reads agent definitions from a JSON file of the form
+// {"reviewer": {"description": "...", "prompt": "..."}}
+func LoadAgents(path string) (Agents, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agents file: %w", err)
+	}
+
+	var agents Agents
+	if err := json.Unmarshal(data, &agents); err != nil {
+		return nil, fmt.Errorf("failed to parse agents file: %w", err)
+	}
+
+	for name, agent := range agents {
+		if agent.Description == "" || agent.Prompt == "" {
+			return nil, fmt.Errorf("agent %q must have a description and prompt", name)
+		}
+	}
+
+	return agents, nil
+}
+
+// Names returns the agent names in sorted order
+func (a Agents) Names() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select returns the named subset of agents; a nil list selects all of them.
+// Unknown names are ignored.
+func (a Agents) Select(names []string) Agents {
+	if names == nil {
+		return a
+	}
+
+	selected := make(Agents, len(names))
+	for _, name := range names {
+		if agent, ok := a[name]; ok {
+			selected[name] = agent
+		}
+	}
+	return selected
+}
+
+// JSON encodes the agents for the --agents flag, or returns an empty string
+// when there are none
+func (a Agents) JSON() (string, error) {
+	if len(a) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode agents: %w", err)
+	}
+	return string(data), nil
+}
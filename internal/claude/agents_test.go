@@ -0,0 +1,49 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAgents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.json")
+	content := `{
+		"reviewer": {"description": "Reviews diffs", "prompt": "You review code.", "tools": ["Read", "Grep"]},
+		"tester": {"description": "Runs tests", "prompt": "You run tests.", "model": "haiku"}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agents, err := LoadAgents(path)
+	if err != nil {
+		t.Fatalf("LoadAgents() error = %v", err)
+	}
+
+	names := agents.Names()
+	if len(names) != 2 || names[0] != "reviewer" || names[1] != "tester" {
+		t.Errorf("Names() = %v", names)
+	}
+
+	if selected := agents.Select(nil); len(selected) != 2 {
+		t.Errorf("Select(nil) returned %d agents, want 2", len(selected))
+	}
+	selected := agents.Select([]string{"tester", "unknown"})
+	if len(selected) != 1 || selected["tester"].Model != "haiku" {
+		t.Errorf("Select() = %v", selected)
+	}
+	if empty, _ := agents.Select([]string{}).JSON(); empty != "" {
+		t.Errorf("JSON() of no agents = %q, want empty", empty)
+	}
+}
+
+func TestLoadAgentsRequiresPrompt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.json")
+	if err := os.WriteFile(path, []byte(`{"reviewer": {"description": "Reviews diffs"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAgents(path); err == nil {
+		t.Error("expected error for agent without prompt")
+	}
+}
@@ -0,0 +1,60 @@
+package claude
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+const (
+	// redactedEnvValue replaces environment variable values in CLI output
+	redactedEnvValue = "[REDACTED]"
+
+	// Shorter values, such as "1" or "true", would blank out unrelated text
+	minRedactedEnvValueLength = 4
+)
+
+// envRedactor returns a replacer that hides the values of a run's extra
+// environment variables, which hold channel secrets, in the CLI's output
+// before it is logged, stored, or posted. Values are matched as written and
+// as escaped inside JSON strings. It returns nil when there is nothing to hide.
+func envRedactor(env []string) *strings.Replacer {
+	seen := make(map[string]bool)
+	var values []string
+	for _, pair := range env {
+		_, value, ok := strings.Cut(pair, "=")
+		if !ok || len(value) < minRedactedEnvValueLength {
+			continue
+		}
+		escaped, _ := json.Marshal(value)
+		for _, form := range []string{value, string(escaped[1 : len(escaped)-1])} {
+			if !seen[form] {
+				seen[form] = true
+				values = append(values, form)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	// At the same position the replacer takes the first matching value, so
+	// longer values come first
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	pairs := make([]string, 0, len(values)*2)
+	for _, value := range values {
+		pairs = append(pairs, value, redactedEnvValue)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// redactEnvBuffer replaces a buffer's contents with its redacted form
+func redactEnvBuffer(redactor *strings.Replacer, buf *bytes.Buffer) {
+	if redactor == nil {
+		return
+	}
+	redacted := redactor.Replace(buf.String())
+	buf.Reset()
+	buf.WriteString(redacted)
+}
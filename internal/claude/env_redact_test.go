@@ -0,0 +1,30 @@
+package claude
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEnvRedactor(t *testing.T) {
+	redactor := envRedactor([]string{"API_TOKEN=tok-123456", "DEBUG=1", "PASSWORD=pa\"ss\\word", "API_TOKEN_PREFIX=tok-123"})
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"token is tok-123456.", "token is [REDACTED]."},
+		{"prefix tok-123 only", "prefix [REDACTED] only"},
+		{`{"result":"the password is pa\"ss\\word"}`, `{"result":"the password is [REDACTED]"}`},
+		{"DEBUG=1 stays readable", "DEBUG=1 stays readable"},
+	}
+	for _, tt := range tests {
+		buf := bytes.NewBufferString(tt.input)
+		if redactEnvBuffer(redactor, buf); buf.String() != tt.want {
+			t.Errorf("redactEnvBuffer(%q) = %q, want %q", tt.input, buf.String(), tt.want)
+		}
+	}
+
+	if envRedactor([]string{"DEBUG=1"}) != nil {
+		t.Error("envRedactor() should be nil when no value is long enough to redact")
+	}
+}
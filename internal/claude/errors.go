@@ -0,0 +1,93 @@
+package claude
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorKindSessionNotFound is the kind of error returned when --resume names
+// a session the CLI no longer has locally, e.g. after a host restart
+const ErrorKindSessionNotFound = "session_not_found"
+
+// ErrorKindFixtureMissing is the kind of error returned in fixture mode when
+// no fixture answers a prompt
+const ErrorKindFixtureMissing = "fixture_missing"
+
+// ErrorKindRateLimited is the kind of error returned when the API refused a
+// run because of rate limits or overload
+const ErrorKindRateLimited = "rate_limited"
+
+// ExecutionError is returned when the Claude Code CLI exits with an error.
+// Its message is the detailed, user-facing explanation.
+type ExecutionError struct {
+	Kind   string // Category such as "network_error" or "timeout"
+	Stderr string // Raw stderr from the CLI
+	Err    error
+}
+
+func (e *ExecutionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorKind categorizes an error returned for a Claude run, for reporting
+func ErrorKind(err error) string {
+	var execErr *ExecutionError
+	if errors.As(err, &execErr) {
+		return execErr.Kind
+	}
+	return "claude_error"
+}
+
+// ErrorStderr returns the CLI's stderr captured with err, if any
+func ErrorStderr(err error) string {
+	var execErr *ExecutionError
+	if errors.As(err, &execErr) {
+		return execErr.Stderr
+	}
+	return ""
+}
+
+// IsSessionNotFound reports whether a run failed because the session it
+// resumed no longer exists
+func IsSessionNotFound(err error) bool {
+	return ErrorKind(err) == ErrorKindSessionNotFound
+}
+
+// mentionsMissingSession reports whether CLI output says the resumed session
+// doesn't exist
+func mentionsMissingSession(output string) bool {
+	return strings.Contains(strings.ToLower(output), "no conversation found with session id")
+}
+
+// rateLimitMarkers are what the CLI prints when the API rate limits or sheds
+// load, e.g. `API Error: 529 {"type":"overloaded_error",...}`
+var rateLimitMarkers = []string{
+	"rate_limit_error",
+	"overloaded_error",
+	"rate limit",
+	"too many requests",
+	"api error: 429",
+	"api error: 529",
+}
+
+// mentionsRateLimit reports whether CLI output says the API rate limited or
+// was overloaded
+func mentionsRateLimit(output string) bool {
+	output = strings.ToLower(output)
+	for _, marker := range rateLimitMarkers {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRateLimited reports whether a run failed because the API was rate
+// limiting or overloaded
+func IsRateLimited(err error) bool {
+	return ErrorKind(err) == ErrorKindRateLimited
+}
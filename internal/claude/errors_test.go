@@ -0,0 +1,64 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorKind(t *testing.T) {
+	execErr := &ExecutionError{Kind: "network_error", Stderr: "connection refused", Err: errors.New("failed")}
+	partial := &PartialResultError{Err: execErr, Partial: "half done"}
+
+	for _, err := range []error{execErr, partial, fmt.Errorf("wrapped: %w", partial)} {
+		if kind := ErrorKind(err); kind != "network_error" {
+			t.Errorf("ErrorKind(%v) = %q, want network_error", err, kind)
+		}
+		if stderr := ErrorStderr(err); stderr != "connection refused" {
+			t.Errorf("ErrorStderr(%v) = %q", err, stderr)
+		}
+	}
+
+	if kind := ErrorKind(errors.New("claude code error: overloaded")); kind != "claude_error" {
+		t.Errorf("ErrorKind() = %q, want claude_error", kind)
+	}
+}
+
+func TestIsSessionNotFound(t *testing.T) {
+	e := &Executor{}
+	kind := e.categorizeError(errors.New("exit status 1"), "No conversation found with session ID: 0b6c7c1e-8a6d-4f0e-9a64-1f1b2e3c4d5e")
+	if kind != ErrorKindSessionNotFound {
+		t.Fatalf("categorizeError() = %q, want %q", kind, ErrorKindSessionNotFound)
+	}
+
+	err := fmt.Errorf("run failed: %w", &ExecutionError{Kind: kind, Err: errors.New("failed")})
+	if !IsSessionNotFound(err) {
+		t.Error("Expected a wrapped session_not_found error to be detected")
+	}
+	if IsSessionNotFound(&ExecutionError{Kind: "network_error", Err: errors.New("failed")}) {
+		t.Error("Expected other error kinds not to be detected")
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	e := &Executor{}
+	for _, stderr := range []string{
+		`API Error: 529 {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+		`API Error: 429 {"type":"error","error":{"type":"rate_limit_error"}}`,
+		"Claude AI usage limit reached: too many requests",
+	} {
+		if kind := e.categorizeError(errors.New("exit status 1"), stderr); kind != ErrorKindRateLimited {
+			t.Errorf("categorizeError(%q) = %q, want %q", stderr, kind, ErrorKindRateLimited)
+		}
+	}
+	if kind := e.categorizeError(errors.New("exit status 1"), "connection refused"); kind == ErrorKindRateLimited {
+		t.Error("Expected a network error not to count as a rate limit")
+	}
+
+	if !IsRateLimited(fmt.Errorf("run failed: %w", &ExecutionError{Kind: ErrorKindRateLimited, Err: errors.New("failed")})) {
+		t.Error("Expected a wrapped rate_limited error to be detected")
+	}
+	if IsRateLimited(errors.New("claude code error: overloaded")) {
+		t.Error("Expected an uncategorized error not to be detected")
+	}
+}
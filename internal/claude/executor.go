@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -14,6 +16,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/workspace"
 )
 
 
@@ -22,6 +25,7 @@ type Executor struct {
 	config        *config.Config
 	logger        *zap.Logger
 	claudeCodePath string
+	fixtures      *fixtureStore // Set in fixture mode, which never runs the CLI
 }
 
 // ClaudeCodeResponse represents the response from Claude Code CLI
@@ -35,12 +39,64 @@ type ClaudeCodeResponse struct {
 	Usage        ClaudeUsage `json:"usage"`
 	Error        string      `json:"error,omitempty"`
 	LatestResponse string    `json:"-"` // Raw JSON response
+	ToolRuns     []ToolRun   `json:"-"` // Tool invocations from the stream
+
+	// Usage of the run's last top-level model call, when the stream reported it
+	lastCallUsage *ClaudeUsage
 }
 
 // ClaudeUsage represents token usage information
 type ClaudeUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+}
+
+// Total returns all input tokens, cached or not, plus output tokens
+func (u ClaudeUsage) Total() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens + u.OutputTokens
+}
+
+// ContextTokens estimates how many tokens the conversation occupies in the
+// model's context window after this run: the prompt of the last model call
+// plus its reply. Without per-call usage it falls back to the run's totals,
+// which overestimate runs that made several calls.
+func (r *ClaudeCodeResponse) ContextTokens() int {
+	if r.lastCallUsage != nil {
+		return r.lastCallUsage.Total()
+	}
+	return r.Usage.Total()
+}
+
+// RunOptions carries optional per-request inputs for a Claude Code run
+type RunOptions struct {
+	// ExtraSystemPrompt is appended after the built-in Slack system prompt
+	ExtraSystemPrompt string
+	// Guardrails are admin-set constraints appended last, after
+	// ExtraSystemPrompt, so nothing else in the prompt can follow them
+	Guardrails string
+	// Model overrides the configured model for this run
+	Model string
+	// Agents are the custom sub-agents available to this run
+	Agents Agents
+	// Env holds extra KEY=VALUE environment variables for the CLI process
+	Env []string
+	// DisallowedTools are tools Claude may not use in this run, on top of
+	// those its permission mode denies
+	DisallowedTools []string
+	// AddDirs are directories beyond the working directory that Claude may
+	// read and edit, e.g. other repositories a task spans
+	AddDirs []string
+	// NoImageDir keeps the directory Slack images are downloaded to out of
+	// reach, for runs that mustn't read other channels' files
+	NoImageDir bool
+	// OnTodos is called with Claude's task list each time it is updated
+	// during the run. It is called from the output reader, so it must not block.
+	OnTodos func([]Todo)
+	// OnToolUse is called with each top-level tool call as Claude makes it.
+	// Like OnTodos, it is called from the output reader and must not block.
+	OnToolUse func(ToolRun)
 }
 
 // Message represents a conversation message
@@ -54,6 +110,8 @@ type Message struct {
 type CommandResult struct {
 	Command    string        `json:"command"`
 	Output     string        `json:"output"`
+	Stdout     string        `json:"-"` // Untruncated stdout
+	Stderr     string        `json:"-"` // Untruncated stderr
 	Error      string        `json:"error"`
 	ExitCode   int           `json:"exit_code"`
 	Duration   time.Duration `json:"duration"`
@@ -62,39 +120,85 @@ type CommandResult struct {
 
 // NewExecutor creates a new Claude Code executor
 func NewExecutor(cfg *config.Config, logger *zap.Logger) (*Executor, error) {
+	executor := NewUnverifiedExecutor(cfg, logger)
+	if err := executor.Verify(); err != nil {
+		return nil, err
+	}
+	return executor, nil
+}
+
+// NewUnverifiedExecutor creates an executor without checking that the Claude
+// Code CLI works, for starting up before it is installed. Call Verify before
+// relying on it.
+func NewUnverifiedExecutor(cfg *config.Config, logger *zap.Logger) *Executor {
 	// Detect Claude Code CLI path
 	claudePath := "claude"
 	if envPath := os.Getenv("CLAUDE_CODE_PATH"); envPath != "" {
 		claudePath = envPath
 	}
-	
+
+	executor := &Executor{
+		config:        cfg,
+		logger:        logger,
+		claudeCodePath: claudePath,
+	}
+	if cfg.ExecutorMode == config.ExecutorFixture {
+		executor.fixtures = &fixtureStore{dir: cfg.ExecutorFixturesDir}
+	}
+	return executor
+}
+
+// Verify checks that the Claude Code CLI is installed and responds
+func (e *Executor) Verify() error {
+	if e.fixtures != nil {
+		e.logger.Warn("Serving canned Claude responses instead of running the CLI",
+			zap.String("fixtures_dir", e.fixtures.dir))
+		return nil
+	}
+
 	// Validate that Claude Code CLI is available
-	if _, err := exec.LookPath(claudePath); err != nil {
-		return nil, fmt.Errorf("claude code CLI not found in PATH: %w", err)
+	if _, err := exec.LookPath(e.claudeCodePath); err != nil {
+		return fmt.Errorf("claude code CLI not found in PATH: %w", err)
 	}
 	
 	// Test Claude Code CLI
-	cmd := exec.Command(claudePath, "--version")
+	cmd := exec.Command(e.claudeCodePath, "--version")
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("claude code CLI not responding: %w", err)
+		return fmt.Errorf("claude code CLI not responding: %w", err)
 	}
 	
-	logger.Info("Claude Code CLI detected", zap.String("path", claudePath))
-	
-	return &Executor{
-		config:        cfg,
-		logger:        logger,
-		claudeCodePath: claudePath,
-	}, nil
+	e.logger.Info("Claude Code CLI detected", zap.String("path", e.claudeCodePath))
+	return nil
+}
+
+// CheckCLI reports whether the Claude Code CLI is still installed and executable
+func (e *Executor) CheckCLI() error {
+	if e.fixtures != nil {
+		return nil
+	}
+	if _, err := exec.LookPath(e.claudeCodePath); err != nil {
+		return fmt.Errorf("claude code CLI not found: %w", err)
+	}
+	return nil
 }
 
 // ExecuteClaudeCode executes a request using Claude Code CLI
-func (e *Executor) ExecuteClaudeCode(ctx context.Context, userMessage string, sessionID string, workingDir string, allowedTools []string, isNewSession bool, permissionMode config.PermissionMode) (*ClaudeCodeResponse, error) {
+func (e *Executor) ExecuteClaudeCode(ctx context.Context, userMessage string, sessionID string, workingDir string, allowedTools []string, isNewSession bool, permissionMode config.PermissionMode, opts RunOptions) (*ClaudeCodeResponse, error) {
+	if e.fixtures != nil {
+		return e.executeFixture(userMessage, sessionID, opts)
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = e.config.ClaudeModel
+	}
+
 	// Prepare Claude Code CLI arguments
 	args := []string{
 		"--print",
-		"--output-format", "json",
-		"--model", "sonnet",
+		"--output-format", "stream-json",
+		"--verbose", // Required by stream-json in print mode
+		"--model", model,
 	}
 	
 	// Add session flag based on whether it's a new session or continuation
@@ -106,18 +210,39 @@ func (e *Executor) ExecuteClaudeCode(ctx context.Context, userMessage string, se
 		}
 	}
 	
+	// Remove tools the permission mode denies, e.g. Bash and edits in plan mode
+	allowedTools, disallowedTools := config.CapabilitiesFor(permissionMode).RestrictTools(allowedTools)
+	disallowedTools = append(disallowedTools, opts.DisallowedTools...)
+
 	// Add allowed tools if specified (empty means all tools available)
 	if len(allowedTools) > 0 {
 		args = append(args, "--allowedTools", strings.Join(allowedTools, ","))
 	}
 	// If allowedTools is empty, don't add --allowedTools flag = Claude Code uses all tools
+	if len(disallowedTools) > 0 {
+		args = append(args, "--disallowedTools", strings.Join(disallowedTools, ","))
+	}
 	
 	// Add permission mode
 	args = append(args, "--permission-mode", string(permissionMode))
 	
 	// Add image storage directory for file access
-	imageStorageDir := "/tmp/claude-slack-images"
-	args = append(args, "--add-dir", imageStorageDir)
+	if !opts.NoImageDir {
+		imageStorageDir := "/tmp/claude-slack-images"
+		args = append(args, "--add-dir", imageStorageDir)
+	}
+	for _, dir := range opts.AddDirs {
+		args = append(args, "--add-dir", dir)
+	}
+
+	// Add custom sub-agents
+	agentsJSON, err := opts.Agents.JSON()
+	if err != nil {
+		return nil, err
+	}
+	if agentsJSON != "" {
+		args = append(args, "--agents", agentsJSON)
+	}
 	
 	// Add system prompt for Slack bot context
 	systemPrompt := `You are Claude Code running in a Slack bot environment with full non-root access to the owner's machine. Your thought process and internal reasoning are not visible to users in Slack, so your final responses should be more verbose and explain how you accomplished tasks.
@@ -159,11 +284,20 @@ Remember: You have full access to the machine's capabilities, but always priorit
 - Use appropriate emojis for visual clarity
 - *Never use markdown headings (## text)* - use *bold text:* instead
 - Only use markdown formatting if explicitly requested by the user`
+	if opts.ExtraSystemPrompt != "" {
+		systemPrompt += "\n\n" + opts.ExtraSystemPrompt
+	}
+	if opts.Guardrails != "" {
+		systemPrompt += "\n\n" + GuardrailsPrompt(opts.Guardrails)
+	}
 	args = append(args, "--append-system-prompt", systemPrompt)
 	
 	// Create command with timeout
 	cmd := exec.CommandContext(ctx, e.claudeCodePath, args...)
 	cmd.Dir = workingDir
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
 	
 	// Set up stdin with user message
 	cmd.Stdin = strings.NewReader(userMessage)
@@ -172,6 +306,9 @@ Remember: You have full access to the machine's capabilities, but always priorit
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	if watcher := opts.streamWatcher(); watcher != nil {
+		cmd.Stdout = io.MultiWriter(&stdout, watcher)
+	}
 	
 	// Log the complete command for debugging
 	fullCommand := fmt.Sprintf("echo '%s' | %s %s", userMessage, e.claudeCodePath, strings.Join(args, " "))
@@ -180,15 +317,21 @@ Remember: You have full access to the machine's capabilities, but always priorit
 		zap.String("session_id", sessionID),
 		zap.String("working_dir", workingDir),
 		zap.Strings("allowed_tools", allowedTools),
+		zap.Strings("disallowed_tools", disallowedTools),
 		zap.Strings("args", args),
 		zap.Bool("is_new_session", isNewSession),
 		zap.String("full_command", fullCommand))
 	
 	// Execute command
 	start := time.Now()
-	err := cmd.Run()
+	err = cmd.Run()
 	duration := time.Since(start)
 	
+	// Channel env values must not reach logs, stored responses, or Slack
+	redactor := envRedactor(opts.Env)
+	redactEnvBuffer(redactor, &stdout)
+	redactEnvBuffer(redactor, &stderr)
+	
 	if err != nil {
 		stderrOutput := strings.TrimSpace(stderr.String())
 		e.logger.Error("Claude Code CLI execution failed",
@@ -204,27 +347,59 @@ Remember: You have full access to the machine's capabilities, but always priorit
 			"args":          args,
 			"full_command":  fullCommand,
 		}
-		enhancedErr := e.createEnhancedError(err, stderrOutput, duration, debugInfo)
+		kind := e.categorizeError(err, stderrOutput)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			kind = "timeout"
+		} else if ctx.Err() != nil {
+			kind = "canceled"
+		}
+		enhancedErr := &ExecutionError{
+			Kind:   kind,
+			Stderr: stderrOutput,
+			Err:    e.createEnhancedError(err, stderrOutput, duration, debugInfo),
+		}
+
+		// Keep whatever Claude produced before failing instead of discarding it
+		if partial, partialSessionID := salvagePartialOutput(stdout.Bytes()); partial != "" {
+			e.logger.Warn("Salvaged partial Claude Code output before failure",
+				zap.String("session_id", sessionID),
+				zap.String("partial_session_id", partialSessionID),
+				zap.Int("partial_length", len(partial)))
+			return nil, &PartialResultError{
+				Err:       enhancedErr,
+				Partial:   partial,
+				SessionID: partialSessionID,
+				RawOutput: stdout.String(),
+			}
+		}
 		return nil, enhancedErr
 	}
 	
-	// Parse JSON response
-	var response ClaudeCodeResponse
-	responseBytes := stdout.Bytes()
-	if err := json.Unmarshal(responseBytes, &response); err != nil {
+	// Parse stream-json response; the result event is kept as the raw response
+	response, err := parseStreamOutput(stdout.Bytes())
+	if err != nil {
 		e.logger.Error("Failed to parse Claude Code response",
 			zap.Error(err),
 			zap.String("stdout", stdout.String()))
 		return nil, fmt.Errorf("failed to parse Claude Code response: %w", err)
 	}
 	
-	// Save raw response
-	response.LatestResponse = string(responseBytes)
-	
 	// Check for errors in response
 	if response.IsError {
 		e.logger.Error("Claude Code returned error",
 			zap.String("error", response.Error))
+		if mentionsMissingSession(response.Error) {
+			return nil, &ExecutionError{
+				Kind: ErrorKindSessionNotFound,
+				Err:  fmt.Errorf("claude code error: %s", response.Error),
+			}
+		}
+		if mentionsRateLimit(response.Error) {
+			return nil, &ExecutionError{
+				Kind: ErrorKindRateLimited,
+				Err:  fmt.Errorf("claude code error: %s", response.Error),
+			}
+		}
 		return nil, fmt.Errorf("claude code error: %s", response.Error)
 	}
 	
@@ -233,9 +408,31 @@ Remember: You have full access to the machine's capabilities, but always priorit
 		zap.Float64("cost_usd", response.TotalCostUSD),
 		zap.Int("input_tokens", response.Usage.InputTokens),
 		zap.Int("output_tokens", response.Usage.OutputTokens),
+		zap.Int("tool_runs", len(response.ToolRuns)),
 		zap.Duration("duration", duration))
 	
-	return &response, nil
+	return response, nil
+}
+
+// executeFixture answers a run from EXECUTOR_FIXTURES_DIR, reporting the
+// same errors and callbacks a CLI run would
+func (e *Executor) executeFixture(userMessage, sessionID string, opts RunOptions) (*ClaudeCodeResponse, error) {
+	response, err := e.fixtures.response(userMessage, sessionID, opts.streamWatcher())
+	if err != nil {
+		e.logger.Error("Failed to serve Claude fixture",
+			zap.String("fixture", FixtureKey(userMessage)),
+			zap.String("session_id", sessionID),
+			zap.Error(err))
+		return nil, err
+	}
+	if response.IsError {
+		return nil, fmt.Errorf("claude code error: %s", response.Error)
+	}
+
+	e.logger.Info("Served Claude fixture",
+		zap.String("fixture", FixtureKey(userMessage)),
+		zap.String("session_id", response.SessionID))
+	return response, nil
 }
 
 // createEnhancedError creates a detailed error message with context and troubleshooting information
@@ -267,6 +464,9 @@ func (e *Executor) createEnhancedError(originalErr error, stderrOutput string, d
 	case "file_not_found":
 		return fmt.Errorf("%s\n\n📁 **File Not Found**\nRequired file or directory does not exist.\n\n**Stderr Output:**\n```\n%s\n```\n\n**Troubleshooting:**\n• Check file paths are correct\n• Verify files exist in expected locations\n• Check working directory", baseMsg, stderrOutput)
 	
+	case ErrorKindSessionNotFound:
+		return fmt.Errorf("%s\n\n🧩 **Session Not Found**\nClaude Code no longer has this conversation locally, e.g. after a restart or cache wipe.\n\n**Stderr Output:**\n```\n%s\n```\n\n**Troubleshooting:**\n• Start a fresh conversation with `/session new`\n• Switch to another session with `/session <session-id>`", baseMsg, stderrOutput)
+	
 	case "timeout":
 		return fmt.Errorf("%s\n\n⏱️ **Operation Timeout**\nThe operation took too long to complete.\n\n**Stderr Output:**\n```\n%s\n```\n\n**Troubleshooting:**\n• Operation may require more time\n• Check system resources\n• Try breaking down into smaller tasks", baseMsg, stderrOutput)
 	
@@ -289,6 +489,16 @@ func (e *Executor) categorizeError(originalErr error, stderrOutput string) strin
 	// Combined text for analysis
 	combinedText := errorStr + " " + stderrLower
 	
+	// Check for a resumed session the CLI no longer has
+	if mentionsMissingSession(combinedText) {
+		return ErrorKindSessionNotFound
+	}
+	
+	// Check for API rate limits and overload, which clear up on their own
+	if mentionsRateLimit(combinedText) {
+		return ErrorKindRateLimited
+	}
+	
 	// Check for permission errors
 	if strings.Contains(combinedText, "permission denied") ||
 		strings.Contains(combinedText, "access denied") ||
@@ -418,13 +628,13 @@ func (e *Executor) ExecuteCommand(ctx context.Context, command string, workingDi
 	stdoutStr := stdout.String()
 	stderrStr := stderr.String()
 
+	// Keep the untruncated output for attachments
+	result.Stdout = stdoutStr
+	result.Stderr = stderrStr
+
 	// Limit output length
-	if len(stdoutStr) > e.config.MaxOutputLength {
-		stdoutStr = stdoutStr[:e.config.MaxOutputLength] + "\n... (output truncated)"
-	}
-	if len(stderrStr) > e.config.MaxOutputLength {
-		stderrStr = stderrStr[:e.config.MaxOutputLength] + "\n... (output truncated)"
-	}
+	stdoutStr = TruncateMiddle(stdoutStr, e.config.MaxOutputLength)
+	stderrStr = TruncateMiddle(stderrStr, e.config.MaxOutputLength)
 
 	result.Output = stdoutStr
 	if stderrStr != "" {
@@ -461,7 +671,7 @@ func (e *Executor) ExecuteCommand(ctx context.Context, command string, workingDi
 
 
 // ProcessClaudeCodeRequest processes a request using Claude Code CLI
-func (e *Executor) ProcessClaudeCodeRequest(ctx context.Context, userMessage string, sessionID string, userID string, workingDir string, allowedTools []string, isNewSession bool, permissionMode config.PermissionMode) (string, string, float64, string, error) {
+func (e *Executor) ProcessClaudeCodeRequest(ctx context.Context, userMessage string, sessionID string, userID string, workingDir string, allowedTools []string, isNewSession bool, permissionMode config.PermissionMode, opts RunOptions) (*ClaudeCodeResponse, error) {
 	// Use provided working directory, fallback to config if empty
 	if workingDir == "" {
 		workingDir = e.config.WorkingDirectory
@@ -479,7 +689,7 @@ func (e *Executor) ProcessClaudeCodeRequest(ctx context.Context, userMessage str
 	// Ensure working directory exists
 	if err := os.MkdirAll(workingDir, 0755); err != nil {
 		e.logger.Error("Failed to create working directory", zap.Error(err))
-		return "", "", 0, "", fmt.Errorf("failed to create working directory: %w", err)
+		return nil, fmt.Errorf("failed to create working directory: %w", err)
 	}
 
 	e.logger.Info("Processing Claude Code request",
@@ -488,10 +698,10 @@ func (e *Executor) ProcessClaudeCodeRequest(ctx context.Context, userMessage str
 		zap.String("working_dir", workingDir))
 
 	// Execute Claude Code CLI
-	response, err := e.ExecuteClaudeCode(ctx, userMessage, sessionID, workingDir, allowedTools, isNewSession, permissionMode)
+	response, err := e.ExecuteClaudeCode(ctx, userMessage, sessionID, workingDir, allowedTools, isNewSession, permissionMode, opts)
 	if err != nil {
 		e.logger.Error("Failed to execute Claude Code", zap.Error(err))
-		return "", "", 0, "", fmt.Errorf("failed to execute Claude Code: %w", err)
+		return nil, fmt.Errorf("failed to execute Claude Code: %w", err)
 	}
 
 	e.logger.Info("Claude Code request completed",
@@ -501,11 +711,40 @@ func (e *Executor) ProcessClaudeCodeRequest(ctx context.Context, userMessage str
 		zap.Int("input_tokens", response.Usage.InputTokens),
 		zap.Int("output_tokens", response.Usage.OutputTokens))
 
-	return response.Result, response.SessionID, response.TotalCostUSD, response.LatestResponse, nil
+	return response, nil
+}
+
+// TruncateMiddle shortens text to about maxLength by dropping the middle, since
+// the end of a long log (errors, summaries) is usually the most useful part
+func TruncateMiddle(text string, maxLength int) string {
+	if maxLength <= 0 || len(text) <= maxLength {
+		return text
+	}
+
+	head := maxLength / 4
+	tail := maxLength - head
+	omitted := len(text) - head - tail
+	return fmt.Sprintf("%s\n... (%d bytes truncated) ...\n%s", text[:head], omitted, text[len(text)-tail:])
 }
 
-// CreateWorkspace creates a dedicated workspace directory for a user session
-func (e *Executor) CreateWorkspace(userID, sessionID string) (string, error) {
+// workspaceCloneTimeout bounds cloning WORKDIR_REPO into a new templated workspace
+const workspaceCloneTimeout = 5 * time.Minute
+
+// worktreeTimeout bounds creating or removing a session worktree
+const worktreeTimeout = time.Minute
+
+// CreateWorkspace returns the workspace directory for a new user session,
+// creating it if needed. With WORKDIR_TEMPLATE set, each user/channel pair
+// gets its own directory, cloned from WORKDIR_REPO the first time.
+func (e *Executor) CreateWorkspace(userID, channelID, sessionID string) (string, error) {
+	if e.config.WorkdirTemplate != "" {
+		workspaceDir, err := e.provisionWorkspace(userID, channelID, sessionID)
+		if err != nil {
+			return "", err
+		}
+		return e.SessionWorkDir(workspaceDir, sessionID)
+	}
+
 	// Just use the base working directory - no nested sessions folders
 	workspaceDir := e.config.WorkingDirectory
 	
@@ -522,15 +761,90 @@ func (e *Executor) CreateWorkspace(userID, sessionID string) (string, error) {
 		zap.String("user_id", userID),
 		zap.String("session_id", sessionID))
 
+	return e.SessionWorkDir(workspaceDir, sessionID)
+}
+
+// SessionWorkDir returns the directory a new session works in. With
+// SESSION_WORKTREES on and dir in a git repository, that's the matching
+// directory in a worktree of the session's own, on a claude/session-<id>
+// branch; otherwise it's dir.
+func (e *Executor) SessionWorkDir(dir, sessionID string) (string, error) {
+	if !e.config.SessionWorktrees {
+		return dir, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), worktreeTimeout)
+	defer cancel()
+
+	workDir, err := workspace.AddSessionWorktree(ctx, dir, e.config.SessionWorktreeDir, sessionID)
+	if err != nil {
+		e.logger.Error("Failed to create session worktree",
+			zap.Error(err),
+			zap.String("dir", dir),
+			zap.String("session_id", sessionID))
+		return "", fmt.Errorf("failed to create a worktree for %s: %w", dir, err)
+	}
+	if workDir != dir {
+		e.logger.Info("Created session worktree",
+			zap.String("repository_dir", dir),
+			zap.String("worktree_dir", workDir),
+			zap.String("session_id", sessionID))
+	}
+	return workDir, nil
+}
+
+// provisionWorkspace expands WORKDIR_TEMPLATE for the user and channel and
+// makes sure the directory exists
+func (e *Executor) provisionWorkspace(userID, channelID, sessionID string) (string, error) {
+	workspaceDir := workspace.ExpandTemplate(e.config.WorkdirTemplate, userID, channelID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), workspaceCloneTimeout)
+	defer cancel()
+
+	created, err := workspace.Provision(ctx, workspaceDir, e.config.WorkdirRepo)
+	if err != nil {
+		e.logger.Error("Failed to provision workspace",
+			zap.Error(err),
+			zap.String("workspace", workspaceDir),
+			zap.String("repo", e.config.WorkdirRepo))
+		return "", fmt.Errorf("failed to provision workspace %s: %w", workspaceDir, err)
+	}
+
+	e.logger.Info("Using templated workspace",
+		zap.String("workspace", workspaceDir),
+		zap.Bool("created", created),
+		zap.String("user_id", userID),
+		zap.String("channel_id", channelID),
+		zap.String("session_id", sessionID))
+
 	return workspaceDir, nil
 }
 
 // CleanupWorkspace removes a workspace directory
 func (e *Executor) CleanupWorkspace(workspaceDir string) error {
-	if workspaceDir == "" || !strings.Contains(workspaceDir, e.config.WorkingDirectory) {
+	// A session worktree is removed through git, along with its branch unless
+	// it has unmerged commits
+	ctx, cancel := context.WithTimeout(context.Background(), worktreeTimeout)
+	defer cancel()
+	if wt, err := workspace.FindSessionWorktree(ctx, workspaceDir); err == nil {
+		kept, err := workspace.DiscardSessionWorktree(ctx, wt)
+		if err != nil {
+			e.logger.Error("Failed to remove session worktree", zap.Error(err), zap.String("workspace", wt.Dir))
+			return fmt.Errorf("failed to remove session worktree: %w", err)
+		}
+		e.logger.Info("Removed session worktree", zap.String("workspace", wt.Dir), zap.String("branch", wt.Branch), zap.Bool("branch_kept", kept))
+		return nil
+	}
+
+	if workspaceDir == "" || e.config.WorkingDirectory == "" || !strings.Contains(workspaceDir, e.config.WorkingDirectory) {
 		return fmt.Errorf("invalid workspace directory")
 	}
 
+	// Templated workspaces outlive sessions and may hold a clone with local work
+	if e.config.WorkdirTemplate != "" {
+		return fmt.Errorf("templated workspaces are not removed")
+	}
+
 	if err := os.RemoveAll(workspaceDir); err != nil {
 		e.logger.Error("Failed to cleanup workspace", zap.Error(err), zap.String("workspace", workspaceDir))
 		return fmt.Errorf("failed to cleanup workspace: %w", err)
@@ -565,11 +879,64 @@ Your summary should be optimized for context compression while preserving maximu
 
 The summary should be comprehensive enough that someone could read it and immediately understand the full context to continue the technical work without missing any important details.`
 
-	// Prepare Claude Code CLI arguments for disposable summarization
+	// Prepare the user message (conversation to summarize)
+	userMessage := fmt.Sprintf("**CONVERSATION TO SUMMARIZE:**\n\n%s", conversationText)
+
+	return e.executeDisposable(ctx, "summarization", "sonnet", systemPrompt, userMessage)
+}
+
+// ExecuteClaudeRecap writes a short "where we left off" recap of a
+// conversation, used to restore context when switching back to a session
+func (e *Executor) ExecuteClaudeRecap(ctx context.Context, conversationText string) (string, error) {
+	systemPrompt := `You write short recaps of technical conversations between a user and an AI assistant so the work can be picked up again later.
+
+Write at most 5 bullet points covering what the user was working on, what was done, and where the conversation left off, including open questions or next steps. Mention specific file paths, commands, and decisions. Do not add a heading or any text besides the bullets.`
+
+	userMessage := fmt.Sprintf("**CONVERSATION TO RECAP:**\n\n%s", conversationText)
+
+	return e.executeDisposable(ctx, "recap", "sonnet", systemPrompt, userMessage)
+}
+
+// ExecuteClaudeTitle asks model for a short title for a conversation from
+// its first exchange and returns it, stripped of quotes and punctuation
+func (e *Executor) ExecuteClaudeTitle(ctx context.Context, model, conversationText string) (string, error) {
+	systemPrompt := `You name conversations between a user and a coding assistant. Reply with a title of at most 6 words that says what the user is working on, e.g. "Fix flaky login test" or "Postgres migration for audit log". Reply with the title only: no quotes, no trailing punctuation, no other text.`
+
+	result, err := e.executeDisposable(ctx, "title", model, systemPrompt, conversationText)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(strings.Trim(strings.TrimSpace(result), "`*\"'"), "."), nil
+}
+
+// ExecuteClaudeClassification asks model which of labels best describes a
+// chat message and returns its answer, lowercased and trimmed. It is meant
+// for cheap models; callers should treat an unexpected answer as unknown.
+func (e *Executor) ExecuteClaudeClassification(ctx context.Context, model string, labels []string, message string) (string, error) {
+	systemPrompt := fmt.Sprintf(`You classify chat messages sent to a coding assistant. Reply with exactly one of these labels and nothing else: %s.`, strings.Join(labels, ", "))
+
+	result, err := e.executeDisposable(ctx, "classification", model, systemPrompt, message)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(strings.Trim(strings.TrimSpace(result), ".`*\"")), nil
+}
+
+// executeDisposable runs a one-off Claude Code CLI call in a throwaway session
+// and returns its result. kind names the call in logs and errors.
+func (e *Executor) executeDisposable(ctx context.Context, kind, model, systemPrompt, userMessage string) (string, error) {
+	if e.fixtures != nil {
+		response, err := e.executeFixture(userMessage, "", RunOptions{})
+		if err != nil {
+			return "", fmt.Errorf("claude %s failed: %w", kind, err)
+		}
+		return response.Result, nil
+	}
+
 	args := []string{
 		"--print",
 		"--output-format", "json",
-		"--model", "sonnet",
+		"--model", model,
 		"--session-id", uuid.New().String(), // Disposable session ID
 	}
 
@@ -581,9 +948,6 @@ The summary should be comprehensive enough that someone could read it and immedi
 	// Add system prompt as a separate argument
 	args = append(args, "--append-system-prompt", systemPrompt)
 
-	// Prepare the user message (conversation to summarize)
-	userMessage := fmt.Sprintf("**CONVERSATION TO SUMMARIZE:**\n\n%s", conversationText)
-
 	// Execute Claude Code CLI
 	cmd := exec.CommandContext(ctx, e.claudeCodePath, args...)
 	cmd.Dir = e.config.WorkingDirectory
@@ -596,23 +960,23 @@ The summary should be comprehensive enough that someone could read it and immedi
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	e.logger.Info("Executing Claude summarization",
+	e.logger.Info("Executing Claude "+kind,
 		zap.String("claude_path", e.claudeCodePath),
 		zap.String("working_dir", e.config.WorkingDirectory),
-		zap.Int("conversation_length", len(conversationText)))
+		zap.Int("message_length", len(userMessage)))
 
 	start := time.Now()
 	err := cmd.Run()
 	duration := time.Since(start)
 
 	if err != nil {
-		e.logger.Error("Claude summarization failed",
+		e.logger.Error("Claude "+kind+" failed",
 			zap.Error(err),
 			zap.String("stderr", stderr.String()),
 			zap.Duration("duration", duration))
 
-		return "", fmt.Errorf("claude summarization failed after %v: %v\nStderr: %s", 
-			duration.Truncate(time.Millisecond), err, stderr.String())
+		return "", fmt.Errorf("claude %s failed after %v: %v\nStderr: %s", 
+			kind, duration.Truncate(time.Millisecond), err, stderr.String())
 	}
 
 	output := stdout.Bytes()
@@ -620,7 +984,7 @@ The summary should be comprehensive enough that someone could read it and immedi
 	// Parse Claude response 
 	var response ClaudeCodeResponse
 	if err := json.Unmarshal(output, &response); err != nil {
-		e.logger.Error("Failed to parse Claude summarization response",
+		e.logger.Error("Failed to parse Claude "+kind+" response",
 			zap.Error(err),
 			zap.String("raw_output", string(output)))
 		return "", fmt.Errorf("failed to parse Claude response: %w", err)
@@ -628,18 +992,18 @@ The summary should be comprehensive enough that someone could read it and immedi
 
 	// Check for Claude-level errors
 	if response.IsError {
-		e.logger.Error("Claude returned an error during summarization",
+		e.logger.Error("Claude returned an error during "+kind,
 			zap.String("error", response.Error),
 			zap.String("result", response.Result))
-		return "", fmt.Errorf("claude summarization error: %s", response.Error)
+		return "", fmt.Errorf("claude %s error: %s", kind, response.Error)
 	}
 
-	e.logger.Info("Claude summarization completed",
+	e.logger.Info("Claude "+kind+" completed",
 		zap.Duration("duration", duration),
 		zap.Float64("cost_usd", response.TotalCostUSD),
 		zap.Int("input_tokens", response.Usage.InputTokens),
 		zap.Int("output_tokens", response.Usage.OutputTokens),
-		zap.Int("summary_length", len(response.Result)))
+		zap.Int("result_length", len(response.Result)))
 
 	return response.Result, nil
-}
\ No newline at end of file
+}
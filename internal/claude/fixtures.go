@@ -0,0 +1,76 @@
+package claude
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// defaultFixture answers prompts that have no fixture of their own, when it exists
+const defaultFixture = "default.json"
+
+// fixtureStore serves canned Claude Code responses instead of running the
+// CLI, for local development and CI of the Slack pipeline. Each response is
+// a file named after the hash of the prompt it answers.
+type fixtureStore struct {
+	dir string
+}
+
+// FixtureKey returns the name of the fixture file that answers prompt: the
+// hex SHA-256 of the prompt without surrounding whitespace, plus .json
+func FixtureKey(prompt string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(prompt)))
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+// response returns the canned response for prompt. Fixtures may hold a
+// single --output-format json result, pretty-printed or not, or captured
+// stream-json output, which is fed to watcher as if the CLI were writing it.
+// The session ID the run asked for is kept so sessions resume as they would
+// with the CLI.
+func (f *fixtureStore) response(prompt, sessionID string, watcher io.Writer) (*ClaudeCodeResponse, error) {
+	key := FixtureKey(prompt)
+	data, err := os.ReadFile(filepath.Join(f.dir, key))
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = os.ReadFile(filepath.Join(f.dir, defaultFixture))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, &ExecutionError{
+				Kind: ErrorKindFixtureMissing,
+				Err:  fmt.Errorf("no fixture for this prompt: add %s or %s to %s", key, defaultFixture, f.dir),
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	// A single JSON document may span lines; stream-json is one event per line
+	var compact bytes.Buffer
+	if json.Compact(&compact, data) == nil {
+		data = compact.Bytes()
+	}
+	if watcher != nil {
+		watcher.Write(append(data, '\n'))
+	}
+	response, err := parseStreamOutput(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", key, err)
+	}
+
+	switch {
+	case sessionID != "":
+		response.SessionID = sessionID
+	case response.SessionID == "":
+		response.SessionID = uuid.New().String()
+	}
+	return response, nil
+}
@@ -0,0 +1,62 @@
+package claude
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+)
+
+func TestFixtureExecutor(t *testing.T) {
+	dir := t.TempDir()
+	pretty := "{\n  \"type\": \"result\",\n  \"result\": \"Hello from a fixture\",\n  \"session_id\": \"recorded\",\n  \"total_cost_usd\": 0.01\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, FixtureKey("say hello")), []byte(pretty), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stream := `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"TodoWrite","input":{"todos":[{"content":"Plan","status":"in_progress"}]}}]}}
+{"type":"result","result":"Planned","session_id":"recorded"}
+`
+	if err := os.WriteFile(filepath.Join(dir, FixtureKey("make a plan")), []byte(stream), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{ExecutorMode: config.ExecutorFixture, ExecutorFixturesDir: dir}
+	executor, err := NewExecutor(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	// Surrounding whitespace doesn't change the key; the requested session is kept
+	response, err := executor.ExecuteClaudeCode(context.Background(), "  say hello\n", "s1", dir, nil, true, config.PermissionModeDefault, RunOptions{})
+	if err != nil {
+		t.Fatalf("ExecuteClaudeCode() error = %v", err)
+	}
+	if response.Result != "Hello from a fixture" || response.SessionID != "s1" || response.TotalCostUSD != 0.01 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	var todos []Todo
+	response, err = executor.ExecuteClaudeCode(context.Background(), "make a plan", "", dir, nil, true, config.PermissionModeDefault, RunOptions{OnTodos: func(t []Todo) { todos = t }})
+	if err != nil {
+		t.Fatalf("ExecuteClaudeCode() error = %v", err)
+	}
+	if response.Result != "Planned" || response.SessionID != "recorded" || len(todos) != 1 {
+		t.Errorf("Unexpected stream fixture response: %+v, todos %v", response, todos)
+	}
+
+	if _, err := executor.ExecuteClaudeCode(context.Background(), "unknown", "", dir, nil, true, config.PermissionModeDefault, RunOptions{}); ErrorKind(err) != ErrorKindFixtureMissing {
+		t.Errorf("Expected a missing fixture error, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, defaultFixture), []byte(`{"type":"result","result":"Default"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := executor.ExecuteClaudeSummary(context.Background(), "anything")
+	if err != nil || summary != "Default" {
+		t.Errorf("ExecuteClaudeSummary() = %q, %v; want the default fixture", summary, err)
+	}
+}
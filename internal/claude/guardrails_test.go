@@ -0,0 +1,16 @@
+package claude
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGuardrailsPrompt(t *testing.T) {
+	prompt := GuardrailsPrompt("  Never touch .env  ")
+	if !strings.Contains(prompt, "<guardrails>\nNever touch .env\n</guardrails>") {
+		t.Errorf("Expected trimmed guardrails in a tagged block, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "override every other instruction") {
+		t.Errorf("Expected guardrails to be framed as overriding other instructions, got:\n%s", prompt)
+	}
+}
@@ -0,0 +1,103 @@
+package claude
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// PartialResultError is returned when Claude Code exits with an error after
+// already producing usable output, so callers can show the work done so far
+type PartialResultError struct {
+	Err       error
+	Partial   string // Text salvaged from stdout before the failure
+	SessionID string // Claude session ID seen in the output, if any
+	RawOutput string // Raw stdout, for /debug
+}
+
+func (e *PartialResultError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialResultError) Unwrap() error {
+	return e.Err
+}
+
+// partialMessage is the subset of Claude Code JSON / stream-json objects we
+// can salvage text from
+type partialMessage struct {
+	Type      string `json:"type"`
+	IsError   bool   `json:"is_error"`
+	Result    string `json:"result"`
+	SessionID string `json:"session_id"`
+	Message   struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"message"`
+}
+
+// salvagePartialOutput extracts whatever readable result exists in stdout of
+// a failed run. It understands a single JSON result object and stream-json
+// lines; anything else, such as plain text or error objects, is not salvaged.
+func salvagePartialOutput(stdout []byte) (text string, sessionID string) {
+	trimmed := bytes.TrimSpace(stdout)
+	if len(trimmed) == 0 {
+		return "", ""
+	}
+
+	// Complete JSON result object
+	var single partialMessage
+	if err := json.Unmarshal(trimmed, &single); err == nil {
+		if !single.isResult() {
+			return "", single.SessionID
+		}
+		return strings.TrimSpace(single.Result), single.SessionID
+	}
+
+	// Stream-json: one object per line, the last ones possibly truncated
+	var assistantText []string
+	var result string
+
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var msg partialMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			continue
+		}
+		if msg.SessionID != "" {
+			sessionID = msg.SessionID
+		}
+		if msg.isResult() {
+			result = msg.Result
+		}
+		if msg.Type == "assistant" {
+			for _, content := range msg.Message.Content {
+				if content.Type == "text" && strings.TrimSpace(content.Text) != "" {
+					assistantText = append(assistantText, strings.TrimSpace(content.Text))
+				}
+			}
+		}
+	}
+
+	if result != "" {
+		return strings.TrimSpace(result), sessionID
+	}
+	if len(assistantText) > 0 {
+		return strings.Join(assistantText, "\n\n"), sessionID
+	}
+	return "", sessionID
+}
+
+// isResult reports whether the message is a successful result with text
+func (m *partialMessage) isResult() bool {
+	return m.Type == "result" && !m.IsError && strings.TrimSpace(m.Result) != ""
+}
@@ -0,0 +1,71 @@
+package claude
+
+import "testing"
+
+func TestSalvagePartialOutput(t *testing.T) {
+	tests := []struct {
+		name          string
+		stdout        string
+		wantText      string
+		wantSessionID string
+	}{
+		{
+			name:   "empty",
+			stdout: "  \n",
+		},
+		{
+			name:          "single result object",
+			stdout:        `{"type":"result","result":"done so far","session_id":"abc"}`,
+			wantText:      "done so far",
+			wantSessionID: "abc",
+		},
+		{
+			name: "stream json with truncated tail",
+			stdout: `{"type":"system","subtype":"init","session_id":"s1"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Step one complete."}]},"session_id":"s1"}
+{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Bash"},{"type":"text","text":"Step two complete."}]},"session_id":"s1"}
+{"type":"assistant","message":{"content":[{"type":"te`,
+			wantText:      "Step one complete.\n\nStep two complete.",
+			wantSessionID: "s1",
+		},
+		{
+			name: "stream json prefers final result",
+			stdout: `{"type":"assistant","message":{"content":[{"type":"text","text":"partial"}]},"session_id":"s2"}
+{"type":"result","result":"final","session_id":"s2"}`,
+			wantText:      "final",
+			wantSessionID: "s2",
+		},
+		{
+			name:   "plain text",
+			stdout: "Error: API key expired\n",
+		},
+		{
+			name:          "error result",
+			stdout:        `{"type":"result","subtype":"error_during_execution","is_error":true,"result":"boom","session_id":"s3"}`,
+			wantSessionID: "s3",
+		},
+		{
+			name:   "json that is not a result",
+			stdout: `{"error":{"type":"overloaded_error","message":"Overloaded"},"result":"Overloaded"}`,
+		},
+		{
+			name: "stream json ignores error results",
+			stdout: `{"type":"assistant","message":{"content":[{"type":"text","text":"partial"}]},"session_id":"s4"}
+{"type":"result","is_error":true,"result":"crashed","session_id":"s4"}`,
+			wantText:      "partial",
+			wantSessionID: "s4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, sessionID := salvagePartialOutput([]byte(tt.stdout))
+			if text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+			if sessionID != tt.wantSessionID {
+				t.Errorf("sessionID = %q, want %q", sessionID, tt.wantSessionID)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package claude
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ToolRun is a single tool invocation reported in Claude Code stream-json output
+type ToolRun struct {
+	ID       string
+	Name     string
+	Command  string // Bash command, when Name is "Bash"
+	Agent    string // Sub-agent type, when the tool delegated to a sub-agent
+	FilePath string // Target file, for file tools such as Edit and Write
+	Output   string // Full, untruncated tool result
+	IsError  bool
+}
+
+// fileEditTools are the tools that modify the file named in file_path
+var fileEditTools = map[string]bool{
+	"Edit":         true,
+	"MultiEdit":    true,
+	"Write":        true,
+	"NotebookEdit": true,
+}
+
+// streamEvent is one line of Claude Code stream-json output
+type streamEvent struct {
+	Type         string      `json:"type"`
+	Subtype      string      `json:"subtype"`
+	SessionID    string      `json:"session_id"`
+	IsError      bool        `json:"is_error"`
+	Result       string      `json:"result"`
+	TotalCostUSD float64     `json:"total_cost_usd"`
+	Usage        ClaudeUsage `json:"usage"`
+	Error        string      `json:"error"`
+	// Set on messages from a sub-agent, whose context is separate
+	ParentToolUseID string `json:"parent_tool_use_id"`
+	Message         struct {
+		Content []streamContent `json:"content"`
+		Usage   *ClaudeUsage    `json:"usage"`
+	} `json:"message"`
+}
+
+// streamContent is a content block inside an assistant or user message
+type streamContent struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// parseStreamOutput builds a response from stream-json output. The final
+// "result" event carries the same fields as --output-format json; tool_use and
+// tool_result blocks are collected into ToolRuns.
+func parseStreamOutput(stdout []byte) (*ClaudeCodeResponse, error) {
+	var response *ClaudeCodeResponse
+	var toolRuns []ToolRun
+	var lastCallUsage *ClaudeUsage
+	toolIndex := make(map[string]int)
+
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), 50*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var event streamEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue
+		}
+
+		switch event.Type {
+		case "assistant":
+			if event.Message.Usage != nil && event.ParentToolUseID == "" {
+				lastCallUsage = event.Message.Usage
+			}
+			for _, content := range event.Message.Content {
+				if content.Type != "tool_use" {
+					continue
+				}
+				toolIndex[content.ID] = len(toolRuns)
+				toolRuns = append(toolRuns, toolRunFromContent(content))
+			}
+		case "user":
+			for _, content := range event.Message.Content {
+				if content.Type != "tool_result" {
+					continue
+				}
+				if i, ok := toolIndex[content.ToolUseID]; ok {
+					toolRuns[i].Output = toolResultText(content.Content)
+					toolRuns[i].IsError = content.IsError
+				}
+			}
+		case "result":
+			response = &ClaudeCodeResponse{
+				Type:           event.Type,
+				Subtype:        event.Subtype,
+				IsError:        event.IsError,
+				Result:         event.Result,
+				SessionID:      event.SessionID,
+				TotalCostUSD:   event.TotalCostUSD,
+				Usage:          event.Usage,
+				Error:          event.Error,
+				LatestResponse: string(line),
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream output: %w", err)
+	}
+
+	if response == nil {
+		return nil, fmt.Errorf("no result event in stream output")
+	}
+	response.ToolRuns = toolRuns
+	response.lastCallUsage = lastCallUsage
+	return response, nil
+}
+
+// toolRunFromContent describes a tool_use block; its result is filled in
+// when the matching tool_result arrives
+func toolRunFromContent(content streamContent) ToolRun {
+	run := ToolRun{ID: content.ID, Name: content.Name}
+	var input struct {
+		Command      string `json:"command"`
+		SubagentType string `json:"subagent_type"`
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+	}
+	if err := json.Unmarshal(content.Input, &input); err == nil {
+		if content.Name == "Bash" {
+			run.Command = input.Command
+		}
+		run.Agent = input.SubagentType
+		run.FilePath = input.FilePath
+		if run.FilePath == "" {
+			run.FilePath = input.NotebookPath
+		}
+	}
+	return run
+}
+
+// AgentsUsed returns the distinct sub-agents that handled part of a run, in
+// the order they were first invoked
+func (r *ClaudeCodeResponse) AgentsUsed() []string {
+	var agents []string
+	seen := make(map[string]bool)
+	for _, run := range r.ToolRuns {
+		if run.Agent == "" || seen[run.Agent] {
+			continue
+		}
+		seen[run.Agent] = true
+		agents = append(agents, run.Agent)
+	}
+	return agents
+}
+
+// EditedFiles returns the distinct files changed by successful file edit
+// tools, in the order they were first edited
+func (r *ClaudeCodeResponse) EditedFiles() []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, run := range r.ToolRuns {
+		if !fileEditTools[run.Name] || run.IsError || run.FilePath == "" || seen[run.FilePath] {
+			continue
+		}
+		seen[run.FilePath] = true
+		files = append(files, run.FilePath)
+	}
+	return files
+}
+
+// toolResultText flattens a tool_result content field, which is either a
+// string or a list of text blocks
+func toolResultText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &blocks); err == nil {
+		var parts []string
+		for _, block := range blocks {
+			if block.Type == "text" {
+				parts = append(parts, block.Text)
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+
+	return string(raw)
+}
@@ -0,0 +1,174 @@
+package claude
+
+import "testing"
+
+func TestParseStreamOutput(t *testing.T) {
+	stdout := `{"type":"system","subtype":"init","session_id":"s1"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Running tests"},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}]},"session_id":"s1"}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"ok  pkg\nFAIL other","is_error":true}]},"session_id":"s1"}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"/tmp/x"}}]},"session_id":"s1"}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t2","content":[{"type":"text","text":"line 1"},{"type":"text","text":"line 2"}]}]},"session_id":"s1"}
+{"type":"result","subtype":"success","is_error":false,"result":"One package failed.","session_id":"s1","total_cost_usd":0.25,"usage":{"input_tokens":10,"output_tokens":20}}
+`
+
+	response, err := parseStreamOutput([]byte(stdout))
+	if err != nil {
+		t.Fatalf("parseStreamOutput() error = %v", err)
+	}
+
+	if response.Result != "One package failed." || response.SessionID != "s1" || response.TotalCostUSD != 0.25 {
+		t.Errorf("unexpected result fields: %+v", response)
+	}
+	if response.Usage.OutputTokens != 20 {
+		t.Errorf("OutputTokens = %d, want 20", response.Usage.OutputTokens)
+	}
+	if len(response.ToolRuns) != 2 {
+		t.Fatalf("got %d tool runs, want 2", len(response.ToolRuns))
+	}
+
+	bash := response.ToolRuns[0]
+	if bash.Name != "Bash" || bash.Command != "go test ./..." || bash.Output != "ok  pkg\nFAIL other" || !bash.IsError {
+		t.Errorf("unexpected Bash run: %+v", bash)
+	}
+	if read := response.ToolRuns[1]; read.Output != "line 1\nline 2" || read.Command != "" {
+		t.Errorf("unexpected Read run: %+v", read)
+	}
+}
+
+func TestParseStreamOutputWithoutResult(t *testing.T) {
+	stdout := `{"type":"system","subtype":"init","session_id":"s1"}`
+	if _, err := parseStreamOutput([]byte(stdout)); err == nil {
+		t.Error("expected error when result event is missing")
+	}
+}
+
+func TestAgentsUsed(t *testing.T) {
+	stdout := `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Task","input":{"subagent_type":"reviewer","prompt":"review"}}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t2","name":"Task","input":{"subagent_type":"tester","prompt":"test"}}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t3","name":"Task","input":{"subagent_type":"reviewer","prompt":"again"}}]}}
+{"type":"result","subtype":"success","result":"done","session_id":"s1"}
+`
+
+	response, err := parseStreamOutput([]byte(stdout))
+	if err != nil {
+		t.Fatalf("parseStreamOutput() error = %v", err)
+	}
+
+	agents := response.AgentsUsed()
+	if len(agents) != 2 || agents[0] != "reviewer" || agents[1] != "tester" {
+		t.Errorf("AgentsUsed() = %v, want [reviewer tester]", agents)
+	}
+}
+
+func TestEditedFiles(t *testing.T) {
+	stdout := `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"/repo/config.yaml","old_string":"a","new_string":"b"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t2","name":"Write","input":{"file_path":"/repo/new.go","content":"package x"}}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t3","name":"Edit","input":{"file_path":"/repo/broken.go"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t3","content":"old_string not found","is_error":true}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t4","name":"Read","input":{"file_path":"/repo/read.go"}}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t5","name":"MultiEdit","input":{"file_path":"/repo/config.yaml"}}]}}
+{"type":"result","subtype":"success","result":"done","session_id":"s1"}
+`
+
+	response, err := parseStreamOutput([]byte(stdout))
+	if err != nil {
+		t.Fatalf("parseStreamOutput() error = %v", err)
+	}
+
+	files := response.EditedFiles()
+	if len(files) != 2 || files[0] != "/repo/config.yaml" || files[1] != "/repo/new.go" {
+		t.Errorf("EditedFiles() = %v, want [/repo/config.yaml /repo/new.go]", files)
+	}
+}
+
+func TestParseStreamOutputContextTokens(t *testing.T) {
+	stdout := `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Task","input":{"subagent_type":"reviewer"}}],"usage":{"input_tokens":5,"cache_read_input_tokens":9000,"output_tokens":100}},"session_id":"s1"}
+{"type":"assistant","parent_tool_use_id":"t1","message":{"content":[{"type":"text","text":"Looks fine"}],"usage":{"input_tokens":50000,"output_tokens":10}},"session_id":"s1"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Done"}],"usage":{"input_tokens":5,"cache_creation_input_tokens":200,"cache_read_input_tokens":9100,"output_tokens":40}},"session_id":"s1"}
+{"type":"result","subtype":"success","result":"Done","session_id":"s1","usage":{"input_tokens":50010,"cache_read_input_tokens":18100,"output_tokens":150}}
+`
+
+	response, err := parseStreamOutput([]byte(stdout))
+	if err != nil {
+		t.Fatalf("parseStreamOutput() error = %v", err)
+	}
+	if got := response.ContextTokens(); got != 9345 {
+		t.Errorf("ContextTokens() = %d, want 9345 from the last top-level call", got)
+	}
+
+	fallback := &ClaudeCodeResponse{Usage: ClaudeUsage{InputTokens: 10, CacheReadInputTokens: 90, OutputTokens: 5}}
+	if got := fallback.ContextTokens(); got != 105 {
+		t.Errorf("ContextTokens() without per-call usage = %d, want 105", got)
+	}
+}
+
+func TestTodoWatcher(t *testing.T) {
+	var updates [][]Todo
+	watcher := &todoWatcher{onTodos: func(todos []Todo) { updates = append(updates, todos) }}
+
+	output := `{"type":"system","subtype":"init","session_id":"s1"}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"TodoWrite","input":{"todos":[{"content":"Read code","status":"in_progress","activeForm":"Reading code"},{"content":"Fix bug","status":"pending","activeForm":"Fixing bug"}]}}]}}
+{"type":"assistant","parent_tool_use_id":"t9","message":{"content":[{"type":"tool_use","id":"t2","name":"TodoWrite","input":{"todos":[{"content":"Sub-agent step","status":"pending"}]}}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t3","name":"TodoWrite","input":{"todos":[{"content":"Read code","status":"completed"},{"content":"Fix bug","status":"in_progress"}]}}]}}
+`
+	// Deliver in uneven chunks, as a pipe would
+	for i := 0; i < len(output); i += 37 {
+		end := i + 37
+		if end > len(output) {
+			end = len(output)
+		}
+		watcher.Write([]byte(output[i:end]))
+	}
+
+	if len(updates) != 2 {
+		t.Fatalf("got %d task list updates, want 2 (sub-agent lists ignored)", len(updates))
+	}
+	if first := updates[0]; len(first) != 2 || first[0].Status != TodoInProgress || first[0].ActiveForm != "Reading code" {
+		t.Errorf("unexpected first update: %+v", first)
+	}
+	if last := updates[1]; last[0].Status != TodoCompleted || last[1].Status != TodoInProgress {
+		t.Errorf("unexpected last update: %+v", last)
+	}
+}
+
+func TestToolWatcher(t *testing.T) {
+	var runs []ToolRun
+	watcher := &toolWatcher{onToolUse: func(run ToolRun) { runs = append(runs, run) }}
+
+	output := `{"type":"system","subtype":"init","session_id":"s1"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Checking"},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}
+{"type":"assistant","parent_tool_use_id":"t9","message":{"content":[{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"/w/sub.go"}}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t3","name":"Edit","input":{"file_path":"/w/main.go"}}]}}
+`
+	for i := 0; i < len(output); i += 29 {
+		end := i + 29
+		if end > len(output) {
+			end = len(output)
+		}
+		watcher.Write([]byte(output[i:end]))
+	}
+
+	if len(runs) != 2 {
+		t.Fatalf("got %d tool calls, want 2 (sub-agent calls ignored): %+v", len(runs), runs)
+	}
+	if runs[0].Name != "Bash" || runs[0].Command != "go test ./..." {
+		t.Errorf("unexpected first tool call: %+v", runs[0])
+	}
+	if runs[1].Name != "Edit" || runs[1].FilePath != "/w/main.go" {
+		t.Errorf("unexpected second tool call: %+v", runs[1])
+	}
+}
+
+func TestToolWatcher_RedactsEnvValues(t *testing.T) {
+	var runs []ToolRun
+	opts := RunOptions{Env: []string{"API_TOKEN=tok-123456"}, OnToolUse: func(run ToolRun) { runs = append(runs, run) }}
+
+	line := `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"curl -H 'Authorization: Bearer tok-123456' https://api.example.com"}}]}}` + "\n"
+	opts.streamWatcher().Write([]byte(line))
+
+	if len(runs) != 1 || runs[0].Command != "curl -H 'Authorization: Bearer [REDACTED]' https://api.example.com" {
+		t.Errorf("Expected the env value to be redacted from the command, got %+v", runs)
+	}
+}
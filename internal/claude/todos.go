@@ -0,0 +1,75 @@
+package claude
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Todo statuses reported by Claude Code's TodoWrite tool
+const (
+	TodoPending    = "pending"
+	TodoInProgress = "in_progress"
+	TodoCompleted  = "completed"
+)
+
+// Todo is one step of the task list Claude keeps with its TodoWrite tool
+type Todo struct {
+	Content    string `json:"content"`
+	Status     string `json:"status"`
+	ActiveForm string `json:"activeForm"` // Present-tense label shown while in progress
+}
+
+// parseTodoWrite returns the task list from a stream-json line if it is a
+// top-level assistant message calling TodoWrite. Each call carries the whole
+// list, so the last one seen is the current state.
+func parseTodoWrite(line []byte) ([]Todo, bool) {
+	var event streamEvent
+	if err := json.Unmarshal(line, &event); err != nil {
+		return nil, false
+	}
+	if event.Type != "assistant" || event.ParentToolUseID != "" {
+		return nil, false
+	}
+
+	var todos []Todo
+	found := false
+	for _, content := range event.Message.Content {
+		if content.Type != "tool_use" || content.Name != "TodoWrite" {
+			continue
+		}
+		var input struct {
+			Todos []Todo `json:"todos"`
+		}
+		if err := json.Unmarshal(content.Input, &input); err != nil {
+			continue
+		}
+		todos, found = input.Todos, true
+	}
+	return todos, found
+}
+
+// todoWatcher is an io.Writer that scans stream-json output as the CLI
+// writes it and reports every TodoWrite task list
+type todoWatcher struct {
+	onTodos func([]Todo)
+	pending []byte
+}
+
+func (w *todoWatcher) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		newline := bytes.IndexByte(w.pending, '\n')
+		if newline < 0 {
+			break
+		}
+		line := w.pending[:newline]
+		// Cheap check before decoding; most lines are not task updates
+		if bytes.Contains(line, []byte(`"TodoWrite"`)) {
+			if todos, ok := parseTodoWrite(line); ok {
+				w.onTodos(todos)
+			}
+		}
+		w.pending = w.pending[newline+1:]
+	}
+	return len(p), nil
+}
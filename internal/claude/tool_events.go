@@ -0,0 +1,75 @@
+package claude
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// parseToolUses returns the tools called in a stream-json line if it is a
+// top-level assistant message. Sub-agents' own tool calls are left out; the
+// Task call that started the sub-agent stands for them.
+func parseToolUses(line []byte) []ToolRun {
+	var event streamEvent
+	if err := json.Unmarshal(line, &event); err != nil {
+		return nil
+	}
+	if event.Type != "assistant" || event.ParentToolUseID != "" {
+		return nil
+	}
+
+	var runs []ToolRun
+	for _, content := range event.Message.Content {
+		if content.Type == "tool_use" {
+			runs = append(runs, toolRunFromContent(content))
+		}
+	}
+	return runs
+}
+
+// toolWatcher is an io.Writer that scans stream-json output as the CLI
+// writes it and reports each tool call as it is made, before its result
+type toolWatcher struct {
+	onToolUse func(ToolRun)
+	redactor  *strings.Replacer // Hides the run's env values in commands
+	pending   []byte
+}
+
+func (w *toolWatcher) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		newline := bytes.IndexByte(w.pending, '\n')
+		if newline < 0 {
+			break
+		}
+		line := w.pending[:newline]
+		// Cheap check before decoding; most lines are not tool calls
+		if bytes.Contains(line, []byte(`"tool_use"`)) {
+			for _, run := range parseToolUses(line) {
+				if w.redactor != nil {
+					run.Command = w.redactor.Replace(run.Command)
+				}
+				w.onToolUse(run)
+			}
+		}
+		w.pending = w.pending[newline+1:]
+	}
+	return len(p), nil
+}
+
+// streamWatcher returns a writer that reports the run's progress callbacks
+// from stream-json output, or nil if the run has none
+func (o RunOptions) streamWatcher() io.Writer {
+	var watchers []io.Writer
+	if o.OnTodos != nil {
+		watchers = append(watchers, &todoWatcher{onTodos: o.OnTodos})
+	}
+	if o.OnToolUse != nil {
+		watchers = append(watchers, &toolWatcher{onToolUse: o.OnToolUse, redactor: envRedactor(o.Env)})
+	}
+	if len(watchers) == 0 {
+		return nil
+	}
+	return io.MultiWriter(watchers...)
+}
@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+)
+
+// Source identifies where a command invocation came from
+type Source int
+
+const (
+	SourceMessage Source = iota
+	SourceSlash
+)
+
+// Availability controls from which sources a command can be invoked
+type Availability int
+
+const (
+	AvailableMessage Availability = 1 << iota
+	AvailableSlash
+
+	AvailableEverywhere = AvailableMessage | AvailableSlash
+)
+
+// Arg describes a positional argument for usage and validation
+type Arg struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Request carries everything a handler needs to run a command
+type Request struct {
+	Command     string
+	UserID      string
+	ChannelID   string
+	Text        string   // Raw argument text after the command name
+	Args        []string // Text split on whitespace
+	Source      Source
+	TriggerID   string // Slash commands only, for opening modals
+	ResponseURL string // Slash commands only, for delayed responses
+}
+
+// Handler executes a command and returns the response text
+type Handler func(ctx context.Context, req *Request) (string, error)
+
+// Command is a registered bot command
+type Command struct {
+	Name         string
+	Description  string
+	Permission   auth.Permission
+	Availability Availability
+	Args         []Arg
+	Variadic     bool                      // Accept more arguments than declared in Args
+	Validate     func(args []string) error // Optional checks beyond the argument schema
+	Details      string                    // Longer explanation shown by "help <command>"
+	Examples     []string                  // Sample invocations shown by "help <command>", without prefix
+	Handler      Handler
+}
+
+// AvailableFrom reports whether the command can be invoked from source
+func (c *Command) AvailableFrom(source Source) bool {
+	switch source {
+	case SourceMessage:
+		return c.Availability&AvailableMessage != 0
+	case SourceSlash:
+		return c.Availability&AvailableSlash != 0
+	}
+	return false
+}
+
+// ValidateArgs checks args against the declared argument schema
+func (c *Command) ValidateArgs(args []string) error {
+	for i, arg := range c.Args {
+		if arg.Required && i >= len(args) {
+			return fmt.Errorf("missing required argument: %s", arg.Name)
+		}
+	}
+	if !c.Variadic && len(args) > len(c.Args) {
+		return fmt.Errorf("too many arguments")
+	}
+	if c.Validate != nil {
+		return c.Validate(args)
+	}
+	return nil
+}
+
+// Usage renders the command signature, e.g. "/session <id>"
+func (c *Command) Usage(source Source) string {
+	var usage strings.Builder
+	if source == SourceSlash {
+		usage.WriteString("/")
+	}
+	usage.WriteString(c.Name)
+	for _, arg := range c.Args {
+		if arg.Required {
+			usage.WriteString(fmt.Sprintf(" <%s>", arg.Name))
+		} else {
+			usage.WriteString(fmt.Sprintf(" [%s]", arg.Name))
+		}
+	}
+	if c.Variadic {
+		usage.WriteString(" ...")
+	}
+	return usage.String()
+}
+
+// Registry holds the set of known commands. It is safe for concurrent use so
+// extensions can register commands after the bot has started.
+type Registry struct {
+	mu       sync.RWMutex
+	commands map[string]*Command
+}
+
+// NewRegistry creates an empty command registry
+func NewRegistry() *Registry {
+	return &Registry{
+		commands: make(map[string]*Command),
+	}
+}
+
+// Register adds a command to the registry
+func (r *Registry) Register(cmd Command) error {
+	name := strings.ToLower(strings.TrimPrefix(cmd.Name, "/"))
+	if name == "" {
+		return fmt.Errorf("command name is required")
+	}
+	if cmd.Handler == nil {
+		return fmt.Errorf("command %s has no handler", name)
+	}
+	if cmd.Availability == 0 {
+		return fmt.Errorf("command %s is not available from any source", name)
+	}
+	cmd.Name = name
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.commands[name]; exists {
+		return fmt.Errorf("command %s is already registered", name)
+	}
+	r.commands[name] = &cmd
+	return nil
+}
+
+// MustRegister is like Register but panics on error; intended for built-ins
+func (r *Registry) MustRegister(cmd Command) {
+	if err := r.Register(cmd); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup finds a command by name, with or without a leading slash
+func (r *Registry) Lookup(name string) (*Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cmd, exists := r.commands[strings.ToLower(strings.TrimPrefix(name, "/"))]
+	return cmd, exists
+}
+
+// Commands returns all registered commands sorted by name
+func (r *Registry) Commands() []*Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*Command, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		result = append(result, cmd)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Available returns the commands invocable from source, sorted by name
+func (r *Registry) Available(source Source) []*Command {
+	var result []*Command
+	for _, cmd := range r.Commands() {
+		if cmd.AvailableFrom(source) {
+			result = append(result, cmd)
+		}
+	}
+	return result
+}
+
+// NewRequest builds a Request from raw argument text
+func NewRequest(command, userID, channelID, text string, source Source) *Request {
+	text = strings.TrimSpace(text)
+	return &Request{
+		Command:   strings.ToLower(strings.TrimPrefix(command, "/")),
+		UserID:    userID,
+		ChannelID: channelID,
+		Text:      text,
+		Args:      strings.Fields(text),
+		Source:    source,
+	}
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ghabxph/claude-on-slack/internal/auth"
+)
+
+func noopHandler(ctx context.Context, req *Request) (string, error) {
+	return "ok", nil
+}
+
+func TestRegistry_RegisterAndLookup(t *testing.T) {
+	registry := NewRegistry()
+
+	err := registry.Register(Command{
+		Name:         "/Session",
+		Description:  "Manage sessions",
+		Permission:   auth.PermissionRead,
+		Availability: AvailableSlash,
+		Handler:      noopHandler,
+	})
+	if err != nil {
+		t.Fatalf("Failed to register command: %v", err)
+	}
+
+	cmd, exists := registry.Lookup("/session")
+	if !exists {
+		t.Fatal("Expected command to be found with leading slash")
+	}
+	if cmd.Name != "session" {
+		t.Errorf("Expected normalized name session, got %s", cmd.Name)
+	}
+	if cmd.AvailableFrom(SourceMessage) {
+		t.Error("Slash-only command should not be available from messages")
+	}
+
+	if err := registry.Register(Command{Name: "session", Availability: AvailableSlash, Handler: noopHandler}); err == nil {
+		t.Error("Expected duplicate registration to fail")
+	}
+	if err := registry.Register(Command{Name: "nohandler", Availability: AvailableSlash}); err == nil {
+		t.Error("Expected registration without handler to fail")
+	}
+}
+
+func TestCommand_ValidateArgsAndUsage(t *testing.T) {
+	cmd := &Command{
+		Name: "delete",
+		Args: []Arg{{Name: "session-id", Required: true}, {Name: "reason"}},
+	}
+
+	if err := cmd.ValidateArgs(nil); err == nil {
+		t.Error("Expected missing required argument error")
+	}
+	if err := cmd.ValidateArgs([]string{"abc"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := cmd.ValidateArgs([]string{"a", "b", "c"}); err == nil {
+		t.Error("Expected too many arguments error")
+	}
+
+	if usage := cmd.Usage(SourceSlash); usage != "/delete <session-id> [reason]" {
+		t.Errorf("Unexpected usage: %s", usage)
+	}
+}
+
+func TestCommand_ValidateHook(t *testing.T) {
+	cmd := &Command{
+		Name: "help",
+		Args: []Arg{{Name: "command"}},
+		Validate: func(args []string) error {
+			if len(args) > 0 && args[0] != "session" {
+				return fmt.Errorf("unknown command: %s", args[0])
+			}
+			return nil
+		},
+	}
+
+	if err := cmd.ValidateArgs([]string{"session"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := cmd.ValidateArgs([]string{"me"}); err == nil {
+		t.Error("Expected validate hook to reject unknown command")
+	}
+	if err := cmd.ValidateArgs([]string{"session", "extra"}); err == nil {
+		t.Error("Expected schema check to run before the hook")
+	}
+}
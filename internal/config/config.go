@@ -1,11 +1,19 @@
 package config
 
 import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/httpauth"
+	"github.com/ghabxph/claude-on-slack/internal/runqueue"
 )
 
 // PermissionMode defines Claude's permission level
@@ -18,6 +26,93 @@ const (
 	PermissionModePlan           PermissionMode = "plan"
 )
 
+// ExternalUserPolicy controls what users from other Slack organizations
+// (Slack Connect shared channels) may do
+type ExternalUserPolicy string
+
+const (
+	ExternalUserDeny     ExternalUserPolicy = "deny"
+	ExternalUserReadOnly ExternalUserPolicy = "read-only"
+	ExternalUserAllow    ExternalUserPolicy = "allow"
+)
+
+// ExecutorMode controls how Claude runs are carried out
+type ExecutorMode string
+
+const (
+	ExecutorCLI     ExecutorMode = "cli"     // Run the Claude Code CLI
+	ExecutorFixture ExecutorMode = "fixture" // Serve canned responses from EXECUTOR_FIXTURES_DIR
+)
+
+// IntentRouterMode controls how short messages are classified before
+// deciding whether they need a full Claude run
+type IntentRouterMode string
+
+const (
+	IntentRouterOff       IntentRouterMode = "off"
+	IntentRouterHeuristic IntentRouterMode = "heuristic"
+	IntentRouterModel     IntentRouterMode = "model"
+)
+
+// Environment snapshot fields a response footer can show
+const (
+	FooterEnvGit  = "git"  // Branch and commit of the working directory
+	FooterEnvKube = "kube" // Current kubectl context
+	FooterEnvVenv = "venv" // Active Python virtualenv
+)
+
+// FooterEnvFields lists every environment snapshot field, in footer order
+var FooterEnvFields = []string{FooterEnvGit, FooterEnvKube, FooterEnvVenv}
+
+// ParseFooterEnvFields parses a comma-separated list of environment
+// snapshot fields
+func ParseFooterEnvFields(value string) ([]string, error) {
+	fields := []string{}
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.ToLower(strings.TrimSpace(field)); field == "" {
+			continue
+		}
+		known := false
+		for _, name := range FooterEnvFields {
+			known = known || field == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown field %q (use %s)", field, strings.Join(FooterEnvFields, ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// StartupMode controls what happens when the database or Claude Code CLI
+// isn't available at startup
+type StartupMode string
+
+const (
+	StartupStrict     StartupMode = "strict"     // Fail startup
+	StartupSupervised StartupMode = "supervised" // Start degraded and retry until ready
+)
+
+// ArchivedChannelSessions controls what happens to a channel's sessions when
+// the channel is archived or deleted
+type ArchivedChannelSessions string
+
+const (
+	ArchivedChannelKeep  ArchivedChannelSessions = "keep"  // Leave sessions attached; they resume on unarchive
+	ArchivedChannelClose ArchivedChannelSessions = "close" // Detach the channel's active sessions
+	ArchivedChannelTrash ArchivedChannelSessions = "trash" // Move the channel's sessions to the trash
+)
+
+// ResponseHook is an entry in RESPONSE_HOOKS: a hook name and what to do
+// with the response when it fails ("skip" or "block")
+type ResponseHook struct {
+	Name      string
+	OnFailure string
+}
+
+// MaxUsagePolicyLength is the most text a Slack section block displays
+const MaxUsagePolicyLength = 3000
+
 // DatabaseConfig holds database connection settings
 type DatabaseConfig struct {
 	URL              string
@@ -38,12 +133,38 @@ type Config struct {
 	SlackAppToken      string
 	SlackSigningSecret string
 
+	// Slack credential rotation and reload
+	SlackSecretsFile      string // KEY=VALUE file re-read when it changes or on SIGHUP
+	SlackClientID         string
+	SlackClientSecret     string
+	SlackRefreshToken     string // Enables bot token rotation when set with the client credentials
+	SecretsReloadInterval time.Duration
+
 	// Claude Code configuration
 	ClaudeCodePath   string
 	ClaudeTimeout    time.Duration
+	ThinkingTimeout  time.Duration // Thinking messages older than this are marked interrupted
+	InstanceName     string        // Identifies this instance across restarts; defaults to the hostname
+	ClaudeModel      string
+	ClaudeModels     []string // Models offered by the "New Claude request" modal
+	ClaudeAgentsFile string // JSON sub-agent definitions passed with --agents
+	ExecutorMode        ExecutorMode
+	ExecutorFixturesDir string // Canned responses keyed by prompt hash, for ExecutorFixture
 	AllowedTools     []string
 	DisallowedTools  []string
 
+	// Pre-routing of trivial messages so they don't start a full Claude run
+	IntentRouter      IntentRouterMode
+	IntentRouterModel string // Cheap model asked to classify messages in "model" mode
+
+	// Base64 32-byte key encrypting per-channel environment variables (/env); unset disables /env
+	EnvEncryptionKey string
+
+	// Budget configuration (zero disables a budget)
+	SessionBudgetUSD      float64
+	ChannelDailyBudgetUSD float64
+	BudgetDowngradePolicy string
+
 	// Bot configuration
 	BotName         string
 	BotDisplayName  string
@@ -51,15 +172,119 @@ type Config struct {
 	AllowedChannels []string
 	AllowedUsers    []string
 
+	// Permission modes specific channels start with, from "C123:acceptEdits,C456:plan".
+	// Deprecated: imported into channel settings once; use /settings.
+	ChannelPermissionDefaults map[string]PermissionMode
+
+	// Permission modes new channels of a type start with, from "private:plan,dm:acceptEdits".
+	// Deprecated: imported into channel type settings once; use /settings type.
+	ChannelTypePermissionDefaults map[string]PermissionMode
+
+	// Only respond to channel messages that mention the bot; DMs never need a mention
+	RequireMention bool
+
 	// Session configuration
 	SessionTimeout    time.Duration
 	MaxSessionsPerUser int
 	SessionCleanupInterval time.Duration
+	SessionTrashRetention  time.Duration // Deleted sessions are purged from the trash after this long
+	SessionPruneAfter      time.Duration // Exchanges older than this are collapsed into checkpoints; 0 disables
+	SessionPruneChunk      int           // Exchanges collapsed into each checkpoint
+	SessionPruneKeep       int           // Latest exchanges of a session that are never collapsed
+	ArchivedChannelSessions ArchivedChannelSessions
+	SessionListingCacheTTL time.Duration // Session and path listings are reused this long; 0 disables
+
+	// Channel context configuration
+	ChannelContextEnabled  bool
+	ChannelContextCacheTTL time.Duration
+
+	// Feature flags (/flags) are reloaded from the database at most this often
+	FeatureFlagCacheTTL time.Duration
+
+	// DM users who opted in when a run takes longer than this
+	NotifyAfter time.Duration
+
+	// Include earlier thread replies when a message shortcut targets a thread reply
+	ShortcutThreadContext bool
+
+	// Also inline the thread around messages linked by permalink in a prompt
+	PermalinkThreadContext bool
+
+	// Post Claude's task list as a checklist that is updated during the run
+	TaskTracker bool
+
+	// Post each command run and file edited to the thread during a run, in
+	// channels that don't set tool_events
+	ToolEvents bool
+
+	// Before runs that edit without asking, warn about files an earlier run
+	// left uncommitted and offer to continue, commit, or discard them
+	DirtyWorkspaceCheck bool
+
+	// Uploaded .diff and .patch files are test-applied in a scratch worktree
+	// and reviewed by Claude, with a button to apply them to the workspace
+	PatchReview bool
+
+	// A prompt identical to one the same user started in the channel within
+	// this window, while it is still in progress, asks before running again
+	// (0 turns the check off)
+	DuplicateRunWindow time.Duration
+
+	// Summarize a conversation when its session is closed or deleted, store
+	// the summary on the session, and post it as a closing recap
+	SummarizeOnClose bool
+
+	// Name each new session after its first exchange with a cheap model, and
+	// show the title in session lists and footers
+	SessionTitles     bool
+	SessionTitleModel string
+
+	// Keep cached sessions coherent across bot instances with Postgres LISTEN/NOTIFY
+	SessionCacheInvalidation bool
+
+	// Model context window size, and how full it gets before replies warn (0 = never)
+	ContextWindowTokens int
+	ContextWarnPercent  int
+
+	// Largest prompt sent to Claude, in estimated tokens; queued messages are
+	// trimmed to fit and longer messages are refused (0 = no cap)
+	PromptMaxTokens int
+
+	// Outgoing Slack message pacing per channel and retries on rate limits or server errors
+	SlackChannelPacing time.Duration
+	SlackMaxRetries    int
+
+	// Outbox: replies Slack couldn't take after the retries above are stored
+	// and redelivered with backoff for up to OutboxMaxAge (0 = off)
+	OutboxMaxAge     time.Duration
+	OutboxBackoff    time.Duration
+	OutboxMaxBackoff time.Duration
+
+	// Presence heartbeat (0 = off) and whether it posts the bot's load as its status
+	PresenceHeartbeatInterval time.Duration
+	PresenceStatus            bool
+	// User token the status is posted with; users.profile.set refuses bot tokens
+	SlackUserToken string
+
+	// How long Slack user profiles (name, email, timezone) are cached
+	UserProfileCacheTTL time.Duration
 
 	// Security configuration
 	AdminUsers         []string
 	RateLimitPerMinute int
+	RateLimitBurst     int // Requests a user can make at once before RATE_LIMIT_PER_MINUTE applies
 	MaxMessageLength   int
+	ExternalUserPolicy ExternalUserPolicy
+
+	// Usage policy users must agree to before their first Claude run; empty
+	// disables the acknowledgment. The version changes with the text unless
+	// set, so an edited policy is shown again.
+	UsagePolicy        string
+	UsagePolicyVersion string
+
+	// Request limit shared by everyone in a channel; 0 disables it
+	ChannelRateLimitPerMinute int
+	ChannelRateLimitBurst     int
 
 	// Logging configuration
 	LogLevel    string
@@ -70,18 +295,130 @@ type Config struct {
 	ServerPort int
 	ServerHost string
 	HealthCheckPath string
+	AdminAPIToken   string // Bearer token for the /admin/ API used by claudectl; empty disables it
+	AdminListenAddr string // Separate host:port for the admin API; empty serves it on the main listener
+	StatusAPIToken  string // Bearer token for the health, metrics, and version endpoints; empty leaves them open
+	StrictSecurity  bool   // Verify every Slack request and keep status and admin endpoints off the open listener
+	StatusAuth          []string         // Ways status requests authenticate: token, jwt, header; empty means token
+	AdminAuth           []string         // Ways admin API requests authenticate; empty means token
+	StatusAllowedIPs    []netip.Prefix   // Addresses the status endpoints answer; empty allows any
+	AdminAllowedIPs     []netip.Prefix   // Addresses the admin API answers; empty allows any
+	HTTPTrustedProxies  []netip.Prefix   // Proxies whose X-Forwarded-For and identity header are believed
+	HTTPAuthHeader      string           // Identity header a trusted proxy sets, e.g. X-Forwarded-Email
+	HTTPAuthJWTSecret   string           // Shared secret for HS256 tokens
+	HTTPAuthJWTKey      crypto.PublicKey // RSA or P-256 key for RS256 and ES256 tokens, from HTTP_AUTH_JWT_KEY_FILE
+	HTTPAuthJWTIssuer   string           // Required iss claim; empty accepts any
+	HTTPAuthJWTAudience string           // Required aud entry; empty accepts any
+	HTTPAuthJWTHeader   string           // Header holding the bare token; empty reads the bearer token
+	StartupMode       StartupMode
+	StartupMaxBackoff time.Duration // Longest wait between dependency retries in supervised mode
 
 	// Working directory for Claude Code
 	WorkingDirectory string
+	WorkdirRoots     []string // Directories browsable from the /session new picker
+	WorkdirTemplate  string   // Per-user/channel workspace path, e.g. /home/{user}/claude/{channel}
+	WorkdirRepo      string   // Git URL cloned into newly created templated workspaces
+	SessionWorktrees   bool   // Give each new session in a git repository its own worktree and branch
+	SessionWorktreeDir string // Where session worktrees go; default "<repository>-worktrees"
+	WorkspaceAuditRoots    []string      // Directories whose subdirectories are bot-managed workspaces, audited for orphans
+	WorkspaceAuditInterval time.Duration // How often to look for orphaned workspaces; 0 only audits on demand
+	WorkspaceCloneRoot     string        // Where `/session new <git URL>` clones repositories; empty disables it
+	WorkspaceCloneHosts    []string      // Hosts repositories may be cloned from
+	WorkspaceCloneSSHKey   string        // Private key for cloning over SSH; empty uses the host's SSH setup
+	WorkspaceCloneToken    string        // Access token sent to clone hosts over HTTPS
+	WorkspaceCloneTimeout  time.Duration // Longest a clone may take
 	AllowedCommands  []string
 	BlockedCommands  []string
 	CommandTimeout   time.Duration
 	MaxOutputLength  int
 
+	// Attach full shell output when a run was mostly shell commands
+	CommandLogAttachments bool
+	CommandLogMinBytes    int
+
+	// Attach a unified diff of files edited during a run (git work trees only)
+	EditDiffAttachments bool
+
+	// Upload files Claude derives from attached images, like crops or
+	// annotated copies, back to the thread
+	ImageOutputUploads bool
+
+	// /batch fan-out limits
+	BatchConcurrency int
+	BatchMaxPaths    int
+
+	// Claude runs allowed at once across all channels (0 = unlimited); extra
+	// runs wait, urgent ones first
+	MaxConcurrentRuns int
+
+	// Slow mode: after an API rate limit or overload, new runs wait this long
+	// before one probes the API, doubling per failed probe up to the max
+	// (0 = off)
+	SlowModeBackoff    time.Duration
+	SlowModeMaxBackoff time.Duration
+
+	// Run priority specific channels start with, from "C123:urgent,C456:batch".
+	// Deprecated: imported into channel settings once; use /priority or /settings.
+	ChannelPriorities map[string]string
+
+	// Free disk space required in the working directory before a run (0 = don't check)
+	PreflightMinFreeMB int64
+
+	// Downloaded file limits (0 = unlimited) and retention
+	FileMaxSizeMB       int64
+	FileUserQuotaMB     int64
+	FileGlobalQuotaMB   int64
+	FileRetention       time.Duration
+	FileCleanupInterval time.Duration
+
+	// Audio transcription for attached recordings; a command takes precedence over a URL
+	TranscribeCommand string // Prints the transcript of {file} to stdout
+	TranscribeURL     string // Multipart upload endpoint, e.g. OpenAI-compatible /v1/audio/transcriptions
+	TranscribeAPIKey  string
+	TranscribeModel   string
+	TranscribeTimeout time.Duration
+
 	// Database configuration
 	Database                DatabaseConfig
 	EnableDatabasePersistence bool
 	NotificationChannels    []string
+	ErrorReportChannel      string // Where Report on an error card sends the debug bundle; "" = first notification channel
+	DeployNotifyDelay       time.Duration // How long an instance must stay up before announcing its version (0 = right away)
+
+	// Notification sinks and the minimum severity each receives, from "slack:info,pagerduty:critical"
+	NotificationSinks         map[string]string
+	NotifyWebhookURL          string
+	NotifySMTPAddr            string // host:port
+	NotifySMTPUsername        string
+	NotifySMTPPassword        string
+	NotifyEmailFrom           string
+	NotifyEmailTo             []string
+	NotifyPagerDutyRoutingKey string
+
+	// Alert when a channel has this many failed runs within the window (0 = off)
+	FailureAlertThreshold int
+	FailureAlertWindow    time.Duration
+
+	// Escalate runs where Claude says it's blocked or needs credentials or a
+	// decision: mention these users or user groups, and post to this channel
+	EscalationMentions []string
+	EscalationChannel  string
+	EscalationCooldown time.Duration // Per session, so one stuck conversation escalates once
+
+	// Post a one-line receipt for every run to this channel, so security and
+	// leads can follow activity without joining every project channel
+	ReceiptsChannel string
+
+	// Hooks run in order on every response before it is posted, from
+	// "redact,footer,webhook:block"
+	ResponseHooks             []ResponseHook
+	ResponseHookTimeout       time.Duration // Per hook
+	ResponseRedactPattern     string        // Go regexp; join several with |
+	ResponseRedactReplacement string
+	ResponseFooter            string
+	ResponseWebhookURL        string // Answers {"text": "..."} to replace the response
+	ResponseForwardURL        string // Receives a copy of each response
+	FooterEnv                 []string // Environment snapshot fields response footers show unless a channel picks its own
 	AppVersion              string
 }
 
@@ -91,6 +428,11 @@ func Load() (*Config, error) {
 		// Default values
 		ClaudeCodePath:         "claude",
 		ClaudeTimeout:          time.Minute * 5,
+		ClaudeModel:            "sonnet",
+		ExecutorMode:           ExecutorCLI,
+		ClaudeModels:           []string{"sonnet", "opus", "haiku"},
+		IntentRouter:           IntentRouterOff,
+		IntentRouterModel:      "haiku",
 		AllowedTools:           []string{}, // Empty = all tools allowed for full access
 		DisallowedTools:        []string{},
 		BotName:                "claude-bot",
@@ -99,16 +441,74 @@ func Load() (*Config, error) {
 		SessionTimeout:         time.Hour * 2,
 		MaxSessionsPerUser:     3,
 		SessionCleanupInterval: time.Minute * 15,
+		SessionTrashRetention:  time.Hour * 24 * 30,
+		SessionPruneChunk:      20,
+		SessionPruneKeep:       10,
+		WorkspaceAuditInterval: time.Hour * 24,
+		WorkspaceCloneHosts:    []string{"github.com", "gitlab.com", "bitbucket.org"},
+		WorkspaceCloneTimeout:  time.Minute * 10,
+		ArchivedChannelSessions: ArchivedChannelKeep,
+		SessionListingCacheTTL: time.Second * 15,
+		SecretsReloadInterval:  time.Minute,
+		ChannelContextCacheTTL: time.Minute * 30,
+		FeatureFlagCacheTTL:    time.Second * 30,
+		ShortcutThreadContext:  true,
+		NotifyAfter:            time.Minute,
+		PresenceHeartbeatInterval: time.Minute * 5,
+		SlackChannelPacing:     time.Second,
+		OutboxMaxAge:           24 * time.Hour,
+		OutboxBackoff:          30 * time.Second,
+		OutboxMaxBackoff:       15 * time.Minute,
+		ContextWindowTokens:    200000,
+		TaskTracker:            true,
+		DirtyWorkspaceCheck:    true,
+		PatchReview:            true,
+		DuplicateRunWindow:     2 * time.Minute,
+		DeployNotifyDelay:      2 * time.Minute,
+		SlowModeBackoff:        30 * time.Second,
+		SlowModeMaxBackoff:     10 * time.Minute,
+		SessionCacheInvalidation: true,
+		SessionTitles:            true,
+		SessionTitleModel:        "haiku",
+		NotificationSinks:      map[string]string{"slack": "info"},
+		FailureAlertThreshold:  3,
+		FailureAlertWindow:     time.Minute * 15,
+		EscalationCooldown:     time.Minute * 30,
+		ResponseHookTimeout:    time.Second * 5,
+		ResponseRedactReplacement: "[redacted]",
+		ContextWarnPercent:     80,
+		PromptMaxTokens:        100000,
+		SlackMaxRetries:        3,
+		PresenceStatus:         true,
+		UserProfileCacheTTL:    time.Hour * 24,
 		RateLimitPerMinute:     20,
+		RateLimitBurst:         5,
+		ChannelRateLimitBurst:  10,
 		MaxMessageLength:       4000,
 		LogLevel:               "info",
 		LogFormat:              "json",
 		ServerPort:             8080,
 		ServerHost:             "0.0.0.0",
 		HealthCheckPath:        "/health",
+		StartupMode:            StartupStrict,
+		StartupMaxBackoff:      time.Minute,
 		WorkingDirectory:       "", // Default to current directory - set in .env
 		CommandTimeout:         time.Minute * 5,
 		MaxOutputLength:        10000,
+		CommandLogAttachments:  true,
+		CommandLogMinBytes:     4000,
+		EditDiffAttachments:    true,
+		ImageOutputUploads:     true,
+		ExternalUserPolicy:     ExternalUserDeny,
+		BatchConcurrency:       3,
+		BatchMaxPaths:          20,
+		PreflightMinFreeMB:     500,
+		FileMaxSizeMB:          50,
+		FileUserQuotaMB:        200,
+		FileGlobalQuotaMB:      2048,
+		FileRetention:          time.Hour * 2,
+		FileCleanupInterval:    time.Minute * 30,
+		TranscribeTimeout:      time.Minute * 10,
 		// Database defaults
 		Database: DatabaseConfig{
 			Host:            "localhost",
@@ -123,22 +523,38 @@ func Load() (*Config, error) {
 		AppVersion:               "2.0.0",
 	}
 
-	// Load required environment variables
+	// Every problem is collected so a bad deployment is reported in one go
 	var err error
-	
-	cfg.SlackBotToken = getEnvRequired("SLACK_BOT_TOKEN")
-	if cfg.SlackBotToken == "" {
-		return nil, fmt.Errorf("SLACK_BOT_TOKEN is required")
-	}
+	problems := &ValidationError{}
 
-	cfg.SlackAppToken = getEnvRequired("SLACK_APP_TOKEN")
-	if cfg.SlackAppToken == "" {
-		return nil, fmt.Errorf("SLACK_APP_TOKEN is required")
+	// Credentials in the secrets file take precedence over the environment
+	if val := os.Getenv("SLACK_SECRETS_FILE"); val != "" {
+		cfg.SlackSecretsFile = val
+		secrets, err := ReadSecretsFile(val)
+		if err != nil {
+			problems.Add("SLACK_SECRETS_FILE", "%v", err)
+		}
+		for _, key := range SecretKeys {
+			if value := secrets[key]; value != "" {
+				os.Setenv(key, value)
+			}
+		}
 	}
 
-	cfg.SlackSigningSecret = getEnvRequired("SLACK_SIGNING_SECRET")
-	if cfg.SlackSigningSecret == "" {
-		return nil, fmt.Errorf("SLACK_SIGNING_SECRET is required")
+	// Required Slack credentials are checked by validate
+	cfg.SlackBotToken = os.Getenv("SLACK_BOT_TOKEN")
+	cfg.SlackAppToken = os.Getenv("SLACK_APP_TOKEN")
+	cfg.SlackSigningSecret = os.Getenv("SLACK_SIGNING_SECRET")
+
+	cfg.SlackClientID = os.Getenv("SLACK_CLIENT_ID")
+	cfg.SlackClientSecret = os.Getenv("SLACK_CLIENT_SECRET")
+	cfg.SlackRefreshToken = os.Getenv("SLACK_REFRESH_TOKEN")
+
+	if val := os.Getenv("SECRETS_RELOAD_INTERVAL"); val != "" {
+		cfg.SecretsReloadInterval, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("SECRETS_RELOAD_INTERVAL", "invalid value %q: %v", val, err)
+		}
 	}
 
 	// Load optional Claude Code configuration
@@ -146,6 +562,24 @@ func Load() (*Config, error) {
 		cfg.ClaudeCodePath = val
 	}
 
+	if val := os.Getenv("CLAUDE_AGENTS_FILE"); val != "" {
+		cfg.ClaudeAgentsFile = val
+	}
+
+	if val := os.Getenv("EXECUTOR_MODE"); val != "" {
+		switch mode := ExecutorMode(val); mode {
+		case ExecutorCLI, ExecutorFixture:
+			cfg.ExecutorMode = mode
+		default:
+			problems.Add("EXECUTOR_MODE", "unknown mode %q (use cli or fixture)", val)
+		}
+	}
+	cfg.ExecutorFixturesDir = strings.TrimSpace(os.Getenv("EXECUTOR_FIXTURES_DIR"))
+
+	if val := os.Getenv("ENV_ENCRYPTION_KEY"); val != "" {
+		cfg.EnvEncryptionKey = val
+	}
+
 	if val := os.Getenv("ALLOWED_TOOLS"); val != "" {
 		cfg.AllowedTools = strings.Split(val, ",")
 	}
@@ -157,10 +591,71 @@ func Load() (*Config, error) {
 	if val := os.Getenv("CLAUDE_TIMEOUT"); val != "" {
 		cfg.ClaudeTimeout, err = time.ParseDuration(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid CLAUDE_TIMEOUT: %v", err)
+			problems.Add("CLAUDE_TIMEOUT", "invalid value %q: %v", val, err)
+		}
+	}
+
+	// A run can't outlive CLAUDE_TIMEOUT, so by default a thinking message is
+	// stale shortly after it
+	cfg.ThinkingTimeout = cfg.ClaudeTimeout + time.Minute*5
+	if val := os.Getenv("THINKING_TIMEOUT"); val != "" {
+		cfg.ThinkingTimeout, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("THINKING_TIMEOUT", "invalid value %q: %v", val, err)
 		}
 	}
 
+	// A restarted instance finds its own leftover thinking messages by name,
+	// so the name has to survive restarts
+	cfg.InstanceName = strings.TrimSpace(os.Getenv("INSTANCE_NAME"))
+	if cfg.InstanceName == "" {
+		cfg.InstanceName, _ = os.Hostname()
+	}
+
+	if val := os.Getenv("CLAUDE_MODEL"); val != "" {
+		cfg.ClaudeModel = val
+	}
+
+	if val := os.Getenv("INTENT_ROUTER"); val != "" {
+		switch mode := IntentRouterMode(val); mode {
+		case IntentRouterOff, IntentRouterHeuristic, IntentRouterModel:
+			cfg.IntentRouter = mode
+		default:
+			problems.Add("INTENT_ROUTER", "unknown mode %q (use off, heuristic, or model)", val)
+		}
+	}
+
+	if val := os.Getenv("INTENT_ROUTER_MODEL"); val != "" {
+		cfg.IntentRouterModel = val
+	}
+
+	if val := os.Getenv("CLAUDE_MODELS"); val != "" {
+		cfg.ClaudeModels = nil
+		for _, model := range strings.Split(val, ",") {
+			if model = strings.TrimSpace(model); model != "" {
+				cfg.ClaudeModels = append(cfg.ClaudeModels, model)
+			}
+		}
+	}
+
+	if val := os.Getenv("SESSION_BUDGET_USD"); val != "" {
+		cfg.SessionBudgetUSD, err = strconv.ParseFloat(val, 64)
+		if err != nil {
+			problems.Add("SESSION_BUDGET_USD", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("CHANNEL_DAILY_BUDGET_USD"); val != "" {
+		cfg.ChannelDailyBudgetUSD, err = strconv.ParseFloat(val, 64)
+		if err != nil {
+			problems.Add("CHANNEL_DAILY_BUDGET_USD", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("BUDGET_DOWNGRADE_POLICY"); val != "" {
+		cfg.BudgetDowngradePolicy = val
+	}
+
 	if val := os.Getenv("BOT_NAME"); val != "" {
 		cfg.BotName = val
 	}
@@ -177,6 +672,53 @@ func Load() (*Config, error) {
 		cfg.AllowedChannels = strings.Split(val, ",")
 	}
 
+	if val := os.Getenv("CHANNEL_PERMISSION_DEFAULTS"); val != "" {
+		cfg.ChannelPermissionDefaults = make(map[string]PermissionMode)
+		for _, entry := range strings.Split(val, ",") {
+			if entry = strings.TrimSpace(entry); entry == "" {
+				continue
+			}
+			channelID, mode, found := strings.Cut(entry, ":")
+			channelID = strings.TrimSpace(channelID)
+			if !found || channelID == "" {
+				problems.Add("CHANNEL_PERMISSION_DEFAULTS", "invalid entry %q: expected channel:mode", entry)
+				continue
+			}
+			if !PermissionMode(strings.TrimSpace(mode)).IsValid() {
+				problems.Add("CHANNEL_PERMISSION_DEFAULTS", "unknown permission mode %q for %s", mode, channelID)
+				continue
+			}
+			cfg.ChannelPermissionDefaults[channelID] = PermissionMode(strings.TrimSpace(mode))
+		}
+	}
+
+	if val := os.Getenv("CHANNEL_TYPE_PERMISSION_DEFAULTS"); val != "" {
+		cfg.ChannelTypePermissionDefaults = make(map[string]PermissionMode)
+		for _, entry := range strings.Split(val, ",") {
+			if entry = strings.TrimSpace(entry); entry == "" {
+				continue
+			}
+			channelType, mode, found := strings.Cut(entry, ":")
+			channelType = strings.TrimSpace(channelType)
+			if !found || !isChannelType(channelType) {
+				problems.Add("CHANNEL_TYPE_PERMISSION_DEFAULTS", "invalid entry %q: expected type:mode with type one of %s", entry, strings.Join(ChannelTypes, ", "))
+				continue
+			}
+			if !PermissionMode(strings.TrimSpace(mode)).IsValid() {
+				problems.Add("CHANNEL_TYPE_PERMISSION_DEFAULTS", "unknown permission mode %q for %s", mode, channelType)
+				continue
+			}
+			cfg.ChannelTypePermissionDefaults[channelType] = PermissionMode(strings.TrimSpace(mode))
+		}
+	}
+
+	if val := os.Getenv("REQUIRE_MENTION"); val != "" {
+		cfg.RequireMention, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("REQUIRE_MENTION", "invalid value %q: %v", val, err)
+		}
+	}
+
 	if val := os.Getenv("ALLOWED_USERS"); val != "" {
 		cfg.AllowedUsers = strings.Split(val, ",")
 	}
@@ -184,40 +726,165 @@ func Load() (*Config, error) {
 	if val := os.Getenv("SESSION_TIMEOUT"); val != "" {
 		cfg.SessionTimeout, err = time.ParseDuration(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid SESSION_TIMEOUT: %v", err)
+			problems.Add("SESSION_TIMEOUT", "invalid value %q: %v", val, err)
 		}
 	}
 
 	if val := os.Getenv("MAX_SESSIONS_PER_USER"); val != "" {
 		cfg.MaxSessionsPerUser, err = strconv.Atoi(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid MAX_SESSIONS_PER_USER: %v", err)
+			problems.Add("MAX_SESSIONS_PER_USER", "invalid value %q: %v", val, err)
 		}
 	}
 
 	if val := os.Getenv("SESSION_CLEANUP_INTERVAL"); val != "" {
 		cfg.SessionCleanupInterval, err = time.ParseDuration(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid SESSION_CLEANUP_INTERVAL: %v", err)
+			problems.Add("SESSION_CLEANUP_INTERVAL", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SESSION_TRASH_RETENTION"); val != "" {
+		cfg.SessionTrashRetention, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("SESSION_TRASH_RETENTION", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SESSION_PRUNE_AFTER"); val != "" {
+		cfg.SessionPruneAfter, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("SESSION_PRUNE_AFTER", "invalid value %q: %v", val, err)
 		}
 	}
 
+	if val := os.Getenv("SESSION_PRUNE_CHUNK"); val != "" {
+		cfg.SessionPruneChunk, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("SESSION_PRUNE_CHUNK", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SESSION_PRUNE_KEEP"); val != "" {
+		cfg.SessionPruneKeep, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("SESSION_PRUNE_KEEP", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("ARCHIVED_CHANNEL_SESSIONS"); val != "" {
+		switch policy := ArchivedChannelSessions(val); policy {
+		case ArchivedChannelKeep, ArchivedChannelClose, ArchivedChannelTrash:
+			cfg.ArchivedChannelSessions = policy
+		default:
+			problems.Add("ARCHIVED_CHANNEL_SESSIONS", "unknown policy %q (use keep, close, or trash)", val)
+		}
+	}
+
+	if val := os.Getenv("CHANNEL_CONTEXT_ENABLED"); val != "" {
+		cfg.ChannelContextEnabled, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("CHANNEL_CONTEXT_ENABLED", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SESSION_LISTING_CACHE_TTL"); val != "" {
+		cfg.SessionListingCacheTTL, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("SESSION_LISTING_CACHE_TTL", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("CHANNEL_CONTEXT_CACHE_TTL"); val != "" {
+		cfg.ChannelContextCacheTTL, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("CHANNEL_CONTEXT_CACHE_TTL", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("FEATURE_FLAG_CACHE_TTL"); val != "" {
+		cfg.FeatureFlagCacheTTL, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("FEATURE_FLAG_CACHE_TTL", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SHORTCUT_THREAD_CONTEXT"); val != "" {
+		cfg.ShortcutThreadContext, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("SHORTCUT_THREAD_CONTEXT", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("PERMALINK_THREAD_CONTEXT"); val != "" {
+		cfg.PermalinkThreadContext, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("PERMALINK_THREAD_CONTEXT", "invalid value %q: %v", val, err)
+		}
+	}
 
 	if val := os.Getenv("ADMIN_USERS"); val != "" {
 		cfg.AdminUsers = strings.Split(val, ",")
 	}
 
+	if val := os.Getenv("EXTERNAL_USER_POLICY"); val != "" {
+		switch policy := ExternalUserPolicy(val); policy {
+		case ExternalUserDeny, ExternalUserReadOnly, ExternalUserAllow:
+			cfg.ExternalUserPolicy = policy
+		default:
+			problems.Add("EXTERNAL_USER_POLICY", "unknown policy %q (use deny, read-only, or allow)", val)
+		}
+	}
+
+	if val := os.Getenv("USAGE_POLICY_FILE"); val != "" {
+		policy, err := os.ReadFile(val)
+		if err != nil {
+			problems.Add("USAGE_POLICY_FILE", "%v", err)
+		} else if cfg.UsagePolicy = strings.TrimSpace(string(policy)); cfg.UsagePolicy == "" {
+			problems.Add("USAGE_POLICY_FILE", "%s is empty", val)
+		} else if len(cfg.UsagePolicy) > MaxUsagePolicyLength {
+			problems.Add("USAGE_POLICY_FILE", "%s is %d characters; Slack shows at most %d", val, len(cfg.UsagePolicy), MaxUsagePolicyLength)
+		}
+		sum := sha256.Sum256([]byte(cfg.UsagePolicy))
+		cfg.UsagePolicyVersion = hex.EncodeToString(sum[:])[:12]
+	}
+
+	if val := os.Getenv("USAGE_POLICY_VERSION"); val != "" {
+		cfg.UsagePolicyVersion = val
+	}
+
 	if val := os.Getenv("RATE_LIMIT_PER_MINUTE"); val != "" {
 		cfg.RateLimitPerMinute, err = strconv.Atoi(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid RATE_LIMIT_PER_MINUTE: %v", err)
+			problems.Add("RATE_LIMIT_PER_MINUTE", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("RATE_LIMIT_BURST"); val != "" {
+		cfg.RateLimitBurst, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("RATE_LIMIT_BURST", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("CHANNEL_RATE_LIMIT_PER_MINUTE"); val != "" {
+		cfg.ChannelRateLimitPerMinute, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("CHANNEL_RATE_LIMIT_PER_MINUTE", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("CHANNEL_RATE_LIMIT_BURST"); val != "" {
+		cfg.ChannelRateLimitBurst, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("CHANNEL_RATE_LIMIT_BURST", "invalid value %q: %v", val, err)
 		}
 	}
 
 	if val := os.Getenv("MAX_MESSAGE_LENGTH"); val != "" {
 		cfg.MaxMessageLength, err = strconv.Atoi(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid MAX_MESSAGE_LENGTH: %v", err)
+			problems.Add("MAX_MESSAGE_LENGTH", "invalid value %q: %v", val, err)
 		}
 	}
 
@@ -232,14 +899,14 @@ func Load() (*Config, error) {
 	if val := os.Getenv("ENABLE_DEBUG"); val != "" {
 		cfg.EnableDebug, err = strconv.ParseBool(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid ENABLE_DEBUG: %v", err)
+			problems.Add("ENABLE_DEBUG", "invalid value %q: %v", val, err)
 		}
 	}
 
 	if val := os.Getenv("SERVER_PORT"); val != "" {
 		cfg.ServerPort, err = strconv.Atoi(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid SERVER_PORT: %v", err)
+			problems.Add("SERVER_PORT", "invalid value %q: %v", val, err)
 		}
 	}
 
@@ -251,10 +918,420 @@ func Load() (*Config, error) {
 		cfg.HealthCheckPath = val
 	}
 
+	cfg.AdminAPIToken = os.Getenv("ADMIN_API_TOKEN")
+	cfg.AdminListenAddr = strings.TrimSpace(os.Getenv("ADMIN_LISTEN_ADDR"))
+	cfg.StatusAPIToken = os.Getenv("STATUS_API_TOKEN")
+
+	if val := os.Getenv("STRICT_SECURITY"); val != "" {
+		cfg.StrictSecurity, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("STRICT_SECURITY", "invalid value %q: %v", val, err)
+		}
+	}
+
+	for key, methods := range map[string]*[]string{"STATUS_AUTH": &cfg.StatusAuth, "ADMIN_AUTH": &cfg.AdminAuth} {
+		val := os.Getenv(key)
+		if val == "" {
+			continue
+		}
+		for _, method := range strings.Split(val, ",") {
+			switch method = strings.TrimSpace(method); method {
+			case "":
+			case HTTPAuthToken, HTTPAuthJWT, HTTPAuthHeader:
+				*methods = append(*methods, method)
+			default:
+				problems.Add(key, "unknown auth method %q (use token, jwt, or header)", method)
+			}
+		}
+	}
+	for key, networks := range map[string]*[]netip.Prefix{
+		"STATUS_ALLOWED_IPS":   &cfg.StatusAllowedIPs,
+		"ADMIN_ALLOWED_IPS":    &cfg.AdminAllowedIPs,
+		"HTTP_TRUSTED_PROXIES": &cfg.HTTPTrustedProxies,
+	} {
+		if val := os.Getenv(key); val != "" {
+			if *networks, err = httpauth.ParseNetworks(strings.Split(val, ",")); err != nil {
+				problems.Add(key, "invalid value %q: %v", val, err)
+			}
+		}
+	}
+	cfg.HTTPAuthHeader = strings.TrimSpace(os.Getenv("HTTP_AUTH_HEADER"))
+	cfg.HTTPAuthJWTSecret = os.Getenv("HTTP_AUTH_JWT_SECRET")
+	if val := os.Getenv("HTTP_AUTH_JWT_KEY_FILE"); val != "" {
+		if pemData, err := os.ReadFile(val); err != nil {
+			problems.Add("HTTP_AUTH_JWT_KEY_FILE", "%v", err)
+		} else if cfg.HTTPAuthJWTKey, err = httpauth.ParseVerificationKey(pemData); err != nil {
+			problems.Add("HTTP_AUTH_JWT_KEY_FILE", "%s: %v", val, err)
+		}
+	}
+	cfg.HTTPAuthJWTIssuer = os.Getenv("HTTP_AUTH_JWT_ISSUER")
+	cfg.HTTPAuthJWTAudience = os.Getenv("HTTP_AUTH_JWT_AUDIENCE")
+	cfg.HTTPAuthJWTHeader = strings.TrimSpace(os.Getenv("HTTP_AUTH_JWT_HEADER"))
+
+	if val := os.Getenv("STARTUP_MODE"); val != "" {
+		switch mode := StartupMode(val); mode {
+		case StartupStrict, StartupSupervised:
+			cfg.StartupMode = mode
+		default:
+			problems.Add("STARTUP_MODE", "unknown mode %q (use strict or supervised)", val)
+		}
+	}
+
+	if val := os.Getenv("STARTUP_MAX_BACKOFF"); val != "" {
+		cfg.StartupMaxBackoff, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("STARTUP_MAX_BACKOFF", "invalid value %q: %v", val, err)
+		}
+	}
+
 	if val := os.Getenv("WORKING_DIRECTORY"); val != "" {
 		cfg.WorkingDirectory = val
 	}
 
+	if val := os.Getenv("COMMAND_LOG_ATTACHMENTS"); val != "" {
+		cfg.CommandLogAttachments, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("COMMAND_LOG_ATTACHMENTS", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("COMMAND_LOG_MIN_BYTES"); val != "" {
+		cfg.CommandLogMinBytes, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("COMMAND_LOG_MIN_BYTES", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("EDIT_DIFF_ATTACHMENTS"); val != "" {
+		cfg.EditDiffAttachments, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("EDIT_DIFF_ATTACHMENTS", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("IMAGE_OUTPUT_UPLOADS"); val != "" {
+		cfg.ImageOutputUploads, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("IMAGE_OUTPUT_UPLOADS", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("BATCH_CONCURRENCY"); val != "" {
+		cfg.BatchConcurrency, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("BATCH_CONCURRENCY", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("BATCH_MAX_PATHS"); val != "" {
+		cfg.BatchMaxPaths, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("BATCH_MAX_PATHS", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("MAX_CONCURRENT_RUNS"); val != "" {
+		cfg.MaxConcurrentRuns, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("MAX_CONCURRENT_RUNS", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SLOW_MODE_BACKOFF"); val != "" {
+		cfg.SlowModeBackoff, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("SLOW_MODE_BACKOFF", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SLOW_MODE_MAX_BACKOFF"); val != "" {
+		cfg.SlowModeMaxBackoff, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("SLOW_MODE_MAX_BACKOFF", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("CHANNEL_PRIORITIES"); val != "" {
+		cfg.ChannelPriorities = make(map[string]string)
+		for _, entry := range strings.Split(val, ",") {
+			if entry = strings.TrimSpace(entry); entry == "" {
+				continue
+			}
+			channelID, priority, found := strings.Cut(entry, ":")
+			channelID, priority = strings.TrimSpace(channelID), strings.TrimSpace(priority)
+			if !found || channelID == "" {
+				problems.Add("CHANNEL_PRIORITIES", "invalid entry %q: expected channel:priority", entry)
+				continue
+			}
+			if _, err := runqueue.ParsePriority(priority); err != nil {
+				problems.Add("CHANNEL_PRIORITIES", "%v for %s", err, channelID)
+				continue
+			}
+			cfg.ChannelPriorities[channelID] = priority
+		}
+	}
+
+	if val := os.Getenv("USER_PROFILE_CACHE_TTL"); val != "" {
+		cfg.UserProfileCacheTTL, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("USER_PROFILE_CACHE_TTL", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("NOTIFY_AFTER"); val != "" {
+		cfg.NotifyAfter, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("NOTIFY_AFTER", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("TASK_TRACKER"); val != "" {
+		cfg.TaskTracker, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("TASK_TRACKER", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("TOOL_EVENTS"); val != "" {
+		cfg.ToolEvents, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("TOOL_EVENTS", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("DUPLICATE_RUN_WINDOW"); val != "" {
+		cfg.DuplicateRunWindow, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("DUPLICATE_RUN_WINDOW", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("DEPLOY_NOTIFY_DELAY"); val != "" {
+		cfg.DeployNotifyDelay, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("DEPLOY_NOTIFY_DELAY", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("PATCH_REVIEW"); val != "" {
+		cfg.PatchReview, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("PATCH_REVIEW", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("DIRTY_WORKSPACE_CHECK"); val != "" {
+		cfg.DirtyWorkspaceCheck, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("DIRTY_WORKSPACE_CHECK", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SUMMARIZE_ON_CLOSE"); val != "" {
+		cfg.SummarizeOnClose, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("SUMMARIZE_ON_CLOSE", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SESSION_TITLES"); val != "" {
+		cfg.SessionTitles, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("SESSION_TITLES", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SESSION_TITLE_MODEL"); val != "" {
+		cfg.SessionTitleModel = val
+	}
+
+	if val := os.Getenv("SESSION_CACHE_INVALIDATION"); val != "" {
+		cfg.SessionCacheInvalidation, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("SESSION_CACHE_INVALIDATION", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("CONTEXT_WINDOW_TOKENS"); val != "" {
+		cfg.ContextWindowTokens, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("CONTEXT_WINDOW_TOKENS", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("CONTEXT_WARN_PERCENT"); val != "" {
+		cfg.ContextWarnPercent, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("CONTEXT_WARN_PERCENT", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("PROMPT_MAX_TOKENS"); val != "" {
+		cfg.PromptMaxTokens, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("PROMPT_MAX_TOKENS", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SLACK_CHANNEL_PACING"); val != "" {
+		cfg.SlackChannelPacing, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("SLACK_CHANNEL_PACING", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("SLACK_MAX_RETRIES"); val != "" {
+		cfg.SlackMaxRetries, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("SLACK_MAX_RETRIES", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("OUTBOX_MAX_AGE"); val != "" {
+		cfg.OutboxMaxAge, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("OUTBOX_MAX_AGE", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("OUTBOX_BACKOFF"); val != "" {
+		cfg.OutboxBackoff, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("OUTBOX_BACKOFF", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("OUTBOX_MAX_BACKOFF"); val != "" {
+		cfg.OutboxMaxBackoff, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("OUTBOX_MAX_BACKOFF", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("PRESENCE_HEARTBEAT_INTERVAL"); val != "" {
+		cfg.PresenceHeartbeatInterval, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("PRESENCE_HEARTBEAT_INTERVAL", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("PRESENCE_STATUS"); val != "" {
+		cfg.PresenceStatus, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("PRESENCE_STATUS", "invalid value %q: %v", val, err)
+		}
+	}
+	cfg.SlackUserToken = strings.TrimSpace(os.Getenv("SLACK_USER_TOKEN"))
+	if cfg.SlackUserToken != "" && !strings.HasPrefix(cfg.SlackUserToken, "xoxp-") {
+		problems.Add("SLACK_USER_TOKEN", "must be a user token (xoxp-...)")
+	}
+
+	if val := os.Getenv("PREFLIGHT_MIN_FREE_MB"); val != "" {
+		cfg.PreflightMinFreeMB, err = strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			problems.Add("PREFLIGHT_MIN_FREE_MB", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("FILE_MAX_SIZE_MB"); val != "" {
+		cfg.FileMaxSizeMB, err = strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			problems.Add("FILE_MAX_SIZE_MB", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("FILE_USER_QUOTA_MB"); val != "" {
+		cfg.FileUserQuotaMB, err = strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			problems.Add("FILE_USER_QUOTA_MB", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("FILE_GLOBAL_QUOTA_MB"); val != "" {
+		cfg.FileGlobalQuotaMB, err = strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			problems.Add("FILE_GLOBAL_QUOTA_MB", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("FILE_RETENTION"); val != "" {
+		cfg.FileRetention, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("FILE_RETENTION", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("FILE_CLEANUP_INTERVAL"); val != "" {
+		cfg.FileCleanupInterval, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("FILE_CLEANUP_INTERVAL", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("TRANSCRIBE_COMMAND"); val != "" {
+		if cfg.TranscribeCommand = strings.TrimSpace(val); cfg.TranscribeCommand == "" {
+			problems.Add("TRANSCRIBE_COMMAND", "must not be blank")
+		}
+	}
+	cfg.TranscribeURL = os.Getenv("TRANSCRIBE_URL")
+	cfg.TranscribeAPIKey = os.Getenv("TRANSCRIBE_API_KEY")
+	cfg.TranscribeModel = os.Getenv("TRANSCRIBE_MODEL")
+
+	if val := os.Getenv("TRANSCRIBE_TIMEOUT"); val != "" {
+		cfg.TranscribeTimeout, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("TRANSCRIBE_TIMEOUT", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("WORKDIR_ROOTS"); val != "" {
+		cfg.WorkdirRoots = strings.Split(val, ",")
+	}
+
+	cfg.WorkdirTemplate = strings.TrimSpace(os.Getenv("WORKDIR_TEMPLATE"))
+	cfg.WorkdirRepo = strings.TrimSpace(os.Getenv("WORKDIR_REPO"))
+
+	if val := os.Getenv("SESSION_WORKTREES"); val != "" {
+		cfg.SessionWorktrees, err = strconv.ParseBool(val)
+		if err != nil {
+			problems.Add("SESSION_WORKTREES", "invalid value %q: %v", val, err)
+		}
+	}
+	cfg.SessionWorktreeDir = strings.TrimSpace(os.Getenv("SESSION_WORKTREE_DIR"))
+
+	if val := os.Getenv("WORKSPACE_AUDIT_ROOTS"); val != "" {
+		for _, root := range strings.Split(val, ",") {
+			if root = strings.TrimSpace(root); root != "" {
+				cfg.WorkspaceAuditRoots = append(cfg.WorkspaceAuditRoots, root)
+			}
+		}
+	}
+
+	if val := os.Getenv("WORKSPACE_AUDIT_INTERVAL"); val != "" {
+		cfg.WorkspaceAuditInterval, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("WORKSPACE_AUDIT_INTERVAL", "invalid value %q: %v", val, err)
+		}
+	}
+
+	cfg.WorkspaceCloneRoot = strings.TrimSpace(os.Getenv("WORKSPACE_CLONE_ROOT"))
+	if val := os.Getenv("WORKSPACE_CLONE_HOSTS"); val != "" {
+		cfg.WorkspaceCloneHosts = nil
+		for _, host := range strings.Split(val, ",") {
+			if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+				cfg.WorkspaceCloneHosts = append(cfg.WorkspaceCloneHosts, host)
+			}
+		}
+	}
+	cfg.WorkspaceCloneSSHKey = strings.TrimSpace(os.Getenv("WORKSPACE_CLONE_SSH_KEY"))
+	cfg.WorkspaceCloneToken = os.Getenv("WORKSPACE_CLONE_TOKEN")
+	if val := os.Getenv("WORKSPACE_CLONE_TIMEOUT"); val != "" {
+		cfg.WorkspaceCloneTimeout, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("WORKSPACE_CLONE_TIMEOUT", "invalid value %q: %v", val, err)
+		}
+	}
+
 	if val := os.Getenv("ALLOWED_COMMANDS"); val != "" {
 		cfg.AllowedCommands = strings.Split(val, ",")
 	}
@@ -266,14 +1343,14 @@ func Load() (*Config, error) {
 	if val := os.Getenv("COMMAND_TIMEOUT"); val != "" {
 		cfg.CommandTimeout, err = time.ParseDuration(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid COMMAND_TIMEOUT: %v", err)
+			problems.Add("COMMAND_TIMEOUT", "invalid value %q: %v", val, err)
 		}
 	}
 
 	if val := os.Getenv("MAX_OUTPUT_LENGTH"); val != "" {
 		cfg.MaxOutputLength, err = strconv.Atoi(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid MAX_OUTPUT_LENGTH: %v", err)
+			problems.Add("MAX_OUTPUT_LENGTH", "invalid value %q: %v", val, err)
 		}
 	}
 
@@ -289,7 +1366,7 @@ func Load() (*Config, error) {
 	if val := os.Getenv("DB_PORT"); val != "" {
 		cfg.Database.Port, err = strconv.Atoi(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid DB_PORT: %v", err)
+			problems.Add("DB_PORT", "invalid value %q: %v", val, err)
 		}
 	}
 
@@ -308,69 +1385,166 @@ func Load() (*Config, error) {
 	if val := os.Getenv("DB_MAX_CONNECTIONS"); val != "" {
 		cfg.Database.MaxConnections, err = strconv.Atoi(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid DB_MAX_CONNECTIONS: %v", err)
+			problems.Add("DB_MAX_CONNECTIONS", "invalid value %q: %v", val, err)
 		}
 	}
 
 	if val := os.Getenv("DB_IDLE_CONNECTIONS"); val != "" {
 		cfg.Database.IdleConnections, err = strconv.Atoi(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid DB_IDLE_CONNECTIONS: %v", err)
+			problems.Add("DB_IDLE_CONNECTIONS", "invalid value %q: %v", val, err)
 		}
 	}
 
 	if val := os.Getenv("DB_MAX_LIFETIME"); val != "" {
 		cfg.Database.MaxLifetime, err = time.ParseDuration(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid DB_MAX_LIFETIME: %v", err)
+			problems.Add("DB_MAX_LIFETIME", "invalid value %q: %v", val, err)
 		}
 	}
 
 	if val := os.Getenv("ENABLE_DATABASE_PERSISTENCE"); val != "" {
 		cfg.EnableDatabasePersistence, err = strconv.ParseBool(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid ENABLE_DATABASE_PERSISTENCE: %v", err)
+			problems.Add("ENABLE_DATABASE_PERSISTENCE", "invalid value %q: %v", val, err)
 		}
 	}
 
 	if val := os.Getenv("SLACK_NOTIFICATION_CHANNELS"); val != "" {
 		cfg.NotificationChannels = strings.Split(val, ",")
 	}
+	cfg.ErrorReportChannel = strings.TrimSpace(os.Getenv("ERROR_REPORT_CHANNEL"))
+
+	if val, ok := os.LookupEnv("NOTIFY_SINKS"); ok {
+		cfg.NotificationSinks = make(map[string]string)
+		for _, entry := range strings.Split(val, ",") {
+			if entry = strings.TrimSpace(entry); entry == "" {
+				continue
+			}
+			sink, severity, found := strings.Cut(entry, ":")
+			if !found {
+				severity = "info"
+			}
+			cfg.NotificationSinks[strings.ToLower(strings.TrimSpace(sink))] = strings.ToLower(strings.TrimSpace(severity))
+		}
+	}
 
-	if val := os.Getenv("APP_VERSION"); val != "" {
-		cfg.AppVersion = val
+	cfg.NotifyWebhookURL = os.Getenv("NOTIFY_WEBHOOK_URL")
+	cfg.NotifySMTPAddr = os.Getenv("NOTIFY_SMTP_ADDR")
+	cfg.NotifySMTPUsername = os.Getenv("NOTIFY_SMTP_USERNAME")
+	cfg.NotifySMTPPassword = os.Getenv("NOTIFY_SMTP_PASSWORD")
+	cfg.NotifyEmailFrom = os.Getenv("NOTIFY_EMAIL_FROM")
+	if val := os.Getenv("NOTIFY_EMAIL_TO"); val != "" {
+		for _, address := range strings.Split(val, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				cfg.NotifyEmailTo = append(cfg.NotifyEmailTo, address)
+			}
+		}
 	}
+	cfg.NotifyPagerDutyRoutingKey = os.Getenv("NOTIFY_PAGERDUTY_ROUTING_KEY")
 
-	return cfg, nil
-}
+	if val := os.Getenv("FAILURE_ALERT_THRESHOLD"); val != "" {
+		cfg.FailureAlertThreshold, err = strconv.Atoi(val)
+		if err != nil {
+			problems.Add("FAILURE_ALERT_THRESHOLD", "invalid value %q: %v", val, err)
+		}
+	}
 
-// Validate validates the configuration
-func (c *Config) Validate() error {
-	if c.SlackBotToken == "" {
-		return fmt.Errorf("slack bot token is required")
+	if val := os.Getenv("FAILURE_ALERT_WINDOW"); val != "" {
+		cfg.FailureAlertWindow, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("FAILURE_ALERT_WINDOW", "invalid value %q: %v", val, err)
+		}
+	}
+
+	if val := os.Getenv("ESCALATION_MENTIONS"); val != "" {
+		for _, id := range strings.Split(val, ",") {
+			id = strings.TrimSpace(id)
+			switch {
+			case id == "":
+			case strings.HasPrefix(id, "S"), strings.HasPrefix(id, "U"), strings.HasPrefix(id, "W"):
+				cfg.EscalationMentions = append(cfg.EscalationMentions, id)
+			default:
+				problems.Add("ESCALATION_MENTIONS", "%q isn't a user (U...) or user group (S...) ID", id)
+			}
+		}
+	}
+
+	if val := os.Getenv("ESCALATION_CHANNEL"); val != "" {
+		cfg.EscalationChannel = val
 	}
-	if c.SlackAppToken == "" {
-		return fmt.Errorf("slack app token is required")
+
+	if val := os.Getenv("ESCALATION_COOLDOWN"); val != "" {
+		cfg.EscalationCooldown, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("ESCALATION_COOLDOWN", "invalid value %q: %v", val, err)
+		}
 	}
-	if c.SlackSigningSecret == "" {
-		return fmt.Errorf("slack signing secret is required")
+
+	if val := os.Getenv("RECEIPTS_CHANNEL"); val != "" {
+		cfg.ReceiptsChannel = strings.TrimSpace(val)
 	}
-	if c.ClaudeCodePath == "" {
-		return fmt.Errorf("claude code path is required")
+
+	defaultHookFailure := "skip"
+	if val := os.Getenv("RESPONSE_HOOK_FAILURE"); val != "" {
+		defaultHookFailure = strings.ToLower(strings.TrimSpace(val))
 	}
-	if c.SessionTimeout <= 0 {
-		return fmt.Errorf("session timeout must be positive")
+	if val := os.Getenv("RESPONSE_HOOKS"); val != "" {
+		for _, entry := range strings.Split(val, ",") {
+			if entry = strings.TrimSpace(entry); entry == "" {
+				continue
+			}
+			name, policy, found := strings.Cut(entry, ":")
+			if !found {
+				policy = defaultHookFailure
+			}
+			cfg.ResponseHooks = append(cfg.ResponseHooks, ResponseHook{
+				Name:      strings.ToLower(strings.TrimSpace(name)),
+				OnFailure: strings.ToLower(strings.TrimSpace(policy)),
+			})
+		}
 	}
-	if c.MaxSessionsPerUser <= 0 {
-		return fmt.Errorf("max sessions per user must be positive")
+
+	if val := os.Getenv("RESPONSE_HOOK_TIMEOUT"); val != "" {
+		cfg.ResponseHookTimeout, err = time.ParseDuration(val)
+		if err != nil {
+			problems.Add("RESPONSE_HOOK_TIMEOUT", "invalid value %q: %v", val, err)
+		}
 	}
-	if c.RateLimitPerMinute <= 0 {
-		return fmt.Errorf("rate limit per minute must be positive")
+
+	cfg.ResponseRedactPattern = os.Getenv("RESPONSE_REDACT_PATTERN")
+	if val, ok := os.LookupEnv("RESPONSE_REDACT_REPLACEMENT"); ok {
+		cfg.ResponseRedactReplacement = val
 	}
-	if c.ServerPort <= 0 || c.ServerPort > 65535 {
-		return fmt.Errorf("server port must be between 1 and 65535")
+	cfg.ResponseFooter = strings.ReplaceAll(os.Getenv("RESPONSE_FOOTER"), `\n`, "\n")
+	cfg.ResponseWebhookURL = os.Getenv("RESPONSE_WEBHOOK_URL")
+	cfg.ResponseForwardURL = os.Getenv("RESPONSE_FORWARD_URL")
+
+	if val := os.Getenv("FOOTER_ENV"); val != "" {
+		cfg.FooterEnv, err = ParseFooterEnvFields(val)
+		if err != nil {
+			problems.Add("FOOTER_ENV", "%v", err)
+		}
 	}
-	return nil
+
+	if val := os.Getenv("APP_VERSION"); val != "" {
+		cfg.AppVersion = val
+	}
+
+	cfg.validate(problems)
+	if err := problems.Err(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the configuration and returns a *ValidationError listing
+// every problem found, or nil
+func (c *Config) Validate() error {
+	problems := &ValidationError{}
+	c.validate(problems)
+	return problems.Err()
 }
 
 // IsUserAllowed checks if a user is allowed to use the bot
@@ -436,11 +1610,119 @@ func (c *Config) IsCommandAllowed(command string) bool {
 	return false
 }
 
-// getEnvRequired gets an environment variable and returns error if not set
-func getEnvRequired(key string) string {
-	value := os.Getenv(key)
+// redactedValue replaces secret values in diagnostic output
+const redactedValue = "[REDACTED]"
+
+// Redacted returns a copy of the configuration with secrets masked, safe to
+// include in logs and debug bundles
+func (c *Config) Redacted() *Config {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	redacted := *c
+	redacted.SlackBotToken = redactIfSet(c.SlackBotToken)
+	redacted.SlackAppToken = redactIfSet(c.SlackAppToken)
+	redacted.SlackSigningSecret = redactIfSet(c.SlackSigningSecret)
+	redacted.SlackClientSecret = redactIfSet(c.SlackClientSecret)
+	redacted.SlackRefreshToken = redactIfSet(c.SlackRefreshToken)
+	redacted.SlackUserToken = redactIfSet(c.SlackUserToken)
+	redacted.TranscribeAPIKey = redactIfSet(c.TranscribeAPIKey)
+	redacted.EnvEncryptionKey = redactIfSet(c.EnvEncryptionKey)
+	redacted.NotifySMTPPassword = redactIfSet(c.NotifySMTPPassword)
+	redacted.NotifyPagerDutyRoutingKey = redactIfSet(c.NotifyPagerDutyRoutingKey)
+	// Webhook URLs carry their secret in the path, e.g. Slack and Discord's
+	redacted.NotifyWebhookURL = redactIfSet(c.NotifyWebhookURL)
+	redacted.ResponseWebhookURL = redactIfSet(c.ResponseWebhookURL)
+	redacted.ResponseForwardURL = redactIfSet(c.ResponseForwardURL)
+	redacted.AdminAPIToken = redactIfSet(c.AdminAPIToken)
+	redacted.StatusAPIToken = redactIfSet(c.StatusAPIToken)
+	redacted.HTTPAuthJWTSecret = redactIfSet(c.HTTPAuthJWTSecret)
+	redacted.WorkspaceCloneToken = redactIfSet(c.WorkspaceCloneToken)
+	redacted.Database.Password = redactIfSet(c.Database.Password)
+	redacted.Database.URL = redactIfSet(c.Database.URL)
+	return &redacted
+}
+
+// SecretValues returns the configured secret values so free-form text can be
+// scrubbed of them
+func (c *Config) SecretValues() []string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	var secrets []string
+	for _, value := range []string{
+		c.SlackBotToken,
+		c.SlackAppToken,
+		c.SlackSigningSecret,
+		c.SlackClientSecret,
+		c.SlackRefreshToken,
+		c.SlackUserToken,
+		c.TranscribeAPIKey,
+		c.EnvEncryptionKey,
+		c.NotifySMTPPassword,
+		c.NotifyPagerDutyRoutingKey,
+		c.NotifyWebhookURL,
+		c.ResponseWebhookURL,
+		c.ResponseForwardURL,
+		c.AdminAPIToken,
+		c.StatusAPIToken,
+		c.HTTPAuthJWTSecret,
+		c.WorkspaceCloneToken,
+		c.Database.Password,
+		c.Database.URL,
+	} {
+		if value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
+
+// SlackCredentials holds the Slack secrets that can change at runtime
+type SlackCredentials struct {
+	BotToken      string
+	AppToken      string
+	SigningSecret string
+	RefreshToken  string
+}
+
+// secretsMu guards the Slack credentials, which are replaced on rotation and
+// reload while other goroutines read them
+var secretsMu sync.RWMutex
+
+// SlackCredentials returns the current Slack credentials
+func (c *Config) SlackCredentials() SlackCredentials {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	return SlackCredentials{
+		BotToken:      c.SlackBotToken,
+		AppToken:      c.SlackAppToken,
+		SigningSecret: c.SlackSigningSecret,
+		RefreshToken:  c.SlackRefreshToken,
+	}
+}
+
+// SetSlackCredentials replaces the Slack credentials after a rotation or reload
+func (c *Config) SetSlackCredentials(creds SlackCredentials) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	c.SlackBotToken = creds.BotToken
+	c.SlackAppToken = creds.AppToken
+	c.SlackSigningSecret = creds.SigningSecret
+	c.SlackRefreshToken = creds.RefreshToken
+}
+
+// ScrubSecrets replaces any configured secret value found in text
+func (c *Config) ScrubSecrets(text string) string {
+	for _, secret := range c.SecretValues() {
+		text = strings.ReplaceAll(text, secret, redactedValue)
+	}
+	return text
+}
+
+func redactIfSet(value string) string {
 	if value == "" {
-		panic(fmt.Sprintf("Required environment variable %s is not set", key))
+		return ""
 	}
-	return value
-}
\ No newline at end of file
+	return redactedValue
+}
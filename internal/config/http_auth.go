@@ -0,0 +1,66 @@
+package config
+
+import (
+	"github.com/ghabxph/claude-on-slack/internal/httpauth"
+)
+
+// HTTP endpoint groups, each with its own auth policy
+const (
+	HTTPGroupStatus = "status" // Health check, /metrics, and /version
+	HTTPGroupAdmin  = "admin"  // The /admin/ API
+)
+
+// Ways an endpoint group can authenticate requests, for STATUS_AUTH and ADMIN_AUTH
+const (
+	HTTPAuthToken  = "token"  // The group's static bearer token
+	HTTPAuthJWT    = "jwt"    // A JWT checked with HTTP_AUTH_JWT_SECRET or HTTP_AUTH_JWT_KEY_FILE
+	HTTPAuthHeader = "header" // HTTP_AUTH_HEADER from one of HTTP_TRUSTED_PROXIES
+)
+
+// HTTPAuthPolicy returns the auth policy for an endpoint group. Methods
+// without the settings they need are left out, so the status endpoints stay
+// open and the admin API stays off until one is configured.
+func (c *Config) HTTPAuthPolicy(group string) *httpauth.Policy {
+	methods, token, allowed := c.StatusAuth, c.StatusAPIToken, c.StatusAllowedIPs
+	if group == HTTPGroupAdmin {
+		methods, token, allowed = c.AdminAuth, c.AdminAPIToken, c.AdminAllowedIPs
+	}
+	if len(methods) == 0 {
+		methods = []string{HTTPAuthToken}
+	}
+
+	policy := &httpauth.Policy{
+		AllowedNetworks: allowed,
+		TrustedProxies:  c.HTTPTrustedProxies,
+		Optional:        group == HTTPGroupStatus,
+	}
+	for _, method := range methods {
+		switch method {
+		case HTTPAuthToken:
+			if token != "" {
+				policy.Providers = append(policy.Providers, httpauth.NewStaticTokens(token))
+			}
+		case HTTPAuthJWT:
+			var key interface{} = c.HTTPAuthJWTKey
+			if c.HTTPAuthJWTSecret != "" {
+				key = []byte(c.HTTPAuthJWTSecret)
+			}
+			if key != nil {
+				policy.Providers = append(policy.Providers, &httpauth.JWT{
+					Key:      key,
+					Issuer:   c.HTTPAuthJWTIssuer,
+					Audience: c.HTTPAuthJWTAudience,
+					Header:   c.HTTPAuthJWTHeader,
+				})
+			}
+		case HTTPAuthHeader:
+			if c.HTTPAuthHeader != "" && len(c.HTTPTrustedProxies) > 0 {
+				policy.Providers = append(policy.Providers, &httpauth.TrustedHeader{
+					Header:  c.HTTPAuthHeader,
+					Proxies: c.HTTPTrustedProxies,
+				})
+			}
+		}
+	}
+	return policy
+}
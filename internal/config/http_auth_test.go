@@ -0,0 +1,85 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func providerNames(c *Config, group string) string {
+	var names []string
+	for _, provider := range c.HTTPAuthPolicy(group).Providers {
+		names = append(names, provider.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func TestHTTPAuthPolicy_Defaults(t *testing.T) {
+	c := &Config{}
+	if status := c.HTTPAuthPolicy(HTTPGroupStatus); status.Guarded() || !status.Optional {
+		t.Errorf("Expected open status endpoints without a token, got %+v", status)
+	}
+	if admin := c.HTTPAuthPolicy(HTTPGroupAdmin); admin.Guarded() || admin.Optional {
+		t.Errorf("Expected the admin API to refuse everything without a token, got %+v", admin)
+	}
+
+	c.AdminAPIToken = "0123456789abcdef"
+	if got := providerNames(c, HTTPGroupAdmin); got != "token" {
+		t.Errorf("Expected ADMIN_API_TOKEN to guard the admin API by default, got %q", got)
+	}
+	if got := providerNames(c, HTTPGroupStatus); got != "" {
+		t.Errorf("Expected the admin token kept off the status endpoints, got %q", got)
+	}
+}
+
+func TestLoad_HTTPAuth(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKING_DIRECTORY", t.TempDir())
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "iap.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("ADMIN_API_TOKEN", "0123456789abcdef")
+	t.Setenv("ADMIN_AUTH", "jwt, header")
+	t.Setenv("ADMIN_ALLOWED_IPS", "10.0.0.0/8,192.168.1.5")
+	t.Setenv("STATUS_AUTH", "token,jwt")
+	t.Setenv("HTTP_TRUSTED_PROXIES", "10.0.0.2")
+	t.Setenv("HTTP_AUTH_HEADER", "X-Forwarded-Email")
+	t.Setenv("HTTP_AUTH_JWT_KEY_FILE", keyFile)
+	t.Setenv("HTTP_AUTH_JWT_HEADER", "X-Goog-IAP-JWT-Assertion")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := providerNames(cfg, HTTPGroupAdmin); got != "jwt,header" {
+		t.Errorf("Expected ADMIN_AUTH to replace the token, got %q", got)
+	}
+	if got := providerNames(cfg, HTTPGroupStatus); got != "jwt" {
+		t.Errorf("Expected token left out without STATUS_API_TOKEN, got %q", got)
+	}
+	if got := len(cfg.HTTPAuthPolicy(HTTPGroupAdmin).AllowedNetworks); got != 2 {
+		t.Errorf("Expected 2 allowed networks, got %d", got)
+	}
+
+	t.Setenv("HTTP_AUTH_JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "HTTP_AUTH_JWT_SECRET: can't be used with HTTP_AUTH_JWT_KEY_FILE") {
+		t.Errorf("Expected a secret and a key file together to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import "strings"
+
+// Capability is a kind of action Claude can take in the working directory
+type Capability string
+
+const (
+	CapabilityFileEdits Capability = "file edits"
+	CapabilityBash      Capability = "bash"
+	CapabilityNetwork   Capability = "network"
+)
+
+// Capabilities lists every capability in the order they are shown
+var Capabilities = []Capability{CapabilityFileEdits, CapabilityBash, CapabilityNetwork}
+
+// capabilityTools are the Claude Code tools that exercise each capability
+var capabilityTools = map[Capability][]string{
+	CapabilityFileEdits: {"Edit", "MultiEdit", "Write", "NotebookEdit"},
+	CapabilityBash:      {"Bash", "BashOutput", "KillShell"},
+	CapabilityNetwork:   {"WebFetch", "WebSearch"},
+}
+
+// Access is how a permission mode treats a capability
+type Access string
+
+const (
+	AccessAllowed Access = "allowed"
+	AccessAsk     Access = "asks first" // Needs approval, which a Slack run can't give, so the tool call fails
+	AccessDenied  Access = "denied"     // Tools are removed from the run
+)
+
+// ModeCapabilities describes what a permission mode lets Claude do
+type ModeCapabilities struct {
+	Mode        PermissionMode
+	Description string
+	Access      map[Capability]Access
+}
+
+// PermissionCapabilities is the capability matrix for every permission mode,
+// in the order they are shown
+var PermissionCapabilities = []ModeCapabilities{
+	{
+		Mode:        PermissionModeDefault,
+		Description: "Standard permissions; anything beyond reading asks first",
+		Access: map[Capability]Access{
+			CapabilityFileEdits: AccessAsk,
+			CapabilityBash:      AccessAsk,
+			CapabilityNetwork:   AccessAsk,
+		},
+	},
+	{
+		Mode:        PermissionModeAcceptEdits,
+		Description: "Automatically accept file edits",
+		Access: map[Capability]Access{
+			CapabilityFileEdits: AccessAllowed,
+			CapabilityBash:      AccessAsk,
+			CapabilityNetwork:   AccessAsk,
+		},
+	},
+	{
+		Mode:        PermissionModeBypassPerms,
+		Description: "Bypass all permission checks",
+		Access: map[Capability]Access{
+			CapabilityFileEdits: AccessAllowed,
+			CapabilityBash:      AccessAllowed,
+			CapabilityNetwork:   AccessAllowed,
+		},
+	},
+	{
+		Mode:        PermissionModePlan,
+		Description: "Planning mode; reads and researches but won't change anything",
+		Access: map[Capability]Access{
+			CapabilityFileEdits: AccessDenied,
+			CapabilityBash:      AccessDenied,
+			CapabilityNetwork:   AccessAllowed,
+		},
+	},
+}
+
+// CapabilitiesFor returns the capability matrix row for mode. Unknown modes
+// are treated as default.
+func CapabilitiesFor(mode PermissionMode) ModeCapabilities {
+	for _, caps := range PermissionCapabilities {
+		if caps.Mode == mode {
+			return caps
+		}
+	}
+	return PermissionCapabilities[0]
+}
+
+// Summary renders the mode's capabilities, e.g. "file edits allowed, bash
+// asks first, network asks first"
+func (c ModeCapabilities) Summary() string {
+	parts := make([]string, 0, len(Capabilities))
+	for _, capability := range Capabilities {
+		parts = append(parts, string(capability)+" "+string(c.Access[capability]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// RestrictTools applies the mode's denied capabilities to a run's tools. It
+// returns the allowed tools with denied ones removed (empty still means all
+// tools) and the tools to pass as disallowed, which keep denied tools off
+// even when no other allowed tool remains.
+func (c ModeCapabilities) RestrictTools(allowedTools []string) (allowed, disallowed []string) {
+	denied := make(map[string]bool)
+	for _, capability := range Capabilities {
+		if c.Access[capability] != AccessDenied {
+			continue
+		}
+		for _, tool := range capabilityTools[capability] {
+			denied[tool] = true
+			disallowed = append(disallowed, tool)
+		}
+	}
+
+	allowed = make([]string, 0, len(allowedTools))
+	for _, tool := range allowedTools {
+		// Tool rules such as "Bash(git:*)" are denied with their tool
+		name := tool
+		if i := strings.Index(name, "("); i >= 0 {
+			name = name[:i]
+		}
+		if !denied[strings.TrimSpace(name)] {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed, disallowed
+}
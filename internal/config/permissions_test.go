@@ -0,0 +1,44 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPermissionCapabilities_CoverEveryMode(t *testing.T) {
+	for _, mode := range []PermissionMode{PermissionModeDefault, PermissionModeAcceptEdits, PermissionModeBypassPerms, PermissionModePlan} {
+		caps := CapabilitiesFor(mode)
+		if caps.Mode != mode {
+			t.Errorf("CapabilitiesFor(%q) returned %q", mode, caps.Mode)
+		}
+		for _, capability := range Capabilities {
+			if caps.Access[capability] == "" {
+				t.Errorf("%s has no access level for %s", mode, capability)
+			}
+		}
+	}
+}
+
+func TestRestrictTools_Plan(t *testing.T) {
+	allowed, disallowed := CapabilitiesFor(PermissionModePlan).RestrictTools([]string{"Read", "Bash(git:*)", "Edit", "WebFetch"})
+
+	if want := []string{"Read", "WebFetch"}; !reflect.DeepEqual(allowed, want) {
+		t.Errorf("allowed = %v, want %v", allowed, want)
+	}
+	for _, tool := range []string{"Bash", "Edit", "Write"} {
+		found := false
+		for _, d := range disallowed {
+			found = found || d == tool
+		}
+		if !found {
+			t.Errorf("Expected %s in disallowed tools %v", tool, disallowed)
+		}
+	}
+}
+
+func TestRestrictTools_BypassKeepsEverything(t *testing.T) {
+	allowed, disallowed := CapabilitiesFor(PermissionModeBypassPerms).RestrictTools(nil)
+	if len(allowed) != 0 || len(disallowed) != 0 {
+		t.Errorf("allowed, disallowed = %v, %v; want none", allowed, disallowed)
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecretKeys are the credentials that may be provided by SLACK_SECRETS_FILE
+// and reloaded without a restart
+var SecretKeys = []string{
+	"SLACK_BOT_TOKEN",
+	"SLACK_APP_TOKEN",
+	"SLACK_SIGNING_SECRET",
+	"SLACK_REFRESH_TOKEN",
+}
+
+// ReadSecretsFile parses a KEY=VALUE secrets file. Blank lines, # comments,
+// an optional "export " prefix, and single or double quoted values are supported.
+func ReadSecretsFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open secrets file: %w", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid secrets file line %d: expected KEY=VALUE", lineNumber)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	return values, nil
+}
+
+// UpdateSecretsFile rewrites the given keys in a secrets file, keeping other
+// lines intact and appending keys that are not present yet. The file is
+// replaced atomically so concurrent readers never see a partial write.
+func UpdateSecretsFile(path string, updates map[string]string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	remaining := make(map[string]string, len(updates))
+	for key, value := range updates {
+		remaining[key] = value
+	}
+
+	var lines []string
+	if len(content) > 0 {
+		lines = strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	}
+	for i, line := range lines {
+		trimmed := strings.TrimPrefix(strings.TrimSpace(line), "export ")
+		key, _, found := strings.Cut(trimmed, "=")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if value, ok := remaining[key]; ok {
+			lines[i] = fmt.Sprintf("%s=%s", key, value)
+			delete(remaining, key)
+		}
+	}
+	for _, key := range SecretKeys {
+		if value, ok := remaining[key]; ok {
+			lines = append(lines, fmt.Sprintf("%s=%s", key, value))
+			delete(remaining, key)
+		}
+	}
+	for key, value := range remaining {
+		lines = append(lines, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".secrets-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary secrets file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set secrets file permissions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace secrets file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadAndUpdateSecretsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slack.env")
+	initial := "# Slack credentials\nexport SLACK_BOT_TOKEN=\"xoxb-old\"\nSLACK_SIGNING_SECRET='secret'\nOTHER=keep\n"
+	if err := os.WriteFile(path, []byte(initial), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := ReadSecretsFile(path)
+	if err != nil {
+		t.Fatalf("ReadSecretsFile() error = %v", err)
+	}
+	if values["SLACK_BOT_TOKEN"] != "xoxb-old" || values["SLACK_SIGNING_SECRET"] != "secret" || values["OTHER"] != "keep" {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	err = UpdateSecretsFile(path, map[string]string{
+		"SLACK_BOT_TOKEN":     "xoxb-new",
+		"SLACK_REFRESH_TOKEN": "xoxe-1",
+	})
+	if err != nil {
+		t.Fatalf("UpdateSecretsFile() error = %v", err)
+	}
+
+	values, err = ReadSecretsFile(path)
+	if err != nil {
+		t.Fatalf("ReadSecretsFile() error = %v", err)
+	}
+	if values["SLACK_BOT_TOKEN"] != "xoxb-new" || values["SLACK_REFRESH_TOKEN"] != "xoxe-1" {
+		t.Errorf("updates not applied: %v", values)
+	}
+	if values["SLACK_SIGNING_SECRET"] != "secret" || values["OTHER"] != "keep" {
+		t.Errorf("unrelated keys changed: %v", values)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestReadSecretsFileInvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slack.env")
+	if err := os.WriteFile(path, []byte("SLACK_BOT_TOKEN\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadSecretsFile(path); err == nil {
+		t.Error("expected error for line without '='")
+	}
+}
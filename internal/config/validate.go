@@ -0,0 +1,552 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/encryption"
+	"github.com/ghabxph/claude-on-slack/internal/workspace"
+)
+
+// minAdminAPITokenLength keeps the admin API from being guarded by a token
+// short enough to guess
+const minAdminAPITokenLength = 16
+
+// minJWTSecretLength is the shortest HS256 secret accepted, the size of the
+// hash it keys
+const minJWTSecretLength = 32
+
+// maxSlackMessageLength is the most characters Slack keeps of a message's
+// text; it truncates the rest
+const maxSlackMessageLength = 40000
+
+// Problem is a single configuration issue, keyed by the environment variable
+// that needs fixing
+type Problem struct {
+	Key     string
+	Message string
+}
+
+// ValidationError lists every configuration problem found at startup. Its
+// message is a readable report meant to be printed as is.
+type ValidationError struct {
+	Problems []Problem
+}
+
+// Add records a problem with key
+func (e *ValidationError) Add(key, format string, args ...interface{}) {
+	e.Problems = append(e.Problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+// Has reports whether a problem was already recorded for key
+func (e *ValidationError) Has(key string) bool {
+	for _, problem := range e.Problems {
+		if problem.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns e if any problems were recorded, or nil
+func (e *ValidationError) Err() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	var report strings.Builder
+	fmt.Fprintf(&report, "invalid configuration (%d problem(s)):", len(e.Problems))
+	for _, problem := range e.Problems {
+		fmt.Fprintf(&report, "\n  - %s: %s", problem.Key, problem.Message)
+	}
+	return report.String()
+}
+
+// ChannelTypes are the Slack conversation types CHANNEL_TYPE_PERMISSION_DEFAULTS
+// accepts
+var ChannelTypes = []string{"public", "private", "dm", "group_dm"}
+
+// isChannelType reports whether t is one of ChannelTypes
+func isChannelType(t string) bool {
+	for _, channelType := range ChannelTypes {
+		if t == channelType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid reports whether m is a permission mode Claude Code accepts
+func (m PermissionMode) IsValid() bool {
+	switch m {
+	case PermissionModeDefault, PermissionModeAcceptEdits, PermissionModeBypassPerms, PermissionModePlan:
+		return true
+	}
+	return false
+}
+
+// validate records every problem with the loaded configuration. Values that
+// already failed to parse aren't reported again.
+func (c *Config) validate(problems *ValidationError) {
+	for _, check := range []struct{ key, value string }{
+		{"SLACK_BOT_TOKEN", c.SlackBotToken},
+		{"SLACK_APP_TOKEN", c.SlackAppToken},
+		{"SLACK_SIGNING_SECRET", c.SlackSigningSecret},
+		{"CLAUDE_CODE_PATH", c.ClaudeCodePath},
+	} {
+		// Strict security mode reports a missing signing secret itself
+		if check.key == "SLACK_SIGNING_SECRET" && c.StrictSecurity {
+			continue
+		}
+		if check.value == "" {
+			problems.Add(check.key, "is required")
+		}
+	}
+
+	for _, check := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"CLAUDE_TIMEOUT", c.ClaudeTimeout},
+		{"THINKING_TIMEOUT", c.ThinkingTimeout},
+		{"COMMAND_TIMEOUT", c.CommandTimeout},
+		{"SESSION_TIMEOUT", c.SessionTimeout},
+		{"SESSION_CLEANUP_INTERVAL", c.SessionCleanupInterval},
+		{"SESSION_TRASH_RETENTION", c.SessionTrashRetention},
+		{"SECRETS_RELOAD_INTERVAL", c.SecretsReloadInterval},
+		{"CHANNEL_CONTEXT_CACHE_TTL", c.ChannelContextCacheTTL},
+		{"FEATURE_FLAG_CACHE_TTL", c.FeatureFlagCacheTTL},
+		{"USER_PROFILE_CACHE_TTL", c.UserProfileCacheTTL},
+		{"FILE_RETENTION", c.FileRetention},
+		{"FILE_CLEANUP_INTERVAL", c.FileCleanupInterval},
+		{"TRANSCRIBE_TIMEOUT", c.TranscribeTimeout},
+		{"DB_MAX_LIFETIME", c.Database.MaxLifetime},
+		{"FAILURE_ALERT_WINDOW", c.FailureAlertWindow},
+		{"ESCALATION_COOLDOWN", c.EscalationCooldown},
+		{"RESPONSE_HOOK_TIMEOUT", c.ResponseHookTimeout},
+		{"STARTUP_MAX_BACKOFF", c.StartupMaxBackoff},
+	} {
+		if check.value <= 0 && !problems.Has(check.key) {
+			problems.Add(check.key, "must be a positive duration, got %s", check.value)
+		}
+	}
+	if c.SessionListingCacheTTL < 0 && !problems.Has("SESSION_LISTING_CACHE_TTL") {
+		problems.Add("SESSION_LISTING_CACHE_TTL", "must not be negative, got %s", c.SessionListingCacheTTL)
+	}
+	if c.WorkspaceAuditInterval < 0 && !problems.Has("WORKSPACE_AUDIT_INTERVAL") {
+		problems.Add("WORKSPACE_AUDIT_INTERVAL", "must not be negative, got %s", c.WorkspaceAuditInterval)
+	}
+	if c.NotifyAfter < 0 && !problems.Has("NOTIFY_AFTER") {
+		problems.Add("NOTIFY_AFTER", "must not be negative, got %s", c.NotifyAfter)
+	}
+	if c.SessionPruneAfter < 0 && !problems.Has("SESSION_PRUNE_AFTER") {
+		problems.Add("SESSION_PRUNE_AFTER", "must not be negative, got %s", c.SessionPruneAfter)
+	}
+	if c.DuplicateRunWindow < 0 && !problems.Has("DUPLICATE_RUN_WINDOW") {
+		problems.Add("DUPLICATE_RUN_WINDOW", "must not be negative, got %s", c.DuplicateRunWindow)
+	}
+	if c.SlowModeBackoff < 0 && !problems.Has("SLOW_MODE_BACKOFF") {
+		problems.Add("SLOW_MODE_BACKOFF", "must not be negative, got %s", c.SlowModeBackoff)
+	}
+	if c.SlowModeBackoff > 0 && c.SlowModeMaxBackoff < c.SlowModeBackoff && !problems.Has("SLOW_MODE_MAX_BACKOFF") && !problems.Has("SLOW_MODE_BACKOFF") {
+		problems.Add("SLOW_MODE_MAX_BACKOFF", "must be at least SLOW_MODE_BACKOFF (%s), got %s", c.SlowModeBackoff, c.SlowModeMaxBackoff)
+	}
+	if c.DeployNotifyDelay < 0 && !problems.Has("DEPLOY_NOTIFY_DELAY") {
+		problems.Add("DEPLOY_NOTIFY_DELAY", "must not be negative, got %s", c.DeployNotifyDelay)
+	}
+	if c.OutboxMaxAge < 0 && !problems.Has("OUTBOX_MAX_AGE") {
+		problems.Add("OUTBOX_MAX_AGE", "must not be negative, got %s", c.OutboxMaxAge)
+	}
+	if c.OutboxMaxAge > 0 && c.OutboxBackoff <= 0 && !problems.Has("OUTBOX_BACKOFF") {
+		problems.Add("OUTBOX_BACKOFF", "must be positive when OUTBOX_MAX_AGE is set, got %s", c.OutboxBackoff)
+	}
+	if c.OutboxMaxAge > 0 && c.OutboxBackoff > 0 && c.OutboxMaxBackoff < c.OutboxBackoff && !problems.Has("OUTBOX_MAX_BACKOFF") && !problems.Has("OUTBOX_BACKOFF") {
+		problems.Add("OUTBOX_MAX_BACKOFF", "must be at least OUTBOX_BACKOFF (%s), got %s", c.OutboxBackoff, c.OutboxMaxBackoff)
+	}
+	if c.SlackChannelPacing < 0 && !problems.Has("SLACK_CHANNEL_PACING") {
+		problems.Add("SLACK_CHANNEL_PACING", "must not be negative, got %s", c.SlackChannelPacing)
+	}
+	if c.PresenceHeartbeatInterval < 0 && !problems.Has("PRESENCE_HEARTBEAT_INTERVAL") {
+		problems.Add("PRESENCE_HEARTBEAT_INTERVAL", "must not be negative, got %s", c.PresenceHeartbeatInterval)
+	}
+
+	for _, check := range []struct {
+		key   string
+		value int
+	}{
+		{"MAX_SESSIONS_PER_USER", c.MaxSessionsPerUser},
+		{"RATE_LIMIT_PER_MINUTE", c.RateLimitPerMinute},
+		{"RATE_LIMIT_BURST", c.RateLimitBurst},
+		{"CHANNEL_RATE_LIMIT_BURST", c.ChannelRateLimitBurst},
+		{"MAX_MESSAGE_LENGTH", c.MaxMessageLength},
+		{"BATCH_CONCURRENCY", c.BatchConcurrency},
+		{"BATCH_MAX_PATHS", c.BatchMaxPaths},
+		{"CONTEXT_WINDOW_TOKENS", c.ContextWindowTokens},
+		{"SESSION_PRUNE_KEEP", c.SessionPruneKeep},
+	} {
+		if check.value <= 0 && !problems.Has(check.key) {
+			problems.Add(check.key, "must be positive, got %d", check.value)
+		}
+	}
+	if c.MaxMessageLength > maxSlackMessageLength && !problems.Has("MAX_MESSAGE_LENGTH") {
+		problems.Add("MAX_MESSAGE_LENGTH", "must be at most %d, Slack's limit, got %d", maxSlackMessageLength, c.MaxMessageLength)
+	}
+	if (c.ContextWarnPercent < 0 || c.ContextWarnPercent > 100) && !problems.Has("CONTEXT_WARN_PERCENT") {
+		problems.Add("CONTEXT_WARN_PERCENT", "must be between 0 and 100, got %d", c.ContextWarnPercent)
+	}
+	if c.PromptMaxTokens < 0 && !problems.Has("PROMPT_MAX_TOKENS") {
+		problems.Add("PROMPT_MAX_TOKENS", "must not be negative, got %d", c.PromptMaxTokens)
+	} else if c.PromptMaxTokens >= c.ContextWindowTokens && c.ContextWindowTokens > 0 && !problems.Has("PROMPT_MAX_TOKENS") {
+		problems.Add("PROMPT_MAX_TOKENS", "must be below CONTEXT_WINDOW_TOKENS (%d), got %d", c.ContextWindowTokens, c.PromptMaxTokens)
+	}
+	if c.SessionPruneChunk < 2 && !problems.Has("SESSION_PRUNE_CHUNK") {
+		problems.Add("SESSION_PRUNE_CHUNK", "must be at least 2, got %d", c.SessionPruneChunk)
+	}
+	if c.MaxConcurrentRuns < 0 && !problems.Has("MAX_CONCURRENT_RUNS") {
+		problems.Add("MAX_CONCURRENT_RUNS", "must not be negative, got %d", c.MaxConcurrentRuns)
+	}
+	if c.ChannelRateLimitPerMinute < 0 && !problems.Has("CHANNEL_RATE_LIMIT_PER_MINUTE") {
+		problems.Add("CHANNEL_RATE_LIMIT_PER_MINUTE", "must not be negative, got %d", c.ChannelRateLimitPerMinute)
+	}
+	if c.FailureAlertThreshold < 0 && !problems.Has("FAILURE_ALERT_THRESHOLD") {
+		problems.Add("FAILURE_ALERT_THRESHOLD", "must not be negative, got %d", c.FailureAlertThreshold)
+	}
+	c.validateNotificationSinks(problems)
+	c.validateResponseHooks(problems)
+	if c.AdminAPIToken != "" && len(c.AdminAPIToken) < minAdminAPITokenLength {
+		problems.Add("ADMIN_API_TOKEN", "must be at least %d characters, got %d", minAdminAPITokenLength, len(c.AdminAPIToken))
+	}
+	c.validateHTTPSecurity(problems)
+	if c.SlackMaxRetries < 0 && !problems.Has("SLACK_MAX_RETRIES") {
+		problems.Add("SLACK_MAX_RETRIES", "must not be negative, got %d", c.SlackMaxRetries)
+	}
+	if (c.ServerPort <= 0 || c.ServerPort > 65535) && !problems.Has("SERVER_PORT") {
+		problems.Add("SERVER_PORT", "must be between 1 and 65535, got %d", c.ServerPort)
+	}
+	for _, check := range []struct {
+		key   string
+		value int64
+	}{
+		{"PREFLIGHT_MIN_FREE_MB", c.PreflightMinFreeMB},
+		{"FILE_MAX_SIZE_MB", c.FileMaxSizeMB},
+		{"FILE_USER_QUOTA_MB", c.FileUserQuotaMB},
+		{"FILE_GLOBAL_QUOTA_MB", c.FileGlobalQuotaMB},
+	} {
+		if check.value < 0 && !problems.Has(check.key) {
+			problems.Add(check.key, "must not be negative, got %d", check.value)
+		}
+	}
+
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		problems.Add("LOG_LEVEL", "unknown level %q (use debug, info, warn, or error)", c.LogLevel)
+	}
+
+	if c.EnvEncryptionKey != "" {
+		if _, err := encryption.ParseKey(c.EnvEncryptionKey); err != nil {
+			problems.Add("ENV_ENCRYPTION_KEY", "%v (generate one with `openssl rand -base64 32`)", err)
+		}
+	}
+
+	if overlap := intersect(c.AllowedTools, c.DisallowedTools); len(overlap) > 0 {
+		problems.Add("DISALLOWED_TOOLS", "also listed in ALLOWED_TOOLS: %s", strings.Join(overlap, ", "))
+	}
+	if overlap := intersect(c.AllowedCommands, c.BlockedCommands); len(overlap) > 0 {
+		problems.Add("BLOCKED_COMMANDS", "also listed in ALLOWED_COMMANDS: %s", strings.Join(overlap, ", "))
+	}
+
+	// The working directory is created on first use, so it may be missing
+	if c.WorkingDirectory != "" {
+		if err := checkDirectory(c.WorkingDirectory, false); err != nil {
+			problems.Add("WORKING_DIRECTORY", "%v", err)
+		}
+	}
+	if c.WorkdirTemplate != "" {
+		if err := workspace.CheckTemplate(c.WorkdirTemplate); err != nil {
+			problems.Add("WORKDIR_TEMPLATE", "%v", err)
+		}
+	} else if c.WorkdirRepo != "" {
+		problems.Add("WORKDIR_REPO", "is only used together with WORKDIR_TEMPLATE")
+	}
+	if c.SessionWorktreeDir != "" {
+		if !filepath.IsAbs(c.SessionWorktreeDir) {
+			problems.Add("SESSION_WORKTREE_DIR", "%q is not an absolute path", c.SessionWorktreeDir)
+		} else if err := checkDirectory(c.SessionWorktreeDir, false); err != nil {
+			problems.Add("SESSION_WORKTREE_DIR", "%v", err)
+		}
+	}
+	for _, root := range c.WorkdirRoots {
+		if root = strings.TrimSpace(root); root == "" {
+			continue
+		}
+		if err := checkDirectory(root, true); err != nil {
+			problems.Add("WORKDIR_ROOTS", "%v", err)
+		}
+	}
+	if c.ExecutorMode == ExecutorFixture {
+		if c.ExecutorFixturesDir == "" {
+			problems.Add("EXECUTOR_FIXTURES_DIR", "is required when EXECUTOR_MODE is fixture")
+		} else if err := checkDirectory(c.ExecutorFixturesDir, true); err != nil {
+			problems.Add("EXECUTOR_FIXTURES_DIR", "%v", err)
+		}
+	}
+	for _, root := range c.WorkspaceAuditRoots {
+		// Everything directly under a root may be purged, so it must be a
+		// dedicated directory
+		if !filepath.IsAbs(root) {
+			problems.Add("WORKSPACE_AUDIT_ROOTS", "%q is not an absolute path", root)
+		} else if filepath.Clean(root) == filepath.Dir(filepath.Clean(root)) {
+			problems.Add("WORKSPACE_AUDIT_ROOTS", "%q cannot be the filesystem root", root)
+		} else if err := checkDirectory(root, true); err != nil {
+			problems.Add("WORKSPACE_AUDIT_ROOTS", "%v", err)
+		}
+	}
+	if c.WorkspaceCloneRoot != "" {
+		if !filepath.IsAbs(c.WorkspaceCloneRoot) {
+			problems.Add("WORKSPACE_CLONE_ROOT", "%q is not an absolute path", c.WorkspaceCloneRoot)
+		} else if err := checkDirectory(c.WorkspaceCloneRoot, false); err != nil {
+			problems.Add("WORKSPACE_CLONE_ROOT", "%v", err)
+		} else if roots := c.browsableRoots(); len(roots) > 0 && !insideAny(c.WorkspaceCloneRoot, roots) {
+			problems.Add("WORKSPACE_CLONE_ROOT", "%q is not inside WORKDIR_ROOTS (%s)", c.WorkspaceCloneRoot, strings.Join(roots, ", "))
+		}
+		if len(c.WorkspaceCloneHosts) == 0 {
+			problems.Add("WORKSPACE_CLONE_HOSTS", "must name at least one host when WORKSPACE_CLONE_ROOT is set")
+		}
+	}
+	if c.WorkspaceCloneSSHKey != "" {
+		if info, err := os.Stat(c.WorkspaceCloneSSHKey); err != nil {
+			problems.Add("WORKSPACE_CLONE_SSH_KEY", "%v", err)
+		} else if info.IsDir() {
+			problems.Add("WORKSPACE_CLONE_SSH_KEY", "%q is a directory, not a key file", c.WorkspaceCloneSSHKey)
+		}
+	}
+	if c.WorkspaceCloneTimeout <= 0 && !problems.Has("WORKSPACE_CLONE_TIMEOUT") {
+		problems.Add("WORKSPACE_CLONE_TIMEOUT", "must be positive, got %s", c.WorkspaceCloneTimeout)
+	}
+
+	if c.EnableDatabasePersistence && c.Database.URL == "" {
+		for _, check := range []struct{ key, value string }{
+			{"DB_HOST", c.Database.Host},
+			{"DB_NAME", c.Database.Name},
+			{"DB_USER", c.Database.User},
+			{"DB_PASSWORD", c.Database.Password},
+		} {
+			if check.value == "" {
+				problems.Add(check.key, "is required when ENABLE_DATABASE_PERSISTENCE is true (or set DATABASE_URL)")
+			}
+		}
+		if (c.Database.Port <= 0 || c.Database.Port > 65535) && !problems.Has("DB_PORT") {
+			problems.Add("DB_PORT", "must be between 1 and 65535, got %d", c.Database.Port)
+		}
+	}
+	if c.Database.MaxConnections <= 0 && !problems.Has("DB_MAX_CONNECTIONS") {
+		problems.Add("DB_MAX_CONNECTIONS", "must be positive, got %d", c.Database.MaxConnections)
+	}
+	if (c.Database.IdleConnections < 0 || c.Database.IdleConnections > c.Database.MaxConnections) && !problems.Has("DB_IDLE_CONNECTIONS") {
+		problems.Add("DB_IDLE_CONNECTIONS", "must be between 0 and DB_MAX_CONNECTIONS, got %d", c.Database.IdleConnections)
+	}
+}
+
+// checkDirectory reports why path can't be used as a working directory
+func checkDirectory(path string, mustExist bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if !mustExist {
+				return nil
+			}
+			return fmt.Errorf("%s does not exist", path)
+		}
+		return fmt.Errorf("%s is not accessible: %v", path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return nil
+}
+
+// browsableRoots returns the directories sessions may work in: WORKDIR_ROOTS,
+// else WORKING_DIRECTORY. Empty means the bot falls back to the home directory.
+func (c *Config) browsableRoots() []string {
+	var roots []string
+	for _, root := range c.WorkdirRoots {
+		if root = strings.TrimSpace(root); root != "" {
+			roots = append(roots, filepath.Clean(root))
+		}
+	}
+	if len(roots) == 0 && c.WorkingDirectory != "" {
+		roots = append(roots, filepath.Clean(c.WorkingDirectory))
+	}
+	return roots
+}
+
+// insideAny reports whether path is one of roots or inside one
+func insideAny(path string, roots []string) bool {
+	path = filepath.Clean(path)
+	for _, root := range roots {
+		if path == root || strings.HasPrefix(path, strings.TrimSuffix(root, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// intersect returns the trimmed entries present in both lists
+func intersect(a, b []string) []string {
+	seen := make(map[string]bool)
+	for _, item := range a {
+		if item = strings.TrimSpace(item); item != "" {
+			seen[item] = true
+		}
+	}
+
+	var both []string
+	for _, item := range b {
+		if item = strings.TrimSpace(item); seen[item] {
+			both = append(both, item)
+			delete(seen, item)
+		}
+	}
+	return both
+}
+
+// validateNotificationSinks checks NOTIFY_SINKS names and severities, and that
+// each enabled sink has the settings it needs
+func (c *Config) validateNotificationSinks(problems *ValidationError) {
+	for sink, severity := range c.NotificationSinks {
+		switch severity {
+		case "info", "warning", "critical":
+		default:
+			problems.Add("NOTIFY_SINKS", "unknown severity %q for %s (use info, warning, or critical)", severity, sink)
+		}
+
+		switch sink {
+		case "slack":
+		case "webhook":
+			if c.NotifyWebhookURL == "" {
+				problems.Add("NOTIFY_WEBHOOK_URL", "is required when the webhook sink is enabled")
+			} else if u, err := url.Parse(c.NotifyWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				problems.Add("NOTIFY_WEBHOOK_URL", "must be an http(s) URL, got %q", c.NotifyWebhookURL)
+			}
+		case "email":
+			if c.NotifySMTPAddr == "" {
+				problems.Add("NOTIFY_SMTP_ADDR", "is required when the email sink is enabled")
+			} else if _, _, err := net.SplitHostPort(c.NotifySMTPAddr); err != nil {
+				problems.Add("NOTIFY_SMTP_ADDR", "must be host:port, got %q", c.NotifySMTPAddr)
+			}
+			if c.NotifyEmailFrom == "" {
+				problems.Add("NOTIFY_EMAIL_FROM", "is required when the email sink is enabled")
+			}
+			if len(c.NotifyEmailTo) == 0 {
+				problems.Add("NOTIFY_EMAIL_TO", "is required when the email sink is enabled")
+			}
+		case "pagerduty":
+			if c.NotifyPagerDutyRoutingKey == "" {
+				problems.Add("NOTIFY_PAGERDUTY_ROUTING_KEY", "is required when the pagerduty sink is enabled")
+			}
+		default:
+			problems.Add("NOTIFY_SINKS", "unknown sink %q (use slack, webhook, email, or pagerduty)", sink)
+		}
+	}
+}
+
+// validateResponseHooks checks each built-in hook in RESPONSE_HOOKS has its
+// settings. Other names are hooks registered in code, checked at startup.
+func (c *Config) validateResponseHooks(problems *ValidationError) {
+	checkURL := func(key, value, hook string) {
+		if value == "" {
+			problems.Add(key, "is required when the %s response hook is enabled", hook)
+		} else if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems.Add(key, "must be an http(s) URL, got %q", value)
+		}
+	}
+
+	for _, hook := range c.ResponseHooks {
+		if hook.OnFailure != "skip" && hook.OnFailure != "block" {
+			problems.Add("RESPONSE_HOOKS", "unknown failure policy %q for %s (use skip or block)", hook.OnFailure, hook.Name)
+		}
+		switch hook.Name {
+		case "redact":
+			if c.ResponseRedactPattern == "" {
+				problems.Add("RESPONSE_REDACT_PATTERN", "is required when the redact response hook is enabled")
+			} else if _, err := regexp.Compile(c.ResponseRedactPattern); err != nil {
+				problems.Add("RESPONSE_REDACT_PATTERN", "invalid regular expression: %v", err)
+			}
+		case "footer":
+			if strings.TrimSpace(c.ResponseFooter) == "" {
+				problems.Add("RESPONSE_FOOTER", "is required when the footer response hook is enabled")
+			}
+		case "webhook":
+			checkURL("RESPONSE_WEBHOOK_URL", c.ResponseWebhookURL, "webhook")
+		case "forward":
+			checkURL("RESPONSE_FORWARD_URL", c.ResponseForwardURL, "forward")
+		}
+	}
+}
+
+// validateHTTPSecurity checks the status token, the admin listener, and what
+// strict security mode needs
+func (c *Config) validateHTTPSecurity(problems *ValidationError) {
+	if c.StatusAPIToken != "" && len(c.StatusAPIToken) < minAdminAPITokenLength {
+		problems.Add("STATUS_API_TOKEN", "must be at least %d characters, got %d", minAdminAPITokenLength, len(c.StatusAPIToken))
+	}
+	if c.AdminListenAddr != "" {
+		if _, port, err := net.SplitHostPort(c.AdminListenAddr); err != nil || port == "" {
+			problems.Add("ADMIN_LISTEN_ADDR", "must be host:port, e.g. 127.0.0.1:9090, got %q", c.AdminListenAddr)
+		} else if port == fmt.Sprint(c.ServerPort) {
+			problems.Add("ADMIN_LISTEN_ADDR", "must use a different port than SERVER_PORT (%d)", c.ServerPort)
+		}
+	}
+	// Strict mode checks the signature of every Slack request, so without a
+	// secret it would turn all of them away
+	if c.StrictSecurity && c.SlackSigningSecret == "" {
+		problems.Add("SLACK_SIGNING_SECRET", "is required when STRICT_SECURITY is on, since every Slack request is signature-checked")
+	}
+	if c.StrictSecurity && c.HTTPAuthPolicy(HTTPGroupAdmin).Guarded() && c.AdminListenAddr == "" {
+		problems.Add("ADMIN_LISTEN_ADDR", "is required when STRICT_SECURITY is on and the admin API is enabled")
+	}
+	c.validateHTTPAuth(problems)
+}
+
+// validateHTTPAuth checks the JWT and trusted header settings the endpoint
+// groups' auth methods need
+func (c *Config) validateHTTPAuth(problems *ValidationError) {
+	uses := func(method string) bool {
+		for _, methods := range [][]string{c.StatusAuth, c.AdminAuth} {
+			for _, m := range methods {
+				if m == method {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if c.HTTPAuthJWTSecret != "" && len(c.HTTPAuthJWTSecret) < minJWTSecretLength {
+		problems.Add("HTTP_AUTH_JWT_SECRET", "must be at least %d characters, got %d", minJWTSecretLength, len(c.HTTPAuthJWTSecret))
+	}
+	if c.HTTPAuthJWTSecret != "" && c.HTTPAuthJWTKey != nil {
+		problems.Add("HTTP_AUTH_JWT_SECRET", "can't be used with HTTP_AUTH_JWT_KEY_FILE; set one")
+	}
+	if uses(HTTPAuthJWT) && c.HTTPAuthJWTSecret == "" && c.HTTPAuthJWTKey == nil && !problems.Has("HTTP_AUTH_JWT_KEY_FILE") {
+		problems.Add("HTTP_AUTH_JWT_KEY_FILE", "or HTTP_AUTH_JWT_SECRET is required when an endpoint group uses jwt auth")
+	}
+	if uses(HTTPAuthHeader) {
+		if c.HTTPAuthHeader == "" {
+			problems.Add("HTTP_AUTH_HEADER", "is required when an endpoint group uses header auth")
+		}
+		if len(c.HTTPTrustedProxies) == 0 && !problems.Has("HTTP_TRUSTED_PROXIES") {
+			problems.Add("HTTP_TRUSTED_PROXIES", "is required when an endpoint group uses header auth")
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("SLACK_SECRETS_FILE", "")
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("SLACK_APP_TOKEN", "xapp-test")
+	t.Setenv("SLACK_SIGNING_SECRET", "secret")
+}
+
+func TestLoad_Valid(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKING_DIRECTORY", t.TempDir())
+
+	if _, err := Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("SLACK_APP_TOKEN", "")
+	t.Setenv("CLAUDE_TIMEOUT", "soon")
+	t.Setenv("SERVER_PORT", "70000")
+	t.Setenv("ALLOWED_TOOLS", "Read,Bash")
+	t.Setenv("DISALLOWED_TOOLS", "Bash")
+	t.Setenv("WORKDIR_ROOTS", "/does/not/exist")
+	t.Setenv("WORKDIR_TEMPLATE", "/home/{username}")
+	t.Setenv("WORKSPACE_AUDIT_ROOTS", "/, workspaces")
+	t.Setenv("INTENT_ROUTER", "smart")
+	t.Setenv("STARTUP_MODE", "lazy")
+	t.Setenv("ARCHIVED_CHANNEL_SESSIONS", "delete")
+	t.Setenv("ESCALATION_MENTIONS", "S123,@oncall")
+	t.Setenv("ADMIN_API_TOKEN", "hunter2")
+	t.Setenv("RESPONSE_HOOKS", "redact:maybe,forward")
+	t.Setenv("RESPONSE_REDACT_PATTERN", "(unclosed")
+	t.Setenv("MAX_MESSAGE_LENGTH", "50000")
+	t.Setenv("FOOTER_ENV", "git,python")
+	t.Setenv("EXECUTOR_MODE", "fixture")
+	t.Setenv("DUPLICATE_RUN_WINDOW", "-1m")
+	t.Setenv("SESSION_PRUNE_CHUNK", "1")
+	t.Setenv("STATUS_API_TOKEN", "short")
+	t.Setenv("ADMIN_LISTEN_ADDR", "9090")
+	t.Setenv("DEPLOY_NOTIFY_DELAY", "-5s")
+	t.Setenv("SLOW_MODE_MAX_BACKOFF", "10s")
+	t.Setenv("OUTBOX_BACKOFF", "0s")
+	t.Setenv("PROMPT_MAX_TOKENS", "-1")
+	t.Setenv("TRANSCRIBE_COMMAND", "   ")
+	t.Setenv("STATUS_AUTH", "token,oidc")
+	t.Setenv("ADMIN_AUTH", "token,header")
+	t.Setenv("ADMIN_ALLOWED_IPS", "10.0.0.0/33")
+	t.Setenv("HTTP_AUTH_JWT_SECRET", "too-short")
+	t.Setenv("WORKSPACE_CLONE_ROOT", "clones")
+	t.Setenv("WORKSPACE_CLONE_HOSTS", " , ")
+	t.Setenv("WORKSPACE_CLONE_TIMEOUT", "0s")
+	t.Setenv("ENABLE_DATABASE_PERSISTENCE", "true")
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("DB_PASSWORD", "")
+
+	_, err := Load()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *ValidationError, got %v", err)
+	}
+
+	report := err.Error()
+	if strings.Count(report, "CLAUDE_TIMEOUT") != 1 {
+		t.Errorf("Expected an unparsable value to be reported once, got:\n%s", report)
+	}
+	for _, want := range []string{
+		"SLACK_APP_TOKEN: is required",
+		"CLAUDE_TIMEOUT: invalid value \"soon\"",
+		"SERVER_PORT: must be between 1 and 65535",
+		"DISALLOWED_TOOLS: also listed in ALLOWED_TOOLS: Bash",
+		"WORKDIR_ROOTS: /does/not/exist does not exist",
+		"WORKDIR_TEMPLATE: unknown placeholder {username}",
+		"WORKSPACE_AUDIT_ROOTS: \"/\" cannot be the filesystem root",
+		"WORKSPACE_AUDIT_ROOTS: \"workspaces\" is not an absolute path",
+		"INTENT_ROUTER: unknown mode \"smart\"",
+		"STARTUP_MODE: unknown mode \"lazy\"",
+		"ARCHIVED_CHANNEL_SESSIONS: unknown policy \"delete\"",
+		"ESCALATION_MENTIONS: \"@oncall\" isn't a user (U...) or user group (S...) ID",
+		"ADMIN_API_TOKEN: must be at least 16 characters, got 7",
+		"RESPONSE_HOOKS: unknown failure policy \"maybe\" for redact",
+		"RESPONSE_REDACT_PATTERN: invalid regular expression",
+		"RESPONSE_FORWARD_URL: is required when the forward response hook is enabled",
+		"MAX_MESSAGE_LENGTH: must be at most 40000, Slack's limit, got 50000",
+		"FOOTER_ENV: unknown field \"python\" (use git, kube, venv)",
+		"EXECUTOR_FIXTURES_DIR: is required when EXECUTOR_MODE is fixture",
+		"DUPLICATE_RUN_WINDOW: must not be negative, got -1m0s",
+		"SESSION_PRUNE_CHUNK: must be at least 2, got 1",
+		"STATUS_API_TOKEN: must be at least 16 characters, got 5",
+		"ADMIN_LISTEN_ADDR: must be host:port",
+		"DEPLOY_NOTIFY_DELAY: must not be negative, got -5s",
+		"SLOW_MODE_MAX_BACKOFF: must be at least SLOW_MODE_BACKOFF (30s), got 10s",
+		"OUTBOX_BACKOFF: must be positive when OUTBOX_MAX_AGE is set, got 0s",
+		"PROMPT_MAX_TOKENS: must not be negative, got -1",
+		"TRANSCRIBE_COMMAND: must not be blank",
+		"STATUS_AUTH: unknown auth method \"oidc\" (use token, jwt, or header)",
+		"ADMIN_ALLOWED_IPS: invalid value \"10.0.0.0/33\"",
+		"HTTP_AUTH_JWT_SECRET: must be at least 32 characters, got 9",
+		"HTTP_AUTH_HEADER: is required when an endpoint group uses header auth",
+		"HTTP_TRUSTED_PROXIES: is required when an endpoint group uses header auth",
+		"WORKSPACE_CLONE_ROOT: \"clones\" is not an absolute path",
+		"WORKSPACE_CLONE_HOSTS: must name at least one host when WORKSPACE_CLONE_ROOT is set",
+		"WORKSPACE_CLONE_TIMEOUT: must be positive, got 0s",
+		"DB_PASSWORD: is required when ENABLE_DATABASE_PERSISTENCE is true",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+}
+
+func TestLoad_ChannelPermissionDefaults(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKING_DIRECTORY", t.TempDir())
+	t.Setenv("CHANNEL_PERMISSION_DEFAULTS", "C123:acceptEdits, C456:plan")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.ChannelPermissionDefaults["C123"] != PermissionModeAcceptEdits || cfg.ChannelPermissionDefaults["C456"] != PermissionModePlan {
+		t.Errorf("Unexpected channel defaults: %v", cfg.ChannelPermissionDefaults)
+	}
+
+	t.Setenv("CHANNEL_PERMISSION_DEFAULTS", "C123:yolo,C456")
+	_, err = Load()
+	if err == nil || strings.Count(err.Error(), "CHANNEL_PERMISSION_DEFAULTS") != 2 {
+		t.Errorf("Expected both entries to be reported, got %v", err)
+	}
+}
+
+func TestLoad_ChannelTypePermissionDefaults(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKING_DIRECTORY", t.TempDir())
+	t.Setenv("CHANNEL_TYPE_PERMISSION_DEFAULTS", "private:plan, dm:acceptEdits")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.ChannelTypePermissionDefaults["private"] != PermissionModePlan || cfg.ChannelTypePermissionDefaults["dm"] != PermissionModeAcceptEdits {
+		t.Errorf("Unexpected channel type defaults: %v", cfg.ChannelTypePermissionDefaults)
+	}
+
+	t.Setenv("CHANNEL_TYPE_PERMISSION_DEFAULTS", "shared:plan,private:yolo")
+	_, err = Load()
+	if err == nil || strings.Count(err.Error(), "CHANNEL_TYPE_PERMISSION_DEFAULTS") != 2 {
+		t.Errorf("Expected both entries to be reported, got %v", err)
+	}
+}
+
+func TestLoad_ChannelPriorities(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKING_DIRECTORY", t.TempDir())
+	t.Setenv("CHANNEL_PRIORITIES", "C123:urgent, C456:batch")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.ChannelPriorities["C123"] != "urgent" || cfg.ChannelPriorities["C456"] != "batch" {
+		t.Errorf("Unexpected channel priorities: %v", cfg.ChannelPriorities)
+	}
+
+	t.Setenv("CHANNEL_PRIORITIES", "C123:asap")
+	t.Setenv("MAX_CONCURRENT_RUNS", "-1")
+	_, err = Load()
+	if err == nil || !strings.Contains(err.Error(), "CHANNEL_PRIORITIES") || !strings.Contains(err.Error(), "MAX_CONCURRENT_RUNS") {
+		t.Errorf("Expected both problems to be reported, got %v", err)
+	}
+}
+
+func TestLoad_NotificationSinks(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKING_DIRECTORY", t.TempDir())
+	t.Setenv("NOTIFY_SINKS", "slack:info, webhook:warning, pagerduty")
+	t.Setenv("NOTIFY_WEBHOOK_URL", "https://hooks.example.com/ops")
+	t.Setenv("NOTIFY_PAGERDUTY_ROUTING_KEY", "routing-key")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := map[string]string{"slack": "info", "webhook": "warning", "pagerduty": "info"}
+	if len(cfg.NotificationSinks) != len(want) {
+		t.Fatalf("NotificationSinks = %v, want %v", cfg.NotificationSinks, want)
+	}
+	for sink, severity := range want {
+		if cfg.NotificationSinks[sink] != severity {
+			t.Errorf("NotificationSinks[%q] = %q, want %q", sink, cfg.NotificationSinks[sink], severity)
+		}
+	}
+
+	t.Setenv("NOTIFY_SINKS", "email:critical,sms:info,webhook:loud")
+	t.Setenv("NOTIFY_WEBHOOK_URL", "")
+	_, err = Load()
+	if err == nil {
+		t.Fatal("Expected invalid sinks to be reported")
+	}
+	for _, want := range []string{
+		"NOTIFY_SMTP_ADDR: is required",
+		"NOTIFY_EMAIL_FROM: is required",
+		"NOTIFY_EMAIL_TO: is required",
+		"NOTIFY_SINKS: unknown sink \"sms\"",
+		"NOTIFY_SINKS: unknown severity \"loud\"",
+		"NOTIFY_WEBHOOK_URL: is required",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, err)
+		}
+	}
+}
+
+func TestLoad_UsagePolicy(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKING_DIRECTORY", t.TempDir())
+	policyFile := filepath.Join(t.TempDir(), "policy.md")
+	if err := os.WriteFile(policyFile, []byte("Conversations are stored in Postgres.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("USAGE_POLICY_FILE", policyFile)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.UsagePolicy != "Conversations are stored in Postgres." || len(cfg.UsagePolicyVersion) != 12 {
+		t.Errorf("UsagePolicy = %q, version %q", cfg.UsagePolicy, cfg.UsagePolicyVersion)
+	}
+
+	version := cfg.UsagePolicyVersion
+	if err := os.WriteFile(policyFile, []byte("Commands run on build-01."), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = Load(); err != nil || cfg.UsagePolicyVersion == version {
+		t.Errorf("Expected an edited policy to get a new version, got %q, %v", cfg.UsagePolicyVersion, err)
+	}
+
+	t.Setenv("USAGE_POLICY_VERSION", "2024-06")
+	if cfg, err = Load(); err != nil || cfg.UsagePolicyVersion != "2024-06" {
+		t.Errorf("Expected USAGE_POLICY_VERSION to be used, got %q, %v", cfg.UsagePolicyVersion, err)
+	}
+
+	t.Setenv("USAGE_POLICY_FILE", filepath.Join(t.TempDir(), "missing.md"))
+	if _, err = Load(); err == nil || !strings.Contains(err.Error(), "USAGE_POLICY_FILE") {
+		t.Errorf("Expected a missing policy file to be reported, got %v", err)
+	}
+}
+
+func TestLoad_StrictSecurityNeedsAdminListener(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKING_DIRECTORY", t.TempDir())
+	t.Setenv("STRICT_SECURITY", "true")
+	t.Setenv("ADMIN_API_TOKEN", "0123456789abcdef")
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "ADMIN_LISTEN_ADDR: is required when STRICT_SECURITY is on") {
+		t.Errorf("Expected a missing admin listener to be reported, got %v", err)
+	}
+
+	t.Setenv("ADMIN_LISTEN_ADDR", "127.0.0.1:9090")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.StrictSecurity || cfg.AdminListenAddr != "127.0.0.1:9090" {
+		t.Errorf("Unexpected security config: strict=%v admin=%q", cfg.StrictSecurity, cfg.AdminListenAddr)
+	}
+}
+
+func TestLoad_StrictSecurityNeedsSigningSecret(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKING_DIRECTORY", t.TempDir())
+	t.Setenv("STRICT_SECURITY", "true")
+	t.Setenv("SLACK_SIGNING_SECRET", "")
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "SLACK_SIGNING_SECRET: is required when STRICT_SECURITY is on") {
+		t.Errorf("Expected a missing signing secret to be reported, got %v", err)
+	}
+	if strings.Count(err.Error(), "SLACK_SIGNING_SECRET") != 1 {
+		t.Errorf("Expected the signing secret to be reported once, got %v", err)
+	}
+}
+
+func TestLoad_CloneRootOutsideWorkdirRoots(t *testing.T) {
+	setRequiredEnv(t)
+	roots := t.TempDir()
+	t.Setenv("WORKING_DIRECTORY", roots)
+	t.Setenv("WORKDIR_ROOTS", roots)
+	t.Setenv("WORKSPACE_CLONE_HOSTS", "github.com")
+
+	t.Setenv("WORKSPACE_CLONE_ROOT", t.TempDir())
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "is not inside WORKDIR_ROOTS") {
+		t.Errorf("Expected a clone root outside WORKDIR_ROOTS to be reported, got %v", err)
+	}
+
+	t.Setenv("WORKSPACE_CLONE_ROOT", filepath.Join(roots, "clones"))
+	if _, err := Load(); err != nil {
+		t.Errorf("Expected a clone root inside WORKDIR_ROOTS to load, got %v", err)
+	}
+}
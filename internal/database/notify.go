@@ -0,0 +1,79 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+const (
+	// Reconnect backoff bounds for LISTEN connections
+	listenMinReconnect = time.Second
+	listenMaxReconnect = time.Minute
+
+	// listenPingInterval is how often an idle LISTEN connection is checked
+	listenPingInterval = 90 * time.Second
+)
+
+// Notify sends a NOTIFY on a channel to every connection listening on it
+func (d *Database) Notify(channel, payload string) error {
+	if _, err := d.db.Exec(`SELECT pg_notify($1, $2)`, channel, payload); err != nil {
+		return fmt.Errorf("failed to notify %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Listen subscribes to a NOTIFY channel on a dedicated connection and calls
+// handle with each payload. The connection is re-established when it drops;
+// notifications sent in the meantime are lost, so onReconnect is called once
+// it is back. Call the returned function to stop listening.
+func (d *Database) Listen(channel string, handle func(payload string), onReconnect func()) (func(), error) {
+	listener := pq.NewListener(d.connStr, listenMinReconnect, listenMaxReconnect,
+		func(event pq.ListenerEventType, err error) {
+			switch event {
+			case pq.ListenerEventDisconnected:
+				d.logger.Warn("LISTEN connection lost", zap.String("channel", channel), zap.Error(err))
+			case pq.ListenerEventReconnected:
+				d.logger.Info("LISTEN connection re-established", zap.String("channel", channel))
+			case pq.ListenerEventConnectionAttemptFailed:
+				d.logger.Debug("LISTEN reconnect attempt failed", zap.String("channel", channel), zap.Error(err))
+			}
+		})
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(listenPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case notification := <-listener.Notify:
+				if notification == nil {
+					// pq sends nil after reconnecting
+					onReconnect()
+					continue
+				}
+				handle(notification.Extra)
+			case <-ticker.C:
+				if err := listener.Ping(); err != nil {
+					d.logger.Debug("LISTEN connection ping failed", zap.String("channel", channel), zap.Error(err))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	stop := func() {
+		close(done)
+		if err := listener.Close(); err != nil {
+			d.logger.Warn("Failed to close LISTEN connection", zap.String("channel", channel), zap.Error(err))
+		}
+	}
+	return stop, nil
+}
@@ -13,50 +13,31 @@ import (
 )
 
 type Database struct {
-	db     *sql.DB
-	config *config.DatabaseConfig
-	logger *zap.Logger
+	db      *sql.DB
+	connStr string // Kept for LISTEN connections, which bypass the pool
+	config  *config.DatabaseConfig
+	logger  *zap.Logger
 }
 
 func NewDatabase(cfg *config.DatabaseConfig, logger *zap.Logger) (*Database, error) {
-	if cfg == nil {
-		return nil, fmt.Errorf("database config cannot be nil")
-	}
-
-	// Build PostgreSQL URL with properly escaped password
-	escapedPassword := url.QueryEscape(cfg.Password)
-	connStr := fmt.Sprintf("postgresql://%s:%s@%s:%d/%s?sslmode=disable&connect_timeout=10&application_name=claude-slack-bot",
-		cfg.User, escapedPassword, cfg.Host, cfg.Port, cfg.Name)
-
-	logger.Info("Attempting database connection",
-		zap.String("host", cfg.Host),
-		zap.Int("port", cfg.Port),
-		zap.String("database", cfg.Name),
-		zap.String("user", cfg.User))
-
-	db, err := sql.Open("postgres", connStr)
+	d, err := Open(cfg, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database connection: %w", err)
+		return nil, err
 	}
 
-	// Configure connection pool with more conservative settings
-	db.SetMaxOpenConns(5)  // Reduce from cfg.MaxConnections
-	db.SetMaxIdleConns(2)  // Reduce from cfg.IdleConnections  
-	db.SetConnMaxLifetime(30 * time.Minute)  // Shorter lifetime
-
 	logger.Info("Testing database connection with ping...")
 	
 	// Test connection with retry logic
 	maxRetries := 3
 	for i := 0; i < maxRetries; i++ {
-		if err := db.Ping(); err != nil {
+		if err := d.db.Ping(); err != nil {
 			logger.Warn("Database ping failed, retrying...", 
 				zap.Error(err), 
 				zap.Int("attempt", i+1), 
 				zap.Int("max_attempts", maxRetries))
 			
 			if i == maxRetries-1 {
-				db.Close()
+				d.db.Close()
 				return nil, fmt.Errorf("failed to ping database after %d attempts: %w", maxRetries, err)
 			}
 			
@@ -72,10 +53,42 @@ func NewDatabase(cfg *config.DatabaseConfig, logger *zap.Logger) (*Database, err
 		zap.String("database", cfg.Name),
 		zap.Int("max_connections", cfg.MaxConnections))
 
+	return d, nil
+}
+
+// Open sets up the connection pool without connecting. Connections are made
+// on first use, so the database may come up later; check with Health.
+func Open(cfg *config.DatabaseConfig, logger *zap.Logger) (*Database, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("database config cannot be nil")
+	}
+
+	// Build PostgreSQL URL with properly escaped password
+	escapedPassword := url.QueryEscape(cfg.Password)
+	connStr := fmt.Sprintf("postgresql://%s:%s@%s:%d/%s?sslmode=disable&connect_timeout=10&application_name=claude-slack-bot",
+		cfg.User, escapedPassword, cfg.Host, cfg.Port, cfg.Name)
+
+	logger.Info("Attempting database connection",
+		zap.String("host", cfg.Host),
+		zap.Int("port", cfg.Port),
+		zap.String("database", cfg.Name),
+		zap.String("user", cfg.User))
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	// Configure connection pool with more conservative settings
+	db.SetMaxOpenConns(5)  // Reduce from cfg.MaxConnections
+	db.SetMaxIdleConns(2)  // Reduce from cfg.IdleConnections  
+	db.SetConnMaxLifetime(30 * time.Minute)  // Shorter lifetime
+
 	return &Database{
-		db:     db,
-		config: cfg,
-		logger: logger,
+		db:      db,
+		connStr: connStr,
+		config:  cfg,
+		logger:  logger,
 	}, nil
 }
 
@@ -0,0 +1,43 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestCipherRoundTrip(t *testing.T) {
+	c, err := NewCipher(bytes.Repeat([]byte{7}, KeySize))
+	if err != nil {
+		t.Fatalf("NewCipher() error = %v", err)
+	}
+
+	sealed, err := c.Seal("arn:aws:iam::123:role/dev")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if again, _ := c.Seal("arn:aws:iam::123:role/dev"); again == sealed {
+		t.Error("Expected a fresh nonce for every Seal")
+	}
+
+	plaintext, err := c.Open(sealed)
+	if err != nil || plaintext != "arn:aws:iam::123:role/dev" {
+		t.Errorf("Open() = %q, %v", plaintext, err)
+	}
+
+	other, _ := NewCipher(bytes.Repeat([]byte{8}, KeySize))
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("Expected Open with a different key to fail")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	if _, err := ParseKey(base64.StdEncoding.EncodeToString(make([]byte, KeySize))); err != nil {
+		t.Errorf("ParseKey() error = %v", err)
+	}
+	for _, key := range []string{"not base64!", base64.StdEncoding.EncodeToString(make([]byte, 16))} {
+		if _, err := ParseKey(key); err == nil {
+			t.Errorf("ParseKey(%q) expected an error", key)
+		}
+	}
+}
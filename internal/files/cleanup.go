@@ -16,13 +16,14 @@ type CleanupService struct {
 	stopCh     chan struct{}
 }
 
-// NewCleanupService creates a new cleanup service
-func NewCleanupService(downloader *Downloader, logger *zap.Logger) *CleanupService {
+// NewCleanupService creates a cleanup service that removes files older than
+// maxAge every interval
+func NewCleanupService(downloader *Downloader, logger *zap.Logger, interval, maxAge time.Duration) *CleanupService {
 	return &CleanupService{
 		downloader: downloader,
 		logger:     logger,
-		interval:   30 * time.Minute, // Run every 30 minutes
-		maxAge:     2 * time.Hour,    // Clean files older than 2 hours
+		interval:   interval,
+		maxAge:     maxAge,
 		stopCh:     make(chan struct{}),
 	}
 }
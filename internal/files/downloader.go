@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/slack-go/slack"
@@ -15,10 +16,16 @@ import (
 
 // Downloader handles downloading files from Slack
 type Downloader struct {
+	mu        sync.RWMutex
 	client    *slack.Client
 	logger    *zap.Logger
 	storageDir string
 	token     string
+	limits    Limits
+
+	quotaMu        sync.Mutex
+	reservedByUser map[string]int64
+	reservedTotal  int64
 }
 
 // FileInfo represents downloaded file information
@@ -31,7 +38,7 @@ type FileInfo struct {
 }
 
 // NewDownloader creates a new file downloader
-func NewDownloader(client *slack.Client, logger *zap.Logger, storageDir string, token string) (*Downloader, error) {
+func NewDownloader(client *slack.Client, logger *zap.Logger, storageDir string, token string, limits Limits) (*Downloader, error) {
 	// Create storage directory if it doesn't exist
 	if err := os.MkdirAll(storageDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create storage directory: %w", err)
@@ -42,26 +49,53 @@ func NewDownloader(client *slack.Client, logger *zap.Logger, storageDir string,
 		logger:     logger,
 		storageDir: storageDir,
 		token:      token,
+		limits:     limits,
+
+		reservedByUser: make(map[string]int64),
 	}, nil
 }
 
+// SetClient replaces the Slack client and bot token after credential rotation
+func (d *Downloader) SetClient(client *slack.Client, token string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.client = client
+	d.token = token
+}
+
+// credentials returns the current Slack client and bot token
+func (d *Downloader) credentials() (*slack.Client, string) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.client, d.token
+}
+
 // DownloadFile downloads a file from Slack and returns local file info
 func (d *Downloader) DownloadFile(fileID string, userID string) (*FileInfo, error) {
+	client, _ := d.credentials()
+
 	// Get file info from Slack API
-	file, _, _, err := d.client.GetFileInfo(fileID, 0, 0)
+	file, _, _, err := client.GetFileInfo(fileID, 0, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file info: %w", err)
 	}
 
-	// Check if it's an image file
-	if !d.isImageFile(file.Mimetype) {
-		return nil, fmt.Errorf("file is not a supported image type: %s", file.Mimetype)
+	// Check if it's an image, audio, or patch file
+	if !d.isImageFile(file.Mimetype) && !IsAudioMimeType(file.Mimetype) && !IsPatchFile(file.Name, file.Mimetype, file.Filetype) {
+		return nil, fmt.Errorf("file is not a supported image, audio, or patch type: %s", file.Mimetype)
 	}
 
-	// Check file size (limit to 50MB)
-	if file.Size > 50*1024*1024 {
-		return nil, fmt.Errorf("file too large: %d bytes (max 50MB)", file.Size)
+	// Check file size and storage quotas, holding the space while downloading
+	release, err := d.reserve(userID, int64(file.Size))
+	if err != nil {
+		d.logger.Warn("Rejected file download",
+			zap.String("fileID", fileID),
+			zap.String("userID", userID),
+			zap.Int("size", file.Size),
+			zap.Error(err))
+		return nil, err
 	}
+	defer release()
 
 	// Generate local filename
 	timestamp := time.Now().Unix()
@@ -107,6 +141,33 @@ func (d *Downloader) isImageFile(mimeType string) bool {
 	return false
 }
 
+// IsAudioMimeType reports whether the mime type is audio that can be
+// transcribed. Slack huddle recordings and clips arrive as mp4 or webm video.
+func IsAudioMimeType(mimeType string) bool {
+	if strings.HasPrefix(mimeType, "audio/") {
+		return true
+	}
+	switch mimeType {
+	case "video/mp4", "video/webm", "video/quicktime":
+		return true
+	}
+	return false
+}
+
+// IsPatchFile reports whether a file is a diff or patch to review, by its
+// name, mime type, or Slack file type
+func IsPatchFile(name, mimeType, fileType string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".diff", ".patch":
+		return true
+	}
+	switch mimeType {
+	case "text/x-diff", "text/x-patch":
+		return true
+	}
+	return fileType == "diff"
+}
+
 // getFileExtension returns the appropriate file extension
 func (d *Downloader) getFileExtension(filename, mimeType string) string {
 	// Try to get extension from filename first
@@ -124,6 +185,22 @@ func (d *Downloader) getFileExtension(filename, mimeType string) string {
 		return ".gif"
 	case "image/webp":
 		return ".webp"
+	case "audio/mpeg":
+		return ".mp3"
+	case "audio/mp4", "audio/x-m4a":
+		return ".m4a"
+	case "audio/wav", "audio/x-wav":
+		return ".wav"
+	case "audio/ogg":
+		return ".ogg"
+	case "audio/webm", "video/webm":
+		return ".webm"
+	case "video/mp4":
+		return ".mp4"
+	case "video/quicktime":
+		return ".mov"
+	case "text/x-diff", "text/x-patch":
+		return ".diff"
 	default:
 		return ".bin"
 	}
@@ -157,7 +234,8 @@ func (d *Downloader) downloadToFile(url, localPath string) error {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+d.token)
+	_, token := d.credentials()
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	// Make the request
 	resp, err := http.DefaultClient.Do(req)
@@ -177,13 +255,22 @@ func (d *Downloader) downloadToFile(url, localPath string) error {
 	}
 	defer out.Close()
 
-	// Copy data
-	_, err = io.Copy(out, resp.Body)
+	// Copy data, never writing more than the size limit even if Slack's
+	// reported size was wrong
+	body := io.Reader(resp.Body)
+	if d.limits.MaxFileSize > 0 {
+		body = io.LimitReader(resp.Body, d.limits.MaxFileSize+1)
+	}
+	written, err := io.Copy(out, body)
 	if err != nil {
 		// Clean up partial file
 		os.Remove(localPath)
 		return fmt.Errorf("failed to write file: %w", err)
 	}
+	if d.limits.MaxFileSize > 0 && written > d.limits.MaxFileSize {
+		os.Remove(localPath)
+		return &QuotaError{Scope: "file", Limit: d.limits.MaxFileSize, FileSize: written}
+	}
 
 	return nil
 }
@@ -0,0 +1,22 @@
+package files
+
+import "testing"
+
+func TestIsPatchFile(t *testing.T) {
+	tests := []struct {
+		name, mimeType, fileType string
+		want                     bool
+	}{
+		{"fix.patch", "text/plain", "text", true},
+		{"FIX.DIFF", "application/octet-stream", "binary", true},
+		{"changes", "text/x-diff", "text", true},
+		{"Untitled", "text/plain", "diff", true},
+		{"notes.txt", "text/plain", "text", false},
+		{"screenshot.png", "image/png", "png", false},
+	}
+	for _, tt := range tests {
+		if got := IsPatchFile(tt.name, tt.mimeType, tt.fileType); got != tt.want {
+			t.Errorf("IsPatchFile(%q, %q, %q) = %v, want %v", tt.name, tt.mimeType, tt.fileType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package files
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxGeneratedFiles caps how many files one run can send back to Slack
+const maxGeneratedFiles = 10
+
+// GeneratedFile is a file written during a run next to a downloaded image,
+// such as a cropped or annotated copy
+type GeneratedFile struct {
+	LocalPath string
+	Name      string    // Upload name based on the original, e.g. screenshot_cropped.png
+	Original  *FileInfo // The download it was derived from
+	MimeType  string
+	Size      int64
+}
+
+// Snapshot records the modification times of the files in the storage
+// directory, to find the ones a run writes
+func (d *Downloader) Snapshot() map[string]time.Time {
+	snapshot := make(map[string]time.Time)
+	entries, err := os.ReadDir(d.storageDir)
+	if err != nil {
+		return snapshot
+	}
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			snapshot[entry.Name()] = info.ModTime()
+		}
+	}
+	return snapshot
+}
+
+// OutputPrefix is the path prefix a run should give files derived from a
+// download, so they can be matched back to it
+func OutputPrefix(input *FileInfo) string {
+	return strings.TrimSuffix(input.LocalPath, filepath.Ext(input.LocalPath))
+}
+
+// GeneratedFiles returns the files created or changed since before whose
+// names start with one of the inputs' OutputPrefix. Files from other runs
+// sharing the directory don't match, and files over the download size limit
+// are skipped.
+func (d *Downloader) GeneratedFiles(inputs []*FileInfo, before map[string]time.Time) []*GeneratedFile {
+	after := d.Snapshot()
+
+	names := make([]string, 0, len(after))
+	for name := range after {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var generated []*GeneratedFile
+	for _, name := range names {
+		if modTime, existed := before[name]; existed && !after[name].After(modTime) {
+			continue
+		}
+		original := matchOriginal(name, inputs)
+		if original == nil {
+			continue
+		}
+
+		path := filepath.Join(d.storageDir, name)
+		info, err := os.Stat(path)
+		if err != nil || info.Size() == 0 || (d.limits.MaxFileSize > 0 && info.Size() > d.limits.MaxFileSize) {
+			continue
+		}
+
+		generated = append(generated, &GeneratedFile{
+			LocalPath: path,
+			Name:      generatedName(name, original),
+			Original:  original,
+			MimeType:  detectMimeType(path),
+			Size:      info.Size(),
+		})
+		if len(generated) == maxGeneratedFiles {
+			break
+		}
+	}
+	return generated
+}
+
+// matchOriginal returns the input a generated file name was derived from,
+// preferring the longest prefix, or nil. An input edited in place matches
+// itself.
+func matchOriginal(name string, inputs []*FileInfo) *FileInfo {
+	var best *FileInfo
+	bestLen := 0
+	for _, input := range inputs {
+		prefix := filepath.Base(OutputPrefix(input))
+		if strings.HasPrefix(name, prefix) && len(prefix) > bestLen {
+			best, bestLen = input, len(prefix)
+		}
+	}
+	return best
+}
+
+// generatedName maps "U1_1700000000_screenshot_cropped.png", derived from
+// "screenshot.png", to "screenshot_cropped.png"
+func generatedName(name string, original *FileInfo) string {
+	suffix := strings.TrimPrefix(name, filepath.Base(OutputPrefix(original)))
+	return strings.TrimSuffix(original.OriginalName, filepath.Ext(original.OriginalName)) + suffix
+}
+
+// detectMimeType guesses a file's type from its extension, then its content
+func detectMimeType(path string) string {
+	if mimeType := mime.TypeByExtension(filepath.Ext(path)); mimeType != "" {
+		return strings.SplitN(mimeType, ";", 2)[0]
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+	return strings.SplitN(http.DetectContentType(head[:n]), ";", 2)[0]
+}
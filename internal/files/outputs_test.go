@@ -0,0 +1,55 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestGeneratedFiles(t *testing.T) {
+	dir := t.TempDir()
+	d, err := NewDownloader(nil, zap.NewNop(), dir, "", Limits{MaxFileSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(name string, size int) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	screenshot := &FileInfo{LocalPath: filepath.Join(dir, "U1_1700_screenshot.png"), OriginalName: "screenshot.png"}
+	screenshot2 := &FileInfo{LocalPath: filepath.Join(dir, "U1_1700_screenshot2.png"), OriginalName: "screenshot 2.png"}
+	write("U1_1700_screenshot.png", 10)
+	write("U1_1700_screenshot2.png", 10)
+	write("U2_1600_other.png", 10)
+
+	before := d.Snapshot()
+	time.Sleep(10 * time.Millisecond)
+
+	write("U1_1700_screenshot_cropped.png", 20)
+	write("U1_1700_screenshot2.jpg", 20)
+	write("U2_1600_other_cropped.png", 20)
+	write("U1_1700_screenshot_huge.png", 2048)
+	write("U1_1700_screenshot_empty.png", 0)
+
+	generated := d.GeneratedFiles([]*FileInfo{screenshot, screenshot2}, before)
+	got := make(map[string]*GeneratedFile)
+	for _, g := range generated {
+		got[g.Name] = g
+	}
+	if len(got) != 2 {
+		t.Fatalf("GeneratedFiles() = %v, want 2 files", got)
+	}
+	if g := got["screenshot_cropped.png"]; g == nil || g.Original != screenshot || g.MimeType != "image/png" || g.Size != 20 {
+		t.Errorf("cropped copy = %+v", g)
+	}
+	if g := got["screenshot 2.jpg"]; g == nil || g.Original != screenshot2 || g.MimeType != "image/jpeg" {
+		t.Errorf("converted copy = %+v", g)
+	}
+}
@@ -0,0 +1,111 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Limits bounds how much downloaded data is kept on disk. Zero disables a limit.
+type Limits struct {
+	MaxFileSize int64 // Largest single file
+	UserQuota   int64 // Total stored per Slack user
+	GlobalQuota int64 // Total stored across all users
+}
+
+// QuotaError reports a download rejected because it would exceed a limit
+type QuotaError struct {
+	Scope    string // "file", "user", or "global"
+	Used     int64
+	Limit    int64
+	FileSize int64
+}
+
+func (e *QuotaError) Error() string {
+	if e.Scope == "file" {
+		return fmt.Sprintf("file too large: %s (max %s)", FormatBytes(e.FileSize), FormatBytes(e.Limit))
+	}
+	return fmt.Sprintf("%s storage quota exceeded: %s used of %s, file is %s",
+		e.Scope, FormatBytes(e.Used), FormatBytes(e.Limit), FormatBytes(e.FileSize))
+}
+
+// FormatBytes renders a byte count with a binary unit, e.g. "1.5MB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for value := n / unit; value >= unit; value /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// StorageUsage returns the bytes stored for a user and in total. Files are
+// attributed to users by the "<userID>_" filename prefix.
+func (d *Downloader) StorageUsage(userID string) (userBytes, totalBytes int64, err error) {
+	entries, err := os.ReadDir(d.storageDir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read storage directory: %w", err)
+	}
+
+	prefix := userID + "_"
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		totalBytes += info.Size()
+		if strings.HasPrefix(entry.Name(), prefix) {
+			userBytes += info.Size()
+		}
+	}
+
+	return userBytes, totalBytes, nil
+}
+
+// reserve checks a pending download against the limits and, if it fits,
+// holds the space until release is called so concurrent downloads can't
+// overshoot a quota together
+func (d *Downloader) reserve(userID string, size int64) (func(), error) {
+	if d.limits.MaxFileSize > 0 && size > d.limits.MaxFileSize {
+		return nil, &QuotaError{Scope: "file", Limit: d.limits.MaxFileSize, FileSize: size}
+	}
+
+	d.quotaMu.Lock()
+	defer d.quotaMu.Unlock()
+
+	if d.limits.UserQuota > 0 || d.limits.GlobalQuota > 0 {
+		userBytes, totalBytes, err := d.StorageUsage(userID)
+		if err != nil {
+			return nil, err
+		}
+		userBytes += d.reservedByUser[userID]
+		totalBytes += d.reservedTotal
+
+		if d.limits.UserQuota > 0 && userBytes+size > d.limits.UserQuota {
+			return nil, &QuotaError{Scope: "user", Used: userBytes, Limit: d.limits.UserQuota, FileSize: size}
+		}
+		if d.limits.GlobalQuota > 0 && totalBytes+size > d.limits.GlobalQuota {
+			return nil, &QuotaError{Scope: "global", Used: totalBytes, Limit: d.limits.GlobalQuota, FileSize: size}
+		}
+	}
+
+	d.reservedByUser[userID] += size
+	d.reservedTotal += size
+
+	return func() {
+		d.quotaMu.Lock()
+		defer d.quotaMu.Unlock()
+		d.reservedByUser[userID] -= size
+		if d.reservedByUser[userID] <= 0 {
+			delete(d.reservedByUser, userID)
+		}
+		d.reservedTotal -= size
+	}, nil
+}
@@ -0,0 +1,62 @@
+package files
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestReserveEnforcesQuotas(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "U1_1_a.png"), make([]byte, 600), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "U2_1_b.png"), make([]byte, 300), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewDownloader(nil, zap.NewNop(), dir, "", Limits{MaxFileSize: 500, UserQuota: 1000, GlobalQuota: 1500})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var quotaErr *QuotaError
+	if _, err := d.reserve("U1", 501); !errors.As(err, &quotaErr) || quotaErr.Scope != "file" {
+		t.Errorf("expected file limit error, got %v", err)
+	}
+	if _, err := d.reserve("U1", 450); !errors.As(err, &quotaErr) || quotaErr.Scope != "user" || quotaErr.Used != 600 {
+		t.Errorf("expected user quota error, got %v", err)
+	}
+
+	release, err := d.reserve("U2", 400)
+	if err != nil {
+		t.Fatalf("reserve() error = %v", err)
+	}
+	// 900 on disk + 400 reserved leaves 200 globally
+	if _, err := d.reserve("U3", 300); !errors.As(err, &quotaErr) || quotaErr.Scope != "global" || quotaErr.Used != 1300 {
+		t.Errorf("expected global quota error, got %v", err)
+	}
+	release()
+	if release, err := d.reserve("U3", 300); err != nil {
+		t.Errorf("reserve() after release error = %v", err)
+	} else {
+		release()
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:                    "512B",
+		1536:                   "1.5KB",
+		50 * 1024 * 1024:       "50.0MB",
+		2 * 1024 * 1024 * 1024: "2.0GB",
+	}
+	for input, want := range tests {
+		if got := FormatBytes(input); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package flags evaluates run-time feature flags, so risky features can be
+// turned on per channel or for a share of channels without a redeploy
+package flags
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+// namePattern matches flag names such as "streaming" or "docker-backend"
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+
+// ValidName reports whether name can be used as a flag name
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Store loads flags; implemented by repository.FeatureFlagRepository
+type Store interface {
+	ListFeatureFlags() ([]*repository.FeatureFlag, error)
+}
+
+// Bucket places a channel in [0, 100) for a flag. The hash is stable, so a
+// channel stays on as a rollout grows, and each flag picks different channels.
+func Bucket(name, channelID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + channelID))
+	return int(h.Sum32() % 100)
+}
+
+// Evaluate reports whether flag is on in a channel: its override if the
+// channel has one, otherwise whether the channel falls within the rollout
+func Evaluate(flag *repository.FeatureFlag, channelID string) bool {
+	if flag == nil {
+		return false
+	}
+	if enabled, ok := flag.Channels[channelID]; ok {
+		return enabled
+	}
+	return Bucket(flag.Name, channelID) < flag.RolloutPercent
+}
+
+// Service caches flags and answers lookups. Changes made by other bot
+// instances are picked up within the cache TTL.
+type Service struct {
+	store  Store
+	ttl    time.Duration
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	flags    map[string]*repository.FeatureFlag
+	loadedAt time.Time
+}
+
+// New creates a flag service that reloads from store at most once per ttl
+func New(store Store, ttl time.Duration, logger *zap.Logger) *Service {
+	return &Service{store: store, ttl: ttl, logger: logger}
+}
+
+// Enabled reports whether a flag is on in a channel. Unknown flags are off;
+// if flags can't be loaded, the last loaded values are used.
+func (s *Service) Enabled(name, channelID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshLocked()
+	return Evaluate(s.flags[name], channelID)
+}
+
+// List returns every flag, reloading them first so admins see current values
+func (s *Service) List() ([]*repository.FeatureFlag, error) {
+	flags, err := s.store.ListFeatureFlags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feature flags: %w", err)
+	}
+
+	s.mu.Lock()
+	s.setLocked(flags)
+	s.mu.Unlock()
+	return flags, nil
+}
+
+// Invalidate drops the cache so the next lookup reloads, e.g. after a change
+func (s *Service) Invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// refreshLocked reloads flags when the cache is stale
+func (s *Service) refreshLocked() {
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < s.ttl {
+		return
+	}
+
+	flags, err := s.store.ListFeatureFlags()
+	if err != nil {
+		// Retry after another TTL rather than on every lookup
+		s.loadedAt = time.Now()
+		s.logger.Warn("Failed to load feature flags; using last known values", zap.Error(err))
+		return
+	}
+	s.setLocked(flags)
+}
+
+func (s *Service) setLocked(flags []*repository.FeatureFlag) {
+	s.flags = make(map[string]*repository.FeatureFlag, len(flags))
+	for _, flag := range flags {
+		s.flags[flag.Name] = flag
+	}
+	s.loadedAt = time.Now()
+}
@@ -0,0 +1,78 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+type fakeStore struct {
+	flags []*repository.FeatureFlag
+	err   error
+	loads int
+}
+
+func (f *fakeStore) ListFeatureFlags() ([]*repository.FeatureFlag, error) {
+	f.loads++
+	return f.flags, f.err
+}
+
+func TestEvaluate(t *testing.T) {
+	flag := &repository.FeatureFlag{Name: "streaming", RolloutPercent: 0, Channels: map[string]bool{"C1": true}}
+	if !Evaluate(flag, "C1") {
+		t.Error("Expected the channel override to turn the flag on")
+	}
+	if Evaluate(flag, "C2") {
+		t.Error("Expected a 0% rollout to be off")
+	}
+
+	flag.RolloutPercent = 100
+	flag.Channels["C3"] = false
+	if !Evaluate(flag, "C2") || Evaluate(flag, "C3") {
+		t.Error("Expected a 100% rollout to be on except where overridden off")
+	}
+	if Evaluate(nil, "C1") {
+		t.Error("Expected an unknown flag to be off")
+	}
+}
+
+func TestEvaluate_RolloutIsMonotonic(t *testing.T) {
+	on := 0
+	for i := 0; i < 1000; i++ {
+		channelID := fmt.Sprintf("C%d", i)
+		small := Evaluate(&repository.FeatureFlag{Name: "docker", RolloutPercent: 20}, channelID)
+		large := Evaluate(&repository.FeatureFlag{Name: "docker", RolloutPercent: 50}, channelID)
+		if small && !large {
+			t.Fatalf("%s was on at 20%% but off at 50%%", channelID)
+		}
+		if large {
+			on++
+		}
+	}
+	if on < 400 || on > 600 {
+		t.Errorf("50%% rollout enabled %d of 1000 channels", on)
+	}
+}
+
+func TestService_CachesAndKeepsLastKnown(t *testing.T) {
+	store := &fakeStore{flags: []*repository.FeatureFlag{{Name: "compaction", RolloutPercent: 100}}}
+	s := New(store, time.Hour, zap.NewNop())
+
+	if !s.Enabled("compaction", "C1") || !s.Enabled("compaction", "C2") {
+		t.Fatal("Expected the flag to be on")
+	}
+	if store.loads != 1 {
+		t.Errorf("Loaded %d times, want 1", store.loads)
+	}
+
+	store.err = errors.New("connection refused")
+	s.Invalidate()
+	if !s.Enabled("compaction", "C1") {
+		t.Error("Expected last known values when loading fails")
+	}
+}
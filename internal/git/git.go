@@ -0,0 +1,480 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// emptyTree is git's well-known hash of an empty tree, used as the diff base
+// in repositories without commits
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// ErrNotRepository is returned when a directory is not inside a git work tree
+var ErrNotRepository = errors.New("not a git repository")
+
+// run executes git in dir and returns its standard output
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	return runInput(ctx, dir, nil, args...)
+}
+
+// runInput executes git in dir with input on its standard input and returns
+// its standard output
+func runInput(ctx context.Context, dir string, input []byte, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// Root returns the top-level directory of the work tree containing dir
+func Root(ctx context.Context, dir string) (string, error) {
+	out, err := run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", ErrNotRepository
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Snapshot records the current state of tracked files in the work tree
+// containing dir without touching it, and returns a commit to diff against
+// later. Uncommitted changes are captured so they don't show up as edits.
+func Snapshot(ctx context.Context, dir string) (string, error) {
+	if _, err := Root(ctx, dir); err != nil {
+		return "", err
+	}
+
+	head, err := run(ctx, dir, "rev-parse", "--verify", "-q", "HEAD")
+	if err != nil {
+		// No commits yet, so there is nothing to stash against
+		return emptyTree, nil
+	}
+
+	out, err := run(ctx, dir, "stash", "create")
+	if err != nil {
+		return "", err
+	}
+	if ref := strings.TrimSpace(out); ref != "" {
+		return ref, nil
+	}
+
+	// Clean work tree: HEAD already matches it
+	return strings.TrimSpace(head), nil
+}
+
+// DiffFiles returns a unified diff of files against base, a ref returned by
+// Snapshot. Files outside the work tree are skipped; files git doesn't track
+// are diffed as new.
+func DiffFiles(ctx context.Context, dir, base string, files []string) (string, error) {
+	root, err := Root(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+
+	var diff strings.Builder
+	for _, file := range files {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		if _, err := run(ctx, root, "ls-files", "--error-unmatch", "--", rel); err == nil {
+			out, err := run(ctx, root, "diff", "--no-color", "--no-ext-diff", base, "--", rel)
+			if err != nil {
+				return "", err
+			}
+			diff.WriteString(out)
+			continue
+		}
+
+		// Untracked: compare against nothing. --no-index exits 1 when the
+		// files differ, so only a missing diff header means failure.
+		out, err := run(ctx, root, "diff", "--no-color", "--no-ext-diff", "--no-index", "--", "/dev/null", rel)
+		if err != nil && !strings.HasPrefix(out, "diff --git") {
+			continue
+		}
+		diff.WriteString(out)
+	}
+
+	return diff.String(), nil
+}
+
+// Remote is a configured remote and its fetch URL
+type Remote struct {
+	Name string
+	URL  string
+}
+
+// Remotes lists the remotes of the repository containing dir
+func Remotes(ctx context.Context, dir string) ([]Remote, error) {
+	if _, err := Root(ctx, dir); err != nil {
+		return nil, err
+	}
+
+	out, err := run(ctx, dir, "remote", "-v")
+	if err != nil {
+		return nil, err
+	}
+
+	var remotes []Remote
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[2] == "(fetch)" {
+			remotes = append(remotes, Remote{Name: fields[0], URL: fields[1]})
+		}
+	}
+	return remotes, nil
+}
+
+// Branch returns the checked out branch, or "" for a detached HEAD
+func Branch(ctx context.Context, dir string) (string, error) {
+	out, err := run(ctx, dir, "symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		if _, rootErr := Root(ctx, dir); rootErr != nil {
+			return "", rootErr
+		}
+		return "", nil
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Head returns the abbreviated commit checked out in dir, or "" when the
+// repository has no commits yet
+func Head(ctx context.Context, dir string) (string, error) {
+	out, err := run(ctx, dir, "rev-parse", "--short", "-q", "--verify", "HEAD")
+	if err != nil {
+		if _, rootErr := Root(ctx, dir); rootErr != nil {
+			return "", rootErr
+		}
+		return "", nil
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Clone clones url into dir, which must not exist yet
+func Clone(ctx context.Context, url, dir string) error {
+	_, err := run(ctx, filepath.Dir(dir), "clone", "--quiet", "--", url, dir)
+	return err
+}
+
+// CloneOptions configures CloneWithProgress
+type CloneOptions struct {
+	Env      []string          // Added to git's environment, e.g. for credentials
+	Progress func(line string) // Called with each progress line git reports
+}
+
+// cloneErrorLines is how many of git's last output lines a failed clone
+// reports
+const cloneErrorLines = 5
+
+// CloneWithProgress clones url into dir, which must not exist yet, passing
+// git's progress reports to opts.Progress as it goes
+func CloneWithProgress(ctx context.Context, url, dir string, opts CloneOptions) error {
+	cmd := exec.CommandContext(ctx, "git", "-C", filepath.Dir(dir), "clone", "--progress", "--", url, dir)
+	cmd.Env = append(os.Environ(), opts.Env...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("git clone failed: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git clone failed: %w", err)
+	}
+
+	// Progress is redrawn with carriage returns, so split on those too
+	var tail []string
+	scanner := bufio.NewScanner(stderr)
+	scanner.Split(scanProgressLines)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if tail = append(tail, line); len(tail) > cloneErrorLines {
+			tail = tail[1:]
+		}
+		if opts.Progress != nil {
+			opts.Progress(line)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git clone failed: %w: %s", err, strings.Join(tail, "\n"))
+	}
+	return nil
+}
+
+// scanProgressLines is a bufio.SplitFunc that ends lines at \r or \n
+func scanProgressLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// defaultIdentity is used for commits the bot makes in repositories without
+// a configured user.name and user.email
+var defaultIdentity = []string{"-c", "user.name=claude-on-slack", "-c", "user.email=claude-on-slack@localhost"}
+
+// commitArgs prefixes args with the default identity when the repository
+// has none, so commits don't fail on unconfigured hosts
+func commitArgs(ctx context.Context, dir string, args ...string) []string {
+	if _, err := run(ctx, dir, "config", "user.email"); err != nil {
+		return append(append([]string{}, defaultIdentity...), args...)
+	}
+	return args
+}
+
+// Worktree is a work tree of a repository and its checked out branch
+type Worktree struct {
+	Path   string
+	Branch string // "" for a detached HEAD
+}
+
+// Worktrees lists the work trees of the repository containing dir. The main
+// work tree comes first.
+func Worktrees(ctx context.Context, dir string) ([]Worktree, error) {
+	if _, err := Root(ctx, dir); err != nil {
+		return nil, err
+	}
+
+	out, err := run(ctx, dir, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	var worktrees []Worktree
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			worktrees = append(worktrees, Worktree{Path: strings.TrimPrefix(line, "worktree ")})
+		case strings.HasPrefix(line, "branch ") && len(worktrees) > 0:
+			worktrees[len(worktrees)-1].Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		}
+	}
+	return worktrees, nil
+}
+
+// AddWorktree checks out a new branch, started from the current HEAD of the
+// repository containing repoDir, in a new work tree at dir
+func AddWorktree(ctx context.Context, repoDir, dir, branch string) error {
+	_, err := run(ctx, repoDir, "worktree", "add", "--quiet", "-b", branch, "--", dir, "HEAD")
+	return err
+}
+
+// AddDetachedWorktree checks out the current HEAD of the repository
+// containing repoDir, without a branch, in a new work tree at dir
+func AddDetachedWorktree(ctx context.Context, repoDir, dir string) error {
+	_, err := run(ctx, repoDir, "worktree", "add", "--quiet", "--detach", "--", dir, "HEAD")
+	return err
+}
+
+// RemoveWorktree deletes the work tree at dir, including uncommitted changes
+func RemoveWorktree(ctx context.Context, repoDir, dir string) error {
+	_, err := run(ctx, repoDir, "worktree", "remove", "--force", "--", dir)
+	return err
+}
+
+// PruneWorktrees drops git's records of work trees whose directories are
+// gone, so their branches can be deleted
+func PruneWorktrees(ctx context.Context, repoDir string) error {
+	_, err := run(ctx, repoDir, "worktree", "prune")
+	return err
+}
+
+// Branches lists the local branches whose names start with prefix
+func Branches(ctx context.Context, dir, prefix string) ([]string, error) {
+	out, err := run(ctx, dir, "for-each-ref", "--format=%(refname:short)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" && strings.HasPrefix(line, prefix) {
+			branches = append(branches, line)
+		}
+	}
+	return branches, nil
+}
+
+// DeleteBranch deletes a local branch that is merged into HEAD; git refuses
+// to delete one with commits that would be lost
+func DeleteBranch(ctx context.Context, repoDir, branch string) error {
+	_, err := run(ctx, repoDir, "branch", "-d", "--", branch)
+	return err
+}
+
+// CommitAll stages every change in the work tree containing dir and commits
+// it. It reports whether there was anything to commit.
+func CommitAll(ctx context.Context, dir, message string) (bool, error) {
+	if _, err := run(ctx, dir, "add", "--all"); err != nil {
+		return false, err
+	}
+	if _, err := run(ctx, dir, "diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	}
+	if _, err := run(ctx, dir, commitArgs(ctx, dir, "commit", "--quiet", "--no-verify", "-m", message)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CommitPaths stages and commits only the given paths, relative to the top
+// of the work tree containing dir, leaving other changes alone. It reports
+// whether there was anything to commit.
+func CommitPaths(ctx context.Context, dir, message string, paths []string) (bool, error) {
+	if len(paths) == 0 {
+		return false, nil
+	}
+	pathspec := append([]string{"--"}, paths...)
+	if _, err := run(ctx, dir, append([]string{"add", "--all"}, pathspec...)...); err != nil {
+		return false, err
+	}
+	if _, err := run(ctx, dir, append([]string{"diff", "--cached", "--quiet"}, pathspec...)...); err == nil {
+		return false, nil
+	}
+	args := append([]string{"commit", "--quiet", "--no-verify", "-m", message}, pathspec...)
+	if _, err := run(ctx, dir, commitArgs(ctx, dir, args...)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DiscardPaths drops uncommitted changes to the given paths, relative to
+// the top of the work tree containing dir: tracked files go back to HEAD and
+// untracked ones are deleted
+func DiscardPaths(ctx context.Context, dir string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	pathspec := append([]string{"--"}, paths...)
+
+	out, err := run(ctx, dir, append([]string{"ls-files", "-z"}, pathspec...)...)
+	if err != nil {
+		return err
+	}
+	var tracked []string
+	for _, path := range strings.Split(out, "\x00") {
+		if path != "" {
+			tracked = append(tracked, path)
+		}
+	}
+	if len(tracked) > 0 {
+		args := append([]string{"restore", "--source=HEAD", "--staged", "--worktree", "--"}, tracked...)
+		if _, err := run(ctx, dir, args...); err != nil {
+			return err
+		}
+	}
+
+	_, err = run(ctx, dir, append([]string{"clean", "--force", "--quiet"}, pathspec...)...)
+	return err
+}
+
+// CommitsAhead counts the commits on branch that HEAD of the work tree
+// containing dir doesn't have
+func CommitsAhead(ctx context.Context, dir, branch string) (int, error) {
+	out, err := run(ctx, dir, "rev-list", "--count", "HEAD.."+branch)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if _, err := fmt.Sscanf(strings.TrimSpace(out), "%d", &count); err != nil {
+		return 0, fmt.Errorf("unexpected rev-list output %q", out)
+	}
+	return count, nil
+}
+
+// ChangedFiles lists the paths with uncommitted changes, untracked files
+// included, in the work tree containing dir
+func ChangedFiles(ctx context.Context, dir string) ([]string, error) {
+	out, err := run(ctx, dir, "status", "--porcelain", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) > 3 {
+			files = append(files, line[3:])
+		}
+	}
+	return files, nil
+}
+
+// Merge merges branch into the branch checked out in the work tree
+// containing dir with a merge commit. A merge that fails, for example on a
+// conflict, is aborted so the work tree is left as it was.
+func Merge(ctx context.Context, dir, branch string) error {
+	if _, err := run(ctx, dir, commitArgs(ctx, dir, "merge", "--no-ff", "--no-edit", branch)...); err != nil {
+		run(ctx, dir, "merge", "--abort")
+		return err
+	}
+	return nil
+}
+
+// PatchFile is a file a patch changes and its line counts
+type PatchFile struct {
+	Path    string
+	Added   int // -1 for binary files
+	Deleted int // -1 for binary files
+}
+
+// PatchFiles lists the files a unified diff or git patch changes, without
+// applying it. It fails if git can't read the patch.
+func PatchFiles(ctx context.Context, dir string, patch []byte) ([]PatchFile, error) {
+	out, err := runInput(ctx, dir, patch, "apply", "--numstat", "-")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []PatchFile
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		file := PatchFile{Path: fields[2], Added: -1, Deleted: -1}
+		if fields[0] != "-" {
+			fmt.Sscan(fields[0], &file.Added)
+			fmt.Sscan(fields[1], &file.Deleted)
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("patch changes no files")
+	}
+	return files, nil
+}
+
+// ApplyPatch applies a patch to the work tree containing dir. Nothing is
+// changed unless every hunk applies; with check set, nothing is changed at
+// all and only whether it would apply is reported.
+func ApplyPatch(ctx context.Context, dir string, patch []byte, check bool) error {
+	args := []string{"apply", "--whitespace=nowarn"}
+	if check {
+		args = append(args, "--check")
+	}
+	_, err := runInput(ctx, dir, patch, append(args, "-")...)
+	return err
+}
@@ -0,0 +1,400 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gitInit(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		if _, err := run(context.Background(), dir, args...); err != nil {
+			t.Fatalf("Failed to set up repository: %v", err)
+		}
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestSnapshotAndDiffFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := gitInit(t)
+
+	writeFile(t, filepath.Join(dir, "config.yaml"), "port: 8080\n")
+	writeFile(t, filepath.Join(dir, "other.txt"), "untouched\n")
+	if _, err := run(ctx, dir, "add", "."); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(ctx, dir, "commit", "-qm", "initial"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A pre-existing uncommitted change must not appear in the diff
+	writeFile(t, filepath.Join(dir, "config.yaml"), "port: 8080\nhost: localhost\n")
+
+	base, err := Snapshot(ctx, dir)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "config.yaml"), "port: 9090\nhost: localhost\n")
+	writeFile(t, filepath.Join(dir, "new.txt"), "created\n")
+
+	diff, err := DiffFiles(ctx, dir, base, []string{
+		filepath.Join(dir, "config.yaml"),
+		"new.txt",
+		"/outside/repo.txt",
+	})
+	if err != nil {
+		t.Fatalf("DiffFiles failed: %v", err)
+	}
+
+	for _, want := range []string{"-port: 8080", "+port: 9090", "+created"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("Expected diff to contain %q, got:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "+host: localhost") {
+		t.Errorf("Diff includes a change made before the snapshot:\n%s", diff)
+	}
+	if strings.Contains(diff, "other.txt") {
+		t.Errorf("Diff includes a file that wasn't requested:\n%s", diff)
+	}
+}
+
+func TestSnapshot_NotRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	if _, err := Snapshot(context.Background(), t.TempDir()); err != ErrNotRepository {
+		t.Errorf("Expected ErrNotRepository, got %v", err)
+	}
+}
+
+func TestSnapshot_NoCommits(t *testing.T) {
+	ctx := context.Background()
+	dir := gitInit(t)
+
+	base, err := Snapshot(ctx, dir)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if base != emptyTree {
+		t.Errorf("Expected empty tree base, got %s", base)
+	}
+
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n")
+	diff, err := DiffFiles(ctx, dir, base, []string{"main.go"})
+	if err != nil {
+		t.Fatalf("DiffFiles failed: %v", err)
+	}
+	if !strings.Contains(diff, "+package main") {
+		t.Errorf("Expected new file in diff, got:\n%s", diff)
+	}
+}
+
+func TestRemotesAndBranch(t *testing.T) {
+	ctx := context.Background()
+	dir := gitInit(t)
+
+	if _, err := run(ctx, dir, "checkout", "-q", "-b", "main"); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+	if _, err := run(ctx, dir, "remote", "add", "origin", "git@example.com:team/app.git"); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+
+	remotes, err := Remotes(ctx, dir)
+	if err != nil {
+		t.Fatalf("Remotes failed: %v", err)
+	}
+	if len(remotes) != 1 || remotes[0] != (Remote{Name: "origin", URL: "git@example.com:team/app.git"}) {
+		t.Errorf("Unexpected remotes: %v", remotes)
+	}
+
+	branch, err := Branch(ctx, dir)
+	if err != nil || branch != "main" {
+		t.Errorf("Branch() = %q, %v; want main", branch, err)
+	}
+
+	if _, err := Remotes(ctx, t.TempDir()); err != ErrNotRepository {
+		t.Errorf("Expected ErrNotRepository, got %v", err)
+	}
+}
+
+func TestHead(t *testing.T) {
+	ctx := context.Background()
+	dir := gitInit(t)
+
+	if head, err := Head(ctx, dir); err != nil || head != "" {
+		t.Errorf("Head() without commits = %q, %v; want empty", head, err)
+	}
+
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n")
+	if _, err := CommitAll(ctx, dir, "Initial commit"); err != nil {
+		t.Fatalf("CommitAll failed: %v", err)
+	}
+	full, _ := run(ctx, dir, "rev-parse", "HEAD")
+	head, err := Head(ctx, dir)
+	if err != nil || head == "" || !strings.HasPrefix(strings.TrimSpace(full), head) {
+		t.Errorf("Head() = %q, %v; want a prefix of %s", head, err, full)
+	}
+
+	if _, err := Head(ctx, t.TempDir()); err != ErrNotRepository {
+		t.Errorf("Expected ErrNotRepository, got %v", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	ctx := context.Background()
+	src := gitInit(t)
+	writeFile(t, filepath.Join(src, "README.md"), "hello\n")
+	if _, err := run(ctx, src, "add", "README.md"); err != nil {
+		t.Fatalf("Failed to stage: %v", err)
+	}
+	if _, err := run(ctx, src, "commit", "-q", "-m", "init"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "clone")
+	if err := Clone(ctx, src, dst); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "README.md")); err != nil {
+		t.Errorf("Expected README.md in clone: %v", err)
+	}
+
+	if err := Clone(ctx, src, dst); err == nil {
+		t.Error("Expected cloning into an existing directory to fail")
+	}
+}
+
+func TestCloneWithProgress(t *testing.T) {
+	ctx := context.Background()
+	src := gitInit(t)
+	writeFile(t, filepath.Join(src, "README.md"), "hello\n")
+	if _, err := run(ctx, src, "add", "README.md"); err != nil {
+		t.Fatalf("Failed to stage: %v", err)
+	}
+	if _, err := run(ctx, src, "commit", "-q", "-m", "init"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	var progress []string
+	dst := filepath.Join(t.TempDir(), "clone")
+	opts := CloneOptions{Progress: func(line string) { progress = append(progress, line) }}
+	if err := CloneWithProgress(ctx, src, dst, opts); err != nil {
+		t.Fatalf("CloneWithProgress failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "README.md")); err != nil {
+		t.Errorf("Expected README.md in clone: %v", err)
+	}
+	if len(progress) == 0 || !strings.HasPrefix(progress[0], "Cloning into") {
+		t.Errorf("Expected progress lines from git, got %q", progress)
+	}
+
+	err := CloneWithProgress(ctx, filepath.Join(t.TempDir(), "missing"), filepath.Join(t.TempDir(), "clone"), CloneOptions{})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected the failure to include git's explanation, got %v", err)
+	}
+}
+
+func TestScanProgressLines(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("Receiving objects:  50%\rReceiving objects: 100%\ndone"))
+	scanner.Split(scanProgressLines)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	want := []string{"Receiving objects:  50%", "Receiving objects: 100%", "done"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestWorktreeCommitAndMerge(t *testing.T) {
+	ctx := context.Background()
+	repo := gitInit(t)
+	writeFile(t, filepath.Join(repo, "app.txt"), "v1\n")
+	if _, err := CommitAll(ctx, repo, "init"); err != nil {
+		t.Fatalf("CommitAll failed: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "wt")
+	if err := AddWorktree(ctx, repo, dir, "feature"); err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	worktrees, err := Worktrees(ctx, dir)
+	if err != nil || len(worktrees) != 2 || worktrees[1].Branch != "feature" {
+		t.Fatalf("Worktrees() = %v, %v", worktrees, err)
+	}
+
+	if committed, err := CommitAll(ctx, dir, "nothing"); err != nil || committed {
+		t.Errorf("CommitAll on a clean tree = %v, %v; want false", committed, err)
+	}
+	writeFile(t, filepath.Join(dir, "app.txt"), "v2\n")
+	writeFile(t, filepath.Join(dir, "new.txt"), "new\n")
+	if changed, err := ChangedFiles(ctx, dir); err != nil || len(changed) != 2 {
+		t.Errorf("ChangedFiles() = %v, %v; want 2 files", changed, err)
+	}
+	if committed, err := CommitAll(ctx, dir, "update"); err != nil || !committed {
+		t.Fatalf("CommitAll = %v, %v; want true", committed, err)
+	}
+	if ahead, err := CommitsAhead(ctx, repo, "feature"); err != nil || ahead != 1 {
+		t.Errorf("CommitsAhead() = %d, %v; want 1", ahead, err)
+	}
+
+	if err := Merge(ctx, repo, "feature"); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repo, "app.txt")); string(content) != "v2\n" {
+		t.Errorf("Expected the merge to update app.txt, got %q", content)
+	}
+
+	if err := RemoveWorktree(ctx, repo, dir); err != nil {
+		t.Fatalf("RemoveWorktree failed: %v", err)
+	}
+	if err := DeleteBranch(ctx, repo, "feature"); err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+	if worktrees, _ := Worktrees(ctx, repo); len(worktrees) != 1 {
+		t.Errorf("Expected only the main work tree left, got %v", worktrees)
+	}
+}
+
+func TestMerge_ConflictIsAborted(t *testing.T) {
+	ctx := context.Background()
+	repo := gitInit(t)
+	writeFile(t, filepath.Join(repo, "app.txt"), "v1\n")
+	CommitAll(ctx, repo, "init")
+
+	dir := filepath.Join(t.TempDir(), "wt")
+	if err := AddWorktree(ctx, repo, dir, "feature"); err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "app.txt"), "feature\n")
+	CommitAll(ctx, dir, "feature change")
+	writeFile(t, filepath.Join(repo, "app.txt"), "main\n")
+	CommitAll(ctx, repo, "main change")
+
+	if err := Merge(ctx, repo, "feature"); err == nil {
+		t.Fatal("Expected a conflicting merge to fail")
+	}
+	if content, _ := os.ReadFile(filepath.Join(repo, "app.txt")); string(content) != "main\n" {
+		t.Errorf("Expected the aborted merge to leave app.txt alone, got %q", content)
+	}
+	if changed, _ := ChangedFiles(ctx, repo); len(changed) != 0 {
+		t.Errorf("Expected a clean work tree after the abort, got %v", changed)
+	}
+}
+
+func TestCommitAndDiscardPaths(t *testing.T) {
+	ctx := context.Background()
+	dir := gitInit(t)
+
+	writeFile(t, filepath.Join(dir, "a.txt"), "a\n")
+	writeFile(t, filepath.Join(dir, "b.txt"), "b\n")
+	if _, err := CommitAll(ctx, dir, "initial"); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(dir, "a.txt"), "a edited\n")
+	writeFile(t, filepath.Join(dir, "b.txt"), "b edited\n")
+	writeFile(t, filepath.Join(dir, "new.txt"), "new\n")
+
+	committed, err := CommitPaths(ctx, dir, "Commit a and new", []string{"a.txt", "new.txt"})
+	if err != nil || !committed {
+		t.Fatalf("CommitPaths() = %v, %v", committed, err)
+	}
+	changed, err := ChangedFiles(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != "b.txt" {
+		t.Errorf("Expected only b.txt left uncommitted, got %v", changed)
+	}
+	if committed, err := CommitPaths(ctx, dir, "Nothing", []string{"a.txt"}); err != nil || committed {
+		t.Errorf("Expected nothing to commit for a clean path, got %v, %v", committed, err)
+	}
+
+	writeFile(t, filepath.Join(dir, "added.txt"), "staged\n")
+	writeFile(t, filepath.Join(dir, "scratch.txt"), "untracked\n")
+	if _, err := run(ctx, dir, "add", "added.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := DiscardPaths(ctx, dir, []string{"b.txt", "added.txt", "scratch.txt"}); err != nil {
+		t.Fatalf("DiscardPaths() error = %v", err)
+	}
+	if changed, _ := ChangedFiles(ctx, dir); len(changed) != 0 {
+		t.Errorf("Expected a clean work tree after discarding, got %v", changed)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "b.txt")); string(content) != "b\n" {
+		t.Errorf("Expected b.txt restored, got %q", content)
+	}
+}
+
+func TestPatchFilesAndApplyPatch(t *testing.T) {
+	ctx := context.Background()
+	repo := gitInit(t)
+	writeFile(t, filepath.Join(repo, "app.txt"), "one\ntwo\n")
+	if _, err := CommitAll(ctx, repo, "init"); err != nil {
+		t.Fatalf("CommitAll failed: %v", err)
+	}
+	patch := []byte("--- a/app.txt\n+++ b/app.txt\n@@ -1,2 +1,2 @@\n one\n-two\n+2\n")
+
+	files, err := PatchFiles(ctx, repo, patch)
+	if err != nil || len(files) != 1 || files[0] != (PatchFile{Path: "app.txt", Added: 1, Deleted: 1}) {
+		t.Fatalf("PatchFiles() = %+v, %v", files, err)
+	}
+	if _, err := PatchFiles(ctx, repo, []byte("not a patch")); err == nil {
+		t.Error("Expected PatchFiles to fail on text that isn't a patch")
+	}
+
+	// Applied in a detached work tree, the checkout is left alone
+	dir := filepath.Join(t.TempDir(), "scratch")
+	if err := AddDetachedWorktree(ctx, repo, dir); err != nil {
+		t.Fatalf("AddDetachedWorktree failed: %v", err)
+	}
+	if err := ApplyPatch(ctx, dir, patch, false); err != nil {
+		t.Fatalf("ApplyPatch failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "app.txt")); string(content) != "one\n2\n" {
+		t.Errorf("Expected the patch applied in the scratch tree, got %q", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repo, "app.txt")); string(content) != "one\ntwo\n" {
+		t.Errorf("Expected the checkout untouched, got %q", content)
+	}
+	if branch, _ := Branch(ctx, dir); branch != "" {
+		t.Errorf("Expected a detached HEAD, got branch %q", branch)
+	}
+
+	if err := ApplyPatch(ctx, repo, patch, true); err != nil {
+		t.Errorf("Expected the patch to apply to the checkout, got %v", err)
+	}
+	if err := ApplyPatch(ctx, dir, patch, true); err == nil {
+		t.Error("Expected an already applied patch not to apply again")
+	}
+	if err := RemoveWorktree(ctx, repo, dir); err != nil {
+		t.Fatalf("RemoveWorktree failed: %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+// Package httpauth authenticates requests to the bot's HTTP endpoints. Each
+// group of endpoints has a Policy: an optional allow-list of addresses every
+// request must come from, and providers (static bearer tokens, JWTs, or an
+// identity header set by a trusted proxy), any one of which can let a
+// request in. New handlers pick a group rather than checking credentials
+// themselves.
+package httpauth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+var (
+	// ErrNoCredentials means a request carries nothing a provider checks
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrAddressNotAllowed means a request came from outside the allow-list
+	ErrAddressNotAllowed = errors.New("address not allowed")
+)
+
+// Identity is who a request was authenticated as
+type Identity struct {
+	Provider string // Name of the provider that let the request in
+	Subject  string // Who the provider says made it, if it knows
+}
+
+// Provider authenticates requests one way
+type Provider interface {
+	Name() string
+	// Authenticate returns who made the request, ErrNoCredentials if it
+	// carries nothing for this provider, or why its credentials were refused
+	Authenticate(r *http.Request) (*Identity, error)
+}
+
+// Policy decides which requests an endpoint group serves
+type Policy struct {
+	AllowedNetworks []netip.Prefix // Client addresses allowed in; empty allows any
+	TrustedProxies  []netip.Prefix // Peers whose X-Forwarded-For is believed
+	Providers       []Provider     // Any one of them can authenticate a request
+	// Optional lets requests in without credentials when there are no
+	// providers; otherwise a group without providers refuses everything
+	Optional bool
+}
+
+// Guarded reports whether requests need credentials
+func (p *Policy) Guarded() bool {
+	return len(p.Providers) > 0
+}
+
+// Authenticate returns who made the request, or why it was refused. A
+// request from outside the allow-list is refused with ErrAddressNotAllowed
+// whatever its credentials.
+func (p *Policy) Authenticate(r *http.Request) (*Identity, error) {
+	if len(p.AllowedNetworks) > 0 {
+		ip := ClientIP(r, p.TrustedProxies)
+		if !contains(p.AllowedNetworks, ip) {
+			return nil, fmt.Errorf("%w: %s", ErrAddressNotAllowed, ip)
+		}
+	}
+	if len(p.Providers) == 0 {
+		if p.Optional {
+			return &Identity{}, nil
+		}
+		return nil, ErrNoCredentials
+	}
+
+	refused := ErrNoCredentials
+	for _, provider := range p.Providers {
+		identity, err := provider.Authenticate(r)
+		if err == nil {
+			return identity, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) && errors.Is(refused, ErrNoCredentials) {
+			refused = fmt.Errorf("%s: %w", provider.Name(), err)
+		}
+	}
+	return nil, refused
+}
+
+// ParseNetworks parses a list of CIDR ranges and single addresses
+func ParseNetworks(values []string) ([]netip.Prefix, error) {
+	var networks []netip.Prefix
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		if strings.Contains(value, "/") {
+			network, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, err
+			}
+			networks = append(networks, network.Masked())
+			continue
+		}
+		ip, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, err
+		}
+		ip = ip.Unmap()
+		networks = append(networks, netip.PrefixFrom(ip, ip.BitLen()))
+	}
+	return networks, nil
+}
+
+// peerIP returns the address of whoever opened the connection, or the zero
+// Addr if RemoteAddr isn't one
+func peerIP(r *http.Request) netip.Addr {
+	if addrPort, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+		return addrPort.Addr().Unmap()
+	}
+	if ip, err := netip.ParseAddr(r.RemoteAddr); err == nil {
+		return ip.Unmap()
+	}
+	return netip.Addr{}
+}
+
+// ClientIP returns the address a request came from. Behind trusted proxies
+// it's the nearest X-Forwarded-For hop that isn't one of them; otherwise
+// it's the peer, since anyone can send X-Forwarded-For.
+func ClientIP(r *http.Request, trusted []netip.Prefix) netip.Addr {
+	ip := peerIP(r)
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0 && contains(trusted, ip); i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		ip = hop.Unmap()
+	}
+	return ip
+}
+
+// contains reports whether ip is in any of networks
+func contains(networks []netip.Prefix, ip netip.Addr) bool {
+	if !ip.IsValid() {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// BearerToken returns the request's bearer token
+func BearerToken(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// StaticTokens lets in requests with one of a fixed set of bearer tokens
+type StaticTokens struct {
+	tokens [][]byte
+}
+
+// NewStaticTokens accepts each of tokens; empty ones are skipped
+func NewStaticTokens(tokens ...string) *StaticTokens {
+	p := &StaticTokens{}
+	for _, token := range tokens {
+		if token != "" {
+			p.tokens = append(p.tokens, []byte(token))
+		}
+	}
+	return p
+}
+
+// Name implements Provider
+func (p *StaticTokens) Name() string {
+	return "token"
+}
+
+// Authenticate compares the bearer token with every accepted token in
+// constant time
+func (p *StaticTokens) Authenticate(r *http.Request) (*Identity, error) {
+	token, ok := BearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	matched := 0
+	for _, accepted := range p.tokens {
+		matched |= subtle.ConstantTimeCompare([]byte(token), accepted)
+	}
+	if matched != 1 {
+		return nil, errors.New("invalid bearer token")
+	}
+	return &Identity{Provider: p.Name()}, nil
+}
+
+// TrustedHeader lets in requests carrying an identity header, such as
+// X-Forwarded-Email from an OIDC proxy, but only straight from one of the
+// proxies; the header is refused from anyone else
+type TrustedHeader struct {
+	Header  string
+	Proxies []netip.Prefix
+}
+
+// Name implements Provider
+func (p *TrustedHeader) Name() string {
+	return "header"
+}
+
+// Authenticate returns the header's value as the subject
+func (p *TrustedHeader) Authenticate(r *http.Request) (*Identity, error) {
+	subject := strings.TrimSpace(r.Header.Get(p.Header))
+	if subject == "" {
+		return nil, ErrNoCredentials
+	}
+	if peer := peerIP(r); !contains(p.Proxies, peer) {
+		return nil, fmt.Errorf("%s sent by %s, which isn't a trusted proxy", p.Header, peer)
+	}
+	return &Identity{Provider: p.Name(), Subject: subject}, nil
+}
@@ -0,0 +1,134 @@
+package httpauth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+const testToken = "static-token-0123456789"
+
+func request(remoteAddr string, headers map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	r.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	return r
+}
+
+func mustParseNetworks(t *testing.T, values ...string) []netip.Prefix {
+	t.Helper()
+	networks, err := ParseNetworks(values)
+	if err != nil {
+		t.Fatalf("ParseNetworks(%v): %v", values, err)
+	}
+	return networks
+}
+
+func TestParseNetworks(t *testing.T) {
+	networks := mustParseNetworks(t, "10.1.2.3/8", " 192.168.0.7 ", "", "::1")
+	want := []string{"10.0.0.0/8", "192.168.0.7/32", "::1/128"}
+	if len(networks) != len(want) {
+		t.Fatalf("Expected %d networks, got %v", len(want), networks)
+	}
+	for i, network := range networks {
+		if network.String() != want[i] {
+			t.Errorf("networks[%d] = %s, want %s", i, network, want[i])
+		}
+	}
+	if _, err := ParseNetworks([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("Expected an invalid prefix to be refused")
+	}
+	if _, err := ParseNetworks([]string{"intranet"}); err == nil {
+		t.Error("Expected a hostname to be refused")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := mustParseNetworks(t, "10.0.0.0/8")
+	forwarded := map[string]string{"X-Forwarded-For": "203.0.113.9, 198.51.100.4, 10.0.0.2"}
+
+	if got := ClientIP(request("198.51.100.1:4000", forwarded), proxies); got.String() != "198.51.100.1" {
+		t.Errorf("Expected X-Forwarded-For ignored from an untrusted peer, got %s", got)
+	}
+	if got := ClientIP(request("10.0.0.1:4000", forwarded), proxies); got.String() != "198.51.100.4" {
+		t.Errorf("Expected the nearest untrusted hop, got %s", got)
+	}
+	if got := ClientIP(request("[::ffff:10.0.0.1]:4000", nil), proxies); got.String() != "10.0.0.1" {
+		t.Errorf("Expected a mapped IPv4 peer unmapped, got %s", got)
+	}
+}
+
+func TestPolicy_AllowList(t *testing.T) {
+	p := &Policy{AllowedNetworks: mustParseNetworks(t, "192.168.0.0/16"), Optional: true}
+
+	if _, err := p.Authenticate(request("192.168.4.2:5000", nil)); err != nil {
+		t.Errorf("Expected an allowed address in, got %v", err)
+	}
+	_, err := p.Authenticate(request("203.0.113.9:5000", nil))
+	if !errors.Is(err, ErrAddressNotAllowed) {
+		t.Errorf("Expected ErrAddressNotAllowed, got %v", err)
+	}
+
+	p.Providers = []Provider{NewStaticTokens(testToken)}
+	_, err = p.Authenticate(request("203.0.113.9:5000", map[string]string{"Authorization": "Bearer " + testToken}))
+	if !errors.Is(err, ErrAddressNotAllowed) {
+		t.Errorf("Expected a valid token refused from outside the allow-list, got %v", err)
+	}
+}
+
+func TestPolicy_WithoutProviders(t *testing.T) {
+	if _, err := (&Policy{Optional: true}).Authenticate(request("203.0.113.9:5000", nil)); err != nil {
+		t.Errorf("Expected an optional policy to let requests in, got %v", err)
+	}
+	if _, err := (&Policy{}).Authenticate(request("203.0.113.9:5000", nil)); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected a required policy to refuse everything, got %v", err)
+	}
+}
+
+func TestPolicy_AnyProvider(t *testing.T) {
+	p := &Policy{Providers: []Provider{
+		NewStaticTokens(testToken),
+		&TrustedHeader{Header: "X-Forwarded-Email", Proxies: mustParseNetworks(t, "10.0.0.5")},
+	}}
+
+	identity, err := p.Authenticate(request("10.0.0.5:5000", map[string]string{"X-Forwarded-Email": "ana@example.com"}))
+	if err != nil || identity.Provider != "header" || identity.Subject != "ana@example.com" {
+		t.Errorf("Expected the proxy's header to authenticate, got %+v, %v", identity, err)
+	}
+
+	identity, err = p.Authenticate(request("203.0.113.9:5000", map[string]string{"Authorization": "Bearer " + testToken}))
+	if err != nil || identity.Provider != "token" {
+		t.Errorf("Expected the static token to authenticate, got %+v, %v", identity, err)
+	}
+
+	_, err = p.Authenticate(request("203.0.113.9:5000", map[string]string{"X-Forwarded-Email": "ana@example.com"}))
+	if err == nil || errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected the header refused from outside the proxies, got %v", err)
+	}
+
+	if _, err := p.Authenticate(request("203.0.113.9:5000", nil)); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials without credentials, got %v", err)
+	}
+}
+
+func TestStaticTokens(t *testing.T) {
+	p := NewStaticTokens("", testToken, "second-token-0123456789")
+	for name, header := range map[string]string{
+		"wrong":      "Bearer not-the-token",
+		"empty":      "Bearer ",
+		"not bearer": "Basic " + testToken,
+	} {
+		if _, err := p.Authenticate(request("203.0.113.9:5000", map[string]string{"Authorization": header})); err == nil {
+			t.Errorf("%s token: expected a refusal", name)
+		}
+	}
+	for _, token := range []string{testToken, "second-token-0123456789"} {
+		if _, err := p.Authenticate(request("203.0.113.9:5000", map[string]string{"Authorization": "Bearer " + token})); err != nil {
+			t.Errorf("Expected %q accepted, got %v", token, err)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package httpauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// jwtClockSkew is how far apart the issuer's clock and ours may be
+const jwtClockSkew = time.Minute
+
+// JWT lets in requests with a signed JWT, such as an OIDC ID token or the
+// assertion an identity-aware proxy adds. Tokens are checked against one
+// key: HS256 with a shared secret ([]byte), RS256 with an *rsa.PublicKey, or
+// ES256 with a P-256 *ecdsa.PublicKey. Keys aren't fetched from the issuer.
+type JWT struct {
+	Key      interface{}
+	Issuer   string // Required iss claim; empty accepts any
+	Audience string // Required aud entry; empty accepts any
+	Header   string // Header holding the bare token; empty reads the bearer token
+
+	now func() time.Time
+}
+
+// jwtClaims are the claims checked or reported
+type jwtClaims struct {
+	Issuer    string      `json:"iss"`
+	Subject   string      `json:"sub"`
+	Email     string      `json:"email"`
+	Audience  jwtAudience `json:"aud"`
+	ExpiresAt *float64    `json:"exp"`
+	NotBefore *float64    `json:"nbf"`
+}
+
+// jwtAudience is the aud claim, which is a string or a list of them
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// Name implements Provider
+func (p *JWT) Name() string {
+	return "jwt"
+}
+
+// Authenticate verifies the token and returns its email, or its subject
+func (p *JWT) Authenticate(r *http.Request) (*Identity, error) {
+	token, ok := BearerToken(r)
+	if p.Header != "" {
+		token = strings.TrimSpace(r.Header.Get(p.Header))
+		ok = token != ""
+	}
+	parts := strings.Split(token, ".")
+	if !ok || len(parts) != 3 {
+		// Static bearer tokens aren't JWTs
+		return nil, ErrNoCredentials
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	if err := p.verify(header.Alg, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if err := p.checkClaims(&claims); err != nil {
+		return nil, err
+	}
+	subject := claims.Email
+	if subject == "" {
+		subject = claims.Subject
+	}
+	return &Identity{Provider: p.Name(), Subject: subject}, nil
+}
+
+// verify checks signature over signed with the key. The algorithm must be
+// the one the key is for, so an RSA public key can't be used as an HMAC secret.
+func (p *JWT) verify(alg, signed string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signed))
+	switch key := p.Key.(type) {
+	case []byte:
+		if alg != "HS256" {
+			return fmt.Errorf("token algorithm %q isn't accepted; want HS256", alg)
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return errors.New("invalid token signature")
+		}
+	case *rsa.PublicKey:
+		if alg != "RS256" {
+			return fmt.Errorf("token algorithm %q isn't accepted; want RS256", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("invalid token signature")
+		}
+	case *ecdsa.PublicKey:
+		if alg != "ES256" {
+			return fmt.Errorf("token algorithm %q isn't accepted; want ES256", alg)
+		}
+		if len(signature) != 64 {
+			return errors.New("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(key, digest[:], r, s) {
+			return errors.New("invalid token signature")
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", p.Key)
+	}
+	return nil
+}
+
+// checkClaims checks the token is current and meant for us
+func (p *JWT) checkClaims(claims *jwtClaims) error {
+	now := time.Now()
+	if p.now != nil {
+		now = p.now()
+	}
+	if claims.ExpiresAt == nil {
+		return errors.New("token has no expiry")
+	}
+	if expires := unixTime(*claims.ExpiresAt); now.After(expires.Add(jwtClockSkew)) {
+		return fmt.Errorf("token expired at %s", expires.UTC().Format(time.RFC3339))
+	}
+	if claims.NotBefore != nil && now.Add(jwtClockSkew).Before(unixTime(*claims.NotBefore)) {
+		return errors.New("token isn't valid yet")
+	}
+	if p.Issuer != "" && claims.Issuer != p.Issuer {
+		return fmt.Errorf("token issuer %q isn't accepted", claims.Issuer)
+	}
+	if p.Audience != "" {
+		for _, audience := range claims.Audience {
+			if audience == p.Audience {
+				return nil
+			}
+		}
+		return fmt.Errorf("token isn't for audience %q", p.Audience)
+	}
+	return nil
+}
+
+// unixTime converts a NumericDate claim
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// decodeJWTSegment decodes a base64url JSON segment of a token into v
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ParseVerificationKey parses a PEM public key or certificate for checking
+// RS256 or ES256 tokens
+func ParseVerificationKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var key crypto.PublicKey
+	var err error
+	switch block.Type {
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q; want a public key or certificate", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return key, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported curve %s; ES256 needs P-256", key.Curve.Params().Name)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T; want RSA or ECDSA", key)
+	}
+}
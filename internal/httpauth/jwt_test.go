@@ -0,0 +1,182 @@
+package httpauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// signJWT builds a token over claims, signed by sign
+func signJWT(t *testing.T, alg string, claims map[string]interface{}, sign func(signed []byte) []byte) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
+
+func hs256(secret []byte) func([]byte) []byte {
+	return func(signed []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		return mac.Sum(nil)
+	}
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   "https://issuer.example.com",
+		"aud":   []string{"claude-on-slack", "other"},
+		"sub":   "12345",
+		"email": "ana@example.com",
+		"exp":   testNow.Add(time.Hour).Unix(),
+	}
+}
+
+func bearer(token string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+func TestJWT_HS256(t *testing.T) {
+	secret := []byte("jwt-secret-0123456789abcdef012345")
+	p := &JWT{Key: secret, Issuer: "https://issuer.example.com", Audience: "claude-on-slack", now: func() time.Time { return testNow }}
+
+	identity, err := p.Authenticate(request("203.0.113.9:5000", bearer(signJWT(t, "HS256", validClaims(), hs256(secret)))))
+	if err != nil || identity.Subject != "ana@example.com" {
+		t.Fatalf("Expected a valid token to authenticate by email, got %+v, %v", identity, err)
+	}
+
+	forged := signJWT(t, "HS256", validClaims(), hs256([]byte("some-other-secret")))
+	if _, err := p.Authenticate(request("203.0.113.9:5000", bearer(forged))); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("Expected a bad signature refused, got %v", err)
+	}
+
+	none := signJWT(t, "none", validClaims(), func([]byte) []byte { return nil })
+	if _, err := p.Authenticate(request("203.0.113.9:5000", bearer(none))); err == nil {
+		t.Error("Expected an unsigned token refused")
+	}
+
+	if _, err := p.Authenticate(request("203.0.113.9:5000", bearer(testToken))); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected a static token left to other providers, got %v", err)
+	}
+}
+
+func TestJWT_Claims(t *testing.T) {
+	secret := []byte("jwt-secret-0123456789abcdef012345")
+	p := &JWT{Key: secret, Issuer: "https://issuer.example.com", Audience: "claude-on-slack", now: func() time.Time { return testNow }}
+
+	for name, change := range map[string]func(map[string]interface{}){
+		"expired":        func(c map[string]interface{}) { c["exp"] = testNow.Add(-2 * time.Minute).Unix() },
+		"no expiry":      func(c map[string]interface{}) { delete(c, "exp") },
+		"not yet valid":  func(c map[string]interface{}) { c["nbf"] = testNow.Add(5 * time.Minute).Unix() },
+		"wrong issuer":   func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" },
+		"wrong audience": func(c map[string]interface{}) { c["aud"] = "someone-else" },
+	} {
+		claims := validClaims()
+		change(claims)
+		token := signJWT(t, "HS256", claims, hs256(secret))
+		if _, err := p.Authenticate(request("203.0.113.9:5000", bearer(token))); err == nil {
+			t.Errorf("%s: expected the token refused", name)
+		}
+	}
+
+	claims := validClaims()
+	claims["exp"] = testNow.Add(-30 * time.Second).Unix()
+	claims["aud"] = "claude-on-slack"
+	delete(claims, "email")
+	identity, err := p.Authenticate(request("203.0.113.9:5000", bearer(signJWT(t, "HS256", claims, hs256(secret)))))
+	if err != nil || identity.Subject != "12345" {
+		t.Errorf("Expected clock skew allowed and the subject reported, got %+v, %v", identity, err)
+	}
+}
+
+func TestJWT_RS256FromPEM(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParseVerificationKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParseVerificationKey: %v", err)
+	}
+
+	p := &JWT{Key: key, now: func() time.Time { return testNow }}
+	token := signJWT(t, "RS256", validClaims(), func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	})
+	if _, err := p.Authenticate(request("203.0.113.9:5000", bearer(token))); err != nil {
+		t.Errorf("Expected an RS256 token accepted, got %v", err)
+	}
+
+	// The public key must not work as an HMAC secret
+	confused := signJWT(t, "HS256", validClaims(), hs256(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	if _, err := p.Authenticate(request("203.0.113.9:5000", bearer(confused))); err == nil {
+		t.Error("Expected an HS256 token refused for an RSA key")
+	}
+}
+
+func TestJWT_ES256FromHeader(t *testing.T) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &JWT{Key: &private.PublicKey, Header: "X-Goog-IAP-JWT-Assertion", now: func() time.Time { return testNow }}
+	token := signJWT(t, "ES256", validClaims(), func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		r, s, err := ecdsa.Sign(rand.Reader, private, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature
+	})
+
+	if _, err := p.Authenticate(request("203.0.113.9:5000", map[string]string{"X-Goog-IAP-JWT-Assertion": token})); err != nil {
+		t.Errorf("Expected an ES256 token in the header accepted, got %v", err)
+	}
+	if _, err := p.Authenticate(request("203.0.113.9:5000", bearer(token))); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected the bearer token ignored when a header is set, got %v", err)
+	}
+}
+
+func TestParseVerificationKey_Refuses(t *testing.T) {
+	if _, err := ParseVerificationKey([]byte("not a key")); err == nil {
+		t.Error("Expected non-PEM data refused")
+	}
+	private, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if _, err := ParseVerificationKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})); err == nil {
+		t.Error("Expected a P-384 key refused")
+	}
+}
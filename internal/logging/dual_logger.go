@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/slack-go/slack"
@@ -14,6 +15,7 @@ import (
 // DualLogger provides centralized error logging to both console and Slack
 type DualLogger struct {
 	zapLogger *zap.Logger
+	mu        sync.RWMutex
 	slackAPI  *slack.Client
 }
 
@@ -34,6 +36,13 @@ func NewDualLogger(zapLogger *zap.Logger, slackAPI *slack.Client) *DualLogger {
 	}
 }
 
+// SetSlackClient replaces the Slack client after credential rotation
+func (dl *DualLogger) SetSlackClient(slackAPI *slack.Client) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	dl.slackAPI = slackAPI
+}
+
 // LogError logs an error to both console and Slack channel
 func (dl *DualLogger) LogError(ctx context.Context, errCtx *ErrorContext, err error, message string) {
 	// Always log to console first
@@ -74,7 +83,11 @@ func (dl *DualLogger) logToSlack(ctx context.Context, errCtx *ErrorContext, err
 	slackMessage := dl.formatSlackMessage(errCtx, err, message)
 	
 	// Send ephemeral message (only visible to the user who triggered the error)
-	_, err = dl.slackAPI.PostEphemeral(
+	dl.mu.RLock()
+	slackAPI := dl.slackAPI
+	dl.mu.RUnlock()
+
+	_, err = slackAPI.PostEphemeral(
 		errCtx.ChannelID,
 		errCtx.UserID,
 		slack.MsgOptionText(slackMessage, false),
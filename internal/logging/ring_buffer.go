@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LogEntry is a captured log line kept in memory for diagnostics
+type LogEntry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// RingBuffer keeps the most recent log entries in memory so they can be
+// attached to debug bundles without reading the systemd journal
+type RingBuffer struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	next    int
+	full    bool
+}
+
+// NewRingBuffer creates a ring buffer holding up to size entries
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		size = 1
+	}
+	return &RingBuffer{
+		entries: make([]LogEntry, size),
+	}
+}
+
+// Core returns a zapcore.Core that writes into the ring buffer
+func (r *RingBuffer) Core(level zapcore.LevelEnabler) zapcore.Core {
+	return &ringCore{LevelEnabler: level, buffer: r}
+}
+
+// Entries returns all captured entries, oldest first
+func (r *RingBuffer) Entries() []LogEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []LogEntry
+	if r.full {
+		result = append(result, r.entries[r.next:]...)
+	}
+	result = append(result, r.entries[:r.next]...)
+	return result
+}
+
+// EntriesForChannel returns captured entries tagged with the given channel_id
+func (r *RingBuffer) EntriesForChannel(channelID string) []LogEntry {
+	var result []LogEntry
+	for _, entry := range r.Entries() {
+		if entry.Fields["channel_id"] == channelID {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// add appends an entry, overwriting the oldest one when full
+func (r *RingBuffer) add(entry LogEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// ringCore adapts RingBuffer to the zapcore.Core interface
+type ringCore struct {
+	zapcore.LevelEnabler
+	buffer *RingBuffer
+	fields []zapcore.Field
+}
+
+func (c *ringCore) With(fields []zapcore.Field) zapcore.Core {
+	combined := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	combined = append(combined, c.fields...)
+	combined = append(combined, fields...)
+	return &ringCore{LevelEnabler: c.LevelEnabler, buffer: c.buffer, fields: combined}
+}
+
+func (c *ringCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *ringCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(encoder)
+	}
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+
+	// Stack traces make bundles huge and are already in the journal
+	delete(encoder.Fields, "stack_trace")
+
+	c.buffer.add(LogEntry{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Fields:  encoder.Fields,
+	})
+	return nil
+}
+
+func (c *ringCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRingBuffer_KeepsMostRecentEntries(t *testing.T) {
+	buffer := NewRingBuffer(3)
+	logger := zap.New(buffer.Core(zapcore.DebugLevel))
+
+	for _, msg := range []string{"one", "two", "three", "four"} {
+		logger.Info(msg)
+	}
+
+	entries := buffer.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Message != "two" || entries[2].Message != "four" {
+		t.Errorf("Unexpected entry order: %q ... %q", entries[0].Message, entries[2].Message)
+	}
+}
+
+func TestRingBuffer_EntriesForChannel(t *testing.T) {
+	buffer := NewRingBuffer(10)
+	logger := zap.New(buffer.Core(zapcore.DebugLevel))
+
+	logger.Info("in channel", zap.String("channel_id", "C1"))
+	logger.With(zap.String("channel_id", "C2")).Info("other channel")
+	logger.Info("no channel")
+
+	entries := buffer.EntriesForChannel("C1")
+	if len(entries) != 1 || entries[0].Message != "in channel" {
+		t.Errorf("Expected only the C1 entry, got %+v", entries)
+	}
+}
@@ -2,91 +2,57 @@ package notifications
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/slack-go/slack"
-	"go.uber.org/zap"
-
 	"github.com/ghabxph/claude-on-slack/internal/version"
 )
 
-type DeploymentNotifier struct {
-	slackClient *slack.Client
-	channels    []string
-	logger      *zap.Logger
-}
-
-func NewDeploymentNotifier(slackClient *slack.Client, channels []string, logger *zap.Logger) *DeploymentNotifier {
-	return &DeploymentNotifier{
-		slackClient: slackClient,
-		channels:    channels,
-		logger:      logger,
+// DeploymentEvent builds the notification sent when a new version starts.
+// previous is the version last announced, if known; changes are its release
+// notes since then.
+func DeploymentEvent(previous string, changes []string) Event {
+	text := "A new version is running."
+	if previous != "" {
+		text = fmt.Sprintf("A new version is running, replacing v%s.", previous)
+	}
+	if len(changes) > 0 {
+		text += "\n\nChanges in this release:\n• " + strings.Join(changes, "\n• ")
+	}
+	return Event{
+		Kind:      EventDeployment,
+		Severity:  SeverityInfo,
+		Title:     fmt.Sprintf("Claude Bot deployed v%s", version.GetVersion()),
+		Text:      text,
+		SlackText: FormatDeploymentMessage(version.GetVersion(), previous, changes),
 	}
 }
 
-func (n *DeploymentNotifier) NotifyDeployment(changes []string) error {
-	message := n.FormatDeploymentMessage(version.GetVersion(), changes)
-	
-	return n.SendConcurrentNotifications(message)
-}
+// maxDeploymentChanges caps the changes listed in a Slack deployment message
+const maxDeploymentChanges = 12
 
-func (n *DeploymentNotifier) FormatDeploymentMessage(version string, changes []string) string {
+func FormatDeploymentMessage(version, previous string, changes []string) string {
 	message := fmt.Sprintf("🚀 *Claude Bot Deployment Complete* - v%s\n", version)
-	message += fmt.Sprintf("⏰ Deployed at: %s\n\n", time.Now().Format("2006-01-02 15:04:05 UTC"))
-	
+	if previous != "" {
+		message += fmt.Sprintf("⬆️ Upgraded from: v%s\n", previous)
+	}
+	message += fmt.Sprintf("⏰ Deployed at: %s\n\n", time.Now().UTC().Format("2006-01-02 15:04:05 UTC"))
+
 	if len(changes) > 0 {
 		message += "*Changes in this release:*\n"
-		for _, change := range changes {
+		for i, change := range changes {
+			if i == maxDeploymentChanges {
+				message += fmt.Sprintf("• _…and %d more_\n", len(changes)-maxDeploymentChanges)
+				break
+			}
 			message += fmt.Sprintf("• %s\n", change)
 		}
 	} else {
-		message += "• 🛠️ **Database Query Robustness** - Replaced SELECT * with explicit columns for schema safety\n"
-		message += "• 🔧 **SQL Scan Error Fix** - Resolved critical column order mismatch in child session queries\n"
-		message += "• 🚀 **Migration-Proof Design** - Future database changes won't break existing queries\n"
-		message += "• 🎯 **Type Safety** - Eliminated runtime SQL scan errors from column/field misalignment\n"
-		message += "• ⚡ **Performance & Maintainability** - Explicit column selection improves query optimization\n"
+		message += "_No release notes for this version._\n"
 	}
-	
+
 	message += "\n📋 *Full details*: See <https://github.com/ghabxph/claude-on-slack/blob/main/CHANGELOG.md|CHANGELOG.md>\n"
 	message += "✅ All systems operational"
-	
+
 	return message
 }
-
-func (n *DeploymentNotifier) SendConcurrentNotifications(message string) error {
-	if len(n.channels) == 0 {
-		n.logger.Info("No notification channels configured, skipping deployment notification")
-		return nil
-	}
-
-	errChan := make(chan error, len(n.channels))
-	
-	for _, channel := range n.channels {
-		go func(ch string) {
-			_, _, err := n.slackClient.PostMessage(ch,
-				slack.MsgOptionText(message, false),
-				slack.MsgOptionAsUser(true))
-			errChan <- err
-		}(channel)
-	}
-
-	// Collect results
-	var errors []error
-	for i := 0; i < len(n.channels); i++ {
-		if err := <-errChan; err != nil {
-			errors = append(errors, err)
-			n.logger.Error("Failed to send deployment notification", 
-				zap.Error(err),
-				zap.String("channel", n.channels[i]))
-		}
-	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to send notifications to %d channels", len(errors))
-	}
-
-	n.logger.Info("Deployment notifications sent successfully", 
-		zap.Int("channels", len(n.channels)))
-
-	return nil
-}
\ No newline at end of file
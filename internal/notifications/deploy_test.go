@@ -0,0 +1,29 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormatDeploymentMessage(t *testing.T) {
+	message := FormatDeploymentMessage("2.1.0", "2.0.1", []string{"Added - Session Titles"})
+	for _, want := range []string{"v2.1.0", "Upgraded from: v2.0.1", "• Added - Session Titles"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("Expected message to contain %q, got:\n%s", want, message)
+		}
+	}
+
+	if message := FormatDeploymentMessage("2.1.0", "", nil); !strings.Contains(message, "No release notes") || strings.Contains(message, "Upgraded from") {
+		t.Errorf("Expected a plain notice without notes or a previous version, got:\n%s", message)
+	}
+
+	var changes []string
+	for i := 0; i < maxDeploymentChanges+3; i++ {
+		changes = append(changes, fmt.Sprintf("Change %d", i))
+	}
+	message = FormatDeploymentMessage("2.1.0", "", changes)
+	if strings.Contains(message, fmt.Sprintf("Change %d", maxDeploymentChanges)) || !strings.Contains(message, "…and 3 more") {
+		t.Errorf("Expected the list capped at %d, got:\n%s", maxDeploymentChanges, message)
+	}
+}
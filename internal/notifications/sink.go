@@ -0,0 +1,140 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Severity ranks how urgent a notification is
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// ParseSeverity parses "info", "warning", or "critical"
+func ParseSeverity(value string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "critical":
+		return SeverityCritical, nil
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q (use info, warning, or critical)", value)
+}
+
+// Event kinds
+const (
+	EventDeployment         = "deployment"
+	EventBudgetBreach       = "budget_breach"
+	EventRepeatedFailures   = "repeated_failures"
+	EventStuckQueue         = "stuck_queue"
+	EventOrphanedWorkspaces = "orphaned_workspaces"
+	EventMissingScopes      = "missing_scopes"
+	EventSlowMode           = "slow_mode"
+	EventOutboxAbandoned    = "outbox_abandoned"
+)
+
+// Event is something operators may want to hear about
+type Event struct {
+	Kind     string
+	Severity Severity
+	Title    string
+	Text     string
+	Fields   map[string]string
+	// Key identifies the affected resource, e.g. a channel, so repeats of
+	// the same condition can be grouped by sinks that support it
+	Key  string
+	Time time.Time
+	// SlackText, when set, is posted to Slack as is instead of a message
+	// built from the other fields
+	SlackText string
+}
+
+// Sink delivers events to one destination
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
+
+// Route sends events at or above MinSeverity to a sink
+type Route struct {
+	Sink        Sink
+	MinSeverity Severity
+}
+
+// Router fans events out to the sinks whose routes match
+type Router struct {
+	routes []Route
+	logger *zap.Logger
+}
+
+func NewRouter(logger *zap.Logger, routes ...Route) *Router {
+	return &Router{routes: routes, logger: logger}
+}
+
+// Notify delivers an event to every matching sink in parallel. Failures are
+// logged; the returned error reports how many sinks failed.
+func (r *Router) Notify(ctx context.Context, event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failed []string
+	for _, route := range r.routes {
+		if event.Severity < route.MinSeverity {
+			continue
+		}
+		wg.Add(1)
+		go func(sink Sink) {
+			defer wg.Done()
+			if err := sink.Send(ctx, event); err != nil {
+				r.logger.Error("Failed to send notification",
+					zap.String("sink", sink.Name()),
+					zap.String("kind", event.Kind),
+					zap.Error(err))
+				mu.Lock()
+				failed = append(failed, sink.Name())
+				mu.Unlock()
+			}
+		}(route.Sink)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to send %s notification to %s", event.Kind, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// plainText renders an event as text for sinks without rich formatting
+func plainText(event Event) string {
+	var text strings.Builder
+	text.WriteString(event.Text)
+	for _, key := range sortedKeys(event.Fields) {
+		text.WriteString(fmt.Sprintf("\n%s: %s", key, event.Fields[key]))
+	}
+	return text.String()
+}
@@ -0,0 +1,183 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type recordingSink struct {
+	name string
+	err  error
+
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Send(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, value := range []string{"info", "Warning", " critical "} {
+		if _, err := ParseSeverity(value); err != nil {
+			t.Errorf("ParseSeverity(%q) error = %v", value, err)
+		}
+	}
+	if _, err := ParseSeverity("urgent"); err == nil {
+		t.Error("ParseSeverity(urgent) succeeded, want an error")
+	}
+}
+
+func TestRouter_RoutesBySeverity(t *testing.T) {
+	slackSink := &recordingSink{name: "slack"}
+	pager := &recordingSink{name: "pagerduty"}
+	router := NewRouter(zap.NewNop(),
+		Route{Sink: slackSink, MinSeverity: SeverityInfo},
+		Route{Sink: pager, MinSeverity: SeverityCritical})
+
+	router.Notify(context.Background(), Event{Kind: EventBudgetBreach, Severity: SeverityWarning, Title: "Budget"})
+	router.Notify(context.Background(), Event{Kind: EventRepeatedFailures, Severity: SeverityCritical, Title: "Failures"})
+
+	if len(slackSink.events) != 2 {
+		t.Errorf("slack got %d events, want 2", len(slackSink.events))
+	}
+	if len(pager.events) != 1 || pager.events[0].Kind != EventRepeatedFailures {
+		t.Errorf("pagerduty got %+v, want only the critical event", pager.events)
+	}
+	if slackSink.events[0].Time.IsZero() {
+		t.Error("event time was not set")
+	}
+}
+
+func TestRouter_ReportsFailedSinks(t *testing.T) {
+	router := NewRouter(zap.NewNop(),
+		Route{Sink: &recordingSink{name: "webhook", err: errors.New("boom")}},
+		Route{Sink: &recordingSink{name: "slack"}})
+
+	err := router.Notify(context.Background(), Event{Kind: EventStuckQueue})
+	if err == nil || !strings.Contains(err.Error(), "webhook") || strings.Contains(err.Error(), "slack") {
+		t.Errorf("Notify() error = %v, want only webhook reported", err)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	event := Event{Kind: EventBudgetBreach, Severity: SeverityWarning, Title: "Budget exceeded", Fields: map[string]string{"channel": "C1"}, Time: time.Now()}
+	if err := sink.Send(context.Background(), event); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if received["kind"] != EventBudgetBreach || received["severity"] != "warning" || received["title"] != "Budget exceeded" {
+		t.Errorf("unexpected payload: %v", received)
+	}
+}
+
+func TestWebhookSink_RejectsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookSink(server.URL).Send(context.Background(), Event{Time: time.Now()}); err == nil {
+		t.Error("Send() succeeded on a 502 response")
+	}
+}
+
+func TestPagerDutySink(t *testing.T) {
+	var received struct {
+		RoutingKey  string `json:"routing_key"`
+		EventAction string `json:"event_action"`
+		DedupKey    string `json:"dedup_key"`
+		Payload     struct {
+			Summary  string `json:"summary"`
+			Severity string `json:"severity"`
+		} `json:"payload"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sink := NewPagerDutySink("routing-key")
+	sink.url = server.URL
+	event := Event{Kind: EventRepeatedFailures, Severity: SeverityCritical, Title: "5 failed runs", Key: "C1", Time: time.Now()}
+	if err := sink.Send(context.Background(), event); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if received.RoutingKey != "routing-key" || received.EventAction != "trigger" ||
+		received.DedupKey != "claude-on-slack/repeated_failures/C1" ||
+		received.Payload.Summary != "5 failed runs" || received.Payload.Severity != "critical" {
+		t.Errorf("unexpected payload: %+v", received)
+	}
+}
+
+func TestEmailSink(t *testing.T) {
+	sink := NewEmailSink("smtp.example.com:587", "bot", "secret", "bot@example.com", []string{"ops@example.com"})
+	var sentTo []string
+	var message string
+	sink.send = func(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sentTo, message = to, string(msg)
+		return nil
+	}
+
+	event := Event{Kind: EventStuckQueue, Severity: SeverityWarning, Title: "Runs stuck", Text: "2 runs", Fields: map[string]string{"oldest": "20m"}, Time: time.Now()}
+	if err := sink.Send(context.Background(), event); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(sentTo) != 1 || sentTo[0] != "ops@example.com" {
+		t.Errorf("sent to %v", sentTo)
+	}
+	if !strings.Contains(message, "Subject: [claude-on-slack warning] Runs stuck\r\n") || !strings.Contains(message, "oldest: 20m") {
+		t.Errorf("unexpected message:\n%s", message)
+	}
+}
+
+func TestSendMail_StopsAtDeadline(t *testing.T) {
+	// A server that accepts the connection but never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			<-done
+			conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = sendMail(ctx, listener.Addr().String(), nil, "bot@example.com", []string{"ops@example.com"}, []byte("Subject: hi\r\n\r\nhi\r\n"))
+	if err == nil {
+		t.Fatal("Expected a silent server to fail the send")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the send to stop at the deadline, took %s", elapsed)
+	}
+}
@@ -0,0 +1,287 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// sinkHTTPTimeout bounds webhook and PagerDuty requests, and email sent
+// without a deadline of its own
+const sinkHTTPTimeout = 10 * time.Second
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// MessagePoster posts a message to a channel and returns its timestamp
+type MessagePoster interface {
+	PostMessage(ctx context.Context, channelID string, options ...slack.MsgOption) (string, error)
+}
+
+// SlackSink posts events to Slack channels
+type SlackSink struct {
+	poster   MessagePoster
+	channels []string
+}
+
+func NewSlackSink(poster MessagePoster, channels []string) *SlackSink {
+	return &SlackSink{poster: poster, channels: channels}
+}
+
+func (s *SlackSink) Name() string { return "slack" }
+
+func (s *SlackSink) Send(ctx context.Context, event Event) error {
+	message := event.SlackText
+	if message == "" {
+		message = slackText(event)
+	}
+
+	var failed int
+	for _, channel := range s.channels {
+		if _, err := s.poster.PostMessage(ctx, channel, slack.MsgOptionText(message, false), slack.MsgOptionAsUser(true)); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to post to %d of %d channels", failed, len(s.channels))
+	}
+	return nil
+}
+
+// slackText formats an event as a Slack message
+func slackText(event Event) string {
+	icon := "ℹ️"
+	switch event.Severity {
+	case SeverityWarning:
+		icon = "⚠️"
+	case SeverityCritical:
+		icon = "🚨"
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("%s *%s*\n%s", icon, event.Title, event.Text))
+	for _, key := range sortedKeys(event.Fields) {
+		text.WriteString(fmt.Sprintf("\n• %s: %s", key, event.Fields[key]))
+	}
+	return text.String()
+}
+
+// WebhookSink posts events as JSON to a URL
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: sinkHTTPTimeout}}
+}
+
+func (s *WebhookSink) Name() string { return "webhook" }
+
+func (s *WebhookSink) Send(ctx context.Context, event Event) error {
+	payload := map[string]interface{}{
+		"kind":     event.Kind,
+		"severity": event.Severity.String(),
+		"title":    event.Title,
+		"text":     event.Text,
+		"fields":   event.Fields,
+		"key":      event.Key,
+		"time":     event.Time.UTC().Format(time.RFC3339),
+	}
+	return postJSON(ctx, s.client, s.url, payload)
+}
+
+// EmailSink sends events by SMTP
+type EmailSink struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+	send func(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailSink creates an email sink; auth is only used when username is set
+func NewEmailSink(addr, username, password, from string, to []string) *EmailSink {
+	var auth smtp.Auth
+	if username != "" {
+		host := addr
+		if i := strings.LastIndex(addr, ":"); i >= 0 {
+			host = addr[:i]
+		}
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &EmailSink{addr: addr, auth: auth, from: from, to: to, send: sendMail}
+}
+
+func (s *EmailSink) Name() string { return "email" }
+
+func (s *EmailSink) Send(ctx context.Context, event Event) error {
+	return s.send(ctx, s.addr, s.auth, s.from, s.to, emailMessage(s.from, s.to, event))
+}
+
+// sendMail delivers msg like smtp.SendMail, but bounded by ctx: the dial
+// and the whole exchange stop at its deadline or cancellation, so a server
+// that stops answering can't hold up other alerts
+func sendMail(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sinkHTTPTimeout)
+		defer cancel()
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %q: %w", addr, err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if a != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := client.Auth(a); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// emailMessage builds an RFC 5322 message for an event
+func emailMessage(from string, to []string, event Event) []byte {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("From: %s\r\n", from))
+	message.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(to, ", ")))
+	message.WriteString(fmt.Sprintf("Subject: [claude-on-slack %s] %s\r\n", event.Severity, event.Title))
+	message.WriteString(fmt.Sprintf("Date: %s\r\n", event.Time.Format(time.RFC1123Z)))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(plainText(event), "\n", "\r\n"))
+	message.WriteString("\r\n")
+	return []byte(message.String())
+}
+
+// PagerDutySink triggers PagerDuty incidents through the Events API v2
+type PagerDutySink struct {
+	routingKey string
+	url        string
+	client     *http.Client
+}
+
+func NewPagerDutySink(routingKey string) *PagerDutySink {
+	return &PagerDutySink{routingKey: routingKey, url: PagerDutyEventsURL, client: &http.Client{Timeout: sinkHTTPTimeout}}
+}
+
+func (s *PagerDutySink) Name() string { return "pagerduty" }
+
+func (s *PagerDutySink) Send(ctx context.Context, event Event) error {
+	details := map[string]string{"text": event.Text}
+	for key, value := range event.Fields {
+		details[key] = value
+	}
+
+	dedupKey := "claude-on-slack/" + event.Kind
+	if event.Key != "" {
+		dedupKey += "/" + event.Key
+	}
+
+	payload := map[string]interface{}{
+		"routing_key":  s.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    dedupKey,
+		"payload": map[string]interface{}{
+			"summary":        event.Title,
+			"source":         "claude-on-slack",
+			"severity":       event.Severity.String(),
+			"timestamp":      event.Time.UTC().Format(time.RFC3339),
+			"class":          event.Kind,
+			"custom_details": details,
+		},
+	}
+	return postJSON(ctx, s.client, s.url, payload)
+}
+
+// postJSON posts a JSON payload and treats any non-2xx response as an error
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
+
+func sortedKeys(fields map[string]string) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
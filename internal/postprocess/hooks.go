@@ -0,0 +1,136 @@
+package postprocess
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// maxWebhookResponse caps what a transform webhook may answer with
+const maxWebhookResponse = 1 << 20
+
+// RedactHook replaces every match of a pattern
+type RedactHook struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+func NewRedactHook(pattern *regexp.Regexp, replacement string) *RedactHook {
+	return &RedactHook{pattern: pattern, replacement: replacement}
+}
+
+func (h *RedactHook) Name() string { return "redact" }
+
+func (h *RedactHook) Process(ctx context.Context, response *Response) error {
+	response.Text = h.pattern.ReplaceAllString(response.Text, h.replacement)
+	return nil
+}
+
+// FooterHook appends fixed text, such as a compliance notice
+type FooterHook struct {
+	footer string
+}
+
+func NewFooterHook(footer string) *FooterHook {
+	return &FooterHook{footer: footer}
+}
+
+func (h *FooterHook) Name() string { return "footer" }
+
+func (h *FooterHook) Process(ctx context.Context, response *Response) error {
+	response.Text = strings.TrimRight(response.Text, "\n") + "\n\n" + h.footer
+	return nil
+}
+
+// WebhookHook posts the response as JSON to a URL that answers with
+// {"text": "..."} to replace it, e.g. a translation service. An empty body or
+// 204 keeps the text as it is.
+type WebhookHook struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookHook(url string) *WebhookHook {
+	return &WebhookHook{url: url, client: &http.Client{}}
+}
+
+func (h *WebhookHook) Name() string { return "webhook" }
+
+func (h *WebhookHook) Process(ctx context.Context, response *Response) error {
+	body, err := postResponse(ctx, h.client, h.url, response)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var reply struct {
+		Text *string `json:"text"`
+	}
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return fmt.Errorf("invalid webhook response: %w", err)
+	}
+	if reply.Text == nil {
+		return fmt.Errorf("webhook response has no text field")
+	}
+	response.Text = *reply.Text
+	return nil
+}
+
+// ForwardHook posts the response as JSON to a URL, such as an external
+// logging system, and leaves it unchanged
+type ForwardHook struct {
+	url    string
+	client *http.Client
+}
+
+func NewForwardHook(url string) *ForwardHook {
+	return &ForwardHook{url: url, client: &http.Client{}}
+}
+
+func (h *ForwardHook) Name() string { return "forward" }
+
+func (h *ForwardHook) Process(ctx context.Context, response *Response) error {
+	_, err := postResponse(ctx, h.client, h.url, response)
+	return err
+}
+
+// postResponse posts a response as JSON and returns the answer's body,
+// treating any non-2xx status as an error. The caller's context bounds it.
+func postResponse(ctx context.Context, client *http.Client, url string, response *Response) ([]byte, error) {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponse))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet := body
+		if len(snippet) > 512 {
+			snippet = snippet[:512]
+		}
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	return body, nil
+}
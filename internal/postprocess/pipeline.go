@@ -0,0 +1,185 @@
+// Package postprocess runs Claude's responses through an ordered pipeline of
+// hooks before they are posted: redaction, compliance footers, translation,
+// or forwarding to external logging systems.
+package postprocess
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Response is a reply on its way to Slack. Hooks may rewrite Text; the rest
+// describes where it's going.
+type Response struct {
+	Text      string `json:"text"`
+	ChannelID string `json:"channel_id"`
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id"`
+}
+
+// Hook processes a response before it is posted. Hooks compiled into the
+// bot can be made available by name with Register.
+type Hook interface {
+	Name() string
+	Process(ctx context.Context, response *Response) error
+}
+
+// FailurePolicy decides what happens to a response when a hook fails or
+// times out
+type FailurePolicy string
+
+const (
+	// FailSkip posts the response as it was before the failed hook
+	FailSkip FailurePolicy = "skip"
+	// FailBlock withholds the response, for hooks that must not be bypassed
+	FailBlock FailurePolicy = "block"
+)
+
+// IsValid reports whether p is a known policy
+func (p FailurePolicy) IsValid() bool {
+	return p == FailSkip || p == FailBlock
+}
+
+// Stage is a hook in a pipeline with its limits
+type Stage struct {
+	Hook      Hook
+	Timeout   time.Duration // Zero means no limit
+	OnFailure FailurePolicy
+}
+
+// BlockedError is returned when a hook with the block policy failed
+type BlockedError struct {
+	Hook string
+	Err  error
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("response hook %s failed: %v", e.Hook, e.Err)
+}
+
+func (e *BlockedError) Unwrap() error {
+	return e.Err
+}
+
+// Pipeline runs its stages in order, each seeing the previous one's output
+type Pipeline struct {
+	stages []Stage
+	logger *zap.Logger
+}
+
+func NewPipeline(logger *zap.Logger, stages ...Stage) *Pipeline {
+	return &Pipeline{stages: stages, logger: logger}
+}
+
+// Len returns how many stages the pipeline has
+func (p *Pipeline) Len() int {
+	return len(p.stages)
+}
+
+// Only returns a pipeline with just the stages whose hook has the given
+// name, in the same order and with the same timeouts and failure policies
+func (p *Pipeline) Only(name string) *Pipeline {
+	var stages []Stage
+	for _, stage := range p.stages {
+		if stage.Hook.Name() == name {
+			stages = append(stages, stage)
+		}
+	}
+	return NewPipeline(p.logger, stages...)
+}
+
+// Run passes response through every stage and returns the text to post. A
+// failed stage with the skip policy is logged and its changes dropped; with
+// the block policy Run stops and returns a *BlockedError.
+func (p *Pipeline) Run(ctx context.Context, response Response) (string, error) {
+	for _, stage := range p.stages {
+		processed, err := runStage(ctx, stage, response)
+		if err == nil {
+			response = processed
+			continue
+		}
+
+		p.logger.Error("Response hook failed",
+			zap.String("hook", stage.Hook.Name()),
+			zap.String("policy", string(stage.OnFailure)),
+			zap.String("channel_id", response.ChannelID),
+			zap.String("session_id", response.SessionID),
+			zap.Error(err))
+		if stage.OnFailure == FailBlock {
+			return "", &BlockedError{Hook: stage.Hook.Name(), Err: err}
+		}
+	}
+	return response.Text, nil
+}
+
+// runStage runs one hook on a copy of the response. A hook that ignores its
+// context is abandoned at the timeout and whatever it does later is dropped.
+func runStage(ctx context.Context, stage Stage, response Response) (Response, error) {
+	if stage.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, stage.Timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- stage.Hook.Process(ctx, &response)
+	}()
+
+	select {
+	case err := <-done:
+		return response, err
+	case <-ctx.Done():
+		return Response{}, fmt.Errorf("gave up: %w", ctx.Err())
+	}
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Hook)
+)
+
+// Register makes a hook available by name to RESPONSE_HOOKS. Call it from an
+// init function in a package built into the bot. Registering a name twice
+// panics.
+func Register(name string, hook Hook) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("postprocess: hook %q registered twice", name))
+	}
+	registry[name] = hook
+}
+
+// Registered returns the hook registered under name
+func Registered(name string) (Hook, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	hook, ok := registry[name]
+	return hook, ok
+}
+
+// RegisteredNames lists the registered hooks, sorted
+func RegisteredNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
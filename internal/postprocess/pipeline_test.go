@@ -0,0 +1,138 @@
+package postprocess
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// funcHook adapts a function to Hook
+type funcHook struct {
+	name string
+	fn   func(ctx context.Context, response *Response) error
+}
+
+func (h funcHook) Name() string { return h.name }
+
+func (h funcHook) Process(ctx context.Context, response *Response) error {
+	return h.fn(ctx, response)
+}
+
+func appendHook(name, suffix string) funcHook {
+	return funcHook{name, func(ctx context.Context, response *Response) error {
+		response.Text += suffix
+		return nil
+	}}
+}
+
+func failingHook(name string) funcHook {
+	return funcHook{name, func(ctx context.Context, response *Response) error {
+		response.Text = "half-done"
+		return errors.New("boom")
+	}}
+}
+
+func TestPipeline_RunsStagesInOrder(t *testing.T) {
+	pipeline := NewPipeline(zap.NewNop(),
+		Stage{Hook: appendHook("a", " a"), OnFailure: FailSkip},
+		Stage{Hook: appendHook("b", " b"), OnFailure: FailSkip},
+	)
+
+	text, err := pipeline.Run(context.Background(), Response{Text: "reply"})
+	if err != nil || text != "reply a b" {
+		t.Errorf("Run() = %q, %v; want %q", text, err, "reply a b")
+	}
+}
+
+func TestPipeline_SkipDropsTheFailedHooksChanges(t *testing.T) {
+	pipeline := NewPipeline(zap.NewNop(),
+		Stage{Hook: appendHook("a", " a"), OnFailure: FailSkip},
+		Stage{Hook: failingHook("broken"), OnFailure: FailSkip},
+		Stage{Hook: appendHook("c", " c"), OnFailure: FailSkip},
+	)
+
+	text, err := pipeline.Run(context.Background(), Response{Text: "reply"})
+	if err != nil || text != "reply a c" {
+		t.Errorf("Run() = %q, %v; want %q", text, err, "reply a c")
+	}
+}
+
+func TestPipeline_BlockStops(t *testing.T) {
+	ran := false
+	pipeline := NewPipeline(zap.NewNop(),
+		Stage{Hook: failingHook("compliance"), OnFailure: FailBlock},
+		Stage{Hook: funcHook{"after", func(ctx context.Context, response *Response) error {
+			ran = true
+			return nil
+		}}, OnFailure: FailSkip},
+	)
+
+	text, err := pipeline.Run(context.Background(), Response{Text: "reply"})
+	var blocked *BlockedError
+	if !errors.As(err, &blocked) || blocked.Hook != "compliance" {
+		t.Fatalf("Expected a BlockedError from compliance, got %v", err)
+	}
+	if text != "" || ran {
+		t.Errorf("Expected nothing to run or be returned after a block, got %q (later hook ran: %v)", text, ran)
+	}
+}
+
+func TestPipeline_TimeoutAbandonsHook(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	stuck := funcHook{"stuck", func(ctx context.Context, response *Response) error {
+		<-release // Ignores its context
+		response.Text = "too late"
+		return nil
+	}}
+
+	pipeline := NewPipeline(zap.NewNop(), Stage{Hook: stuck, Timeout: 10 * time.Millisecond, OnFailure: FailSkip})
+	started := time.Now()
+	text, err := pipeline.Run(context.Background(), Response{Text: "reply"})
+	if err != nil || text != "reply" {
+		t.Errorf("Run() = %q, %v; want the unchanged reply", text, err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Expected the stuck hook to be abandoned at its timeout, took %s", elapsed)
+	}
+
+	pipeline = NewPipeline(zap.NewNop(), Stage{Hook: stuck, Timeout: 10 * time.Millisecond, OnFailure: FailBlock})
+	if _, err := pipeline.Run(context.Background(), Response{Text: "reply"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a blocking timeout to report the deadline, got %v", err)
+	}
+}
+
+func TestPipeline_RecoversPanics(t *testing.T) {
+	panicky := funcHook{"panicky", func(ctx context.Context, response *Response) error {
+		panic("nil map")
+	}}
+	pipeline := NewPipeline(zap.NewNop(), Stage{Hook: panicky, OnFailure: FailBlock})
+
+	_, err := pipeline.Run(context.Background(), Response{Text: "reply"})
+	if err == nil || !strings.Contains(err.Error(), "panic: nil map") {
+		t.Errorf("Expected the panic to fail the hook, got %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	hook := appendHook("test-register", "!")
+	Register("test-register", hook)
+
+	if got, ok := Registered("test-register"); !ok || got.Name() != "test-register" {
+		t.Errorf("Registered() = %v, %v", got, ok)
+	}
+	if _, ok := Registered("missing"); ok {
+		t.Error("Expected an unregistered name to be missing")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a name twice to panic")
+		}
+	}()
+	Register("test-register", hook)
+}
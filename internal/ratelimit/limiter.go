@@ -0,0 +1,203 @@
+// Package ratelimit limits requests per user and per channel with token
+// buckets, which allow short bursts while holding the average to a rate
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Scopes a request is limited in
+const (
+	ScopeUser    = "user"
+	ScopeChannel = "channel"
+)
+
+// Limit configures a bucket: it holds up to Burst requests and refills at
+// PerMinute requests a minute. A PerMinute of zero or less disables it.
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+func (l Limit) enabled() bool {
+	return l.PerMinute > 0 && l.Burst > 0
+}
+
+// bucket is the capacity left for one user or channel
+type bucket struct {
+	scope   string
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+// refill adds the capacity that returned since the bucket was last updated
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed.Minutes()*float64(b.limit.PerMinute))
+		b.updated = now
+	}
+}
+
+// after is when the bucket will hold tokens requests' worth of capacity
+func (b *bucket) after(tokens float64) time.Time {
+	if b.tokens >= tokens {
+		return b.updated
+	}
+	perToken := float64(time.Minute) / float64(b.limit.PerMinute)
+	return b.updated.Add(time.Duration(math.Ceil((tokens - b.tokens) * perToken)))
+}
+
+// Decision is the outcome of a request
+type Decision struct {
+	Allowed bool
+	Scope   string // Scope that refused the request, or the one with least capacity left
+
+	Limit     int       // Burst size of Scope's bucket
+	Remaining int       // Whole requests left in Scope's bucket
+	RetryAt   time.Time // When the next request will be allowed
+	FullAt    time.Time // When Scope's bucket is full again
+}
+
+// RetryAfter is how long until the next request will be allowed
+func (d Decision) RetryAfter(now time.Time) time.Duration {
+	if !d.RetryAt.After(now) {
+		return 0
+	}
+	return d.RetryAt.Sub(now)
+}
+
+// Limiter holds a bucket per user and per channel
+type Limiter struct {
+	user    Limit
+	channel Limit
+	buckets map[string]*bucket // Keyed by scope and ID
+	mu      sync.Mutex
+}
+
+// New creates a limiter; either limit may be disabled
+func New(user, channel Limit) *Limiter {
+	return &Limiter{
+		user:    user,
+		channel: channel,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes one request from the user's and the channel's buckets. The
+// request is only counted if both have capacity, so a refused request
+// doesn't use up the other bucket.
+func (l *Limiter) Allow(userID, channelID string, now time.Time) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var buckets []*bucket
+	if l.user.enabled() && userID != "" {
+		buckets = append(buckets, l.bucket(ScopeUser, userID, l.user, now))
+	}
+	if l.channel.enabled() && channelID != "" {
+		buckets = append(buckets, l.bucket(ScopeChannel, channelID, l.channel, now))
+	}
+	if len(buckets) == 0 {
+		return Decision{Allowed: true, RetryAt: now, FullAt: now}
+	}
+
+	// Refuse with whichever empty bucket refills last
+	var refused *bucket
+	for _, b := range buckets {
+		if b.tokens < 1 && (refused == nil || b.after(1).After(refused.after(1))) {
+			refused = b
+		}
+	}
+	if refused != nil {
+		return decide(false, refused)
+	}
+
+	// Report the bucket closest to running out
+	tightest := buckets[0]
+	for _, b := range buckets {
+		b.tokens--
+		if b.tokens < tightest.tokens {
+			tightest = b
+		}
+	}
+	return decide(true, tightest)
+}
+
+// bucket returns a bucket refilled up to now, creating a full one for IDs
+// not seen before
+func (l *Limiter) bucket(scope, id string, limit Limit, now time.Time) *bucket {
+	key := scope + ":" + id
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{scope: scope, limit: limit, tokens: float64(limit.Burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+	return b
+}
+
+func decide(allowed bool, b *bucket) Decision {
+	return Decision{
+		Allowed:   allowed,
+		Scope:     b.scope,
+		Limit:     b.limit.Burst,
+		Remaining: int(b.tokens),
+		RetryAt:   b.after(1),
+		FullAt:    b.after(float64(b.limit.Burst)),
+	}
+}
+
+// Capacity is what is left in one bucket
+type Capacity struct {
+	Scope     string
+	Limit     Limit
+	Remaining int       // Whole requests left
+	FullAt    time.Time // When the bucket is full again
+}
+
+// Peek reports the capacity left in the user's and the channel's buckets
+// without taking a request. Disabled limits are left out.
+func (l *Limiter) Peek(userID, channelID string, now time.Time) []Capacity {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var capacity []Capacity
+	for _, scope := range []struct {
+		name  string
+		id    string
+		limit Limit
+	}{{ScopeUser, userID, l.user}, {ScopeChannel, channelID, l.channel}} {
+		if !scope.limit.enabled() || scope.id == "" {
+			continue
+		}
+		// Refill a copy so peeking leaves no trace, not even a new bucket
+		b := bucket{scope: scope.name, limit: scope.limit, tokens: float64(scope.limit.Burst), updated: now}
+		if existing, ok := l.buckets[scope.name+":"+scope.id]; ok {
+			b = *existing
+			b.refill(now)
+		}
+		capacity = append(capacity, Capacity{
+			Scope:     b.scope,
+			Limit:     b.limit,
+			Remaining: int(b.tokens),
+			FullAt:    b.after(float64(b.limit.Burst)),
+		})
+	}
+	return capacity
+}
+
+// Prune drops buckets that are full again by now; a bucket seen for the
+// first time starts full, so they aren't needed
+func (l *Limiter) Prune(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, b := range l.buckets {
+		if !now.Before(b.after(float64(b.limit.Burst))) {
+			delete(l.buckets, key)
+		}
+	}
+}
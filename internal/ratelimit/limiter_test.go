@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter_Burst(t *testing.T) {
+	l := New(Limit{PerMinute: 6, Burst: 3}, Limit{})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if d := l.Allow("U1", "C1", now); !d.Allowed || d.Remaining != 2-i {
+			t.Fatalf("Request %d: Allowed, Remaining = %v, %d; want true, %d", i+1, d.Allowed, d.Remaining, 2-i)
+		}
+	}
+
+	d := l.Allow("U1", "C1", now)
+	if d.Allowed || d.Scope != ScopeUser {
+		t.Fatalf("Expected the fourth request to be refused by the user bucket, got %+v", d)
+	}
+	if got := d.RetryAfter(now); got != 10*time.Second {
+		t.Errorf("RetryAfter = %v, want 10s", got)
+	}
+	if want := now.Add(30 * time.Second); !d.FullAt.Equal(want) {
+		t.Errorf("FullAt = %v, want %v", d.FullAt, want)
+	}
+
+	if d := l.Allow("U2", "C1", now); !d.Allowed {
+		t.Error("Expected another user to have their own bucket")
+	}
+	if d := l.Allow("U1", "C1", now.Add(10*time.Second)); !d.Allowed {
+		t.Error("Expected one request to be allowed once capacity returned")
+	}
+}
+
+func TestLimiter_ChannelBucket(t *testing.T) {
+	l := New(Limit{PerMinute: 60, Burst: 5}, Limit{PerMinute: 2, Burst: 2})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	l.Allow("U1", "C1", now)
+	l.Allow("U2", "C1", now)
+
+	d := l.Allow("U3", "C1", now)
+	if d.Allowed || d.Scope != ScopeChannel {
+		t.Fatalf("Expected the channel bucket to refuse, got %+v", d)
+	}
+	if got := d.RetryAfter(now); got != 30*time.Second {
+		t.Errorf("RetryAfter = %v, want 30s", got)
+	}
+
+	// The refused request wasn't taken from U3's own bucket
+	if d := l.Allow("U3", "C2", now); !d.Allowed || d.Remaining != 1 || d.Scope != ScopeChannel {
+		t.Errorf("Unexpected decision in another channel: %+v", d)
+	}
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	l := New(Limit{}, Limit{})
+	for i := 0; i < 100; i++ {
+		if d := l.Allow("U1", "C1", time.Now()); !d.Allowed {
+			t.Fatal("Expected a disabled limiter to allow everything")
+		}
+	}
+}
+
+func TestLimiter_Prune(t *testing.T) {
+	l := New(Limit{PerMinute: 6, Burst: 3}, Limit{})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.Allow("U1", "", now)
+
+	l.Prune(now.Add(5 * time.Second))
+	if len(l.buckets) != 1 {
+		t.Fatalf("Expected a partly empty bucket to be kept")
+	}
+	l.Prune(now.Add(10 * time.Second))
+	if len(l.buckets) != 0 {
+		t.Errorf("Expected a full bucket to be pruned, have %d", len(l.buckets))
+	}
+}
+
+func TestLimiter_Peek(t *testing.T) {
+	l := New(Limit{PerMinute: 6, Burst: 3}, Limit{PerMinute: 60, Burst: 10})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.Allow("U1", "C1", now)
+
+	capacity := l.Peek("U1", "C1", now)
+	if len(capacity) != 2 || capacity[0].Scope != ScopeUser || capacity[0].Remaining != 2 || capacity[1].Remaining != 9 {
+		t.Fatalf("Peek() = %+v, want 2 left for the user and 9 for the channel", capacity)
+	}
+	if want := now.Add(10 * time.Second); !capacity[0].FullAt.Equal(want) {
+		t.Errorf("Expected the user bucket to be full at %v, got %v", want, capacity[0].FullAt)
+	}
+
+	// Peeking takes nothing and doesn't create buckets for new IDs
+	l.Peek("U1", "C1", now)
+	l.Peek("U2", "", now)
+	if d := l.Allow("U1", "", now); d.Remaining != 1 {
+		t.Errorf("Expected peeking to leave the bucket alone, %d left after one more request", d.Remaining)
+	}
+	if len(l.buckets) != 2 {
+		t.Errorf("Expected no bucket for a peeked-at user, have %d buckets", len(l.buckets))
+	}
+}
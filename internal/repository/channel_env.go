@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type ChannelEnvVar struct {
+	ChannelID      string    `db:"channel_id"`
+	Name           string    `db:"name"`
+	ValueEncrypted string    `db:"value_encrypted"`
+	UpdatedBy      *string   `db:"updated_by"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+type ChannelEnvRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewChannelEnvRepository(db *database.Database, logger *zap.Logger) *ChannelEnvRepository {
+	return &ChannelEnvRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SetChannelEnvVar creates or replaces a channel's environment variable. The
+// value must already be encrypted.
+func (r *ChannelEnvRepository) SetChannelEnvVar(channelID, name, valueEncrypted, updatedBy string) error {
+	query := `
+		INSERT INTO channel_env_vars (channel_id, name, value_encrypted, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (channel_id, name) DO UPDATE
+		SET value_encrypted = EXCLUDED.value_encrypted, updated_by = EXCLUDED.updated_by, updated_at = NOW()`
+
+	if _, err := r.db.GetDB().Exec(query, channelID, name, valueEncrypted, updatedBy); err != nil {
+		return fmt.Errorf("failed to set channel env var: %w", err)
+	}
+
+	r.logger.Info("Channel env var set",
+		zap.String("channel_id", channelID),
+		zap.String("name", name),
+		zap.String("updated_by", updatedBy))
+	return nil
+}
+
+// UnsetChannelEnvVar removes a channel's environment variable. It reports
+// false if the variable wasn't set.
+func (r *ChannelEnvRepository) UnsetChannelEnvVar(channelID, name string) (bool, error) {
+	query := `DELETE FROM channel_env_vars WHERE channel_id = $1 AND name = $2`
+
+	result, err := r.db.GetDB().Exec(query, channelID, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to unset channel env var: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check unset channel env var: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ListChannelEnvVars returns a channel's environment variables sorted by name
+func (r *ChannelEnvRepository) ListChannelEnvVars(channelID string) ([]*ChannelEnvVar, error) {
+	query := `
+		SELECT channel_id, name, value_encrypted, updated_by, updated_at
+		FROM channel_env_vars
+		WHERE channel_id = $1
+		ORDER BY name`
+
+	rows, err := r.db.GetDB().Query(query, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel env vars: %w", err)
+	}
+	defer rows.Close()
+
+	var vars []*ChannelEnvVar
+	for rows.Next() {
+		envVar := &ChannelEnvVar{}
+		if err := rows.Scan(&envVar.ChannelID, &envVar.Name, &envVar.ValueEncrypted,
+			&envVar.UpdatedBy, &envVar.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan channel env var: %w", err)
+		}
+		vars = append(vars, envVar)
+	}
+
+	return vars, rows.Err()
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+// ChannelFact is something a channel asked the bot to remember
+type ChannelFact struct {
+	ID        int       `db:"id"`
+	ChannelID string    `db:"channel_id"`
+	Fact      string    `db:"fact"`
+	CreatedBy string    `db:"created_by"`
+	UpdatedBy string    `db:"updated_by"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type ChannelFactRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewChannelFactRepository(db *database.Database, logger *zap.Logger) *ChannelFactRepository {
+	return &ChannelFactRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListChannelFacts returns a channel's facts, oldest first
+func (r *ChannelFactRepository) ListChannelFacts(channelID string) ([]*ChannelFact, error) {
+	query := `
+		SELECT id, channel_id, fact, created_by, updated_by, updated_at
+		FROM channel_facts
+		WHERE channel_id = $1
+		ORDER BY id`
+
+	rows, err := r.db.GetDB().Query(query, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel facts: %w", err)
+	}
+	defer rows.Close()
+
+	var facts []*ChannelFact
+	for rows.Next() {
+		f := &ChannelFact{}
+		if err := rows.Scan(&f.ID, &f.ChannelID, &f.Fact, &f.CreatedBy, &f.UpdatedBy, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan channel fact: %w", err)
+		}
+		facts = append(facts, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list channel facts: %w", err)
+	}
+	return facts, nil
+}
+
+// AddChannelFact saves a new fact for a channel and returns its ID
+func (r *ChannelFactRepository) AddChannelFact(channelID, fact, createdBy string) (int, error) {
+	query := `
+		INSERT INTO channel_facts (channel_id, fact, created_by, updated_by)
+		VALUES ($1, $2, $3, $3)
+		RETURNING id`
+
+	var id int
+	if err := r.db.GetDB().QueryRow(query, channelID, fact, createdBy).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to add channel fact: %w", err)
+	}
+
+	r.logger.Debug("Channel fact added",
+		zap.String("channel_id", channelID),
+		zap.Int("fact_id", id),
+		zap.String("created_by", createdBy))
+	return id, nil
+}
+
+// UpdateChannelFact replaces the text of one of a channel's facts. It
+// reports false if the channel has no fact with that ID.
+func (r *ChannelFactRepository) UpdateChannelFact(channelID string, id int, fact, updatedBy string) (bool, error) {
+	query := `
+		UPDATE channel_facts
+		SET fact = $3, updated_by = $4, updated_at = NOW()
+		WHERE channel_id = $1 AND id = $2`
+
+	result, err := r.db.GetDB().Exec(query, channelID, id, fact, updatedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to update channel fact: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check updated channel fact: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// DeleteChannelFact removes one of a channel's facts. It reports false if
+// the channel has no fact with that ID.
+func (r *ChannelFactRepository) DeleteChannelFact(channelID string, id int) (bool, error) {
+	query := `DELETE FROM channel_facts WHERE channel_id = $1 AND id = $2`
+
+	result, err := r.db.GetDB().Exec(query, channelID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete channel fact: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check deleted channel fact: %w", err)
+	}
+	return rows > 0, nil
+}
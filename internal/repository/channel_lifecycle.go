@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Channel lifecycle states recorded in slack_channels.lifecycle_state
+const (
+	ChannelLifecycleActive   = "active"
+	ChannelLifecycleArchived = "archived"
+	ChannelLifecycleDeleted  = "deleted"
+)
+
+// ErrChannelArchived is returned when a channel that is archived or deleted
+// would be given a session
+var ErrChannelArchived = errors.New("channel is archived or deleted")
+
+// SetChannelLifecycleState records that a channel was archived, unarchived,
+// or deleted
+func (r *SessionRepository) SetChannelLifecycleState(channelID, state string) error {
+	if err := r.EnsureChannel(channelID); err != nil {
+		return err
+	}
+
+	query := `UPDATE slack_channels SET lifecycle_state = $1, lifecycle_changed_at = NOW(), updated_at = NOW() WHERE channel_id = $2`
+	if _, err := r.db.GetDB().Exec(query, state, channelID); err != nil {
+		return fmt.Errorf("failed to update channel lifecycle state: %w", err)
+	}
+	return nil
+}
+
+// GetChannelLifecycleState returns a channel's lifecycle state; channels
+// without a row are active
+func (r *SessionRepository) GetChannelLifecycleState(channelID string) (string, error) {
+	var state string
+	err := r.db.GetDB().QueryRow(`SELECT lifecycle_state FROM slack_channels WHERE channel_id = $1`, channelID).Scan(&state)
+	if err == sql.ErrNoRows {
+		return ChannelLifecycleActive, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get channel lifecycle state: %w", err)
+	}
+	return state, nil
+}
+
+// ListChannelSessionIDs returns the live sessions started in a channel
+func (r *SessionRepository) ListChannelSessionIDs(channelID string) ([]string, error) {
+	rows, err := r.db.GetDB().Query(`SELECT session_id FROM sessions WHERE channel_id = $1 AND deleted_at IS NULL ORDER BY id`, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessionIDs []string
+	for rows.Next() {
+		var sessionID string
+		if err := rows.Scan(&sessionID); err != nil {
+			return nil, fmt.Errorf("failed to scan channel session: %w", err)
+		}
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	return sessionIDs, rows.Err()
+}
+
+// DetachChannelSessions clears a channel's active session, shared and per
+// user, so the next message there starts a new one
+func (r *SessionRepository) DetachChannelSessions(channelID string) error {
+	tx, err := r.db.GetDB().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE slack_channels SET active_session_id = NULL, active_child_session_id = NULL, updated_at = NOW() WHERE channel_id = $1`
+	if _, err := tx.Exec(query, channelID); err != nil {
+		return fmt.Errorf("failed to clear channel state: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM slack_channel_user_sessions WHERE channel_id = $1`, channelID); err != nil {
+		return fmt.Errorf("failed to clear per-user channel state: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+// Channel settings, named after their channel_settings columns
+const (
+	ChannelSettingAutoRespond     = "auto_respond"
+	ChannelSettingModel           = "model"
+	ChannelSettingPermissionMode  = "permission_mode"
+	ChannelSettingReplyInThread   = "reply_in_thread"
+	ChannelSettingFooterTemplate  = "footer_template"
+	ChannelSettingFooterEnv       = "footer_env"
+	ChannelSettingAgents          = "agents"
+	ChannelSettingRunPriority     = "run_priority"
+	ChannelSettingAllowedTools    = "allowed_tools"
+	ChannelSettingDisallowedTools = "disallowed_tools"
+	ChannelSettingToolEvents      = "tool_events"
+	ChannelSettingObserver        = "observer"
+	ChannelSettingMirrorChannel   = "mirror_channel"
+	ChannelSettingMirrorResponses = "mirror_responses"
+	ChannelSettingMirrorPrompts   = "mirror_prompts"
+)
+
+// channelSettingColumns guards the column names interpolated into queries
+var channelSettingColumns = map[string]bool{
+	ChannelSettingAutoRespond:     true,
+	ChannelSettingModel:           true,
+	ChannelSettingPermissionMode:  true,
+	ChannelSettingReplyInThread:   true,
+	ChannelSettingFooterTemplate:  true,
+	ChannelSettingFooterEnv:       true,
+	ChannelSettingAgents:          true,
+	ChannelSettingRunPriority:     true,
+	ChannelSettingAllowedTools:    true,
+	ChannelSettingDisallowedTools: true,
+	ChannelSettingToolEvents:      true,
+	ChannelSettingObserver:        true,
+	ChannelSettingMirrorChannel:   true,
+	ChannelSettingMirrorResponses: true,
+	ChannelSettingMirrorPrompts:   true,
+}
+
+// ChannelSettings are a channel's behavior overrides. Nil fields inherit the
+// bot-wide configuration.
+type ChannelSettings struct {
+	ChannelID       string    `db:"channel_id"`
+	AutoRespond     *bool     `db:"auto_respond"`
+	Model           *string   `db:"model"`
+	PermissionMode  *string   `db:"permission_mode"`
+	ReplyInThread   *bool     `db:"reply_in_thread"`
+	FooterTemplate  *string   `db:"footer_template"`
+	FooterEnv       []string  `db:"footer_env"` // nil = FOOTER_ENV, empty = none
+	Agents          []string  `db:"agents"`     // nil = all configured agents
+	RunPriority     *string   `db:"run_priority"`
+	AllowedTools    []string  `db:"allowed_tools"`    // nil = inherit, empty = all tools
+	DisallowedTools []string  `db:"disallowed_tools"` // nil = inherit
+	ToolEvents      *bool     `db:"tool_events"`
+	Observer        *bool     `db:"observer"`
+	MirrorChannel   *string   `db:"mirror_channel"`
+	MirrorResponses *bool     `db:"mirror_responses"`
+	MirrorPrompts   *bool     `db:"mirror_prompts"`
+	UpdatedBy       *string   `db:"updated_by"`
+	UpdatedAt       time.Time `db:"updated_at"`
+}
+
+type ChannelSettingsRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewChannelSettingsRepository(db *database.Database, logger *zap.Logger) *ChannelSettingsRepository {
+	return &ChannelSettingsRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetChannelSettings returns a channel's settings, or nil if none are stored
+func (r *ChannelSettingsRepository) GetChannelSettings(channelID string) (*ChannelSettings, error) {
+	query := `
+		SELECT channel_id, auto_respond, model, permission_mode, reply_in_thread, footer_template,
+		       footer_env, agents, run_priority, allowed_tools, disallowed_tools, tool_events, observer,
+		       mirror_channel, mirror_responses, mirror_prompts, updated_by, updated_at
+		FROM channel_settings WHERE channel_id = $1`
+
+	settings := &ChannelSettings{}
+	var footerEnv, agents, allowedTools, disallowedTools pq.StringArray
+	err := r.db.GetDB().QueryRow(query, channelID).Scan(&settings.ChannelID, &settings.AutoRespond,
+		&settings.Model, &settings.PermissionMode, &settings.ReplyInThread, &settings.FooterTemplate,
+		&footerEnv, &agents, &settings.RunPriority, &allowedTools, &disallowedTools, &settings.ToolEvents, &settings.Observer, &settings.MirrorChannel,
+		&settings.MirrorResponses, &settings.MirrorPrompts, &settings.UpdatedBy, &settings.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get channel settings: %w", err)
+	}
+
+	// NULL arrays scan as nil and empty ones as empty, which keeps
+	// "inherit" and "all tools" apart
+	if footerEnv != nil {
+		settings.FooterEnv = []string(footerEnv)
+	}
+	if agents != nil {
+		settings.Agents = []string(agents)
+	}
+	if allowedTools != nil {
+		settings.AllowedTools = []string(allowedTools)
+	}
+	if disallowedTools != nil {
+		settings.DisallowedTools = []string(disallowedTools)
+	}
+	return settings, nil
+}
+
+// SetChannelSetting stores one setting for a channel. value is a bool,
+// string, or []string matching the setting's column. A permission mode also
+// becomes the mode the channel reverts to, and its current mode unless a
+// temporary one is in effect.
+func (r *ChannelSettingsRepository) SetChannelSetting(channelID, name string, value interface{}, updatedBy string) error {
+	if !channelSettingColumns[name] {
+		return fmt.Errorf("unknown channel setting %q", name)
+	}
+	if tools, ok := value.([]string); ok {
+		value = pq.Array(tools)
+	}
+
+	tx, err := r.db.GetDB().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		INSERT INTO channel_settings (channel_id, %[1]s, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (channel_id) DO UPDATE
+		SET %[1]s = EXCLUDED.%[1]s, updated_by = EXCLUDED.updated_by, updated_at = NOW()`, name)
+	if _, err := tx.Exec(query, channelID, value, updatedBy); err != nil {
+		return fmt.Errorf("failed to set channel setting: %w", err)
+	}
+
+	if name == ChannelSettingPermissionMode {
+		query := `
+			UPDATE slack_channels
+			SET default_permission = $1,
+			    permission = CASE WHEN permission_expires_at > NOW() THEN permission ELSE $1 END,
+			    permission_expires_at = CASE WHEN permission_expires_at > NOW() THEN permission_expires_at END,
+			    updated_at = NOW()
+			WHERE channel_id = $2`
+		if _, err := tx.Exec(query, value, channelID); err != nil {
+			return fmt.Errorf("failed to update channel permission: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit channel setting: %w", err)
+	}
+
+	r.logger.Info("Channel setting changed",
+		zap.String("channel_id", channelID),
+		zap.String("setting", name),
+		zap.String("updated_by", updatedBy))
+	return nil
+}
+
+// UnsetChannelSetting makes a channel inherit a setting again. It reports
+// false if the setting wasn't set.
+func (r *ChannelSettingsRepository) UnsetChannelSetting(channelID, name, updatedBy string) (bool, error) {
+	if !channelSettingColumns[name] {
+		return false, fmt.Errorf("unknown channel setting %q", name)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE channel_settings SET %[1]s = NULL, updated_by = $2, updated_at = NOW()
+		WHERE channel_id = $1 AND %[1]s IS NOT NULL`, name)
+	result, err := r.db.GetDB().Exec(query, channelID, updatedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to unset channel setting: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check unset channel setting: %w", err)
+	}
+
+	if rows > 0 {
+		r.logger.Info("Channel setting cleared",
+			zap.String("channel_id", channelID),
+			zap.String("setting", name),
+			zap.String("updated_by", updatedBy))
+	}
+	return rows > 0, nil
+}
+
+// ImportChannelSettings stores one setting for the channels an env list
+// names, skipping channels that already set it, and returns how many it
+// stored. Each source is imported once: once recorded in
+// channel_settings_imports it is skipped, so values cleared with /settings
+// unset stay cleared. imported is false if the source was imported before.
+func (r *ChannelSettingsRepository) ImportChannelSettings(source, name string, values map[string]string) (stored int, imported bool, err error) {
+	if !channelSettingColumns[name] {
+		return 0, false, fmt.Errorf("unknown channel setting %q", name)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO channel_settings (channel_id, %[1]s, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (channel_id) DO UPDATE
+		SET %[1]s = EXCLUDED.%[1]s, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		WHERE channel_settings.%[1]s IS NULL`, name)
+	return r.importOnce(source, func(tx *sql.Tx) (int, error) {
+		stored := 0
+		for channelID, value := range values {
+			result, err := tx.Exec(query, channelID, value, source)
+			if err != nil {
+				return 0, fmt.Errorf("failed to import channel setting: %w", err)
+			}
+			if rows, err := result.RowsAffected(); err == nil && rows > 0 {
+				stored++
+			}
+		}
+		return stored, nil
+	})
+}
+
+// GetChannelTypePermissionModes returns the permission modes new channels
+// start in by channel type
+func (r *ChannelSettingsRepository) GetChannelTypePermissionModes() (map[string]string, error) {
+	rows, err := r.db.GetDB().Query(`SELECT channel_type, permission_mode FROM channel_type_settings WHERE permission_mode IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel type settings: %w", err)
+	}
+	defer rows.Close()
+
+	modes := make(map[string]string)
+	for rows.Next() {
+		var channelType, mode string
+		if err := rows.Scan(&channelType, &mode); err != nil {
+			return nil, fmt.Errorf("failed to scan channel type setting: %w", err)
+		}
+		modes[channelType] = mode
+	}
+	return modes, rows.Err()
+}
+
+// GetChannelTypePermissionMode returns the permission mode new channels of a
+// type start in, or "" if it isn't set
+func (r *ChannelSettingsRepository) GetChannelTypePermissionMode(channelType string) (string, error) {
+	var mode sql.NullString
+	err := r.db.GetDB().QueryRow(`SELECT permission_mode FROM channel_type_settings WHERE channel_type = $1`, channelType).Scan(&mode)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get channel type setting: %w", err)
+	}
+	return mode.String, nil
+}
+
+// SetChannelTypePermissionMode sets the permission mode new channels of a
+// type start in; "" clears it. It reports false if clearing found nothing set.
+func (r *ChannelSettingsRepository) SetChannelTypePermissionMode(channelType, mode, updatedBy string) (bool, error) {
+	var value interface{}
+	if mode != "" {
+		value = mode
+	}
+
+	query := `
+		INSERT INTO channel_type_settings (channel_type, permission_mode, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (channel_type) DO UPDATE
+		SET permission_mode = EXCLUDED.permission_mode, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		WHERE EXCLUDED.permission_mode IS NOT NULL OR channel_type_settings.permission_mode IS NOT NULL`
+	result, err := r.db.GetDB().Exec(query, channelType, value, updatedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to set channel type setting: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check channel type setting: %w", err)
+	}
+
+	r.logger.Info("Channel type permission mode changed",
+		zap.String("channel_type", channelType),
+		zap.String("mode", mode),
+		zap.String("updated_by", updatedBy))
+	return rows > 0, nil
+}
+
+// ImportChannelTypePermissionModes stores permission modes from the
+// deprecated CHANNEL_TYPE_PERMISSION_DEFAULTS list for types that don't have
+// one set. Like ImportChannelSettings it runs once per source.
+func (r *ChannelSettingsRepository) ImportChannelTypePermissionModes(source string, modes map[string]string) (stored int, imported bool, err error) {
+	query := `
+		INSERT INTO channel_type_settings (channel_type, permission_mode, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (channel_type) DO UPDATE
+		SET permission_mode = EXCLUDED.permission_mode, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		WHERE channel_type_settings.permission_mode IS NULL`
+	return r.importOnce(source, func(tx *sql.Tx) (int, error) {
+		stored := 0
+		for channelType, mode := range modes {
+			result, err := tx.Exec(query, channelType, mode, source)
+			if err != nil {
+				return 0, fmt.Errorf("failed to import channel type setting: %w", err)
+			}
+			if rows, err := result.RowsAffected(); err == nil && rows > 0 {
+				stored++
+			}
+		}
+		return stored, nil
+	})
+}
+
+// importOnce runs store in a transaction unless source was imported before,
+// and records the import with how many settings store stored
+func (r *ChannelSettingsRepository) importOnce(source string, store func(tx *sql.Tx) (int, error)) (int, bool, error) {
+	tx, err := r.db.GetDB().Begin()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO channel_settings_imports (source) VALUES ($1) ON CONFLICT (source) DO NOTHING`, source)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to record channel settings import: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		return 0, false, err
+	}
+
+	stored, err := store(tx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if _, err := tx.Exec(`UPDATE channel_settings_imports SET imported = $1 WHERE source = $2`, stored, source); err != nil {
+		return 0, false, fmt.Errorf("failed to record channel settings import: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("failed to commit channel settings import: %w", err)
+	}
+	return stored, true, nil
+}
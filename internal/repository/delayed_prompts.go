@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+// Delayed prompt statuses
+const (
+	DelayedPromptPending    = "pending"
+	DelayedPromptDispatched = "dispatched"
+	DelayedPromptCanceled   = "canceled"
+)
+
+type DelayedPrompt struct {
+	ID           int        `db:"id"`
+	ChannelID    string     `db:"channel_id"`
+	UserID       string     `db:"user_id"`
+	Prompt       string     `db:"prompt"`
+	RunAt        time.Time  `db:"run_at"`
+	Status       string     `db:"status"`
+	CreatedAt    time.Time  `db:"created_at"`
+	DispatchedAt *time.Time `db:"dispatched_at"`
+}
+
+type DelayedPromptRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewDelayedPromptRepository(db *database.Database, logger *zap.Logger) *DelayedPromptRepository {
+	return &DelayedPromptRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const delayedPromptColumns = `id, channel_id, user_id, prompt, run_at, status, created_at, dispatched_at`
+
+// ScheduleDelayedPrompt stores a prompt to run at runAt and returns its ID
+func (r *DelayedPromptRepository) ScheduleDelayedPrompt(prompt *DelayedPrompt) (int, error) {
+	query := `
+		INSERT INTO delayed_prompts (channel_id, user_id, prompt, run_at, status, created_at)
+		VALUES ($1, $2, $3, $4, 'pending', NOW())
+		RETURNING id`
+
+	var id int
+	err := r.db.GetDB().QueryRow(query, prompt.ChannelID, prompt.UserID, prompt.Prompt, prompt.RunAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to schedule delayed prompt: %w", err)
+	}
+	return id, nil
+}
+
+// ClaimDueDelayedPrompts marks pending prompts whose time has come as
+// dispatched and returns them, oldest first. Rows are locked while claimed,
+// so each prompt is dispatched by only one instance.
+func (r *DelayedPromptRepository) ClaimDueDelayedPrompts(limit int) ([]*DelayedPrompt, error) {
+	query := `
+		UPDATE delayed_prompts SET status = 'dispatched', dispatched_at = NOW()
+		WHERE id IN (
+			SELECT id FROM delayed_prompts
+			WHERE status = 'pending' AND run_at <= NOW()
+			ORDER BY run_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + delayedPromptColumns
+
+	rows, err := r.db.GetDB().Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due delayed prompts: %w", err)
+	}
+	defer rows.Close()
+
+	prompts, err := scanDelayedPrompts(rows)
+	if err != nil {
+		return nil, err
+	}
+	// RETURNING doesn't keep the subquery's order
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].RunAt.Before(prompts[j].RunAt) })
+	return prompts, nil
+}
+
+// ListPendingDelayedPrompts returns a channel's pending prompts, soonest first
+func (r *DelayedPromptRepository) ListPendingDelayedPrompts(channelID string) ([]*DelayedPrompt, error) {
+	query := `SELECT ` + delayedPromptColumns + ` FROM delayed_prompts
+		WHERE channel_id = $1 AND status = 'pending' ORDER BY run_at, id`
+
+	rows, err := r.db.GetDB().Query(query, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delayed prompts: %w", err)
+	}
+	defer rows.Close()
+
+	return scanDelayedPrompts(rows)
+}
+
+// CancelDelayedPrompt cancels a pending prompt in a channel. It reports
+// whether a prompt was canceled.
+func (r *DelayedPromptRepository) CancelDelayedPrompt(id int, channelID string) (bool, error) {
+	query := `UPDATE delayed_prompts SET status = 'canceled' WHERE id = $1 AND channel_id = $2 AND status = 'pending'`
+
+	result, err := r.db.GetDB().Exec(query, id, channelID)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel delayed prompt: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel delayed prompt: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// CancelChannelDelayedPrompts cancels every pending prompt in a channel,
+// returning how many were canceled
+func (r *DelayedPromptRepository) CancelChannelDelayedPrompts(channelID string) (int, error) {
+	query := `UPDATE delayed_prompts SET status = 'canceled' WHERE channel_id = $1 AND status = 'pending'`
+
+	result, err := r.db.GetDB().Exec(query, channelID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel channel delayed prompts: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel channel delayed prompts: %w", err)
+	}
+	return int(affected), nil
+}
+
+// scanDelayedPrompts scans rows selected with delayedPromptColumns
+func scanDelayedPrompts(rows interface {
+	Next() bool
+	Scan(...interface{}) error
+	Err() error
+}) ([]*DelayedPrompt, error) {
+	var prompts []*DelayedPrompt
+	for rows.Next() {
+		p := &DelayedPrompt{}
+		if err := rows.Scan(&p.ID, &p.ChannelID, &p.UserID, &p.Prompt,
+			&p.RunAt, &p.Status, &p.CreatedAt, &p.DispatchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan delayed prompt: %w", err)
+		}
+		prompts = append(prompts, p)
+	}
+	return prompts, rows.Err()
+}
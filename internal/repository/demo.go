@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type Demo struct {
+	ID              int       `db:"id"`
+	Name            string    `db:"name"`
+	SourceChannelID string    `db:"source_channel_id"`
+	CreatedBy       string    `db:"created_by"`
+	Recording       bool      `db:"recording"`
+	CreatedAt       time.Time `db:"created_at"`
+	Steps           int       `db:"-"` // Number of recorded steps
+}
+
+type DemoStep struct {
+	Position   int    `db:"position"`
+	Prompt     string `db:"prompt"`
+	Response   string `db:"response"`
+	DurationMS int    `db:"duration_ms"`
+}
+
+type DemoRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewDemoRepository(db *database.Database, logger *zap.Logger) *DemoRepository {
+	return &DemoRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const demoColumns = `d.id, d.name, d.source_channel_id, d.created_by, d.recording, d.created_at,
+	(SELECT COUNT(*) FROM demo_steps s WHERE s.demo_id = d.id)`
+
+// CreateDemo starts recording a demo in a channel. It returns false if the
+// name is taken or the channel is already recording.
+func (r *DemoRepository) CreateDemo(name, channelID, userID string) (bool, error) {
+	query := `
+		INSERT INTO demos (name, source_channel_id, created_by, recording, created_at)
+		VALUES ($1, $2, $3, TRUE, NOW())
+		ON CONFLICT DO NOTHING`
+
+	result, err := r.db.GetDB().Exec(query, name, channelID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to create demo: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to create demo: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetRecordingDemo returns the demo being recorded in a channel, or nil
+func (r *DemoRepository) GetRecordingDemo(channelID string) (*Demo, error) {
+	query := `SELECT ` + demoColumns + ` FROM demos d WHERE d.source_channel_id = $1 AND d.recording`
+
+	demo, err := scanDemo(r.db.GetDB().QueryRow(query, channelID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recording demo: %w", err)
+	}
+	return demo, nil
+}
+
+// StopDemoRecording ends the recording in a channel and returns the demo, or
+// nil if the channel wasn't recording
+func (r *DemoRepository) StopDemoRecording(channelID string) (*Demo, error) {
+	query := `
+		UPDATE demos d SET recording = FALSE
+		WHERE d.source_channel_id = $1 AND d.recording
+		RETURNING ` + demoColumns
+
+	demo, err := scanDemo(r.db.GetDB().QueryRow(query, channelID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stop demo recording: %w", err)
+	}
+	return demo, nil
+}
+
+// AppendDemoStep adds an exchange to the end of a demo
+func (r *DemoRepository) AppendDemoStep(demoID int, prompt, response string, durationMS int) error {
+	query := `
+		INSERT INTO demo_steps (demo_id, position, prompt, response, duration_ms, created_at)
+		SELECT $1, COALESCE(MAX(position), 0) + 1, $2, $3, $4, NOW()
+		FROM demo_steps WHERE demo_id = $1`
+
+	if _, err := r.db.GetDB().Exec(query, demoID, prompt, response, durationMS); err != nil {
+		return fmt.Errorf("failed to append demo step: %w", err)
+	}
+	return nil
+}
+
+// GetDemo returns a demo by name, or nil if it doesn't exist
+func (r *DemoRepository) GetDemo(name string) (*Demo, error) {
+	query := `SELECT ` + demoColumns + ` FROM demos d WHERE d.name = $1`
+
+	demo, err := scanDemo(r.db.GetDB().QueryRow(query, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get demo: %w", err)
+	}
+	return demo, nil
+}
+
+// ListDemos returns all demos, newest first
+func (r *DemoRepository) ListDemos() ([]*Demo, error) {
+	query := `SELECT ` + demoColumns + ` FROM demos d ORDER BY d.created_at DESC`
+
+	rows, err := r.db.GetDB().Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list demos: %w", err)
+	}
+	defer rows.Close()
+
+	var demos []*Demo
+	for rows.Next() {
+		demo, err := scanDemo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan demo: %w", err)
+		}
+		demos = append(demos, demo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list demos: %w", err)
+	}
+	return demos, nil
+}
+
+// GetDemoSteps returns a demo's steps in order
+func (r *DemoRepository) GetDemoSteps(demoID int) ([]DemoStep, error) {
+	query := `
+		SELECT position, prompt, response, duration_ms
+		FROM demo_steps WHERE demo_id = $1
+		ORDER BY position`
+
+	rows, err := r.db.GetDB().Query(query, demoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get demo steps: %w", err)
+	}
+	defer rows.Close()
+
+	var steps []DemoStep
+	for rows.Next() {
+		var step DemoStep
+		if err := rows.Scan(&step.Position, &step.Prompt, &step.Response, &step.DurationMS); err != nil {
+			return nil, fmt.Errorf("failed to scan demo step: %w", err)
+		}
+		steps = append(steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get demo steps: %w", err)
+	}
+	return steps, nil
+}
+
+// DeleteDemo removes a demo and its steps; it returns false if none matched
+func (r *DemoRepository) DeleteDemo(name string) (bool, error) {
+	result, err := r.db.GetDB().Exec(`DELETE FROM demos WHERE name = $1`, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete demo: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete demo: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func scanDemo(row interface{ Scan(...interface{}) error }) (*Demo, error) {
+	var demo Demo
+	err := row.Scan(&demo.ID, &demo.Name, &demo.SourceChannelID, &demo.CreatedBy, &demo.Recording, &demo.CreatedAt, &demo.Steps)
+	if err != nil {
+		return nil, err
+	}
+	return &demo, nil
+}
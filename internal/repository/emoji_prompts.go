@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type EmojiPrompt struct {
+	Emoji     string    `db:"emoji"`
+	Template  string    `db:"template"`
+	UpdatedBy *string   `db:"updated_by"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type EmojiPromptRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewEmojiPromptRepository(db *database.Database, logger *zap.Logger) *EmojiPromptRepository {
+	return &EmojiPromptRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetEmojiPrompt returns the prompt mapped to an emoji, or nil if there is none
+func (r *EmojiPromptRepository) GetEmojiPrompt(emoji string) (*EmojiPrompt, error) {
+	query := `SELECT emoji, template, updated_by, updated_at FROM emoji_prompts WHERE emoji = $1`
+
+	var p EmojiPrompt
+	err := r.db.GetDB().QueryRow(query, emoji).Scan(&p.Emoji, &p.Template, &p.UpdatedBy, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emoji prompt: %w", err)
+	}
+	return &p, nil
+}
+
+// ListEmojiPrompts returns every emoji mapping, by emoji
+func (r *EmojiPromptRepository) ListEmojiPrompts() ([]*EmojiPrompt, error) {
+	rows, err := r.db.GetDB().Query(`SELECT emoji, template, updated_by, updated_at FROM emoji_prompts ORDER BY emoji`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list emoji prompts: %w", err)
+	}
+	defer rows.Close()
+
+	var prompts []*EmojiPrompt
+	for rows.Next() {
+		var p EmojiPrompt
+		if err := rows.Scan(&p.Emoji, &p.Template, &p.UpdatedBy, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan emoji prompt: %w", err)
+		}
+		prompts = append(prompts, &p)
+	}
+	return prompts, rows.Err()
+}
+
+// SetEmojiPrompt creates or replaces the prompt mapped to an emoji
+func (r *EmojiPromptRepository) SetEmojiPrompt(emoji, template, updatedBy string) error {
+	query := `
+		INSERT INTO emoji_prompts (emoji, template, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (emoji) DO UPDATE
+		SET template = EXCLUDED.template, updated_by = EXCLUDED.updated_by, updated_at = NOW()`
+
+	if _, err := r.db.GetDB().Exec(query, emoji, template, updatedBy); err != nil {
+		return fmt.Errorf("failed to set emoji prompt: %w", err)
+	}
+
+	r.logger.Info("Emoji prompt set",
+		zap.String("emoji", emoji),
+		zap.String("updated_by", updatedBy))
+	return nil
+}
+
+// DeleteEmojiPrompt removes an emoji mapping. It reports false if there was
+// none.
+func (r *EmojiPromptRepository) DeleteEmojiPrompt(emoji string) (bool, error) {
+	result, err := r.db.GetDB().Exec(`DELETE FROM emoji_prompts WHERE emoji = $1`, emoji)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete emoji prompt: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete emoji prompt: %w", err)
+	}
+	return rows > 0, nil
+}
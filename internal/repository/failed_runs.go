@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type FailedRun struct {
+	ID               int        `db:"id"`
+	ChannelID        string     `db:"channel_id"`
+	UserID           string     `db:"user_id"`
+	ThreadTS         *string    `db:"thread_ts"`
+	SessionID        *string    `db:"session_id"`
+	WorkingDirectory *string    `db:"working_directory"`
+	Prompt           string     `db:"prompt"`
+	FileIDs          []string   `db:"file_ids"`
+	ErrorKind        string     `db:"error_kind"`
+	ErrorMessage     string     `db:"error_message"`
+	Stderr           *string    `db:"stderr"`
+	RetryCount       int        `db:"retry_count"`
+	RetriedAt        *time.Time `db:"retried_at"`
+	RetriedBy        *string    `db:"retried_by"`
+	CreatedAt        time.Time  `db:"created_at"`
+}
+
+type FailedRunRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewFailedRunRepository(db *database.Database, logger *zap.Logger) *FailedRunRepository {
+	return &FailedRunRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const failedRunColumns = `id, channel_id, user_id, thread_ts, session_id, working_directory, prompt,
+	file_ids, error_kind, error_message, stderr, retry_count, retried_at, retried_by, created_at`
+
+// RecordFailedRun stores a failed run and returns its ID
+func (r *FailedRunRepository) RecordFailedRun(run *FailedRun) (int, error) {
+	query := `
+		INSERT INTO failed_runs (channel_id, user_id, thread_ts, session_id, working_directory,
+			prompt, file_ids, error_kind, error_message, stderr, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		RETURNING id`
+
+	fileIDs := run.FileIDs
+	if fileIDs == nil {
+		fileIDs = []string{}
+	}
+
+	var id int
+	err := r.db.GetDB().QueryRow(query, run.ChannelID, run.UserID, run.ThreadTS, run.SessionID,
+		run.WorkingDirectory, run.Prompt, pq.Array(fileIDs), run.ErrorKind, run.ErrorMessage, run.Stderr).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record failed run: %w", err)
+	}
+	return id, nil
+}
+
+// ListFailedRuns returns the most recent failed runs, newest first
+func (r *FailedRunRepository) ListFailedRuns(limit int) ([]*FailedRun, error) {
+	query := `SELECT ` + failedRunColumns + ` FROM failed_runs ORDER BY created_at DESC, id DESC LIMIT $1`
+
+	rows, err := r.db.GetDB().Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*FailedRun
+	for rows.Next() {
+		run, err := scanFailedRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// GetFailedRun returns a failed run by ID, or nil if there is none
+func (r *FailedRunRepository) GetFailedRun(id int) (*FailedRun, error) {
+	query := `SELECT ` + failedRunColumns + ` FROM failed_runs WHERE id = $1`
+
+	run, err := scanFailedRun(r.db.GetDB().QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return run, err
+}
+
+// MarkFailedRunRetried records that a failed run was replayed
+func (r *FailedRunRepository) MarkFailedRunRetried(id int, userID string) error {
+	query := `UPDATE failed_runs SET retry_count = retry_count + 1, retried_at = NOW(), retried_by = $1 WHERE id = $2`
+
+	if _, err := r.db.GetDB().Exec(query, userID, id); err != nil {
+		return fmt.Errorf("failed to mark failed run retried: %w", err)
+	}
+	return nil
+}
+
+// CountFailedRunsSince counts a channel's failed runs recorded since a time
+func (r *FailedRunRepository) CountFailedRunsSince(channelID string, since time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM failed_runs WHERE channel_id = $1 AND created_at >= $2`
+	if err := r.db.GetDB().QueryRow(query, channelID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count failed runs: %w", err)
+	}
+	return count, nil
+}
+
+// scanFailedRun scans a row selected with failedRunColumns
+func scanFailedRun(row interface{ Scan(...interface{}) error }) (*FailedRun, error) {
+	run := &FailedRun{}
+	err := row.Scan(&run.ID, &run.ChannelID, &run.UserID, &run.ThreadTS, &run.SessionID,
+		&run.WorkingDirectory, &run.Prompt, pq.Array(&run.FileIDs), &run.ErrorKind, &run.ErrorMessage, &run.Stderr,
+		&run.RetryCount, &run.RetriedAt, &run.RetriedBy, &run.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan failed run: %w", err)
+	}
+	return run, nil
+}
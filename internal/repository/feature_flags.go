@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type FeatureFlag struct {
+	Name           string          `db:"name"`
+	RolloutPercent int             `db:"rollout_percent"`
+	Channels       map[string]bool // Channel ID -> override
+	UpdatedBy      *string         `db:"updated_by"`
+	UpdatedAt      time.Time       `db:"updated_at"`
+}
+
+type FeatureFlagRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewFeatureFlagRepository(db *database.Database, logger *zap.Logger) *FeatureFlagRepository {
+	return &FeatureFlagRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListFeatureFlags returns every flag with its channel overrides, by name
+func (r *FeatureFlagRepository) ListFeatureFlags() ([]*FeatureFlag, error) {
+	rows, err := r.db.GetDB().Query(`SELECT name, rollout_percent, updated_by, updated_at FROM feature_flags ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	var flags []*FeatureFlag
+	byName := make(map[string]*FeatureFlag)
+	for rows.Next() {
+		flag := &FeatureFlag{Channels: make(map[string]bool)}
+		if err := rows.Scan(&flag.Name, &flag.RolloutPercent, &flag.UpdatedBy, &flag.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag: %w", err)
+		}
+		flags = append(flags, flag)
+		byName[flag.Name] = flag
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+
+	overrides, err := r.db.GetDB().Query(`SELECT flag_name, channel_id, enabled FROM feature_flag_channels`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flag overrides: %w", err)
+	}
+	defer overrides.Close()
+
+	for overrides.Next() {
+		var name, channelID string
+		var enabled bool
+		if err := overrides.Scan(&name, &channelID, &enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag override: %w", err)
+		}
+		if flag, ok := byName[name]; ok {
+			flag.Channels[channelID] = enabled
+		}
+	}
+	if err := overrides.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list feature flag overrides: %w", err)
+	}
+
+	return flags, nil
+}
+
+// SetFeatureFlagRollout creates a flag or changes the share of channels it is on for
+func (r *FeatureFlagRepository) SetFeatureFlagRollout(name string, percent int, updatedBy string) error {
+	query := `
+		INSERT INTO feature_flags (name, rollout_percent, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (name) DO UPDATE SET rollout_percent = $2, updated_by = $3, updated_at = NOW()`
+
+	if _, err := r.db.GetDB().Exec(query, name, percent, updatedBy); err != nil {
+		return fmt.Errorf("failed to update feature flag %s: %w", name, err)
+	}
+
+	r.logger.Info("Feature flag rollout updated",
+		zap.String("flag", name),
+		zap.Int("rollout_percent", percent),
+		zap.String("updated_by", updatedBy))
+	return nil
+}
+
+// SetFeatureFlagChannel turns a flag on or off in one channel, creating the
+// flag at 0% rollout if needed. A nil enabled removes the override.
+func (r *FeatureFlagRepository) SetFeatureFlagChannel(name, channelID string, enabled *bool, updatedBy string) error {
+	tx, err := r.db.GetDB().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if enabled == nil {
+		if _, err := tx.Exec(`DELETE FROM feature_flag_channels WHERE flag_name = $1 AND channel_id = $2`, name, channelID); err != nil {
+			return fmt.Errorf("failed to remove feature flag override: %w", err)
+		}
+	} else {
+		if _, err := tx.Exec(`
+			INSERT INTO feature_flags (name, updated_by, updated_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT (name) DO NOTHING`, name, updatedBy); err != nil {
+			return fmt.Errorf("failed to create feature flag %s: %w", name, err)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO feature_flag_channels (flag_name, channel_id, enabled, updated_by, updated_at)
+			VALUES ($1, $2, $3, $4, NOW())
+			ON CONFLICT (flag_name, channel_id) DO UPDATE SET enabled = $3, updated_by = $4, updated_at = NOW()`,
+			name, channelID, *enabled, updatedBy); err != nil {
+			return fmt.Errorf("failed to set feature flag override: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit feature flag override: %w", err)
+	}
+
+	r.logger.Info("Feature flag override updated",
+		zap.String("flag", name),
+		zap.String("channel_id", channelID),
+		zap.Any("enabled", enabled),
+		zap.String("updated_by", updatedBy))
+	return nil
+}
+
+// DeleteFeatureFlag removes a flag and its overrides, reporting whether it existed
+func (r *FeatureFlagRepository) DeleteFeatureFlag(name string) (bool, error) {
+	result, err := r.db.GetDB().Exec(`DELETE FROM feature_flags WHERE name = $1`, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete feature flag %s: %w", name, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete feature flag %s: %w", name, err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type ChannelGuardrails struct {
+	ChannelID  string    `db:"channel_id"`
+	Guardrails string    `db:"guardrails"`
+	UpdatedBy  *string   `db:"updated_by"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+type GuardrailRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewGuardrailRepository(db *database.Database, logger *zap.Logger) *GuardrailRepository {
+	return &GuardrailRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetChannelGuardrails returns a channel's guardrails, or nil if none are set
+func (r *GuardrailRepository) GetChannelGuardrails(channelID string) (*ChannelGuardrails, error) {
+	query := `SELECT channel_id, guardrails, updated_by, updated_at FROM channel_guardrails WHERE channel_id = $1`
+
+	var g ChannelGuardrails
+	err := r.db.GetDB().QueryRow(query, channelID).Scan(&g.ChannelID, &g.Guardrails, &g.UpdatedBy, &g.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel guardrails: %w", err)
+	}
+	return &g, nil
+}
+
+// SetChannelGuardrails creates or replaces a channel's guardrails
+func (r *GuardrailRepository) SetChannelGuardrails(channelID, guardrails, updatedBy string) error {
+	query := `
+		INSERT INTO channel_guardrails (channel_id, guardrails, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (channel_id) DO UPDATE
+		SET guardrails = EXCLUDED.guardrails, updated_by = EXCLUDED.updated_by, updated_at = NOW()`
+
+	if _, err := r.db.GetDB().Exec(query, channelID, guardrails, updatedBy); err != nil {
+		return fmt.Errorf("failed to set channel guardrails: %w", err)
+	}
+
+	r.logger.Info("Channel guardrails set",
+		zap.String("channel_id", channelID),
+		zap.Int("length", len(guardrails)),
+		zap.String("updated_by", updatedBy))
+	return nil
+}
+
+// ClearChannelGuardrails removes a channel's guardrails. It reports false if
+// none were set.
+func (r *GuardrailRepository) ClearChannelGuardrails(channelID string) (bool, error) {
+	result, err := r.db.GetDB().Exec(`DELETE FROM channel_guardrails WHERE channel_id = $1`, channelID)
+	if err != nil {
+		return false, fmt.Errorf("failed to clear channel guardrails: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to clear channel guardrails: %w", err)
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+// Outbox message statuses
+const (
+	OutboxPending   = "pending"
+	OutboxDelivered = "delivered"
+	OutboxAbandoned = "abandoned"
+)
+
+type OutboxMessage struct {
+	ID            int        `db:"id"`
+	ChannelID     string     `db:"channel_id"`
+	ThreadTS      string     `db:"thread_ts"`
+	Text          string     `db:"text"`
+	Status        string     `db:"status"`
+	Attempts      int        `db:"attempts"`
+	LastError     string     `db:"last_error"`
+	NextAttemptAt time.Time  `db:"next_attempt_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+	SettledAt     *time.Time `db:"settled_at"`
+	MessageTS     *string    `db:"message_ts"`
+}
+
+type OutboxRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewOutboxRepository(db *database.Database, logger *zap.Logger) *OutboxRepository {
+	return &OutboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const outboxColumns = `id, channel_id, thread_ts, text, status, attempts, last_error, next_attempt_at, created_at, settled_at, message_ts`
+
+// EnqueueOutboxMessage stores a message that failed to post, to be retried
+// at msg.NextAttemptAt, and returns its ID
+func (r *OutboxRepository) EnqueueOutboxMessage(msg *OutboxMessage) (int, error) {
+	query := `
+		INSERT INTO outbox (channel_id, thread_ts, text, status, attempts, last_error, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, 'pending', $4, $5, $6, NOW())
+		RETURNING id`
+
+	var id int
+	err := r.db.GetDB().QueryRow(query, msg.ChannelID, msg.ThreadTS, msg.Text, msg.Attempts, msg.LastError, msg.NextAttemptAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue outbox message: %w", err)
+	}
+	return id, nil
+}
+
+// ClaimDueOutboxMessages returns pending messages due for delivery, oldest
+// first, and pushes their next attempt back by lease so no other instance
+// delivers them meanwhile. Only the oldest pending message of each channel
+// is claimed, so a channel's messages arrive in the order they were written.
+func (r *OutboxRepository) ClaimDueOutboxMessages(limit int, lease time.Duration) ([]*OutboxMessage, error) {
+	query := `
+		UPDATE outbox SET attempts = attempts + 1, next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM outbox o
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			  AND NOT EXISTS (
+				SELECT 1 FROM outbox earlier
+				WHERE earlier.channel_id = o.channel_id AND earlier.status = 'pending' AND earlier.id < o.id
+			  )
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+
+	rows, err := r.db.GetDB().Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*OutboxMessage
+	for rows.Next() {
+		m := &OutboxMessage{}
+		if err := rows.Scan(&m.ID, &m.ChannelID, &m.ThreadTS, &m.Text, &m.Status, &m.Attempts,
+			&m.LastError, &m.NextAttemptAt, &m.CreatedAt, &m.SettledAt, &m.MessageTS); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// MarkOutboxMessageDelivered records that Slack accepted a message. It
+// reports whether a pending message was marked.
+func (r *OutboxRepository) MarkOutboxMessageDelivered(id int, messageTS string) (bool, error) {
+	query := `UPDATE outbox SET status = 'delivered', settled_at = NOW(), message_ts = $2 WHERE id = $1 AND status = 'pending'`
+	return r.update("mark outbox message delivered", query, id, messageTS)
+}
+
+// RescheduleOutboxMessage records a failed delivery and when to try again
+func (r *OutboxRepository) RescheduleOutboxMessage(id int, lastError string, nextAttemptAt time.Time) (bool, error) {
+	query := `UPDATE outbox SET last_error = $2, next_attempt_at = $3 WHERE id = $1 AND status = 'pending'`
+	return r.update("reschedule outbox message", query, id, lastError, nextAttemptAt)
+}
+
+// AbandonOutboxMessage gives up on delivering a message
+func (r *OutboxRepository) AbandonOutboxMessage(id int, lastError string) (bool, error) {
+	query := `UPDATE outbox SET status = 'abandoned', settled_at = NOW(), last_error = $2 WHERE id = $1 AND status = 'pending'`
+	return r.update("abandon outbox message", query, id, lastError)
+}
+
+// CountPendingOutboxMessages returns how many messages are waiting for delivery
+func (r *OutboxRepository) CountPendingOutboxMessages() (int, error) {
+	var count int
+	if err := r.db.GetDB().QueryRow(`SELECT COUNT(*) FROM outbox WHERE status = 'pending'`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count pending outbox messages: %w", err)
+	}
+	return count, nil
+}
+
+// PurgeSettledOutboxMessages deletes delivered and abandoned messages settled
+// before the cutoff, returning how many were deleted
+func (r *OutboxRepository) PurgeSettledOutboxMessages(before time.Time) (int, error) {
+	result, err := r.db.GetDB().Exec(`DELETE FROM outbox WHERE status <> 'pending' AND settled_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge settled outbox messages: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge settled outbox messages: %w", err)
+	}
+	return int(affected), nil
+}
+
+func (r *OutboxRepository) update(action, query string, args ...interface{}) (bool, error) {
+	result, err := r.db.GetDB().Exec(query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to %s: %w", action, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to %s: %w", action, err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type PolicyAcknowledgmentRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewPolicyAcknowledgmentRepository(db *database.Database, logger *zap.Logger) *PolicyAcknowledgmentRepository {
+	return &PolicyAcknowledgmentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// HasAcknowledgedPolicy reports whether a user agreed to a policy version
+func (r *PolicyAcknowledgmentRepository) HasAcknowledgedPolicy(userID, version string) (bool, error) {
+	query := `SELECT 1 FROM policy_acknowledgments WHERE user_id = $1 AND policy_version = $2`
+
+	var found int
+	err := r.db.GetDB().QueryRow(query, userID, version).Scan(&found)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check policy acknowledgment: %w", err)
+	}
+	return true, nil
+}
+
+// RecordPolicyAcknowledgment stores that a user agreed to a policy version.
+// Agreeing again keeps the original record.
+func (r *PolicyAcknowledgmentRepository) RecordPolicyAcknowledgment(userID, version, channelID string) error {
+	query := `
+		INSERT INTO policy_acknowledgments (user_id, policy_version, channel_id, acknowledged_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, policy_version) DO NOTHING`
+
+	if _, err := r.db.GetDB().Exec(query, userID, version, channelID); err != nil {
+		return fmt.Errorf("failed to record policy acknowledgment: %w", err)
+	}
+
+	r.logger.Info("Usage policy acknowledged",
+		zap.String("user_id", userID),
+		zap.String("policy_version", version),
+		zap.String("channel_id", channelID))
+	return nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type UserPreferences struct {
+	UserID             string    `db:"user_id"`
+	NotifyOnCompletion bool      `db:"notify_on_completion"`
+	CreatedAt          time.Time `db:"created_at"`
+	UpdatedAt          time.Time `db:"updated_at"`
+}
+
+// Response preference scopes
+const (
+	PreferenceScopeUser    = "user"
+	PreferenceScopeChannel = "channel"
+)
+
+type ResponsePreference struct {
+	Scope     string    `db:"scope"`
+	ScopeID   string    `db:"scope_id"`
+	Name      string    `db:"name"`
+	Value     string    `db:"value"`
+	UpdatedBy *string   `db:"updated_by"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type PreferencesRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewPreferencesRepository(db *database.Database, logger *zap.Logger) *PreferencesRepository {
+	return &PreferencesRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetUserPreferences returns a user's preferences, or nil if none are stored
+func (r *PreferencesRepository) GetUserPreferences(userID string) (*UserPreferences, error) {
+	query := `SELECT user_id, notify_on_completion, created_at, updated_at FROM user_preferences WHERE user_id = $1`
+
+	prefs := &UserPreferences{}
+	err := r.db.GetDB().QueryRow(query, userID).Scan(&prefs.UserID, &prefs.NotifyOnCompletion,
+		&prefs.CreatedAt, &prefs.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// SetNotifyOnCompletion stores whether a user is DMed when their long runs finish
+func (r *PreferencesRepository) SetNotifyOnCompletion(userID string, enabled bool) error {
+	query := `
+		INSERT INTO user_preferences (user_id, notify_on_completion, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE SET notify_on_completion = $2, updated_at = NOW()`
+
+	if _, err := r.db.GetDB().Exec(query, userID, enabled); err != nil {
+		return fmt.Errorf("failed to update notification preference: %w", err)
+	}
+
+	r.logger.Debug("Notification preference updated",
+		zap.String("user_id", userID),
+		zap.Bool("enabled", enabled))
+
+	return nil
+}
+
+// SetResponsePreference creates or replaces a user or channel response preference
+func (r *PreferencesRepository) SetResponsePreference(scope, scopeID, name, value, updatedBy string) error {
+	query := `
+		INSERT INTO response_preferences (scope, scope_id, name, value, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (scope, scope_id, name) DO UPDATE
+		SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = NOW()`
+
+	if _, err := r.db.GetDB().Exec(query, scope, scopeID, name, value, updatedBy); err != nil {
+		return fmt.Errorf("failed to set response preference: %w", err)
+	}
+
+	r.logger.Debug("Response preference set",
+		zap.String("scope", scope),
+		zap.String("scope_id", scopeID),
+		zap.String("name", name),
+		zap.String("updated_by", updatedBy))
+	return nil
+}
+
+// UnsetResponsePreference removes a response preference. It reports false if
+// it wasn't set.
+func (r *PreferencesRepository) UnsetResponsePreference(scope, scopeID, name string) (bool, error) {
+	query := `DELETE FROM response_preferences WHERE scope = $1 AND scope_id = $2 AND name = $3`
+
+	result, err := r.db.GetDB().Exec(query, scope, scopeID, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to unset response preference: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check unset response preference: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetResponsePreferences returns a user's preferences and those of a channel
+func (r *PreferencesRepository) GetResponsePreferences(userID, channelID string) ([]*ResponsePreference, error) {
+	query := `
+		SELECT scope, scope_id, name, value, updated_by, updated_at
+		FROM response_preferences
+		WHERE (scope = 'user' AND scope_id = $1) OR (scope = 'channel' AND scope_id = $2)
+		ORDER BY name, scope`
+
+	rows, err := r.db.GetDB().Query(query, userID, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get response preferences: %w", err)
+	}
+	defer rows.Close()
+
+	var prefs []*ResponsePreference
+	for rows.Next() {
+		pref := &ResponsePreference{}
+		if err := rows.Scan(&pref.Scope, &pref.ScopeID, &pref.Name, &pref.Value, &pref.UpdatedBy, &pref.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan response preference: %w", err)
+		}
+		prefs = append(prefs, pref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get response preferences: %w", err)
+	}
+	return prefs, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+// Prompt template scopes
+const (
+	TemplateScopeUser    = "user"
+	TemplateScopeChannel = "channel"
+)
+
+type PromptTemplate struct {
+	Scope     string    `db:"scope"`
+	ScopeID   string    `db:"scope_id"`
+	Name      string    `db:"name"`
+	Template  string    `db:"template"`
+	UpdatedBy *string   `db:"updated_by"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type PromptTemplateRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewPromptTemplateRepository(db *database.Database, logger *zap.Logger) *PromptTemplateRepository {
+	return &PromptTemplateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetPromptTemplate returns the template a user runs by name in a channel:
+// their own if they have one, otherwise the channel's, or nil if neither
+// exists
+func (r *PromptTemplateRepository) GetPromptTemplate(userID, channelID, name string) (*PromptTemplate, error) {
+	templates, err := r.queryPromptTemplates(`
+		SELECT scope, scope_id, name, template, updated_by, updated_at
+		FROM prompt_templates
+		WHERE name = $3 AND ((scope = 'user' AND scope_id = $1) OR (scope = 'channel' AND scope_id = $2))
+		ORDER BY scope DESC
+		LIMIT 1`, userID, channelID, name)
+	if err != nil || len(templates) == 0 {
+		return nil, err
+	}
+	return templates[0], nil
+}
+
+// ListPromptTemplates returns a user's templates and those of a channel, by
+// name
+func (r *PromptTemplateRepository) ListPromptTemplates(userID, channelID string) ([]*PromptTemplate, error) {
+	return r.queryPromptTemplates(`
+		SELECT scope, scope_id, name, template, updated_by, updated_at
+		FROM prompt_templates
+		WHERE (scope = 'user' AND scope_id = $1) OR (scope = 'channel' AND scope_id = $2)
+		ORDER BY name, scope`, userID, channelID)
+}
+
+// SavePromptTemplate creates or replaces a user or channel template
+func (r *PromptTemplateRepository) SavePromptTemplate(scope, scopeID, name, template, updatedBy string) error {
+	query := `
+		INSERT INTO prompt_templates (scope, scope_id, name, template, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (scope, scope_id, name) DO UPDATE
+		SET template = EXCLUDED.template, updated_by = EXCLUDED.updated_by, updated_at = NOW()`
+
+	if _, err := r.db.GetDB().Exec(query, scope, scopeID, name, template, updatedBy); err != nil {
+		return fmt.Errorf("failed to save prompt template: %w", err)
+	}
+
+	r.logger.Debug("Prompt template saved",
+		zap.String("scope", scope),
+		zap.String("scope_id", scopeID),
+		zap.String("name", name),
+		zap.String("updated_by", updatedBy))
+	return nil
+}
+
+// DeletePromptTemplate removes a template. It reports false if there was
+// none.
+func (r *PromptTemplateRepository) DeletePromptTemplate(scope, scopeID, name string) (bool, error) {
+	query := `DELETE FROM prompt_templates WHERE scope = $1 AND scope_id = $2 AND name = $3`
+
+	result, err := r.db.GetDB().Exec(query, scope, scopeID, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete prompt template: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check deleted prompt template: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *PromptTemplateRepository) queryPromptTemplates(query string, args ...interface{}) ([]*PromptTemplate, error) {
+	rows, err := r.db.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*PromptTemplate
+	for rows.Next() {
+		t := &PromptTemplate{}
+		if err := rows.Scan(&t.Scope, &t.ScopeID, &t.Name, &t.Template, &t.UpdatedBy, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan prompt template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get prompt templates: %w", err)
+	}
+	return templates, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+// SearchResult is the best matching exchange of a root session
+type SearchResult struct {
+	SessionDBID      int       `db:"session_db_id"`
+	SessionID        string    `db:"session_id"`
+	WorkingDirectory string    `db:"working_directory"`
+	ChannelID        *string   `db:"channel_id"`
+	ChildSessionID   string    `db:"child_session_id"`
+	Snippet          string    `db:"snippet"`
+	Rank             float64   `db:"rank"`
+	CreatedAt        time.Time `db:"created_at"`
+}
+
+type SearchRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewSearchRepository(db *database.Database, logger *zap.Logger) *SearchRepository {
+	return &SearchRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SearchConversations full-text searches prompts, responses and summaries,
+// returning the best matching exchange per session. A nil channelIDs slice
+// searches every session; otherwise only sessions created in those channels match.
+func (r *SearchRepository) SearchConversations(query string, channelIDs []string, limit int) ([]*SearchResult, error) {
+	sqlQuery := `
+		SELECT session_db_id, session_id, working_directory, channel_id, child_session_id, snippet, rank, created_at
+		FROM (
+			SELECT DISTINCT ON (s.id)
+				s.id AS session_db_id, s.session_id, s.working_directory, s.channel_id,
+				cs.session_id AS child_session_id,
+				ts_headline('english',
+					COALESCE(cs.summary, '') || ' ' || COALESCE(cs.user_prompt, '') || ' ' || COALESCE(cs.ai_response, ''),
+					q, 'StartSel=*, StopSel=*, MaxWords=30, MinWords=10, MaxFragments=2') AS snippet,
+				ts_rank(cs.search_vector, q) AS rank,
+				cs.created_at
+			FROM child_sessions cs
+			JOIN sessions s ON s.id = cs.root_parent_id,
+				websearch_to_tsquery('english', $1) q
+			WHERE cs.search_vector @@ q
+			  AND s.deleted_at IS NULL
+			  AND ($2::text[] IS NULL OR s.channel_id = ANY($2))
+			ORDER BY s.id, rank DESC, cs.created_at DESC
+		) best
+		ORDER BY rank DESC, created_at DESC
+		LIMIT $3`
+
+	rows, err := r.db.GetDB().Query(sqlQuery, query, channelFilter(channelIDs), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		result := &SearchResult{}
+		err := rows.Scan(&result.SessionDBID, &result.SessionID, &result.WorkingDirectory, &result.ChannelID,
+			&result.ChildSessionID, &result.Snippet, &result.Rank, &result.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search conversations: %w", err)
+	}
+
+	r.logger.Debug("Conversation search",
+		zap.String("query", query),
+		zap.Int("channels", len(channelIDs)),
+		zap.Int("results", len(results)))
+
+	return results, nil
+}
@@ -3,8 +3,10 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 
 	"github.com/ghabxph/claude-on-slack/internal/database"
@@ -16,6 +18,7 @@ type Session struct {
 	WorkingDirectory string    `db:"working_directory"`
 	SystemUser       string    `db:"system_user"`
 	UserPrompt       *string   `db:"user_prompt"`
+	ChannelID        *string   `db:"channel_id"`
 	CreatedAt        time.Time `db:"created_at"`
 	UpdatedAt        time.Time `db:"updated_at"`
 }
@@ -33,36 +36,107 @@ type ChildSession struct {
 }
 
 type SlackChannel struct {
-	ID                    int       `db:"id"`
-	ChannelID             string    `db:"channel_id"`
-	ActiveSessionID       *int      `db:"active_session_id"`
-	ActiveChildSessionID  *int      `db:"active_child_session_id"`
-	Permission            string    `db:"permission"`
-	CreatedAt             time.Time `db:"created_at"`
-	UpdatedAt             time.Time `db:"updated_at"`
+	ID                    int        `db:"id"`
+	ChannelID             string     `db:"channel_id"`
+	ActiveSessionID       *int       `db:"active_session_id"`
+	ActiveChildSessionID  *int       `db:"active_child_session_id"`
+	Permission            string     `db:"permission"`
+	DefaultPermission     string     `db:"default_permission"` // Mode the channel started with; expiry reverts to it
+	PermissionExpiresAt   *time.Time `db:"permission_expires_at"`
+	ChannelContextEnabled *bool      `db:"channel_context_enabled"`
+	Agents                []string   `db:"agents"` // From channel_settings; nil = all configured agents
+	SessionMode           string     `db:"session_mode"`
+	ChannelType           *string    `db:"channel_type"` // nil = not seen since channel types were recorded
+	RunPriority           *string    `db:"run_priority"` // From channel_settings; nil = normal
+	FooterEnv             []string   `db:"footer_env"`   // From channel_settings; nil = configured default
+	CreatedAt             time.Time  `db:"created_at"`
+	UpdatedAt             time.Time  `db:"updated_at"`
+}
+
+// Slack conversation types recorded in slack_channels.channel_type
+const (
+	ChannelTypePublic  = "public"
+	ChannelTypePrivate = "private"
+	ChannelTypeDM      = "dm"
+	ChannelTypeGroupDM = "group_dm"
+)
+
+// SlackChannelUserSession is a user's active session in a per-user mode channel
+type SlackChannelUserSession struct {
+	ChannelID            string    `db:"channel_id"`
+	UserID               string    `db:"user_id"`
+	ActiveSessionID      *int      `db:"active_session_id"`
+	ActiveChildSessionID *int      `db:"active_child_session_id"`
+	CreatedAt            time.Time `db:"created_at"`
+	UpdatedAt            time.Time `db:"updated_at"`
 }
 
 type SessionRepository struct {
-	db     *database.Database
-	logger *zap.Logger
+	db       *database.Database
+	logger   *zap.Logger
+	settings *SettingsRepository
+
+	// Channel settings, including the permission modes channels and channel
+	// types were given with /settings
+	channelSettings *ChannelSettingsRepository
 }
 
 func NewSessionRepository(db *database.Database, logger *zap.Logger) *SessionRepository {
 	return &SessionRepository{
-		db:     db,
-		logger: logger,
+		db:       db,
+		logger:   logger,
+		settings: NewSettingsRepository(db, logger),
+
+		channelSettings: NewChannelSettingsRepository(db, logger),
+	}
+}
+
+// DefaultChannelPermission returns the permission mode a new channel of an
+// unknown type starts with
+func (r *SessionRepository) DefaultChannelPermission(channelID string) (string, error) {
+	return r.defaultChannelPermission(channelID, "")
+}
+
+// defaultChannelPermission returns the permission mode a new channel starts
+// with: its permission_mode channel setting, else its type's permission mode
+// setting, else the workspace default
+func (r *SessionRepository) defaultChannelPermission(channelID, channelType string) (string, error) {
+	channelSettings, err := r.channelSettings.GetChannelSettings(channelID)
+	if err != nil {
+		return "", err
 	}
+	if channelSettings != nil && channelSettings.PermissionMode != nil {
+		return *channelSettings.PermissionMode, nil
+	}
+	if channelType != "" {
+		mode, err := r.channelSettings.GetChannelTypePermissionMode(channelType)
+		if err != nil {
+			return "", err
+		}
+		if mode != "" {
+			return mode, nil
+		}
+	}
+
+	mode, err := r.settings.GetSetting(SettingDefaultPermission)
+	if err != nil {
+		return "", err
+	}
+	if mode == "" {
+		return "default", nil
+	}
+	return mode, nil
 }
 
 // CreateSession inserts a new root session
 func (r *SessionRepository) CreateSession(session *Session) error {
 	query := `
-		INSERT INTO sessions (session_id, working_directory, system_user, user_prompt, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		INSERT INTO sessions (session_id, working_directory, system_user, user_prompt, channel_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
 		RETURNING id`
 
 	err := r.db.GetDB().QueryRow(query, session.SessionID, session.WorkingDirectory, 
-		session.SystemUser, session.UserPrompt).Scan(&session.ID)
+		session.SystemUser, session.UserPrompt, session.ChannelID).Scan(&session.ID)
 	
 	if err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
@@ -125,7 +199,7 @@ func (r *SessionRepository) GetConversationTree(rootParentID int) ([]*ChildSessi
 
 // GetSessionBySessionID retrieves a root session by its session ID
 func (r *SessionRepository) GetSessionBySessionID(sessionID string) (*Session, error) {
-	query := `SELECT id, session_id, working_directory, system_user, user_prompt, created_at, updated_at FROM sessions WHERE session_id = $1`
+	query := `SELECT id, session_id, working_directory, system_user, user_prompt, created_at, updated_at FROM sessions WHERE session_id = $1 AND deleted_at IS NULL`
 	
 	session := &Session{}
 	err := r.db.GetDB().QueryRow(query, sessionID).Scan(
@@ -188,14 +262,47 @@ func (r *SessionRepository) UpdateChildUserPrompt(childID int, prompt string) er
 	return nil
 }
 
+// UpdateChildSummary stores a summary of the conversation up to a child session
+func (r *SessionRepository) UpdateChildSummary(childID int, summary string) error {
+	query := `UPDATE child_sessions SET summary = $1, updated_at = NOW() WHERE id = $2`
+
+	_, err := r.db.GetDB().Exec(query, summary, childID)
+	if err != nil {
+		return fmt.Errorf("failed to update child summary: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSessionSummary stores a summary of a root session's whole
+// conversation. Trashed sessions are updated too, so a summary written on
+// delete survives a restore.
+func (r *SessionRepository) UpdateSessionSummary(sessionID, summary string) error {
+	query := `UPDATE sessions SET summary = $1, summarized_at = NOW() WHERE session_id = $2`
+
+	result, err := r.db.GetDB().Exec(query, summary, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to update session summary: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("failed to update session summary: session %s not found", sessionID)
+	}
+
+	return nil
+}
+
 // GetChannelState retrieves the active session state for a Slack channel
 func (r *SessionRepository) GetChannelState(channelID string) (*SlackChannel, error) {
-	query := `SELECT id, channel_id, active_session_id, active_child_session_id, created_at, updated_at, permission FROM slack_channels WHERE channel_id = $1`
+	query := `SELECT c.id, c.channel_id, c.active_session_id, c.active_child_session_id, c.created_at, c.updated_at, c.permission, c.default_permission, c.permission_expires_at, c.channel_context_enabled, cs.agents, c.session_mode, c.channel_type, cs.run_priority, cs.footer_env
+		FROM slack_channels c LEFT JOIN channel_settings cs ON cs.channel_id = c.channel_id
+		WHERE c.channel_id = $1`
 	
 	channel := &SlackChannel{}
 	err := r.db.GetDB().QueryRow(query, channelID).Scan(
 		&channel.ID, &channel.ChannelID, &channel.ActiveSessionID,
-		&channel.ActiveChildSessionID, &channel.CreatedAt, &channel.UpdatedAt, &channel.Permission)
+		&channel.ActiveChildSessionID, &channel.CreatedAt, &channel.UpdatedAt, &channel.Permission,
+		&channel.DefaultPermission, &channel.PermissionExpiresAt, &channel.ChannelContextEnabled, pq.Array(&channel.Agents),
+		&channel.SessionMode, &channel.ChannelType, &channel.RunPriority, pq.Array(&channel.FooterEnv))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -216,10 +323,15 @@ func (r *SessionRepository) UpdateChannelState(channelID string, activeSessionID
 	}
 
 	if existingChannel == nil {
+		permission, err := r.DefaultChannelPermission(channelID)
+		if err != nil {
+			return err
+		}
+
 		// Create new channel state
-		query := `INSERT INTO slack_channels (channel_id, active_session_id, active_child_session_id, permission, created_at, updated_at)
-				  VALUES ($1, $2, $3, 'default', NOW(), NOW())`
-		_, err = r.db.GetDB().Exec(query, channelID, activeSessionID, activeChildSessionID)
+		query := `INSERT INTO slack_channels (channel_id, active_session_id, active_child_session_id, permission, default_permission, created_at, updated_at)
+				  VALUES ($1, $2, $3, $4, $4, NOW(), NOW())`
+		_, err = r.db.GetDB().Exec(query, channelID, activeSessionID, activeChildSessionID, permission)
 		if err != nil {
 			return fmt.Errorf("failed to create channel state: %w", err)
 		}
@@ -239,7 +351,7 @@ func (r *SessionRepository) UpdateChannelState(channelID string, activeSessionID
 
 // ListAllSessions returns all sessions with their paths, ordered by most recent
 func (r *SessionRepository) ListAllSessions(limit int) ([]*Session, error) {
-	query := `SELECT id, session_id, working_directory, system_user, user_prompt, created_at, updated_at FROM sessions ORDER BY updated_at DESC LIMIT $1`
+	query := `SELECT id, session_id, working_directory, system_user, user_prompt, created_at, updated_at FROM sessions WHERE deleted_at IS NULL ORDER BY updated_at DESC LIMIT $1`
 	
 	rows, err := r.db.GetDB().Query(query, limit)
 	if err != nil {
@@ -263,7 +375,7 @@ func (r *SessionRepository) ListAllSessions(limit int) ([]*Session, error) {
 
 // GetUniqueWorkingDirectories returns unique working directories from all sessions
 func (r *SessionRepository) GetUniqueWorkingDirectories(limit int) ([]string, error) {
-	query := `SELECT DISTINCT working_directory FROM sessions ORDER BY working_directory LIMIT $1`
+	query := `SELECT DISTINCT working_directory FROM sessions WHERE deleted_at IS NULL ORDER BY working_directory LIMIT $1`
 	
 	rows, err := r.db.GetDB().Query(query, limit)
 	if err != nil {
@@ -305,7 +417,7 @@ func (r *SessionRepository) GetSessionByID(id int) (*Session, error) {
 
 // GetSessionsByWorkingDirectory returns sessions that match a specific working directory
 func (r *SessionRepository) GetSessionsByWorkingDirectory(workingDir string, limit int) ([]*Session, error) {
-	query := `SELECT id, session_id, working_directory, system_user, user_prompt, created_at, updated_at FROM sessions WHERE working_directory = $1 ORDER BY updated_at DESC LIMIT $2`
+	query := `SELECT id, session_id, working_directory, system_user, user_prompt, created_at, updated_at FROM sessions WHERE working_directory = $1 AND deleted_at IS NULL ORDER BY updated_at DESC LIMIT $2`
 	
 	rows, err := r.db.GetDB().Query(query, workingDir, limit)
 	if err != nil {
@@ -351,7 +463,11 @@ func (r *SessionRepository) CountMessagesInConversationTree(rootParentID int) (i
 
 // UpdateChannelPermission updates the permission mode for a Slack channel
 func (r *SessionRepository) UpdateChannelPermission(channelID string, permission string) error {
-	query := `UPDATE slack_channels SET permission = $1, updated_at = NOW() WHERE channel_id = $2`
+	if err := r.EnsureChannel(channelID); err != nil {
+		return err
+	}
+
+	query := `UPDATE slack_channels SET permission = $1, permission_expires_at = NULL, updated_at = NOW() WHERE channel_id = $2`
 	
 	_, err := r.db.GetDB().Exec(query, permission, channelID)
 	if err != nil {
@@ -361,6 +477,49 @@ func (r *SessionRepository) UpdateChannelPermission(channelID string, permission
 	return nil
 }
 
+// UpdateChannelPermissionWithExpiry sets a permission mode that reverts to the
+// channel's default at expiresAt
+func (r *SessionRepository) UpdateChannelPermissionWithExpiry(channelID string, permission string, expiresAt time.Time) error {
+	if err := r.EnsureChannel(channelID); err != nil {
+		return err
+	}
+
+	query := `UPDATE slack_channels SET permission = $1, permission_expires_at = $2, updated_at = NOW() WHERE channel_id = $3`
+	
+	_, err := r.db.GetDB().Exec(query, permission, expiresAt, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel permission: %w", err)
+	}
+
+	return nil
+}
+
+// RevertExpiredChannelPermissions resets expired channel permissions to each
+// channel's default and returns the affected channel IDs
+func (r *SessionRepository) RevertExpiredChannelPermissions() ([]string, error) {
+	query := `
+		UPDATE slack_channels SET permission = default_permission, permission_expires_at = NULL, updated_at = NOW()
+		WHERE permission_expires_at IS NOT NULL AND permission_expires_at <= NOW()
+		RETURNING channel_id`
+
+	rows, err := r.db.GetDB().Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revert expired channel permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var channelIDs []string
+	for rows.Next() {
+		var channelID string
+		if err := rows.Scan(&channelID); err != nil {
+			return nil, fmt.Errorf("failed to scan channel ID: %w", err)
+		}
+		channelIDs = append(channelIDs, channelID)
+	}
+
+	return channelIDs, rows.Err()
+}
+
 // GetChannelPermission retrieves the permission mode for a Slack channel
 func (r *SessionRepository) GetChannelPermission(channelID string) (string, error) {
 	channel, err := r.GetChannelState(channelID)
@@ -369,11 +528,232 @@ func (r *SessionRepository) GetChannelPermission(channelID string) (string, erro
 	}
 	
 	if channel == nil {
-		// Channel doesn't exist, return default permission
-		return "default", nil
+		// Channel doesn't exist, return the mode it will start with
+		return r.DefaultChannelPermission(channelID)
+	}
+
+	return channel.EffectivePermission(time.Now()), nil
+}
+
+// EffectivePermission returns the channel's permission mode at now: the
+// default it reverts to once expired, even before the background reverter
+// has swept it
+func (c *SlackChannel) EffectivePermission(now time.Time) string {
+	if c.PermissionExpiresAt != nil && !c.PermissionExpiresAt.After(now) {
+		return c.DefaultPermission
+	}
+	return c.Permission
+}
+
+// EnsureChannel creates a channel state row if one does not exist yet
+func (r *SessionRepository) EnsureChannel(channelID string) error {
+	existingChannel, err := r.GetChannelState(channelID)
+	if err != nil {
+		return err
+	}
+	if existingChannel != nil {
+		return nil
+	}
+	return r.UpdateChannelState(channelID, nil, nil)
+}
+
+// RecordChannelType stores a channel's type. A channel seen for the first
+// time starts with its type's default permission mode, and DMs and group DMs
+// give each user their own session; existing channels keep their settings.
+func (r *SessionRepository) RecordChannelType(channelID, channelType string) error {
+	existingChannel, err := r.GetChannelState(channelID)
+	if err != nil {
+		return err
+	}
+
+	if existingChannel != nil {
+		if existingChannel.ChannelType != nil && *existingChannel.ChannelType == channelType {
+			return nil
+		}
+		query := `UPDATE slack_channels SET channel_type = $1, updated_at = NOW() WHERE channel_id = $2`
+		if _, err := r.db.GetDB().Exec(query, channelType, channelID); err != nil {
+			return fmt.Errorf("failed to update channel type: %w", err)
+		}
+		return nil
+	}
+
+	permission, err := r.defaultChannelPermission(channelID, channelType)
+	if err != nil {
+		return err
+	}
+	sessionMode := "shared"
+	if channelType == ChannelTypeDM || channelType == ChannelTypeGroupDM {
+		sessionMode = "per-user"
+	}
+
+	query := `INSERT INTO slack_channels (channel_id, channel_type, permission, default_permission, session_mode, created_at, updated_at)
+			  VALUES ($1, $2, $3, $3, $4, NOW(), NOW())`
+	if _, err := r.db.GetDB().Exec(query, channelID, channelType, permission, sessionMode); err != nil {
+		return fmt.Errorf("failed to create channel state: %w", err)
+	}
+	return nil
+}
+
+// ListSessionsForChannel returns the sessions that may be listed in a
+// channel, most recent first: the channel's own sessions plus those from
+// public channels. Sessions from channels of unknown type count as public.
+// A non-empty channelType only returns sessions from channels of that type;
+// ChannelTypeDM also matches group DMs.
+func (r *SessionRepository) ListSessionsForChannel(channelID, channelType string, limit int) ([]*Session, error) {
+	query := `
+		SELECT id, session_id, working_directory, system_user, user_prompt, channel_id, created_at, updated_at
+		FROM (
+			SELECT s.*, COALESCE((SELECT sc.channel_type FROM slack_channels sc WHERE sc.channel_id = s.channel_id LIMIT 1), 'public') AS context_type
+			FROM sessions s
+			WHERE s.deleted_at IS NULL
+		) listed
+		WHERE (channel_id = $1 OR context_type = 'public')
+		  AND ($2 = '' OR context_type = $2 OR ($2 = 'dm' AND context_type = 'group_dm'))
+		ORDER BY updated_at DESC
+		LIMIT $3`
+
+	rows, err := r.db.GetDB().Query(query, channelID, channelType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions for channel: %w", err)
 	}
+	defer rows.Close()
+
+	var sessions []*Session
+	for rows.Next() {
+		session := &Session{}
+		err := rows.Scan(&session.ID, &session.SessionID, &session.WorkingDirectory,
+			&session.SystemUser, &session.UserPrompt, &session.ChannelID, &session.CreatedAt, &session.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// FindSessionsByIDPrefix returns the sessions whose session ID starts with
+// prefix, most recent first, among those ListSessionsForChannel would list
+// in the channel
+func (r *SessionRepository) FindSessionsByIDPrefix(channelID, prefix string, limit int) ([]*Session, error) {
+	query := `
+		SELECT id, session_id, working_directory, system_user, user_prompt, channel_id, created_at, updated_at
+		FROM (
+			SELECT s.*, COALESCE((SELECT sc.channel_type FROM slack_channels sc WHERE sc.channel_id = s.channel_id LIMIT 1), 'public') AS context_type
+			FROM sessions s
+			WHERE s.deleted_at IS NULL AND s.session_id LIKE $2 ESCAPE '\'
+		) listed
+		WHERE channel_id = $1 OR context_type = 'public'
+		ORDER BY updated_at DESC
+		LIMIT $3`
+
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(prefix)) + "%"
+	rows, err := r.db.GetDB().Query(query, channelID, pattern, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sessions by prefix: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*Session
+	for rows.Next() {
+		session := &Session{}
+		err := rows.Scan(&session.ID, &session.SessionID, &session.WorkingDirectory,
+			&session.SystemUser, &session.UserPrompt, &session.ChannelID, &session.CreatedAt, &session.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// SessionVisibleInChannel reports whether a session may be shown in a
+// channel, by the same rule as listings: its own channel's, or a public one's
+func (r *SessionRepository) SessionVisibleInChannel(sessionID, channelID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM sessions s
+			WHERE s.session_id = $1 AND s.deleted_at IS NULL
+			AND (s.channel_id = $2
+				OR COALESCE((SELECT sc.channel_type FROM slack_channels sc WHERE sc.channel_id = s.channel_id LIMIT 1), 'public') = 'public')
+		)`
+
+	var visible bool
+	if err := r.db.GetDB().QueryRow(query, sessionID, channelID).Scan(&visible); err != nil {
+		return false, fmt.Errorf("failed to check session visibility: %w", err)
+	}
+	return visible, nil
+}
+
+// UpdateChannelContextEnabled sets whether channel topic/purpose is injected into prompts
+func (r *SessionRepository) UpdateChannelContextEnabled(channelID string, enabled bool) error {
+	if err := r.EnsureChannel(channelID); err != nil {
+		return err
+	}
+
+	query := `UPDATE slack_channels SET channel_context_enabled = $1, updated_at = NOW() WHERE channel_id = $2`
 	
-	return channel.Permission, nil
+	_, err := r.db.GetDB().Exec(query, enabled, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel context setting: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateChannelSessionMode sets whether a channel shares one session or gives
+// each user their own
+func (r *SessionRepository) UpdateChannelSessionMode(channelID string, mode string) error {
+	if err := r.EnsureChannel(channelID); err != nil {
+		return err
+	}
+
+	query := `UPDATE slack_channels SET session_mode = $1, updated_at = NOW() WHERE channel_id = $2`
+
+	_, err := r.db.GetDB().Exec(query, mode, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel session mode: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserChannelState retrieves a user's active session in a per-user mode channel
+func (r *SessionRepository) GetUserChannelState(channelID, userID string) (*SlackChannelUserSession, error) {
+	query := `SELECT channel_id, user_id, active_session_id, active_child_session_id, created_at, updated_at
+			  FROM slack_channel_user_sessions WHERE channel_id = $1 AND user_id = $2`
+
+	state := &SlackChannelUserSession{}
+	err := r.db.GetDB().QueryRow(query, channelID, userID).Scan(
+		&state.ChannelID, &state.UserID, &state.ActiveSessionID,
+		&state.ActiveChildSessionID, &state.CreatedAt, &state.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user channel state: %w", err)
+	}
+
+	return state, nil
+}
+
+// UpdateUserChannelState updates a user's active session in a per-user mode channel
+func (r *SessionRepository) UpdateUserChannelState(channelID, userID string, activeSessionID, activeChildSessionID *int) error {
+	query := `INSERT INTO slack_channel_user_sessions (channel_id, user_id, active_session_id, active_child_session_id, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, NOW(), NOW())
+			  ON CONFLICT (channel_id, user_id) DO UPDATE
+			  SET active_session_id = EXCLUDED.active_session_id,
+				  active_child_session_id = EXCLUDED.active_child_session_id,
+				  updated_at = NOW()`
+
+	_, err := r.db.GetDB().Exec(query, channelID, userID, activeSessionID, activeChildSessionID)
+	if err != nil {
+		return fmt.Errorf("failed to update user channel state: %w", err)
+	}
+
+	return nil
 }
 
 // FindChannelForSession finds which channel a session belongs to
@@ -382,7 +762,10 @@ func (r *SessionRepository) FindChannelForSession(sessionDBID int) (string, erro
 			  WHERE active_session_id = $1 
 			  OR active_child_session_id IN (
 				  SELECT id FROM child_sessions WHERE root_parent_id = $1
-			  )`
+			  )
+			  UNION
+			  SELECT channel_id FROM slack_channel_user_sessions
+			  WHERE active_session_id = $1`
 	
 	var channelID string
 	err := r.db.GetDB().QueryRow(query, sessionDBID).Scan(&channelID)
@@ -393,10 +776,47 @@ func (r *SessionRepository) FindChannelForSession(sessionDBID int) (string, erro
 	return channelID, nil
 }
 
-// DeleteSession deletes a session and all its associated child sessions
+// MoveSessionToChannel hands a session over to another channel: the session
+// is listed there from now on. Both channels keep their own permission mode
+// and settings, and which session each has active is left to the caller.
+// Archived and deleted channels are refused with ErrChannelArchived.
+func (r *SessionRepository) MoveSessionToChannel(sessionDBID int, toChannelID string) error {
+	if err := r.EnsureChannel(toChannelID); err != nil {
+		return err
+	}
+
+	tx, err := r.db.GetDB().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin session move: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the target so it can't be archived between the check and the move
+	var state string
+	err = tx.QueryRow(`SELECT lifecycle_state FROM slack_channels WHERE channel_id = $1 FOR UPDATE`, toChannelID).Scan(&state)
+	if err != nil {
+		return fmt.Errorf("failed to get channel lifecycle state: %w", err)
+	}
+	if state != ChannelLifecycleActive {
+		return ErrChannelArchived
+	}
+
+	if _, err := tx.Exec(`UPDATE sessions SET channel_id = $1, updated_at = NOW() WHERE id = $2`, toChannelID, sessionDBID); err != nil {
+		return fmt.Errorf("failed to move session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit session move: %w", err)
+	}
+	return nil
+}
+
+// DeleteSession permanently deletes a session, live or trashed, and all its
+// associated child sessions
 func (r *SessionRepository) DeleteSession(sessionID string) error {
 	// First, get the session to get its ID for deleting child sessions
-	session, err := r.GetSessionBySessionID(sessionID)
+	session := &Session{}
+	err := r.db.GetDB().QueryRow(`SELECT id FROM sessions WHERE session_id = $1`, sessionID).Scan(&session.ID)
 	if err != nil {
 		return fmt.Errorf("failed to find session to delete: %w", err)
 	}
@@ -441,6 +861,25 @@ func (r *SessionRepository) DeleteSession(sessionID string) error {
 	return nil
 }
 
+// GetChildSessionBySessionID retrieves a child session by its Claude session ID
+func (r *SessionRepository) GetChildSessionBySessionID(sessionID string) (*ChildSession, error) {
+	query := `SELECT id, session_id, previous_session_id, root_parent_id, ai_response, user_prompt, summary, created_at, updated_at FROM child_sessions WHERE session_id = $1`
+
+	child := &ChildSession{}
+	err := r.db.GetDB().QueryRow(query, sessionID).Scan(
+		&child.ID, &child.SessionID, &child.PreviousSessionID, &child.RootParentID,
+		&child.AIResponse, &child.UserPrompt, &child.Summary, &child.CreatedAt, &child.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Child session not found
+		}
+		return nil, fmt.Errorf("failed to get child session by session ID: %w", err)
+	}
+
+	return child, nil
+}
+
 // GetChildSessionByID retrieves a child session by its database ID
 func (r *SessionRepository) GetChildSessionByID(id int) (*ChildSession, error) {
 	query := `SELECT id, session_id, previous_session_id, root_parent_id, ai_response, user_prompt, summary, created_at, updated_at FROM child_sessions WHERE id = $1`
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type SessionDirRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewSessionDirRepository(db *database.Database, logger *zap.Logger) *SessionDirRepository {
+	return &SessionDirRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddSessionDir attaches a directory to a session. It returns false if the
+// session doesn't exist; adding a directory twice keeps the first record.
+func (r *SessionDirRepository) AddSessionDir(sessionID, path, addedBy string) (bool, error) {
+	query := `
+		INSERT INTO session_dirs (session_id, path, added_by, added_at)
+		SELECT id, $2, $3, NOW() FROM sessions WHERE session_id = $1 AND deleted_at IS NULL
+		ON CONFLICT (session_id, path) DO NOTHING`
+
+	result, err := r.db.GetDB().Exec(query, sessionID, path, addedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to add session directory: %w", err)
+	}
+	if added, err := result.RowsAffected(); err == nil && added > 0 {
+		r.logger.Info("Session directory added",
+			zap.String("session_id", sessionID),
+			zap.String("path", path),
+			zap.String("added_by", addedBy))
+		return true, nil
+	}
+
+	// Nothing inserted: either the directory was already attached or the
+	// session doesn't exist
+	var exists bool
+	err = r.db.GetDB().QueryRow(`SELECT EXISTS (SELECT 1 FROM sessions WHERE session_id = $1 AND deleted_at IS NULL)`, sessionID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return exists, nil
+}
+
+// RemoveSessionDir detaches a directory from a session, reporting whether it
+// was attached
+func (r *SessionDirRepository) RemoveSessionDir(sessionID, path string) (bool, error) {
+	query := `
+		DELETE FROM session_dirs
+		WHERE path = $2 AND session_id = (SELECT id FROM sessions WHERE session_id = $1)`
+
+	result, err := r.db.GetDB().Exec(query, sessionID, path)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove session directory: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove session directory: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// ListSessionDirs returns the directories attached to a session, in the order
+// they were added
+func (r *SessionDirRepository) ListSessionDirs(sessionID string) ([]string, error) {
+	query := `
+		SELECT d.path
+		FROM session_dirs d
+		JOIN sessions s ON s.id = d.session_id
+		WHERE s.session_id = $1
+		ORDER BY d.added_at, d.path`
+
+	rows, err := r.db.GetDB().Query(query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session directories: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to scan session directory: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list session directories: %w", err)
+	}
+	return paths, nil
+}
+
+// ListReferencedDirectories returns every directory a session refers to as
+// its working directory or an extra directory, including sessions in the
+// trash since they can still be restored
+func (r *SessionDirRepository) ListReferencedDirectories() ([]string, error) {
+	query := `
+		SELECT working_directory FROM sessions
+		UNION
+		SELECT path FROM session_dirs`
+
+	rows, err := r.db.GetDB().Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list referenced directories: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to scan referenced directory: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list referenced directories: %w", err)
+	}
+	return paths, nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// PruneRun is a run of old exchanges in one session that can be collapsed
+// into a single checkpoint
+type PruneRun struct {
+	RootParentID int
+	SessionID    string // The root session's ID
+	Children     []*ChildSession
+}
+
+// FindPruneRuns returns up to limit runs of chunk exchanges to collapse: the
+// oldest exchanges created before cutoff in each session, skipping the keep
+// latest exchanges and existing checkpoints. Sessions with fewer than chunk
+// such exchanges are left alone.
+func (r *SessionRepository) FindPruneRuns(cutoff time.Time, keep, chunk, limit int) ([]*PruneRun, error) {
+	query := `
+		WITH eligible AS (
+			SELECT ranked.id, ranked.root_parent_id,
+			       ROW_NUMBER() OVER (PARTITION BY ranked.root_parent_id ORDER BY ranked.id) AS position,
+			       COUNT(*) OVER (PARTITION BY ranked.root_parent_id) AS total
+			FROM (
+				SELECT id, root_parent_id, checkpoint_of, created_at,
+				       ROW_NUMBER() OVER (PARTITION BY root_parent_id ORDER BY id DESC) AS newer
+				FROM child_sessions
+			) ranked
+			JOIN sessions s ON s.id = ranked.root_parent_id AND s.deleted_at IS NULL
+			WHERE ranked.newer > $2 AND ranked.checkpoint_of IS NULL AND ranked.created_at < $1
+		),
+		runs AS (
+			SELECT DISTINCT root_parent_id FROM eligible WHERE total >= $3
+			ORDER BY root_parent_id LIMIT $4
+		)
+		SELECT s.session_id, c.id, c.session_id, c.previous_session_id, c.root_parent_id,
+		       c.ai_response, c.user_prompt, c.summary, c.created_at, c.updated_at
+		FROM eligible e
+		JOIN runs ON runs.root_parent_id = e.root_parent_id
+		JOIN child_sessions c ON c.id = e.id
+		JOIN sessions s ON s.id = c.root_parent_id
+		WHERE e.position <= $3
+		ORDER BY c.root_parent_id, c.id`
+
+	rows, err := r.db.GetDB().Query(query, cutoff, keep, chunk, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find exchanges to prune: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*PruneRun
+	for rows.Next() {
+		var rootSessionID string
+		child := &ChildSession{}
+		err := rows.Scan(&rootSessionID, &child.ID, &child.SessionID, &child.PreviousSessionID,
+			&child.RootParentID, &child.AIResponse, &child.UserPrompt, &child.Summary,
+			&child.CreatedAt, &child.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan exchange to prune: %w", err)
+		}
+		if len(runs) == 0 || runs[len(runs)-1].RootParentID != child.RootParentID {
+			runs = append(runs, &PruneRun{RootParentID: child.RootParentID, SessionID: rootSessionID})
+		}
+		run := runs[len(runs)-1]
+		run.Children = append(run.Children, child)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find exchanges to prune: %w", err)
+	}
+	return runs, nil
+}
+
+// CollapsePruneRun replaces a run of exchanges with one checkpoint holding
+// their summary. The run's last row becomes the checkpoint, so its Claude
+// session ID, which later exchanges link to, stays valid; it takes over the
+// first row's previous_session_id, and anything pointing at the removed rows
+// (later exchanges, tags, channel state) is moved to it. It returns
+// sql.ErrNoRows if the run changed since it was found.
+func (r *SessionRepository) CollapsePruneRun(run *PruneRun, checkpointText, summary string) error {
+	if len(run.Children) < 2 {
+		return fmt.Errorf("a checkpoint needs at least 2 exchanges, got %d", len(run.Children))
+	}
+	first := run.Children[0]
+	last := run.Children[len(run.Children)-1]
+	removedIDs := make([]int64, 0, len(run.Children)-1)
+	removedSessionIDs := make([]string, 0, len(run.Children)-1)
+	for _, child := range run.Children[:len(run.Children)-1] {
+		removedIDs = append(removedIDs, int64(child.ID))
+		removedSessionIDs = append(removedSessionIDs, child.SessionID)
+	}
+
+	tx, err := r.db.GetDB().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the run, and make sure nothing collapsed or removed it meanwhile
+	var locked int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM (
+			SELECT id FROM child_sessions
+			WHERE root_parent_id = $1 AND (id = ANY($2) OR id = $3) AND checkpoint_of IS NULL
+			FOR UPDATE
+		) run`, run.RootParentID, pq.Array(removedIDs), last.ID).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("failed to lock exchanges to prune: %w", err)
+	}
+	if locked != len(run.Children) {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(`
+		UPDATE child_sessions
+		SET previous_session_id = $2, ai_response = $3, summary = $4, checkpoint_of = $5, updated_at = NOW()
+		WHERE id = $1`, last.ID, first.PreviousSessionID, checkpointText, summary, len(run.Children))
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	// Exchanges that branched off a removed row continue from the checkpoint
+	_, err = tx.Exec(`
+		UPDATE child_sessions SET previous_session_id = $2, updated_at = NOW()
+		WHERE root_parent_id = $1 AND previous_session_id = ANY($3) AND id <> ALL($4)`,
+		run.RootParentID, last.SessionID, pq.Array(removedSessionIDs), pq.Array(removedIDs))
+	if err != nil {
+		return fmt.Errorf("failed to rewire exchanges: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO tags (child_session_id, label, channel_id, tagged_by, created_at)
+		SELECT DISTINCT ON (label) $1, label, channel_id, tagged_by, created_at
+		FROM tags WHERE child_session_id = ANY($2)
+		ORDER BY label, created_at
+		ON CONFLICT (child_session_id, label) DO NOTHING`, last.ID, pq.Array(removedIDs))
+	if err != nil {
+		return fmt.Errorf("failed to move tags to checkpoint: %w", err)
+	}
+
+	for _, query := range []string{
+		`UPDATE slack_channels SET active_child_session_id = $1, updated_at = NOW() WHERE active_child_session_id = ANY($2)`,
+		`UPDATE slack_channel_user_sessions SET active_child_session_id = $1, updated_at = NOW() WHERE active_child_session_id = ANY($2)`,
+	} {
+		if _, err := tx.Exec(query, last.ID, pq.Array(removedIDs)); err != nil {
+			return fmt.Errorf("failed to move channel state to checkpoint: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM child_sessions WHERE id = ANY($1)`, pq.Array(removedIDs)); err != nil {
+		return fmt.Errorf("failed to remove pruned exchanges: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info("Collapsed exchanges into a checkpoint",
+		zap.String("session_id", run.SessionID),
+		zap.Int("checkpoint_id", last.ID),
+		zap.Int("exchanges", len(run.Children)))
+	return nil
+}
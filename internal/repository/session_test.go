@@ -87,4 +87,25 @@ func TestSessionRepository_GetSessionBySessionID(t *testing.T) {
 	if retrieved.SessionID != "test-session-456" {
 		t.Errorf("Expected session ID test-session-456, got %s", retrieved.SessionID)
 	}
-}
\ No newline at end of file
+}
+func TestSlackChannel_EffectivePermission(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	channel := &SlackChannel{Permission: "bypassPermissions", DefaultPermission: "default", PermissionExpiresAt: &expired}
+
+	// Expired but not yet swept by the background reverter
+	if got := channel.EffectivePermission(now); got != "default" {
+		t.Errorf("EffectivePermission() = %q, want the channel default once expired", got)
+	}
+
+	later := now.Add(time.Minute)
+	channel.PermissionExpiresAt = &later
+	if got := channel.EffectivePermission(now); got != "bypassPermissions" {
+		t.Errorf("EffectivePermission() = %q, want the temporary mode before it expires", got)
+	}
+
+	channel.PermissionExpiresAt = nil
+	if got := channel.EffectivePermission(now); got != "bypassPermissions" {
+		t.Errorf("EffectivePermission() = %q, want the mode without an expiry", got)
+	}
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// UpdateSessionTitle stores a root session's title unless it already has
+// one, reporting whether it was stored
+func (r *SessionRepository) UpdateSessionTitle(sessionID, title string) (bool, error) {
+	query := `UPDATE sessions SET title = $1 WHERE session_id = $2 AND title IS NULL`
+
+	result, err := r.db.GetDB().Exec(query, title, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update session title: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update session title: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetSessionTitles returns the titles of the given root sessions, keyed by
+// session ID. Sessions without a title are left out.
+func (r *SessionRepository) GetSessionTitles(sessionIDs []string) (map[string]string, error) {
+	titles := make(map[string]string)
+	if len(sessionIDs) == 0 {
+		return titles, nil
+	}
+
+	query := `SELECT session_id, title FROM sessions WHERE session_id = ANY($1) AND title IS NOT NULL`
+
+	rows, err := r.db.GetDB().Query(query, pq.Array(sessionIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session titles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sessionID string
+		var title sql.NullString
+		if err := rows.Scan(&sessionID, &title); err != nil {
+			return nil, fmt.Errorf("failed to scan session title: %w", err)
+		}
+		titles[sessionID] = title.String
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate session titles: %w", err)
+	}
+	return titles, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+// SettingDefaultPermission is the permission mode new channels start with
+// unless CHANNEL_PERMISSION_DEFAULTS names them
+const SettingDefaultPermission = "default_permission"
+
+// SettingDeployNotifiedVersion is the last version a deployment notification
+// was sent for
+const SettingDeployNotifiedVersion = "deploy_notified_version"
+
+type SettingsRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewSettingsRepository(db *database.Database, logger *zap.Logger) *SettingsRepository {
+	return &SettingsRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetSetting returns a workspace setting, or "" if it was never set
+func (r *SettingsRepository) GetSetting(key string) (string, error) {
+	var value string
+	err := r.db.GetDB().QueryRow(`SELECT value FROM bot_settings WHERE key = $1`, key).Scan(&value)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get setting %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// SetSetting stores a workspace setting and who changed it
+func (r *SettingsRepository) SetSetting(key, value, updatedBy string) error {
+	query := `
+		INSERT INTO bot_settings (key, value, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (key) DO UPDATE SET value = $2, updated_by = $3, updated_at = NOW()`
+
+	if _, err := r.db.GetDB().Exec(query, key, value, updatedBy); err != nil {
+		return fmt.Errorf("failed to update setting %s: %w", key, err)
+	}
+
+	r.logger.Info("Setting updated",
+		zap.String("key", key),
+		zap.String("value", value),
+		zap.String("updated_by", updatedBy))
+	return nil
+}
+
+// SwapSetting sets a workspace setting to value only if it is still old ("" =
+// never set), reporting whether it did, so only one instance acts on a change.
+// Swapping to "" removes the setting.
+func (r *SettingsRepository) SwapSetting(key, old, value, updatedBy string) (bool, error) {
+	query := `
+		INSERT INTO bot_settings (key, value, updated_by, updated_at)
+		SELECT $1, $3, $4, NOW() WHERE $2 = ''
+		ON CONFLICT (key) DO NOTHING`
+	args := []interface{}{key, old, value, updatedBy}
+	switch {
+	case old != "" && value == "":
+		query = `DELETE FROM bot_settings WHERE key = $1 AND value = $2`
+		args = args[:2]
+	case old != "":
+		query = `
+			UPDATE bot_settings SET value = $3, updated_by = $4, updated_at = NOW()
+			WHERE key = $1 AND value = $2`
+	}
+
+	result, err := r.db.GetDB().Exec(query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to swap setting %s: %w", key, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to swap setting %s: %w", key, err)
+	}
+	return rows > 0, nil
+}
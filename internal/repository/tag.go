@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type Tag struct {
+	ID             int       `db:"id"`
+	ChildSessionID int       `db:"child_session_id"`
+	Label          string    `db:"label"`
+	ChannelID      string    `db:"channel_id"`
+	TaggedBy       string    `db:"tagged_by"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// TaggedExchange is a tagged child session with its conversation context
+type TaggedExchange struct {
+	Tag
+	SessionID        string  `db:"session_id"`
+	ClaudeSessionID  string  `db:"claude_session_id"`
+	WorkingDirectory string  `db:"working_directory"`
+	UserPrompt       *string `db:"user_prompt"`
+	Summary          *string `db:"summary"`
+}
+
+// TagCount is a label and how many exchanges carry it
+type TagCount struct {
+	Label string `db:"label"`
+	Count int    `db:"count"`
+}
+
+type TagRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewTagRepository(db *database.Database, logger *zap.Logger) *TagRepository {
+	return &TagRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// FindLatestChildInChannel returns the most recent child session of the
+// session the user has active in the channel, or nil if it has no exchanges yet
+func (r *TagRepository) FindLatestChildInChannel(channelID, userID string) (*ChildSession, error) {
+	query := `
+		SELECT cs.id, cs.session_id, cs.previous_session_id, cs.root_parent_id, cs.ai_response,
+			cs.user_prompt, cs.summary, cs.created_at, cs.updated_at
+		FROM child_sessions cs
+		JOIN slack_channels sc ON sc.channel_id = $1
+		LEFT JOIN slack_channel_user_sessions us ON us.channel_id = sc.channel_id AND us.user_id = $2
+		WHERE cs.root_parent_id = CASE WHEN sc.session_mode = 'per-user'
+			THEN us.active_session_id ELSE sc.active_session_id END
+		ORDER BY cs.id DESC
+		LIMIT 1`
+
+	child := &ChildSession{}
+	err := r.db.GetDB().QueryRow(query, channelID, userID).Scan(&child.ID, &child.SessionID, &child.PreviousSessionID,
+		&child.RootParentID, &child.AIResponse, &child.UserPrompt, &child.Summary,
+		&child.CreatedAt, &child.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find latest child session: %w", err)
+	}
+
+	return child, nil
+}
+
+// AddTag labels a child session. It reports false if the label was already applied.
+func (r *TagRepository) AddTag(childSessionID int, label, channelID, taggedBy string) (bool, error) {
+	query := `
+		INSERT INTO tags (child_session_id, label, channel_id, tagged_by, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (child_session_id, label) DO NOTHING`
+
+	result, err := r.db.GetDB().Exec(query, childSessionID, label, channelID, taggedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to add tag: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to add tag: %w", err)
+	}
+
+	r.logger.Debug("Tag added",
+		zap.Int("child_session_id", childSessionID),
+		zap.String("label", label),
+		zap.Bool("created", rows > 0))
+
+	return rows > 0, nil
+}
+
+// RemoveTag removes a label from a child session. It reports false if the label was not applied.
+func (r *TagRepository) RemoveTag(childSessionID int, label string) (bool, error) {
+	query := `DELETE FROM tags WHERE child_session_id = $1 AND label = $2`
+
+	result, err := r.db.GetDB().Exec(query, childSessionID, label)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove tag: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove tag: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// ListTaggedExchanges returns exchanges carrying a label, newest first. A nil
+// channelIDs slice lists every channel; otherwise only tags applied in those channels.
+func (r *TagRepository) ListTaggedExchanges(label string, channelIDs []string, limit int) ([]*TaggedExchange, error) {
+	query := `
+		SELECT t.id, t.child_session_id, t.label, t.channel_id, t.tagged_by, t.created_at,
+			s.session_id, cs.session_id, s.working_directory, cs.user_prompt, cs.summary
+		FROM tags t
+		JOIN child_sessions cs ON cs.id = t.child_session_id
+		JOIN sessions s ON s.id = cs.root_parent_id
+		WHERE t.label = $1
+		  AND s.deleted_at IS NULL
+		  AND ($2::text[] IS NULL OR t.channel_id = ANY($2))
+		ORDER BY t.created_at DESC
+		LIMIT $3`
+
+	rows, err := r.db.GetDB().Query(query, label, channelFilter(channelIDs), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tagged exchanges: %w", err)
+	}
+	defer rows.Close()
+
+	var exchanges []*TaggedExchange
+	for rows.Next() {
+		exchange := &TaggedExchange{}
+		err := rows.Scan(&exchange.ID, &exchange.ChildSessionID, &exchange.Label, &exchange.ChannelID,
+			&exchange.TaggedBy, &exchange.CreatedAt, &exchange.SessionID, &exchange.ClaudeSessionID,
+			&exchange.WorkingDirectory, &exchange.UserPrompt, &exchange.Summary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tagged exchange: %w", err)
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tagged exchanges: %w", err)
+	}
+
+	return exchanges, nil
+}
+
+// ListLabels returns labels with their usage counts, most used first
+func (r *TagRepository) ListLabels(channelIDs []string, limit int) ([]*TagCount, error) {
+	query := `
+		SELECT label, COUNT(*)
+		FROM tags
+		WHERE ($1::text[] IS NULL OR channel_id = ANY($1))
+		GROUP BY label
+		ORDER BY COUNT(*) DESC, label
+		LIMIT $2`
+
+	rows, err := r.db.GetDB().Query(query, channelFilter(channelIDs), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*TagCount
+	for rows.Next() {
+		count := &TagCount{}
+		if err := rows.Scan(&count.Label, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan label: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+
+	return counts, nil
+}
+
+// channelFilter converts an optional channel list to a text[] query
+// parameter, where NULL matches every channel
+func channelFilter(channelIDs []string) interface{} {
+	if channelIDs == nil {
+		return nil
+	}
+	return pq.Array(channelIDs)
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type ThinkingMessage struct {
+	ChannelID string    `db:"channel_id"`
+	MessageTS string    `db:"message_ts"`
+	RunID     string    `db:"run_id"`
+	SessionID *string   `db:"session_id"`
+	Owner     *string   `db:"owner"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type ThinkingMessageRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewThinkingMessageRepository(db *database.Database, logger *zap.Logger) *ThinkingMessageRepository {
+	return &ThinkingMessageRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordThinkingMessage remembers a posted thinking message, and the instance
+// that posted it, until it is cleared
+func (r *ThinkingMessageRepository) RecordThinkingMessage(channelID, messageTS, runID, sessionID, owner string) error {
+	query := `
+		INSERT INTO thinking_messages (channel_id, message_ts, run_id, session_id, owner, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NOW())
+		ON CONFLICT (channel_id, message_ts) DO NOTHING`
+
+	if _, err := r.db.GetDB().Exec(query, channelID, messageTS, runID, sessionID, owner); err != nil {
+		return fmt.Errorf("failed to record thinking message: %w", err)
+	}
+	return nil
+}
+
+// RemoveThinkingMessage forgets a thinking message. It reports false if the
+// message was not tracked, e.g. because it was already cleared.
+func (r *ThinkingMessageRepository) RemoveThinkingMessage(channelID, messageTS string) (bool, error) {
+	query := `DELETE FROM thinking_messages WHERE channel_id = $1 AND message_ts = $2`
+
+	result, err := r.db.GetDB().Exec(query, channelID, messageTS)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove thinking message: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check removed thinking message: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ListThinkingMessagesBefore returns tracked thinking messages posted before
+// cutoff, only those posted by owner unless it is ""
+func (r *ThinkingMessageRepository) ListThinkingMessagesBefore(cutoff time.Time, owner string) ([]*ThinkingMessage, error) {
+	query := `
+		SELECT channel_id, message_ts, run_id, session_id, owner, created_at
+		FROM thinking_messages
+		WHERE created_at < $1 AND ($2 = '' OR owner = $2)
+		ORDER BY created_at`
+
+	rows, err := r.db.GetDB().Query(query, cutoff, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list thinking messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*ThinkingMessage
+	for rows.Next() {
+		message := &ThinkingMessage{}
+		if err := rows.Scan(&message.ChannelID, &message.MessageTS, &message.RunID,
+			&message.SessionID, &message.Owner, &message.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan thinking message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
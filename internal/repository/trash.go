@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TrashedSession is a soft-deleted session waiting to be restored or purged
+type TrashedSession struct {
+	SessionID        string    `db:"session_id"`
+	WorkingDirectory string    `db:"working_directory"`
+	UserPrompt       *string   `db:"user_prompt"`
+	DeletedAt        time.Time `db:"deleted_at"`
+	DeletedBy        *string   `db:"deleted_by"`
+	Exchanges        int       `db:"exchanges"`
+}
+
+// TrashSession soft-deletes a session and detaches it from every channel.
+// It returns sql.ErrNoRows if there is no live session with that ID.
+func (r *SessionRepository) TrashSession(sessionID, deletedBy string) error {
+	tx, err := r.db.GetDB().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow(`
+		UPDATE sessions SET deleted_at = NOW(), deleted_by = NULLIF($2, '')
+		WHERE session_id = $1 AND deleted_at IS NULL
+		RETURNING id`, sessionID, deletedBy).Scan(&id)
+	if err == sql.ErrNoRows {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to trash session: %w", err)
+	}
+
+	// Channels fall back to a new session, as if it had been deleted
+	clearChannelQuery := `
+		UPDATE slack_channels SET active_session_id = NULL, active_child_session_id = NULL, updated_at = NOW()
+		WHERE active_session_id = $1
+		   OR active_child_session_id IN (SELECT id FROM child_sessions WHERE root_parent_id = $1)`
+	if _, err := tx.Exec(clearChannelQuery, id); err != nil {
+		return fmt.Errorf("failed to clear channel state: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM slack_channel_user_sessions WHERE active_session_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to clear per-user channel state: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info("Moved session to trash",
+		zap.String("session_id", sessionID),
+		zap.String("deleted_by", deletedBy))
+	return nil
+}
+
+// RestoreSession takes a session out of the trash. It returns sql.ErrNoRows
+// if the session isn't in the trash.
+func (r *SessionRepository) RestoreSession(sessionID string) error {
+	result, err := r.db.GetDB().Exec(`
+		UPDATE sessions SET deleted_at = NULL, deleted_by = NULL, updated_at = NOW()
+		WHERE session_id = $1 AND deleted_at IS NOT NULL`, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to restore session: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check restored session: %w", err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	r.logger.Info("Restored session from trash", zap.String("session_id", sessionID))
+	return nil
+}
+
+// ListTrashedSessions returns sessions in the trash, most recently deleted first
+func (r *SessionRepository) ListTrashedSessions(limit int) ([]*TrashedSession, error) {
+	query := `
+		SELECT s.session_id, s.working_directory, s.user_prompt, s.deleted_at, s.deleted_by,
+			(SELECT COUNT(*) FROM child_sessions cs WHERE cs.root_parent_id = s.id) AS exchanges
+		FROM sessions s
+		WHERE s.deleted_at IS NOT NULL
+		ORDER BY s.deleted_at DESC
+		LIMIT $1`
+
+	rows, err := r.db.GetDB().Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trashed sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*TrashedSession
+	for rows.Next() {
+		session := &TrashedSession{}
+		if err := rows.Scan(&session.SessionID, &session.WorkingDirectory, &session.UserPrompt,
+			&session.DeletedAt, &session.DeletedBy, &session.Exchanges); err != nil {
+			return nil, fmt.Errorf("failed to scan trashed session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// PurgeTrashedSessions permanently deletes sessions trashed before cutoff and
+// returns their IDs
+func (r *SessionRepository) PurgeTrashedSessions(cutoff time.Time) ([]string, error) {
+	rows, err := r.db.GetDB().Query(`SELECT session_id FROM sessions WHERE deleted_at < $1`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sessions to purge: %w", err)
+	}
+
+	var sessionIDs []string
+	for rows.Next() {
+		var sessionID string
+		if err := rows.Scan(&sessionID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan session to purge: %w", err)
+		}
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find sessions to purge: %w", err)
+	}
+
+	var purged []string
+	for _, sessionID := range sessionIDs {
+		if err := r.DeleteSession(sessionID); err != nil {
+			return purged, err
+		}
+		purged = append(purged, sessionID)
+	}
+	return purged, nil
+}
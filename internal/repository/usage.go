@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type SessionUsage struct {
+	ID              int       `db:"id"`
+	SessionID       *int      `db:"session_id"`
+	ChannelID       string    `db:"channel_id"`
+	ClaudeSessionID *string   `db:"claude_session_id"`
+	Model           string    `db:"model"`
+	CostUSD         float64   `db:"cost_usd"`
+	ContextTokens   *int      `db:"context_tokens"`
+	DurationMS      *int      `db:"duration_ms"`
+	CreatedAt       time.Time `db:"created_at"`
+}
+
+type UsageRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewUsageRepository(db *database.Database, logger *zap.Logger) *UsageRepository {
+	return &UsageRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RunUsage is what one Claude run used, attributed to the user who started
+// it and their mapped identity. Empty strings and zero counts or durations are
+// stored as unknown.
+type RunUsage struct {
+	SessionID       string // Root session the run belongs to
+	ChannelID       string
+	UserID          string
+	Identity        string
+	ClaudeSessionID string
+	Model           string
+	CostUSD         float64
+	ContextTokens   int // Estimated context size after the run
+	TotalTokens     int // Tokens used across all of the run's model calls
+	Duration        time.Duration
+	EditedFiles     []string
+}
+
+// RecordUsage stores the cost, tokens, duration, and edited files of a single
+// Claude run against its root session
+func (r *UsageRepository) RecordUsage(u *RunUsage) error {
+	query := `
+		INSERT INTO session_usage (session_id, channel_id, user_id, identity, claude_session_id, model, cost_usd,
+		                           context_tokens, total_tokens, duration_ms, edited_files, created_at)
+		SELECT id, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7, NULLIF($8, 0), NULLIF($9, 0), NULLIF($10, 0), $11, NOW()
+		FROM sessions WHERE session_id = $1`
+
+	editedFiles := u.EditedFiles
+	if editedFiles == nil {
+		editedFiles = []string{}
+	}
+	result, err := r.db.GetDB().Exec(query, u.SessionID, u.ChannelID, u.UserID, u.Identity, u.ClaudeSessionID, u.Model,
+		u.CostUSD, u.ContextTokens, u.TotalTokens, u.Duration.Milliseconds(), pq.Array(editedFiles))
+	if err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("failed to record usage: session %s not found", u.SessionID)
+	}
+
+	r.logger.Debug("Usage recorded",
+		zap.String("session_id", u.SessionID),
+		zap.String("channel_id", u.ChannelID),
+		zap.String("user_id", u.UserID),
+		zap.String("identity", u.Identity),
+		zap.String("model", u.Model),
+		zap.Float64("cost_usd", u.CostUSD),
+		zap.Int("context_tokens", u.ContextTokens),
+		zap.Int("total_tokens", u.TotalTokens),
+		zap.Int("edited_files", len(u.EditedFiles)),
+		zap.Duration("duration", u.Duration))
+
+	return nil
+}
+
+// GetContextTokens returns the estimated context size after the run that
+// produced a Claude session, or 0 if it isn't known
+func (r *UsageRepository) GetContextTokens(claudeSessionID string) (int, error) {
+	query := `
+		SELECT COALESCE(context_tokens, 0) FROM session_usage
+		WHERE claude_session_id = $1
+		ORDER BY created_at DESC LIMIT 1`
+
+	var tokens int
+	err := r.db.GetDB().QueryRow(query, claudeSessionID).Scan(&tokens)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get context tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// GetSessionCost returns the total cost of all runs in a root session
+func (r *UsageRepository) GetSessionCost(sessionID string) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(u.cost_usd), 0)
+		FROM session_usage u
+		JOIN sessions s ON s.id = u.session_id
+		WHERE s.session_id = $1`
+
+	var total float64
+	if err := r.db.GetDB().QueryRow(query, sessionID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to get session cost: %w", err)
+	}
+
+	return total, nil
+}
+
+// SessionStats aggregates a root session's conversation and the runs
+// recorded against it
+type SessionStats struct {
+	CreatedAt time.Time
+	Exchanges int // Exchanges in the conversation tree, including those pruned into checkpoints
+	Branches  int // Exchanges no other exchange continues from
+
+	Runs        int // Runs recorded in session_usage, including /ask and /batch runs
+	CostUSD     float64
+	TotalTokens int
+	TokenRuns   int           // Runs with a known token count
+	AvgDuration time.Duration // Average over runs with a known duration; 0 if none
+	FilesEdited int           // Distinct files edited across runs
+	FileRuns    int           // Runs recorded since edited files were tracked
+}
+
+// GetSessionStats totals a root session's exchanges, branches, and usage. It
+// returns nil if the session doesn't exist.
+func (r *UsageRepository) GetSessionStats(sessionID string) (*SessionStats, error) {
+	query := `
+		SELECT s.created_at,
+		       (SELECT COALESCE(SUM(COALESCE(c.checkpoint_of, 1)), 0) FROM child_sessions c WHERE c.root_parent_id = s.id),
+		       (SELECT COUNT(*) FROM child_sessions c WHERE c.root_parent_id = s.id AND NOT EXISTS (
+		            SELECT 1 FROM child_sessions n WHERE n.root_parent_id = s.id AND n.previous_session_id = c.session_id)),
+		       COUNT(u.id), COALESCE(SUM(u.cost_usd), 0), COALESCE(SUM(u.total_tokens), 0), COUNT(u.total_tokens),
+		       COALESCE(AVG(u.duration_ms), 0), COUNT(u.edited_files),
+		       (SELECT COUNT(DISTINCT f) FROM session_usage e, UNNEST(e.edited_files) AS f WHERE e.session_id = s.id)
+		FROM sessions s
+		LEFT JOIN session_usage u ON u.session_id = s.id
+		WHERE s.session_id = $1
+		GROUP BY s.id, s.created_at`
+
+	stats := &SessionStats{}
+	var avgMS float64
+	err := r.db.GetDB().QueryRow(query, sessionID).Scan(&stats.CreatedAt, &stats.Exchanges, &stats.Branches,
+		&stats.Runs, &stats.CostUSD, &stats.TotalTokens, &stats.TokenRuns, &avgMS, &stats.FileRuns, &stats.FilesEdited)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session stats: %w", err)
+	}
+	stats.AvgDuration = time.Duration(avgMS * float64(time.Millisecond))
+	return stats, nil
+}
+
+// GetChannelCostSince returns the total cost of runs in a channel since a point in time
+func (r *UsageRepository) GetChannelCostSince(channelID string, since time.Time) (float64, error) {
+	query := `SELECT COALESCE(SUM(cost_usd), 0) FROM session_usage WHERE channel_id = $1 AND created_at >= $2`
+
+	var total float64
+	if err := r.db.GetDB().QueryRow(query, channelID, since).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to get channel cost: %w", err)
+	}
+
+	return total, nil
+}
+
+// UsageBucket aggregates the runs that ended within one slice of a time range
+type UsageBucket struct {
+	Start      time.Time
+	Runs       int // Successful runs
+	Failures   int // Runs recorded in failed_runs
+	CostUSD    float64
+	AvgLatency time.Duration // Average over runs with a known duration; 0 if none
+}
+
+// ErrorRate returns the share of failed runs in the bucket, from 0 to 1
+func (b UsageBucket) ErrorRate() float64 {
+	if total := b.Runs + b.Failures; total > 0 {
+		return float64(b.Failures) / float64(total)
+	}
+	return 0
+}
+
+// GetUsageTrend splits the time since a point into count buckets of the
+// given width and returns run volume, cost, failures, and latency for each,
+// including empty ones, oldest first
+func (r *UsageRepository) GetUsageTrend(since time.Time, width time.Duration, count int) ([]UsageBucket, error) {
+	if width <= 0 || count <= 0 {
+		return nil, fmt.Errorf("failed to get usage trend: invalid buckets %d x %s", count, width)
+	}
+
+	query := `
+		WITH runs AS (
+			SELECT FLOOR(EXTRACT(EPOCH FROM created_at::timestamptz - $1::timestamptz) / $2)::int AS bucket,
+			       COUNT(*) AS runs, SUM(cost_usd) AS cost, AVG(duration_ms) AS avg_ms
+			FROM session_usage
+			WHERE created_at::timestamptz >= $1::timestamptz
+			GROUP BY 1
+		), failures AS (
+			SELECT FLOOR(EXTRACT(EPOCH FROM created_at - $1::timestamptz) / $2)::int AS bucket,
+			       COUNT(*) AS failures
+			FROM failed_runs
+			WHERE created_at >= $1::timestamptz
+			GROUP BY 1
+		)
+		SELECT COALESCE(r.bucket, f.bucket), COALESCE(r.runs, 0), COALESCE(r.cost, 0),
+		       COALESCE(r.avg_ms, 0), COALESCE(f.failures, 0)
+		FROM runs r FULL OUTER JOIN failures f ON r.bucket = f.bucket`
+
+	rows, err := r.db.GetDB().Query(query, since, width.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage trend: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := make([]UsageBucket, count)
+	for i := range buckets {
+		buckets[i].Start = since.Add(time.Duration(i) * width)
+	}
+
+	for rows.Next() {
+		var index, runs, failures int
+		var cost, avgMS float64
+		if err := rows.Scan(&index, &runs, &cost, &avgMS, &failures); err != nil {
+			return nil, fmt.Errorf("failed to scan usage trend: %w", err)
+		}
+		if index < 0 || index >= count {
+			continue
+		}
+		buckets[index].Runs = runs
+		buckets[index].Failures = failures
+		buckets[index].CostUSD = cost
+		buckets[index].AvgLatency = time.Duration(avgMS) * time.Millisecond
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get usage trend: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// UserCost is the spend attributed to one person
+type UserCost struct {
+	Who     string // Mapped identity, or the Slack user ID if the runs weren't mapped
+	Mapped  bool
+	Runs    int
+	CostUSD float64
+}
+
+// GetCostByUserSince returns spend since a point per identity, or per Slack
+// user for unmapped runs, highest first. Runs recorded before attribution
+// are left out.
+func (r *UsageRepository) GetCostByUserSince(since time.Time, limit int) ([]UserCost, error) {
+	query := `
+		SELECT COALESCE(identity, user_id), identity IS NOT NULL, COUNT(*), COALESCE(SUM(cost_usd), 0)
+		FROM session_usage
+		WHERE created_at::timestamptz >= $1::timestamptz AND user_id IS NOT NULL
+		GROUP BY 1, 2
+		ORDER BY 4 DESC
+		LIMIT $2`
+
+	rows, err := r.db.GetDB().Query(query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost by user: %w", err)
+	}
+	defer rows.Close()
+
+	var costs []UserCost
+	for rows.Next() {
+		var cost UserCost
+		if err := rows.Scan(&cost.Who, &cost.Mapped, &cost.Runs, &cost.CostUSD); err != nil {
+			return nil, fmt.Errorf("failed to scan cost by user: %w", err)
+		}
+		costs = append(costs, cost)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get cost by user: %w", err)
+	}
+	return costs, nil
+}
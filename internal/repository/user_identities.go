@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/database"
+)
+
+type UserIdentityRepository struct {
+	db     *database.Database
+	logger *zap.Logger
+}
+
+func NewUserIdentityRepository(db *database.Database, logger *zap.Logger) *UserIdentityRepository {
+	return &UserIdentityRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SetUserIdentity maps a Slack user to a system identity, replacing any
+// earlier mapping
+func (r *UserIdentityRepository) SetUserIdentity(slackUserID, identity, updatedBy string) error {
+	query := `
+		INSERT INTO user_identities (slack_user_id, identity, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (slack_user_id) DO UPDATE
+		SET identity = EXCLUDED.identity, updated_by = EXCLUDED.updated_by, updated_at = NOW()`
+
+	if _, err := r.db.GetDB().Exec(query, slackUserID, identity, updatedBy); err != nil {
+		return fmt.Errorf("failed to set user identity: %w", err)
+	}
+
+	r.logger.Info("User identity mapped",
+		zap.String("user_id", slackUserID),
+		zap.String("identity", identity),
+		zap.String("updated_by", updatedBy))
+	return nil
+}
+
+// DeleteUserIdentity removes a Slack user's mapping, reporting whether there
+// was one
+func (r *UserIdentityRepository) DeleteUserIdentity(slackUserID string) (bool, error) {
+	result, err := r.db.GetDB().Exec(`DELETE FROM user_identities WHERE slack_user_id = $1`, slackUserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete user identity: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete user identity: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+// ListUserIdentities returns every mapping, keyed by Slack user ID
+func (r *UserIdentityRepository) ListUserIdentities() (map[string]string, error) {
+	rows, err := r.db.GetDB().Query(`SELECT slack_user_id, identity FROM user_identities`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := make(map[string]string)
+	for rows.Next() {
+		var userID, identity string
+		if err := rows.Scan(&userID, &identity); err != nil {
+			return nil, fmt.Errorf("failed to scan user identity: %w", err)
+		}
+		identities[userID] = identity
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list user identities: %w", err)
+	}
+	return identities, nil
+}
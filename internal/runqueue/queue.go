@@ -0,0 +1,196 @@
+// Package runqueue limits how many Claude runs execute at once, starting
+// waiting runs by priority and preempting batch runs for urgent ones
+package runqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Priority orders waiting runs; higher runs first
+type Priority int
+
+const (
+	PriorityBatch Priority = iota
+	PriorityNormal
+	PriorityUrgent
+)
+
+// priorityNames are the names used in configuration and /priority
+var priorityNames = map[Priority]string{
+	PriorityBatch:  "batch",
+	PriorityNormal: "normal",
+	PriorityUrgent: "urgent",
+}
+
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+// ParsePriority parses a priority name
+func ParsePriority(name string) (Priority, error) {
+	for priority, n := range priorityNames {
+		if n == name {
+			return priority, nil
+		}
+	}
+	return PriorityNormal, fmt.Errorf("unknown priority %q (use urgent, normal, or batch)", name)
+}
+
+// ErrPreempted is the cancellation cause of a preemptible run stopped to
+// make room for an urgent one. The caller should requeue it.
+var ErrPreempted = errors.New("preempted by an urgent run")
+
+// slot is a running or waiting run
+type slot struct {
+	priority    Priority
+	preemptible bool
+	seq         uint64
+	ready       chan struct{} // closed when a waiting run may start
+	cancel      context.CancelCauseFunc
+	preempted   bool
+}
+
+// Queue hands out a fixed number of run slots
+type Queue struct {
+	size    int
+	seq     uint64
+	running map[*slot]struct{}
+	waiting []*slot // Highest priority first, then oldest
+	mu      sync.Mutex
+}
+
+// New creates a queue with size slots; a size of zero or less never queues
+func New(size int) *Queue {
+	return &Queue{
+		size:    size,
+		running: make(map[*slot]struct{}),
+	}
+}
+
+// Acquire waits for a slot. A preemptible run's context is canceled with
+// cause ErrPreempted when an urgent run needs its slot, so only runs that can
+// safely start over should be preemptible. release must be called when the
+// run ends. Acquire fails only if ctx is done first.
+func (q *Queue) Acquire(ctx context.Context, priority Priority, preemptible bool) (context.Context, func(), error) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+
+	q.mu.Lock()
+	q.seq++
+	s := &slot{priority: priority, preemptible: preemptible, seq: q.seq, ready: make(chan struct{}), cancel: cancel}
+	if q.size <= 0 || len(q.running) < q.size {
+		q.running[s] = struct{}{}
+		close(s.ready)
+	} else {
+		q.enqueue(s)
+		if priority == PriorityUrgent {
+			q.preempt()
+		}
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-s.ready:
+	case <-ctx.Done():
+		q.mu.Lock()
+		select {
+		case <-s.ready:
+			// Started just as ctx ended; hand the slot back
+			q.release(s)
+		default:
+			q.remove(s)
+		}
+		q.mu.Unlock()
+		cancel(ctx.Err())
+		return nil, nil, ctx.Err()
+	}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			q.mu.Lock()
+			q.release(s)
+			q.mu.Unlock()
+			cancel(context.Canceled)
+		})
+	}
+	return runCtx, release, nil
+}
+
+// Stats reports how many runs are running and waiting
+func (q *Queue) Stats() (running, waiting int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.running), len(q.waiting)
+}
+
+// enqueue adds a waiting run in priority order
+func (q *Queue) enqueue(s *slot) {
+	i := sort.Search(len(q.waiting), func(i int) bool {
+		w := q.waiting[i]
+		return w.priority < s.priority || (w.priority == s.priority && w.seq > s.seq)
+	})
+	q.waiting = append(q.waiting, nil)
+	copy(q.waiting[i+1:], q.waiting[i:])
+	q.waiting[i] = s
+}
+
+// remove drops a waiting run that gave up
+func (q *Queue) remove(s *slot) {
+	for i, w := range q.waiting {
+		if w == s {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return
+		}
+	}
+}
+
+// preempt cancels the newest preemptible batch-priority run, unless one is
+// already being preempted for every urgent run waiting. Its slot goes to the urgent run
+// when it releases.
+func (q *Queue) preempt() {
+	urgent, preempting := 0, 0
+	for _, w := range q.waiting {
+		if w.priority == PriorityUrgent {
+			urgent++
+		}
+	}
+	var victim *slot
+	for s := range q.running {
+		if !s.preemptible || s.priority != PriorityBatch {
+			continue
+		}
+		if s.preempted {
+			preempting++
+			continue
+		}
+		if victim == nil || s.seq > victim.seq {
+			victim = s
+		}
+	}
+	if victim == nil || preempting >= urgent {
+		return
+	}
+	victim.preempted = true
+	victim.cancel(ErrPreempted)
+}
+
+// release frees a slot and starts the next waiting run
+func (q *Queue) release(s *slot) {
+	if _, ok := q.running[s]; !ok {
+		return
+	}
+	delete(q.running, s)
+	for len(q.waiting) > 0 && (q.size <= 0 || len(q.running) < q.size) {
+		next := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		q.running[next] = struct{}{}
+		close(next.ready)
+	}
+}
@@ -0,0 +1,146 @@
+package runqueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func acquire(t *testing.T, q *Queue, priority Priority) (context.Context, func()) {
+	t.Helper()
+	ctx, release, err := q.Acquire(context.Background(), priority, priority == PriorityBatch)
+	if err != nil {
+		t.Fatalf("Acquire(%v) failed: %v", priority, err)
+	}
+	return ctx, release
+}
+
+// acquireAsync starts waiting for a slot and reports the priority once it
+// gets one
+func acquireAsync(q *Queue, priority Priority, started chan<- Priority) {
+	go func() {
+		_, release, err := q.Acquire(context.Background(), priority, priority == PriorityBatch)
+		if err != nil {
+			return
+		}
+		started <- priority
+		release()
+	}()
+}
+
+func waitForWaiting(t *testing.T, q *Queue, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, waiting := q.Stats(); waiting == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d queued runs", want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestQueue_PriorityOrder(t *testing.T) {
+	q := New(1)
+	_, release := acquire(t, q, PriorityNormal)
+
+	started := make(chan Priority, 3)
+	acquireAsync(q, PriorityBatch, started)
+	waitForWaiting(t, q, 1)
+	acquireAsync(q, PriorityNormal, started)
+	waitForWaiting(t, q, 2)
+	acquireAsync(q, PriorityUrgent, started)
+	waitForWaiting(t, q, 3)
+
+	release()
+	for _, want := range []Priority{PriorityUrgent, PriorityNormal, PriorityBatch} {
+		if got := <-started; got != want {
+			t.Fatalf("Started %v, want %v", got, want)
+		}
+	}
+}
+
+func TestQueue_PreemptsBatch(t *testing.T) {
+	q := New(1)
+	batchCtx, releaseBatch := acquire(t, q, PriorityBatch)
+
+	started := make(chan Priority, 1)
+	acquireAsync(q, PriorityUrgent, started)
+
+	select {
+	case <-batchCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the batch run to be preempted")
+	}
+	if !errors.Is(context.Cause(batchCtx), ErrPreempted) {
+		t.Errorf("Cause = %v, want ErrPreempted", context.Cause(batchCtx))
+	}
+
+	releaseBatch()
+	if got := <-started; got != PriorityUrgent {
+		t.Errorf("Started %v, want urgent", got)
+	}
+}
+
+func TestQueue_NormalDoesNotPreempt(t *testing.T) {
+	q := New(1)
+	batchCtx, releaseBatch := acquire(t, q, PriorityBatch)
+	defer releaseBatch()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := q.Acquire(ctx, PriorityNormal, false); err == nil {
+		t.Fatal("Expected Acquire to time out while the batch run holds the slot")
+	}
+	if batchCtx.Err() != nil {
+		t.Error("A normal run must not preempt a batch run")
+	}
+	if running, waiting := q.Stats(); running != 1 || waiting != 0 {
+		t.Errorf("Stats = %d, %d; want 1, 0", running, waiting)
+	}
+}
+
+func TestQueue_OnlyPreemptibleRunsArePreempted(t *testing.T) {
+	q := New(1)
+	ctx, release, err := q.Acquire(context.Background(), PriorityBatch, false)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	started := make(chan Priority, 1)
+	acquireAsync(q, PriorityUrgent, started)
+	waitForWaiting(t, q, 1)
+	if ctx.Err() != nil {
+		t.Fatal("A run that isn't preemptible must not be canceled")
+	}
+
+	release()
+	if got := <-started; got != PriorityUrgent {
+		t.Errorf("Started %v, want urgent", got)
+	}
+}
+
+func TestQueue_Unlimited(t *testing.T) {
+	q := New(0)
+	for i := 0; i < 10; i++ {
+		acquire(t, q, PriorityBatch)
+	}
+	if running, _ := q.Stats(); running != 10 {
+		t.Errorf("running = %d, want 10", running)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for _, name := range []string{"urgent", "normal", "batch"} {
+		priority, err := ParsePriority(name)
+		if err != nil || priority.String() != name {
+			t.Errorf("ParsePriority(%q) = %v, %v", name, priority, err)
+		}
+	}
+	if _, err := ParsePriority("asap"); err == nil {
+		t.Error("Expected an error for an unknown priority")
+	}
+}
@@ -0,0 +1,76 @@
+package session
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+// SetChannelLifecycleState records that a channel was archived, unarchived,
+// or deleted
+func (m *DatabaseManager) SetChannelLifecycleState(channelID, state string) error {
+	return m.repository.SetChannelLifecycleState(channelID, state)
+}
+
+// GetChannelLifecycleState returns a channel's lifecycle state
+func (m *DatabaseManager) GetChannelLifecycleState(channelID string) (string, error) {
+	return m.repository.GetChannelLifecycleState(channelID)
+}
+
+// ClearChannelProcessing drops the in-flight run counts of a channel's
+// sessions, so a channel that went away doesn't leave them busy. It returns
+// the sessions that were marked as processing.
+func (m *DatabaseManager) ClearChannelProcessing(channelID string) ([]string, error) {
+	sessionIDs, err := m.repository.ListChannelSessionIDs(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var cleared []string
+	for _, sessionID := range sessionIDs {
+		if m.processing[sessionID] > 0 {
+			delete(m.processing, sessionID)
+			cleared = append(cleared, sessionID)
+		}
+	}
+	return cleared, nil
+}
+
+// CloseChannelSessions detaches a channel's active sessions. The sessions
+// themselves are kept and can still be resumed elsewhere with /session.
+func (m *DatabaseManager) CloseChannelSessions(channelID string) error {
+	if err := m.repository.DetachChannelSessions(channelID); err != nil {
+		return err
+	}
+	m.listings.invalidate()
+	return nil
+}
+
+// TrashChannelSessions moves every session started in a channel to the
+// trash. Sessions with a run still in progress are skipped.
+func (m *DatabaseManager) TrashChannelSessions(channelID, userID string) ([]string, error) {
+	sessionIDs, err := m.repository.ListChannelSessionIDs(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	var trashed []string
+	for _, sessionID := range sessionIDs {
+		err := m.TrashSession(sessionID, userID)
+		switch {
+		case err == nil:
+			trashed = append(trashed, sessionID)
+		case errors.Is(err, ErrSessionBusy), errors.Is(err, ErrSessionNotFound):
+			m.logger.Info("Skipped trashing channel session",
+				zap.String("channel_id", channelID),
+				zap.String("session_id", sessionID),
+				zap.Error(err))
+		default:
+			return trashed, err
+		}
+	}
+	return trashed, m.repository.DetachChannelSessions(channelID)
+}
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"errors"
 	"time"
 
 	"github.com/ghabxph/claude-on-slack/internal/config"
@@ -8,6 +9,18 @@ import (
 	"github.com/ghabxph/claude-on-slack/internal/repository"
 )
 
+// ErrSessionBusy is returned when deleting or switching away from a session
+// while a Claude run is in progress on it
+var ErrSessionBusy = errors.New("session busy; use /stop first")
+
+// ErrSessionNotFound is returned when trashing or restoring a session that
+// doesn't exist in the expected state
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrChannelArchived is returned when moving a session to a channel that is
+// archived or deleted
+var ErrChannelArchived = repository.ErrChannelArchived
+
 // SessionManager interface defines the contract for session management
 type SessionManager interface {
 	// Session lifecycle
@@ -20,7 +33,6 @@ type SessionManager interface {
 	// Session operations
 	UpdateSessionActivity(sessionID string) error
 	AddMessageToSession(sessionID string, message claude.Message) error
-	CheckRateLimit(sessionID string) (bool, time.Duration, error)
 	GetLatestChildSessionID(sessionID string) (*string, error)
 
 	// Permission and state management
@@ -63,6 +75,150 @@ type ChannelPermissionManager interface {
 	GetPermissionModeForChannel(channelID string) (config.PermissionMode, error)
 }
 
+// ChannelPermissionExpiryManager is an optional extension interface for
+// channel permission modes that revert to default after a TTL
+type ChannelPermissionExpiryManager interface {
+	SetPermissionModeForChannelWithExpiry(channelID string, mode config.PermissionMode, ttl time.Duration) (time.Time, error)
+	GetPermissionExpiryForChannel(channelID string) (*time.Time, error)
+	RevertExpiredPermissions() ([]string, error)
+}
+
+// ChannelContextManager is an optional extension interface for the per-channel
+// topic/purpose prompt context toggle
+type ChannelContextManager interface {
+	SetChannelContextEnabled(channelID string, enabled bool) error
+	GetChannelContextEnabled(channelID string) (*bool, error)
+}
+
+// SessionSummaryManager is an optional extension interface for storing
+// conversation summaries: per exchange, reused as recaps when switching back
+// to a session, and per root session when it is closed or deleted
+type SessionSummaryManager interface {
+	SetChildSessionSummary(childID int, summary string) error
+	SetSessionSummary(sessionID, summary string) error
+}
+
+// SessionTitleManager is an optional extension interface for the short
+// titles generated for sessions after their first exchange
+type SessionTitleManager interface {
+	SetSessionTitle(sessionID, title string) (bool, error)
+	GetSessionTitles(sessionIDs []string) (map[string]string, error)
+}
+
+// SessionBranchManager is an optional extension interface for walking the
+// branches of a conversation tree
+type SessionBranchManager interface {
+	GetChildSessionBySessionID(sessionID string) (*repository.ChildSession, error)
+	LoadConversationTree(rootParentID int) ([]*repository.ChildSession, error)
+	LoadSessionByID(id int) (*repository.Session, error)
+}
+
+// SessionTrashManager is an optional extension interface for soft-deleted
+// sessions, which can be restored until they are purged
+type SessionTrashManager interface {
+	TrashSession(sessionID, userID string) error
+	RestoreSession(sessionID string) error
+	ListTrashedSessions(limit int) ([]*repository.TrashedSession, error)
+	PurgeTrashedSessions(cutoff time.Time) ([]string, error)
+}
+
+// SessionPruneManager is an optional extension interface for collapsing old
+// exchanges into summarized checkpoints
+type SessionPruneManager interface {
+	FindPruneRuns(cutoff time.Time, keep, chunk, limit int) ([]*repository.PruneRun, error)
+	CollapsePruneRun(run *repository.PruneRun, checkpointText, summary string) error
+}
+
+// DefaultPermissionManager is an optional extension interface for the
+// workspace-wide permission mode new channels start with
+type DefaultPermissionManager interface {
+	SetDefaultPermissionMode(mode config.PermissionMode, userID string) error
+	GetDefaultPermissionMode() (config.PermissionMode, error)
+}
+
+// DeploymentNoticeManager is an optional extension interface for sending a
+// deployment notification once per version across restarts and instances
+type DeploymentNoticeManager interface {
+	ClaimDeploymentNotice(version string) (string, bool, error)
+	ReleaseDeploymentNotice(version, previous string) error
+}
+
+// SessionMode controls whether users in a channel share one active session
+type SessionMode string
+
+const (
+	SessionModeShared  SessionMode = "shared"
+	SessionModePerUser SessionMode = "per-user"
+)
+
+// CacheInvalidationListener is an optional extension interface for session
+// managers that keep their cache coherent with other bot instances
+type CacheInvalidationListener interface {
+	StartInvalidationListener() error
+}
+
+// ChannelSessionModeManager is an optional extension interface for channels
+// where each user has their own active session
+type ChannelSessionModeManager interface {
+	SetChannelSessionMode(channelID string, mode SessionMode) error
+	GetChannelSessionMode(channelID string) (SessionMode, error)
+	SwitchToSessionForUser(channelID, userID, sessionID string) error
+}
+
+// ChannelTypeManager is an optional extension interface for session managers
+// that know whether a channel is public, private, or a DM
+type ChannelTypeManager interface {
+	RecordChannelType(channelID, channelType string) error
+	ListSessionsForChannel(channelID, channelType string, limit int) ([]SessionInfo, error)
+}
+
+// SessionPrefixFinder is an optional extension interface for switching to a
+// session by the first characters of its ID, as listings show it
+type SessionPrefixFinder interface {
+	FindSessionsByIDPrefix(channelID, prefix string, limit int) ([]SessionInfo, error)
+}
+
+// ChannelLifecycleManager is an optional extension interface for reacting
+// to channels being archived, unarchived, or deleted
+type ChannelLifecycleManager interface {
+	SetChannelLifecycleState(channelID, state string) error
+	GetChannelLifecycleState(channelID string) (string, error)
+	ClearChannelProcessing(channelID string) ([]string, error)
+	CloseChannelSessions(channelID string) error
+	TrashChannelSessions(channelID, userID string) ([]string, error)
+}
+
+// IdentityResolverSetter is an optional extension interface for recording
+// a session's system_user as the Slack user's mapped identity
+type IdentityResolverSetter interface {
+	SetIdentityResolver(resolve func(slackUserID string) string)
+}
+
+// ChannelSessionMover is an optional extension interface for handing a
+// channel's active session over to another channel
+type ChannelSessionMover interface {
+	MoveActiveSession(fromChannelID, toChannelID, userID string) (SessionInfo, error)
+}
+
+// ChannelStateReader is an optional extension interface for reading a
+// channel's stored state, as the admin API reports it
+type ChannelStateReader interface {
+	GetChannelState(channelID string) (*repository.SlackChannel, error)
+}
+
+// SessionVisibilityChecker is an optional extension interface for checking
+// a session may be shown in a channel, by the same rule as listings
+type SessionVisibilityChecker interface {
+	SessionVisibleInChannel(sessionID, channelID string) (bool, error)
+}
+
+// ActiveSessionCloser is an optional extension interface for managers that
+// keep a channel's active session in storage rather than per user
+type ActiveSessionCloser interface {
+	ActiveSessionID(channelID, userID string) (string, error)
+	CloseActiveSession(channelID, userID string) error
+}
+
 // SessionInfo provides a common interface for session data
 type SessionInfo interface {
 	GetID() string
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+// InvalidationChannel is the Postgres NOTIFY channel bot instances use to
+// tell each other which cached sessions changed
+const InvalidationChannel = "claude_on_slack_session_invalidation"
+
+// invalidation is the NOTIFY payload: a session whose cached copy and
+// conversation tree other instances must drop
+type invalidation struct {
+	Origin    string `json:"origin"` // Instance that sent it, to skip our own
+	SessionID string `json:"session_id"`
+	ID        int    `json:"id,omitempty"` // Database ID, when known
+}
+
+// StartInvalidationListener subscribes to invalidations from other bot
+// instances so a session switched, deleted, or extended elsewhere isn't
+// served from this instance's cache
+func (m *DatabaseManager) StartInvalidationListener() error {
+	stop, err := m.db.Listen(InvalidationChannel, m.handleInvalidation, m.flushCache)
+	if err != nil {
+		return fmt.Errorf("failed to start session invalidation listener: %w", err)
+	}
+
+	m.mu.Lock()
+	m.stopListening = stop
+	m.mu.Unlock()
+
+	m.logger.Info("Listening for session cache invalidations", zap.String("instance_id", m.instanceID))
+	return nil
+}
+
+// broadcastInvalidation drops this instance's cached listings and tells
+// other instances to drop their cached copy of a session and their listings.
+// Failures are logged; the other caches then stay stale until the session is
+// evicted, the listings expire, or the instance restarts.
+func (m *DatabaseManager) broadcastInvalidation(session *repository.Session) {
+	m.listings.invalidate()
+	if m.db == nil || session == nil {
+		return
+	}
+
+	payload, err := json.Marshal(invalidation{Origin: m.instanceID, SessionID: session.SessionID, ID: session.ID})
+	if err != nil {
+		m.logger.Error("Failed to encode session invalidation", zap.Error(err))
+		return
+	}
+	if err := m.db.Notify(InvalidationChannel, string(payload)); err != nil {
+		m.logger.Warn("Failed to broadcast session invalidation",
+			zap.String("session_id", session.SessionID),
+			zap.Error(err))
+	}
+}
+
+// handleInvalidation drops a session another instance changed
+func (m *DatabaseManager) handleInvalidation(payload string) {
+	var msg invalidation
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		m.logger.Warn("Ignoring malformed session invalidation", zap.String("payload", payload), zap.Error(err))
+		return
+	}
+	if msg.Origin == m.instanceID {
+		return
+	}
+
+	m.evictSession(msg.SessionID, msg.ID)
+	m.listings.invalidate()
+	m.logger.Debug("Evicted session changed by another instance",
+		zap.String("session_id", msg.SessionID),
+		zap.String("origin", msg.Origin))
+}
+
+// evictSession drops a session and its conversation tree from the cache
+func (m *DatabaseManager) evictSession(sessionID string, id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if session, ok := m.sessionLookup[sessionID]; ok {
+		delete(m.conversationTrees, session.ID)
+	}
+	if id != 0 {
+		delete(m.conversationTrees, id)
+	}
+	delete(m.sessionLookup, sessionID)
+}
+
+// flushCache drops every cached session and conversation tree. Used after
+// the listener reconnects, since invalidations may have been missed.
+func (m *DatabaseManager) flushCache() {
+	m.mu.Lock()
+	m.sessionLookup = make(map[string]*repository.Session)
+	m.conversationTrees = make(map[int][]*repository.ChildSession)
+	m.mu.Unlock()
+	m.listings.invalidate()
+
+	m.logger.Info("Flushed session cache after invalidation listener reconnected")
+}
+
+// newInstanceID identifies this process in invalidation payloads
+func newInstanceID() string {
+	return uuid.New().String()
+}
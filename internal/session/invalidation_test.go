@@ -0,0 +1,69 @@
+package session
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func newCachedManager(t *testing.T) *DatabaseManager {
+	t.Helper()
+	m := NewDatabaseManager(&config.Config{}, zap.NewNop(), nil, nil)
+	m.sessionLookup["s1"] = &repository.Session{ID: 1, SessionID: "s1"}
+	m.sessionLookup["s2"] = &repository.Session{ID: 2, SessionID: "s2"}
+	m.conversationTrees[1] = []*repository.ChildSession{{ID: 10}}
+	m.conversationTrees[2] = []*repository.ChildSession{{ID: 20}}
+	return m
+}
+
+func invalidationPayload(t *testing.T, msg invalidation) string {
+	t.Helper()
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Failed to encode invalidation: %v", err)
+	}
+	return string(payload)
+}
+
+func TestHandleInvalidation(t *testing.T) {
+	m := newCachedManager(t)
+
+	m.handleInvalidation(invalidationPayload(t, invalidation{Origin: "other", SessionID: "s1", ID: 1}))
+	if _, ok := m.sessionLookup["s1"]; ok {
+		t.Error("Expected s1 to be evicted")
+	}
+	if _, ok := m.conversationTrees[1]; ok {
+		t.Error("Expected s1's conversation tree to be evicted")
+	}
+	if _, ok := m.sessionLookup["s2"]; !ok {
+		t.Error("Expected s2 to stay cached")
+	}
+
+	// Our own broadcasts are ignored
+	m.handleInvalidation(invalidationPayload(t, invalidation{Origin: m.instanceID, SessionID: "s2", ID: 2}))
+	if _, ok := m.sessionLookup["s2"]; !ok {
+		t.Error("Expected our own invalidation to be ignored")
+	}
+
+	m.handleInvalidation("not json")
+	if len(m.sessionLookup) != 1 {
+		t.Error("Expected a malformed payload to be ignored")
+	}
+}
+
+func TestFlushCache(t *testing.T) {
+	m := newCachedManager(t)
+	m.SetProcessing("s1", true)
+
+	m.flushCache()
+	if len(m.sessionLookup) != 0 || len(m.conversationTrees) != 0 {
+		t.Errorf("Expected an empty cache, got %d sessions and %d trees", len(m.sessionLookup), len(m.conversationTrees))
+	}
+	if !m.IsProcessing("s1") {
+		t.Error("Expected in-flight runs to be kept")
+	}
+}
@@ -0,0 +1,113 @@
+package session
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// listingCache briefly keeps session and path listings, which /session help,
+// the pickers, and composer each query on every use. Any session change
+// invalidates every entry, since a session shows up in listings for
+// channels other than its own.
+type listingCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]listingEntry
+	version int // Bumped by invalidate, so a load racing a change isn't cached
+}
+
+type listingEntry struct {
+	value    interface{}
+	cachedAt time.Time
+}
+
+// newListingCache creates a cache whose entries expire after ttl. A ttl of
+// zero disables caching.
+func newListingCache(ttl time.Duration) *listingCache {
+	return &listingCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]listingEntry),
+	}
+}
+
+// allSessionsKey, channelSessionsKey, and knownPathsKey name the cached
+// listings, including every argument that changes the query
+func allSessionsKey(limit int) string {
+	return fmt.Sprintf("all:%d", limit)
+}
+
+func channelSessionsKey(channelID, channelType string, limit int) string {
+	return fmt.Sprintf("channel:%s:%s:%d", channelID, channelType, limit)
+}
+
+func knownPathsKey(limit int) string {
+	return fmt.Sprintf("paths:%d", limit)
+}
+
+// get returns a cached listing if it has not expired, and the version to
+// pass to set when it has
+func (c *listingCache) get(key string) (interface{}, int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || c.ttl <= 0 || c.now().Sub(entry.cachedAt) >= c.ttl {
+		return nil, c.version, false
+	}
+	return entry.value, c.version, true
+}
+
+// set stores a listing loaded at version, unless the cache was invalidated
+// since
+func (c *listingCache) set(key string, version int, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 || version != c.version {
+		return
+	}
+	c.entries[key] = listingEntry{value: value, cachedAt: c.now()}
+}
+
+// invalidate drops every cached listing
+func (c *listingCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]listingEntry)
+	c.version++
+}
+
+// cachedSessions returns a listing from the cache or loads and caches it.
+// Callers get their own copy of the slice.
+func (c *listingCache) cachedSessions(key string, load func() ([]SessionInfo, error)) ([]SessionInfo, error) {
+	value, version, ok := c.get(key)
+	if ok {
+		return append([]SessionInfo(nil), value.([]SessionInfo)...), nil
+	}
+
+	sessions, err := load()
+	if err != nil {
+		return nil, err
+	}
+	c.set(key, version, sessions)
+	return append([]SessionInfo(nil), sessions...), nil
+}
+
+// cachedPaths is cachedSessions for working directory listings
+func (c *listingCache) cachedPaths(key string, load func() ([]string, error)) ([]string, error) {
+	value, version, ok := c.get(key)
+	if ok {
+		return append([]string(nil), value.([]string)...), nil
+	}
+
+	paths, err := load()
+	if err != nil {
+		return nil, err
+	}
+	c.set(key, version, paths)
+	return append([]string(nil), paths...), nil
+}
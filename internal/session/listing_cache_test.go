@@ -0,0 +1,107 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestListingCache(t *testing.T) {
+	now := time.Now()
+	c := newListingCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	loads := 0
+	load := func() ([]string, error) {
+		loads++
+		return []string{"/srv/app"}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		paths, err := c.cachedPaths(knownPathsKey(10), load)
+		if err != nil || len(paths) != 1 || paths[0] != "/srv/app" {
+			t.Fatalf("cachedPaths() = %v, %v", paths, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("Expected one load within the TTL, got %d", loads)
+	}
+
+	// A different limit is a different listing
+	c.cachedPaths(knownPathsKey(5), load)
+	if loads != 2 {
+		t.Errorf("Expected a load for another limit, got %d loads", loads)
+	}
+
+	now = now.Add(time.Minute)
+	c.cachedPaths(knownPathsKey(10), load)
+	if loads != 3 {
+		t.Errorf("Expected an expired listing to be reloaded, got %d loads", loads)
+	}
+
+	c.invalidate()
+	c.cachedPaths(knownPathsKey(10), load)
+	if loads != 4 {
+		t.Errorf("Expected an invalidated listing to be reloaded, got %d loads", loads)
+	}
+}
+
+func TestListingCache_CallersGetCopies(t *testing.T) {
+	c := newListingCache(time.Minute)
+	load := func() ([]string, error) { return []string{"/a", "/b"}, nil }
+
+	paths, _ := c.cachedPaths(knownPathsKey(10), load)
+	paths[0] = "/changed"
+
+	again, _ := c.cachedPaths(knownPathsKey(10), load)
+	if again[0] != "/a" {
+		t.Errorf("Expected the cached listing to be unaffected by callers, got %v", again)
+	}
+}
+
+func TestListingCache_SkipsRacingLoadsAndErrors(t *testing.T) {
+	c := newListingCache(time.Minute)
+
+	loads := 0
+	racing := func() ([]SessionInfo, error) {
+		loads++
+		if loads == 1 {
+			c.invalidate() // A session changed while the listing was loading
+		}
+		return nil, nil
+	}
+	c.cachedSessions(allSessionsKey(10), racing)
+	c.cachedSessions(allSessionsKey(10), racing)
+	if loads != 2 {
+		t.Errorf("Expected a load that raced an invalidation not to be cached, got %d loads", loads)
+	}
+
+	failures := 0
+	failing := func() ([]SessionInfo, error) {
+		failures++
+		return nil, errors.New("database unavailable")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.cachedSessions(channelSessionsKey("C1", "", 10), failing); err == nil {
+			t.Error("Expected the load error to be returned")
+		}
+	}
+	if failures != 2 {
+		t.Errorf("Expected failed loads not to be cached, got %d loads", failures)
+	}
+}
+
+func TestListingCache_Disabled(t *testing.T) {
+	c := newListingCache(0)
+
+	loads := 0
+	load := func() ([]string, error) {
+		loads++
+		return nil, nil
+	}
+	c.cachedPaths(knownPathsKey(10), load)
+	c.cachedPaths(knownPathsKey(10), load)
+	if loads != 2 {
+		t.Errorf("Expected every call to load with caching disabled, got %d loads", loads)
+	}
+}
@@ -33,7 +33,6 @@ type Session struct {
 	Context       map[string]interface{} `json:"context"`
 	Active        bool                   `json:"is_active"`
 	TokensUsed    int                    `json:"tokens_used"`
-	RateLimitInfo *RateLimitInfo         `json:"rate_limit_info"`
 	ExecutionMutex  sync.Mutex             `json:"-"` // Prevents concurrent executions within same session
 	ClaudeSessionID string                 `json:"claude_session_id"` // Current Claude Code session ID
 	MessageQueue    *MessageQueue          `json:"message_queue"`     // Queue for combining messages
@@ -41,15 +40,6 @@ type Session struct {
 	LatestResponse  string                 `json:"latest_response"`   // Latest raw JSON response from Claude
 }
 
-// RateLimitInfo tracks rate limiting for a session
-type RateLimitInfo struct {
-	RequestCount    int       `json:"request_count"`
-	LastRequestTime time.Time `json:"last_request_time"`
-	WindowStart     time.Time `json:"window_start"`
-	IsLimited       bool      `json:"is_limited"`
-	LimitUntil      time.Time `json:"limit_until"`
-}
-
 // Manager handles session management
 type Manager struct {
 	config     *config.Config
@@ -124,7 +114,7 @@ func (m *Manager) CreateSession(userID, channelID string) (*Session, error) {
 	sessionID := uuid.New().String()
 
 	// Create workspace
-	workspaceDir, err := m.executor.CreateWorkspace(userID, sessionID)
+	workspaceDir, err := m.executor.CreateWorkspace(userID, channelID, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create workspace: %w", err)
 	}
@@ -140,9 +130,6 @@ func (m *Manager) CreateSession(userID, channelID string) (*Session, error) {
 		History:      make([]claude.Message, 0),
 		Context:      make(map[string]interface{}),
 		Active:       true,
-		RateLimitInfo: &RateLimitInfo{
-			WindowStart: time.Now(),
-		},
 		MessageQueue: &MessageQueue{
 			Messages: make([]string, 0),
 		},
@@ -209,8 +196,16 @@ func (m *Manager) GetOrCreateSession(userID, channelID string) (*Session, error)
 
 // CreateSessionWithPath creates a new session with a specific working directory
 func (m *Manager) CreateSessionWithPath(userID, channelID, workingDir string) (SessionInfo, error) {
+	sessionID := uuid.New().String()
+
+	// A session in a git repository may get a worktree of its own
+	workingDir, err := m.executor.SessionWorkDir(workingDir, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
 	session := &Session{
-		ID:             uuid.New().String(),
+		ID:             sessionID,
 		UserID:         userID,
 		ChannelID:      channelID,
 		WorkspaceDir:   workingDir,
@@ -325,46 +320,6 @@ func (m *Manager) CloseSession(sessionID string) error {
 	return nil
 }
 
-// CheckRateLimit checks if a user/session is rate limited
-func (m *Manager) CheckRateLimit(sessionID string) (bool, time.Duration, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	session, exists := m.sessions[sessionID]
-	if !exists {
-		return false, 0, fmt.Errorf("session %s not found", sessionID)
-	}
-
-	rateLimitInfo := session.RateLimitInfo
-	now := time.Now()
-
-	// Check if currently rate limited
-	if rateLimitInfo.IsLimited && now.Before(rateLimitInfo.LimitUntil) {
-		remaining := rateLimitInfo.LimitUntil.Sub(now)
-		return true, remaining, nil
-	}
-
-	// Reset rate limit if window has passed
-	if now.Sub(rateLimitInfo.WindowStart) > time.Minute {
-		rateLimitInfo.RequestCount = 0
-		rateLimitInfo.WindowStart = now
-		rateLimitInfo.IsLimited = false
-	}
-
-	// Check if exceeding rate limit
-	if rateLimitInfo.RequestCount >= m.config.RateLimitPerMinute {
-		rateLimitInfo.IsLimited = true
-		rateLimitInfo.LimitUntil = now.Add(time.Minute)
-		return true, time.Minute, nil
-	}
-
-	// Increment request count
-	rateLimitInfo.RequestCount++
-	rateLimitInfo.LastRequestTime = now
-
-	return false, 0, nil
-}
-
 // GetSessionStats returns statistics about sessions
 func (m *Manager) GetSessionStats() map[string]interface{} {
 	m.mu.RLock()
@@ -692,6 +647,9 @@ func (m *Manager) DeleteSession(sessionID string) error {
 	if !exists {
 		return fmt.Errorf("session %s not found", sessionID)
 	}
+	if session.MessageQueue != nil && session.MessageQueue.IsProcessing {
+		return ErrSessionBusy
+	}
 
 	// Remove from sessions map
 	delete(m.sessions, sessionID)
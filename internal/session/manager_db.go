@@ -1,6 +1,8 @@
 package session
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"os/user"
 	"sync"
@@ -20,11 +22,21 @@ type DatabaseManager struct {
 	config     *config.Config
 	logger     *zap.Logger
 	repository *repository.SessionRepository
+	settings   *repository.SettingsRepository
 	executor   *claude.Executor
+	db         *database.Database
+
+	// Cross-instance cache invalidation
+	instanceID    string
+	stopListening func()
 	
 	// Memory optimization: conversation trees loaded on demand
 	conversationTrees map[int][]*repository.ChildSession  // keyed by root_parent_id
 	sessionLookup     map[string]*repository.Session       // keyed by session_id for O(1) lookup
+	latestResponses   map[string]string                    // raw Claude JSON keyed by session_id, for /debug
+	processing        map[string]int                       // in-flight runs keyed by session_id
+	listings          *listingCache                        // session and path listings, briefly
+	identityResolver  func(slackUserID string) string      // system identity a session is attributed to
 	mu               sync.RWMutex
 }
 
@@ -36,9 +48,15 @@ func NewDatabaseManager(cfg *config.Config, logger *zap.Logger, executor *claude
 		config:            cfg,
 		logger:            logger,
 		repository:        repo,
+		settings:          repository.NewSettingsRepository(db, logger),
 		executor:          executor,
+		db:                db,
+		instanceID:        newInstanceID(),
 		conversationTrees: make(map[int][]*repository.ChildSession),
 		sessionLookup:     make(map[string]*repository.Session),
+		latestResponses:   make(map[string]string),
+		processing:        make(map[string]int),
+		listings:          newListingCache(cfg.SessionListingCacheTTL),
 	}
 }
 
@@ -48,17 +66,12 @@ func (m *DatabaseManager) CreateSession(userID, channelID string) (SessionInfo,
 	sessionID := uuid.New().String()
 
 	// Create workspace
-	workspaceDir, err := m.executor.CreateWorkspace(userID, sessionID)
+	workspaceDir, err := m.executor.CreateWorkspace(userID, channelID, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create workspace: %w", err)
 	}
 
-	// Get actual system user (not Slack user ID) with fallback for systemd
-	systemUser, err := user.Current()
-	systemUsername := "claude-bot" // Default fallback for systemd
-	if err == nil {
-		systemUsername = systemUser.Username
-	}
+	systemUsername := m.systemUsername(userID)
 
 	// Create session in database
 	session := &repository.Session{
@@ -66,6 +79,7 @@ func (m *DatabaseManager) CreateSession(userID, channelID string) (SessionInfo,
 		WorkingDirectory: workspaceDir,
 		SystemUser:       systemUsername,
 		UserPrompt:       nil, // Will be set when user sends first message
+		ChannelID:        &channelID,
 	}
 
 	if err := m.repository.CreateSession(session); err != nil {
@@ -79,7 +93,7 @@ func (m *DatabaseManager) CreateSession(userID, channelID string) (SessionInfo,
 	}
 
 	// Update channel state to point to new session
-	if err := m.repository.UpdateChannelState(channelID, &session.ID, nil); err != nil {
+	if err := m.setActiveSession(channelID, userID, &session.ID, nil); err != nil {
 		m.logger.Error("Failed to update channel state", zap.Error(err))
 	}
 
@@ -87,6 +101,7 @@ func (m *DatabaseManager) CreateSession(userID, channelID string) (SessionInfo,
 	m.mu.Lock()
 	m.sessionLookup[sessionID] = session
 	m.mu.Unlock()
+	m.broadcastInvalidation(session)
 
 	m.logger.Info("Created new database session",
 		zap.String("session_id", sessionID),
@@ -98,16 +113,45 @@ func (m *DatabaseManager) CreateSession(userID, channelID string) (SessionInfo,
 	return &DbSessionInfo{session}, nil
 }
 
+// SetIdentityResolver sets how a Slack user maps to the system identity
+// recorded as a session's system_user
+func (m *DatabaseManager) SetIdentityResolver(resolve func(slackUserID string) string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.identityResolver = resolve
+}
+
+// systemUsername returns the identity mapped to the Slack user, or else the
+// account the bot runs as
+func (m *DatabaseManager) systemUsername(userID string) string {
+	m.mu.RLock()
+	resolve := m.identityResolver
+	m.mu.RUnlock()
+	if resolve != nil {
+		if identity := resolve(userID); identity != "" {
+			return identity
+		}
+	}
+
+	// Get actual system user (not Slack user ID) with fallback for systemd
+	if systemUser, err := user.Current(); err == nil {
+		return systemUser.Username
+	}
+	return "claude-bot" // Default fallback for systemd
+}
+
 // CreateSessionWithPath creates a new session with a specific working directory
 func (m *DatabaseManager) CreateSessionWithPath(userID, channelID, workingDir string) (SessionInfo, error) {
 	// Generate session ID
 	sessionID := uuid.New().String()
 
-	// Get actual system user (not Slack user ID) with fallback for systemd
-	systemUser, err := user.Current()
-	systemUsername := "claude-bot" // Default fallback for systemd
-	if err == nil {
-		systemUsername = systemUser.Username
+	systemUsername := m.systemUsername(userID)
+
+	// A session in a git repository may get a worktree of its own
+	requestedDir := workingDir
+	workingDir, err := m.executor.SessionWorkDir(workingDir, sessionID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create session in database with specified working directory
@@ -116,14 +160,23 @@ func (m *DatabaseManager) CreateSessionWithPath(userID, channelID, workingDir st
 		WorkingDirectory: workingDir,
 		SystemUser:       systemUsername,
 		UserPrompt:       nil, // Will be set when user sends first message
+		ChannelID:        &channelID,
 	}
 
 	if err := m.repository.CreateSession(session); err != nil {
+		// Remove the session's worktree; the requested directory itself stays
+		if workingDir != requestedDir {
+			go func() {
+				if cleanupErr := m.executor.CleanupWorkspace(workingDir); cleanupErr != nil {
+					m.logger.Error("Failed to cleanup worktree after session creation failure", zap.Error(cleanupErr))
+				}
+			}()
+		}
 		return nil, fmt.Errorf("failed to create session in database: %w", err)
 	}
 
 	// Update channel state to point to new session
-	if err := m.repository.UpdateChannelState(channelID, &session.ID, nil); err != nil {
+	if err := m.setActiveSession(channelID, userID, &session.ID, nil); err != nil {
 		m.logger.Error("Failed to update channel state", zap.Error(err))
 	}
 
@@ -131,6 +184,7 @@ func (m *DatabaseManager) CreateSessionWithPath(userID, channelID, workingDir st
 	m.mu.Lock()
 	m.sessionLookup[sessionID] = session
 	m.mu.Unlock()
+	m.broadcastInvalidation(session)
 
 	m.logger.Info("Created new database session with custom path",
 		zap.String("session_id", sessionID),
@@ -145,7 +199,7 @@ func (m *DatabaseManager) CreateSessionWithPath(userID, channelID, workingDir st
 // GetOrCreateSession gets existing session for channel or creates new one
 func (m *DatabaseManager) GetOrCreateSession(userID, channelID string) (SessionInfo, error) {
 	// Check channel state for existing active session
-	channelState, err := m.repository.GetChannelState(channelID)
+	channelState, err := m.GetChannelStateForUser(channelID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get channel state: %w", err)
 	}
@@ -205,6 +259,7 @@ func (m *DatabaseManager) ProcessUserMessage(sessionID string, message string) e
 			return fmt.Errorf("failed to update session user prompt: %w", err)
 		}
 		session.UserPrompt = &message
+		m.broadcastInvalidation(session)
 		return nil
 	}
 
@@ -267,6 +322,7 @@ func (m *DatabaseManager) ProcessAIResponse(sessionID string, aiResponse string)
 		m.conversationTrees[session.ID] = append(tree, childSession)
 	}
 	m.mu.Unlock()
+	m.broadcastInvalidation(session)
 
 	m.logger.Debug("Created child session for AI response",
 		zap.String("session_id", sessionID),
@@ -363,7 +419,48 @@ func (m *DatabaseManager) GetSessionStats() map[string]interface{} {
 
 // ListAllSessions returns all sessions with pagination (SessionManager interface)
 func (m *DatabaseManager) ListAllSessions(limit int) ([]SessionInfo, error) {
-	sessions, err := m.repository.ListAllSessions(limit)
+	return m.listings.cachedSessions(allSessionsKey(limit), func() ([]SessionInfo, error) {
+		sessions, err := m.repository.ListAllSessions(limit)
+		if err != nil {
+			return nil, err
+		}
+
+		var sessionInfos []SessionInfo
+		for _, session := range sessions {
+			sessionInfos = append(sessionInfos, &DbSessionInfo{session})
+		}
+
+		return sessionInfos, nil
+	})
+}
+
+// RecordChannelType stores whether a channel is public, private, or a DM
+func (m *DatabaseManager) RecordChannelType(channelID, channelType string) error {
+	return m.repository.RecordChannelType(channelID, channelType)
+}
+
+// ListSessionsForChannel returns the sessions that may be listed in a
+// channel, optionally only those from channels of one type
+func (m *DatabaseManager) ListSessionsForChannel(channelID, channelType string, limit int) ([]SessionInfo, error) {
+	return m.listings.cachedSessions(channelSessionsKey(channelID, channelType, limit), func() ([]SessionInfo, error) {
+		sessions, err := m.repository.ListSessionsForChannel(channelID, channelType, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		var sessionInfos []SessionInfo
+		for _, session := range sessions {
+			sessionInfos = append(sessionInfos, &DbSessionInfo{session})
+		}
+
+		return sessionInfos, nil
+	})
+}
+
+// FindSessionsByIDPrefix returns the sessions listable in a channel whose ID
+// starts with prefix, most recent first
+func (m *DatabaseManager) FindSessionsByIDPrefix(channelID, prefix string, limit int) ([]SessionInfo, error) {
+	sessions, err := m.repository.FindSessionsByIDPrefix(channelID, prefix, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -376,9 +473,44 @@ func (m *DatabaseManager) ListAllSessions(limit int) ([]SessionInfo, error) {
 	return sessionInfos, nil
 }
 
+// SessionVisibleInChannel reports whether a session may be shown in a
+// channel: its own channel's, or a public one's
+func (m *DatabaseManager) SessionVisibleInChannel(sessionID, channelID string) (bool, error) {
+	return m.repository.SessionVisibleInChannel(sessionID, channelID)
+}
+
+// ActiveSessionID returns the channel's active session, or the user's in
+// per-user mode, without creating one; "" if there is none
+func (m *DatabaseManager) ActiveSessionID(channelID, userID string) (string, error) {
+	channelState, err := m.GetChannelStateForUser(channelID, userID)
+	if err != nil || channelState == nil || channelState.ActiveSessionID == nil {
+		return "", err
+	}
+	session, err := m.loadSessionByID(*channelState.ActiveSessionID)
+	if err != nil {
+		return "", err
+	}
+	return session.SessionID, nil
+}
+
+// CloseActiveSession stops the channel, or the user in per-user mode,
+// pointing at its active session, so the next message starts a new one
+func (m *DatabaseManager) CloseActiveSession(channelID, userID string) error {
+	sessionID, err := m.ActiveSessionID(channelID, userID)
+	if err != nil || sessionID == "" {
+		return err
+	}
+	if err := m.setActiveSession(channelID, userID, nil, nil); err != nil {
+		return fmt.Errorf("failed to clear active session: %w", err)
+	}
+	return m.CloseSession(sessionID)
+}
+
 // GetKnownPaths returns unique working directories from all sessions (SessionManager interface)
 func (m *DatabaseManager) GetKnownPaths(limit int) ([]string, error) {
-	return m.repository.GetUniqueWorkingDirectories(limit)
+	return m.listings.cachedPaths(knownPathsKey(limit), func() ([]string, error) {
+		return m.repository.GetUniqueWorkingDirectories(limit)
+	})
 }
 
 // GetSessionsByPath returns sessions for a specific path (database implementation)
@@ -398,6 +530,18 @@ func (m *DatabaseManager) GetSessionsByPath(path string, limit int) ([]SessionIn
 
 // SwitchToSessionInChannel switches the active session for a channel
 func (m *DatabaseManager) SwitchToSessionInChannel(channelID, sessionID string) error {
+	return m.switchToSession(channelID, "", sessionID)
+}
+
+// SwitchToSessionForUser switches the user's active session, which is the
+// channel's session unless the channel is in per-user mode
+func (m *DatabaseManager) SwitchToSessionForUser(channelID, userID, sessionID string) error {
+	return m.switchToSession(channelID, userID, sessionID)
+}
+
+// switchToSession points the channel, or the user's slot in a per-user mode
+// channel, at a session and its latest exchange
+func (m *DatabaseManager) switchToSession(channelID, userID, sessionID string) error {
 	// Get the target session to validate it exists
 	session, err := m.getSessionBySessionID(sessionID)
 	if err != nil {
@@ -407,6 +551,22 @@ func (m *DatabaseManager) SwitchToSessionInChannel(channelID, sessionID string)
 		return fmt.Errorf("session %s not found", sessionID)
 	}
 
+	// Don't move the channel while either session has a run in flight: the
+	// run would record its reply against a session the channel has left
+	if m.IsProcessing(session.SessionID) {
+		return ErrSessionBusy
+	}
+	channelState, err := m.GetChannelStateForUser(channelID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get channel state: %w", err)
+	}
+	if channelState != nil && channelState.ActiveSessionID != nil && *channelState.ActiveSessionID != session.ID {
+		current, err := m.loadSessionByID(*channelState.ActiveSessionID)
+		if err == nil && m.IsProcessing(current.SessionID) {
+			return ErrSessionBusy
+		}
+	}
+
 	// Get the latest child session (leaf) for this session
 	leafChild, err := m.repository.FindLeafChild(session.ID)
 	if err != nil {
@@ -419,14 +579,13 @@ func (m *DatabaseManager) SwitchToSessionInChannel(channelID, sessionID string)
 		activeChildSessionID = &leafChild.ID
 	}
 
-	err = m.repository.UpdateChannelState(channelID, &session.ID, activeChildSessionID)
+	err = m.setActiveSession(channelID, userID, &session.ID, activeChildSessionID)
 	if err != nil {
 		return fmt.Errorf("failed to update channel state: %w", err)
 	}
 
 	// Update memory cache
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	// Update session lookup cache
 	m.sessionLookup[session.SessionID] = session
@@ -435,10 +594,82 @@ func (m *DatabaseManager) SwitchToSessionInChannel(channelID, sessionID string)
 	if leafChild != nil {
 		m.conversationTrees[session.ID] = []*repository.ChildSession{leafChild}
 	}
+	m.mu.Unlock()
 
+	m.broadcastInvalidation(session)
 	return nil
 }
 
+// MoveActiveSession hands the user's active session in one channel, with its
+// latest exchange, over to another channel; each channel keeps its own
+// permission mode and settings. The source channel starts a new session with
+// its next message. It returns ErrSessionNotFound if the source has no
+// active session, and ErrChannelArchived if the target is archived.
+func (m *DatabaseManager) MoveActiveSession(fromChannelID, toChannelID, userID string) (SessionInfo, error) {
+	source, err := m.GetChannelStateForUser(fromChannelID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel state: %w", err)
+	}
+	if source == nil || source.ActiveSessionID == nil {
+		return nil, ErrSessionNotFound
+	}
+	session, err := m.loadSessionByID(*source.ActiveSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load active session: %w", err)
+	}
+
+	// Neither channel may be mid-run: the reply would land in the wrong place
+	if m.IsProcessing(session.SessionID) {
+		return nil, ErrSessionBusy
+	}
+	target, err := m.GetChannelStateForUser(toChannelID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel state: %w", err)
+	}
+	if target != nil && target.ActiveSessionID != nil {
+		current, err := m.loadSessionByID(*target.ActiveSessionID)
+		if err == nil && m.IsProcessing(current.SessionID) {
+			return nil, ErrSessionBusy
+		}
+	}
+
+	if err := m.repository.MoveSessionToChannel(session.ID, toChannelID); err != nil {
+		return nil, err
+	}
+	if err := m.setActiveSession(toChannelID, userID, &session.ID, source.ActiveChildSessionID); err != nil {
+		return nil, fmt.Errorf("failed to update target channel state: %w", err)
+	}
+	if err := m.setActiveSession(fromChannelID, userID, nil, nil); err != nil {
+		return nil, fmt.Errorf("failed to update source channel state: %w", err)
+	}
+
+	// The cached copy still names the old channel
+	m.evictSession(session.SessionID, session.ID)
+	m.broadcastInvalidation(session)
+
+	m.logger.Info("Moved session to another channel",
+		zap.String("session_id", session.SessionID),
+		zap.String("from_channel_id", fromChannelID),
+		zap.String("to_channel_id", toChannelID),
+		zap.String("user_id", userID))
+	return &DbSessionInfo{session}, nil
+}
+
+// SetChildSessionSummary stores a summary of the conversation up to a child session
+func (m *DatabaseManager) SetChildSessionSummary(childID int, summary string) error {
+	return m.repository.UpdateChildSummary(childID, summary)
+}
+
+// SetSessionSummary stores a summary of a root session's whole conversation
+func (m *DatabaseManager) SetSessionSummary(sessionID, summary string) error {
+	return m.repository.UpdateSessionSummary(sessionID, summary)
+}
+
+// GetChildSessionBySessionID retrieves a child session by its Claude session ID
+func (m *DatabaseManager) GetChildSessionBySessionID(sessionID string) (*repository.ChildSession, error) {
+	return m.repository.GetChildSessionBySessionID(sessionID)
+}
+
 // GetChildSessionByID retrieves a child session by database ID
 func (m *DatabaseManager) GetChildSessionByID(id int) (*repository.ChildSession, error) {
 	return m.repository.GetChildSessionByID(id)
@@ -449,6 +680,75 @@ func (m *DatabaseManager) GetChannelState(channelID string) (*repository.SlackCh
 	return m.repository.GetChannelState(channelID)
 }
 
+// GetChannelStateForUser retrieves the channel state with the active session
+// pointers replaced by the user's own when the channel is in per-user mode
+func (m *DatabaseManager) GetChannelStateForUser(channelID, userID string) (*repository.SlackChannel, error) {
+	channelState, err := m.repository.GetChannelState(channelID)
+	if err != nil || channelState == nil || SessionMode(channelState.SessionMode) != SessionModePerUser {
+		return channelState, err
+	}
+
+	userState, err := m.repository.GetUserChannelState(channelID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return stateForUser(channelState, userState), nil
+}
+
+// stateForUser returns the channel state a user sees. In per-user mode the
+// user's own active session replaces the channel's, and a user without one
+// gets none so their next message starts a fresh session; in shared mode
+// everyone sees the channel's session.
+func stateForUser(channelState *repository.SlackChannel, userState *repository.SlackChannelUserSession) *repository.SlackChannel {
+	if channelState == nil || SessionMode(channelState.SessionMode) != SessionModePerUser {
+		return channelState
+	}
+
+	state := *channelState
+	state.ActiveSessionID = nil
+	state.ActiveChildSessionID = nil
+	if userState != nil {
+		state.ActiveSessionID = userState.ActiveSessionID
+		state.ActiveChildSessionID = userState.ActiveChildSessionID
+	}
+	return &state
+}
+
+// setActiveSession updates the channel's active session, or the user's when
+// the channel is in per-user mode. An empty userID always targets the channel.
+func (m *DatabaseManager) setActiveSession(channelID, userID string, activeSessionID, activeChildSessionID *int) error {
+	if userID != "" {
+		mode, err := m.GetChannelSessionMode(channelID)
+		if err != nil {
+			return err
+		}
+		if mode == SessionModePerUser {
+			return m.repository.UpdateUserChannelState(channelID, userID, activeSessionID, activeChildSessionID)
+		}
+	}
+	return m.repository.UpdateChannelState(channelID, activeSessionID, activeChildSessionID)
+}
+
+// SetChannelSessionMode sets whether users in a channel share one session
+func (m *DatabaseManager) SetChannelSessionMode(channelID string, mode SessionMode) error {
+	if mode != SessionModeShared && mode != SessionModePerUser {
+		return fmt.Errorf("invalid session mode: %s", mode)
+	}
+	return m.repository.UpdateChannelSessionMode(channelID, string(mode))
+}
+
+// GetChannelSessionMode returns the channel's session mode, defaulting to shared
+func (m *DatabaseManager) GetChannelSessionMode(channelID string) (SessionMode, error) {
+	channel, err := m.repository.GetChannelState(channelID)
+	if err != nil {
+		return SessionModeShared, err
+	}
+	if channel == nil || channel.SessionMode == "" {
+		return SessionModeShared, nil
+	}
+	return SessionMode(channel.SessionMode), nil
+}
+
 // LoadSessionByID loads session by database ID (public version)
 func (m *DatabaseManager) LoadSessionByID(id int) (*repository.Session, error) {
 	return m.loadSessionByID(id)
@@ -465,7 +765,12 @@ var _ SessionInfo = (*DbSessionInfo)(nil)
 // SessionInfo implementation for database Session
 func (s *DbSessionInfo) GetID() string                         { return s.SessionID }
 func (s *DbSessionInfo) GetUserID() string                     { return s.SystemUser }
-func (s *DbSessionInfo) GetChannelID() string                  { return "" } // Not stored in DB session
+func (s *DbSessionInfo) GetChannelID() string {
+	if s.ChannelID == nil {
+		return "" // Sessions created before channels were recorded
+	}
+	return *s.ChannelID
+}
 func (s *DbSessionInfo) GetWorkspaceDir() string               { return s.WorkingDirectory }
 func (s *DbSessionInfo) GetCurrentWorkDir() string             { return s.WorkingDirectory }
 func (s *DbSessionInfo) GetPermissionMode() config.PermissionMode { 
@@ -528,12 +833,6 @@ func (m *DatabaseManager) UpdateSessionActivity(sessionID string) error {
 	return nil
 }
 
-// CheckRateLimit checks if a session is rate limited (database implementation)
-func (m *DatabaseManager) CheckRateLimit(sessionID string) (bool, time.Duration, error) {
-	// Database sessions don't implement rate limiting yet
-	return false, 0, nil
-}
-
 // SetPermissionMode sets the permission mode for a database session (now channel-based)
 func (m *DatabaseManager) SetPermissionMode(sessionID string, mode config.PermissionMode) error {
 	// Find which channel this session belongs to
@@ -580,6 +879,91 @@ func (m *DatabaseManager) GetPermissionModeForChannel(channelID string) (config.
 	return config.PermissionMode(permission), nil
 }
 
+// SetPermissionModeForChannelWithExpiry sets a channel permission mode that
+// reverts to default after ttl
+func (m *DatabaseManager) SetPermissionModeForChannelWithExpiry(channelID string, mode config.PermissionMode, ttl time.Duration) (time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	if err := m.repository.UpdateChannelPermissionWithExpiry(channelID, string(mode), expiresAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to update channel permission: %w", err)
+	}
+
+	m.logger.Debug("Channel permission mode updated with expiry",
+		zap.String("channel_id", channelID),
+		zap.String("mode", string(mode)),
+		zap.Time("expires_at", expiresAt))
+	return expiresAt, nil
+}
+
+// GetPermissionExpiryForChannel returns when the channel permission reverts, or nil if never
+func (m *DatabaseManager) GetPermissionExpiryForChannel(channelID string) (*time.Time, error) {
+	channel, err := m.repository.GetChannelState(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, nil
+	}
+	return channel.PermissionExpiresAt, nil
+}
+
+// SetDefaultPermissionMode sets the permission mode new channels start with,
+// unless CHANNEL_PERMISSION_DEFAULTS names them. Existing channels keep theirs.
+func (m *DatabaseManager) SetDefaultPermissionMode(mode config.PermissionMode, userID string) error {
+	return m.settings.SetSetting(repository.SettingDefaultPermission, string(mode), userID)
+}
+
+// GetDefaultPermissionMode returns the workspace-wide default permission mode
+func (m *DatabaseManager) GetDefaultPermissionMode() (config.PermissionMode, error) {
+	mode, err := m.settings.GetSetting(repository.SettingDefaultPermission)
+	if err != nil || mode == "" {
+		return config.PermissionModeDefault, err
+	}
+	return config.PermissionMode(mode), nil
+}
+
+// ClaimDeploymentNotice records that a deployment notification is being sent
+// for version. It returns the version last notified and whether this caller
+// won the claim; it doesn't when version was already notified or another
+// instance claimed it first.
+func (m *DatabaseManager) ClaimDeploymentNotice(version string) (string, bool, error) {
+	previous, err := m.settings.GetSetting(repository.SettingDeployNotifiedVersion)
+	if err != nil || previous == version {
+		return previous, false, err
+	}
+	claimed, err := m.settings.SwapSetting(repository.SettingDeployNotifiedVersion, previous, version, "startup")
+	return previous, claimed, err
+}
+
+// ReleaseDeploymentNotice gives up a claim on version whose notification
+// could not be sent, restoring previous so the next start announces it
+func (m *DatabaseManager) ReleaseDeploymentNotice(version, previous string) error {
+	_, err := m.settings.SwapSetting(repository.SettingDeployNotifiedVersion, version, previous, "startup")
+	return err
+}
+
+// RevertExpiredPermissions resets expired channel permissions and returns the affected channels
+func (m *DatabaseManager) RevertExpiredPermissions() ([]string, error) {
+	return m.repository.RevertExpiredChannelPermissions()
+}
+
+// SetChannelContextEnabled toggles channel topic/purpose prompt context for a channel
+func (m *DatabaseManager) SetChannelContextEnabled(channelID string, enabled bool) error {
+	return m.repository.UpdateChannelContextEnabled(channelID, enabled)
+}
+
+// GetChannelContextEnabled reports whether channel context is enabled, or nil
+// if the channel follows the configured default
+func (m *DatabaseManager) GetChannelContextEnabled(channelID string) (*bool, error) {
+	channel, err := m.repository.GetChannelState(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, nil
+	}
+	return channel.ChannelContextEnabled, nil
+}
+
 // findChannelForSession finds which channel a session belongs to
 func (m *DatabaseManager) findChannelForSession(sessionID string) (string, error) {
 	// Get session to find its DB ID
@@ -592,13 +976,26 @@ func (m *DatabaseManager) findChannelForSession(sessionID string) (string, error
 	return m.repository.FindChannelForSession(session.ID)
 }
 
-// UpdateLatestResponse updates the latest response for a database session
+// UpdateLatestResponse keeps the latest raw Claude JSON in memory for debugging
 func (m *DatabaseManager) UpdateLatestResponse(sessionID string, response string) error {
-	// This would be handled by ProcessAIResponse in database sessions
-	m.logger.Debug("UpdateLatestResponse called", zap.String("session_id", sessionID))
+	m.mu.Lock()
+	m.latestResponses[sessionID] = response
+	m.mu.Unlock()
 	return nil
 }
 
+// GetLatestResponse returns the latest raw Claude JSON seen for a session
+func (m *DatabaseManager) GetLatestResponse(sessionID string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	response, exists := m.latestResponses[sessionID]
+	if !exists || response == "" {
+		return "", fmt.Errorf("no response available")
+	}
+	return response, nil
+}
+
 // UpdateCurrentWorkDir updates the current working directory for a database session
 func (m *DatabaseManager) UpdateCurrentWorkDir(sessionID string, workDir string) error {
 	// Database sessions use the workspace directory from creation
@@ -614,9 +1011,19 @@ func (m *DatabaseManager) QueueMessage(sessionID string, message string) (bool,
 	return false, nil
 }
 
-// SetProcessing sets the processing status for a database session
+// SetProcessing marks the start or end of a run on a database session. Runs
+// are counted, so overlapping runs keep the session busy until all finish.
 func (m *DatabaseManager) SetProcessing(sessionID string, processing bool) error {
-	// Database sessions don't track processing status in memory
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if processing {
+		m.processing[sessionID]++
+	} else if m.processing[sessionID] <= 1 {
+		delete(m.processing, sessionID)
+	} else {
+		m.processing[sessionID]--
+	}
 	return nil
 }
 
@@ -626,10 +1033,11 @@ func (m *DatabaseManager) GetQueuedMessages(sessionID string) ([]string, error)
 	return nil, nil
 }
 
-// IsProcessing checks if a database session is processing
+// IsProcessing checks if a database session has a run in progress
 func (m *DatabaseManager) IsProcessing(sessionID string) bool {
-	// Database sessions don't track processing status
-	return false
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.processing[sessionID] > 0
 }
 
 // GetActiveSessionsForUser gets active sessions for a user (database implementation)
@@ -644,15 +1052,63 @@ func (m *DatabaseManager) ListUserSessions(userID string) string {
 	return "Database session listing not yet implemented."
 }
 
-// DeleteSession deletes a session from the database
+// DeleteSession moves a session to the trash
 func (m *DatabaseManager) DeleteSession(sessionID string) error {
-	// Remove from memory cache
+	return m.TrashSession(sessionID, "")
+}
+
+// TrashSession moves a session to the trash, detaching it from every channel.
+// It stays restorable until PurgeTrashedSessions removes it.
+func (m *DatabaseManager) TrashSession(sessionID, userID string) error {
+	// Remove from memory cache, unless a run still needs the session
 	m.mu.Lock()
+	if m.processing[sessionID] > 0 {
+		m.mu.Unlock()
+		return ErrSessionBusy
+	}
+	evicted := &repository.Session{SessionID: sessionID}
+	if session, ok := m.sessionLookup[sessionID]; ok {
+		evicted = session
+		delete(m.conversationTrees, session.ID)
+	}
 	delete(m.sessionLookup, sessionID)
 	m.mu.Unlock()
 
-	// Delete from database
-	return m.repository.DeleteSession(sessionID)
+	err := m.repository.TrashSession(sessionID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSessionNotFound
+	}
+	if err == nil {
+		m.broadcastInvalidation(evicted)
+	}
+	return err
+}
+
+// RestoreSession takes a session out of the trash
+func (m *DatabaseManager) RestoreSession(sessionID string) error {
+	err := m.repository.RestoreSession(sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSessionNotFound
+	}
+	if err == nil {
+		m.broadcastInvalidation(&repository.Session{SessionID: sessionID})
+	}
+	return err
+}
+
+// ListTrashedSessions returns sessions in the trash, most recently deleted first
+func (m *DatabaseManager) ListTrashedSessions(limit int) ([]*repository.TrashedSession, error) {
+	return m.repository.ListTrashedSessions(limit)
+}
+
+// PurgeTrashedSessions permanently deletes sessions trashed before cutoff
+func (m *DatabaseManager) PurgeTrashedSessions(cutoff time.Time) ([]string, error) {
+	purged, err := m.repository.PurgeTrashedSessions(cutoff)
+	for _, sessionID := range purged {
+		m.evictSession(sessionID, 0)
+		m.broadcastInvalidation(&repository.Session{SessionID: sessionID})
+	}
+	return purged, err
 }
 
 // ProcessClaudeAIResponse creates new child session with Claude's returned session ID
@@ -695,6 +1151,7 @@ func (m *DatabaseManager) ProcessClaudeAIResponse(sessionID string, claudeSessio
 		m.conversationTrees[session.ID] = append(tree, childSession)
 	}
 	m.mu.Unlock()
+	m.broadcastInvalidation(session)
 
 	m.logger.Debug("Created child session with Claude session ID",
 		zap.String("root_session_id", sessionID),
@@ -745,7 +1202,15 @@ func (m *DatabaseManager) GetConversationTree(sessionID string) ([]*repository.C
 	return m.repository.GetConversationTree(session.ID)
 }
 
-// Stop cleanup resources (no background routines in database mode)
+// Stop cleanup resources, including the invalidation listener if started
 func (m *DatabaseManager) Stop() {
+	m.mu.Lock()
+	stop := m.stopListening
+	m.stopListening = nil
+	m.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+
 	m.logger.Info("Database session manager stopped")
 }
\ No newline at end of file
@@ -0,0 +1,71 @@
+package session
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/ghabxph/claude-on-slack/internal/config"
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+func TestDatabaseManager_DeleteWhileProcessing(t *testing.T) {
+	m := NewDatabaseManager(&config.Config{}, zap.NewNop(), nil, nil)
+
+	m.SetProcessing("s1", true)
+	m.SetProcessing("s1", true)
+	if err := m.DeleteSession("s1"); !errors.Is(err, ErrSessionBusy) {
+		t.Fatalf("Expected ErrSessionBusy, got %v", err)
+	}
+
+	// Still busy until every overlapping run has finished
+	m.SetProcessing("s1", false)
+	if !m.IsProcessing("s1") {
+		t.Error("Expected session to stay busy while a run is in flight")
+	}
+	m.SetProcessing("s1", false)
+	if m.IsProcessing("s1") {
+		t.Error("Expected session to be idle after all runs finished")
+	}
+}
+
+func TestStateForUser(t *testing.T) {
+	channelSession, channelChild := 1, 10
+	userSession, userChild := 2, 20
+	channel := &repository.SlackChannel{ChannelID: "C1", ActiveSessionID: &channelSession, ActiveChildSessionID: &channelChild}
+	user := &repository.SlackChannelUserSession{ChannelID: "C1", UserID: "U1", ActiveSessionID: &userSession, ActiveChildSessionID: &userChild}
+
+	// Shared, or unset: everyone continues the channel's session
+	for _, mode := range []string{"", string(SessionModeShared)} {
+		channel.SessionMode = mode
+		if got := stateForUser(channel, user); *got.ActiveSessionID != channelSession {
+			t.Errorf("Mode %q: expected the channel's session, got %d", mode, *got.ActiveSessionID)
+		}
+	}
+
+	// Per-user: the user's own session takes precedence
+	channel.SessionMode = string(SessionModePerUser)
+	got := stateForUser(channel, user)
+	if *got.ActiveSessionID != userSession || *got.ActiveChildSessionID != userChild {
+		t.Errorf("Expected the user's session, got %d/%d", *got.ActiveSessionID, *got.ActiveChildSessionID)
+	}
+	if *channel.ActiveSessionID != channelSession {
+		t.Error("Expected the channel's own state to be left alone")
+	}
+
+	// A user without one starts fresh rather than joining the channel's
+	if got := stateForUser(channel, nil); got.ActiveSessionID != nil || got.ActiveChildSessionID != nil {
+		t.Errorf("Expected no active session for a new user, got %+v", got)
+	}
+
+	// Back to shared: the user's session no longer applies
+	channel.SessionMode = string(SessionModeShared)
+	if got := stateForUser(channel, user); *got.ActiveSessionID != channelSession {
+		t.Errorf("Expected the channel's session after leaving per-user mode, got %d", *got.ActiveSessionID)
+	}
+
+	if stateForUser(nil, user) != nil {
+		t.Error("Expected an unknown channel to stay unknown")
+	}
+}
@@ -0,0 +1,31 @@
+package session
+
+import (
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/repository"
+)
+
+// FindPruneRuns returns runs of old exchanges that can be collapsed into
+// checkpoints
+func (m *DatabaseManager) FindPruneRuns(cutoff time.Time, keep, chunk, limit int) ([]*repository.PruneRun, error) {
+	return m.repository.FindPruneRuns(cutoff, keep, chunk, limit)
+}
+
+// CollapsePruneRun replaces a run of exchanges with a summarized checkpoint.
+// Sessions with a run in progress are left for a later sweep.
+func (m *DatabaseManager) CollapsePruneRun(run *repository.PruneRun, checkpointText, summary string) error {
+	m.mu.RLock()
+	busy := m.processing[run.SessionID] > 0
+	m.mu.RUnlock()
+	if busy {
+		return ErrSessionBusy
+	}
+
+	if err := m.repository.CollapsePruneRun(run, checkpointText, summary); err != nil {
+		return err
+	}
+	m.evictSession(run.SessionID, run.RootParentID)
+	m.broadcastInvalidation(&repository.Session{ID: run.RootParentID, SessionID: run.SessionID})
+	return nil
+}
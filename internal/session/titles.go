@@ -0,0 +1,13 @@
+package session
+
+// SetSessionTitle stores a root session's generated title unless it already
+// has one, reporting whether it was stored
+func (m *DatabaseManager) SetSessionTitle(sessionID, title string) (bool, error) {
+	return m.repository.UpdateSessionTitle(sessionID, title)
+}
+
+// GetSessionTitles returns the titles of the given root sessions, keyed by
+// session ID
+func (m *DatabaseManager) GetSessionTitles(sessionIDs []string) (map[string]string, error) {
+	return m.repository.GetSessionTitles(sessionIDs)
+}
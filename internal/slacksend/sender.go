@@ -0,0 +1,251 @@
+package slacksend
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// minRetryAfter is the shortest wait before retrying a rate-limited call
+const minRetryAfter = time.Second
+
+// laneSweepInterval is how often lanes of channels that went quiet are
+// dropped, so a long-running sender doesn't keep one per channel ever used
+const laneSweepInterval = time.Minute
+
+// Client is the part of the Slack Web API the sender writes through
+type Client interface {
+	PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
+	UpdateMessageContext(ctx context.Context, channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error)
+	DeleteMessageContext(ctx context.Context, channel, messageTimestamp string) (string, string, error)
+	PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error)
+}
+
+// Sender delivers messages to Slack one call at a time per channel, spaced by
+// a pacing interval, and retries calls Slack rejects with HTTP 429 after the
+// Retry-After it sends back. Calls for one channel wait their turn, so bursts
+// such as split long replies are delivered in order instead of dropped.
+// Edits and deletes are also retried after server errors; posts are not, as
+// Slack may have posted the message before failing.
+type Sender struct {
+	client     func() Client // looked up per call, as the client is swapped on credential rotation
+	pacing     time.Duration
+	maxRetries int
+	logger     *zap.Logger
+	sleep      func(ctx context.Context, d time.Duration) error
+
+	mu        sync.Mutex
+	lanes     map[string]*lane
+	lastSweep time.Time
+}
+
+// lane serializes the calls for one channel
+type lane struct {
+	mu   sync.Mutex
+	next time.Time // earliest time the next call may be made
+
+	// Guarded by Sender.mu
+	users     int       // calls holding or waiting for the lane
+	idleAfter time.Time // when the lane stops pacing anything, once unused
+}
+
+// New creates a sender. pacing is the minimum time between calls to one
+// channel (Slack allows about one message per second per channel), and
+// maxRetries bounds the retries of a rate-limited or failed call.
+func New(client func() Client, pacing time.Duration, maxRetries int, logger *zap.Logger) *Sender {
+	return &Sender{
+		client:     client,
+		pacing:     pacing,
+		maxRetries: maxRetries,
+		logger:     logger,
+		sleep:      sleepContext,
+		lanes:      make(map[string]*lane),
+	}
+}
+
+// PostMessage posts a message and returns its timestamp
+func (s *Sender) PostMessage(ctx context.Context, channelID string, options ...slack.MsgOption) (string, error) {
+	var timestamp string
+	err := s.do(ctx, channelID, "chat.postMessage", false, func(ctx context.Context) error {
+		var err error
+		_, timestamp, err = s.client().PostMessageContext(ctx, channelID, options...)
+		return err
+	})
+	return timestamp, err
+}
+
+// UpdateMessage edits a message
+func (s *Sender) UpdateMessage(ctx context.Context, channelID, timestamp string, options ...slack.MsgOption) error {
+	return s.do(ctx, channelID, "chat.update", true, func(ctx context.Context) error {
+		_, _, _, err := s.client().UpdateMessageContext(ctx, channelID, timestamp, options...)
+		return err
+	})
+}
+
+// DeleteMessage deletes a message
+func (s *Sender) DeleteMessage(ctx context.Context, channelID, timestamp string) error {
+	return s.do(ctx, channelID, "chat.delete", true, func(ctx context.Context) error {
+		_, _, err := s.client().DeleteMessageContext(ctx, channelID, timestamp)
+		return err
+	})
+}
+
+// PostEphemeral posts a message only userID can see
+func (s *Sender) PostEphemeral(ctx context.Context, channelID, userID string, options ...slack.MsgOption) error {
+	return s.do(ctx, channelID, "chat.postEphemeral", false, func(ctx context.Context) error {
+		_, err := s.client().PostEphemeralContext(ctx, channelID, userID, options...)
+		return err
+	})
+}
+
+// do runs call in channelID's lane, pacing it after the previous call and
+// retrying it while Slack reports a retryable error. Calls that aren't
+// repeatable are only retried when Slack refused them outright with a 429.
+func (s *Sender) do(ctx context.Context, channelID, method string, repeatable bool, call func(ctx context.Context) error) error {
+	l := s.acquire(channelID)
+	defer s.release(channelID, l)
+
+	for attempt := 0; ; attempt++ {
+		if wait := time.Until(l.next); wait > 0 {
+			if err := s.sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
+
+		err := call(ctx)
+		l.next = time.Now().Add(s.pacing)
+		if err == nil {
+			return nil
+		}
+
+		retryAfter, retryable := retryDelay(err, attempt)
+		if !retryable || attempt >= s.maxRetries || !repeatable && !rateLimited(err) {
+			return err
+		}
+
+		s.logger.Warn("Slack call failed, retrying",
+			zap.String("method", method),
+			zap.String("channel_id", channelID),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("retry_after", retryAfter),
+			zap.Error(err))
+		if next := time.Now().Add(retryAfter); next.After(l.next) {
+			l.next = next
+		}
+	}
+}
+
+// acquire waits for a channel's lane, creating it on first use
+func (s *Sender) acquire(channelID string) *lane {
+	s.mu.Lock()
+	s.sweepLanes(time.Now())
+	l, ok := s.lanes[channelID]
+	if !ok {
+		l = &lane{}
+		s.lanes[channelID] = l
+	}
+	l.users++
+	s.mu.Unlock()
+
+	l.mu.Lock()
+	return l
+}
+
+// release hands a lane to the next call, dropping it if no call wants it and
+// it has no pacing left to enforce
+func (s *Sender) release(channelID string, l *lane) {
+	idleAfter := l.next
+	l.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l.users--
+	l.idleAfter = idleAfter
+	if l.users == 0 && !time.Now().Before(idleAfter) {
+		delete(s.lanes, channelID)
+	}
+}
+
+// sweepLanes drops the unused lanes whose pacing has run out, at most once
+// per laneSweepInterval. The caller holds s.mu.
+func (s *Sender) sweepLanes(now time.Time) {
+	if now.Sub(s.lastSweep) < laneSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for channelID, l := range s.lanes {
+		if l.users == 0 && !now.Before(l.idleAfter) {
+			delete(s.lanes, channelID)
+		}
+	}
+}
+
+// rateLimited reports whether Slack refused a call with HTTP 429, which
+// means it wasn't carried out and is safe to repeat
+func rateLimited(err error) bool {
+	var rateLimitedErr *slack.RateLimitedError
+	return errors.As(err, &rateLimitedErr)
+}
+
+// retryDelay reports whether err is worth retrying and how long to wait:
+// Slack's Retry-After for rate limits, or a growing backoff for server errors
+func retryDelay(err error, attempt int) (time.Duration, bool) {
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		if rateLimited.RetryAfter < minRetryAfter {
+			return minRetryAfter, true
+		}
+		return rateLimited.RetryAfter, true
+	}
+
+	var statusErr slack.StatusCodeError
+	if errors.As(err, &statusErr) && statusErr.Retryable() {
+		return time.Duration(attempt+1) * time.Second, true
+	}
+	return 0, false
+}
+
+// transientSlackErrors are API errors Slack returns while it is having
+// trouble rather than because the call was wrong
+var transientSlackErrors = map[string]bool{
+	"fatal_error":         true,
+	"internal_error":      true,
+	"ratelimited":         true,
+	"request_timeout":     true,
+	"service_unavailable": true,
+}
+
+// Transient reports whether a call that failed with err may succeed later
+// unchanged: Slack was unreachable, overloaded, or rate limiting. Errors about
+// the call itself, such as channel_not_found, are not transient.
+func Transient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if _, retryable := retryDelay(err, 0); retryable {
+		return true
+	}
+	var slackErr slack.SlackErrorResponse
+	if errors.As(err, &slackErr) {
+		return transientSlackErrors[slackErr.Err]
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,211 @@
+package slacksend
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// fakeClient fails the first failures calls with err, then succeeds
+type fakeClient struct {
+	mu       sync.Mutex
+	failures int
+	err      error
+	calls    int
+}
+
+func (c *fakeClient) next() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if c.calls <= c.failures {
+		return c.err
+	}
+	return nil
+}
+
+func (c *fakeClient) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	return channelID, "1700000000.000100", c.next()
+}
+
+func (c *fakeClient) UpdateMessageContext(ctx context.Context, channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	return channelID, timestamp, "", c.next()
+}
+
+func (c *fakeClient) DeleteMessageContext(ctx context.Context, channel, messageTimestamp string) (string, string, error) {
+	return channel, messageTimestamp, c.next()
+}
+
+func (c *fakeClient) PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error) {
+	return "", c.next()
+}
+
+func newTestSender(client *fakeClient, maxRetries int) (*Sender, *[]time.Duration) {
+	var waits []time.Duration
+	sender := New(func() Client { return client }, 0, maxRetries, zap.NewNop())
+	sender.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return sender, &waits
+}
+
+func TestSender_RetriesRateLimited(t *testing.T) {
+	client := &fakeClient{failures: 2, err: &slack.RateLimitedError{RetryAfter: 3 * time.Second}}
+	sender, waits := newTestSender(client, 3)
+
+	ts, err := sender.PostMessage(context.Background(), "C1", slack.MsgOptionText("hi", false))
+	if err != nil {
+		t.Fatalf("PostMessage() error = %v", err)
+	}
+	if ts != "1700000000.000100" {
+		t.Errorf("PostMessage() ts = %q", ts)
+	}
+	if client.calls != 3 {
+		t.Errorf("calls = %d, want 3", client.calls)
+	}
+	if len(*waits) != 2 {
+		t.Fatalf("waited %d times, want 2", len(*waits))
+	}
+	for _, wait := range *waits {
+		if wait <= 2*time.Second || wait > 3*time.Second {
+			t.Errorf("waited %s, want about the 3s Retry-After", wait)
+		}
+	}
+}
+
+func TestSender_GivesUpAfterMaxRetries(t *testing.T) {
+	rateLimited := &slack.RateLimitedError{RetryAfter: time.Second}
+	client := &fakeClient{failures: 10, err: rateLimited}
+	sender, _ := newTestSender(client, 2)
+
+	err := sender.UpdateMessage(context.Background(), "C1", "1.2")
+	if !errors.Is(err, rateLimited) {
+		t.Fatalf("UpdateMessage() error = %v, want the rate limit error", err)
+	}
+	if client.calls != 3 {
+		t.Errorf("calls = %d, want 3 (1 + 2 retries)", client.calls)
+	}
+}
+
+func TestSender_DoesNotRetryPermanentErrors(t *testing.T) {
+	client := &fakeClient{failures: 1, err: slack.SlackErrorResponse{Err: "channel_not_found"}}
+	sender, waits := newTestSender(client, 3)
+
+	if err := sender.DeleteMessage(context.Background(), "C1", "1.2"); err == nil {
+		t.Fatal("DeleteMessage() succeeded, want channel_not_found")
+	}
+	if client.calls != 1 || len(*waits) != 0 {
+		t.Errorf("calls = %d, waits = %d; want 1 call and no retries", client.calls, len(*waits))
+	}
+}
+
+func TestSender_RetriesServerErrors(t *testing.T) {
+	client := &fakeClient{failures: 1, err: slack.StatusCodeError{Code: 503, Status: "503 Service Unavailable"}}
+	sender, _ := newTestSender(client, 3)
+
+	if err := sender.UpdateMessage(context.Background(), "C1", "1.2"); err != nil {
+		t.Fatalf("UpdateMessage() error = %v", err)
+	}
+	if client.calls != 2 {
+		t.Errorf("calls = %d, want 2", client.calls)
+	}
+}
+
+func TestSender_DoesNotRepostAfterServerErrors(t *testing.T) {
+	// Slack may have posted the message before failing, so posting it again
+	// could duplicate it
+	client := &fakeClient{failures: 1, err: slack.StatusCodeError{Code: 503, Status: "503 Service Unavailable"}}
+	sender, _ := newTestSender(client, 3)
+
+	if _, err := sender.PostMessage(context.Background(), "C1"); err == nil {
+		t.Fatal("PostMessage() succeeded, want the server error")
+	}
+	if client.calls != 1 {
+		t.Errorf("calls = %d, want 1", client.calls)
+	}
+}
+
+func TestSender_DropsIdleLanes(t *testing.T) {
+	client := &fakeClient{}
+	sender, _ := newTestSender(client, 0)
+
+	ctx := context.Background()
+	sender.PostMessage(ctx, "C1")
+	sender.PostMessage(ctx, "C2")
+	if len(sender.lanes) != 0 {
+		t.Errorf("%d lanes left after unpaced calls, want 0", len(sender.lanes))
+	}
+
+	paced := New(func() Client { return client }, time.Minute, 0, zap.NewNop())
+	paced.PostMessage(ctx, "C1")
+	if len(paced.lanes) != 1 {
+		t.Fatalf("%d lanes after a paced call, want 1 until its pacing runs out", len(paced.lanes))
+	}
+	paced.lanes["C1"].idleAfter = time.Now().Add(-time.Second)
+	paced.lastSweep = time.Time{}
+	paced.PostMessage(ctx, "C2")
+	if _, ok := paced.lanes["C1"]; ok {
+		t.Error("Idle lane for C1 was not swept")
+	}
+}
+
+func TestSender_PacesCallsPerChannel(t *testing.T) {
+	client := &fakeClient{}
+	sender := New(func() Client { return client }, time.Minute, 0, zap.NewNop())
+	var waits []time.Duration
+	sender.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	ctx := context.Background()
+	sender.PostMessage(ctx, "C1")
+	sender.PostMessage(ctx, "C2")
+	if len(waits) != 0 {
+		t.Fatalf("first calls to each channel waited %v", waits)
+	}
+
+	sender.PostMessage(ctx, "C1")
+	if len(waits) != 1 || waits[0] <= 59*time.Second {
+		t.Errorf("second call to C1 waited %v, want about a minute", waits)
+	}
+}
+
+func TestSender_StopsWaitingWhenCanceled(t *testing.T) {
+	client := &fakeClient{failures: 1, err: &slack.RateLimitedError{RetryAfter: time.Hour}}
+	sender := New(func() Client { return client }, 0, 3, zap.NewNop())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := sender.PostMessage(ctx, "C1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("PostMessage() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestTransient(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{&slack.RateLimitedError{RetryAfter: time.Second}, true},
+		{slack.StatusCodeError{Code: 503, Status: "Service Unavailable"}, true},
+		{slack.SlackErrorResponse{Err: "service_unavailable"}, true},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{context.DeadlineExceeded, true},
+		{slack.SlackErrorResponse{Err: "channel_not_found"}, false},
+		{slack.StatusCodeError{Code: 400, Status: "Bad Request"}, false},
+		{context.Canceled, false},
+		{nil, false},
+	} {
+		if got := Transient(tt.err); got != tt.want {
+			t.Errorf("Transient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// filePlaceholder is replaced with the audio path in the command; the path is
+// appended as the last argument when the command doesn't contain it
+const filePlaceholder = "{file}"
+
+// maxResponseBytes caps how much of a transcription API response is read
+const maxResponseBytes = 10 << 20
+
+// Config selects how audio is transcribed. Command takes precedence over URL.
+type Config struct {
+	Command string        // e.g. "whisper-transcribe {file}"; transcript is read from stdout
+	URL     string        // Endpoint accepting a multipart "file" upload, e.g. an OpenAI-compatible /v1/audio/transcriptions
+	APIKey  string        // Sent as a bearer token to URL
+	Model   string        // Sent as the "model" form field to URL when set
+	Timeout time.Duration // Per file
+}
+
+// Transcriber turns audio files into text using an external command or API
+type Transcriber struct {
+	config Config
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewTranscriber creates a transcriber; it is disabled when neither a command
+// nor a URL is configured
+func NewTranscriber(config Config, logger *zap.Logger) *Transcriber {
+	return &Transcriber{
+		config: config,
+		client: &http.Client{},
+		logger: logger,
+	}
+}
+
+// Enabled reports whether a transcription backend is configured
+func (t *Transcriber) Enabled() bool {
+	return t.config.Command != "" || t.config.URL != ""
+}
+
+// Transcribe returns the transcript of the audio file at path
+func (t *Transcriber) Transcribe(ctx context.Context, path string) (string, error) {
+	if !t.Enabled() {
+		return "", fmt.Errorf("audio transcription is not configured")
+	}
+
+	if t.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.config.Timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	var transcript string
+	var err error
+	if t.config.Command != "" {
+		transcript, err = t.runCommand(ctx, path)
+	} else {
+		transcript, err = t.callAPI(ctx, path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	transcript = strings.TrimSpace(transcript)
+	if transcript == "" {
+		return "", fmt.Errorf("transcription returned no text")
+	}
+
+	t.logger.Info("Transcribed audio file",
+		zap.String("path", path),
+		zap.Int("transcript_length", len(transcript)),
+		zap.Duration("duration", time.Since(started)))
+	return transcript, nil
+}
+
+// runCommand runs the configured command and returns its standard output
+func (t *Transcriber) runCommand(ctx context.Context, path string) (string, error) {
+	args := strings.Fields(t.config.Command)
+	substituted := false
+	for i, arg := range args {
+		if strings.Contains(arg, filePlaceholder) {
+			args[i] = strings.ReplaceAll(arg, filePlaceholder, path)
+			substituted = true
+		}
+	}
+	if !substituted {
+		args = append(args, path)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("transcription timed out after %s", t.config.Timeout)
+		}
+		return "", fmt.Errorf("transcription command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// callAPI uploads the file to the configured endpoint. A JSON response is
+// expected to carry the transcript in "text"; anything else is used as-is.
+func (t *Transcriber) callAPI(ctx context.Context, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return "", fmt.Errorf("failed to read audio file: %w", err)
+	}
+	if t.config.Model != "" {
+		if err := form.WriteField("model", t.config.Model); err != nil {
+			return "", fmt.Errorf("failed to build upload: %w", err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.config.URL, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if t.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.config.APIKey)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("transcription request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read transcription response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("transcription API returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		var result struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return "", fmt.Errorf("failed to parse transcription response: %w", err)
+		}
+		return result.Text, nil
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,99 @@
+package transcribe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func writeAudio(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "huddle.m4a")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write audio file: %v", err)
+	}
+	return path
+}
+
+func TestTranscriber_Command(t *testing.T) {
+	path := writeAudio(t, "  we decided to ship friday\n")
+
+	// The path is appended when the command has no placeholder
+	transcriber := NewTranscriber(Config{Command: "cat", Timeout: 5 * time.Second}, zap.NewNop())
+	transcript, err := transcriber.Transcribe(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if transcript != "we decided to ship friday" {
+		t.Errorf("Unexpected transcript: %q", transcript)
+	}
+
+	transcriber = NewTranscriber(Config{Command: "echo file={file}"}, zap.NewNop())
+	transcript, err = transcriber.Transcribe(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if transcript != "file="+path {
+		t.Errorf("Expected placeholder substitution, got %q", transcript)
+	}
+
+	transcriber = NewTranscriber(Config{Command: "false"}, zap.NewNop())
+	if _, err := transcriber.Transcribe(context.Background(), path); err == nil {
+		t.Error("Expected failing command to return an error")
+	}
+}
+
+func TestTranscriber_API(t *testing.T) {
+	path := writeAudio(t, "audio-bytes")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("model") != "whisper-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":"heard ` + string(data) + `"}`))
+	}))
+	defer server.Close()
+
+	transcriber := NewTranscriber(Config{URL: server.URL, APIKey: "secret", Model: "whisper-1"}, zap.NewNop())
+	transcript, err := transcriber.Transcribe(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if transcript != "heard audio-bytes" {
+		t.Errorf("Unexpected transcript: %q", transcript)
+	}
+
+	transcriber = NewTranscriber(Config{URL: server.URL, APIKey: "wrong"}, zap.NewNop())
+	if _, err := transcriber.Transcribe(context.Background(), path); err == nil {
+		t.Error("Expected error for non-200 response")
+	}
+}
+
+func TestTranscriber_Disabled(t *testing.T) {
+	transcriber := NewTranscriber(Config{}, zap.NewNop())
+	if transcriber.Enabled() {
+		t.Error("Expected transcriber without command or URL to be disabled")
+	}
+	if _, err := transcriber.Transcribe(context.Background(), "/tmp/none.m4a"); err == nil {
+		t.Error("Expected error when disabled")
+	}
+}
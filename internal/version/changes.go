@@ -0,0 +1,56 @@
+package version
+
+import (
+	"strings"
+
+	claudeonslack "github.com/ghabxph/claude-on-slack"
+)
+
+// ChangesSince lists the changes between the previous version and this one,
+// from the CHANGELOG built into the binary. With no previous version, only
+// this version's changes are listed.
+func ChangesSince(previous string) []string {
+	return parseChanges(claudeonslack.Changelog, previous, Version)
+}
+
+// parseChanges lists the "### " entry headings of the CHANGELOG sections
+// after previous up to and including current, newest first. Sections are
+// "## [version] - date" headings, newest first; unreleased changes aren't
+// listed. It returns nil if current has no section.
+func parseChanges(changelog, previous, current string) []string {
+	var changes []string
+	inRange, found := false, false
+	for _, line := range strings.Split(changelog, "\n") {
+		line = strings.TrimSpace(line)
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			name := sectionVersion(heading)
+			if previous != "" && name == previous {
+				break
+			}
+			if name == current {
+				inRange, found = true, true
+			} else if found && previous == "" {
+				break
+			}
+			continue
+		}
+		if entry, ok := strings.CutPrefix(line, "### "); ok && inRange {
+			changes = append(changes, strings.TrimSpace(entry))
+		}
+	}
+	if !found {
+		return nil
+	}
+	return changes
+}
+
+// sectionVersion returns the version a "## [version] - date" heading names,
+// or "Unreleased"
+func sectionVersion(heading string) string {
+	heading = strings.TrimPrefix(heading, "[")
+	if name, _, ok := strings.Cut(heading, "]"); ok {
+		return strings.TrimSpace(name)
+	}
+	name, _, _ := strings.Cut(heading, " ")
+	return name
+}
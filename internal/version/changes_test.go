@@ -0,0 +1,57 @@
+package version
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testChangelog = `# Changelog
+
+## [Unreleased]
+
+### Added - Not Shipped Yet
+- **Draft**: Not in any version
+
+## [2.1.0] - 2025-09-02
+
+### Added - Session Titles
+- **Titles**: Sessions are named
+
+### Fixed - Footer Spacing
+
+## [2.0.1] - 2025-09-01
+
+### Fixed - Startup Crash
+
+## [2.0.0] - 2025-08-31
+
+### Added - Everything
+`
+
+func TestParseChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		current  string
+		want     []string
+	}{
+		{"one version", "2.0.1", "2.1.0", []string{"Added - Session Titles", "Fixed - Footer Spacing"}},
+		{"several versions", "2.0.0", "2.1.0", []string{"Added - Session Titles", "Fixed - Footer Spacing", "Fixed - Startup Crash"}},
+		{"first notice", "", "2.0.1", []string{"Fixed - Startup Crash"}},
+		{"unknown previous", "1.9.0", "2.0.1", []string{"Fixed - Startup Crash", "Added - Everything"}},
+		{"unknown current", "2.0.0", "3.0.0", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseChanges(testChangelog, tt.previous, tt.current); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseChanges(%q, %q) = %q, want %q", tt.previous, tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangesSince_EmbeddedChangelog(t *testing.T) {
+	if changes := ChangesSince(""); len(changes) == 0 {
+		t.Errorf("Expected the built-in CHANGELOG to have a section for v%s", Version)
+	}
+}
@@ -0,0 +1,79 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Orphan is a managed workspace directory that no session refers to
+type Orphan struct {
+	Path    string
+	ModTime time.Time
+}
+
+// ManagedDirs returns the workspaces under roots: the non-hidden immediate
+// subdirectories of each root, sorted. Roots that don't exist are skipped.
+func ManagedDirs(roots []string) ([]string, error) {
+	var dirs []string
+	for _, root := range roots {
+		entries, err := os.ReadDir(root)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", root, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+				dirs = append(dirs, filepath.Join(root, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// FindOrphans returns the managed directories that are unrelated to every
+// directory in used and haven't been modified within minAge. Symlinks are
+// never orphans, since deleting through them could reach outside the roots. A directory
+// is in use when a used directory is it, is inside it, or contains it.
+func FindOrphans(managed, used []string, minAge time.Duration, now time.Time) []Orphan {
+	var orphans []Orphan
+	for _, dir := range managed {
+		if inUse(dir, used) {
+			continue
+		}
+		info, err := os.Lstat(dir)
+		if err != nil || !info.IsDir() || now.Sub(info.ModTime()) < minAge {
+			continue
+		}
+		orphans = append(orphans, Orphan{Path: dir, ModTime: info.ModTime()})
+	}
+	return orphans
+}
+
+// inUse reports whether dir and any of used are the same directory or one
+// contains the other
+func inUse(dir string, used []string) bool {
+	dir = filepath.Clean(dir)
+	for _, path := range used {
+		if path == "" {
+			continue
+		}
+		path = filepath.Clean(path)
+		if path == dir || isWithin(path, dir) || isWithin(dir, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithin reports whether path is strictly inside dir
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
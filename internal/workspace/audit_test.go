@@ -0,0 +1,79 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFindOrphans(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{"used", "nested", "orphan", "fresh", ".cache"} {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if name != "fresh" {
+			if err := os.Chtimes(dir, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	managed, err := ManagedDirs([]string{root, filepath.Join(root, "missing")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(root, "fresh"), filepath.Join(root, "nested"), filepath.Join(root, "orphan"), filepath.Join(root, "used")}
+	if !reflect.DeepEqual(managed, want) {
+		t.Fatalf("ManagedDirs() = %v, want %v", managed, want)
+	}
+
+	used := []string{filepath.Join(root, "used") + "/", filepath.Join(root, "nested", "repo"), "/elsewhere"}
+	orphans := FindOrphans(managed, used, 24*time.Hour, time.Now())
+	if len(orphans) != 1 || orphans[0].Path != filepath.Join(root, "orphan") {
+		t.Errorf("FindOrphans() = %v, want only the orphan directory", orphans)
+	}
+
+	// A session working in the root itself keeps everything under it
+	if orphans := FindOrphans(managed, []string{root}, 0, time.Now()); len(orphans) != 0 {
+		t.Errorf("Expected nothing orphaned under a used root, got %v", orphans)
+	}
+}
+
+func TestFindOrphans_SkipsSymlinks(t *testing.T) {
+	root := t.TempDir()
+	target := t.TempDir()
+	link := filepath.Join(root, "linked")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if managed, err := ManagedDirs([]string{root}); err != nil || len(managed) != 0 {
+		t.Errorf("ManagedDirs() = %v, %v; want no symlinked directories", managed, err)
+	}
+	if orphans := FindOrphans([]string{link}, nil, 0, time.Now()); len(orphans) != 0 {
+		t.Errorf("FindOrphans() = %v, want symlinks skipped", orphans)
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+	tests := []struct {
+		path, dir string
+		want      bool
+	}{
+		{"/ws/a/b", "/ws/a", true},
+		{"/ws/a", "/ws/a", false},
+		{"/ws/ab", "/ws/a", false},
+		{"/ws", "/ws/a", false},
+		{"/ws/..a", "/ws", true},
+	}
+	for _, tt := range tests {
+		if got := isWithin(tt.path, tt.dir); got != tt.want {
+			t.Errorf("isWithin(%q, %q) = %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
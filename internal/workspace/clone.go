@@ -0,0 +1,169 @@
+package workspace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ghabxph/claude-on-slack/internal/git"
+)
+
+// scpURLPattern matches scp-like SSH URLs such as git@github.com:org/repo.git
+var scpURLPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+@([A-Za-z0-9.-]+):([^/].*)$`)
+
+// RepoURL is a git URL that may be cloned into a managed workspace
+type RepoURL struct {
+	URL   string // As given
+	Host  string // Lowercased host name
+	Path  string // Repository path on the host without .git, e.g. org/repo
+	HTTPS bool   // Cloned over HTTPS rather than SSH
+}
+
+// LooksLikeRepoURL reports whether arg is meant as a git URL rather than a
+// directory
+func LooksLikeRepoURL(arg string) bool {
+	return strings.Contains(arg, "://") || scpURLPattern.MatchString(arg)
+}
+
+// ParseRepoURL checks that raw is an HTTPS or SSH URL of a repository on one
+// of allowedHosts. Other transports, such as local paths and ext::, are
+// refused because they can read or run anything on the host.
+func ParseRepoURL(raw string, allowedHosts []string) (*RepoURL, error) {
+	repo := &RepoURL{URL: raw}
+	var path string
+	if match := scpURLPattern.FindStringSubmatch(raw); match != nil {
+		repo.Host, path = match[1], match[2]
+	} else {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q isn't a valid URL", raw)
+		}
+		switch u.Scheme {
+		case "https":
+			// Credentials in the URL would end up in .git/config and logs
+			if u.User != nil {
+				return nil, fmt.Errorf("leave credentials out of the URL; the bot's own are used")
+			}
+			repo.HTTPS = true
+		case "ssh":
+		default:
+			return nil, fmt.Errorf("only https:// and SSH URLs can be cloned, not %s://", u.Scheme)
+		}
+		repo.Host, path = u.Hostname(), u.Path
+	}
+
+	repo.Host = strings.ToLower(repo.Host)
+	if !slices.Contains(allowedHosts, repo.Host) {
+		return nil, fmt.Errorf("cloning from %s isn't allowed; allowed hosts: %s", repo.Host, strings.Join(allowedHosts, ", "))
+	}
+
+	repo.Path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if repo.Path == "" {
+		return nil, fmt.Errorf("%q doesn't name a repository", raw)
+	}
+	for _, part := range strings.Split(repo.Path, "/") {
+		if part == "" || part == "." || part == ".." || strings.HasPrefix(part, "-") {
+			return nil, fmt.Errorf("%q isn't a valid repository path", repo.Path)
+		}
+	}
+	return repo, nil
+}
+
+// DirName is the directory the repository is cloned into under the clone
+// root, e.g. org-repo-1a2b3c4d. The suffix is a hash of the host and path, so
+// org/my-repo, org-my/repo, and the same path on another host don't share a
+// directory.
+func (r *RepoURL) DirName() string {
+	sum := sha256.Sum256([]byte(r.Host + "/" + strings.ToLower(r.Path)))
+	return pathElement(strings.ReplaceAll(r.Path, "/", "-")) + "-" + hex.EncodeToString(sum[:4])
+}
+
+// sameRepo reports whether a remote URL points at the same repository
+func (r *RepoURL) sameRepo(remote string) bool {
+	other, err := ParseRepoURL(remote, []string{strings.ToLower(hostOf(remote))})
+	return err == nil && other.Host == r.Host && strings.EqualFold(other.Path, r.Path)
+}
+
+// hostOf returns the host of an HTTPS, SSH, or scp-like URL, or ""
+func hostOf(raw string) string {
+	if match := scpURLPattern.FindStringSubmatch(raw); match != nil {
+		return match[1]
+	}
+	if u, err := url.Parse(raw); err == nil {
+		return u.Hostname()
+	}
+	return ""
+}
+
+// CloneCredentials are what the bot authenticates to clone hosts with
+type CloneCredentials struct {
+	SSHKey string // Private key file for SSH; empty uses the host's SSH setup
+	Token  string // Access token sent over HTTPS
+}
+
+// CloneEnv returns the environment git clones repo with. Git never prompts,
+// only HTTPS and SSH are allowed (submodules included), and a token is only
+// sent to the repository's own host. Credentials are passed in the
+// environment so they stay out of the process list and the clone's config.
+func CloneEnv(repo *RepoURL, creds CloneCredentials) []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL=https:ssh"}
+	if !repo.HTTPS {
+		ssh := "ssh -o BatchMode=yes"
+		if creds.SSHKey != "" {
+			ssh += " -o IdentitiesOnly=yes -i '" + strings.ReplaceAll(creds.SSHKey, "'", `'\''`) + "'"
+		}
+		return append(env, "GIT_SSH_COMMAND="+ssh)
+	}
+	if creds.Token != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + creds.Token))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.https://"+repo.Host+"/.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+auth)
+	}
+	return env
+}
+
+// CloneRepo clones repo into its directory under root and returns the
+// directory. If the repository was cloned there before, the existing
+// checkout is reused as it is and existed is true.
+func CloneRepo(ctx context.Context, root string, repo *RepoURL, creds CloneCredentials, progress func(line string)) (dir string, existed bool, err error) {
+	dir = filepath.Join(root, repo.DirName())
+	if info, err := os.Stat(dir); err == nil {
+		if !info.IsDir() {
+			return "", false, fmt.Errorf("%s is not a directory", dir)
+		}
+		remotes, err := git.Remotes(ctx, dir)
+		if err != nil {
+			return "", false, fmt.Errorf("%s already exists and isn't a git checkout", dir)
+		}
+		for _, remote := range remotes {
+			if remote.Name == "origin" && repo.sameRepo(remote.URL) {
+				return dir, true, nil
+			}
+		}
+		return "", false, fmt.Errorf("%s already holds a different repository", dir)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", false, err
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", false, err
+	}
+	opts := git.CloneOptions{Env: CloneEnv(repo, creds), Progress: progress}
+	if err := git.CloneWithProgress(ctx, repo.URL, dir, opts); err != nil {
+		// Don't leave a partial clone behind to be mistaken for a workspace
+		os.RemoveAll(dir)
+		return "", false, err
+	}
+	return dir, false, nil
+}
@@ -0,0 +1,44 @@
+package workspace
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// dirLock serializes work on one directory; users counts holders and waiters
+// so the lock can be dropped once nobody needs it
+type dirLock struct {
+	mu    sync.Mutex
+	users int
+}
+
+var (
+	dirLocksMu sync.Mutex
+	dirLocks   = make(map[string]*dirLock)
+)
+
+// lockDir blocks until no other caller in the process is working on dir and
+// returns the function that releases it
+func lockDir(dir string) func() {
+	dir = filepath.Clean(dir)
+
+	dirLocksMu.Lock()
+	lock := dirLocks[dir]
+	if lock == nil {
+		lock = &dirLock{}
+		dirLocks[dir] = lock
+	}
+	lock.users++
+	dirLocksMu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		dirLocksMu.Lock()
+		if lock.users--; lock.users == 0 {
+			delete(dirLocks, dir)
+		}
+		dirLocksMu.Unlock()
+	}
+}
@@ -0,0 +1,228 @@
+// Package workspace summarizes a working directory without running Claude
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghabxph/claude-on-slack/internal/git"
+)
+
+// Limits bound how much of a directory tree is scanned
+type Limits struct {
+	MaxFiles  int // Stop counting after this many files
+	RecentTop int // How many recently modified files to keep
+}
+
+// DefaultLimits keep a scan well under a second on typical repositories
+var DefaultLimits = Limits{MaxFiles: 50000, RecentTop: 5}
+
+// skippedDirs are dependency, build, and VCS directories that would drown
+// out the project's own files
+var skippedDirs = map[string]bool{
+	".git":         true,
+	".hg":          true,
+	".svn":         true,
+	"node_modules": true,
+	"vendor":       true,
+	".venv":        true,
+	"venv":         true,
+	"__pycache__":  true,
+	"target":       true,
+	"dist":         true,
+	"build":        true,
+	".next":        true,
+	".idea":        true,
+	".vscode":      true,
+}
+
+// languages maps file extensions to language names
+var languages = map[string]string{
+	".go":     "Go",
+	".py":     "Python",
+	".js":     "JavaScript",
+	".jsx":    "JavaScript",
+	".mjs":    "JavaScript",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".rs":     "Rust",
+	".java":   "Java",
+	".kt":     "Kotlin",
+	".rb":     "Ruby",
+	".php":    "PHP",
+	".c":      "C",
+	".h":      "C",
+	".cc":     "C++",
+	".cpp":    "C++",
+	".hpp":    "C++",
+	".cs":     "C#",
+	".swift":  "Swift",
+	".scala":  "Scala",
+	".sh":     "Shell",
+	".bash":   "Shell",
+	".sql":    "SQL",
+	".html":   "HTML",
+	".css":    "CSS",
+	".scss":   "CSS",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+	".md":     "Markdown",
+	".yaml":   "YAML",
+	".yml":    "YAML",
+	".json":   "JSON",
+	".toml":   "TOML",
+	".tf":     "Terraform",
+	".proto":  "Protobuf",
+	".lua":    "Lua",
+	".dart":   "Dart",
+	".ex":     "Elixir",
+	".exs":    "Elixir",
+}
+
+// Language is how much of a tree is written in one language
+type Language struct {
+	Name  string
+	Files int
+	Bytes int64
+}
+
+// File is a file in the tree, relative to its root
+type File struct {
+	Path    string
+	ModTime time.Time
+}
+
+// Inventory summarizes a directory tree
+type Inventory struct {
+	Dir       string
+	Files     int
+	Dirs      int
+	Bytes     int64
+	Languages []Language // Largest first
+	Recent    []File     // Most recently modified first
+	Skipped   []string   // Skipped dependency and build directories, relative to Dir
+	Truncated bool       // MaxFiles was reached
+
+	// Git details; empty if Dir isn't in a repository
+	GitRoot string
+	Branch  string
+	Remotes []git.Remote
+}
+
+// Scan walks dir and summarizes it. Unreadable entries are skipped; the scan
+// stops early when ctx is done or Limits.MaxFiles is reached.
+func Scan(ctx context.Context, dir string, limits Limits) (*Inventory, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read working directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	inv := &Inventory{Dir: dir}
+	byLanguage := make(map[string]*Language)
+	errStop := errors.New("stop")
+
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && path != dir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if entry.IsDir() {
+			if path == dir {
+				return nil
+			}
+			if skippedDirs[entry.Name()] {
+				if rel, err := filepath.Rel(dir, path); err == nil {
+					inv.Skipped = append(inv.Skipped, rel)
+				}
+				return fs.SkipDir
+			}
+			inv.Dirs++
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		if limits.MaxFiles > 0 && inv.Files >= limits.MaxFiles {
+			inv.Truncated = true
+			return errStop
+		}
+
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		inv.Files++
+		inv.Bytes += fileInfo.Size()
+
+		if name, ok := languages[strings.ToLower(filepath.Ext(path))]; ok {
+			language := byLanguage[name]
+			if language == nil {
+				language = &Language{Name: name}
+				byLanguage[name] = language
+			}
+			language.Files++
+			language.Bytes += fileInfo.Size()
+		}
+
+		if rel, err := filepath.Rel(dir, path); err == nil {
+			inv.Recent = addRecent(inv.Recent, File{Path: rel, ModTime: fileInfo.ModTime()}, limits.RecentTop)
+		}
+		return nil
+	})
+	if err != nil && err != errStop {
+		return nil, fmt.Errorf("failed to scan working directory: %w", err)
+	}
+
+	for _, language := range byLanguage {
+		inv.Languages = append(inv.Languages, *language)
+	}
+	sort.Slice(inv.Languages, func(i, j int) bool {
+		if inv.Languages[i].Bytes != inv.Languages[j].Bytes {
+			return inv.Languages[i].Bytes > inv.Languages[j].Bytes
+		}
+		return inv.Languages[i].Name < inv.Languages[j].Name
+	})
+
+	if root, err := git.Root(ctx, dir); err == nil {
+		inv.GitRoot = root
+		inv.Branch, _ = git.Branch(ctx, dir)
+		inv.Remotes, _ = git.Remotes(ctx, dir)
+	}
+
+	return inv, nil
+}
+
+// addRecent inserts file into recent, kept sorted newest first and capped at top
+func addRecent(recent []File, file File, top int) []File {
+	if top <= 0 {
+		return recent
+	}
+	i := sort.Search(len(recent), func(i int) bool { return recent[i].ModTime.Before(file.ModTime) })
+	if i >= top {
+		return recent
+	}
+	recent = append(recent, File{})
+	copy(recent[i+1:], recent[i:])
+	recent[i] = file
+	if len(recent) > top {
+		recent = recent[:top]
+	}
+	return recent
+}
@@ -0,0 +1,74 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set times on %s: %v", path, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n\nfunc main() {}\n", now.Add(-time.Hour))
+	writeFile(t, filepath.Join(dir, "internal", "app", "app.go"), "package app\n", now)
+	writeFile(t, filepath.Join(dir, "scripts", "deploy.sh"), "#!/bin/sh\n", now.Add(-2*time.Hour))
+	writeFile(t, filepath.Join(dir, "LICENSE"), "MIT", now.Add(-3*time.Hour))
+	writeFile(t, filepath.Join(dir, "node_modules", "left-pad", "index.js"), "module.exports = 1\n", now)
+
+	inv, err := Scan(context.Background(), dir, Limits{MaxFiles: 100, RecentTop: 2})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	if inv.Files != 4 || inv.Dirs != 3 {
+		t.Errorf("Files, Dirs = %d, %d; want 4, 3", inv.Files, inv.Dirs)
+	}
+	if len(inv.Skipped) != 1 || inv.Skipped[0] != "node_modules" {
+		t.Errorf("Skipped = %v, want [node_modules]", inv.Skipped)
+	}
+	if len(inv.Languages) != 2 || inv.Languages[0].Name != "Go" || inv.Languages[0].Files != 2 || inv.Languages[1].Name != "Shell" {
+		t.Errorf("Unexpected languages: %+v", inv.Languages)
+	}
+	if len(inv.Recent) != 2 || inv.Recent[0].Path != filepath.Join("internal", "app", "app.go") || inv.Recent[1].Path != "main.go" {
+		t.Errorf("Unexpected recent files: %+v", inv.Recent)
+	}
+	if inv.Truncated {
+		t.Error("Expected a complete scan")
+	}
+}
+
+func TestScan_Truncated(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		writeFile(t, filepath.Join(dir, name), "package x\n", time.Now())
+	}
+
+	inv, err := Scan(context.Background(), dir, Limits{MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if !inv.Truncated || inv.Files != 2 {
+		t.Errorf("Truncated, Files = %v, %d; want true, 2", inv.Truncated, inv.Files)
+	}
+}
+
+func TestScan_MissingDirectory(t *testing.T) {
+	if _, err := Scan(context.Background(), filepath.Join(t.TempDir(), "missing"), DefaultLimits); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,95 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ghabxph/claude-on-slack/internal/git"
+)
+
+// Template placeholders, replaced with the Slack user and channel IDs
+const (
+	UserPlaceholder    = "{user}"
+	ChannelPlaceholder = "{channel}"
+)
+
+var (
+	placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+	unsafeChars        = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+)
+
+// CheckTemplate reports whether template is an absolute path that only uses
+// known placeholders
+func CheckTemplate(template string) error {
+	if !filepath.IsAbs(template) {
+		return fmt.Errorf("%q is not an absolute path", template)
+	}
+	for _, p := range placeholderPattern.FindAllString(template, -1) {
+		if p != UserPlaceholder && p != ChannelPlaceholder {
+			return fmt.Errorf("unknown placeholder %s (use %s or %s)", p, UserPlaceholder, ChannelPlaceholder)
+		}
+	}
+	return nil
+}
+
+// ExpandTemplate fills in the placeholders of template. Values are reduced
+// to letters, digits, dashes, and underscores so they can't escape the
+// template's directory.
+func ExpandTemplate(template, userID, channelID string) string {
+	r := strings.NewReplacer(
+		UserPlaceholder, pathElement(userID),
+		ChannelPlaceholder, pathElement(channelID),
+	)
+	return filepath.Clean(r.Replace(template))
+}
+
+// pathElement makes value safe to use as a single directory name
+func pathElement(value string) string {
+	if value = unsafeChars.ReplaceAllString(value, "_"); value == "" {
+		return "_"
+	}
+	return value
+}
+
+// Provision makes sure dir exists, cloning repoURL into it when it has to
+// be created. An existing directory is left as it is. It reports whether
+// the directory was created. Calls for the same directory run one at a time,
+// so a second run waits for the first clone instead of failing on the half
+// cloned directory and removing it.
+func Provision(ctx context.Context, dir, repoURL string) (bool, error) {
+	unlock := lockDir(dir)
+	defer unlock()
+
+	info, err := os.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return false, fmt.Errorf("%s is not a directory", dir)
+		}
+		return false, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	if repoURL == "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return false, err
+	}
+	if err := git.Clone(ctx, repoURL, dir); err != nil {
+		// Don't leave a partial clone behind to be mistaken for a workspace
+		os.RemoveAll(dir)
+		return false, err
+	}
+	return true, nil
+}
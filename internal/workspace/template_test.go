@@ -0,0 +1,121 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCheckTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		valid    bool
+	}{
+		{"/home/{user}/claude/{channel}", true},
+		{"/srv/claude", true},
+		{"claude/{user}", false},
+		{"/home/{username}", false},
+	}
+	for _, tt := range tests {
+		if err := CheckTemplate(tt.template); (err == nil) != tt.valid {
+			t.Errorf("CheckTemplate(%q) = %v, want valid=%v", tt.template, err, tt.valid)
+		}
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	tests := []struct {
+		template, user, channel, want string
+	}{
+		{"/home/{user}/claude/{channel}", "U123", "C456", "/home/U123/claude/C456"},
+		{"/ws/{user}-{user}", "U1", "", "/ws/U1-U1"},
+		{"/ws/{user}/{channel}", "../../etc", "", "/ws/_etc/_"},
+		{"/ws/{channel}", "U1", "C1/x", "/ws/C1_x"},
+	}
+	for _, tt := range tests {
+		if got := ExpandTemplate(tt.template, tt.user, tt.channel); got != tt.want {
+			t.Errorf("ExpandTemplate(%q, %q, %q) = %q, want %q", tt.template, tt.user, tt.channel, got, tt.want)
+		}
+	}
+}
+
+func TestProvision(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "U1", "C1")
+
+	created, err := Provision(ctx, dir, "")
+	if err != nil || !created {
+		t.Fatalf("Provision() = %v, %v; want true, nil", created, err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Fatalf("Expected %s to be a directory: %v", dir, err)
+	}
+
+	writeFile(t, filepath.Join(dir, "notes.txt"), "keep", time.Now())
+	created, err = Provision(ctx, dir, "")
+	if err != nil || created {
+		t.Fatalf("Provision() on existing dir = %v, %v; want false, nil", created, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("Existing contents should be kept: %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	writeFile(t, file, "", time.Now())
+	if _, err := Provision(ctx, file, ""); err == nil {
+		t.Error("Expected an error for a path that is a file")
+	}
+
+	missing := filepath.Join(t.TempDir(), "clone")
+	if _, err := Provision(ctx, missing, filepath.Join(t.TempDir(), "no-such-repo")); err == nil {
+		t.Error("Expected a failed clone to return an error")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("Failed clone should not leave %s behind", missing)
+	}
+}
+
+func TestProvision_ConcurrentClones(t *testing.T) {
+	repo := gitRepo(t)
+	dir := filepath.Join(t.TempDir(), "U1", "C1")
+
+	var wg sync.WaitGroup
+	results := make(chan error, 4)
+	created := make(chan bool, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := Provision(context.Background(), dir, repo)
+			results <- err
+			created <- ok
+		}()
+	}
+	wg.Wait()
+	close(results)
+	close(created)
+
+	for err := range results {
+		if err != nil {
+			t.Errorf("Provision() error = %v", err)
+		}
+	}
+	clones := 0
+	for ok := range created {
+		if ok {
+			clones++
+		}
+	}
+	if clones != 1 {
+		t.Errorf("Expected exactly one call to clone, got %d", clones)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "service", "main.go")); err != nil {
+		t.Errorf("The clone should survive concurrent calls: %v", err)
+	}
+	if len(dirLocks) != 0 {
+		t.Errorf("Expected directory locks to be released, %d left", len(dirLocks))
+	}
+}
@@ -0,0 +1,206 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghabxph/claude-on-slack/internal/git"
+)
+
+// WorktreeBranchPrefix starts the name of every session branch, so /merge
+// and /discard never act on a branch a person checked out
+const WorktreeBranchPrefix = "claude/session-"
+
+// ErrNotSessionWorktree is returned for directories that aren't in a
+// session's worktree
+var ErrNotSessionWorktree = errors.New("not in a session worktree")
+
+// SessionWorktree is a session's worktree and the checkout it branched from
+type SessionWorktree struct {
+	Dir          string // Top of the session's worktree
+	Branch       string
+	MainCheckout string // Top of the repository's main work tree
+}
+
+// ShortSessionID is the part of a session ID used in branch and directory
+// names
+func ShortSessionID(sessionID string) string {
+	if len(sessionID) > 8 {
+		return sessionID[:8]
+	}
+	return sessionID
+}
+
+// WorktreesDir returns where session worktrees of the repository at root
+// go: worktreeDir if set, otherwise a "<repository>-worktrees" directory
+// next to it
+func WorktreesDir(root, worktreeDir string) string {
+	if worktreeDir != "" {
+		return filepath.Join(worktreeDir, pathElement(filepath.Base(root)))
+	}
+	return root + "-worktrees"
+}
+
+// AddSessionWorktree gives a session its own worktree on a new
+// claude/session-<short-id> branch when dir is in a git repository, and
+// returns the directory in it that corresponds to dir. Outside a repository
+// dir is returned as it is.
+func AddSessionWorktree(ctx context.Context, dir, worktreeDir, sessionID string) (string, error) {
+	root, err := git.Root(ctx, dir)
+	if err != nil {
+		return dir, nil
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", err
+	}
+
+	// Clear out records and branches of worktrees removed behind git's back
+	if _, err := PruneSessionWorktrees(ctx, root); err != nil {
+		return "", err
+	}
+
+	short := pathElement(ShortSessionID(sessionID))
+	path := filepath.Join(WorktreesDir(root, worktreeDir), short)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := git.AddWorktree(ctx, root, path, WorktreeBranchPrefix+short); err != nil {
+		return "", err
+	}
+	return filepath.Join(path, rel), nil
+}
+
+// FindSessionWorktree returns the session worktree containing dir, or
+// ErrNotSessionWorktree
+func FindSessionWorktree(ctx context.Context, dir string) (*SessionWorktree, error) {
+	root, err := git.Root(ctx, dir)
+	if err != nil {
+		return nil, ErrNotSessionWorktree
+	}
+	worktrees, err := git.Worktrees(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	if len(worktrees) < 2 {
+		return nil, ErrNotSessionWorktree
+	}
+
+	for _, wt := range worktrees[1:] {
+		if sameDir(wt.Path, root) && strings.HasPrefix(wt.Branch, WorktreeBranchPrefix) {
+			return &SessionWorktree{Dir: root, Branch: wt.Branch, MainCheckout: worktrees[0].Path}, nil
+		}
+	}
+	return nil, ErrNotSessionWorktree
+}
+
+// sameDir compares directories after resolving symlinks, since git reports
+// resolved paths
+func sameDir(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// MergeSessionWorktree commits the session's uncommitted changes with
+// message, merges its branch into the main checkout, and removes the
+// worktree and branch. It returns how many commits were landed; with none,
+// the worktree is kept.
+func MergeSessionWorktree(ctx context.Context, wt *SessionWorktree, message string) (int, error) {
+	if _, err := git.CommitAll(ctx, wt.Dir, message); err != nil {
+		return 0, fmt.Errorf("failed to commit the session's changes: %w", err)
+	}
+
+	commits, err := git.CommitsAhead(ctx, wt.MainCheckout, wt.Branch)
+	if err != nil {
+		return 0, err
+	}
+	if commits == 0 {
+		return 0, nil
+	}
+
+	if err := git.Merge(ctx, wt.MainCheckout, wt.Branch); err != nil {
+		return 0, fmt.Errorf("failed to merge %s: %w", wt.Branch, err)
+	}
+	_, err = DiscardSessionWorktree(ctx, wt)
+	return commits, err
+}
+
+// Pending describes what a session worktree holds that the main checkout
+// doesn't: commits on its branch and files with uncommitted changes
+func (wt *SessionWorktree) Pending(ctx context.Context) (commits int, changed []string, err error) {
+	if commits, err = git.CommitsAhead(ctx, wt.MainCheckout, wt.Branch); err != nil {
+		return 0, nil, err
+	}
+	if changed, err = git.ChangedFiles(ctx, wt.Dir); err != nil {
+		return 0, nil, err
+	}
+	return commits, changed, nil
+}
+
+// DiscardSessionWorktree removes the session's worktree, dropping its
+// uncommitted changes, and deletes its branch once the main checkout has all
+// of its commits. A branch with unmerged commits is kept so they can still be
+// recovered, and kept is true.
+func DiscardSessionWorktree(ctx context.Context, wt *SessionWorktree) (kept bool, err error) {
+	if err := git.RemoveWorktree(ctx, wt.MainCheckout, wt.Dir); err != nil {
+		return false, err
+	}
+	if err := git.PruneWorktrees(ctx, wt.MainCheckout); err != nil {
+		return false, err
+	}
+
+	commits, err := git.CommitsAhead(ctx, wt.MainCheckout, wt.Branch)
+	if err != nil {
+		return false, err
+	}
+	if commits > 0 {
+		return true, nil
+	}
+	return false, git.DeleteBranch(ctx, wt.MainCheckout, wt.Branch)
+}
+
+// PruneSessionWorktrees drops the records of session worktrees whose
+// directories were removed, e.g. by /workspace purge, and deletes session
+// branches that no worktree uses and that have nothing left to merge. It
+// returns the deleted branches.
+func PruneSessionWorktrees(ctx context.Context, dir string) ([]string, error) {
+	if err := git.PruneWorktrees(ctx, dir); err != nil {
+		return nil, err
+	}
+	worktrees, err := git.Worktrees(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	branches, err := git.Branches(ctx, dir, WorktreeBranchPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	inUse := make(map[string]bool, len(worktrees))
+	for _, wt := range worktrees {
+		inUse[wt.Branch] = true
+	}
+	var deleted []string
+	for _, branch := range branches {
+		if inUse[branch] {
+			continue
+		}
+		if commits, err := git.CommitsAhead(ctx, worktrees[0].Path, branch); err != nil || commits > 0 {
+			continue
+		}
+		if err := git.DeleteBranch(ctx, worktrees[0].Path, branch); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, branch)
+	}
+	return deleted, nil
+}
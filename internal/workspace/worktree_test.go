@@ -0,0 +1,197 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(filepath.Join(dir, "service"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "service", "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"add", "."},
+		{"commit", "-qm", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestAddSessionWorktree(t *testing.T) {
+	ctx := context.Background()
+	repo := gitRepo(t)
+
+	dir, err := AddSessionWorktree(ctx, filepath.Join(repo, "service"), "", "1a2b3c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d")
+	if err != nil {
+		t.Fatalf("AddSessionWorktree failed: %v", err)
+	}
+	if want := filepath.Join(repo+"-worktrees", "1a2b3c4d", "service"); dir != want {
+		t.Errorf("AddSessionWorktree() = %q, want %q", dir, want)
+	}
+
+	wt, err := FindSessionWorktree(ctx, dir)
+	if err != nil {
+		t.Fatalf("FindSessionWorktree failed: %v", err)
+	}
+	if wt.Branch != "claude/session-1a2b3c4d" || !sameDir(wt.MainCheckout, repo) {
+		t.Errorf("FindSessionWorktree() = %+v", wt)
+	}
+
+	if _, err := FindSessionWorktree(ctx, repo); !errors.Is(err, ErrNotSessionWorktree) {
+		t.Errorf("Expected the main checkout not to be a session worktree, got %v", err)
+	}
+
+	outside := t.TempDir()
+	if dir, err := AddSessionWorktree(ctx, outside, "", "abc"); err != nil || dir != outside {
+		t.Errorf("Expected a directory outside git to be used as is, got %q, %v", dir, err)
+	}
+}
+
+func TestMergeSessionWorktree(t *testing.T) {
+	ctx := context.Background()
+	repo := gitRepo(t)
+	worktreeDir := t.TempDir()
+
+	dir, err := AddSessionWorktree(ctx, repo, worktreeDir, "feedface-0000")
+	if err != nil {
+		t.Fatalf("AddSessionWorktree failed: %v", err)
+	}
+	if want := filepath.Join(worktreeDir, "project", "feedface"); dir != want {
+		t.Errorf("AddSessionWorktree() = %q, want %q", dir, want)
+	}
+	wt, err := FindSessionWorktree(ctx, dir)
+	if err != nil {
+		t.Fatalf("FindSessionWorktree failed: %v", err)
+	}
+
+	if commits, err := MergeSessionWorktree(ctx, wt, "session changes"); err != nil || commits != 0 {
+		t.Errorf("Merging an unchanged worktree = %d, %v; want 0", commits, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "service", "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if commits, changed, err := wt.Pending(ctx); err != nil || commits != 0 || len(changed) != 1 {
+		t.Errorf("Pending() = %d, %v, %v; want 0 commits and 1 file", commits, changed, err)
+	}
+
+	commits, err := MergeSessionWorktree(ctx, wt, "session changes")
+	if err != nil || commits != 1 {
+		t.Fatalf("MergeSessionWorktree() = %d, %v; want 1", commits, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repo, "service", "main.go")); string(content) != "package main\n\nfunc main() {}\n" {
+		t.Errorf("Expected the change in the main checkout, got %q", content)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the worktree to be removed, got %v", err)
+	}
+}
+
+func TestDiscardSessionWorktree(t *testing.T) {
+	ctx := context.Background()
+	repo := gitRepo(t)
+
+	dir, err := AddSessionWorktree(ctx, repo, "", "deadbeef")
+	if err != nil {
+		t.Fatalf("AddSessionWorktree failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "scratch.txt"), []byte("temp\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := FindSessionWorktree(ctx, dir)
+	if err != nil {
+		t.Fatalf("FindSessionWorktree failed: %v", err)
+	}
+
+	if kept, err := DiscardSessionWorktree(ctx, wt); err != nil || kept {
+		t.Fatalf("DiscardSessionWorktree() = %v, %v; want false, nil", kept, err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "scratch.txt")); !os.IsNotExist(err) {
+		t.Error("Expected the discarded change not to reach the main checkout")
+	}
+	out, _ := exec.Command("git", "-C", repo, "branch", "--list", "claude/session-deadbeef").Output()
+	if len(out) != 0 {
+		t.Errorf("Expected the session branch to be deleted, got %q", out)
+	}
+}
+
+func TestDiscardSessionWorktree_KeepsUnmergedBranch(t *testing.T) {
+	ctx := context.Background()
+	repo := gitRepo(t)
+
+	dir, err := AddSessionWorktree(ctx, repo, "", "cafef00d")
+	if err != nil {
+		t.Fatalf("AddSessionWorktree failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "feature.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "."}, {"commit", "-qm", "wip"}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	wt, err := FindSessionWorktree(ctx, dir)
+	if err != nil {
+		t.Fatalf("FindSessionWorktree failed: %v", err)
+	}
+
+	if kept, err := DiscardSessionWorktree(ctx, wt); err != nil || !kept {
+		t.Fatalf("DiscardSessionWorktree() = %v, %v; want true, nil", kept, err)
+	}
+	if _, err := os.Stat(wt.Dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the worktree to be removed, got %v", err)
+	}
+	out, _ := exec.Command("git", "-C", repo, "branch", "--list", "claude/session-cafef00d").Output()
+	if len(out) == 0 {
+		t.Error("Expected the branch with an unmerged commit to be kept")
+	}
+}
+
+func TestPruneSessionWorktrees(t *testing.T) {
+	ctx := context.Background()
+	repo := gitRepo(t)
+
+	dir, err := AddSessionWorktree(ctx, repo, "", "0badc0de")
+	if err != nil {
+		t.Fatalf("AddSessionWorktree failed: %v", err)
+	}
+	if _, err := AddSessionWorktree(ctx, repo, "", "1badc0de"); err != nil {
+		t.Fatalf("AddSessionWorktree failed: %v", err)
+	}
+	// Removed without git, as /workspace purge does
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := PruneSessionWorktrees(ctx, repo)
+	if err != nil {
+		t.Fatalf("PruneSessionWorktrees failed: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "claude/session-0badc0de" {
+		t.Errorf("PruneSessionWorktrees() = %v, want [claude/session-0badc0de]", deleted)
+	}
+	out, _ := exec.Command("git", "-C", repo, "branch", "--list", "claude/session-1badc0de").Output()
+	if len(out) == 0 {
+		t.Error("Expected the branch of the remaining worktree to be kept")
+	}
+}
@@ -0,0 +1,7 @@
+-- Migration 007: Add per-channel toggle for channel topic/purpose prompt context
+-- NULL means the channel follows the CHANNEL_CONTEXT_ENABLED default
+
+ALTER TABLE slack_channels ADD COLUMN channel_context_enabled BOOLEAN;
+
+-- Add comment for clarity
+COMMENT ON COLUMN slack_channels.channel_context_enabled IS 'Inject channel topic/purpose into the system prompt (NULL = use config default)';
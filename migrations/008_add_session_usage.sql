@@ -0,0 +1,22 @@
+-- Migration 008: Add session_usage table for per-run cost tracking
+-- Used for budget-aware model downgrades; rows survive session deletion so
+-- channel spend stays accurate
+
+CREATE TABLE session_usage (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER REFERENCES sessions(id) ON DELETE SET NULL,
+    channel_id VARCHAR(255) NOT NULL,
+    claude_session_id VARCHAR(255),
+    model VARCHAR(100) NOT NULL,
+    cost_usd NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Index for session budget lookups
+CREATE INDEX idx_session_usage_session_id ON session_usage(session_id);
+
+-- Index for channel budget lookups by time window
+CREATE INDEX idx_session_usage_channel_created ON session_usage(channel_id, created_at);
+
+-- Add comment for clarity
+COMMENT ON TABLE session_usage IS 'Cost of each Claude Code run, per session and channel';
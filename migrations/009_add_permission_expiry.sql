@@ -0,0 +1,10 @@
+-- Migration 009: Add permission mode expiry to slack_channels
+-- A channel permission set with a TTL reverts to default once it expires
+
+ALTER TABLE slack_channels ADD COLUMN permission_expires_at TIMESTAMP WITH TIME ZONE;
+
+-- Index for the background expiry sweep
+CREATE INDEX idx_slack_channels_permission_expires_at ON slack_channels(permission_expires_at) WHERE permission_expires_at IS NOT NULL;
+
+-- Add comment for clarity
+COMMENT ON COLUMN slack_channels.permission_expires_at IS 'When the channel permission reverts to default (NULL = never)';
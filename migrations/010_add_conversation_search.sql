@@ -0,0 +1,26 @@
+-- Migration 010: Add full-text conversation search
+-- Sessions remember the channel they were created in so search can be scoped
+-- to channels the requesting user can access
+
+ALTER TABLE sessions ADD COLUMN channel_id VARCHAR(255);
+
+-- Backfill from current channel state where possible
+UPDATE sessions s SET channel_id = sc.channel_id
+FROM slack_channels sc
+WHERE sc.active_session_id = s.id AND s.channel_id IS NULL;
+
+CREATE INDEX idx_sessions_channel_id ON sessions(channel_id);
+
+-- Search document over prompt, response and summary (requires PostgreSQL 12+)
+ALTER TABLE child_sessions ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', COALESCE(summary, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(user_prompt, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(ai_response, '')), 'C')
+    ) STORED;
+
+CREATE INDEX idx_child_sessions_search_vector ON child_sessions USING GIN(search_vector);
+
+-- Add comments for clarity
+COMMENT ON COLUMN sessions.channel_id IS 'Slack channel the session was created in (NULL for sessions created before migration 010)';
+COMMENT ON COLUMN child_sessions.search_vector IS 'Full-text search document over summary, user_prompt and ai_response';
@@ -0,0 +1,7 @@
+-- Migration 011: Add per-channel Claude sub-agent selection to slack_channels
+-- Lists which configured agents are passed to Claude Code in the channel
+
+ALTER TABLE slack_channels ADD COLUMN agents TEXT[];
+
+-- Add comment for clarity
+COMMENT ON COLUMN slack_channels.agents IS 'Names of configured Claude sub-agents enabled in the channel (NULL = all configured agents)';
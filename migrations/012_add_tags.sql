@@ -0,0 +1,18 @@
+-- Migration 012: Add tags table for labelling conversation exchanges
+-- Users tag the latest exchange in a channel and later list exchanges by label
+
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    child_session_id INTEGER NOT NULL REFERENCES child_sessions(id) ON DELETE CASCADE,
+    label VARCHAR(50) NOT NULL,
+    channel_id VARCHAR(255) NOT NULL,
+    tagged_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (child_session_id, label)
+);
+
+-- Index for listing exchanges by label
+CREATE INDEX idx_tags_label ON tags(label, created_at DESC);
+
+-- Add comment for clarity
+COMMENT ON TABLE tags IS 'User-applied labels on child sessions (conversation exchanges)';
@@ -0,0 +1,12 @@
+-- Migration 013: Add per-user preferences
+-- Starts with the completion DM for long runs
+
+CREATE TABLE user_preferences (
+    user_id VARCHAR(255) PRIMARY KEY,
+    notify_on_completion BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Add comment for clarity
+COMMENT ON COLUMN user_preferences.notify_on_completion IS 'DM the user when one of their runs takes longer than NOTIFY_AFTER';
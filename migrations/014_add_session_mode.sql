@@ -0,0 +1,23 @@
+-- Migration 014: Add per-user session mode to slack_channels
+-- In per-user mode each user in a channel has their own active session
+
+ALTER TABLE slack_channels ADD COLUMN session_mode VARCHAR(20) NOT NULL DEFAULT 'shared'
+    CHECK (session_mode IN ('shared', 'per-user'));
+
+-- Active session per (channel, user) for channels in per-user mode
+CREATE TABLE slack_channel_user_sessions (
+    channel_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    active_session_id INTEGER REFERENCES sessions(id) ON DELETE CASCADE,
+    active_child_session_id INTEGER REFERENCES child_sessions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (channel_id, user_id)
+);
+
+-- Find which channel a session is active in
+CREATE INDEX idx_slack_channel_user_sessions_active_session ON slack_channel_user_sessions(active_session_id);
+
+-- Add comments for clarity
+COMMENT ON COLUMN slack_channels.session_mode IS 'shared = one active session for the channel, per-user = each user has their own';
+COMMENT ON TABLE slack_channel_user_sessions IS 'Active session of each user in channels using per-user session mode';
@@ -0,0 +1,17 @@
+-- Migration 015: Track posted "Thinking..." messages
+-- Lets a janitor clean up messages left behind by crashes or failed runs
+
+CREATE TABLE thinking_messages (
+    channel_id VARCHAR(255) NOT NULL,
+    message_ts VARCHAR(32) NOT NULL,
+    run_id VARCHAR(255) NOT NULL,
+    session_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (channel_id, message_ts)
+);
+
+-- Index for the stale message sweep
+CREATE INDEX idx_thinking_messages_created_at ON thinking_messages(created_at);
+
+-- Add comment for clarity
+COMMENT ON TABLE thinking_messages IS 'Thinking messages still visible in Slack; rows are removed when the message is cleared';
@@ -0,0 +1,16 @@
+-- Migration 016: Add configurable default permission modes
+-- Channels remember the mode they started with; the workspace default is a bot setting
+
+ALTER TABLE slack_channels ADD COLUMN default_permission VARCHAR(50) NOT NULL DEFAULT 'default';
+
+-- Workspace-wide settings changed at runtime by admins
+CREATE TABLE bot_settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Add comments for clarity
+COMMENT ON COLUMN slack_channels.default_permission IS 'Permission mode the channel was created with; temporary modes revert to it';
+COMMENT ON TABLE bot_settings IS 'Workspace-wide settings such as default_permission, set with admin commands';
@@ -0,0 +1,27 @@
+-- Migration 017: Dead letter queue for failed Claude runs
+-- Keeps failed prompts with their error details so admins can replay them
+
+CREATE TABLE failed_runs (
+    id SERIAL PRIMARY KEY,
+    channel_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    thread_ts VARCHAR(32),
+    session_id VARCHAR(255),
+    working_directory TEXT,
+    prompt TEXT NOT NULL,
+    error_kind VARCHAR(50) NOT NULL,
+    error_message TEXT NOT NULL,
+    stderr TEXT,
+    retry_count INTEGER NOT NULL DEFAULT 0,
+    retried_at TIMESTAMP WITH TIME ZONE,
+    retried_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Index for listing the most recent failures
+CREATE INDEX idx_failed_runs_created_at ON failed_runs(created_at DESC);
+
+-- Add comments for clarity
+COMMENT ON TABLE failed_runs IS 'Claude runs that failed, kept for inspection and replay with /failed';
+COMMENT ON COLUMN failed_runs.prompt IS 'Prompt as sent to Claude, including attachment references';
+COMMENT ON COLUMN failed_runs.error_kind IS 'Failure category, e.g. network_error, timeout, or claude_error';
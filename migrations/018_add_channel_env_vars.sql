@@ -0,0 +1,15 @@
+-- Migration 018: Per-channel environment variables for Claude runs
+-- Values are encrypted with ENV_ENCRYPTION_KEY before they are stored
+
+CREATE TABLE channel_env_vars (
+    channel_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    value_encrypted TEXT NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (channel_id, name)
+);
+
+-- Add comments for clarity
+COMMENT ON TABLE channel_env_vars IS 'Environment variables injected into Claude CLI runs in a channel, managed with /env';
+COMMENT ON COLUMN channel_env_vars.value_encrypted IS 'AES-256-GCM ciphertext with nonce, base64-encoded';
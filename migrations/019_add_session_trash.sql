@@ -0,0 +1,12 @@
+-- Migration 019: Soft delete for sessions
+-- /delete moves sessions to the trash; they are purged after SESSION_TRASH_RETENTION
+
+ALTER TABLE sessions ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE sessions ADD COLUMN deleted_by VARCHAR(255);
+
+-- Index for the trash listing and purge job
+CREATE INDEX idx_sessions_deleted_at ON sessions(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Add comments for clarity
+COMMENT ON COLUMN sessions.deleted_at IS 'When the session was moved to the trash; NULL for live sessions';
+COMMENT ON COLUMN sessions.deleted_by IS 'Slack user ID that deleted the session';
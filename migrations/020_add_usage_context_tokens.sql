@@ -0,0 +1,10 @@
+-- Migration 020: Track estimated context size per run
+-- Used to warn when a conversation approaches the model's context window
+
+ALTER TABLE session_usage ADD COLUMN context_tokens INTEGER;
+
+-- Index for looking up the latest run of a Claude session
+CREATE INDEX idx_session_usage_claude_session_id ON session_usage(claude_session_id);
+
+-- Add comment for clarity
+COMMENT ON COLUMN session_usage.context_tokens IS 'Estimated tokens the conversation occupies in the context window after the run; NULL when unknown';
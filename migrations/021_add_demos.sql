@@ -0,0 +1,33 @@
+-- Migration 021: Recorded demos for onboarding
+-- /demo record captures a channel's prompts and responses; /demo play replays
+-- them in another channel without running Claude
+
+CREATE TABLE demos (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    source_channel_id VARCHAR(255) NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    recording BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE demo_steps (
+    id SERIAL PRIMARY KEY,
+    demo_id INTEGER NOT NULL REFERENCES demos(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    prompt TEXT NOT NULL,
+    response TEXT NOT NULL,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (demo_id, position)
+);
+
+-- At most one recording per channel
+CREATE UNIQUE INDEX idx_demos_recording_channel ON demos(source_channel_id) WHERE recording;
+
+-- Add comments for clarity
+COMMENT ON TABLE demos IS 'Named recordings of a channel conversation, replayed with /demo play';
+COMMENT ON COLUMN demos.recording IS 'TRUE while new exchanges in the source channel are being appended';
+COMMENT ON COLUMN demo_steps.prompt IS 'Anonymized prompt as sent to Claude';
+COMMENT ON COLUMN demo_steps.response IS 'Anonymized response, without the session footer';
+COMMENT ON COLUMN demo_steps.duration_ms IS 'How long the original run took, used to pace the replay';
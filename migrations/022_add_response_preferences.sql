@@ -0,0 +1,18 @@
+-- Migration 022: Response preferences
+-- Verbosity, language, and code-comment style set with /prefs, per user or per
+-- channel, and appended to Claude's system prompt
+
+CREATE TABLE response_preferences (
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('user', 'channel')),
+    scope_id VARCHAR(255) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    value TEXT NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (scope, scope_id, name)
+);
+
+-- Add comments for clarity
+COMMENT ON TABLE response_preferences IS 'How Claude should respond, managed with /prefs; user preferences override channel ones';
+COMMENT ON COLUMN response_preferences.scope_id IS 'Slack user ID for user scope, channel ID for channel scope';
+COMMENT ON COLUMN response_preferences.name IS 'Preference name: verbosity, language, or comments';
@@ -0,0 +1,9 @@
+-- Migration 023: Channel type on slack_channels
+-- Records whether a conversation is a public channel, private channel, DM, or
+-- group DM so defaults and /session listings can depend on it
+
+ALTER TABLE slack_channels ADD COLUMN channel_type VARCHAR(20)
+    CHECK (channel_type IN ('public', 'private', 'dm', 'group_dm'));
+
+-- Add comments for clarity
+COMMENT ON COLUMN slack_channels.channel_type IS 'public, private, dm, or group_dm; NULL for channels not seen since this column was added';
@@ -0,0 +1,9 @@
+-- Migration 024: Run priority on slack_channels
+-- Set with /priority; when runs have to wait for a slot, urgent channels go
+-- first and batch ones last
+
+ALTER TABLE slack_channels ADD COLUMN run_priority VARCHAR(10)
+    CHECK (run_priority IN ('urgent', 'normal', 'batch'));
+
+-- Add comments for clarity
+COMMENT ON COLUMN slack_channels.run_priority IS 'urgent, normal, or batch; NULL = CHANNEL_PRIORITIES entry, else normal';
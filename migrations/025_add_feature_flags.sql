@@ -0,0 +1,24 @@
+-- Migration 025: Feature flags
+-- Run-time switches for risky features, managed with /flags: a percentage
+-- rollout across channels plus per-channel overrides
+
+CREATE TABLE feature_flags (
+    name VARCHAR(50) PRIMARY KEY,
+    rollout_percent INTEGER NOT NULL DEFAULT 0 CHECK (rollout_percent BETWEEN 0 AND 100),
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE feature_flag_channels (
+    flag_name VARCHAR(50) NOT NULL REFERENCES feature_flags(name) ON DELETE CASCADE,
+    channel_id VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (flag_name, channel_id)
+);
+
+-- Add comments for clarity
+COMMENT ON TABLE feature_flags IS 'Feature flags managed with /flags';
+COMMENT ON COLUMN feature_flags.rollout_percent IS 'Share of channels the flag is on for, picked by a stable hash of flag name and channel ID';
+COMMENT ON TABLE feature_flag_channels IS 'Per-channel overrides that turn a flag on or off regardless of rollout';
@@ -0,0 +1,10 @@
+-- Migration 026: Track how long each run took
+-- Used by /stats to chart latency alongside volume, cost, and errors
+
+ALTER TABLE session_usage ADD COLUMN duration_ms INTEGER;
+
+-- Index for time-range analytics across all channels
+CREATE INDEX idx_session_usage_created_at ON session_usage(created_at);
+
+-- Add comment for clarity
+COMMENT ON COLUMN session_usage.duration_ms IS 'Time from starting the run to Claude''s reply in milliseconds, including time queued for a run slot; NULL when unknown';
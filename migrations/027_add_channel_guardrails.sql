@@ -0,0 +1,14 @@
+-- Migration 027: Per-channel guardrails for Claude runs
+-- Admins set them with /guardrails; they are appended to the system prompt
+-- of every run in the channel, after everything else
+
+CREATE TABLE channel_guardrails (
+    channel_id VARCHAR(255) PRIMARY KEY,
+    guardrails TEXT NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Add comments for clarity
+COMMENT ON TABLE channel_guardrails IS 'Mandatory constraints added to the system prompt of Claude runs in a channel, managed with /guardrails';
+COMMENT ON COLUMN channel_guardrails.guardrails IS 'Constraints as written by the admin, e.g. never touch .env files';
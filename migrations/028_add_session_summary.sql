@@ -0,0 +1,10 @@
+-- Migration 028: Closing summary on root sessions
+-- Written when a session is closed or deleted with SUMMARIZE_ON_CLOSE on, so
+-- what was learned outlives the conversation
+
+ALTER TABLE sessions ADD COLUMN summary TEXT;
+ALTER TABLE sessions ADD COLUMN summarized_at TIMESTAMP WITH TIME ZONE;
+
+-- Add comments for clarity
+COMMENT ON COLUMN sessions.summary IS 'Summary of the whole conversation, written when the session was closed or deleted (nullable)';
+COMMENT ON COLUMN sessions.summarized_at IS 'When summary was last written';
@@ -0,0 +1,24 @@
+-- Migration 029: Delayed prompts scheduled with /later
+-- Each row is a prompt to run in a channel's current session at run_at;
+-- the bot's dispatcher claims due rows and runs them as their author
+
+CREATE TABLE IF NOT EXISTS delayed_prompts (
+    id SERIAL PRIMARY KEY,
+    channel_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    prompt TEXT NOT NULL,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    dispatched_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT delayed_prompts_status_check CHECK (status IN ('pending', 'dispatched', 'canceled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_delayed_prompts_due ON delayed_prompts(status, run_at);
+CREATE INDEX IF NOT EXISTS idx_delayed_prompts_channel ON delayed_prompts(channel_id, status);
+
+-- Add comments for clarity
+COMMENT ON TABLE delayed_prompts IS 'Prompts scheduled with /later to run in a channel at a future time';
+COMMENT ON COLUMN delayed_prompts.run_at IS 'When the prompt becomes due';
+COMMENT ON COLUMN delayed_prompts.status IS 'pending until due, then dispatched; canceled with /later cancel';
+COMMENT ON COLUMN delayed_prompts.dispatched_at IS 'When the dispatcher handed the prompt to Claude; the result is threaded under a marker message';
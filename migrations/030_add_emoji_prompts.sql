@@ -0,0 +1,15 @@
+-- Migration 030: Emoji-triggered quick prompts
+-- Admins map a reaction emoji to a prompt template with /emoji; reacting
+-- with it on a message runs the template with the message as context
+
+CREATE TABLE emoji_prompts (
+    emoji VARCHAR(100) PRIMARY KEY,
+    template TEXT NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Add comments for clarity
+COMMENT ON TABLE emoji_prompts IS 'Prompt templates run when a message gets a matching reaction, managed with /emoji';
+COMMENT ON COLUMN emoji_prompts.emoji IS 'Reaction name without colons or skin tone, e.g. explain or translate-jp';
+COMMENT ON COLUMN emoji_prompts.template IS 'Prompt with {message}, {author}, and {user} placeholders';
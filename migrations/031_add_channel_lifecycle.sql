@@ -0,0 +1,11 @@
+-- Migration 031: Channel lifecycle state
+-- Set from Slack's channel_archive, channel_unarchive, and channel_deleted
+-- events (and their group_* equivalents for private channels)
+
+ALTER TABLE slack_channels ADD COLUMN lifecycle_state VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (lifecycle_state IN ('active', 'archived', 'deleted'));
+ALTER TABLE slack_channels ADD COLUMN lifecycle_changed_at TIMESTAMP WITH TIME ZONE;
+
+-- Add comments for clarity
+COMMENT ON COLUMN slack_channels.lifecycle_state IS 'active, archived, or deleted, as last reported by Slack';
+COMMENT ON COLUMN slack_channels.lifecycle_changed_at IS 'When the channel was last archived, unarchived, or deleted';
@@ -0,0 +1,18 @@
+-- Migration 032: Prompt templates
+-- Frequently used prompts saved with /template, per user or per channel, with
+-- {variable} placeholders filled in by /template run
+
+CREATE TABLE prompt_templates (
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('user', 'channel')),
+    scope_id VARCHAR(255) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    template TEXT NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (scope, scope_id, name)
+);
+
+-- Add comments for clarity
+COMMENT ON TABLE prompt_templates IS 'Saved prompts managed with /template; user templates override channel ones with the same name';
+COMMENT ON COLUMN prompt_templates.scope_id IS 'Slack user ID for user scope, channel ID for channel scope';
+COMMENT ON COLUMN prompt_templates.template IS 'Prompt text with {variable} placeholders';
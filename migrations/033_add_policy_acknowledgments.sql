@@ -0,0 +1,16 @@
+-- Migration 033: Usage policy acknowledgments
+-- Records which users agreed to which version of USAGE_POLICY_FILE, before
+-- their first Claude run
+
+CREATE TABLE policy_acknowledgments (
+    user_id VARCHAR(255) NOT NULL,
+    policy_version VARCHAR(100) NOT NULL,
+    channel_id VARCHAR(255),
+    acknowledged_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, policy_version)
+);
+
+-- Add comments for clarity
+COMMENT ON TABLE policy_acknowledgments IS 'Users who clicked "I agree" on the usage policy, kept as a compliance record';
+COMMENT ON COLUMN policy_acknowledgments.policy_version IS 'USAGE_POLICY_VERSION, or a hash of the policy text, at the time of agreement';
+COMMENT ON COLUMN policy_acknowledgments.channel_id IS 'Channel the policy was agreed to in';
@@ -0,0 +1,15 @@
+-- Migration 034: Extra session directories
+-- Directories attached to a session with /session adddir, passed to Claude
+-- Code as additional --add-dir flags so cross-repo tasks can see all the code
+
+CREATE TABLE session_dirs (
+    session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    added_by VARCHAR(255),
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (session_id, path)
+);
+
+-- Add comments for clarity
+COMMENT ON TABLE session_dirs IS 'Directories besides the working directory that a session''s runs may access';
+COMMENT ON COLUMN session_dirs.path IS 'Absolute, cleaned directory path';
@@ -0,0 +1,7 @@
+-- Migration 035: Add per-channel environment snapshot fields to slack_channels
+-- Chooses which parts of the run environment (git, kube, venv) the response footer shows
+
+ALTER TABLE slack_channels ADD COLUMN footer_env TEXT[];
+
+-- Add comment for clarity
+COMMENT ON COLUMN slack_channels.footer_env IS 'Environment snapshot fields shown in the response footer: git, kube, venv (NULL = FOOTER_ENV default, empty = none)';
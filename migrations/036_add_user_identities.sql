@@ -0,0 +1,22 @@
+-- Migration 036: Slack user to system identity mapping
+-- Admins map Slack user IDs to real identities (LDAP or Unix usernames,
+-- emails) so sessions, audit logs, and cost reports name people, not U01ABC IDs
+
+CREATE TABLE user_identities (
+    slack_user_id VARCHAR(255) PRIMARY KEY,
+    identity VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Attribute each run's cost to who asked for it, as identified at the time
+ALTER TABLE session_usage ADD COLUMN user_id VARCHAR(255);
+ALTER TABLE session_usage ADD COLUMN identity VARCHAR(255);
+
+-- Index for cost reports per person
+CREATE INDEX idx_session_usage_identity_created ON session_usage(identity, created_at);
+
+-- Add comments for clarity
+COMMENT ON TABLE user_identities IS 'System identity (LDAP/Unix username or email) of each mapped Slack user, managed with /identity';
+COMMENT ON COLUMN session_usage.user_id IS 'Slack user who started the run; NULL for runs recorded before attribution';
+COMMENT ON COLUMN session_usage.identity IS 'Mapped system identity of user_id when the run was recorded; NULL if unmapped';
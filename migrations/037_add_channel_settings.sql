@@ -0,0 +1,27 @@
+-- Migration 037: Per-channel settings
+-- One typed row per channel for behavior that used to be spread across
+-- comma-separated env lists; managed with /settings. NULL inherits the
+-- bot-wide configuration.
+
+CREATE TABLE channel_settings (
+    channel_id VARCHAR(255) PRIMARY KEY,
+    auto_respond BOOLEAN,
+    model VARCHAR(255),
+    permission_mode VARCHAR(50),
+    reply_in_thread BOOLEAN,
+    footer VARCHAR(50),
+    allowed_tools TEXT[],
+    disallowed_tools TEXT[],
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Add comments for clarity
+COMMENT ON TABLE channel_settings IS 'Per-channel behavior overrides managed with /settings; NULL columns inherit the bot-wide configuration';
+COMMENT ON COLUMN channel_settings.auto_respond IS 'Reply to every message rather than only mentions (NULL = REQUIRE_MENTION, which never applies to DMs)';
+COMMENT ON COLUMN channel_settings.model IS 'Claude model for runs in the channel (NULL = CLAUDE_MODEL)';
+COMMENT ON COLUMN channel_settings.permission_mode IS 'Permission mode the channel starts in and reverts to (NULL = channel type or workspace default)';
+COMMENT ON COLUMN channel_settings.reply_in_thread IS 'Reply to top-level messages in a thread under them (NULL = reply in the channel)';
+COMMENT ON COLUMN channel_settings.footer IS 'Response footer style: full, compact, or none (NULL = full)';
+COMMENT ON COLUMN channel_settings.allowed_tools IS 'Tools Claude may use in the channel (NULL = ALLOWED_TOOLS, empty = all tools)';
+COMMENT ON COLUMN channel_settings.disallowed_tools IS 'Tools Claude may not use in the channel, on top of DISALLOWED_TOOLS (NULL or empty = none)';
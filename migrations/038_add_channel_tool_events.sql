@@ -0,0 +1,7 @@
+-- Migration 038: Add the tool_events channel setting
+-- Posts each command Claude runs and file it edits to the thread while a run is in progress
+
+ALTER TABLE channel_settings ADD COLUMN tool_events BOOLEAN;
+
+-- Add comment for clarity
+COMMENT ON COLUMN channel_settings.tool_events IS 'Post major tool calls as thread replies during runs (NULL = TOOL_EVENTS)';
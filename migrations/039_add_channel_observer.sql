@@ -0,0 +1,7 @@
+-- Migration 039: Add the observer channel setting
+-- Observer channels only discuss conversation content: no working directory, no file or shell tools
+
+ALTER TABLE channel_settings ADD COLUMN observer BOOLEAN;
+
+-- Add comment for clarity
+COMMENT ON COLUMN channel_settings.observer IS 'Read-only observer mode: plan mode, no tools touching the host, empty working directory (NULL = off)';
@@ -0,0 +1,7 @@
+-- Migration 040: Add summarized checkpoints to child_sessions
+-- Pruning collapses runs of old exchanges into one checkpoint row holding their summary
+
+ALTER TABLE child_sessions ADD COLUMN checkpoint_of INTEGER;
+
+-- Add comment for clarity
+COMMENT ON COLUMN child_sessions.checkpoint_of IS 'Number of exchanges this checkpoint row summarizes (NULL = a regular exchange)';
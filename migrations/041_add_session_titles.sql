@@ -0,0 +1,8 @@
+-- Migration 041: Short generated titles on root sessions
+-- Written after a session's first exchange so lists and footers can name a
+-- session instead of showing its UUID and path
+
+ALTER TABLE sessions ADD COLUMN title VARCHAR(100);
+
+-- Add comment for clarity
+COMMENT ON COLUMN sessions.title IS 'Short title generated from the first exchange (nullable until generated)';
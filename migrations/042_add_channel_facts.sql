@@ -0,0 +1,20 @@
+-- Migration 042: Channel facts
+-- Short facts a channel asks the bot to remember with /remember, added to the
+-- system prompt of every run there so they outlive sessions and compactions
+
+CREATE TABLE channel_facts (
+    id SERIAL PRIMARY KEY,
+    channel_id VARCHAR(255) NOT NULL,
+    fact TEXT NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_by VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_channel_facts_channel ON channel_facts(channel_id, id);
+
+-- Add comments for clarity
+COMMENT ON TABLE channel_facts IS 'Facts saved with /remember and managed with /memory, injected into runs in the channel';
+COMMENT ON COLUMN channel_facts.fact IS 'One fact in plain text, e.g. "deploys run via make deploy"';
+COMMENT ON COLUMN channel_facts.updated_by IS 'Slack user ID of whoever last saved or edited the fact';
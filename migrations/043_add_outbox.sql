@@ -0,0 +1,28 @@
+-- Migration 043: Outbox for undelivered Slack messages
+-- Replies Slack wouldn't take during an outage or a long rate limit are kept
+-- here and redelivered with backoff, so finished Claude runs aren't lost
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id SERIAL PRIMARY KEY,
+    channel_id VARCHAR(255) NOT NULL,
+    thread_ts VARCHAR(50) NOT NULL DEFAULT '',
+    text TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    settled_at TIMESTAMP WITH TIME ZONE,
+    message_ts VARCHAR(50),
+    CONSTRAINT outbox_status_check CHECK (status IN ('pending', 'delivered', 'abandoned'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_due ON outbox(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_channel ON outbox(channel_id, status, id);
+
+-- Add comments for clarity
+COMMENT ON TABLE outbox IS 'Slack messages that failed to post and are retried until delivered or OUTBOX_MAX_AGE passes';
+COMMENT ON COLUMN outbox.thread_ts IS 'Thread to post in, or empty to post in the channel';
+COMMENT ON COLUMN outbox.status IS 'pending until Slack acknowledges it (delivered) or it is given up (abandoned)';
+COMMENT ON COLUMN outbox.next_attempt_at IS 'When delivery is next tried; pushed back while an instance is delivering it';
+COMMENT ON COLUMN outbox.message_ts IS 'Timestamp Slack gave the message once delivered';
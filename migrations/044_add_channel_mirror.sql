@@ -0,0 +1,12 @@
+-- Migration 044: Add the channel mirror settings
+-- Mirrored channels cross-post Claude's responses, and optionally the prompts
+-- behind them, to another channel such as an audit or team-lead channel
+
+ALTER TABLE channel_settings ADD COLUMN mirror_channel VARCHAR(255);
+ALTER TABLE channel_settings ADD COLUMN mirror_responses BOOLEAN;
+ALTER TABLE channel_settings ADD COLUMN mirror_prompts BOOLEAN;
+
+-- Add comments for clarity
+COMMENT ON COLUMN channel_settings.mirror_channel IS 'Channel that responses and prompts here are cross-posted to (NULL = not mirrored)';
+COMMENT ON COLUMN channel_settings.mirror_responses IS 'Cross-post Claude''s responses to the mirror channel (NULL = on)';
+COMMENT ON COLUMN channel_settings.mirror_prompts IS 'Cross-post the prompts that start runs to the mirror channel (NULL = off)';
@@ -0,0 +1,9 @@
+-- Migration 045: Track tokens and edited files per run
+-- Used by /session stats to total a session's tokens and count the files it touched
+
+ALTER TABLE session_usage ADD COLUMN total_tokens INTEGER;
+ALTER TABLE session_usage ADD COLUMN edited_files TEXT[];
+
+-- Add comments for clarity
+COMMENT ON COLUMN session_usage.total_tokens IS 'Input, cache, and output tokens the run used across all its model calls; NULL when unknown';
+COMMENT ON COLUMN session_usage.edited_files IS 'Files the run created or edited; NULL for runs recorded before this was tracked';
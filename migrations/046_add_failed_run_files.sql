@@ -0,0 +1,8 @@
+-- Migration 046: Keep a failed run's attachments
+-- Replays download them again rather than pointing at temporary files that are gone
+
+ALTER TABLE failed_runs ADD COLUMN file_ids TEXT[] NOT NULL DEFAULT '{}';
+
+-- Add comments for clarity
+COMMENT ON COLUMN failed_runs.prompt IS 'The user''s message as they sent it, before attachments and links were expanded';
+COMMENT ON COLUMN failed_runs.file_ids IS 'Slack file IDs attached to the message, downloaded again when the run is replayed';
@@ -0,0 +1,78 @@
+-- Migration 047: Move the remaining per-channel defaults into channel settings
+-- Agents, footer environment fields, and run priority move from slack_channels
+-- to channel_settings; the footer style becomes a footer template; permission
+-- modes by channel type get their own table; and env lists imported into
+-- settings are recorded so they are imported only once.
+--
+-- One-way: slack_channels.agents, footer_env, and run_priority are dropped,
+-- so binaries older than this migration lose those per-channel values and
+-- compact footers. To go back to an older binary, restore them first:
+--
+--   ALTER TABLE slack_channels ADD COLUMN agents TEXT[];
+--   ALTER TABLE slack_channels ADD COLUMN footer_env TEXT[];
+--   ALTER TABLE slack_channels ADD COLUMN run_priority VARCHAR(10)
+--       CHECK (run_priority IN ('urgent', 'normal', 'batch'));
+--   UPDATE slack_channels c
+--   SET agents = s.agents, footer_env = s.footer_env, run_priority = s.run_priority
+--   FROM channel_settings s WHERE s.channel_id = c.channel_id;
+--   ALTER TABLE channel_settings RENAME COLUMN footer_template TO footer;
+--   UPDATE channel_settings SET footer = CASE
+--       WHEN footer = 'none' THEN 'none'
+--       WHEN footer IS NOT NULL THEN 'compact'
+--   END;
+--   ALTER TABLE channel_settings ALTER COLUMN footer TYPE VARCHAR(50);
+--   ALTER TABLE channel_settings DROP COLUMN agents, DROP COLUMN footer_env, DROP COLUMN run_priority;
+--   DROP TABLE channel_type_settings;
+--   DROP TABLE channel_settings_imports;
+
+CREATE TABLE channel_settings_imports (
+    source VARCHAR(100) PRIMARY KEY,
+    imported INTEGER NOT NULL DEFAULT 0,
+    imported_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Releases since migration 037 copied CHANNEL_PERMISSION_DEFAULTS into
+-- channel_settings on every startup, so a database with settings has it
+INSERT INTO channel_settings_imports (source)
+SELECT 'CHANNEL_PERMISSION_DEFAULTS' WHERE EXISTS (SELECT 1 FROM channel_settings);
+
+ALTER TABLE channel_settings ADD COLUMN agents TEXT[];
+ALTER TABLE channel_settings ADD COLUMN footer_env TEXT[];
+ALTER TABLE channel_settings ADD COLUMN run_priority VARCHAR(10)
+    CHECK (run_priority IN ('urgent', 'normal', 'batch'));
+
+INSERT INTO channel_settings (channel_id, agents, footer_env, run_priority, updated_at)
+SELECT channel_id, agents, footer_env, run_priority, NOW()
+FROM slack_channels
+WHERE agents IS NOT NULL OR footer_env IS NOT NULL OR run_priority IS NOT NULL
+ON CONFLICT (channel_id) DO UPDATE
+SET agents = EXCLUDED.agents, footer_env = EXCLUDED.footer_env, run_priority = EXCLUDED.run_priority;
+
+ALTER TABLE slack_channels DROP COLUMN agents;
+ALTER TABLE slack_channels DROP COLUMN footer_env;
+ALTER TABLE slack_channels DROP COLUMN run_priority;
+
+-- The full style is the default template; compact becomes its one-line template
+ALTER TABLE channel_settings RENAME COLUMN footer TO footer_template;
+ALTER TABLE channel_settings ALTER COLUMN footer_template TYPE TEXT;
+UPDATE channel_settings SET footer_template = CASE footer_template
+    WHEN 'compact' THEN '• _{mode} · {short_session}_'
+    WHEN 'none' THEN 'none'
+END
+WHERE footer_template IS NOT NULL;
+
+CREATE TABLE channel_type_settings (
+    channel_type VARCHAR(20) PRIMARY KEY,
+    permission_mode VARCHAR(50),
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Add comments for clarity
+COMMENT ON COLUMN channel_settings.agents IS 'Names of configured Claude sub-agents enabled in the channel (NULL = all configured agents)';
+COMMENT ON COLUMN channel_settings.footer_env IS 'Environment snapshot fields shown in the response footer: git, kube, venv (NULL = FOOTER_ENV default, empty = none)';
+COMMENT ON COLUMN channel_settings.run_priority IS 'urgent, normal, or batch (NULL = normal)';
+COMMENT ON COLUMN channel_settings.footer_template IS 'Response footer template with placeholders such as {mode} and {session}, or none (NULL = the full footer)';
+COMMENT ON TABLE channel_type_settings IS 'Settings new channels of a type start with, managed with /settings type';
+COMMENT ON COLUMN channel_type_settings.permission_mode IS 'Permission mode new channels of the type start in (NULL = workspace default)';
+COMMENT ON TABLE channel_settings_imports IS 'Deprecated env lists already copied into settings; each is imported once so settings cleared later stay cleared';
@@ -0,0 +1,8 @@
+-- Migration 048: Record which instance posted each "Thinking..." message
+-- On startup an instance marks its own leftover placeholders interrupted right
+-- away; other instances' are only swept once older than THINKING_TIMEOUT.
+
+ALTER TABLE thinking_messages ADD COLUMN owner VARCHAR(255);
+
+-- Add comment for clarity
+COMMENT ON COLUMN thinking_messages.owner IS 'INSTANCE_NAME of the instance that posted the message; NULL for rows recorded before migration 048';